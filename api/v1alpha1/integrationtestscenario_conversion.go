@@ -17,6 +17,7 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"encoding/json"
 	"reflect"
 
 	"github.com/redhat-appstudio/integration-service/api/v1beta1"
@@ -24,6 +25,10 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/conversion"
 )
 
+// ConversionDataAnnotation contains the JSON of the v1beta1 spec of an IntegrationTestScenario whose fields can't be
+// represented in v1alpha1, so they can be restored when the IntegrationTestScenario is converted back to v1beta1.
+const ConversionDataAnnotation = "test.appstudio.openshift.io/v1beta1-conversion-data"
+
 func (r *IntegrationTestScenario) SetupWebhookWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewWebhookManagedBy(mgr).
 		For(r).
@@ -41,7 +46,11 @@ func (src *IntegrationTestScenario) ConvertTo(dstRaw conversion.Hub) error {
 	}
 	if src.Spec.Params != nil {
 		for _, par := range src.Spec.Params {
-			dst.Spec.Params = append(dst.Spec.Params, v1beta1.PipelineParameter(par))
+			dst.Spec.Params = append(dst.Spec.Params, v1beta1.PipelineParameter{
+				Name:   par.Name,
+				Value:  par.Value,
+				Values: par.Values,
+			})
 		}
 	}
 	if src.Spec.Contexts != nil {
@@ -66,19 +75,23 @@ func (src *IntegrationTestScenario) ConvertTo(dstRaw conversion.Hub) error {
 			},
 		},
 	}
-	return nil
+	return restoreConversionData(src, dst)
 }
 
 func (dst *IntegrationTestScenario) ConvertFrom(srcRaw conversion.Hub) error {
 	src := srcRaw.(*v1beta1.IntegrationTestScenario)
 	dst.ObjectMeta = src.ObjectMeta
 	dst.Spec.Application = src.Spec.Application
-	if !reflect.ValueOf(dst.Spec.Environment).IsZero() {
+	if !reflect.ValueOf(src.Spec.Environment).IsZero() {
 		dst.Spec.Environment = TestEnvironment(src.Spec.Environment)
 	}
 	if src.Spec.Params != nil {
 		for _, par := range src.Spec.Params {
-			dst.Spec.Params = append(dst.Spec.Params, PipelineParameter(par))
+			dst.Spec.Params = append(dst.Spec.Params, PipelineParameter{
+				Name:   par.Name,
+				Value:  par.Value,
+				Values: par.Values,
+			})
 		}
 	}
 	if src.Spec.Contexts != nil {
@@ -97,5 +110,89 @@ func (dst *IntegrationTestScenario) ConvertFrom(srcRaw conversion.Hub) error {
 			}
 		}
 	}
+	return storeConversionData(src, dst)
+}
+
+// storeConversionData stores the v1beta1 spec of the IntegrationTestScenario in the ConversionDataAnnotation
+// of the converted v1alpha1 IntegrationTestScenario if any of its fields can't be represented in v1alpha1.
+func storeConversionData(src *v1beta1.IntegrationTestScenario, dst *IntegrationTestScenario) error {
+	if !hasV1beta1OnlyFields(src) {
+		return nil
+	}
+
+	data, err := json.Marshal(src.Spec)
+	if err != nil {
+		return err
+	}
+
+	annotations := map[string]string{}
+	for key, value := range src.Annotations {
+		annotations[key] = value
+	}
+	annotations[ConversionDataAnnotation] = string(data)
+	dst.Annotations = annotations
+
 	return nil
 }
+
+// restoreConversionData restores the fields of the v1beta1 IntegrationTestScenario which can't be represented in
+// v1alpha1 from the ConversionDataAnnotation of the v1alpha1 IntegrationTestScenario, and removes the annotation.
+func restoreConversionData(src *IntegrationTestScenario, dst *v1beta1.IntegrationTestScenario) error {
+	data, found := src.Annotations[ConversionDataAnnotation]
+	if !found {
+		return nil
+	}
+
+	restored := &v1beta1.IntegrationTestScenarioSpec{}
+	err := json.Unmarshal([]byte(data), restored)
+	if err != nil {
+		return err
+	}
+
+	dst.Spec.ApplicationSelector = restored.ApplicationSelector
+	dst.Spec.Environments = restored.Environments
+	dst.Spec.Matrix = restored.Matrix
+	dst.Spec.DependsOn = restored.DependsOn
+	dst.Spec.Schedule = restored.Schedule
+	for i, param := range dst.Spec.Params {
+		for _, restoredParam := range restored.Params {
+			if restoredParam.Name == param.Name {
+				dst.Spec.Params[i].ValueFrom = restoredParam.ValueFrom
+			}
+		}
+	}
+	// Only the bundles resolver can be represented in v1alpha1, the other ones are converted to an empty bundle
+	if restored.ResolverRef.Resolver != "bundles" && src.Spec.Bundle == "" && src.Spec.Pipeline == "" {
+		dst.Spec.ResolverRef = restored.ResolverRef
+	}
+
+	annotations := map[string]string{}
+	for key, value := range src.Annotations {
+		if key != ConversionDataAnnotation {
+			annotations[key] = value
+		}
+	}
+	dst.Annotations = nil
+	if len(annotations) > 0 {
+		dst.Annotations = annotations
+	}
+
+	return nil
+}
+
+// hasV1beta1OnlyFields returns true if any of the fields of the v1beta1 IntegrationTestScenario which can't be
+// represented in v1alpha1 is set.
+func hasV1beta1OnlyFields(integrationTestScenario *v1beta1.IntegrationTestScenario) bool {
+	spec := integrationTestScenario.Spec
+	if spec.ApplicationSelector != nil || len(spec.Environments) > 0 || spec.Matrix != nil ||
+		len(spec.DependsOn) > 0 || spec.Schedule != "" || spec.ResolverRef.Resolver != "bundles" {
+		return true
+	}
+	for _, param := range spec.Params {
+		if param.ValueFrom != nil {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,144 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	"github.com/redhat-appstudio/integration-service/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("IntegrationTestScenario conversion", func() {
+
+	var hubScenario *v1beta1.IntegrationTestScenario
+
+	BeforeEach(func() {
+		hubScenario = &v1beta1.IntegrationTestScenario{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "integrationtestscenario",
+				Namespace: "default",
+				Annotations: map[string]string{
+					"test.appstudio.openshift.io/optional": "false",
+				},
+			},
+			Spec: v1beta1.IntegrationTestScenarioSpec{
+				ApplicationSelector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{"tier": "backend"},
+				},
+				ResolverRef: v1beta1.ResolverRef{
+					Resolver: "bundles",
+					Params: []v1beta1.ResolverParameter{
+						{Name: "bundle", Value: "quay.io/kpavic/test-bundle:build-pipeline-pass"},
+						{Name: "name", Value: "component-pipeline-pass"},
+						{Name: "kind", Value: "pipeline"},
+					},
+				},
+				Params: []v1beta1.PipelineParameter{
+					{
+						Name:  "pipeline-param-name",
+						Value: "pipeline-param-value",
+					},
+					{
+						Name: "token",
+						ValueFrom: &v1beta1.PipelineParameterSource{
+							SecretKeyRef: &corev1.SecretKeySelector{
+								LocalObjectReference: corev1.LocalObjectReference{Name: "test-secret"},
+								Key:                  "token",
+							},
+						},
+					},
+				},
+				Environment: v1beta1.TestEnvironment{
+					Name: "environment-name",
+					Type: "POC",
+					Configuration: &applicationapiv1alpha1.EnvironmentConfiguration{
+						Env: []applicationapiv1alpha1.EnvVarPair{{Name: "env-config-name", Value: "env-config-value"}},
+					},
+				},
+				Environments: []v1beta1.TestEnvironment{
+					{Name: "database", Type: "POC"},
+				},
+				Contexts: []v1beta1.TestContext{
+					{Name: "test-ctx", Description: "test-ctx-description"},
+				},
+				Matrix: &v1beta1.TestMatrix{
+					Params: []v1beta1.MatrixParameter{{Name: "arch", Values: []string{"amd64", "arm64"}}},
+				},
+				DependsOn: []string{"smoke-tests"},
+				Schedule:  "0 2 * * *",
+			},
+		}
+	})
+
+	It("preserves the v1beta1 only fields when converting to v1alpha1 and back", func() {
+		scenario := &IntegrationTestScenario{}
+		Expect(scenario.ConvertFrom(hubScenario)).To(Succeed())
+		Expect(scenario.Spec.Bundle).To(Equal("quay.io/kpavic/test-bundle:build-pipeline-pass"))
+		Expect(scenario.Spec.Pipeline).To(Equal("component-pipeline-pass"))
+		Expect(scenario.Spec.Environment.Name).To(Equal("environment-name"))
+		Expect(scenario.Annotations).To(HaveKey(ConversionDataAnnotation))
+		Expect(hubScenario.Annotations).NotTo(HaveKey(ConversionDataAnnotation))
+
+		convertedScenario := &v1beta1.IntegrationTestScenario{}
+		Expect(scenario.ConvertTo(convertedScenario)).To(Succeed())
+		Expect(convertedScenario.Spec).To(Equal(hubScenario.Spec))
+		Expect(convertedScenario.Annotations).To(Equal(hubScenario.Annotations))
+	})
+
+	It("preserves a resolver which can't be represented in v1alpha1", func() {
+		hubScenario.Spec.ResolverRef = v1beta1.ResolverRef{
+			Resolver: "git",
+			Params: []v1beta1.ResolverParameter{
+				{Name: "url", Value: "https://github.com/redhat-appstudio/integration-examples.git"},
+				{Name: "revision", Value: "main"},
+				{Name: "pathInRepo", Value: "pipelines/integration_pipeline_pass.yaml"},
+			},
+		}
+
+		scenario := &IntegrationTestScenario{}
+		Expect(scenario.ConvertFrom(hubScenario)).To(Succeed())
+		Expect(scenario.Spec.Bundle).To(BeEmpty())
+
+		convertedScenario := &v1beta1.IntegrationTestScenario{}
+		Expect(scenario.ConvertTo(convertedScenario)).To(Succeed())
+		Expect(convertedScenario.Spec.ResolverRef).To(Equal(hubScenario.Spec.ResolverRef))
+	})
+
+	It("doesn't add the conversion data to IntegrationTestScenarios which can be represented in v1alpha1", func() {
+		hubScenario.Annotations = nil
+		hubScenario.Spec.ApplicationSelector = nil
+		hubScenario.Spec.Application = "application-sample"
+		hubScenario.Spec.Params = hubScenario.Spec.Params[:1]
+		hubScenario.Spec.Environments = nil
+		hubScenario.Spec.Matrix = nil
+		hubScenario.Spec.DependsOn = nil
+		hubScenario.Spec.Schedule = ""
+
+		scenario := &IntegrationTestScenario{}
+		Expect(scenario.ConvertFrom(hubScenario)).To(Succeed())
+		Expect(scenario.Annotations).NotTo(HaveKey(ConversionDataAnnotation))
+
+		convertedScenario := &v1beta1.IntegrationTestScenario{}
+		Expect(scenario.ConvertTo(convertedScenario)).To(Succeed())
+		Expect(convertedScenario.Spec).To(Equal(hubScenario.Spec))
+		Expect(convertedScenario.Annotations).To(BeEmpty())
+	})
+})
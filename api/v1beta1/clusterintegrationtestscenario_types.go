@@ -0,0 +1,74 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ClusterIntegrationTestScenarioSpec defines the desired state of ClusterIntegrationTestScenario
+type ClusterIntegrationTestScenarioSpec struct {
+	// ApplicationSelector selects the Applications which inherit the ClusterIntegrationTestScenario by their labels
+	ApplicationSelector *metav1.LabelSelector `json:"applicationSelector,omitempty"`
+	// NamespaceSelector selects the namespaces whose Applications inherit the ClusterIntegrationTestScenario
+	// by their labels
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+	// Tekton Resolver where to store the Tekton resolverRef trigger Tekton pipeline used to refer to a Pipeline or Task in a remote location like a git repo.
+	// +required
+	ResolverRef ResolverRef `json:"resolverRef"`
+	// Params to pass to the pipeline, the Applications inheriting the ClusterIntegrationTestScenario can override them
+	Params []PipelineParameter `json:"params,omitempty"`
+	// Contexts where this ClusterIntegrationTestScenario can be applied
+	Contexts []TestContext `json:"contexts,omitempty"`
+	// Matrix of params the ClusterIntegrationTestScenario is fanned out over,
+	// a separate PipelineRun is created for each combination of their values
+	Matrix *TestMatrix `json:"matrix,omitempty"`
+}
+
+// ClusterIntegrationTestScenarioStatus defines the observed state of ClusterIntegrationTestScenario
+type ClusterIntegrationTestScenarioStatus struct {
+	Conditions []metav1.Condition `json:"conditions"`
+	// Applications contains the Applications which inherit the ClusterIntegrationTestScenario,
+	// each of them in the namespace/name format
+	Applications []string `json:"applications,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:subresource:status
+
+// ClusterIntegrationTestScenario is the Schema for the clusterintegrationtestscenarios API
+type ClusterIntegrationTestScenario struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ClusterIntegrationTestScenarioSpec   `json:"spec,omitempty"`
+	Status ClusterIntegrationTestScenarioStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ClusterIntegrationTestScenarioList contains a list of ClusterIntegrationTestScenario
+type ClusterIntegrationTestScenarioList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ClusterIntegrationTestScenario `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ClusterIntegrationTestScenario{}, &ClusterIntegrationTestScenarioList{})
+}
@@ -18,15 +18,21 @@ package v1beta1
 
 import (
 	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 // IntegrationTestScenarioSpec defines the desired state of IntegrationScenario
 type IntegrationTestScenarioSpec struct {
-	// Application that's associated with the IntegrationTestScenario
+	// Application that's associated with the IntegrationTestScenario, exactly one of
+	// Application and ApplicationSelector has to be set
 	// +kubebuilder:validation:Pattern=^[a-z0-9]([-a-z0-9]*[a-z0-9])?$
-	// +required
-	Application string `json:"application"`
+	// +optional
+	Application string `json:"application,omitempty"`
+	// ApplicationSelector selects the Applications in the namespace of the IntegrationTestScenario
+	// it is associated with by their labels, as an alternative to Application
+	// +optional
+	ApplicationSelector *metav1.LabelSelector `json:"applicationSelector,omitempty"`
 	// Tekton Resolver where to store the Tekton resolverRef trigger Tekton pipeline used to refer to a Pipeline or Task in a remote location like a git repo.
 	// +required
 	ResolverRef ResolverRef `json:"resolverRef"`
@@ -34,20 +40,133 @@ type IntegrationTestScenarioSpec struct {
 	Params []PipelineParameter `json:"params,omitempty"`
 	// Environment that will be utilized by the test pipeline
 	Environment TestEnvironment `json:"environment,omitempty"`
+	// Environments that will be utilized by the test pipeline in addition to the Environment,
+	// the Snapshot will be deployed to an ephemeral copy of each of them before the test pipeline starts
+	Environments []TestEnvironment `json:"environments,omitempty"`
 	// Contexts where this IntegrationTestScenario can be applied
 	Contexts []TestContext `json:"contexts,omitempty"`
+	// Matrix of params the IntegrationTestScenario is fanned out over,
+	// a separate PipelineRun is created for each combination of their values
+	Matrix *TestMatrix `json:"matrix,omitempty"`
+	// DependsOn contains the names of the IntegrationTestScenarios of the same Application which have to pass
+	// before the test pipeline of this IntegrationTestScenario is started
+	DependsOn []string `json:"dependsOn,omitempty"`
+	// Schedule is a cron expression, e.g. "0 2 * * *", on which the IntegrationTestScenario is run against a Snapshot
+	// of the Global Candidate List of the Application instead of against the Snapshots of new builds
+	Schedule string `json:"schedule,omitempty"`
 }
 
 // IntegrationTestScenarioStatus defines the observed state of IntegrationTestScenario
 type IntegrationTestScenarioStatus struct {
 	Conditions []metav1.Condition `json:"conditions"`
+	// LastScheduleTime is the last time a Snapshot was created for a scheduled run of the IntegrationTestScenario
+	LastScheduleTime *metav1.Time `json:"lastScheduleTime,omitempty"`
+	// ScheduledRuns contains the history of the most recent scheduled runs of the IntegrationTestScenario
+	ScheduledRuns []ScheduledRun `json:"scheduledRuns,omitempty"`
+	// Flakiness contains the history of the most recent runs of the IntegrationTestScenario and the flakiness
+	// computed from the runs which tested identical inputs
+	Flakiness *ScenarioFlakiness `json:"flakiness,omitempty"`
+}
+
+// ScenarioFlakiness contains the flakiness of an IntegrationTestScenario and of its tasks
+type ScenarioFlakiness struct {
+	// Score is the percentage of the inputs tested more than once whose runs had different outcomes
+	Score int `json:"score"`
+	// RepeatedInputs is the number of inputs tested more than once
+	RepeatedInputs int `json:"repeatedInputs"`
+	// Tasks contains the flakiness of the tasks reporting their outcome in a TEST_OUTPUT result
+	Tasks []TaskFlakiness `json:"tasks,omitempty"`
+	// Runs contains the most recent runs of the IntegrationTestScenario, oldest first
+	Runs []ScenarioRun `json:"runs,omitempty"`
+}
+
+// TaskFlakiness contains the flakiness of a single task of an IntegrationTestScenario
+type TaskFlakiness struct {
+	// Name of the pipeline task
+	Name string `json:"name"`
+	// Score is the percentage of the inputs tested more than once whose runs of the task had different outcomes
+	Score int `json:"score"`
+	// RepeatedInputs is the number of inputs the task tested more than once
+	RepeatedInputs int `json:"repeatedInputs"`
+}
+
+// ScenarioRun contains the outcome of a single run of an IntegrationTestScenario
+type ScenarioRun struct {
+	// Input identifies what the run tested: the hash of the content of the Snapshot,
+	// followed by the matrix combination, if any
+	Input string `json:"input"`
+	// Snapshot is the name of the tested Snapshot
+	Snapshot string `json:"snapshot"`
+	// PipelineRun is the name of the integration PipelineRun of the run
+	PipelineRun string `json:"pipelineRun"`
+	// Passed is true if the run passed
+	Passed bool `json:"passed"`
+	// PassedTasks are the names of the tasks which reported a passing TEST_OUTPUT result
+	PassedTasks []string `json:"passedTasks,omitempty"`
+	// FailedTasks are the names of the tasks which reported a failing TEST_OUTPUT result
+	FailedTasks []string `json:"failedTasks,omitempty"`
+}
+
+// ScheduledRun contains the result of a scheduled run of an IntegrationTestScenario
+type ScheduledRun struct {
+	// Snapshot is the name of the Snapshot of the Global Candidate List created for the run
+	Snapshot string `json:"snapshot"`
+	// ScheduleTime is the time the run was scheduled at
+	ScheduleTime metav1.Time `json:"scheduleTime"`
+	// Result of the run, one of InProgress, Passed or Failed
+	Result string `json:"result"`
+	// CompletionTime is the time the run finished
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
 }
 
 // PipelineParameter contains the name and values of a Tekton Pipeline parameter
 type PipelineParameter struct {
-	Name   string   `json:"name"`
-	Value  string   `json:"value,omitempty"`
+	Name string `json:"name"`
+	// Value of the parameter, it can contain Go templates referencing the Snapshot data,
+	// e.g. {{ .Component.ContainerImage }}, which are resolved when the PipelineRun is created
+	Value string `json:"value,omitempty"`
+	// Values of an array parameter, each of them can contain Go templates like Value
 	Values []string `json:"values,omitempty"`
+	// ValueFrom references the Secret or ConfigMap key where the value of the parameter is taken from
+	ValueFrom *PipelineParameterSource `json:"valueFrom,omitempty"`
+}
+
+// PipelineParameterSource references the source of the value of a Tekton Pipeline parameter,
+// only one of its fields may be set
+type PipelineParameterSource struct {
+	// Selects a key of a Secret in the namespace of the IntegrationTestScenario. The secret value isn't passed as
+	// a param but mounted as the file named after the key in the workspace named after the parameter
+	SecretKeyRef *corev1.SecretKeySelector `json:"secretKeyRef,omitempty"`
+	// Selects a key of a ConfigMap in the namespace of the IntegrationTestScenario, its value is passed as the param value
+	ConfigMapKeyRef *corev1.ConfigMapKeySelector `json:"configMapKeyRef,omitempty"`
+}
+
+// TestMatrix contains the params whose values are combined to create the PipelineRuns of an IntegrationTestScenario
+type TestMatrix struct {
+	// Params whose values are combined, the values of each combination are passed to its PipelineRun
+	// +required
+	Params []MatrixParameter `json:"params"`
+	// Optional contains the combinations which are allowed to fail, a combination is optional
+	// if it contains all the param values of any of the entries
+	Optional []MatrixCombinationSelector `json:"optional,omitempty"`
+}
+
+// MatrixParameter contains the name and the values of a matrix param
+type MatrixParameter struct {
+	Name string `json:"name"`
+	// +kubebuilder:validation:MinItems=1
+	Values []string `json:"values"`
+}
+
+// MatrixCombinationSelector selects the matrix combinations containing all of its param values
+type MatrixCombinationSelector struct {
+	Params []MatrixParameterValue `json:"params"`
+}
+
+// MatrixParameterValue contains the name and a single value of a matrix param
+type MatrixParameterValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
 }
 
 // TestEnvironment contains the name and values of a Test environment
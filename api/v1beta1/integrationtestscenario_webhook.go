@@ -17,11 +17,325 @@ limitations under the License.
 package v1beta1
 
 import (
+	"context"
+	"fmt"
+	"strings"
+	"text/template"
+	"text/template/parse"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
 )
 
+// ParamTemplateFields contains the names of the top level fields that can be referenced by the templates
+// in the values of the IntegrationTestScenario params, e.g. {{ .Component.ContainerImage }}.
+var ParamTemplateFields = []string{"Application", "Snapshot", "Component", "Components", "PullRequest", "Environment"}
+
+// MaxMatrixCombinations is the maximum number of combinations, and thus PipelineRuns, an IntegrationTestScenario
+// matrix can be expanded into.
+const MaxMatrixCombinations = 256
+
 func (r *IntegrationTestScenario) SetupWebhookWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewWebhookManagedBy(mgr).
 		For(r).
+		WithValidator(&integrationTestScenarioValidator{client: mgr.GetClient()}).
 		Complete()
 }
+
+//+kubebuilder:webhook:path=/validate-appstudio-redhat-com-v1beta1-integrationtestscenario,mutating=false,failurePolicy=fail,sideEffects=None,groups=appstudio.redhat.com,resources=integrationtestscenarios,verbs=create;update,versions=v1beta1,name=vintegrationtestscenario.kb.io,admissionReviewVersions=v1
+
+// integrationTestScenarioValidator validates the IntegrationTestScenarios, using the client to load the other
+// IntegrationTestScenarios of the Application when validating the dependencies between them.
+type integrationTestScenarioValidator struct {
+	client client.Client
+}
+
+var _ webhook.CustomValidator = &integrationTestScenarioValidator{}
+
+// ValidateCreate implements webhook.CustomValidator so a webhook will be registered for the type.
+func (v *integrationTestScenarioValidator) ValidateCreate(ctx context.Context, obj runtime.Object) error {
+	return v.validate(ctx, obj)
+}
+
+// ValidateUpdate implements webhook.CustomValidator so a webhook will be registered for the type.
+func (v *integrationTestScenarioValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) error {
+	return v.validate(ctx, newObj)
+}
+
+// ValidateDelete implements webhook.CustomValidator so a webhook will be registered for the type.
+func (v *integrationTestScenarioValidator) ValidateDelete(ctx context.Context, obj runtime.Object) error {
+	return nil
+}
+
+// validate makes sure that the given object is an IntegrationTestScenario and validates it.
+func (v *integrationTestScenarioValidator) validate(ctx context.Context, obj runtime.Object) error {
+	scenario, ok := obj.(*IntegrationTestScenario)
+	if !ok {
+		return apierrors.NewBadRequest(fmt.Sprintf("expected an IntegrationTestScenario but got a %T", obj))
+	}
+
+	return scenario.validateIntegrationTestScenario(ctx, v.client)
+}
+
+// validateIntegrationTestScenario validates the IntegrationTestScenario spec, returning an Invalid error
+// listing all the problems found in it. The client is used to load the other IntegrationTestScenarios when
+// validating the dependencies, which are only checked for self references and duplicates if it is nil.
+func (r *IntegrationTestScenario) validateIntegrationTestScenario(ctx context.Context, c client.Client) error {
+	specPath := field.NewPath("spec")
+	allErrs := validatePipelineParameters(r.Spec.Params, specPath.Child("params"))
+	allErrs = append(allErrs, r.validateApplicationSelector(specPath)...)
+	if r.Spec.Matrix != nil {
+		if r.Spec.Environment.Name != "" || len(r.Spec.Environments) > 0 {
+			allErrs = append(allErrs, field.Forbidden(specPath.Child("matrix"),
+				"matrix can't be used together with environment or environments"))
+		}
+		allErrs = append(allErrs, validateTestMatrix(r.Spec.Matrix, specPath.Child("matrix"))...)
+	}
+	if len(r.Spec.DependsOn) > 0 {
+		allErrs = append(allErrs, r.validateDependencies(ctx, c, specPath.Child("dependsOn"))...)
+	}
+	if len(allErrs) == 0 {
+		return nil
+	}
+
+	return apierrors.NewInvalid(GroupVersion.WithKind("IntegrationTestScenario").GroupKind(), r.Name, allErrs)
+}
+
+// validateApplicationSelector makes sure that the IntegrationTestScenario is associated with Applications either by
+// name or by a valid label selector, and that scenarios selecting Applications by label don't run on a schedule.
+func (r *IntegrationTestScenario) validateApplicationSelector(specPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if (r.Spec.Application == "") == (r.Spec.ApplicationSelector == nil) {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("application"), r.Spec.Application,
+			"exactly one of application or applicationSelector must be set"))
+	}
+	if r.Spec.ApplicationSelector != nil {
+		if _, err := metav1.LabelSelectorAsSelector(r.Spec.ApplicationSelector); err != nil {
+			allErrs = append(allErrs, field.Invalid(specPath.Child("applicationSelector"), r.Spec.ApplicationSelector, err.Error()))
+		}
+		if r.Spec.Schedule != "" {
+			allErrs = append(allErrs, field.Forbidden(specPath.Child("schedule"),
+				"schedule can't be used together with applicationSelector"))
+		}
+	}
+
+	return allErrs
+}
+
+// validatePipelineParameters makes sure that every param either has a value or references a Secret or
+// ConfigMap key, and that the templates in their values are valid.
+func validatePipelineParameters(params []PipelineParameter, path *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	for i, param := range params {
+		paramPath := path.Index(i)
+		if param.ValueFrom != nil {
+			if param.Value != "" || len(param.Values) > 0 {
+				allErrs = append(allErrs, field.Invalid(paramPath.Child("valueFrom"), param.Name,
+					"valueFrom can't be set together with value or values"))
+			}
+			if (param.ValueFrom.SecretKeyRef == nil) == (param.ValueFrom.ConfigMapKeyRef == nil) {
+				allErrs = append(allErrs, field.Invalid(paramPath.Child("valueFrom"), param.Name,
+					"exactly one of secretKeyRef or configMapKeyRef must be set"))
+			}
+		}
+
+		if err := ValidateParamTemplate(param.Name, param.Value); err != nil {
+			allErrs = append(allErrs, field.Invalid(paramPath.Child("value"), param.Value, err.Error()))
+		}
+		for j, value := range param.Values {
+			if err := ValidateParamTemplate(param.Name, value); err != nil {
+				allErrs = append(allErrs, field.Invalid(paramPath.Child("values").Index(j), value, err.Error()))
+			}
+		}
+	}
+
+	return allErrs
+}
+
+// validateDependencies makes sure that the IntegrationTestScenario doesn't depend on itself, directly or through
+// the other IntegrationTestScenarios of the Application.
+func (r *IntegrationTestScenario) validateDependencies(ctx context.Context, c client.Client, path *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	dependencies := map[string]bool{}
+	for i, dependency := range r.Spec.DependsOn {
+		if dependency == r.Name {
+			allErrs = append(allErrs, field.Invalid(path.Index(i), dependency, "IntegrationTestScenario can't depend on itself"))
+		} else if dependencies[dependency] {
+			allErrs = append(allErrs, field.Duplicate(path.Index(i), dependency))
+		}
+		dependencies[dependency] = true
+	}
+	if len(allErrs) > 0 || c == nil {
+		return allErrs
+	}
+
+	scenarios := &IntegrationTestScenarioList{}
+	err := c.List(ctx, scenarios, client.InNamespace(r.Namespace))
+	if err != nil {
+		return append(allErrs, field.InternalError(path, fmt.Errorf("failed to load the IntegrationTestScenarios: %w", err)))
+	}
+
+	if cycle := r.findDependencyCycle(scenarios.Items); cycle != nil {
+		allErrs = append(allErrs, field.Invalid(path, r.Spec.DependsOn,
+			fmt.Sprintf("dependency cycle between IntegrationTestScenarios: %s", strings.Join(cycle, " -> "))))
+	}
+
+	return allErrs
+}
+
+// findDependencyCycle looks for a path of dependencies leading from the IntegrationTestScenario back to itself,
+// taking into account the given IntegrationTestScenarios of the same Application. The IntegrationTestScenarios
+// selecting Applications by label may share an Application with any other, so they are always taken into account.
+// It returns the names of the IntegrationTestScenarios on the path, or nil if there is no cycle.
+func (r *IntegrationTestScenario) findDependencyCycle(scenarios []IntegrationTestScenario) []string {
+	dependsOn := map[string][]string{}
+	for _, scenario := range scenarios {
+		if scenario.Spec.Application == r.Spec.Application || scenario.Spec.ApplicationSelector != nil ||
+			r.Spec.ApplicationSelector != nil {
+			dependsOn[scenario.Name] = scenario.Spec.DependsOn
+		}
+	}
+	dependsOn[r.Name] = r.Spec.DependsOn
+
+	visited := map[string]bool{}
+	var visit func(path []string) []string
+	visit = func(path []string) []string {
+		for _, dependency := range dependsOn[path[len(path)-1]] {
+			if dependency == r.Name {
+				return append(path, dependency)
+			}
+			if visited[dependency] {
+				continue
+			}
+			visited[dependency] = true
+			if cycle := visit(append(path, dependency)); cycle != nil {
+				return cycle
+			}
+		}
+		return nil
+	}
+
+	return visit([]string{r.Name})
+}
+
+// validateTestMatrix makes sure that the matrix params are unique and have values, that the matrix doesn't expand
+// into more than MaxMatrixCombinations combinations and that the optional combinations reference existing param values.
+func validateTestMatrix(matrix *TestMatrix, path *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	matrixValues := map[string]map[string]bool{}
+	combinations := 1
+	for i, param := range matrix.Params {
+		paramPath := path.Child("params").Index(i)
+		if _, found := matrixValues[param.Name]; found {
+			allErrs = append(allErrs, field.Duplicate(paramPath.Child("name"), param.Name))
+			continue
+		}
+		if len(param.Values) == 0 {
+			allErrs = append(allErrs, field.Required(paramPath.Child("values"), "matrix params must have at least one value"))
+		}
+		matrixValues[param.Name] = map[string]bool{}
+		for _, value := range param.Values {
+			matrixValues[param.Name][value] = true
+		}
+		combinations *= len(param.Values)
+		if combinations > MaxMatrixCombinations {
+			allErrs = append(allErrs, field.TooMany(path.Child("params"), combinations, MaxMatrixCombinations))
+			return allErrs
+		}
+	}
+
+	for i, selector := range matrix.Optional {
+		selectorPath := path.Child("optional").Index(i).Child("params")
+		if len(selector.Params) == 0 {
+			allErrs = append(allErrs, field.Required(selectorPath, "optional combinations must select at least one param value"))
+		}
+		for j, selectorParam := range selector.Params {
+			values, found := matrixValues[selectorParam.Name]
+			if !found {
+				allErrs = append(allErrs, field.NotFound(selectorPath.Index(j).Child("name"), selectorParam.Name))
+			} else if !values[selectorParam.Value] {
+				allErrs = append(allErrs, field.NotFound(selectorPath.Index(j).Child("value"), selectorParam.Value))
+			}
+		}
+	}
+
+	return allErrs
+}
+
+// ValidateParamTemplate parses the given param value as a Go template and makes sure that it only references
+// the fields listed in ParamTemplateFields. Values without templates are always valid.
+func ValidateParamTemplate(name, value string) error {
+	tmpl, err := template.New(name).Parse(value)
+	if err != nil {
+		return fmt.Errorf("failed to parse the template: %w", err)
+	}
+	if tmpl.Tree == nil {
+		return nil
+	}
+
+	return validateTemplateNode(tmpl.Tree.Root)
+}
+
+// validateTemplateNode walks the template parse tree and returns an error for the first field reference
+// which is not listed in ParamTemplateFields.
+func validateTemplateNode(node parse.Node) error {
+	var children []parse.Node
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return nil
+		}
+		children = n.Nodes
+	case *parse.ActionNode:
+		children = []parse.Node{n.Pipe}
+	case *parse.PipeNode:
+		if n == nil {
+			return nil
+		}
+		for _, cmd := range n.Cmds {
+			children = append(children, cmd)
+		}
+	case *parse.CommandNode:
+		children = n.Args
+	case *parse.ChainNode:
+		children = []parse.Node{n.Node}
+	case *parse.IfNode:
+		children = []parse.Node{n.Pipe, n.List, n.ElseList}
+	case *parse.RangeNode:
+		children = []parse.Node{n.Pipe, n.List, n.ElseList}
+	case *parse.WithNode:
+		children = []parse.Node{n.Pipe, n.List, n.ElseList}
+	case *parse.TemplateNode:
+		children = []parse.Node{n.Pipe}
+	case *parse.FieldNode:
+		return validateTemplateField(n.Ident[0])
+	case *parse.VariableNode:
+		if len(n.Ident) > 1 && n.Ident[0] == "$" {
+			return validateTemplateField(n.Ident[1])
+		}
+	}
+
+	for _, child := range children {
+		if err := validateTemplateNode(child); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validateTemplateField returns an error if the given top level field is not listed in ParamTemplateFields.
+func validateTemplateField(name string) error {
+	for _, templateField := range ParamTemplateFields {
+		if name == templateField {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("unknown template field .%s, supported fields are %v", name, ParamTemplateFields)
+}
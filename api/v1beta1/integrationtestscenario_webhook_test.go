@@ -0,0 +1,281 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("IntegrationTestScenario webhook", func() {
+
+	var (
+		validator               *integrationTestScenarioValidator
+		integrationTestScenario *IntegrationTestScenario
+	)
+
+	BeforeEach(func() {
+		validator = &integrationTestScenarioValidator{}
+		integrationTestScenario = &IntegrationTestScenario{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "integrationtestscenario",
+				Namespace: "default",
+			},
+			Spec: IntegrationTestScenarioSpec{
+				Application: "application-sample",
+				ResolverRef: ResolverRef{
+					Resolver: "git",
+					Params: []ResolverParameter{
+						{
+							Name:  "url",
+							Value: "https://github.com/redhat-appstudio/integration-examples.git",
+						},
+					},
+				},
+			},
+		}
+	})
+
+	It("accepts params with static values and supported templates", func() {
+		integrationTestScenario.Spec.Params = []PipelineParameter{
+			{
+				Name:  "static",
+				Value: "static-value",
+			},
+			{
+				Name:  "image",
+				Value: "{{ .Component.ContainerImage }}",
+			},
+			{
+				Name: "revisions",
+				Values: []string{
+					`{{ (index .Components "component-sample").Source.Revision }}`,
+					"{{ if .PullRequest.Number }}pr-{{ .PullRequest.Number }}{{ end }}",
+				},
+			},
+			{
+				Name:  "names",
+				Value: "{{ .Application.Name }}-{{ .Environment.Name }}",
+			},
+		}
+		Expect(validator.ValidateCreate(ctx, integrationTestScenario)).To(Succeed())
+		Expect(validator.ValidateUpdate(ctx, integrationTestScenario, integrationTestScenario)).To(Succeed())
+	})
+
+	It("rejects params referencing unknown template fields", func() {
+		integrationTestScenario.Spec.Params = []PipelineParameter{
+			{
+				Name:  "unknown",
+				Value: "{{ .Unknown.Field }}",
+			},
+		}
+		err := validator.ValidateCreate(ctx, integrationTestScenario)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("unknown template field .Unknown"))
+
+		integrationTestScenario.Spec.Params[0].Value = `{{ (index .Unknown "key").Field }}`
+		Expect(validator.ValidateCreate(ctx, integrationTestScenario)).NotTo(Succeed())
+	})
+
+	It("rejects params with invalid templates", func() {
+		integrationTestScenario.Spec.Params = []PipelineParameter{
+			{
+				Name:   "invalid",
+				Values: []string{"{{ .Component.ContainerImage"},
+			},
+		}
+		Expect(validator.ValidateCreate(ctx, integrationTestScenario)).NotTo(Succeed())
+	})
+
+	It("accepts params referencing a single Secret or ConfigMap key", func() {
+		integrationTestScenario.Spec.Params = []PipelineParameter{
+			{
+				Name: "secret",
+				ValueFrom: &PipelineParameterSource{
+					SecretKeyRef: &corev1.SecretKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: "secret-name"},
+						Key:                  "token",
+					},
+				},
+			},
+			{
+				Name: "configmap",
+				ValueFrom: &PipelineParameterSource{
+					ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: "configmap-name"},
+						Key:                  "url",
+					},
+				},
+			},
+		}
+		Expect(validator.ValidateCreate(ctx, integrationTestScenario)).To(Succeed())
+	})
+
+	It("rejects params with both a value and a reference or with several references", func() {
+		integrationTestScenario.Spec.Params = []PipelineParameter{
+			{
+				Name:  "both",
+				Value: "value",
+				ValueFrom: &PipelineParameterSource{
+					SecretKeyRef: &corev1.SecretKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: "secret-name"},
+						Key:                  "token",
+					},
+				},
+			},
+		}
+		Expect(validator.ValidateCreate(ctx, integrationTestScenario)).NotTo(Succeed())
+
+		integrationTestScenario.Spec.Params = []PipelineParameter{
+			{
+				Name:      "none",
+				ValueFrom: &PipelineParameterSource{},
+			},
+		}
+		Expect(validator.ValidateCreate(ctx, integrationTestScenario)).NotTo(Succeed())
+	})
+
+	It("accepts a valid matrix", func() {
+		integrationTestScenario.Spec.Matrix = &TestMatrix{
+			Params: []MatrixParameter{
+				{Name: "os", Values: []string{"rhel8", "rhel9"}},
+				{Name: "arch", Values: []string{"amd64", "arm64"}},
+			},
+			Optional: []MatrixCombinationSelector{
+				{Params: []MatrixParameterValue{{Name: "arch", Value: "arm64"}}},
+			},
+		}
+		Expect(validator.ValidateCreate(ctx, integrationTestScenario)).To(Succeed())
+	})
+
+	It("rejects invalid matrices", func() {
+		integrationTestScenario.Spec.Matrix = &TestMatrix{
+			Params: []MatrixParameter{
+				{Name: "os", Values: []string{"rhel8", "rhel9"}},
+				{Name: "os", Values: []string{"rhel7"}},
+			},
+		}
+		Expect(validator.ValidateCreate(ctx, integrationTestScenario)).NotTo(Succeed())
+
+		integrationTestScenario.Spec.Matrix = &TestMatrix{
+			Params: []MatrixParameter{
+				{Name: "os", Values: []string{"rhel8", "rhel9"}},
+			},
+			Optional: []MatrixCombinationSelector{
+				{Params: []MatrixParameterValue{{Name: "os", Value: "rhel7"}}},
+			},
+		}
+		Expect(validator.ValidateCreate(ctx, integrationTestScenario)).NotTo(Succeed())
+
+		values := make([]string, 17)
+		for i := range values {
+			values[i] = fmt.Sprintf("value-%d", i)
+		}
+		integrationTestScenario.Spec.Matrix = &TestMatrix{
+			Params: []MatrixParameter{
+				{Name: "first", Values: values},
+				{Name: "second", Values: values},
+			},
+		}
+		Expect(validator.ValidateCreate(ctx, integrationTestScenario)).NotTo(Succeed())
+	})
+
+	It("rejects a matrix together with environments", func() {
+		integrationTestScenario.Spec.Matrix = &TestMatrix{
+			Params: []MatrixParameter{
+				{Name: "os", Values: []string{"rhel8", "rhel9"}},
+			},
+		}
+		integrationTestScenario.Spec.Environment = TestEnvironment{Name: "envname", Type: "POC"}
+		Expect(validator.ValidateCreate(ctx, integrationTestScenario)).NotTo(Succeed())
+	})
+
+	It("rejects scenarios depending on themselves or on the same scenario twice", func() {
+		integrationTestScenario.Spec.DependsOn = []string{"smoke-tests"}
+		Expect(validator.ValidateCreate(ctx, integrationTestScenario)).To(Succeed())
+
+		integrationTestScenario.Spec.DependsOn = []string{integrationTestScenario.Name}
+		Expect(validator.ValidateCreate(ctx, integrationTestScenario)).NotTo(Succeed())
+
+		integrationTestScenario.Spec.DependsOn = []string{"smoke-tests", "smoke-tests"}
+		Expect(validator.ValidateCreate(ctx, integrationTestScenario)).NotTo(Succeed())
+	})
+
+	It("finds dependency cycles through the other scenarios of the Application", func() {
+		newScenario := func(name, application string, dependsOn ...string) IntegrationTestScenario {
+			return IntegrationTestScenario{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+				Spec:       IntegrationTestScenarioSpec{Application: application, DependsOn: dependsOn},
+			}
+		}
+		integrationTestScenario.Spec.DependsOn = []string{"smoke-tests"}
+
+		scenarios := []IntegrationTestScenario{
+			newScenario("smoke-tests", "application-sample", "unit-tests"),
+			newScenario("unit-tests", "application-sample"),
+			newScenario("unit-tests", "other-application", integrationTestScenario.Name),
+		}
+		Expect(integrationTestScenario.findDependencyCycle(scenarios)).To(BeNil())
+
+		scenarios[1] = newScenario("unit-tests", "application-sample", integrationTestScenario.Name)
+		Expect(integrationTestScenario.findDependencyCycle(scenarios)).To(Equal(
+			[]string{integrationTestScenario.Name, "smoke-tests", "unit-tests", integrationTestScenario.Name}))
+	})
+
+	It("accepts an application selector as an alternative to the application", func() {
+		integrationTestScenario.Spec.Application = ""
+		integrationTestScenario.Spec.ApplicationSelector = &metav1.LabelSelector{
+			MatchLabels: map[string]string{"tier": "backend"},
+		}
+		Expect(validator.ValidateCreate(ctx, integrationTestScenario)).To(Succeed())
+	})
+
+	It("rejects scenarios with both or neither of application and application selector", func() {
+		integrationTestScenario.Spec.ApplicationSelector = &metav1.LabelSelector{
+			MatchLabels: map[string]string{"tier": "backend"},
+		}
+		Expect(validator.ValidateCreate(ctx, integrationTestScenario)).NotTo(Succeed())
+
+		integrationTestScenario.Spec.Application = ""
+		integrationTestScenario.Spec.ApplicationSelector = nil
+		Expect(validator.ValidateCreate(ctx, integrationTestScenario)).NotTo(Succeed())
+	})
+
+	It("rejects invalid application selectors and scheduled scenarios selecting applications", func() {
+		integrationTestScenario.Spec.Application = ""
+		integrationTestScenario.Spec.ApplicationSelector = &metav1.LabelSelector{
+			MatchExpressions: []metav1.LabelSelectorRequirement{
+				{Key: "tier", Operator: "Unknown"},
+			},
+		}
+		Expect(validator.ValidateCreate(ctx, integrationTestScenario)).NotTo(Succeed())
+
+		integrationTestScenario.Spec.ApplicationSelector = &metav1.LabelSelector{
+			MatchLabels: map[string]string{"tier": "backend"},
+		}
+		integrationTestScenario.Spec.Schedule = "0 2 * * *"
+		Expect(validator.ValidateCreate(ctx, integrationTestScenario)).NotTo(Succeed())
+	})
+
+	It("allows deleting any IntegrationTestScenario", func() {
+		Expect(validator.ValidateDelete(ctx, integrationTestScenario)).To(Succeed())
+	})
+})
@@ -0,0 +1,113 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TestRunRecordSpec defines the summary of a finished integration PipelineRun
+type TestRunRecordSpec struct {
+	// Application is the name of the Application which was tested
+	// +required
+	Application string `json:"application"`
+	// Scenario is the name of the IntegrationTestScenario which was run
+	// +required
+	Scenario string `json:"scenario"`
+	// Snapshot is the name of the Snapshot which was tested
+	// +required
+	Snapshot string `json:"snapshot"`
+	// PipelineRun is the name of the integration PipelineRun which ran the tests
+	// +required
+	PipelineRun string `json:"pipelineRun"`
+	// MatrixParams are the param values of the matrix combination which was tested, if any
+	MatrixParams string `json:"matrixParams,omitempty"`
+	// Components are the Components of the tested Snapshot
+	Components []TestRunComponent `json:"components,omitempty"`
+	// Outcome is the outcome of the tests, either Passed or Failed
+	// +kubebuilder:validation:Enum=Passed;Failed
+	// +required
+	Outcome string `json:"outcome"`
+	// Counts are the numbers of successful, failed and warning tests reported by the tasks
+	Counts TestRunCounts `json:"counts"`
+	// Tasks are the results of the tasks which reported a test result
+	Tasks []TestRunTask `json:"tasks,omitempty"`
+	// StartTime is the time the integration PipelineRun started
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+	// CompletionTime is the time the integration PipelineRun finished
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+	// Duration is the time the integration PipelineRun took to finish
+	Duration *metav1.Duration `json:"duration,omitempty"`
+	// FailureReason describes why the tests failed
+	FailureReason string `json:"failureReason,omitempty"`
+}
+
+// TestRunComponent is a Component of the tested Snapshot
+type TestRunComponent struct {
+	// Name is the name of the Component
+	Name string `json:"name"`
+	// ContainerImage is the container image of the Component
+	ContainerImage string `json:"containerImage"`
+}
+
+// TestRunCounts are the numbers of tests reported by the tasks of an integration PipelineRun
+type TestRunCounts struct {
+	// Successes is the number of successful tests
+	Successes int `json:"successes"`
+	// Failures is the number of failed tests
+	Failures int `json:"failures"`
+	// Warnings is the number of tests with warnings
+	Warnings int `json:"warnings"`
+}
+
+// TestRunTask is the test result of a task of an integration PipelineRun
+type TestRunTask struct {
+	// Name is the name of the task in the Pipeline
+	Name string `json:"name"`
+	// Result is the result reported by the task, e.g. SUCCESS or FAILURE
+	Result string `json:"result"`
+	// Note is the note reported by the task
+	Note string `json:"note,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:printcolumn:name="Scenario",type=string,JSONPath=`.spec.scenario`
+// +kubebuilder:printcolumn:name="Snapshot",type=string,JSONPath=`.spec.snapshot`
+// +kubebuilder:printcolumn:name="Outcome",type=string,JSONPath=`.spec.outcome`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// TestRunRecord is the Schema for the testrunrecords API, it keeps the summary of a finished integration
+// PipelineRun after the PipelineRun and its TaskRuns are pruned
+type TestRunRecord struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec TestRunRecordSpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// TestRunRecordList contains a list of TestRunRecord
+type TestRunRecordList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []TestRunRecord `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&TestRunRecord{}, &TestRunRecordList{})
+}
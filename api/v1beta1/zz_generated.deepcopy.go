@@ -23,10 +23,140 @@ package v1beta1
 
 import (
 	"github.com/redhat-appstudio/application-api/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterIntegrationTestScenario) DeepCopyInto(out *ClusterIntegrationTestScenario) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterIntegrationTestScenario.
+func (in *ClusterIntegrationTestScenario) DeepCopy() *ClusterIntegrationTestScenario {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterIntegrationTestScenario)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterIntegrationTestScenario) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterIntegrationTestScenarioList) DeepCopyInto(out *ClusterIntegrationTestScenarioList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ClusterIntegrationTestScenario, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterIntegrationTestScenarioList.
+func (in *ClusterIntegrationTestScenarioList) DeepCopy() *ClusterIntegrationTestScenarioList {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterIntegrationTestScenarioList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterIntegrationTestScenarioList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterIntegrationTestScenarioSpec) DeepCopyInto(out *ClusterIntegrationTestScenarioSpec) {
+	*out = *in
+	if in.ApplicationSelector != nil {
+		in, out := &in.ApplicationSelector, &out.ApplicationSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NamespaceSelector != nil {
+		in, out := &in.NamespaceSelector, &out.NamespaceSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	in.ResolverRef.DeepCopyInto(&out.ResolverRef)
+	if in.Params != nil {
+		in, out := &in.Params, &out.Params
+		*out = make([]PipelineParameter, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Contexts != nil {
+		in, out := &in.Contexts, &out.Contexts
+		*out = make([]TestContext, len(*in))
+		copy(*out, *in)
+	}
+	if in.Matrix != nil {
+		in, out := &in.Matrix, &out.Matrix
+		*out = new(TestMatrix)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterIntegrationTestScenarioSpec.
+func (in *ClusterIntegrationTestScenarioSpec) DeepCopy() *ClusterIntegrationTestScenarioSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterIntegrationTestScenarioSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterIntegrationTestScenarioStatus) DeepCopyInto(out *ClusterIntegrationTestScenarioStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Applications != nil {
+		in, out := &in.Applications, &out.Applications
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterIntegrationTestScenarioStatus.
+func (in *ClusterIntegrationTestScenarioStatus) DeepCopy() *ClusterIntegrationTestScenarioStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterIntegrationTestScenarioStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *IntegrationTestScenario) DeepCopyInto(out *IntegrationTestScenario) {
 	*out = *in
@@ -89,6 +219,11 @@ func (in *IntegrationTestScenarioList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *IntegrationTestScenarioSpec) DeepCopyInto(out *IntegrationTestScenarioSpec) {
 	*out = *in
+	if in.ApplicationSelector != nil {
+		in, out := &in.ApplicationSelector, &out.ApplicationSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
 	in.ResolverRef.DeepCopyInto(&out.ResolverRef)
 	if in.Params != nil {
 		in, out := &in.Params, &out.Params
@@ -98,11 +233,28 @@ func (in *IntegrationTestScenarioSpec) DeepCopyInto(out *IntegrationTestScenario
 		}
 	}
 	in.Environment.DeepCopyInto(&out.Environment)
+	if in.Environments != nil {
+		in, out := &in.Environments, &out.Environments
+		*out = make([]TestEnvironment, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.Contexts != nil {
 		in, out := &in.Contexts, &out.Contexts
 		*out = make([]TestContext, len(*in))
 		copy(*out, *in)
 	}
+	if in.Matrix != nil {
+		in, out := &in.Matrix, &out.Matrix
+		*out = new(TestMatrix)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DependsOn != nil {
+		in, out := &in.DependsOn, &out.DependsOn
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IntegrationTestScenarioSpec.
@@ -125,6 +277,22 @@ func (in *IntegrationTestScenarioStatus) DeepCopyInto(out *IntegrationTestScenar
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.LastScheduleTime != nil {
+		in, out := &in.LastScheduleTime, &out.LastScheduleTime
+		*out = (*in).DeepCopy()
+	}
+	if in.ScheduledRuns != nil {
+		in, out := &in.ScheduledRuns, &out.ScheduledRuns
+		*out = make([]ScheduledRun, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Flakiness != nil {
+		in, out := &in.Flakiness, &out.Flakiness
+		*out = new(ScenarioFlakiness)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IntegrationTestScenarioStatus.
@@ -137,6 +305,61 @@ func (in *IntegrationTestScenarioStatus) DeepCopy() *IntegrationTestScenarioStat
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MatrixCombinationSelector) DeepCopyInto(out *MatrixCombinationSelector) {
+	*out = *in
+	if in.Params != nil {
+		in, out := &in.Params, &out.Params
+		*out = make([]MatrixParameterValue, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MatrixCombinationSelector.
+func (in *MatrixCombinationSelector) DeepCopy() *MatrixCombinationSelector {
+	if in == nil {
+		return nil
+	}
+	out := new(MatrixCombinationSelector)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MatrixParameter) DeepCopyInto(out *MatrixParameter) {
+	*out = *in
+	if in.Values != nil {
+		in, out := &in.Values, &out.Values
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MatrixParameter.
+func (in *MatrixParameter) DeepCopy() *MatrixParameter {
+	if in == nil {
+		return nil
+	}
+	out := new(MatrixParameter)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MatrixParameterValue) DeepCopyInto(out *MatrixParameterValue) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MatrixParameterValue.
+func (in *MatrixParameterValue) DeepCopy() *MatrixParameterValue {
+	if in == nil {
+		return nil
+	}
+	out := new(MatrixParameterValue)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PipelineParameter) DeepCopyInto(out *PipelineParameter) {
 	*out = *in
@@ -145,6 +368,11 @@ func (in *PipelineParameter) DeepCopyInto(out *PipelineParameter) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.ValueFrom != nil {
+		in, out := &in.ValueFrom, &out.ValueFrom
+		*out = new(PipelineParameterSource)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PipelineParameter.
@@ -157,6 +385,31 @@ func (in *PipelineParameter) DeepCopy() *PipelineParameter {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PipelineParameterSource) DeepCopyInto(out *PipelineParameterSource) {
+	*out = *in
+	if in.SecretKeyRef != nil {
+		in, out := &in.SecretKeyRef, &out.SecretKeyRef
+		*out = new(corev1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ConfigMapKeyRef != nil {
+		in, out := &in.ConfigMapKeyRef, &out.ConfigMapKeyRef
+		*out = new(corev1.ConfigMapKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PipelineParameterSource.
+func (in *PipelineParameterSource) DeepCopy() *PipelineParameterSource {
+	if in == nil {
+		return nil
+	}
+	out := new(PipelineParameterSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ResolverParameter) DeepCopyInto(out *ResolverParameter) {
 	*out = *in
@@ -192,6 +445,93 @@ func (in *ResolverRef) DeepCopy() *ResolverRef {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScenarioFlakiness) DeepCopyInto(out *ScenarioFlakiness) {
+	*out = *in
+	if in.Tasks != nil {
+		in, out := &in.Tasks, &out.Tasks
+		*out = make([]TaskFlakiness, len(*in))
+		copy(*out, *in)
+	}
+	if in.Runs != nil {
+		in, out := &in.Runs, &out.Runs
+		*out = make([]ScenarioRun, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScenarioFlakiness.
+func (in *ScenarioFlakiness) DeepCopy() *ScenarioFlakiness {
+	if in == nil {
+		return nil
+	}
+	out := new(ScenarioFlakiness)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScenarioRun) DeepCopyInto(out *ScenarioRun) {
+	*out = *in
+	if in.PassedTasks != nil {
+		in, out := &in.PassedTasks, &out.PassedTasks
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.FailedTasks != nil {
+		in, out := &in.FailedTasks, &out.FailedTasks
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScenarioRun.
+func (in *ScenarioRun) DeepCopy() *ScenarioRun {
+	if in == nil {
+		return nil
+	}
+	out := new(ScenarioRun)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScheduledRun) DeepCopyInto(out *ScheduledRun) {
+	*out = *in
+	in.ScheduleTime.DeepCopyInto(&out.ScheduleTime)
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScheduledRun.
+func (in *ScheduledRun) DeepCopy() *ScheduledRun {
+	if in == nil {
+		return nil
+	}
+	out := new(ScheduledRun)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TaskFlakiness) DeepCopyInto(out *TaskFlakiness) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TaskFlakiness.
+func (in *TaskFlakiness) DeepCopy() *TaskFlakiness {
+	if in == nil {
+		return nil
+	}
+	out := new(TaskFlakiness)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TestContext) DeepCopyInto(out *TestContext) {
 	*out = *in
@@ -207,6 +547,35 @@ func (in *TestContext) DeepCopy() *TestContext {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TestMatrix) DeepCopyInto(out *TestMatrix) {
+	*out = *in
+	if in.Params != nil {
+		in, out := &in.Params, &out.Params
+		*out = make([]MatrixParameter, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Optional != nil {
+		in, out := &in.Optional, &out.Optional
+		*out = make([]MatrixCombinationSelector, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TestMatrix.
+func (in *TestMatrix) DeepCopy() *TestMatrix {
+	if in == nil {
+		return nil
+	}
+	out := new(TestMatrix)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TestEnvironment) DeepCopyInto(out *TestEnvironment) {
 	*out = *in
@@ -226,3 +595,145 @@ func (in *TestEnvironment) DeepCopy() *TestEnvironment {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TestRunComponent) DeepCopyInto(out *TestRunComponent) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TestRunComponent.
+func (in *TestRunComponent) DeepCopy() *TestRunComponent {
+	if in == nil {
+		return nil
+	}
+	out := new(TestRunComponent)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TestRunCounts) DeepCopyInto(out *TestRunCounts) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TestRunCounts.
+func (in *TestRunCounts) DeepCopy() *TestRunCounts {
+	if in == nil {
+		return nil
+	}
+	out := new(TestRunCounts)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TestRunRecord) DeepCopyInto(out *TestRunRecord) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TestRunRecord.
+func (in *TestRunRecord) DeepCopy() *TestRunRecord {
+	if in == nil {
+		return nil
+	}
+	out := new(TestRunRecord)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *TestRunRecord) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TestRunRecordList) DeepCopyInto(out *TestRunRecordList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]TestRunRecord, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TestRunRecordList.
+func (in *TestRunRecordList) DeepCopy() *TestRunRecordList {
+	if in == nil {
+		return nil
+	}
+	out := new(TestRunRecordList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *TestRunRecordList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TestRunRecordSpec) DeepCopyInto(out *TestRunRecordSpec) {
+	*out = *in
+	if in.Components != nil {
+		in, out := &in.Components, &out.Components
+		*out = make([]TestRunComponent, len(*in))
+		copy(*out, *in)
+	}
+	out.Counts = in.Counts
+	if in.Tasks != nil {
+		in, out := &in.Tasks, &out.Tasks
+		*out = make([]TestRunTask, len(*in))
+		copy(*out, *in)
+	}
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Duration != nil {
+		in, out := &in.Duration, &out.Duration
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TestRunRecordSpec.
+func (in *TestRunRecordSpec) DeepCopy() *TestRunRecordSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(TestRunRecordSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TestRunTask) DeepCopyInto(out *TestRunTask) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TestRunTask.
+func (in *TestRunTask) DeepCopy() *TestRunTask {
+	if in == nil {
+		return nil
+	}
+	out := new(TestRunTask)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -23,6 +23,7 @@ import (
 	"github.com/redhat-appstudio/integration-service/api/v1beta1"
 
 	releasev1alpha1 "github.com/redhat-appstudio/release-service/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -87,12 +88,82 @@ func SetupSnapshotCache(mgr ctrl.Manager) error {
 		"spec.application", snapshotIndexFunc)
 }
 
+// ApplicationSelectorIndexValue is the value the IntegrationTestScenarios selecting their Applications by label
+// are indexed under, it can't collide with the name of an Application.
+const ApplicationSelectorIndexValue = "@application-selector"
+
 // SetupIntegrationTestScenarioCache adds a new index field to be able to search IntegrationTestScenarios by Application.
+// The IntegrationTestScenarios selecting their Applications by label are indexed under ApplicationSelectorIndexValue.
 func SetupIntegrationTestScenarioCache(mgr ctrl.Manager) error {
 	integrationTestScenariosIndexFunc := func(obj client.Object) []string {
-		return []string{obj.(*v1beta1.IntegrationTestScenario).Spec.Application}
+		integrationTestScenario := obj.(*v1beta1.IntegrationTestScenario)
+		if integrationTestScenario.Spec.ApplicationSelector != nil {
+			return []string{ApplicationSelectorIndexValue}
+		}
+		return []string{integrationTestScenario.Spec.Application}
 	}
 
 	return mgr.GetCache().IndexField(context.Background(), &v1beta1.IntegrationTestScenario{},
 		"spec.application", integrationTestScenariosIndexFunc)
 }
+
+// AnyLabelsIndexValue is the value the ClusterIntegrationTestScenarios whose selectors can match objects without
+// any of the labels they reference are indexed under, it can't collide with a label key.
+const AnyLabelsIndexValue = "@any-labels"
+
+// SetupClusterIntegrationTestScenarioCache adds new index fields to be able to search ClusterIntegrationTestScenarios
+// by the Applications inheriting them, in the namespace/name format, and by the label keys their application and
+// namespace selectors reference.
+func SetupClusterIntegrationTestScenarioCache(mgr ctrl.Manager) error {
+	applicationsIndexFunc := func(obj client.Object) []string {
+		return obj.(*v1beta1.ClusterIntegrationTestScenario).Status.Applications
+	}
+	err := mgr.GetCache().IndexField(context.Background(), &v1beta1.ClusterIntegrationTestScenario{},
+		"status.applications", applicationsIndexFunc)
+	if err != nil {
+		return err
+	}
+
+	applicationSelectorIndexFunc := func(obj client.Object) []string {
+		return getLabelSelectorIndexValues(obj.(*v1beta1.ClusterIntegrationTestScenario).Spec.ApplicationSelector)
+	}
+	err = mgr.GetCache().IndexField(context.Background(), &v1beta1.ClusterIntegrationTestScenario{},
+		"spec.applicationSelector", applicationSelectorIndexFunc)
+	if err != nil {
+		return err
+	}
+
+	namespaceSelectorIndexFunc := func(obj client.Object) []string {
+		return getLabelSelectorIndexValues(obj.(*v1beta1.ClusterIntegrationTestScenario).Spec.NamespaceSelector)
+	}
+
+	return mgr.GetCache().IndexField(context.Background(), &v1beta1.ClusterIntegrationTestScenario{},
+		"spec.namespaceSelector", namespaceSelectorIndexFunc)
+}
+
+// getLabelSelectorIndexValues returns the label keys the label selector references. A missing selector or a selector
+// without a requirement a label has to be set for, e.g. only NotIn and DoesNotExist expressions, can match objects
+// without any of those labels, so it's indexed under AnyLabelsIndexValue as well.
+func getLabelSelectorIndexValues(labelSelector *metav1.LabelSelector) []string {
+	if labelSelector == nil {
+		return []string{AnyLabelsIndexValue}
+	}
+
+	values := []string{}
+	requiresLabel := false
+	for key := range labelSelector.MatchLabels {
+		values = append(values, key)
+		requiresLabel = true
+	}
+	for _, requirement := range labelSelector.MatchExpressions {
+		values = append(values, requirement.Key)
+		if requirement.Operator == metav1.LabelSelectorOpIn || requirement.Operator == metav1.LabelSelectorOpExists {
+			requiresLabel = true
+		}
+	}
+	if !requiresLabel {
+		values = append(values, AnyLabelsIndexValue)
+	}
+
+	return values
+}
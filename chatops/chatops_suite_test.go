@@ -0,0 +1,13 @@
+package chatops_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestChatOps(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "ChatOps Suite")
+}
@@ -0,0 +1,68 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package chatops contains the handling of the commands developers post as comments on their pull requests
+package chatops
+
+import (
+	"strings"
+)
+
+const (
+	// RetestCommand reruns the integration PipelineRuns of the latest Snapshot of the pull request, optionally
+	// only the ones of the IntegrationTestScenario named in its argument.
+	RetestCommand = "retest"
+
+	// SkipCommand skips the optional IntegrationTestScenario named in its argument for the latest Snapshot
+	// of the pull request.
+	SkipCommand = "skip"
+
+	// ApproveCommand approves the promotion of the latest Snapshot of the pull request.
+	ApproveCommand = "approve"
+)
+
+// Command is a command found in a pull request comment.
+type Command struct {
+	// Name of the command, without the leading slash
+	Name string
+	// Args are the whitespace separated arguments following the name of the command
+	Args []string
+}
+
+// String returns the command as it was written in the comment.
+func (c Command) String() string {
+	return strings.Join(append([]string{"/" + c.Name}, c.Args...), " ")
+}
+
+// ParseCommands returns the supported commands found in the body of a comment. Commands have to start a line
+// with a slash followed by the name of the command, lines with unsupported commands are ignored.
+func ParseCommands(body string) []Command {
+	commands := []Command{}
+	for _, line := range strings.Split(body, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || !strings.HasPrefix(fields[0], "/") {
+			continue
+		}
+
+		name := strings.ToLower(strings.TrimPrefix(fields[0], "/"))
+		switch name {
+		case RetestCommand, SkipCommand, ApproveCommand:
+			commands = append(commands, Command{Name: name, Args: fields[1:]})
+		}
+	}
+
+	return commands
+}
@@ -0,0 +1,41 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chatops_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-appstudio/integration-service/chatops"
+)
+
+var _ = Describe("ParseCommands", func() {
+	It("finds the supported commands starting a line", func() {
+		commands := chatops.ParseCommands("Looks good to me\n/retest\n  /skip flaky-tests\r\n/lgtm\nplease /approve\n/Approve")
+		Expect(commands).To(Equal([]chatops.Command{
+			{Name: chatops.RetestCommand, Args: []string{}},
+			{Name: chatops.SkipCommand, Args: []string{"flaky-tests"}},
+			{Name: chatops.ApproveCommand, Args: []string{}},
+		}))
+		Expect(commands[1].String()).To(Equal("/skip flaky-tests"))
+	})
+
+	It("ignores comments without commands", func() {
+		Expect(chatops.ParseCommands("")).To(BeEmpty())
+		Expect(chatops.ParseCommands("Thanks for the review")).To(BeEmpty())
+	})
+})
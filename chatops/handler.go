@@ -0,0 +1,348 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chatops
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-logr/logr"
+	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	"github.com/redhat-appstudio/integration-service/api/v1beta1"
+	"github.com/redhat-appstudio/integration-service/git/github"
+	"github.com/redhat-appstudio/integration-service/gitops"
+	"github.com/redhat-appstudio/integration-service/helpers"
+	"github.com/redhat-appstudio/integration-service/loader"
+	"github.com/redhat-appstudio/integration-service/status"
+	"github.com/redhat-appstudio/integration-service/tekton"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// allowedPermissions are the repository permissions a commenter needs to run the commands.
+var allowedPermissions = map[string]bool{
+	"admin": true,
+	"write": true,
+}
+
+// PullRequestComment is a comment posted on a pull request.
+type PullRequestComment struct {
+	// Owner of the repository of the pull request
+	Owner string
+	// Repository of the pull request
+	Repository string
+	// RepositoryURL is the URL of the repository of the pull request
+	RepositoryURL string
+	// PullRequest is the number of the pull request
+	PullRequest int
+	// Author is the login of the user who posted the comment
+	Author string
+	// Body of the comment
+	Body string
+}
+
+// Handler runs the commands found in pull request comments against the latest Snapshots of the pull request
+// and acknowledges each of them with a comment reply.
+type Handler struct {
+	logger   logr.Logger
+	client   client.Client
+	loader   loader.ObjectLoader
+	ghClient github.ClientInterface
+}
+
+// HandlerOption is used to extend Handler with optional parameters.
+type HandlerOption = func(h *Handler)
+
+// WithLoader is an option which allows for replacement of the loader used to load the Snapshots.
+func WithLoader(loader loader.ObjectLoader) HandlerOption {
+	return func(h *Handler) {
+		h.loader = loader
+	}
+}
+
+// WithGitHubClient is an option which allows for replacement of the client used to talk to GitHub.
+// By default, a new client is created for each comment since the client holds the token of the repository.
+func WithGitHubClient(ghClient github.ClientInterface) HandlerOption {
+	return func(h *Handler) {
+		h.ghClient = ghClient
+	}
+}
+
+// NewHandler constructs a Handler with optional params, if specified.
+func NewHandler(logger logr.Logger, client client.Client, opts ...HandlerOption) *Handler {
+	handler := Handler{
+		logger: logger,
+		client: client,
+		loader: loader.NewLoader(),
+	}
+
+	for _, opt := range opts {
+		opt(&handler)
+	}
+
+	return &handler
+}
+
+// HandleComment runs the commands found in the comment against the latest Snapshot of each Application and
+// Component of its pull request, once it made sure the author of the comment can write to the repository. Only the
+// Snapshots of the namespaces bound to the repository through a Pipelines as Code Repository are considered. Every
+// command is acknowledged with a comment reply on the pull request, listing its outcome for each Snapshot. Comments
+// without commands and pull requests without Snapshots are ignored.
+func (h *Handler) HandleComment(ctx context.Context, comment *PullRequestComment) error {
+	commands := ParseCommands(comment.Body)
+	if len(commands) == 0 {
+		return nil
+	}
+	log := h.logger.WithValues("owner", comment.Owner, "repository", comment.Repository,
+		"pullRequest", comment.PullRequest, "author", comment.Author)
+
+	pullRequestSnapshots, err := h.getPullRequestSnapshots(ctx, comment)
+	if err != nil {
+		return err
+	}
+	if len(pullRequestSnapshots) == 0 {
+		log.Info("Found no Snapshots for the pull request, ignoring its commands")
+		return nil
+	}
+	snapshots := getLatestSnapshots(pullRequestSnapshots)
+
+	// The credentials used to reply are the ones Pipelines as Code uses for the repository of the Snapshots
+	ghClient := h.ghClient
+	if ghClient == nil {
+		ghClient = github.NewClient(h.logger)
+	}
+	err = status.NewGitHubReporter(h.logger, h.client, status.WithGitHubClient(ghClient)).Authenticate(ctx, snapshots[0])
+	if err != nil {
+		return err
+	}
+
+	permission, err := ghClient.GetUserPermission(ctx, comment.Owner, comment.Repository, comment.Author)
+	if err != nil {
+		return err
+	}
+
+	// All the commands are acknowledged even if one of them fails, the first error is returned afterwards
+	var firstErr error
+	for _, command := range commands {
+		var reply string
+		if !allowedPermissions[permission] {
+			log.Info("Refusing the command of a user without write permission", "command", command.String(), "permission", permission)
+			reply = fmt.Sprintf("@%s only users with write permission on the repository can run `%s`.", comment.Author, command)
+		} else {
+			replies := []string{}
+			for _, snapshot := range snapshots {
+				snapshotReply, err := h.runCommand(ctx, snapshot, comment, command)
+				if err != nil {
+					log.Error(err, "Failed to run the command", "command", command.String(), "snapshot.Name", snapshot.Name)
+					snapshotReply = fmt.Sprintf("@%s `%s` failed for Snapshot %s, please try again later.", comment.Author, command, snapshot.Name)
+					if firstErr == nil {
+						firstErr = err
+					}
+				} else {
+					log.Info("Ran the command", "command", command.String(), "snapshot.Name", snapshot.Name)
+				}
+				replies = append(replies, snapshotReply)
+			}
+			reply = strings.Join(replies, "\n")
+		}
+
+		_, err = ghClient.CreateComment(ctx, comment.Owner, comment.Repository, comment.PullRequest, reply)
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return firstErr
+}
+
+// getPullRequestSnapshots returns the Snapshots of the pull request in the namespaces bound to its repository
+// through a Pipelines as Code Repository.
+func (h *Handler) getPullRequestSnapshots(ctx context.Context, comment *PullRequestComment) ([]applicationapiv1alpha1.Snapshot, error) {
+	repositories, err := h.loader.GetAllRepositoriesForURL(h.client, ctx, comment.RepositoryURL)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshots := []applicationapiv1alpha1.Snapshot{}
+	searchedNamespaces := map[string]bool{}
+	for _, repository := range *repositories {
+		if searchedNamespaces[repository.Namespace] {
+			continue
+		}
+		searchedNamespaces[repository.Namespace] = true
+
+		namespaceSnapshots, err := h.loader.GetAllSnapshotsForPullRequest(h.client, ctx, repository.Namespace,
+			comment.Owner, comment.Repository, strconv.Itoa(comment.PullRequest))
+		if err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, *namespaceSnapshots...)
+	}
+
+	return snapshots, nil
+}
+
+// runCommand runs the command against the Snapshot and returns the reply acknowledging it.
+func (h *Handler) runCommand(ctx context.Context, snapshot *applicationapiv1alpha1.Snapshot, comment *PullRequestComment, command Command) (string, error) {
+	switch command.Name {
+	case RetestCommand:
+		return h.retest(ctx, snapshot, comment, command)
+	case SkipCommand:
+		return h.skip(ctx, snapshot, comment, command)
+	case ApproveCommand:
+		return h.approve(ctx, snapshot, comment)
+	}
+
+	return "", fmt.Errorf("unsupported command %q", command.Name)
+}
+
+// retest requests a rerun of the IntegrationTestScenario named in the command, or of all the IntegrationTestScenarios
+// testing the Snapshot if none is named.
+func (h *Handler) retest(ctx context.Context, snapshot *applicationapiv1alpha1.Snapshot, comment *PullRequestComment, command Command) (string, error) {
+	integrationTestScenarios, err := h.getIntegrationTestScenarios(ctx, snapshot)
+	if err != nil {
+		return "", err
+	}
+
+	scenarioNames := []string{}
+	if len(command.Args) > 0 {
+		integrationTestScenario := findIntegrationTestScenario(integrationTestScenarios, command.Args[0])
+		if integrationTestScenario == nil {
+			return fmt.Sprintf("@%s IntegrationTestScenario %s doesn't test Snapshot %s.", comment.Author, command.Args[0], snapshot.Name), nil
+		}
+		if gitops.IsIntegrationTestScenarioSkipped(snapshot, integrationTestScenario.Name) {
+			return fmt.Sprintf("@%s IntegrationTestScenario %s was skipped for Snapshot %s and can't be rerun.",
+				comment.Author, integrationTestScenario.Name, snapshot.Name), nil
+		}
+		scenarioNames = append(scenarioNames, integrationTestScenario.Name)
+	} else {
+		for _, integrationTestScenario := range *integrationTestScenarios {
+			if !gitops.IsIntegrationTestScenarioSkipped(snapshot, integrationTestScenario.Name) {
+				scenarioNames = append(scenarioNames, integrationTestScenario.Name)
+			}
+		}
+	}
+	if len(scenarioNames) == 0 {
+		return fmt.Sprintf("@%s Snapshot %s has no IntegrationTestScenarios to rerun.", comment.Author, snapshot.Name), nil
+	}
+
+	err = gitops.RequestIntegrationTestScenariosRerun(h.client, ctx, snapshot, scenarioNames)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("@%s rerunning IntegrationTestScenarios %s for Snapshot %s.",
+		comment.Author, strings.Join(scenarioNames, ", "), snapshot.Name), nil
+}
+
+// skip marks the optional IntegrationTestScenario named in the command as skipped for the Snapshot.
+// Quarantined IntegrationTestScenarios can't block the Snapshot either, so they can be skipped too.
+func (h *Handler) skip(ctx context.Context, snapshot *applicationapiv1alpha1.Snapshot, comment *PullRequestComment, command Command) (string, error) {
+	if len(command.Args) == 0 {
+		return fmt.Sprintf("@%s `/%s` needs the name of the IntegrationTestScenario to skip.", comment.Author, SkipCommand), nil
+	}
+
+	integrationTestScenarios, err := h.getIntegrationTestScenarios(ctx, snapshot)
+	if err != nil {
+		return "", err
+	}
+	integrationTestScenario := findIntegrationTestScenario(integrationTestScenarios, command.Args[0])
+	if integrationTestScenario == nil {
+		return fmt.Sprintf("@%s IntegrationTestScenario %s doesn't test Snapshot %s.", comment.Author, command.Args[0], snapshot.Name), nil
+	}
+	if !helpers.HasLabelWithValue(integrationTestScenario, tekton.OptionalLabel, "true") &&
+		!gitops.IsIntegrationTestScenarioQuarantined(integrationTestScenario) {
+		return fmt.Sprintf("@%s IntegrationTestScenario %s is required and can't be skipped.", comment.Author, integrationTestScenario.Name), nil
+	}
+
+	err = gitops.MarkIntegrationTestScenariosAsSkipped(h.client, ctx, snapshot, []v1beta1.IntegrationTestScenario{*integrationTestScenario})
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("@%s skipped IntegrationTestScenario %s for Snapshot %s.", comment.Author, integrationTestScenario.Name, snapshot.Name), nil
+}
+
+// approve approves the promotion of the Snapshot to the ReleasePlans and Environments requiring approval.
+func (h *Handler) approve(ctx context.Context, snapshot *applicationapiv1alpha1.Snapshot, comment *PullRequestComment) (string, error) {
+	if gitops.IsSnapshotApproved(snapshot) {
+		return fmt.Sprintf("@%s Snapshot %s was already approved by @%s.",
+			comment.Author, snapshot.Name, snapshot.GetAnnotations()[gitops.SnapshotApprovedByAnnotation]), nil
+	}
+
+	err := gitops.ApproveSnapshot(h.client, ctx, snapshot, comment.Author)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("@%s approved Snapshot %s.", comment.Author, snapshot.Name), nil
+}
+
+// getIntegrationTestScenarios returns the IntegrationTestScenarios of the Application of the Snapshot which test it.
+func (h *Handler) getIntegrationTestScenarios(ctx context.Context, snapshot *applicationapiv1alpha1.Snapshot) (*[]v1beta1.IntegrationTestScenario, error) {
+	application, err := h.loader.GetApplicationFromSnapshot(h.client, ctx, snapshot)
+	if err != nil {
+		return nil, err
+	}
+	integrationTestScenarios, err := h.loader.GetAllIntegrationTestScenariosForApplication(h.client, ctx, application)
+	if err != nil {
+		return nil, err
+	}
+
+	return gitops.GetIntegrationTestScenariosForSnapshot(snapshot, integrationTestScenarios), nil
+}
+
+// findIntegrationTestScenario returns the IntegrationTestScenario with the given name from the list, or nil if there is none.
+func findIntegrationTestScenario(integrationTestScenarios *[]v1beta1.IntegrationTestScenario, name string) *v1beta1.IntegrationTestScenario {
+	for i := range *integrationTestScenarios {
+		if (*integrationTestScenarios)[i].Name == name {
+			return &(*integrationTestScenarios)[i]
+		}
+	}
+
+	return nil
+}
+
+// getLatestSnapshots returns the most recently created Snapshot of each Application and Component among the
+// Snapshots, the Applications of different namespaces being told apart. The Snapshots are sorted by namespace,
+// Application and Component.
+func getLatestSnapshots(snapshots []applicationapiv1alpha1.Snapshot) []*applicationapiv1alpha1.Snapshot {
+	latestSnapshots := map[string]*applicationapiv1alpha1.Snapshot{}
+	for i := range snapshots {
+		snapshot := &snapshots[i]
+		key := strings.Join([]string{snapshot.Namespace, snapshot.Spec.Application,
+			snapshot.GetLabels()[gitops.SnapshotComponentLabel]}, "/")
+		if latestSnapshot, found := latestSnapshots[key]; !found ||
+			latestSnapshot.CreationTimestamp.Before(&snapshot.CreationTimestamp) {
+			latestSnapshots[key] = snapshot
+		}
+	}
+
+	keys := make([]string, 0, len(latestSnapshots))
+	for key := range latestSnapshots {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	sortedSnapshots := make([]*applicationapiv1alpha1.Snapshot, 0, len(keys))
+	for _, key := range keys {
+		sortedSnapshots = append(sortedSnapshots, latestSnapshots[key].DeepCopy())
+	}
+
+	return sortedSnapshots
+}
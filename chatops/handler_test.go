@@ -0,0 +1,344 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chatops_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/go-logr/logr"
+	ghapi "github.com/google/go-github/v45/github"
+	pacv1alpha1 "github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	"github.com/redhat-appstudio/integration-service/api/v1beta1"
+	"github.com/redhat-appstudio/integration-service/chatops"
+	"github.com/redhat-appstudio/integration-service/git/github"
+	"github.com/redhat-appstudio/integration-service/gitops"
+	"github.com/redhat-appstudio/integration-service/loader"
+	"github.com/redhat-appstudio/integration-service/tekton"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+type MockGitHubClient struct {
+	permission string
+	comments   []string
+}
+
+func (c *MockGitHubClient) CreateAppInstallationToken(ctx context.Context, appID int64, installationID int64, privateKey []byte) (string, error) {
+	return "example-token", nil
+}
+
+func (c *MockGitHubClient) SetOAuthToken(ctx context.Context, token string) {}
+
+func (c *MockGitHubClient) CreateCheckRun(ctx context.Context, cra *github.CheckRunAdapter) (*int64, error) {
+	return nil, nil
+}
+
+func (c *MockGitHubClient) UpdateCheckRun(ctx context.Context, checkRunID int64, cra *github.CheckRunAdapter) error {
+	return nil
+}
+
+func (c *MockGitHubClient) GetCheckRunID(context.Context, string, string, string, string, int64) (*int64, error) {
+	return nil, nil
+}
+
+func (c *MockGitHubClient) CreateComment(ctx context.Context, owner string, repo string, issueNumber int, body string) (int64, error) {
+	c.comments = append(c.comments, body)
+	return int64(len(c.comments)), nil
+}
+
+func (c *MockGitHubClient) ListComments(ctx context.Context, owner string, repo string, issueNumber int) ([]*ghapi.IssueComment, error) {
+	return nil, nil
+}
+
+func (c *MockGitHubClient) GetCommentIDWithMarker(ctx context.Context, owner string, repo string, issueNumber int, marker string) (*int64, error) {
+	return nil, nil
+}
+
+func (c *MockGitHubClient) GetDeploymentID(ctx context.Context, owner string, repo string, ref string, environment string) (*int64, error) {
+	return nil, nil
+}
+
+func (c *MockGitHubClient) CreateDeployment(ctx context.Context, owner string, repo string, ref string, environment string, description string) (int64, error) {
+	return 0, nil
+}
+
+func (c *MockGitHubClient) CreateDeploymentStatus(ctx context.Context, owner string, repo string, deploymentID int64, state string, description string) (int64, error) {
+	return 0, nil
+}
+
+func (c *MockGitHubClient) EditComment(ctx context.Context, owner string, repo string, commentID int64, body string) (int64, error) {
+	return commentID, nil
+}
+
+func (c *MockGitHubClient) CreateCommitStatus(ctx context.Context, owner string, repo string, SHA string, state string, description string, statusContext string) (int64, error) {
+	return 0, nil
+}
+
+func (c *MockGitHubClient) GetUserPermission(ctx context.Context, owner string, repo string, user string) (string, error) {
+	return c.permission, nil
+}
+
+type MockK8sClient struct {
+	patched []client.Object
+}
+
+func (c *MockK8sClient) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	return nil
+}
+
+func (c *MockK8sClient) Get(ctx context.Context, key types.NamespacedName, obj client.Object, opts ...client.GetOption) error {
+	if secret, ok := obj.(*v1.Secret); ok {
+		secret.Data = map[string][]byte{
+			"github-application-id": []byte("1234"),
+			"github-private-key":    []byte("example-private-key"),
+		}
+	}
+	return nil
+}
+
+func (c *MockK8sClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	return nil
+}
+
+func (c *MockK8sClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	return nil
+}
+
+func (c *MockK8sClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	c.patched = append(c.patched, obj)
+	return nil
+}
+
+func (c *MockK8sClient) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	return nil
+}
+
+func (c *MockK8sClient) DeleteAllOf(ctx context.Context, obj client.Object, opts ...client.DeleteAllOfOption) error {
+	return nil
+}
+
+func (c *MockK8sClient) Status() client.SubResourceWriter {
+	panic("implement me")
+}
+
+func (c *MockK8sClient) SubResource(subResource string) client.SubResourceClient {
+	panic("implement me")
+}
+
+func (c *MockK8sClient) Scheme() *runtime.Scheme {
+	panic("implement me")
+}
+
+func (c *MockK8sClient) RESTMapper() meta.RESTMapper {
+	panic("implement me")
+}
+
+var _ = Describe("Handler", func() {
+
+	var (
+		handler          *chatops.Handler
+		mockGitHubClient *MockGitHubClient
+		mockK8sClient    *MockK8sClient
+		mockedContext    context.Context
+		latestSnapshot   *applicationapiv1alpha1.Snapshot
+		olderSnapshot    *applicationapiv1alpha1.Snapshot
+	)
+
+	newScenario := func(name string, labels map[string]string) v1beta1.IntegrationTestScenario {
+		return v1beta1.IntegrationTestScenario{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+				Labels:    labels,
+			},
+			Spec: v1beta1.IntegrationTestScenarioSpec{
+				Application: "application-sample",
+			},
+		}
+	}
+
+	newComment := func(body string) *chatops.PullRequestComment {
+		return &chatops.PullRequestComment{
+			Owner:         "devfile-sample",
+			Repository:    "devfile-sample-go-basic",
+			RepositoryURL: "https://github.com/devfile-sample/devfile-sample-go-basic",
+			PullRequest:   1,
+			Author:        "developer",
+			Body:          body,
+		}
+	}
+
+	BeforeEach(func() {
+		now := time.Now()
+		latestSnapshot = &applicationapiv1alpha1.Snapshot{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              "snapshot-latest",
+				Namespace:         "default",
+				CreationTimestamp: metav1.NewTime(now),
+				Labels: map[string]string{
+					gitops.PipelineAsCodeEventTypeLabel:     gitops.PipelineAsCodePullRequestType,
+					gitops.PipelineAsCodeURLOrgLabel:        "devfile-sample",
+					gitops.PipelineAsCodeURLRepositoryLabel: "devfile-sample-go-basic",
+				},
+				Annotations: map[string]string{
+					gitops.PipelineAsCodeInstallationIDAnnotation: "123",
+					gitops.PipelineAsCodePullRequestAnnotation:    "1",
+				},
+			},
+			Spec: applicationapiv1alpha1.SnapshotSpec{
+				Application: "application-sample",
+			},
+		}
+		olderSnapshot = latestSnapshot.DeepCopy()
+		olderSnapshot.Name = "snapshot-older"
+		olderSnapshot.CreationTimestamp = metav1.NewTime(now.Add(-time.Hour))
+
+		mockGitHubClient = &MockGitHubClient{permission: "write"}
+		mockK8sClient = &MockK8sClient{}
+		mockedContext = loader.GetMockedContext(context.Background(), []loader.MockData{
+			{
+				ContextKey: loader.RepositoriesContextKey,
+				Resource: []pacv1alpha1.Repository{
+					{ObjectMeta: metav1.ObjectMeta{Name: "repository-sample", Namespace: "default"}},
+				},
+			},
+			{
+				ContextKey: loader.AllSnapshotsContextKey,
+				Resource:   []applicationapiv1alpha1.Snapshot{*olderSnapshot, *latestSnapshot},
+			},
+			{
+				ContextKey: loader.ApplicationContextKey,
+				Resource: &applicationapiv1alpha1.Application{
+					ObjectMeta: metav1.ObjectMeta{Name: "application-sample", Namespace: "default"},
+				},
+			},
+			{
+				ContextKey: loader.AllIntegrationTestScenariosContextKey,
+				Resource: []v1beta1.IntegrationTestScenario{
+					newScenario("e2e-tests", nil),
+					newScenario("flaky-tests", map[string]string{tekton.OptionalLabel: "true"}),
+				},
+			},
+		})
+		handler = chatops.NewHandler(logr.Discard(), mockK8sClient,
+			chatops.WithLoader(loader.NewMockLoader()), chatops.WithGitHubClient(mockGitHubClient))
+	})
+
+	getPatchedSnapshot := func() *applicationapiv1alpha1.Snapshot {
+		Expect(mockK8sClient.patched).To(HaveLen(1))
+		snapshot, ok := mockK8sClient.patched[0].(*applicationapiv1alpha1.Snapshot)
+		Expect(ok).To(BeTrue())
+		Expect(snapshot.Name).To(Equal(latestSnapshot.Name))
+		return snapshot
+	}
+
+	It("requests a rerun of all the scenarios of the latest Snapshot", func() {
+		Expect(handler.HandleComment(mockedContext, newComment("/retest"))).To(Succeed())
+		snapshot := getPatchedSnapshot()
+		Expect(gitops.GetRequestedRerunIntegrationTestScenarios(snapshot)).To(Equal([]string{"e2e-tests", "flaky-tests"}))
+		Expect(mockGitHubClient.comments).To(Equal([]string{
+			"@developer rerunning IntegrationTestScenarios e2e-tests, flaky-tests for Snapshot snapshot-latest.",
+		}))
+	})
+
+	It("requests a rerun of a single scenario", func() {
+		Expect(handler.HandleComment(mockedContext, newComment("/retest e2e-tests"))).To(Succeed())
+		Expect(gitops.GetRequestedRerunIntegrationTestScenarios(getPatchedSnapshot())).To(Equal([]string{"e2e-tests"}))
+
+		mockGitHubClient.comments = nil
+		Expect(handler.HandleComment(mockedContext, newComment("/retest unknown-tests"))).To(Succeed())
+		Expect(mockGitHubClient.comments).To(Equal([]string{
+			"@developer IntegrationTestScenario unknown-tests doesn't test Snapshot snapshot-latest.",
+		}))
+	})
+
+	It("only skips optional scenarios", func() {
+		Expect(handler.HandleComment(mockedContext, newComment("/skip e2e-tests\n/skip flaky-tests"))).To(Succeed())
+		Expect(gitops.IsIntegrationTestScenarioSkipped(getPatchedSnapshot(), "flaky-tests")).To(BeTrue())
+		Expect(mockGitHubClient.comments).To(Equal([]string{
+			"@developer IntegrationTestScenario e2e-tests is required and can't be skipped.",
+			"@developer skipped IntegrationTestScenario flaky-tests for Snapshot snapshot-latest.",
+		}))
+	})
+
+	It("approves the latest Snapshot", func() {
+		Expect(handler.HandleComment(mockedContext, newComment("/approve"))).To(Succeed())
+		Expect(getPatchedSnapshot().Annotations).To(HaveKeyWithValue(gitops.SnapshotApprovedByAnnotation, "developer"))
+		Expect(mockGitHubClient.comments).To(Equal([]string{"@developer approved Snapshot snapshot-latest."}))
+	})
+
+	It("runs the commands against the latest Snapshot of each Component of the pull request", func() {
+		otherComponentSnapshot := latestSnapshot.DeepCopy()
+		otherComponentSnapshot.Name = "snapshot-other-component"
+		otherComponentSnapshot.Labels[gitops.SnapshotComponentLabel] = "component-other"
+		olderOtherComponentSnapshot := olderSnapshot.DeepCopy()
+		olderOtherComponentSnapshot.Name = "snapshot-other-component-older"
+		olderOtherComponentSnapshot.Labels[gitops.SnapshotComponentLabel] = "component-other"
+		mockedContext = loader.GetMockedContext(mockedContext, []loader.MockData{
+			{
+				ContextKey: loader.AllSnapshotsContextKey,
+				Resource: []applicationapiv1alpha1.Snapshot{*olderSnapshot, *otherComponentSnapshot,
+					*latestSnapshot, *olderOtherComponentSnapshot},
+			},
+		})
+
+		Expect(handler.HandleComment(mockedContext, newComment("/approve"))).To(Succeed())
+		Expect(mockK8sClient.patched).To(HaveLen(2))
+		patchedNames := []string{}
+		for _, patched := range mockK8sClient.patched {
+			patchedNames = append(patchedNames, patched.GetName())
+			Expect(patched.GetAnnotations()).To(HaveKeyWithValue(gitops.SnapshotApprovedByAnnotation, "developer"))
+		}
+		Expect(patchedNames).To(ConsistOf(latestSnapshot.Name, otherComponentSnapshot.Name))
+		Expect(mockGitHubClient.comments).To(Equal([]string{
+			"@developer approved Snapshot snapshot-latest.\n@developer approved Snapshot snapshot-other-component.",
+		}))
+	})
+
+	It("refuses the commands of users without write permission", func() {
+		mockGitHubClient.permission = "read"
+		Expect(handler.HandleComment(mockedContext, newComment("/approve"))).To(Succeed())
+		Expect(mockK8sClient.patched).To(BeEmpty())
+		Expect(mockGitHubClient.comments).To(Equal([]string{
+			"@developer only users with write permission on the repository can run `/approve`.",
+		}))
+	})
+
+	It("ignores pull requests of repositories not bound to any namespace", func() {
+		mockedContext = loader.GetMockedContext(mockedContext, []loader.MockData{
+			{ContextKey: loader.RepositoriesContextKey, Resource: []pacv1alpha1.Repository{}},
+		})
+		Expect(handler.HandleComment(mockedContext, newComment("/approve"))).To(Succeed())
+		Expect(mockK8sClient.patched).To(BeEmpty())
+		Expect(mockGitHubClient.comments).To(BeEmpty())
+	})
+
+	It("ignores comments without commands", func() {
+		Expect(handler.HandleComment(mockedContext, newComment("Looks good to me"))).To(Succeed())
+		Expect(mockK8sClient.patched).To(BeEmpty())
+		Expect(mockGitHubClient.comments).To(BeEmpty())
+	})
+})
@@ -19,7 +19,9 @@ import (
 	"time"
 
 	"github.com/redhat-appstudio/operator-toolkit/controller"
+	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 
 	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
@@ -27,6 +29,7 @@ import (
 	"github.com/redhat-appstudio/integration-service/gitops"
 	h "github.com/redhat-appstudio/integration-service/helpers"
 	"github.com/redhat-appstudio/integration-service/loader"
+	"github.com/redhat-appstudio/integration-service/status"
 	"github.com/redhat-appstudio/integration-service/tekton"
 	pipeline "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -44,6 +47,7 @@ type Adapter struct {
 	client                     client.Client
 	context                    context.Context
 	loader                     loader.ObjectLoader
+	status                     status.Status
 }
 
 // NewAdapter creates and returns an Adapter instance.
@@ -60,9 +64,262 @@ func NewAdapter(snapshotEnvironmentBinding *applicationapiv1alpha1.SnapshotEnvir
 		loader:                     loader,
 		client:                     client,
 		context:                    context,
+		status:                     status.NewAdapter(logger.Logger, client),
 	}
 }
 
+// EnsureFailedPromotionRolledBack is an operation that will ensure that a SnapshotEnvironmentBinding of a persistent
+// environment is switched back to its last known-good Snapshot if the Snapshot promoted to it fails to deploy or doesn't
+// deploy before the deadline. The failed promotion is recorded on the promoted Snapshot so it isn't promoted again.
+func (a *Adapter) EnsureFailedPromotionRolledBack() (controller.OperationResult, error) {
+	if a.integrationTestScenario != nil || !gitops.IsBindingPromotionInProgress(a.snapshotEnvironmentBinding) {
+		return controller.ContinueProcessing()
+	}
+
+	patch := client.MergeFrom(a.snapshotEnvironmentBinding.DeepCopy())
+	if gitops.IsBindingDeployed(a.snapshotEnvironmentBinding) && !gitops.HaveBindingsFailed(a.snapshotEnvironmentBinding) {
+		h.AddAnnotation(&a.snapshotEnvironmentBinding.ObjectMeta, gitops.BindingLastKnownGoodSnapshotAnnotation, a.snapshotEnvironmentBinding.Spec.Snapshot)
+		delete(a.snapshotEnvironmentBinding.Annotations, gitops.BindingPromotionTimeAnnotation)
+		err := a.client.Patch(a.context, a.snapshotEnvironmentBinding, patch)
+		if err != nil {
+			a.logger.Error(err, "Failed to mark the Snapshot as the last known-good Snapshot of the SnapshotEnvironmentBinding")
+			return controller.RequeueWithError(err)
+		}
+		a.logger.LogAuditEvent("The promoted Snapshot was deployed, marked it as the last known-good Snapshot",
+			a.snapshotEnvironmentBinding, h.LogActionUpdate,
+			"snapshot.Name", a.snapshotEnvironmentBinding.Spec.Snapshot)
+		return controller.ContinueProcessing()
+	}
+
+	var reason string
+	if gitops.HasBindingDeploymentFailed(a.snapshotEnvironmentBinding) {
+		reason = "the deployment of the Snapshot to the Environment failed: " +
+			meta.FindStatusCondition(a.snapshotEnvironmentBinding.Status.BindingConditions, gitops.BindingErrorOccurredStatusConditionType).Message
+	} else if deadline := gitops.GetBindingPromotionDeadline(a.snapshotEnvironmentBinding); time.Now().Before(deadline) {
+		// An error reported by the deployment fails it before the promotion deadline, so check it again by then
+		if errorDeadline := gitops.GetBindingErrorDeadline(a.snapshotEnvironmentBinding); !errorDeadline.IsZero() && errorDeadline.Before(deadline) {
+			deadline = errorDeadline
+		}
+		a.logger.Info("The promoted Snapshot hasn't been deployed yet, checking it again later",
+			"snapshot.Name", a.snapshotEnvironmentBinding.Spec.Snapshot,
+			"deadline", deadline)
+		return controller.RequeueAfter(time.Until(deadline), nil)
+	} else {
+		reason = fmt.Sprintf("the Snapshot wasn't deployed to the Environment within %s", gitops.BindingDeploymentTimeout)
+	}
+
+	err := gitops.RecordSnapshotPromotionDecisions(a.client, a.context, a.snapshot, map[string]gitops.PromotionDecision{
+		gitops.GetPromotionTargetKey(gitops.PromotionTargetEnvironment, a.snapshotEnvironmentBinding.Spec.Environment): {
+			Promoted:   false,
+			RolledBack: true,
+			Reasons:    []string{reason},
+		},
+	})
+	if err != nil {
+		a.logger.Error(err, "Failed to record the failed promotion on the Snapshot")
+		return controller.RequeueWithError(err)
+	}
+
+	failedSnapshot := a.snapshotEnvironmentBinding.Spec.Snapshot
+	lastKnownGoodSnapshot := a.snapshotEnvironmentBinding.GetAnnotations()[gitops.BindingLastKnownGoodSnapshotAnnotation]
+	rolledBack, err := a.rollBackSnapshotEnvironmentBinding(lastKnownGoodSnapshot)
+	if err != nil {
+		a.logger.Error(err, "Failed to roll back the SnapshotEnvironmentBinding")
+		return controller.RequeueWithError(err)
+	}
+
+	if !rolledBack {
+		a.logger.LogAuditEvent("The promoted Snapshot failed to deploy and there is no known-good Snapshot to roll back to",
+			a.snapshotEnvironmentBinding, h.LogActionUpdate,
+			"snapshot.Name", failedSnapshot,
+			"reason", reason)
+	} else {
+		a.logger.LogAuditEvent("The promoted Snapshot failed to deploy, rolled back to the last known-good Snapshot",
+			a.snapshotEnvironmentBinding, h.LogActionUpdate,
+			"failedSnapshot.Name", failedSnapshot,
+			"snapshot.Name", lastKnownGoodSnapshot,
+			"reason", reason)
+	}
+
+	return controller.ContinueProcessing()
+}
+
+// EnsurePostDeploymentVerificationPassed is an operation that will ensure that the post-deployment verification scenarios
+// of a persistent environment are run against it once a Snapshot is deployed to it. The result of the verification is
+// recorded on both the Snapshot and the Environment. If the verification fails, the SnapshotEnvironmentBinding is rolled
+// back to the last known-good Snapshot when the Environment asks for it, and the Snapshot isn't promoted any further.
+func (a *Adapter) EnsurePostDeploymentVerificationPassed() (controller.OperationResult, error) {
+	if !a.isPromotedSnapshotDeployed() || gitops.IsSnapshotVerifiedInEnvironment(a.snapshot, a.environment.Name) {
+		return controller.ContinueProcessing()
+	}
+
+	if gitops.HasSnapshotFailedVerificationInEnvironment(a.snapshot, a.environment.Name) {
+		a.logger.Info("The Snapshot failed the post-deployment verification of the Environment, it won't be promoted further.",
+			"environment.Name", a.environment.Name)
+		return controller.StopProcessing()
+	}
+
+	err := a.ensureSnapshotDeploymentRecorded()
+	if err != nil {
+		a.logger.Error(err, "Failed to record the deployment of the Snapshot to the Environment")
+		return controller.RequeueWithError(err)
+	}
+
+	verification, err := gitops.GetEnvironmentVerification(a.environment)
+	if err == nil && verification != nil && verification.Snapshot == a.snapshot.Name && verification.Passed {
+		return controller.ContinueProcessing()
+	}
+
+	integrationTestScenarios, err := a.loader.GetAllIntegrationTestScenariosForApplication(a.client, a.context, a.application)
+	if err != nil {
+		a.logger.Error(err, "Failed to get the IntegrationTestScenarios of the Application")
+		return controller.RequeueWithError(err)
+	}
+	postDeploymentScenarios := gitops.GetPostDeploymentIntegrationTestScenariosForEnvironment(integrationTestScenarios, a.environment.Name)
+	if len(postDeploymentScenarios) == 0 {
+		return controller.ContinueProcessing()
+	}
+
+	pipelineRuns, err := a.loader.GetAllVerificationPipelineRunsForSnapshotAndEnvironment(a.client, a.context, a.snapshot, a.environment)
+	if err != nil {
+		a.logger.Error(err, "Failed to get the verification PipelineRuns of the Snapshot and Environment")
+		return controller.RequeueWithError(err)
+	}
+
+	allFinished := true
+	failedScenarios := []string{}
+	for _, postDeploymentScenario := range postDeploymentScenarios {
+		postDeploymentScenario := postDeploymentScenario // G601
+		pipelineRun := findLatestPipelineRunForScenario(pipelineRuns, postDeploymentScenario.Name)
+		if pipelineRun == nil {
+			pipelineRun, err = a.createVerificationPipelineRun(&postDeploymentScenario)
+			if err != nil {
+				a.logger.Error(err, "Failed to create the verification PipelineRun",
+					"integrationTestScenario.Name", postDeploymentScenario.Name)
+				return controller.RequeueWithError(err)
+			}
+			a.logger.LogAuditEvent("Verification PipelineRun for the deployed Snapshot created", pipelineRun, h.LogActionAdd,
+				"snapshot.Name", a.snapshot.Name,
+				"environment.Name", a.environment.Name)
+			allFinished = false
+			continue
+		}
+		if !h.HasPipelineRunFinished(pipelineRun) {
+			allFinished = false
+			continue
+		}
+		passed, err := h.CalculateIntegrationPipelineRunOutcome(a.client, a.context, a.logger.Logger, pipelineRun)
+		if err != nil {
+			a.logger.Error(err, "Failed to get the outcome of the verification PipelineRun",
+				"pipelineRun.Name", pipelineRun.Name)
+			return controller.RequeueWithError(err)
+		}
+		if !passed {
+			failedScenarios = append(failedScenarios, postDeploymentScenario.Name)
+		}
+	}
+	if !allFinished {
+		a.logger.Info("Waiting for the post-deployment verification of the Environment to finish",
+			"environment.Name", a.environment.Name)
+		return controller.StopProcessing()
+	}
+
+	err = gitops.RecordEnvironmentVerification(a.client, a.context, a.environment, a.snapshot, failedScenarios)
+	if err != nil {
+		a.logger.Error(err, "Failed to record the post-deployment verification on the Environment")
+		return controller.RequeueWithError(err)
+	}
+	if len(failedScenarios) == 0 {
+		a.logger.LogAuditEvent("The Snapshot passed the post-deployment verification of the Environment", a.environment, h.LogActionUpdate,
+			"snapshot.Name", a.snapshot.Name)
+		return controller.ContinueProcessing()
+	}
+
+	rollBack := gitops.IsRollbackOnFailedVerificationEnabled(a.environment)
+	err = gitops.MarkSnapshotVerificationFailedInEnvironment(a.client, a.context, a.snapshot, a.environment.Name, failedScenarios, rollBack)
+	if err != nil {
+		a.logger.Error(err, "Failed to record the failed post-deployment verification on the Snapshot")
+		return controller.RequeueWithError(err)
+	}
+	a.logger.LogAuditEvent("The Snapshot failed the post-deployment verification of the Environment", a.environment, h.LogActionUpdate,
+		"snapshot.Name", a.snapshot.Name,
+		"failedScenarios", failedScenarios)
+
+	if rollBack {
+		// The Snapshot became the last known-good Snapshot once it deployed, so it's rolled back to the last verified one
+		lastVerifiedSnapshot := a.snapshotEnvironmentBinding.GetAnnotations()[gitops.BindingLastVerifiedSnapshotAnnotation]
+		rolledBack, err := a.rollBackSnapshotEnvironmentBinding(lastVerifiedSnapshot)
+		if err != nil {
+			a.logger.Error(err, "Failed to roll back the SnapshotEnvironmentBinding")
+			return controller.RequeueWithError(err)
+		}
+		if !rolledBack {
+			a.logger.LogAuditEvent("The deployed Snapshot failed the post-deployment verification and there is no verified Snapshot to roll back to",
+				a.snapshotEnvironmentBinding, h.LogActionUpdate,
+				"snapshot.Name", a.snapshot.Name,
+				"failedScenarios", failedScenarios)
+		} else {
+			a.logger.LogAuditEvent("The deployed Snapshot failed the post-deployment verification, rolled back to the last verified Snapshot",
+				a.snapshotEnvironmentBinding, h.LogActionUpdate,
+				"failedSnapshot.Name", a.snapshot.Name,
+				"snapshot.Name", lastVerifiedSnapshot,
+				"failedScenarios", failedScenarios)
+		}
+	}
+
+	return controller.StopProcessing()
+}
+
+// EnsurePromotionChainAdvanced is an operation that will ensure that the deployment of a Snapshot to a persistent
+// environment is recorded on the Snapshot. Once the Snapshot stayed deployed and healthy for the soak time of the
+// environment, it's marked as verified in it and becomes the last verified Snapshot of the SnapshotEnvironmentBinding,
+// which makes it eligible for promotion to the child environments.
+func (a *Adapter) EnsurePromotionChainAdvanced() (controller.OperationResult, error) {
+	if !a.isPromotedSnapshotDeployed() || gitops.IsSnapshotVerifiedInEnvironment(a.snapshot, a.environment.Name) {
+		return controller.ContinueProcessing()
+	}
+
+	err := a.ensureSnapshotDeploymentRecorded()
+	if err != nil {
+		a.logger.Error(err, "Failed to record the deployment of the Snapshot to the Environment")
+		return controller.RequeueWithError(err)
+	}
+
+	soakTime, err := gitops.GetEnvironmentSoakTime(a.environment)
+	if err != nil {
+		a.logger.Error(err, "The Snapshot won't be promoted to the child Environments",
+			"environment.Name", a.environment.Name)
+		return controller.ContinueProcessing()
+	}
+	if deploymentTime := gitops.GetSnapshotEnvironmentDeploymentTime(a.snapshot, a.environment.Name); deploymentTime != nil {
+		if soakDeadline := deploymentTime.Add(soakTime); time.Now().Before(soakDeadline) {
+			a.logger.Info("The Snapshot is soaking in the Environment, it will be promoted to the child Environments later",
+				"environment.Name", a.environment.Name,
+				"soakTime", soakTime)
+			return controller.RequeueAfter(time.Until(soakDeadline), nil)
+		}
+	}
+
+	err = gitops.MarkSnapshotVerifiedInEnvironment(a.client, a.context, a.snapshot, a.environment.Name)
+	if err != nil {
+		a.logger.Error(err, "Failed to mark the Snapshot as verified in the Environment")
+		return controller.RequeueWithError(err)
+	}
+
+	patch := client.MergeFrom(a.snapshotEnvironmentBinding.DeepCopy())
+	h.AddAnnotation(&a.snapshotEnvironmentBinding.ObjectMeta, gitops.BindingLastVerifiedSnapshotAnnotation, a.snapshot.Name)
+	err = a.client.Patch(a.context, a.snapshotEnvironmentBinding, patch)
+	if err != nil {
+		a.logger.Error(err, "Failed to mark the Snapshot as the last verified Snapshot of the SnapshotEnvironmentBinding")
+		return controller.RequeueWithError(err)
+	}
+	a.logger.LogAuditEvent("The Snapshot was verified in the Environment and can be promoted to its child Environments",
+		a.snapshot, h.LogActionUpdate,
+		"environment.Name", a.environment.Name)
+
+	return controller.ContinueProcessing()
+}
+
 // EnsureIntegrationTestPipelineForScenarioExists is an operation that will ensure that the Integration test pipeline
 // associated with the Snapshot and the SnapshotEnvironmentBinding's IntegrationTestScenarios exist.
 func (a *Adapter) EnsureIntegrationTestPipelineForScenarioExists() (controller.OperationResult, error) {
@@ -94,19 +351,38 @@ func (a *Adapter) EnsureIntegrationTestPipelineForScenarioExists() (controller.O
 			a.logger.Info("Found existing integrationPipelineRun",
 				"integrationTestScenario.Name", a.integrationTestScenario.Name,
 				"integrationPipelineRun.Name", integrationPipelineRun.Name)
-		} else {
-			a.logger.Info("Creating new pipelinerun for integrationTestscenario",
+			return controller.ContinueProcessing()
+		}
+
+		environments, err := a.getDeployedEnvironmentsForScenario()
+		if errors.IsNotFound(err) {
+			a.logger.Info("Not all ephemeral environments of the scenario have been created yet.",
 				"integrationTestScenario.Name", a.integrationTestScenario.Name,
-				"app name", a.application.Name,
-				"namespace", a.application.Namespace)
-			pipelineRun, err := a.createIntegrationPipelineRunWithEnvironment(a.application, a.integrationTestScenario, a.snapshot, a.environment)
-			if err != nil {
-				a.logger.Error(err, "Failed to create pipelineRun for snapshot, environment and scenario")
-				return controller.RequeueWithError(err)
-			}
-			a.logger.LogAuditEvent("PipelineRun for snapshot created", pipelineRun, h.LogActionAdd,
-				"snapshot.Name", a.snapshot.Name)
+				"error", err.Error())
+			return controller.ContinueProcessing()
+		}
+		if err != nil {
+			a.logger.Error(err, "Failed to get the environments the Snapshot is deployed to for scenario",
+				"integrationTestScenario.Name", a.integrationTestScenario.Name)
+			return controller.RequeueWithError(err)
 		}
+		if environments == nil {
+			a.logger.Info("Not all SnapshotEnvironmentBindings of the scenario have deployed to their ephemeral environments yet.",
+				"integrationTestScenario.Name", a.integrationTestScenario.Name)
+			return controller.ContinueProcessing()
+		}
+
+		a.logger.Info("Creating new pipelinerun for integrationTestscenario",
+			"integrationTestScenario.Name", a.integrationTestScenario.Name,
+			"app name", a.application.Name,
+			"namespace", a.application.Namespace)
+		pipelineRun, err := a.createIntegrationPipelineRunWithEnvironments(a.application, a.integrationTestScenario, a.snapshot, environments)
+		if err != nil {
+			a.logger.Error(err, "Failed to create pipelineRun for snapshot, environment and scenario")
+			return controller.RequeueWithError(err)
+		}
+		a.logger.LogAuditEvent("PipelineRun for snapshot created", pipelineRun, h.LogActionAdd,
+			"snapshot.Name", a.snapshot.Name)
 	}
 
 	return controller.ContinueProcessing()
@@ -115,7 +391,7 @@ func (a *Adapter) EnsureIntegrationTestPipelineForScenarioExists() (controller.O
 // EnsureEphemeralEnvironmentsCleanedUp will ensure that ephemeral environment(s) associated with the
 // SnapshotEnvironmentBinding are cleaned up.
 func (a *Adapter) EnsureEphemeralEnvironmentsCleanedUp() (controller.OperationResult, error) {
-	if !gitops.HaveBindingsFailed(a.snapshotEnvironmentBinding) {
+	if a.integrationTestScenario == nil || !gitops.HaveBindingsFailed(a.snapshotEnvironmentBinding) {
 		return controller.ContinueProcessing()
 	}
 
@@ -170,21 +446,104 @@ func (a *Adapter) EnsureEphemeralEnvironmentsCleanedUp() (controller.OperationRe
 
 }
 
-// createIntegrationPipelineRunWithEnvironment creates new integration PipelineRun. The Pipeline information and the parameters to it
-// will be extracted from the given integrationScenario. The integration's Snapshot will also be passed to the integration PipelineRun.
+// EnsureDeploymentStatusReported is an operation that will ensure that the state of the deployment of the Snapshot of
+// a SnapshotEnvironmentBinding of a persistent environment is reported to the git repositories of its Components
+// whenever it changes. Once the Snapshot is deployed, the revisions of the Snapshot it replaced which aren't deployed
+// anymore are reported as inactive. Failures to report are only logged, so they never hold back the reconciliation
+// of the SnapshotEnvironmentBinding; the state is reported again the next time the binding is reconciled.
+func (a *Adapter) EnsureDeploymentStatusReported() (controller.OperationResult, error) {
+	if a.integrationTestScenario != nil {
+		return controller.ContinueProcessing()
+	}
+
+	state := gitops.GetBindingDeploymentState(a.snapshotEnvironmentBinding)
+	reportedSnapshot, reportedState := gitops.GetBindingReportedDeploymentStatus(a.snapshotEnvironmentBinding)
+	if reportedSnapshot == a.snapshot.Name && reportedState == state {
+		return controller.ContinueProcessing()
+	}
+
+	reporters, err := a.status.GetDeploymentReporters(a.snapshot)
+	if err != nil {
+		a.logger.Error(err, "Failed to get the deployment reporters for the Snapshot")
+		return controller.ContinueProcessing()
+	}
+	if len(reporters) == 0 {
+		return controller.ContinueProcessing()
+	}
+
+	var replacedSnapshot *applicationapiv1alpha1.Snapshot
+	if state == gitops.DeploymentSucceeded {
+		replacedSnapshot, err = a.getReplacedSnapshot()
+		if err != nil {
+			a.logger.Error(err, "Failed to get the Snapshot replaced in the Environment")
+			return controller.ContinueProcessing()
+		}
+	}
+
+	reported := true
+	for _, reporter := range reporters {
+		if err := reporter.ReportDeploymentStatus(a.client, a.context, a.snapshot, a.environment.Name, state); err != nil {
+			a.logger.Error(err, "Failed to report the deployment status of the Snapshot")
+			reported = false
+		}
+		if replacedSnapshot == nil {
+			continue
+		}
+		if err := reporter.ReportDeploymentStatus(a.client, a.context, replacedSnapshot, a.environment.Name, gitops.DeploymentInactive); err != nil {
+			a.logger.Error(err, "Failed to report the deployment of the replaced Snapshot as inactive",
+				"replacedSnapshot.Name", replacedSnapshot.Name)
+			reported = false
+		}
+	}
+	if !reported {
+		return controller.ContinueProcessing()
+	}
+
+	patch := client.MergeFrom(a.snapshotEnvironmentBinding.DeepCopy())
+	gitops.MarkBindingDeploymentStatusReported(a.snapshotEnvironmentBinding, a.snapshot.Name, state)
+	err = a.client.Patch(a.context, a.snapshotEnvironmentBinding, patch)
+	if err != nil {
+		a.logger.Error(err, "Failed to record the reported deployment status on the SnapshotEnvironmentBinding")
+		return controller.ContinueProcessing()
+	}
+	a.logger.LogAuditEvent("The deployment status of the Snapshot was reported", a.snapshotEnvironmentBinding, h.LogActionUpdate,
+		"snapshot.Name", a.snapshot.Name,
+		"state", state)
+
+	return controller.ContinueProcessing()
+}
+
+// createIntegrationPipelineRunWithEnvironments creates new integration PipelineRun. The Pipeline information and the parameters to it
+// will be extracted from the given integrationScenario. The integration's Snapshot will also be passed to the integration PipelineRun
+// together with the DeploymentTarget details of each of the given environments, which are ordered as the environments of the integrationScenario.
 // If the creation of the PipelineRun is unsuccessful, an error will be returned.
-func (a *Adapter) createIntegrationPipelineRunWithEnvironment(application *applicationapiv1alpha1.Application, integrationTestScenario *v1beta1.IntegrationTestScenario, snapshot *applicationapiv1alpha1.Snapshot, environment *applicationapiv1alpha1.Environment) (*pipeline.PipelineRun, error) {
-	deploymentTarget, err := a.getDeploymentTargetForEnvironment(environment)
-	if err != nil || deploymentTarget == nil {
+func (a *Adapter) createIntegrationPipelineRunWithEnvironments(application *applicationapiv1alpha1.Application, integrationTestScenario *v1beta1.IntegrationTestScenario, snapshot *applicationapiv1alpha1.Snapshot, environments []applicationapiv1alpha1.Environment) (*pipeline.PipelineRun, error) {
+	var templateEnvironment *applicationapiv1alpha1.Environment
+	if len(environments) > 0 {
+		templateEnvironment = &environments[0]
+	}
+	params, err := gitops.ResolveIntegrationTestScenarioParams(a.client, a.context, integrationTestScenario.Namespace, integrationTestScenario.Spec.Params,
+		gitops.NewScenarioParamTemplateData(application, snapshot, a.component, templateEnvironment))
+	if err != nil {
 		return nil, err
 	}
 
-	pipelineRun := tekton.NewIntegrationPipelineRun(snapshot.Name, application.Namespace, *integrationTestScenario).
+	integrationPipelineRun := tekton.NewIntegrationPipelineRun(snapshot.Name, application.Namespace, *integrationTestScenario).
 		WithSnapshot(snapshot).
 		WithApplicationAndComponent(a.application, a.component).
 		WithIntegrationLabels(integrationTestScenario).
-		WithEnvironmentAndDeploymentTarget(deploymentTarget, environment.Name).
-		AsPipelineRun()
+		WithExtraParams(params)
+
+	for i, environment := range environments {
+		environment := environment // G601
+		deploymentTarget, err := a.getDeploymentTargetForEnvironment(&environment)
+		if err != nil || deploymentTarget == nil {
+			return nil, err
+		}
+		integrationPipelineRun.WithEnvironmentAndDeploymentTargetAtIndex(deploymentTarget, environment.Name, i)
+	}
+
+	pipelineRun := integrationPipelineRun.AsPipelineRun()
 	// copy PipelineRun PAC annotations/labels from snapshot to integration test PipelineRuns
 	h.CopyAnnotationsByPrefix(&snapshot.ObjectMeta, &pipelineRun.ObjectMeta, gitops.PipelinesAsCodePrefix, gitops.PipelinesAsCodePrefix)
 	h.CopyLabelsByPrefix(&snapshot.ObjectMeta, &pipelineRun.ObjectMeta, gitops.PipelinesAsCodePrefix, gitops.PipelinesAsCodePrefix)
@@ -201,6 +560,68 @@ func (a *Adapter) createIntegrationPipelineRunWithEnvironment(application *appli
 
 }
 
+// getDeployedEnvironmentsForScenario returns the ephemeral environments that the Snapshot has been deployed to for the
+// IntegrationTestScenario, the environment at each index being the copy of the environment at the same index of the
+// IntegrationTestScenario environments. If the ephemeral copy of one of the environments doesn't exist, a NotFound error
+// is returned. If the SnapshotEnvironmentBindings for some of the environments haven't deployed yet, nil is returned.
+func (a *Adapter) getDeployedEnvironmentsForScenario() ([]applicationapiv1alpha1.Environment, error) {
+	bindings, err := a.loader.GetAllSnapshotEnvironmentBindingsForScenario(a.client, a.context, a.snapshot, a.integrationTestScenario)
+	if err != nil {
+		return nil, err
+	}
+
+	deployedEnvironments := []applicationapiv1alpha1.Environment{}
+	for _, binding := range *bindings {
+		binding := binding // G601
+		if !gitops.IsBindingDeployed(&binding) {
+			a.logger.Info("The SnapshotEnvironmentBinding hasn't yet deployed to the ephemeral environment.", "snapshotEnvironmentBinding.Name", binding.Name)
+			return nil, nil
+		}
+
+		if binding.Spec.Environment == a.environment.Name {
+			deployedEnvironments = append(deployedEnvironments, *a.environment)
+			continue
+		}
+		environment := &applicationapiv1alpha1.Environment{}
+		err = a.client.Get(a.context, types.NamespacedName{
+			Namespace: binding.Namespace,
+			Name:      binding.Spec.Environment,
+		}, environment)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find environment %s of SnapshotEnvironmentBinding %s: %w", binding.Spec.Environment, binding.Name, err)
+		}
+		deployedEnvironments = append(deployedEnvironments, *environment)
+	}
+
+	testEnvironments := gitops.GetIntegrationTestScenarioEnvironments(a.integrationTestScenario)
+	environments := make([]applicationapiv1alpha1.Environment, len(testEnvironments))
+	for i, testEnvironment := range testEnvironments {
+		environment := findCopyOfTestEnvironment(deployedEnvironments, a.integrationTestScenario, &testEnvironment)
+		if environment == nil {
+			return nil, fmt.Errorf("failed to find the ephemeral copy of environment %s: %w", testEnvironment.Name,
+				errors.NewNotFound(applicationapiv1alpha1.GroupVersion.WithResource("environments").GroupResource(), testEnvironment.Name))
+		}
+		environments[i] = *environment
+	}
+
+	return environments, nil
+}
+
+// findCopyOfTestEnvironment returns the ephemeral environment copied from the given testEnvironment of the
+// integrationTestScenario. Copies created before the source environment label was introduced are matched against
+// the Environment of the integrationTestScenario. If no copy is found, nil is returned.
+func findCopyOfTestEnvironment(environments []applicationapiv1alpha1.Environment, integrationTestScenario *v1beta1.IntegrationTestScenario, testEnvironment *v1beta1.TestEnvironment) *applicationapiv1alpha1.Environment {
+	for _, environment := range environments {
+		environment := environment // G601
+		if h.HasLabelWithValue(&environment, gitops.SourceEnvironmentLabel, testEnvironment.Name) ||
+			(!h.HasLabel(&environment, gitops.SourceEnvironmentLabel) && testEnvironment.Name == integrationTestScenario.Spec.Environment.Name) {
+			return &environment
+		}
+	}
+
+	return nil
+}
+
 // getDeploymentTargetForEnvironment gets the DeploymentTarget associated with Environment, if the DeploymentTarget is not found, an error will be returned
 func (a *Adapter) getDeploymentTargetForEnvironment(environment *applicationapiv1alpha1.Environment) (*applicationapiv1alpha1.DeploymentTarget, error) {
 	deploymentTargetClaim, err := a.loader.GetDeploymentTargetClaimForEnvironment(a.client, a.context, environment)
@@ -215,3 +636,129 @@ func (a *Adapter) getDeploymentTargetForEnvironment(environment *applicationapiv
 
 	return deploymentTarget, nil
 }
+
+// isPromotedSnapshotDeployed returns true if the SnapshotEnvironmentBinding belongs to a persistent environment and the
+// Snapshot promoted to it finished deploying successfully.
+func (a *Adapter) isPromotedSnapshotDeployed() bool {
+	return a.integrationTestScenario == nil && a.snapshotEnvironmentBinding.Spec.Snapshot == a.snapshot.Name &&
+		!gitops.IsBindingPromotionInProgress(a.snapshotEnvironmentBinding) &&
+		gitops.IsBindingDeployed(a.snapshotEnvironmentBinding) && !gitops.HaveBindingsFailed(a.snapshotEnvironmentBinding)
+}
+
+// ensureSnapshotDeploymentRecorded records the deployment of the Snapshot to the environment on the Snapshot,
+// unless it was already recorded. If the Snapshot can't be patched, an error will be returned.
+func (a *Adapter) ensureSnapshotDeploymentRecorded() error {
+	if gitops.IsSnapshotDeployedToEnvironment(a.snapshot, a.environment.Name) {
+		return nil
+	}
+
+	err := gitops.MarkSnapshotDeployedToEnvironment(a.client, a.context, a.snapshot, a.environment.Name)
+	if err != nil {
+		return err
+	}
+	a.logger.LogAuditEvent("The Snapshot was deployed to the Environment", a.snapshot, h.LogActionUpdate,
+		"environment.Name", a.environment.Name)
+
+	return nil
+}
+
+// getReplacedSnapshot returns the Snapshot the SnapshotEnvironmentBinding pointed to before its current one, keeping
+// only the Components whose revisions aren't deployed by the current Snapshot. If there is no such Snapshot or
+// none of its revisions were replaced, nil is returned.
+func (a *Adapter) getReplacedSnapshot() (*applicationapiv1alpha1.Snapshot, error) {
+	previousSnapshotName := a.snapshotEnvironmentBinding.GetAnnotations()[gitops.BindingPreviousSnapshotAnnotation]
+	if previousSnapshotName == "" || previousSnapshotName == a.snapshot.Name {
+		return nil, nil
+	}
+
+	previousSnapshot, err := a.loader.GetSnapshot(a.client, a.context, previousSnapshotName, a.snapshotEnvironmentBinding.Namespace)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	replacedSnapshot := previousSnapshot.DeepCopy()
+	replacedSnapshot.Spec.Components = gitops.GetReplacedSnapshotComponents(previousSnapshot, a.snapshot)
+	if len(replacedSnapshot.Spec.Components) == 0 {
+		return nil, nil
+	}
+
+	return replacedSnapshot, nil
+}
+
+// rollBackSnapshotEnvironmentBinding switches the SnapshotEnvironmentBinding back to the given Snapshot, keeping the
+// name of the replaced Snapshot, and marks the promotion as finished. The given Snapshot becomes the last known-good
+// Snapshot again. If it's empty or the current Snapshot, the SnapshotEnvironmentBinding keeps its Snapshot and false
+// is returned. If the patch command fails, an error will be returned.
+func (a *Adapter) rollBackSnapshotEnvironmentBinding(snapshotName string) (bool, error) {
+	patch := client.MergeFrom(a.snapshotEnvironmentBinding.DeepCopy())
+	failedSnapshot := a.snapshotEnvironmentBinding.Spec.Snapshot
+	rolledBack := snapshotName != "" && snapshotName != failedSnapshot
+	delete(a.snapshotEnvironmentBinding.Annotations, gitops.BindingPromotionTimeAnnotation)
+	if rolledBack {
+		a.snapshotEnvironmentBinding.Spec.Snapshot = snapshotName
+		h.AddAnnotation(&a.snapshotEnvironmentBinding.ObjectMeta, gitops.BindingPreviousSnapshotAnnotation, failedSnapshot)
+		h.AddAnnotation(&a.snapshotEnvironmentBinding.ObjectMeta, gitops.BindingLastKnownGoodSnapshotAnnotation, snapshotName)
+	}
+	err := a.client.Patch(a.context, a.snapshotEnvironmentBinding, patch)
+	if err != nil {
+		return false, err
+	}
+
+	return rolledBack, nil
+}
+
+// createVerificationPipelineRun creates a new post-deployment verification PipelineRun for the given IntegrationTestScenario,
+// passing it the Snapshot and the DeploymentTarget details of the environment. The PipelineRun is owned by the
+// SnapshotEnvironmentBinding. If the creation of the PipelineRun is unsuccessful, an error will be returned.
+func (a *Adapter) createVerificationPipelineRun(integrationTestScenario *v1beta1.IntegrationTestScenario) (*pipeline.PipelineRun, error) {
+	params, err := gitops.ResolveIntegrationTestScenarioParams(a.client, a.context, integrationTestScenario.Namespace, integrationTestScenario.Spec.Params,
+		gitops.NewScenarioParamTemplateData(a.application, a.snapshot, a.component, a.environment))
+	if err != nil {
+		return nil, err
+	}
+
+	deploymentTarget, err := a.getDeploymentTargetForEnvironment(a.environment)
+	if err != nil {
+		return nil, err
+	}
+	if deploymentTarget == nil {
+		return nil, fmt.Errorf("no DeploymentTarget found for the Environment %s", a.environment.Name)
+	}
+
+	pipelineRun := tekton.NewIntegrationPipelineRun(a.snapshot.Name, a.application.Namespace, *integrationTestScenario).
+		WithSnapshot(a.snapshot).
+		WithApplicationAndComponent(a.application, a.component).
+		WithVerificationLabels(integrationTestScenario).
+		WithExtraParams(params).
+		WithEnvironmentAndDeploymentTarget(deploymentTarget, a.environment.Name).
+		AsPipelineRun()
+	err = ctrl.SetControllerReference(a.snapshotEnvironmentBinding, pipelineRun, a.client.Scheme())
+	if err != nil {
+		return nil, err
+	}
+	err = a.client.Create(a.context, pipelineRun)
+	if err != nil {
+		return nil, err
+	}
+
+	return pipelineRun, nil
+}
+
+// findLatestPipelineRunForScenario returns the most recently created PipelineRun of the IntegrationTestScenario with
+// the given name from the given list. If there is none, nil is returned.
+func findLatestPipelineRunForScenario(pipelineRuns *[]pipeline.PipelineRun, integrationTestScenarioName string) *pipeline.PipelineRun {
+	var latestPipelineRun *pipeline.PipelineRun
+	for i, pipelineRun := range *pipelineRuns {
+		if pipelineRun.Labels[tekton.ScenarioNameLabel] != integrationTestScenarioName {
+			continue
+		}
+		if latestPipelineRun == nil || latestPipelineRun.CreationTimestamp.Before(&pipelineRun.CreationTimestamp) {
+			latestPipelineRun = &(*pipelineRuns)[i]
+		}
+	}
+
+	return latestPipelineRun
+}
@@ -15,6 +15,7 @@ package binding
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"reflect"
 	"time"
@@ -32,15 +33,43 @@ import (
 	tektonv1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/apis"
 
 	"github.com/redhat-appstudio/integration-service/gitops"
 	"github.com/redhat-appstudio/integration-service/helpers"
 	"github.com/redhat-appstudio/integration-service/loader"
+	"github.com/redhat-appstudio/integration-service/status"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
+type MockStatusAdapter struct {
+	DeploymentReporter *MockDeploymentReporter
+}
+
+func (a *MockStatusAdapter) GetReporters(pipelineRun *tektonv1beta1.PipelineRun) ([]status.Reporter, error) {
+	return []status.Reporter{}, nil
+}
+
+func (a *MockStatusAdapter) GetReleaseReporters(snapshot *applicationapiv1alpha1.Snapshot) ([]status.ReleaseReporter, error) {
+	return []status.ReleaseReporter{}, nil
+}
+
+func (a *MockStatusAdapter) GetDeploymentReporters(snapshot *applicationapiv1alpha1.Snapshot) ([]status.DeploymentReporter, error) {
+	return []status.DeploymentReporter{a.DeploymentReporter}, nil
+}
+
+type MockDeploymentReporter struct {
+	reports []string
+	err     error
+}
+
+func (r *MockDeploymentReporter) ReportDeploymentStatus(k8sClient client.Client, ctx context.Context, snapshot *applicationapiv1alpha1.Snapshot, environmentName string, state gitops.DeploymentState) error {
+	r.reports = append(r.reports, snapshot.Name+"/"+string(state))
+	return r.err
+}
+
 var _ = Describe("Binding Adapter", Ordered, func() {
 	var (
 		adapter *Adapter
@@ -404,6 +433,44 @@ var _ = Describe("Binding Adapter", Ordered, func() {
 
 	})
 
+	It("ensures the integrationTestPipelines are NOT created until the bindings for all environments of the scenario are deployed", func() {
+		multiEnvScenario := integrationTestScenario.DeepCopy()
+		multiEnvScenario.Spec.Environments = []v1beta1.TestEnvironment{
+			{
+				Name: "dr-envname",
+				Type: "POC",
+			},
+		}
+		multiEnvAdapter := NewAdapter(hasBinding, hasSnapshot, hasEnv, hasApp, hasComp, multiEnvScenario, logger, loader.NewMockLoader(), k8sClient, ctx)
+		multiEnvAdapter.context = loader.GetMockedContext(ctx, []loader.MockData{
+			{
+				ContextKey: loader.PipelineRunsContextKey,
+				Resource:   nil,
+			},
+			{
+				ContextKey: loader.ScenarioBindingsContextKey,
+				Resource:   []applicationapiv1alpha1.SnapshotEnvironmentBinding{*hasBinding},
+			},
+		})
+
+		result, err := multiEnvAdapter.EnsureIntegrationTestPipelineForScenarioExists()
+		Expect(!result.CancelRequest && err == nil).To(BeTrue())
+
+		integrationPipelineRuns := &tektonv1beta1.PipelineRunList{}
+		opts := []client.ListOption{
+			client.InNamespace(hasApp.Namespace),
+			client.MatchingLabels{
+				"pipelines.appstudio.openshift.io/type": "test",
+				"appstudio.openshift.io/snapshot":       hasSnapshot.Name,
+				"test.appstudio.openshift.io/scenario":  multiEnvScenario.Name,
+			},
+		}
+		Consistently(func() bool {
+			err := k8sClient.List(ctx, integrationPipelineRuns, opts...)
+			return len(integrationPipelineRuns.Items) == 0 && err == nil
+		}, time.Second*5).Should(BeTrue())
+	})
+
 	It("ensures the integrationTestPipelines are NOT created for a Snapshot that finished testing", func() {
 		finishedAdapter := NewAdapter(hasBinding, finishedSnapshot, hasEnv, hasApp, hasComp, integrationTestScenario, logger, loader.NewMockLoader(), k8sClient, ctx)
 		Expect(reflect.TypeOf(adapter)).To(Equal(reflect.TypeOf(&Adapter{})))
@@ -531,4 +598,266 @@ var _ = Describe("Binding Adapter", Ordered, func() {
 		environments, _ := adapter.loader.GetAllEnvironments(k8sClient, adapter.context, hasApp)
 		Expect(*environments).To(ContainElement(HaveField("ObjectMeta.Name", "envname")))
 	})
+
+	It("ensures a promoted Snapshot which failed to deploy is rolled back to the last known-good Snapshot", func() {
+		var buf bytes.Buffer
+		log := helpers.IntegrationLogger{Logger: buflogr.NewWithBuffer(&buf)}
+
+		promotedBinding := hasBinding.DeepCopy()
+		promotedBinding.Labels = map[string]string{}
+		promotedBinding.Annotations = map[string]string{
+			gitops.BindingLastKnownGoodSnapshotAnnotation: finishedSnapshot.Name,
+			gitops.BindingPromotionTimeAnnotation:         time.Now().Add(-gitops.BindingDeploymentTimeout).UTC().Format(time.RFC3339),
+		}
+		promotedBinding.Status = applicationapiv1alpha1.SnapshotEnvironmentBindingStatus{}
+		Expect(k8sClient.Update(ctx, promotedBinding)).Should(Succeed())
+
+		adapter = NewAdapter(promotedBinding, hasSnapshot, hasEnv, hasApp, hasComp, nil, log, loader.NewMockLoader(), k8sClient, ctx)
+		result, err := adapter.EnsureFailedPromotionRolledBack()
+		Expect(!result.CancelRequest && err == nil).To(BeTrue())
+
+		Expect(promotedBinding.Spec.Snapshot).To(Equal(finishedSnapshot.Name))
+		Expect(promotedBinding.Annotations).To(HaveKeyWithValue(gitops.BindingPreviousSnapshotAnnotation, hasSnapshot.Name))
+		Expect(gitops.IsBindingPromotionInProgress(promotedBinding)).To(BeFalse())
+		Expect(gitops.HasSnapshotPromotionBeenRolledBack(hasSnapshot, gitops.GetPromotionTargetKey(gitops.PromotionTargetEnvironment, hasEnv.Name))).To(BeTrue())
+
+		expectedLogEntry := "The promoted Snapshot failed to deploy, rolled back to the last known-good Snapshot"
+		Expect(buf.String()).Should(ContainSubstring(expectedLogEntry))
+	})
+
+	It("ensures a promoted Snapshot which reported a deployment error is checked again once the error timeout passes", func() {
+		var buf bytes.Buffer
+		log := helpers.IntegrationLogger{Logger: buflogr.NewWithBuffer(&buf)}
+
+		promotedBinding := hasBinding.DeepCopy()
+		promotedBinding.Labels = map[string]string{}
+		promotedBinding.Annotations = map[string]string{
+			gitops.BindingLastKnownGoodSnapshotAnnotation: finishedSnapshot.Name,
+			gitops.BindingPromotionTimeAnnotation:         time.Now().UTC().Format(time.RFC3339),
+		}
+		Expect(k8sClient.Update(ctx, promotedBinding)).Should(Succeed())
+
+		adapter = NewAdapter(promotedBinding, hasSnapshot, hasEnv, hasApp, hasComp, nil, log, loader.NewMockLoader(), k8sClient, ctx)
+		promotedBinding.Status = applicationapiv1alpha1.SnapshotEnvironmentBindingStatus{}
+		result, err := adapter.EnsureFailedPromotionRolledBack()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueDelay).To(BeNumerically("~", gitops.BindingDeploymentTimeout, time.Minute))
+
+		promotedBinding.Status.BindingConditions = []metav1.Condition{
+			{
+				Reason:             "ErrorOccurred",
+				Status:             "True",
+				Type:               gitops.BindingErrorOccurredStatusConditionType,
+				Message:            "failed to sync the application",
+				LastTransitionTime: metav1.Time{Time: time.Now()},
+			},
+		}
+		result, err = adapter.EnsureFailedPromotionRolledBack()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueDelay).To(BeNumerically("~", gitops.BindingErrorTimeout, time.Minute))
+		Expect(gitops.IsBindingPromotionInProgress(promotedBinding)).To(BeTrue())
+
+		promotedBinding.Status.BindingConditions[0].LastTransitionTime = metav1.Time{Time: time.Now().Add(-gitops.BindingErrorTimeout)}
+		result, err = adapter.EnsureFailedPromotionRolledBack()
+		Expect(!result.CancelRequest && err == nil).To(BeTrue())
+		Expect(result.RequeueDelay).To(BeZero())
+
+		Expect(promotedBinding.Spec.Snapshot).To(Equal(finishedSnapshot.Name))
+		Expect(gitops.IsBindingPromotionInProgress(promotedBinding)).To(BeFalse())
+		decisions, err := gitops.GetSnapshotPromotionDecisions(hasSnapshot)
+		Expect(err).NotTo(HaveOccurred())
+		decision := decisions[gitops.GetPromotionTargetKey(gitops.PromotionTargetEnvironment, hasEnv.Name)]
+		Expect(decision.RolledBack).To(BeTrue())
+		Expect(decision.Reasons).To(ConsistOf(ContainSubstring("failed to sync the application")))
+	})
+
+	It("ensures a promoted Snapshot which deployed becomes the last known-good Snapshot", func() {
+		var buf bytes.Buffer
+		log := helpers.IntegrationLogger{Logger: buflogr.NewWithBuffer(&buf)}
+
+		promotedBinding := hasBinding.DeepCopy()
+		promotedBinding.Labels = map[string]string{}
+		promotedBinding.Annotations = map[string]string{
+			gitops.BindingPromotionTimeAnnotation: time.Now().UTC().Format(time.RFC3339),
+		}
+		Expect(k8sClient.Update(ctx, promotedBinding)).Should(Succeed())
+		promotedBinding.Status = hasBinding.Status
+
+		adapter = NewAdapter(promotedBinding, hasSnapshot, hasEnv, hasApp, hasComp, nil, log, loader.NewMockLoader(), k8sClient, ctx)
+		result, err := adapter.EnsureFailedPromotionRolledBack()
+		Expect(!result.CancelRequest && err == nil).To(BeTrue())
+
+		Expect(promotedBinding.Annotations).To(HaveKeyWithValue(gitops.BindingLastKnownGoodSnapshotAnnotation, hasSnapshot.Name))
+		Expect(gitops.IsBindingPromotionInProgress(promotedBinding)).To(BeFalse())
+	})
+
+	It("ensures a deployed Snapshot is verified in the Environment once its soak time passed", func() {
+		var buf bytes.Buffer
+		log := helpers.IntegrationLogger{Logger: buflogr.NewWithBuffer(&buf)}
+
+		deployedBinding := hasBinding.DeepCopy()
+		deployedBinding.Labels = map[string]string{}
+		soakEnv := hasEnv.DeepCopy()
+		soakEnv.Annotations = map[string]string{gitops.EnvironmentSoakTimeAnnotation: "1h"}
+
+		adapter = NewAdapter(deployedBinding, hasSnapshot, soakEnv, hasApp, hasComp, nil, log, loader.NewMockLoader(), k8sClient, ctx)
+		result, err := adapter.EnsurePromotionChainAdvanced()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueDelay).To(BeNumerically("~", time.Hour, time.Minute))
+		Expect(gitops.IsSnapshotDeployedToEnvironment(hasSnapshot, hasEnv.Name)).To(BeTrue())
+		Expect(gitops.IsSnapshotVerifiedInEnvironment(hasSnapshot, hasEnv.Name)).To(BeFalse())
+
+		soakEnv.Annotations = nil
+		result, err = adapter.EnsurePromotionChainAdvanced()
+		Expect(!result.CancelRequest && err == nil).To(BeTrue())
+		Expect(gitops.IsSnapshotVerifiedInEnvironment(hasSnapshot, hasEnv.Name)).To(BeTrue())
+		Expect(deployedBinding.Annotations).To(HaveKeyWithValue(gitops.BindingLastVerifiedSnapshotAnnotation, hasSnapshot.Name))
+
+		expectedLogEntry := "The Snapshot was verified in the Environment and can be promoted to its child Environments"
+		Expect(buf.String()).Should(ContainSubstring(expectedLogEntry))
+	})
+
+	It("ensures a deployed Snapshot failing the post-deployment verification is rolled back", func() {
+		var buf bytes.Buffer
+		log := helpers.IntegrationLogger{Logger: buflogr.NewWithBuffer(&buf)}
+
+		deployedBinding := hasBinding.DeepCopy()
+		deployedBinding.Labels = map[string]string{}
+		deployedBinding.Annotations = map[string]string{
+			gitops.BindingLastKnownGoodSnapshotAnnotation: hasSnapshot.Name,
+			gitops.BindingLastVerifiedSnapshotAnnotation:  finishedSnapshot.Name,
+		}
+		Expect(k8sClient.Update(ctx, deployedBinding)).Should(Succeed())
+		deployedBinding.Status = hasBinding.Status
+
+		verifiedEnv := hasEnv.DeepCopy()
+		verifiedEnv.Annotations = map[string]string{gitops.EnvironmentRollbackOnFailedVerificationAnnotation: "true"}
+
+		postDeploymentScenario := integrationTestScenario.DeepCopy()
+		postDeploymentScenario.Name = "smoke-tests"
+		postDeploymentScenario.Spec.Contexts = []v1beta1.TestContext{{Name: gitops.PostDeploymentContext}}
+
+		verificationPipelineRun := &tektonv1beta1.PipelineRun{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "verification-pipelinerun-sample",
+				Namespace: "default",
+				Labels: map[string]string{
+					"pipelines.appstudio.openshift.io/type": "verification",
+					"test.appstudio.openshift.io/scenario":  postDeploymentScenario.Name,
+				},
+			},
+		}
+		verificationPipelineRun.Status.SetCondition(&apis.Condition{
+			Type:   apis.ConditionSucceeded,
+			Status: "False",
+		})
+
+		adapter = NewAdapter(deployedBinding, hasSnapshot, verifiedEnv, hasApp, hasComp, nil, log, loader.NewMockLoader(), k8sClient, ctx)
+		adapter.context = loader.GetMockedContext(ctx, []loader.MockData{
+			{
+				ContextKey: loader.AllIntegrationTestScenariosContextKey,
+				Resource:   []v1beta1.IntegrationTestScenario{*integrationTestScenario, *postDeploymentScenario},
+			},
+			{
+				ContextKey: loader.PipelineRunsContextKey,
+				Resource:   []tektonv1beta1.PipelineRun{*verificationPipelineRun},
+			},
+		})
+		result, err := adapter.EnsurePostDeploymentVerificationPassed()
+		Expect(result.CancelRequest && err == nil).To(BeTrue())
+
+		Expect(gitops.HasSnapshotFailedVerificationInEnvironment(hasSnapshot, hasEnv.Name)).To(BeTrue())
+		Expect(deployedBinding.Spec.Snapshot).To(Equal(finishedSnapshot.Name))
+		Expect(deployedBinding.Annotations).To(HaveKeyWithValue(gitops.BindingLastKnownGoodSnapshotAnnotation, finishedSnapshot.Name))
+		verification, err := gitops.GetEnvironmentVerification(verifiedEnv)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(verification.Passed).To(BeFalse())
+		Expect(verification.FailedScenarios).To(Equal([]string{postDeploymentScenario.Name}))
+
+		expectedLogEntry := "The Snapshot failed the post-deployment verification of the Environment"
+		Expect(buf.String()).Should(ContainSubstring(expectedLogEntry))
+		expectedLogEntry = "The deployed Snapshot failed the post-deployment verification, rolled back to the last verified Snapshot"
+		Expect(buf.String()).Should(ContainSubstring(expectedLogEntry))
+	})
+
+	It("ensures the deployment status of a deployed Snapshot is reported once", func() {
+		var buf bytes.Buffer
+		log := helpers.IntegrationLogger{Logger: buflogr.NewWithBuffer(&buf)}
+
+		deployedBinding := hasBinding.DeepCopy()
+		deployedBinding.Labels = map[string]string{}
+		deployedBinding.Annotations = map[string]string{
+			gitops.BindingPreviousSnapshotAnnotation: finishedSnapshot.Name,
+		}
+		Expect(k8sClient.Update(ctx, deployedBinding)).Should(Succeed())
+		deployedBinding.Status = hasBinding.Status
+
+		newGitSource := func(revision string) applicationapiv1alpha1.ComponentSource {
+			return applicationapiv1alpha1.ComponentSource{
+				ComponentSourceUnion: applicationapiv1alpha1.ComponentSourceUnion{
+					GitSource: &applicationapiv1alpha1.GitSource{URL: SampleRepoLink, Revision: revision},
+				},
+			}
+		}
+		deployedSnapshot := hasSnapshot.DeepCopy()
+		deployedSnapshot.Spec.Components[0].Source = newGitSource("f6e5d4c3b2a10987654321fedcba098765432101")
+		previousSnapshot := finishedSnapshot.DeepCopy()
+		previousSnapshot.Spec.Components[0].Source = newGitSource("a1b2c3d4e5f60718293a4b5c6d7e8f9012345678")
+
+		deploymentReporter := &MockDeploymentReporter{}
+		adapter = NewAdapter(deployedBinding, deployedSnapshot, hasEnv, hasApp, hasComp, nil, log, loader.NewMockLoader(), k8sClient, ctx)
+		adapter.status = &MockStatusAdapter{DeploymentReporter: deploymentReporter}
+		adapter.context = loader.GetMockedContext(ctx, []loader.MockData{
+			{
+				ContextKey: loader.SnapshotContextKey,
+				Resource:   previousSnapshot,
+			},
+		})
+		result, err := adapter.EnsureDeploymentStatusReported()
+		Expect(!result.CancelRequest && err == nil).To(BeTrue())
+		Expect(deploymentReporter.reports).To(Equal([]string{
+			deployedSnapshot.Name + "/Succeeded",
+			previousSnapshot.Name + "/Inactive",
+		}))
+		Expect(deployedBinding.Annotations).To(HaveKeyWithValue(gitops.BindingReportedDeploymentStatusAnnotation, deployedSnapshot.Name+"/Succeeded"))
+
+		expectedLogEntry := "The deployment status of the Snapshot was reported"
+		Expect(buf.String()).Should(ContainSubstring(expectedLogEntry))
+
+		// The same deployment status isn't reported twice
+		deployedBinding.Status = hasBinding.Status
+		result, err = adapter.EnsureDeploymentStatusReported()
+		Expect(!result.CancelRequest && err == nil).To(BeTrue())
+		Expect(deploymentReporter.reports).To(HaveLen(2))
+	})
+
+	It("ensures a failure to report the deployment status doesn't stop the reconciliation", func() {
+		var buf bytes.Buffer
+		log := helpers.IntegrationLogger{Logger: buflogr.NewWithBuffer(&buf)}
+
+		deployedBinding := hasBinding.DeepCopy()
+		deployedBinding.Labels = map[string]string{}
+		deployedBinding.Annotations = map[string]string{}
+
+		deploymentReporter := &MockDeploymentReporter{err: fmt.Errorf("API rate limit exceeded")}
+		adapter = NewAdapter(deployedBinding, hasSnapshot, hasEnv, hasApp, hasComp, nil, log, loader.NewMockLoader(), k8sClient, ctx)
+		adapter.status = &MockStatusAdapter{DeploymentReporter: deploymentReporter}
+		result, err := adapter.EnsureDeploymentStatusReported()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.CancelRequest || result.RequeueRequest).To(BeFalse())
+		Expect(deploymentReporter.reports).To(Equal([]string{hasSnapshot.Name + "/Succeeded"}))
+		Expect(deployedBinding.Annotations).NotTo(HaveKey(gitops.BindingReportedDeploymentStatusAnnotation))
+
+		expectedLogEntry := "Failed to report the deployment status of the Snapshot"
+		Expect(buf.String()).Should(ContainSubstring(expectedLogEntry))
+	})
+
+	It("ensures the deployment status isn't reported for bindings of IntegrationTestScenarios", func() {
+		deploymentReporter := &MockDeploymentReporter{}
+		adapter = NewAdapter(hasBinding, hasSnapshot, hasEnv, hasApp, hasComp, integrationTestScenario, logger, loader.NewMockLoader(), k8sClient, ctx)
+		adapter.status = &MockStatusAdapter{DeploymentReporter: deploymentReporter}
+		result, err := adapter.EnsureDeploymentStatusReported()
+		Expect(!result.CancelRequest && err == nil).To(BeTrue())
+		Expect(deploymentReporter.reports).To(BeEmpty())
+	})
 })
@@ -22,11 +22,14 @@ import (
 	"github.com/redhat-appstudio/integration-service/gitops"
 	"github.com/redhat-appstudio/integration-service/helpers"
 	"github.com/redhat-appstudio/integration-service/loader"
+	"github.com/redhat-appstudio/integration-service/tekton"
 	"github.com/redhat-appstudio/operator-toolkit/controller"
+	tektonv1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 )
@@ -59,8 +62,11 @@ func NewBindingReconciler(client client.Client, logger *logr.Logger, scheme *run
 //+kubebuilder:rbac:groups=appstudio.redhat.com,resources=applications/status,verbs=get
 //+kubebuilder:rbac:groups=appstudio.redhat.com,resources=deploymenttargets,verbs=get;list;watch
 //+kubebuilder:rbac:groups=appstudio.redhat.com,resources=deploymenttargetclaims,verbs=get;list;watch
-//+kubebuilder:rbac:groups=appstudio.redhat.com,resources=environments,verbs=get;list;watch
+//+kubebuilder:rbac:groups=appstudio.redhat.com,resources=environments,verbs=get;list;watch;update;patch
 //+kubebuilder:rbac:groups=appstudio.redhat.com,resources=environments/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch
+//+kubebuilder:rbac:groups=pipelinesascode.tekton.dev,resources=repositories,verbs=get;list;watch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -113,8 +119,12 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 	adapter := NewAdapter(snapshotEnvironmentBinding, snapshot, environment, application, component, integrationTestScenario, logger, loader, r.Client, ctx)
 
 	return controller.ReconcileHandler([]controller.Operation{
+		adapter.EnsureFailedPromotionRolledBack,
+		adapter.EnsurePostDeploymentVerificationPassed,
+		adapter.EnsurePromotionChainAdvanced,
 		adapter.EnsureIntegrationTestPipelineForScenarioExists,
 		adapter.EnsureEphemeralEnvironmentsCleanedUp,
+		adapter.EnsureDeploymentStatusReported,
 	})
 }
 
@@ -188,8 +198,12 @@ func (r *Reconciler) getIntegrationTestScenarioFromSnapshotEnvironmentBinding(co
 
 // AdapterInterface is an interface defining all the operations that should be defined in an Integration adapter.
 type AdapterInterface interface {
+	EnsureFailedPromotionRolledBack() (controller.OperationResult, error)
+	EnsurePostDeploymentVerificationPassed() (controller.OperationResult, error)
+	EnsurePromotionChainAdvanced() (controller.OperationResult, error)
 	EnsureIntegrationTestPipelineForScenarioExists() (controller.OperationResult, error)
 	EnsureEphemeralEnvironmentsCleanedUp() (controller.OperationResult, error)
+	EnsureDeploymentStatusReported() (controller.OperationResult, error)
 }
 
 // SetupController creates a new Integration reconciler and adds it to the Manager.
@@ -199,9 +213,15 @@ func SetupController(manager ctrl.Manager, log *logr.Logger) error {
 
 // setupControllerWithManager sets up the controller with the Manager which monitors new SnapshotEnvironmentBindings
 func setupControllerWithManager(manager ctrl.Manager, reconciler *Reconciler) error {
+	// Finished post-deployment verification PipelineRuns trigger the reconciliation of the
+	// SnapshotEnvironmentBinding owning them, so the result of the verification is recorded
 	return ctrl.NewControllerManagedBy(manager).
-		For(&applicationapiv1alpha1.SnapshotEnvironmentBinding{}).
-		WithEventFilter(predicate.And(gitops.IntegrationSnapshotEnvironmentBindingPredicate(), predicate.Or(
-			gitops.DeploymentSucceededForIntegrationBindingPredicate(), gitops.DeploymentFailedForIntegrationBindingPredicate()))).
+		For(&applicationapiv1alpha1.SnapshotEnvironmentBinding{}, builder.WithPredicates(predicate.Or(
+			predicate.And(gitops.IntegrationSnapshotEnvironmentBindingPredicate(), predicate.Or(
+				gitops.DeploymentSucceededForIntegrationBindingPredicate(), gitops.DeploymentFailedForIntegrationBindingPredicate())),
+			gitops.PromotedSnapshotEnvironmentBindingPredicate(),
+			gitops.DeploymentStatusChangedBindingPredicate()))).
+		Owns(&tektonv1beta1.PipelineRun{}, builder.WithPredicates(
+			tekton.VerificationPipelineRunFinishedPredicate())).
 		Complete(reconciler)
 }
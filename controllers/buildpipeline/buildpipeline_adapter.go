@@ -23,17 +23,26 @@ import (
 	"strings"
 	"time"
 
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
 	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
 	"github.com/redhat-appstudio/integration-service/gitops"
 	h "github.com/redhat-appstudio/integration-service/helpers"
 	"github.com/redhat-appstudio/integration-service/loader"
 	"github.com/redhat-appstudio/integration-service/metrics"
 	"github.com/redhat-appstudio/integration-service/tekton"
+	"github.com/redhat-appstudio/integration-service/verification"
 	"github.com/redhat-appstudio/operator-toolkit/controller"
 	tektonv1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
+// defaultServiceAccountName is the service account Tekton runs the PipelineRuns which don't specify any with.
+const defaultServiceAccountName = "default"
+
 // Adapter holds the objects needed to reconcile a build PipelineRun.
 type Adapter struct {
 	pipelineRun *tektonv1beta1.PipelineRun
@@ -59,6 +68,80 @@ func NewAdapter(pipelineRun *tektonv1beta1.PipelineRun, component *applicationap
 	}
 }
 
+// EnsureBuildVerified is an operation that will ensure that the image built by the build PipelineRun meets the
+// build verification policy of the Application, if it has one. Builds failing the verification are annotated with
+// the reason and no Snapshot is created for them. The annotations only record the outcome for the users, they can be
+// set by anyone who can update the build PipelineRun, so the image is verified again whenever the build is reconciled.
+func (a *Adapter) EnsureBuildVerified() (controller.OperationResult, error) {
+	if !h.HasPipelineRunSucceeded(a.pipelineRun) || a.component == nil {
+		return controller.ContinueProcessing()
+	}
+
+	policy, err := a.getBuildVerificationPolicy()
+	if err != nil {
+		a.logger.Error(err, "Failed to load the build verification policy of the Application")
+		return controller.RequeueWithError(err)
+	}
+	if policy == nil {
+		return controller.ContinueProcessing()
+	}
+
+	image, err := a.getImagePullSpecFromPipelineRun(a.pipelineRun)
+	if err != nil {
+		return controller.RequeueWithError(err)
+	}
+	// The source repository is taken from the Component rather than from the build, the verification fails
+	// if the Component doesn't have one while the policy requires the provenance
+	var sourceRepository string
+	if a.component.Spec.Source.GitSource != nil {
+		sourceRepository = a.component.Spec.Source.GitSource.URL
+	}
+
+	keychain, err := a.getPullSecretKeychain()
+	if err != nil {
+		a.logger.Error(err, "Failed to load the pull secrets of the build pipelineRun")
+		return controller.RequeueWithError(err)
+	}
+
+	err = verification.NewVerifier(a.context, policy, remote.WithAuthFromKeychain(keychain)).Verify(image, sourceRepository)
+	if err != nil && !verification.IsVerificationError(err) {
+		a.logger.Error(err, "Failed to verify the image of the build pipelineRun", "image", image)
+		return controller.RequeueWithError(err)
+	}
+
+	outcome, reason := verification.BuildVerificationPassed, ""
+	if err != nil {
+		outcome, reason = verification.BuildVerificationFailed, err.Error()
+	}
+	annotations := a.pipelineRun.GetAnnotations()
+	if annotations[verification.BuildVerificationAnnotation] != outcome || annotations[verification.BuildVerificationReasonAnnotation] != reason {
+		patch := client.MergeFrom(a.pipelineRun.DeepCopy())
+		h.AddAnnotation(&a.pipelineRun.ObjectMeta, verification.BuildVerificationAnnotation, outcome)
+		if reason != "" {
+			h.AddAnnotation(&a.pipelineRun.ObjectMeta, verification.BuildVerificationReasonAnnotation, reason)
+		} else {
+			delete(a.pipelineRun.Annotations, verification.BuildVerificationReasonAnnotation)
+		}
+		patchErr := a.client.Patch(a.context, a.pipelineRun, patch)
+		if patchErr != nil {
+			a.logger.Error(patchErr, "Failed to record the outcome of the verification on the build pipelineRun")
+			return controller.RequeueWithError(patchErr)
+		}
+	}
+
+	if err != nil {
+		a.logger.LogAuditEvent("The image of the build pipelineRun failed the verification, will not create a new Snapshot.",
+			a.pipelineRun, h.LogActionUpdate,
+			"image", image,
+			"reason", err.Error())
+		return controller.StopProcessing()
+	}
+	a.logger.LogAuditEvent("The image of the build pipelineRun passed the verification", a.pipelineRun, h.LogActionUpdate,
+		"image", image)
+
+	return controller.ContinueProcessing()
+}
+
 // EnsureSnapshotExists is an operation that will ensure that a pipeline Snapshot associated
 // to the build PipelineRun being processed exists. Otherwise, it will create a new pipeline Snapshot.
 func (a *Adapter) EnsureSnapshotExists() (controller.OperationResult, error) {
@@ -71,6 +154,13 @@ func (a *Adapter) EnsureSnapshotExists() (controller.OperationResult, error) {
 		return controller.ContinueProcessing()
 	}
 
+	if reason := gitops.GetAutomationPauseReason(gitops.PauseSnapshotCreationAnnotation, a.application, a.component); reason != "" {
+		a.logger.Info("Snapshot creation is paused, will not create a new Snapshot.",
+			"component.Name", a.component.Name,
+			"reason", reason)
+		return controller.ContinueProcessing()
+	}
+
 	isLatest, err := a.isLatestSucceededBuildPipelineRun()
 	if err != nil {
 		return controller.RequeueWithError(err)
@@ -88,6 +178,14 @@ func (a *Adapter) EnsureSnapshotExists() (controller.OperationResult, error) {
 		return controller.RequeueWithError(err)
 	}
 
+	batchWindow, err := gitops.GetBatchWindow(a.application)
+	if err != nil {
+		a.logger.Error(err, "Failed to get the batch window of the Application, will create a Snapshot for the build alone")
+	}
+	if batchWindow > 0 && !gitops.IsSnapshotCreatedByPACPullRequestEvent(expectedSnapshot) {
+		return a.ensureBuildBatched(expectedSnapshot, batchWindow)
+	}
+
 	allSnapshots, err := a.loader.GetAllSnapshots(a.client, a.context, a.application)
 	if err != nil {
 		return controller.RequeueWithError(err)
@@ -131,6 +229,241 @@ func (a *Adapter) EnsureSnapshotExists() (controller.OperationResult, error) {
 	return controller.ContinueProcessing()
 }
 
+// ensureBuildBatched adds the build to the batch the Application is collecting. Once the batch window of the
+// first build in the batch has passed, the batch is closed and a single batch Snapshot is created for all of its builds.
+// A build replaced in the batch by a build of the same Component which completed after it is marked as superseded,
+// so it isn't batched again.
+func (a *Adapter) ensureBuildBatched(expectedSnapshot *applicationapiv1alpha1.Snapshot, batchWindow time.Duration) (controller.OperationResult, error) {
+	if _, found := a.pipelineRun.ObjectMeta.Annotations[tekton.SnapshotNameLabel]; found {
+		return controller.ContinueProcessing()
+	}
+	if _, found := a.pipelineRun.ObjectMeta.Annotations[gitops.BuildPipelineRunBatchSupersededAnnotation]; found {
+		return controller.ContinueProcessing()
+	}
+
+	build := gitops.BatchedBuild{PipelineRun: a.pipelineRun.Name}
+	for _, snapshotComponent := range expectedSnapshot.Spec.Components {
+		if snapshotComponent.Name == a.component.Name {
+			build.Component = snapshotComponent
+		}
+	}
+	if a.pipelineRun.Status.CompletionTime != nil {
+		build.CompletionTime = *a.pipelineRun.Status.CompletionTime
+	}
+
+	pendingBatch, replacedBuild, err := gitops.AddBuildToPendingBatch(a.client, a.context, a.application, build)
+	if err != nil {
+		a.logger.Error(err, "Failed to add the build to the pending batch of the Application")
+		return controller.RequeueWithError(err)
+	}
+	if replacedBuild != nil {
+		err = a.annotateBatchedBuildAsSuperseded(replacedBuild.PipelineRun, a.pipelineRun.Name)
+		if err != nil {
+			return controller.RequeueWithError(err)
+		}
+	}
+
+	// A build of the same Component which completed later is already in the batch, so this build is left out of it
+	if supersedingBuild := pendingBatch.GetSupersedingBuild(build); supersedingBuild != nil {
+		a.logger.Info("A newer build of the Component is in the pending batch, the build won't be batched",
+			"component.Name", a.component.Name,
+			"batch.Snapshot", pendingBatch.SnapshotName,
+			"supersedingPipelineRun.Name", supersedingBuild.PipelineRun)
+		err = a.annotateBatchedBuildAsSuperseded(a.pipelineRun.Name, supersedingBuild.PipelineRun)
+		if err != nil {
+			return controller.RequeueWithError(err)
+		}
+		return controller.ContinueProcessing()
+	}
+
+	// The build arrived while the previous batch was being closed, the batch is finished before the build
+	// is added to the next one
+	if pendingBatch.Closed && !pendingBatch.HasBuild(a.pipelineRun.Name) {
+		a.logger.Info("The pending batch is closed, the build will be added to the next batch",
+			"component.Name", a.component.Name,
+			"batch.Snapshot", pendingBatch.SnapshotName)
+		err = a.createBatchSnapshot(pendingBatch)
+		if err != nil {
+			return controller.RequeueWithError(err)
+		}
+		return controller.Requeue()
+	}
+
+	batchDeadline := pendingBatch.OpenedAt.Add(batchWindow)
+	if !pendingBatch.Closed && time.Now().Before(batchDeadline) {
+		a.logger.Info("Added the build to the pending batch, waiting for the batch window to close",
+			"component.Name", a.component.Name,
+			"batch.Deadline", batchDeadline)
+		return controller.RequeueAfter(time.Until(batchDeadline), nil)
+	}
+
+	pendingBatch, err = gitops.ClosePendingBatch(a.client, a.context, a.application)
+	if err != nil {
+		a.logger.Error(err, "Failed to close the pending batch of the Application")
+		return controller.RequeueWithError(err)
+	}
+	if pendingBatch == nil {
+		// The batch was already removed by the reconciliation of another build of the batch
+		return controller.Requeue()
+	}
+
+	err = a.createBatchSnapshot(pendingBatch)
+	if err != nil {
+		return controller.RequeueWithError(err)
+	}
+
+	return controller.ContinueProcessing()
+}
+
+// createBatchSnapshot creates the batch Snapshot of the closed batch unless it already exists, annotates all the
+// batched build pipelineRuns with it and removes the batch, so the next build opens a new one. All the steps can be
+// repeated, so the reconciliation of any build of the batch can finish closing it.
+func (a *Adapter) createBatchSnapshot(pendingBatch *gitops.PendingBatch) error {
+	applicationComponents, err := a.loader.GetAllApplicationComponents(a.client, a.context, a.application)
+	if err != nil {
+		return err
+	}
+	batchedComponents := []applicationapiv1alpha1.SnapshotComponent{}
+	for _, batchedBuild := range pendingBatch.Builds {
+		batchedComponents = append(batchedComponents, batchedBuild.Component)
+	}
+	batchSnapshot, err := gitops.PrepareBatchSnapshot(a.client, a.context, a.application, applicationComponents,
+		batchedComponents, pendingBatch.SnapshotName)
+	if err != nil {
+		return err
+	}
+
+	err = a.client.Create(a.context, batchSnapshot)
+	if err != nil && !errors.IsAlreadyExists(err) {
+		a.logger.Error(err, "Failed to create the batch Snapshot")
+		return err
+	}
+	if err == nil {
+		go metrics.RegisterNewSnapshot()
+		a.logger.LogAuditEvent("Created new batch Snapshot", batchSnapshot, h.LogActionAdd,
+			"snapshot.Name", batchSnapshot.Name,
+			"snapshot.Spec.Components", batchSnapshot.Spec.Components)
+	}
+
+	for _, batchedBuild := range pendingBatch.Builds {
+		pipelineRun := a.pipelineRun
+		if batchedBuild.PipelineRun != a.pipelineRun.Name {
+			pipelineRun = &tektonv1beta1.PipelineRun{}
+			err = a.client.Get(a.context, types.NamespacedName{Namespace: a.pipelineRun.Namespace, Name: batchedBuild.PipelineRun}, pipelineRun)
+			if errors.IsNotFound(err) {
+				continue
+			} else if err != nil {
+				return err
+			}
+		}
+		if pipelineRun.GetAnnotations()[tekton.SnapshotNameLabel] == batchSnapshot.Name {
+			continue
+		}
+
+		_, err = a.annotateBuildPipelineRunWithSnapshot(pipelineRun, batchSnapshot)
+		if err != nil {
+			a.logger.Error(err, "Failed to update the build pipelineRun with new annotations",
+				"pipelineRun.Name", pipelineRun.Name)
+			return err
+		}
+	}
+
+	err = gitops.RemovePendingBatch(a.client, a.context, a.application, pendingBatch)
+	if err != nil {
+		a.logger.Error(err, "Failed to remove the closed batch of the Application")
+		return err
+	}
+
+	return nil
+}
+
+// annotateBatchedBuildAsSuperseded annotates the build PipelineRun with the given name with the name of the build
+// PipelineRun of the same Component superseding it in the batch. Build PipelineRuns which don't exist anymore are ignored.
+// If the build PipelineRun can't be loaded or patched, an error will be returned.
+func (a *Adapter) annotateBatchedBuildAsSuperseded(pipelineRunName, supersedingPipelineRunName string) error {
+	pipelineRun := a.pipelineRun
+	if pipelineRunName != a.pipelineRun.Name {
+		pipelineRun = &tektonv1beta1.PipelineRun{}
+		err := a.client.Get(a.context, types.NamespacedName{Namespace: a.pipelineRun.Namespace, Name: pipelineRunName}, pipelineRun)
+		if errors.IsNotFound(err) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+
+	patch := client.MergeFrom(pipelineRun.DeepCopy())
+	h.AddAnnotation(&pipelineRun.ObjectMeta, gitops.BuildPipelineRunBatchSupersededAnnotation, supersedingPipelineRunName)
+	err := a.client.Patch(a.context, pipelineRun, patch)
+	if err != nil {
+		a.logger.Error(err, "Failed to mark the build pipelineRun as superseded in the batch",
+			"pipelineRun.Name", pipelineRun.Name)
+		return err
+	}
+	a.logger.LogAuditEvent("Marked build pipelineRun as superseded in the batch", pipelineRun, h.LogActionUpdate,
+		"supersedingPipelineRun.Name", supersedingPipelineRunName)
+
+	return nil
+}
+
+// getBuildVerificationPolicy loads the build verification policy from the ConfigMap referenced by the Application.
+// If the Application doesn't reference any policy, nil is returned. In case the ConfigMap can't be loaded or the policy
+// can't be parsed, an error will be returned.
+func (a *Adapter) getBuildVerificationPolicy() (*verification.Policy, error) {
+	policyName := a.application.GetAnnotations()[verification.BuildVerificationPolicyAnnotation]
+	if policyName == "" {
+		return nil, nil
+	}
+
+	configMap := &corev1.ConfigMap{}
+	err := a.client.Get(a.context, types.NamespacedName{Namespace: a.application.Namespace, Name: policyName}, configMap)
+	if err != nil {
+		return nil, err
+	}
+
+	return verification.ParsePolicy(configMap.Data)
+}
+
+// getPullSecretKeychain returns a keychain with the pull secrets of the service account the build PipelineRun ran
+// with, so the built image is fetched with the credentials of the tenant. Pull secrets which don't exist are ignored.
+// If the service account or its pull secrets can't be loaded, an error will be returned.
+func (a *Adapter) getPullSecretKeychain() (authn.Keychain, error) {
+	serviceAccountName := a.pipelineRun.Spec.ServiceAccountName
+	if serviceAccountName == "" {
+		serviceAccountName = defaultServiceAccountName
+	}
+
+	serviceAccount := &corev1.ServiceAccount{}
+	err := a.client.Get(a.context, types.NamespacedName{Namespace: a.pipelineRun.Namespace, Name: serviceAccountName}, serviceAccount)
+	if errors.IsNotFound(err) {
+		return verification.NewPullSecretKeychain(nil)
+	} else if err != nil {
+		return nil, err
+	}
+
+	secretNames := []string{}
+	for _, pullSecret := range serviceAccount.ImagePullSecrets {
+		secretNames = append(secretNames, pullSecret.Name)
+	}
+	for _, secret := range serviceAccount.Secrets {
+		secretNames = append(secretNames, secret.Name)
+	}
+
+	secrets := []corev1.Secret{}
+	for _, secretName := range secretNames {
+		secret := corev1.Secret{}
+		err := a.client.Get(a.context, types.NamespacedName{Namespace: a.pipelineRun.Namespace, Name: secretName}, &secret)
+		if errors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		secrets = append(secrets, secret)
+	}
+
+	return verification.NewPullSecretKeychain(secrets)
+}
+
 // getImagePullSpecFromPipelineRun gets the full image pullspec from the given build PipelineRun,
 // In case the Image pullspec can't be composed, an error will be returned.
 func (a *Adapter) getImagePullSpecFromPipelineRun(pipelineRun *tektonv1beta1.PipelineRun) (string, error) {
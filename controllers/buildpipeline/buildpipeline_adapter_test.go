@@ -15,9 +15,25 @@ package buildpipeline
 
 import (
 	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"io"
+	"log"
+	"net/http/httptest"
 	"reflect"
+	"strings"
 	"time"
 
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/redhat-appstudio/integration-service/verification"
+	corev1 "k8s.io/api/core/v1"
+
 	"github.com/redhat-appstudio/integration-service/gitops"
 	"github.com/redhat-appstudio/integration-service/helpers"
 	"github.com/redhat-appstudio/integration-service/loader"
@@ -36,6 +52,8 @@ import (
 	tektonv1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	"github.com/tonglil/buflogr"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 var _ = Describe("Pipeline Adapter", Ordered, func() {
@@ -470,6 +488,238 @@ var _ = Describe("Pipeline Adapter", Ordered, func() {
 		})
 	})
 
+	When("Snapshot creation is paused for the Component", func() {
+		It("ensures snapshot creation is skipped", func() {
+			var buf bytes.Buffer
+			log := helpers.IntegrationLogger{Logger: buflogr.NewWithBuffer(&buf)}
+
+			pausedComp := hasComp.DeepCopy()
+			pausedComp.Annotations = map[string]string{gitops.PauseSnapshotCreationAnnotation: "true"}
+			adapter = NewAdapter(buildPipelineRun, pausedComp, hasApp, log, loader.NewMockLoader(), k8sClient, ctx)
+
+			result, err := adapter.EnsureSnapshotExists()
+			Expect(!result.CancelRequest && err == nil).To(BeTrue())
+			Expect(buf.String()).Should(ContainSubstring("Snapshot creation is paused, will not create a new Snapshot."))
+		})
+	})
+
+	When("the Application has a build verification policy", func() {
+		var (
+			buf            bytes.Buffer
+			registryServer *httptest.Server
+			policyApp      *applicationapiv1alpha1.Application
+			policy         *corev1.ConfigMap
+		)
+
+		BeforeAll(func() {
+			// An in-memory registry stands in for the registry the built images are pushed to
+			registryServer = httptest.NewServer(registry.New(registry.Logger(log.New(io.Discard, "", 0))))
+
+			signingKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+			Expect(err).To(BeNil())
+			publicKey, err := x509.MarshalPKIXPublicKey(signingKey.Public())
+			Expect(err).To(BeNil())
+			policy = &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "build-verification-policy",
+					Namespace: "default",
+				},
+				Data: map[string]string{
+					verification.PublicKeysKey: string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicKey})),
+				},
+			}
+			Expect(k8sClient.Create(ctx, policy)).Should(Succeed())
+
+			policyApp = hasApp.DeepCopy()
+			policyApp.Annotations = map[string]string{verification.BuildVerificationPolicyAnnotation: policy.Name}
+		})
+
+		AfterAll(func() {
+			registryServer.Close()
+			Expect(k8sClient.Delete(ctx, policy)).Should(Succeed())
+		})
+
+		BeforeEach(func() {
+			// The built image is pushed to the registry without being signed
+			image, err := random.Image(64, 1)
+			Expect(err).To(BeNil())
+			tag, err := name.NewTag(strings.TrimPrefix(registryServer.URL, "http://") + "/unsigned:latest")
+			Expect(err).To(BeNil())
+			Expect(remote.Write(tag, image)).To(Succeed())
+			digest, err := image.Digest()
+			Expect(err).To(BeNil())
+
+			buildPipelineRun.Status.PipelineResults[0].Value = *tektonv1beta1.NewStructuredValues(digest.String())
+			buildPipelineRun.Status.PipelineResults[1].Value = *tektonv1beta1.NewStructuredValues(tag.Context().String())
+			Expect(k8sClient.Status().Update(ctx, buildPipelineRun)).Should(Succeed())
+
+			log := helpers.IntegrationLogger{Logger: buflogr.NewWithBuffer(&buf)}
+			adapter = NewAdapter(buildPipelineRun, hasComp, policyApp, log, loader.NewMockLoader(), k8sClient, ctx)
+		})
+
+		It("ensures builds failing the verification are annotated and don't get a Snapshot", func() {
+			result, err := adapter.EnsureBuildVerified()
+			Expect(err).To(BeNil())
+			Expect(result.CancelRequest).To(BeTrue())
+			Expect(buildPipelineRun.Annotations[verification.BuildVerificationAnnotation]).To(Equal(verification.BuildVerificationFailed))
+			Expect(buildPipelineRun.Annotations[verification.BuildVerificationReasonAnnotation]).To(ContainSubstring("no signature was found"))
+
+			// The recorded outcome isn't trusted, annotating the build as passed doesn't bypass the verification
+			buildPipelineRun.Annotations[verification.BuildVerificationAnnotation] = verification.BuildVerificationPassed
+			result, err = adapter.EnsureBuildVerified()
+			Expect(err).To(BeNil())
+			Expect(result.CancelRequest).To(BeTrue())
+			Expect(buildPipelineRun.Annotations[verification.BuildVerificationAnnotation]).To(Equal(verification.BuildVerificationFailed))
+			Expect(buf.String()).Should(ContainSubstring("The image of the build pipelineRun failed the verification, will not create a new Snapshot."))
+		})
+
+		It("ensures builds are not verified when the Application has no build verification policy", func() {
+			adapter.application = hasApp
+			result, err := adapter.EnsureBuildVerified()
+			Expect(!result.CancelRequest && err == nil).To(BeTrue())
+			Expect(buildPipelineRun.Annotations).NotTo(HaveKey(verification.BuildVerificationAnnotation))
+		})
+	})
+
+	When("the Application batches the builds of its Components", func() {
+		var (
+			buf      bytes.Buffer
+			batchApp *applicationapiv1alpha1.Application
+		)
+
+		BeforeAll(func() {
+			batchApp = &applicationapiv1alpha1.Application{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: hasApp.Namespace, Name: hasApp.Name}, batchApp)).To(Succeed())
+			patch := client.MergeFrom(batchApp.DeepCopy())
+			batchApp.Annotations = map[string]string{gitops.ApplicationBatchWindowAnnotation: "1h"}
+			Expect(k8sClient.Patch(ctx, batchApp, patch)).To(Succeed())
+		})
+
+		AfterAll(func() {
+			patch := client.MergeFrom(batchApp.DeepCopy())
+			batchApp.Annotations = map[string]string{}
+			Expect(k8sClient.Patch(ctx, batchApp, patch)).To(Succeed())
+		})
+
+		BeforeEach(func() {
+			log := helpers.IntegrationLogger{Logger: buflogr.NewWithBuffer(&buf)}
+			adapter = NewAdapter(buildPipelineRun.DeepCopy(), hasComp, batchApp, log, loader.NewMockLoader(), k8sClient, ctx)
+			adapter.context = loader.GetMockedContext(ctx, []loader.MockData{
+				{
+					ContextKey: loader.ApplicationComponentsContextKey,
+					Resource:   []applicationapiv1alpha1.Component{*hasComp},
+				},
+			})
+		})
+
+		It("ensures the build is added to the pending batch until the batch window closes", func() {
+			result, err := adapter.EnsureSnapshotExists()
+			Expect(err).To(BeNil())
+			Expect(result.RequeueDelay).To(BeNumerically(">", 0))
+			Expect(result.RequeueDelay).To(BeNumerically("<=", time.Hour))
+			Expect(buf.String()).Should(ContainSubstring("Added the build to the pending batch"))
+
+			pendingBatch, err := gitops.GetPendingBatch(k8sClient, ctx, batchApp)
+			Expect(err).To(BeNil())
+			Expect(pendingBatch.Builds).To(HaveLen(1))
+			Expect(pendingBatch.Builds[0].PipelineRun).To(Equal(buildPipelineRun.Name))
+			Expect(pendingBatch.Builds[0].Component.ContainerImage).To(Equal(SampleImage))
+		})
+
+		It("ensures a batch Snapshot is created for the pending batch once the batch window closed", func() {
+			patch := client.MergeFrom(batchApp.DeepCopy())
+			batchApp.Annotations[gitops.ApplicationBatchWindowAnnotation] = "1ns"
+			Expect(k8sClient.Patch(ctx, batchApp, patch)).To(Succeed())
+
+			pendingBatch, err := gitops.GetPendingBatch(k8sClient, ctx, batchApp)
+			Expect(err).To(BeNil())
+
+			result, err := adapter.EnsureSnapshotExists()
+			Expect(!result.CancelRequest && err == nil).To(BeTrue())
+			Expect(gitops.GetPendingBatch(k8sClient, ctx, batchApp)).To(BeNil())
+
+			snapshotName := adapter.pipelineRun.Annotations[tekton.SnapshotNameLabel]
+			Expect(snapshotName).To(Equal(pendingBatch.SnapshotName))
+			batchSnapshot := &applicationapiv1alpha1.Snapshot{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: hasApp.Namespace, Name: snapshotName}, batchSnapshot)).To(Succeed())
+			Expect(gitops.IsSnapshotBatch(batchSnapshot)).To(BeTrue())
+			Expect(gitops.GetSnapshotBatchComponents(batchSnapshot)).To(Equal([]string{hasComp.Name}))
+			Expect(k8sClient.Delete(ctx, batchSnapshot)).To(Succeed())
+		})
+
+		It("ensures a batch interrupted after its Snapshot was created is finished without creating another one", func() {
+			build := gitops.BatchedBuild{PipelineRun: buildPipelineRun.Name, Component: applicationapiv1alpha1.SnapshotComponent{
+				Name:           hasComp.Name,
+				ContainerImage: SampleImage,
+			}}
+			_, _, err := gitops.AddBuildToPendingBatch(k8sClient, ctx, batchApp, build)
+			Expect(err).To(BeNil())
+			pendingBatch, err := gitops.ClosePendingBatch(k8sClient, ctx, batchApp)
+			Expect(err).To(BeNil())
+			batchSnapshot, err := gitops.PrepareBatchSnapshot(k8sClient, ctx, batchApp, &[]applicationapiv1alpha1.Component{*hasComp},
+				[]applicationapiv1alpha1.SnapshotComponent{build.Component}, pendingBatch.SnapshotName)
+			Expect(err).To(BeNil())
+			Expect(k8sClient.Create(ctx, batchSnapshot)).To(Succeed())
+
+			result, err := adapter.EnsureSnapshotExists()
+			Expect(!result.CancelRequest && err == nil).To(BeTrue())
+			Expect(adapter.pipelineRun.Annotations[tekton.SnapshotNameLabel]).To(Equal(pendingBatch.SnapshotName))
+			Expect(gitops.GetPendingBatch(k8sClient, ctx, batchApp)).To(BeNil())
+			Expect(k8sClient.Delete(ctx, batchSnapshot)).To(Succeed())
+		})
+
+		It("ensures a build replaced by a newer build of the same Component in the batch isn't batched again", func() {
+			completionTime := metav1.Now()
+			olderAdapter := adapter
+			olderAdapter.pipelineRun.Status.CompletionTime = &metav1.Time{Time: completionTime.Add(-time.Minute)}
+
+			newerPipelineRun := buildPipelineRun.DeepCopy()
+			newerPipelineRun.ObjectMeta = metav1.ObjectMeta{
+				Name:        buildPipelineRun.Name + "-newer",
+				Namespace:   buildPipelineRun.Namespace,
+				Labels:      buildPipelineRun.Labels,
+				Annotations: buildPipelineRun.Annotations,
+			}
+			Expect(k8sClient.Create(ctx, newerPipelineRun)).To(Succeed())
+			defer func() {
+				Expect(k8sClient.Delete(ctx, newerPipelineRun)).To(Succeed())
+			}()
+			newerPipelineRun.Status = *buildPipelineRun.Status.DeepCopy()
+			newerPipelineRun.Status.CompletionTime = &completionTime
+			log := helpers.IntegrationLogger{Logger: buflogr.NewWithBuffer(&buf)}
+			newerAdapter := NewAdapter(newerPipelineRun, hasComp, batchApp, log, loader.NewMockLoader(), k8sClient, ctx)
+			newerAdapter.context = adapter.context
+
+			// The older build is batched first and replaced once the newer build is batched
+			result, err := olderAdapter.EnsureSnapshotExists()
+			Expect(err).To(BeNil())
+			Expect(result.RequeueDelay).To(BeNumerically(">", 0))
+			result, err = newerAdapter.EnsureSnapshotExists()
+			Expect(err).To(BeNil())
+			Expect(result.RequeueDelay).To(BeNumerically(">", 0))
+
+			olderPipelineRun := &tektonv1beta1.PipelineRun{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: buildPipelineRun.Namespace, Name: buildPipelineRun.Name}, olderPipelineRun)).To(Succeed())
+			Expect(olderPipelineRun.Annotations[gitops.BuildPipelineRunBatchSupersededAnnotation]).To(Equal(newerPipelineRun.Name))
+
+			// The requeued reconciliation of the older build doesn't add it back to the batch, even without the annotation
+			delete(olderAdapter.pipelineRun.Annotations, gitops.BuildPipelineRunBatchSupersededAnnotation)
+			result, err = olderAdapter.EnsureSnapshotExists()
+			Expect(!result.CancelRequest && err == nil).To(BeTrue())
+			Expect(result.RequeueDelay).To(BeZero())
+			Expect(buf.String()).Should(ContainSubstring("A newer build of the Component is in the pending batch"))
+
+			pendingBatch, err := gitops.GetPendingBatch(k8sClient, ctx, batchApp)
+			Expect(err).To(BeNil())
+			Expect(pendingBatch.Builds).To(HaveLen(1))
+			Expect(pendingBatch.Builds[0].PipelineRun).To(Equal(newerPipelineRun.Name))
+
+			closedBatch, err := gitops.ClosePendingBatch(k8sClient, ctx, batchApp)
+			Expect(err).To(BeNil())
+			Expect(gitops.RemovePendingBatch(k8sClient, ctx, batchApp, closedBatch)).To(Succeed())
+		})
+	})
+
 	When("Snapshot already exists", func() {
 		BeforeEach(func() {
 			adapter = NewAdapter(buildPipelineRun, hasComp, hasApp, logger, loader.NewMockLoader(), k8sClient, ctx)
@@ -0,0 +1,171 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterscenario
+
+import (
+	"context"
+	"sort"
+
+	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	"github.com/redhat-appstudio/integration-service/api/v1beta1"
+	"github.com/redhat-appstudio/integration-service/gitops"
+	h "github.com/redhat-appstudio/integration-service/helpers"
+	"github.com/redhat-appstudio/integration-service/loader"
+	"github.com/redhat-appstudio/operator-toolkit/controller"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Adapter holds the objects needed to reconcile a ClusterIntegrationTestScenario.
+type Adapter struct {
+	clusterScenario *v1beta1.ClusterIntegrationTestScenario
+	logger          h.IntegrationLogger
+	loader          loader.ObjectLoader
+	client          client.Client
+	context         context.Context
+}
+
+// NewAdapter creates and returns an Adapter instance.
+func NewAdapter(clusterScenario *v1beta1.ClusterIntegrationTestScenario, logger h.IntegrationLogger, loader loader.ObjectLoader, client client.Client,
+	context context.Context) *Adapter {
+	return &Adapter{
+		clusterScenario: clusterScenario,
+		logger:          logger,
+		loader:          loader,
+		client:          client,
+		context:         context,
+	}
+}
+
+// EnsureInheritingApplicationsRecorded is an operation that ensures the ClusterIntegrationTestScenario is validated
+// and that the Applications inheriting it are recorded in its status.
+func (a *Adapter) EnsureInheritingApplicationsRecorded() (controller.OperationResult, error) {
+	patch := client.MergeFrom(a.clusterScenario.DeepCopy())
+
+	err := gitops.ValidateClusterIntegrationTestScenario(a.clusterScenario)
+	if err != nil {
+		a.logger.Info("ClusterIntegrationTestScenario has invalid selectors", "error", err)
+		SetClusterScenarioStatusAsInvalid(a.clusterScenario, "ClusterIntegrationTestScenario has invalid selectors: "+err.Error())
+		a.clusterScenario.Status.Applications = nil
+		err = a.client.Status().Patch(a.context, a.clusterScenario, patch)
+		if err != nil {
+			a.logger.Error(err, "Failed to update ClusterIntegrationTestScenario")
+			return controller.RequeueWithError(err)
+		}
+		a.logger.LogAuditEvent("ClusterIntegrationTestScenario marked as Invalid. Its selectors are invalid",
+			a.clusterScenario, h.LogActionUpdate)
+		return controller.ContinueProcessing()
+	}
+
+	inheritingApplications, err := a.getInheritingApplications()
+	if err != nil {
+		a.logger.Error(err, "Failed to determine the Applications inheriting the ClusterIntegrationTestScenario")
+		return controller.RequeueWithError(err)
+	}
+
+	SetClusterScenarioStatusAsValid(a.clusterScenario, "ClusterIntegrationTestScenario is Valid.")
+	a.clusterScenario.Status.Applications = inheritingApplications
+	err = a.client.Status().Patch(a.context, a.clusterScenario, patch)
+	if err != nil {
+		a.logger.Error(err, "Failed to update ClusterIntegrationTestScenario")
+		return controller.RequeueWithError(err)
+	}
+	a.logger.Info("Recorded the Applications inheriting the ClusterIntegrationTestScenario",
+		"applications", len(inheritingApplications))
+
+	return controller.ContinueProcessing()
+}
+
+// getInheritingApplications returns the Applications in all namespaces which inherit the ClusterIntegrationTestScenario,
+// each of them in the namespace/name format. The Applications and namespaces are listed by the selectors of the
+// ClusterIntegrationTestScenario, so only the matching ones are loaded from the cache.
+func (a *Adapter) getInheritingApplications() ([]string, error) {
+	inheritingApplications := []string{}
+	if a.clusterScenario.Spec.ApplicationSelector == nil && a.clusterScenario.Spec.NamespaceSelector == nil {
+		return inheritingApplications, nil
+	}
+
+	applicationSelector, err := getSelector(a.clusterScenario.Spec.ApplicationSelector)
+	if err != nil {
+		return nil, err
+	}
+	namespaceSelector, err := getSelector(a.clusterScenario.Spec.NamespaceSelector)
+	if err != nil {
+		return nil, err
+	}
+
+	namespaceNames := []string{metav1.NamespaceAll}
+	if a.clusterScenario.Spec.NamespaceSelector != nil {
+		namespaces := &corev1.NamespaceList{}
+		err = a.client.List(a.context, namespaces, client.MatchingLabelsSelector{Selector: namespaceSelector})
+		if err != nil {
+			return nil, err
+		}
+		namespaceNames = []string{}
+		for _, namespace := range namespaces.Items {
+			namespaceNames = append(namespaceNames, namespace.Name)
+		}
+	}
+
+	for _, namespaceName := range namespaceNames {
+		applications := &applicationapiv1alpha1.ApplicationList{}
+		err = a.client.List(a.context, applications, client.InNamespace(namespaceName),
+			client.MatchingLabelsSelector{Selector: applicationSelector})
+		if err != nil {
+			return nil, err
+		}
+		for _, application := range applications.Items {
+			application := application // G601
+			inheritingApplications = append(inheritingApplications, gitops.GetInheritingApplicationName(&application))
+		}
+	}
+	sort.Strings(inheritingApplications)
+
+	return inheritingApplications, nil
+}
+
+// getSelector returns the selector for the given label selector, a missing label selector matches everything.
+func getSelector(labelSelector *metav1.LabelSelector) (labels.Selector, error) {
+	if labelSelector == nil {
+		return labels.Everything(), nil
+	}
+
+	return metav1.LabelSelectorAsSelector(labelSelector)
+}
+
+// SetClusterScenarioStatusAsInvalid sets the IntegrationTestScenarioValid status condition for the ClusterIntegrationTestScenario to invalid.
+func SetClusterScenarioStatusAsInvalid(clusterScenario *v1beta1.ClusterIntegrationTestScenario, message string) {
+	meta.SetStatusCondition(&clusterScenario.Status.Conditions, metav1.Condition{
+		Type:    gitops.IntegrationTestScenarioValid,
+		Status:  metav1.ConditionFalse,
+		Reason:  gitops.AppStudioIntegrationStatusInvalid,
+		Message: message,
+	})
+}
+
+// SetClusterScenarioStatusAsValid sets the IntegrationTestScenarioValid status condition for the ClusterIntegrationTestScenario to valid.
+func SetClusterScenarioStatusAsValid(clusterScenario *v1beta1.ClusterIntegrationTestScenario, message string) {
+	meta.SetStatusCondition(&clusterScenario.Status.Conditions, metav1.Condition{
+		Type:    gitops.IntegrationTestScenarioValid,
+		Status:  metav1.ConditionTrue,
+		Reason:  gitops.AppStudioIntegrationStatusValid,
+		Message: message,
+	})
+}
@@ -0,0 +1,144 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterscenario
+
+import (
+	"reflect"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	"github.com/redhat-appstudio/integration-service/api/v1beta1"
+	"github.com/redhat-appstudio/integration-service/gitops"
+	"github.com/redhat-appstudio/integration-service/helpers"
+	"github.com/redhat-appstudio/integration-service/loader"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("ClusterIntegrationTestScenario Adapter", Ordered, func() {
+	var (
+		adapter         *Adapter
+		namespace       *corev1.Namespace
+		hasApp          *applicationapiv1alpha1.Application
+		otherApp        *applicationapiv1alpha1.Application
+		clusterScenario *v1beta1.ClusterIntegrationTestScenario
+		logger          helpers.IntegrationLogger
+	)
+
+	BeforeAll(func() {
+		logger = helpers.IntegrationLogger{Logger: ctrl.Log}
+
+		namespace = &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "security-tenant",
+				Labels: map[string]string{
+					"test.appstudio.openshift.io/security-scans": "true",
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, namespace)).Should(Succeed())
+
+		hasApp = &applicationapiv1alpha1.Application{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "application-sample",
+				Namespace: namespace.Name,
+			},
+			Spec: applicationapiv1alpha1.ApplicationSpec{
+				DisplayName: "application-sample",
+			},
+		}
+		Expect(k8sClient.Create(ctx, hasApp)).Should(Succeed())
+
+		otherApp = &applicationapiv1alpha1.Application{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "application-other",
+				Namespace: "default",
+			},
+			Spec: applicationapiv1alpha1.ApplicationSpec{
+				DisplayName: "application-other",
+			},
+		}
+		Expect(k8sClient.Create(ctx, otherApp)).Should(Succeed())
+
+		clusterScenario = &v1beta1.ClusterIntegrationTestScenario{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "security-scan",
+			},
+			Spec: v1beta1.ClusterIntegrationTestScenarioSpec{
+				NamespaceSelector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{
+						"test.appstudio.openshift.io/security-scans": "true",
+					},
+				},
+				ResolverRef: v1beta1.ResolverRef{
+					Resolver: "bundles",
+					Params: []v1beta1.ResolverParameter{
+						{Name: "bundle", Value: "quay.io/redhat-appstudio/example-tekton-bundle:security-scan"},
+					},
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, clusterScenario)).Should(Succeed())
+	})
+
+	BeforeEach(func() {
+		adapter = NewAdapter(clusterScenario, logger, loader.NewMockLoader(), k8sClient, ctx)
+	})
+
+	AfterAll(func() {
+		err := k8sClient.Delete(ctx, clusterScenario)
+		Expect(err == nil || errors.IsNotFound(err)).To(BeTrue())
+		err = k8sClient.Delete(ctx, hasApp)
+		Expect(err == nil || errors.IsNotFound(err)).To(BeTrue())
+		err = k8sClient.Delete(ctx, otherApp)
+		Expect(err == nil || errors.IsNotFound(err)).To(BeTrue())
+		err = k8sClient.Delete(ctx, namespace)
+		Expect(err == nil || errors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("can create a new Adapter instance", func() {
+		Expect(reflect.TypeOf(adapter)).To(Equal(reflect.TypeOf(&Adapter{})))
+	})
+
+	It("ensures the Applications inheriting the ClusterIntegrationTestScenario are recorded", func() {
+		Eventually(func() []string {
+			result, err := adapter.EnsureInheritingApplicationsRecorded()
+			Expect(!result.CancelRequest && err == nil).To(BeTrue())
+			return clusterScenario.Status.Applications
+		}).Should(Equal([]string{"security-tenant/application-sample"}))
+		Expect(meta.IsStatusConditionTrue(clusterScenario.Status.Conditions, gitops.IntegrationTestScenarioValid)).To(BeTrue())
+	})
+
+	It("ensures the ClusterIntegrationTestScenario with invalid selectors is marked as invalid", func() {
+		clusterScenario.Spec.ApplicationSelector = &metav1.LabelSelector{
+			MatchExpressions: []metav1.LabelSelectorRequirement{
+				{Key: "tier", Operator: "Unknown"},
+			},
+		}
+		result, err := adapter.EnsureInheritingApplicationsRecorded()
+		Expect(!result.CancelRequest && err == nil).To(BeTrue())
+		Expect(meta.IsStatusConditionFalse(clusterScenario.Status.Conditions, gitops.IntegrationTestScenarioValid)).To(BeTrue())
+		Expect(clusterScenario.Status.Applications).To(BeEmpty())
+	})
+
+})
@@ -0,0 +1,197 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterscenario
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	"github.com/redhat-appstudio/integration-service/api/v1beta1"
+	"github.com/redhat-appstudio/integration-service/cache"
+	"github.com/redhat-appstudio/integration-service/gitops"
+	"github.com/redhat-appstudio/integration-service/helpers"
+	"github.com/redhat-appstudio/integration-service/loader"
+	"github.com/redhat-appstudio/operator-toolkit/controller"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+// Reconciler reconciles a ClusterIntegrationTestScenario object
+type Reconciler struct {
+	client.Client
+	Log    logr.Logger
+	Scheme *runtime.Scheme
+}
+
+// NewClusterScenarioReconciler creates and returns a Reconciler.
+func NewClusterScenarioReconciler(client client.Client, logger *logr.Logger, scheme *runtime.Scheme) *Reconciler {
+	return &Reconciler{
+		Client: client,
+		Log:    logger.WithName("clusterIntegrationTestScenario"),
+		Scheme: scheme,
+	}
+}
+
+//+kubebuilder:rbac:groups=appstudio.redhat.com,resources=clusterintegrationtestscenarios,verbs=get;list;watch
+//+kubebuilder:rbac:groups=appstudio.redhat.com,resources=clusterintegrationtestscenarios/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=appstudio.redhat.com,resources=applications,verbs=get;list;watch
+//+kubebuilder:rbac:groups=core,resources=namespaces,verbs=get;list;watch
+
+// Reconcile is part of the main kubernetes reconciliation loop which aims to
+// move the current state of the cluster closer to the desired state.
+func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := helpers.IntegrationLogger{Logger: r.Log.WithValues("clusterIntegrationTestScenario", req.NamespacedName)}
+	loader := loader.NewLoader()
+	clusterScenario := &v1beta1.ClusterIntegrationTestScenario{}
+	err := r.Get(ctx, req.NamespacedName, clusterScenario)
+	if err != nil {
+		logger.Error(err, "Failed to get ClusterIntegrationTestScenario from request", "req", req.NamespacedName)
+		if errors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+
+		return ctrl.Result{}, err
+	}
+
+	adapter := NewAdapter(clusterScenario, logger, loader, r.Client, ctx)
+
+	return controller.ReconcileHandler([]controller.Operation{
+		adapter.EnsureInheritingApplicationsRecorded,
+	})
+}
+
+// AdapterInterface is an interface defining all the operations that should be defined in an Integration adapter.
+type AdapterInterface interface {
+	EnsureInheritingApplicationsRecorded() (controller.OperationResult, error)
+}
+
+// SetupController creates a new Integration controller and adds it to the Manager.
+func SetupController(manager ctrl.Manager, log *logr.Logger) error {
+	return setupControllerWithManager(manager, NewClusterScenarioReconciler(manager.GetClient(), log, manager.GetScheme()))
+}
+
+func setupControllerWithManager(manager ctrl.Manager, controller *Reconciler) error {
+	err := setupCache(manager)
+	if err != nil {
+		return err
+	}
+
+	// Creating or deleting an Application or changing the labels of an Application or a namespace changes which
+	// Applications inherit the ClusterIntegrationTestScenarios, only the ones which can be affected are reconciled
+	return ctrl.NewControllerManagedBy(manager).
+		For(&v1beta1.ClusterIntegrationTestScenario{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Watches(&source.Kind{Type: &applicationapiv1alpha1.Application{}}, handler.Funcs{
+			CreateFunc: func(e event.CreateEvent, queue workqueue.RateLimitingInterface) {
+				enqueueRequests(queue, controller.getClusterScenariosForCreatedApplication(e.Object))
+			},
+			UpdateFunc: func(e event.UpdateEvent, queue workqueue.RateLimitingInterface) {
+				enqueueRequests(queue, controller.getClusterScenariosForChangedLabels("spec.applicationSelector", e.ObjectOld, e.ObjectNew))
+			},
+			DeleteFunc: func(e event.DeleteEvent, queue workqueue.RateLimitingInterface) {
+				enqueueRequests(queue, controller.getClusterScenariosForDeletedApplication(e.Object))
+			},
+		}).
+		Watches(&source.Kind{Type: &corev1.Namespace{}}, handler.Funcs{
+			UpdateFunc: func(e event.UpdateEvent, queue workqueue.RateLimitingInterface) {
+				enqueueRequests(queue, controller.getClusterScenariosForChangedLabels("spec.namespaceSelector", e.ObjectOld, e.ObjectNew))
+			},
+		}).
+		Complete(controller)
+}
+
+// setupCache indexes the fields of the ClusterIntegrationTestScenarios used to find the ones affected by changes of
+// the Applications and namespaces, and the ones inherited by an Application.
+func setupCache(mgr ctrl.Manager) error {
+	return cache.SetupClusterIntegrationTestScenarioCache(mgr)
+}
+
+// getClusterScenariosForCreatedApplication maps a created Application to reconcile requests for the
+// ClusterIntegrationTestScenarios whose application selector can match it, i.e. the ones referencing one of its
+// label keys and the ones which can match Applications without any of the labels they reference.
+func (r *Reconciler) getClusterScenariosForCreatedApplication(object client.Object) []reconcile.Request {
+	indexValues := []string{cache.AnyLabelsIndexValue}
+	for key := range object.GetLabels() {
+		indexValues = append(indexValues, key)
+	}
+
+	return r.getClusterScenarioRequests("spec.applicationSelector", indexValues)
+}
+
+// getClusterScenariosForDeletedApplication maps a deleted Application to reconcile requests for the
+// ClusterIntegrationTestScenarios recording it as inheriting them.
+func (r *Reconciler) getClusterScenariosForDeletedApplication(object client.Object) []reconcile.Request {
+	application, ok := object.(*applicationapiv1alpha1.Application)
+	if !ok {
+		return []reconcile.Request{}
+	}
+
+	return r.getClusterScenarioRequests("status.applications", []string{gitops.GetInheritingApplicationName(application)})
+}
+
+// getClusterScenariosForChangedLabels maps an updated Application or namespace to reconcile requests for the
+// ClusterIntegrationTestScenarios whose selector in the given index field references one of the changed label keys.
+func (r *Reconciler) getClusterScenariosForChangedLabels(indexField string, oldObject, newObject client.Object) []reconcile.Request {
+	return r.getClusterScenarioRequests(indexField, helpers.GetChangedLabelKeys(oldObject, newObject))
+}
+
+// getClusterScenarioRequests returns the reconcile requests for the ClusterIntegrationTestScenarios indexed under
+// any of the given values of the index field.
+func (r *Reconciler) getClusterScenarioRequests(indexField string, indexValues []string) []reconcile.Request {
+	requests := []reconcile.Request{}
+	found := map[string]bool{}
+	for _, indexValue := range indexValues {
+		clusterScenarios := &v1beta1.ClusterIntegrationTestScenarioList{}
+		err := r.List(context.Background(), clusterScenarios, client.MatchingFields{indexField: indexValue})
+		if err != nil {
+			r.Log.Error(err, "Failed to list the ClusterIntegrationTestScenarios", indexField, indexValue)
+			return []reconcile.Request{}
+		}
+
+		for _, clusterScenario := range clusterScenarios.Items {
+			if found[clusterScenario.Name] {
+				continue
+			}
+			found[clusterScenario.Name] = true
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Name: clusterScenario.Name,
+				},
+			})
+		}
+	}
+
+	return requests
+}
+
+// enqueueRequests adds the given reconcile requests to the queue.
+func enqueueRequests(queue workqueue.RateLimitingInterface, requests []reconcile.Request) {
+	for _, request := range requests {
+		queue.Add(request)
+	}
+}
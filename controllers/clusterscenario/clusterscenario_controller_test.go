@@ -0,0 +1,166 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterscenario
+
+import (
+	"reflect"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	"github.com/redhat-appstudio/integration-service/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientsetscheme "k8s.io/client-go/kubernetes/scheme"
+)
+
+var _ = Describe("ClusterIntegrationTestScenario Controller", func() {
+	var (
+		manager                    ctrl.Manager
+		clusterScenarioReconciler  *Reconciler
+		scheme                     runtime.Scheme
+		hasApp                     *applicationapiv1alpha1.Application
+		namespaceScenario          *v1beta1.ClusterIntegrationTestScenario
+		applicationScenario        *v1beta1.ClusterIntegrationTestScenario
+		namespaceScenarioRequest   reconcile.Request
+		applicationScenarioRequest reconcile.Request
+	)
+
+	BeforeEach(func() {
+		hasApp = &applicationapiv1alpha1.Application{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "application-sample",
+				Namespace: "default",
+				Labels: map[string]string{
+					"tier": "production",
+				},
+			},
+			Spec: applicationapiv1alpha1.ApplicationSpec{
+				DisplayName: "application-sample",
+			},
+		}
+
+		namespaceScenario = &v1beta1.ClusterIntegrationTestScenario{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "namespace-scan",
+			},
+			Spec: v1beta1.ClusterIntegrationTestScenarioSpec{
+				NamespaceSelector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{
+						"test.appstudio.openshift.io/security-scans": "true",
+					},
+				},
+				ResolverRef: v1beta1.ResolverRef{
+					Resolver: "bundles",
+					Params: []v1beta1.ResolverParameter{
+						{Name: "bundle", Value: "quay.io/redhat-appstudio/example-tekton-bundle:security-scan"},
+					},
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, namespaceScenario)).Should(Succeed())
+		namespaceScenarioRequest = reconcile.Request{NamespacedName: types.NamespacedName{Name: namespaceScenario.Name}}
+
+		applicationScenario = namespaceScenario.DeepCopy()
+		applicationScenario.ObjectMeta = metav1.ObjectMeta{
+			Name: "application-scan",
+		}
+		applicationScenario.Spec.NamespaceSelector = nil
+		applicationScenario.Spec.ApplicationSelector = &metav1.LabelSelector{
+			MatchLabels: map[string]string{
+				"tier": "production",
+			},
+		}
+		Expect(k8sClient.Create(ctx, applicationScenario)).Should(Succeed())
+		applicationScenarioRequest = reconcile.Request{NamespacedName: types.NamespacedName{Name: applicationScenario.Name}}
+
+		var err error
+		manager, err = ctrl.NewManager(cfg, ctrl.Options{
+			Scheme:             clientsetscheme.Scheme,
+			MetricsBindAddress: "0", // this disables metrics
+			LeaderElection:     false,
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		clusterScenarioReconciler = NewClusterScenarioReconciler(k8sClient, &logf.Log, &scheme)
+	})
+
+	AfterEach(func() {
+		err := k8sClient.Delete(ctx, namespaceScenario)
+		Expect(err == nil || errors.IsNotFound(err)).To(BeTrue())
+		err = k8sClient.Delete(ctx, applicationScenario)
+		Expect(err == nil || errors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("can create and return a new Reconciler object", func() {
+		Expect(reflect.TypeOf(clusterScenarioReconciler)).To(Equal(reflect.TypeOf(&Reconciler{})))
+	})
+
+	It("maps a created Application to the ClusterIntegrationTestScenarios whose application selector can match it", func() {
+		Eventually(func() []reconcile.Request {
+			return clusterScenarioReconciler.getClusterScenariosForCreatedApplication(hasApp)
+		}).Should(ConsistOf(namespaceScenarioRequest, applicationScenarioRequest))
+	})
+
+	It("maps label changes only to the ClusterIntegrationTestScenarios whose selectors reference the changed labels", func() {
+		updatedApp := hasApp.DeepCopy()
+		updatedApp.Labels["tier"] = "staging"
+		Eventually(func() []reconcile.Request {
+			return clusterScenarioReconciler.getClusterScenariosForChangedLabels("spec.applicationSelector", hasApp, updatedApp)
+		}).Should(ConsistOf(applicationScenarioRequest))
+
+		updatedApp = hasApp.DeepCopy()
+		updatedApp.Labels["team"] = "security"
+		Expect(clusterScenarioReconciler.getClusterScenariosForChangedLabels("spec.applicationSelector", hasApp, updatedApp)).To(BeEmpty())
+
+		namespace := &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "default",
+			},
+		}
+		updatedNamespace := namespace.DeepCopy()
+		updatedNamespace.Labels = map[string]string{
+			"test.appstudio.openshift.io/security-scans": "true",
+		}
+		Eventually(func() []reconcile.Request {
+			return clusterScenarioReconciler.getClusterScenariosForChangedLabels("spec.namespaceSelector", namespace, updatedNamespace)
+		}).Should(ConsistOf(namespaceScenarioRequest))
+	})
+
+	It("maps a deleted Application to the ClusterIntegrationTestScenarios recording it as inheriting them", func() {
+		Expect(clusterScenarioReconciler.getClusterScenariosForDeletedApplication(hasApp)).To(BeEmpty())
+
+		applicationScenario.Status.Applications = []string{"default/application-sample"}
+		Expect(k8sClient.Status().Update(ctx, applicationScenario)).Should(Succeed())
+		Eventually(func() []reconcile.Request {
+			return clusterScenarioReconciler.getClusterScenariosForDeletedApplication(hasApp)
+		}).Should(ConsistOf(applicationScenarioRequest))
+	})
+
+	It("can setup a new controller manager with the given clusterScenarioReconciler", func() {
+		err := setupControllerWithManager(manager, clusterScenarioReconciler)
+		Expect(err).NotTo(HaveOccurred())
+	})
+})
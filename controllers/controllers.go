@@ -20,6 +20,7 @@ import (
 	"github.com/go-logr/logr"
 	"github.com/redhat-appstudio/integration-service/controllers/binding"
 	"github.com/redhat-appstudio/integration-service/controllers/buildpipeline"
+	"github.com/redhat-appstudio/integration-service/controllers/clusterscenario"
 	"github.com/redhat-appstudio/integration-service/controllers/integrationpipeline"
 	"github.com/redhat-appstudio/integration-service/controllers/scenario"
 	"github.com/redhat-appstudio/integration-service/controllers/snapshot"
@@ -33,6 +34,7 @@ var setupFunctions = []func(manager.Manager, *logr.Logger) error{
 	buildpipeline.SetupController,
 	snapshot.SetupController,
 	scenario.SetupController,
+	clusterscenario.SetupController,
 	binding.SetupController,
 }
 
@@ -30,7 +30,9 @@ import (
 	"github.com/redhat-appstudio/integration-service/tekton"
 	"github.com/redhat-appstudio/operator-toolkit/controller"
 	tektonv1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -79,7 +81,7 @@ func (a *Adapter) EnsureSnapshotPassedAllTests() (controller.OperationResult, er
 
 	// Get all integrationTestScenarios for the Application and then find the latest Succeeded Integration PipelineRuns
 	// for the Snapshot
-	integrationTestScenarios, err := a.loader.GetRequiredIntegrationTestScenariosForApplication(a.client, a.context, a.application)
+	integrationTestScenarios, err := a.getRequiredIntegrationTestScenariosForSnapshot(existingSnapshot)
 	if err != nil {
 		return controller.RequeueWithError(err)
 	}
@@ -91,7 +93,8 @@ func (a *Adapter) EnsureSnapshotPassedAllTests() (controller.OperationResult, er
 	}
 
 	// Skip doing anything if not all Integration PipelineRuns were found for all integrationTestScenarios
-	if len(*integrationTestScenarios) != len(*integrationPipelineRuns) {
+	// and the required combinations of their matrices
+	if countRequiredIntegrationPipelineRuns(existingSnapshot, integrationTestScenarios) != len(*integrationPipelineRuns) {
 		a.logger.Info("Not all required Integration PipelineRuns finished",
 			"snapshot.Name", existingSnapshot.Name,
 			"snapshot.Spec.Components", existingSnapshot.Spec.Components)
@@ -112,6 +115,12 @@ func (a *Adapter) EnsureSnapshotPassedAllTests() (controller.OperationResult, er
 			"snapshot.Name", existingSnapshot.Name)
 		return controller.RequeueWithError(err)
 	}
+	// Required IntegrationTestScenarios skipped because they were invalid or their dependencies failed count as failed
+	if hasRequiredIntegrationTestScenarioBeenSkipped(existingSnapshot, integrationTestScenarios) {
+		a.logger.Info("Some required IntegrationTestScenarios were skipped because they were invalid or their dependencies failed",
+			"snapshot.Name", existingSnapshot.Name)
+		allIntegrationPipelineRunsPassed = false
+	}
 
 	// If the snapshot is a component type, check if the global component list changed in the meantime and
 	// create a composite snapshot if it did. Does not apply for PAC pull request events.
@@ -171,6 +180,157 @@ func (a *Adapter) EnsureSnapshotPassedAllTests() (controller.OperationResult, er
 	return controller.ContinueProcessing()
 }
 
+// EnsureTestRunRecorded is an operation that will ensure that the summary of the finished integration PipelineRun
+// is kept in a TestRunRecord, so the test history of the Application survives the pruning of PipelineRuns and TaskRuns.
+// The oldest TestRunRecords of the Application beyond gitops.MaxTestRunRecordsPerApplication are deleted. The PipelineRun
+// is marked with the gitops.PipelineRunTestRunRecordedAnnotation afterwards, so it's only recorded once.
+func (a *Adapter) EnsureTestRunRecorded() (controller.OperationResult, error) {
+	if !h.HasPipelineRunFinished(a.pipelineRun) || a.application == nil ||
+		h.HasAnnotation(a.pipelineRun, gitops.PipelineRunTestRunRecordedAnnotation) {
+		return controller.ContinueProcessing()
+	}
+
+	snapshot, err := a.loader.GetSnapshotFromPipelineRun(a.client, a.context, a.pipelineRun)
+	if err != nil {
+		a.logger.Error(err, "Failed to get the Snapshot of the Integration PipelineRun")
+		return controller.RequeueWithError(err)
+	}
+	passed, err := h.CalculateIntegrationPipelineRunOutcome(a.client, a.context, a.logger.Logger, a.pipelineRun)
+	if err != nil {
+		a.logger.Error(err, "Failed to get Integration PipelineRun outcome")
+		return controller.RequeueWithError(err)
+	}
+	taskRuns, err := h.GetAllChildTaskRunsForPipelineRun(a.client, a.context, a.logger.Logger, a.pipelineRun)
+	if err != nil {
+		a.logger.Error(err, "Failed to get the TaskRuns of the Integration PipelineRun")
+		return controller.RequeueWithError(err)
+	}
+	testRunRecord, err := gitops.NewTestRunRecord(a.application, snapshot, a.pipelineRun, passed, taskRuns)
+	if err != nil {
+		a.logger.Error(err, "Failed to get the test results of the Integration PipelineRun")
+		return controller.RequeueWithError(err)
+	}
+	err = ctrl.SetControllerReference(a.application, testRunRecord, a.client.Scheme())
+	if err != nil {
+		a.logger.Error(err, "Failed to set the Application as the owner of the TestRunRecord")
+		return controller.RequeueWithError(err)
+	}
+
+	// The TestRunRecord may have been created by an earlier reconcile which failed to mark the PipelineRun
+	err = a.client.Create(a.context, testRunRecord)
+	if err != nil && !errors.IsAlreadyExists(err) {
+		a.logger.Error(err, "Failed to create the TestRunRecord of the Integration PipelineRun")
+		return controller.RequeueWithError(err)
+	} else if err == nil {
+		a.logger.LogAuditEvent("Recorded the summary of the Integration PipelineRun", testRunRecord, h.LogActionAdd,
+			"integrationPipelineRun.Name", a.pipelineRun.Name,
+			"outcome", testRunRecord.Spec.Outcome)
+	}
+
+	testRunRecords, err := a.loader.GetAllTestRunRecordsForApplication(a.client, a.context, a.application)
+	if err != nil {
+		a.logger.Error(err, "Failed to get the TestRunRecords of the Application")
+		return controller.RequeueWithError(err)
+	}
+	for _, prunedTestRunRecord := range gitops.GetTestRunRecordsToPrune(*testRunRecords, gitops.MaxTestRunRecordsPerApplication) {
+		prunedTestRunRecord := prunedTestRunRecord // G601
+		err = a.client.Delete(a.context, &prunedTestRunRecord)
+		if err != nil && !errors.IsNotFound(err) {
+			a.logger.Error(err, "Failed to delete the oldest TestRunRecord of the Application",
+				"testRunRecord.Name", prunedTestRunRecord.Name)
+			return controller.RequeueWithError(err)
+		}
+	}
+
+	patch := client.MergeFrom(a.pipelineRun.DeepCopy())
+	h.AddAnnotation(&a.pipelineRun.ObjectMeta, gitops.PipelineRunTestRunRecordedAnnotation, "true")
+	err = a.client.Patch(a.context, a.pipelineRun, patch)
+	if err != nil {
+		a.logger.Error(err, "Failed to mark the Integration PipelineRun as kept in a TestRunRecord")
+		return controller.RequeueWithError(err)
+	}
+
+	return controller.ContinueProcessing()
+}
+
+// EnsureScenarioFlakinessRecorded is an operation that will ensure that the outcome of the finished integration
+// PipelineRun is recorded in the history of its IntegrationTestScenario, and that the flakiness score and the
+// quarantine of the IntegrationTestScenario are updated accordingly.
+func (a *Adapter) EnsureScenarioFlakinessRecorded() (controller.OperationResult, error) {
+	if !h.HasPipelineRunFinished(a.pipelineRun) || h.HasAnnotation(a.pipelineRun, gitops.PipelineRunFlakinessRecordedAnnotation) {
+		return controller.ContinueProcessing()
+	}
+
+	integrationTestScenario, err := a.loader.GetScenarioFromPipelineRun(a.client, a.context, a.pipelineRun)
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			a.logger.Error(err, "Failed to get the IntegrationTestScenario of the Integration PipelineRun")
+			return controller.RequeueWithError(err)
+		}
+		// IntegrationTestScenarios inherited from ClusterIntegrationTestScenarios or deleted ones have no history
+		integrationTestScenario = nil
+	}
+
+	if integrationTestScenario != nil && !gitops.HasScenarioRunBeenRecorded(integrationTestScenario, a.pipelineRun.Name) {
+		snapshot, err := a.loader.GetSnapshotFromPipelineRun(a.client, a.context, a.pipelineRun)
+		if err != nil {
+			a.logger.Error(err, "Failed to get the Snapshot of the Integration PipelineRun")
+			return controller.RequeueWithError(err)
+		}
+		passed, err := h.CalculateIntegrationPipelineRunOutcome(a.client, a.context, a.logger.Logger, a.pipelineRun)
+		if err != nil {
+			a.logger.Error(err, "Failed to get Integration PipelineRun outcome")
+			return controller.RequeueWithError(err)
+		}
+		taskRuns, err := h.GetAllChildTaskRunsForPipelineRun(a.client, a.context, a.logger.Logger, a.pipelineRun)
+		if err != nil {
+			a.logger.Error(err, "Failed to get the TaskRuns of the Integration PipelineRun")
+			return controller.RequeueWithError(err)
+		}
+		run, err := gitops.NewScenarioRun(snapshot, a.pipelineRun, passed, taskRuns)
+		if err != nil {
+			a.logger.Error(err, "Failed to get the test results of the Integration PipelineRun")
+			return controller.RequeueWithError(err)
+		}
+
+		patch := client.MergeFrom(integrationTestScenario.DeepCopy())
+		gitops.RecordScenarioRun(integrationTestScenario, run)
+		_, err = gitops.UpdateIntegrationTestScenarioQuarantine(integrationTestScenario)
+		if err != nil {
+			// An invalid quarantine threshold only disables the automatic quarantine, the run is recorded regardless
+			a.logger.Error(err, "Failed to determine the quarantine of the IntegrationTestScenario",
+				"integrationTestScenario.Name", integrationTestScenario.Name)
+		}
+		err = a.client.Status().Patch(a.context, integrationTestScenario, patch)
+		if err != nil {
+			a.logger.Error(err, "Failed to record the run in the IntegrationTestScenario status",
+				"integrationTestScenario.Name", integrationTestScenario.Name)
+			return controller.RequeueWithError(err)
+		}
+		a.logger.LogAuditEvent("Recorded the outcome of the Integration PipelineRun in the IntegrationTestScenario history",
+			integrationTestScenario, h.LogActionUpdate,
+			"integrationPipelineRun.Name", a.pipelineRun.Name,
+			"flakinessScore", gitops.GetIntegrationTestScenarioFlakinessScore(integrationTestScenario),
+			"quarantined", gitops.IsIntegrationTestScenarioQuarantined(integrationTestScenario))
+	}
+
+	if integrationTestScenario != nil {
+		metrics.RegisterIntegrationTestScenarioFlakiness(integrationTestScenario.Namespace, integrationTestScenario.Name,
+			gitops.GetIntegrationTestScenarioFlakinessScore(integrationTestScenario),
+			gitops.IsIntegrationTestScenarioQuarantined(integrationTestScenario))
+	}
+
+	patch := client.MergeFrom(a.pipelineRun.DeepCopy())
+	h.AddAnnotation(&a.pipelineRun.ObjectMeta, gitops.PipelineRunFlakinessRecordedAnnotation, "true")
+	err = a.client.Patch(a.context, a.pipelineRun, patch)
+	if err != nil {
+		a.logger.Error(err, "Failed to mark the Integration PipelineRun as recorded in the IntegrationTestScenario history")
+		return controller.RequeueWithError(err)
+	}
+
+	return controller.ContinueProcessing()
+}
+
 // EnsureStatusReported will ensure that integration PipelineRun status is reported to the git provider
 // which (indirectly) triggered its execution.
 func (a *Adapter) EnsureStatusReported() (controller.OperationResult, error) {
@@ -225,11 +385,57 @@ func (a *Adapter) EnsureEphemeralEnvironmentsCleanedUp() (controller.OperationRe
 			a.logger.Error(err, "Failed to delete the Ephemeral Environment")
 			return controller.RequeueWithError(err)
 		}
+
+		// IntegrationTestScenarios with several environments have one ephemeral environment per each of them
+		err = a.cleanUpOtherEphemeralEnvironmentsOfScenario(testEnvironment)
+		if err != nil {
+			a.logger.Error(err, "Failed to delete the other Ephemeral Environments of the IntegrationTestScenario")
+			return controller.RequeueWithError(err)
+		}
 	}
 
 	return controller.ContinueProcessing()
 }
 
+// cleanUpOtherEphemeralEnvironmentsOfScenario deletes the ephemeral environments, other than the given one, that were
+// created to test the Snapshot of the integration PipelineRun with its IntegrationTestScenario.
+func (a *Adapter) cleanUpOtherEphemeralEnvironmentsOfScenario(cleanedUpEnvironment *applicationapiv1alpha1.Environment) error {
+	snapshotName, found := a.pipelineRun.Labels[tekton.SnapshotNameLabel]
+	if !found {
+		return nil
+	}
+	scenarioName, found := a.pipelineRun.Labels[tekton.ScenarioNameLabel]
+	if !found {
+		return nil
+	}
+
+	allEnvironments, err := a.loader.GetAllEnvironments(a.client, a.context, a.application)
+	if err != nil {
+		return err
+	}
+
+	for _, environment := range *allEnvironments {
+		environment := environment // G601
+		if environment.Name == cleanedUpEnvironment.Name || !h.IsEnvironmentEphemeral(&environment) ||
+			!h.HasLabelWithValue(&environment, gitops.SnapshotLabel, snapshotName) ||
+			!h.HasLabelWithValue(&environment, gitops.SnapshotTestScenarioLabel, scenarioName) {
+			continue
+		}
+
+		dtc, err := a.loader.GetDeploymentTargetClaimForEnvironment(a.client, a.context, &environment)
+		if err != nil {
+			return fmt.Errorf("failed to find deploymentTargetClaim defined in environment %s: %w", environment.Name, err)
+		}
+
+		err = h.CleanUpEphemeralEnvironments(a.client, &a.logger, a.context, &environment, dtc)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // getImagePullSpecFromSnapshotComponent gets the full image pullspec from the given Snapshot Component,
 func (a *Adapter) getImagePullSpecFromSnapshotComponent(snapshot *applicationapiv1alpha1.Snapshot, component *applicationapiv1alpha1.Component) (string, error) {
 	for _, snapshotComponent := range snapshot.Spec.Components {
@@ -250,6 +456,23 @@ func (a *Adapter) getComponentSourceFromSnapshotComponent(snapshot *applicationa
 	return nil, fmt.Errorf("couldn't find the requested component source info in the given Snapshot")
 }
 
+// getRequiredIntegrationTestScenariosForSnapshot returns the required IntegrationTestScenarios which test the Snapshot.
+// The scheduled IntegrationTestScenario decides the outcome of the Snapshot created for its run even if it's optional.
+func (a *Adapter) getRequiredIntegrationTestScenariosForSnapshot(snapshot *applicationapiv1alpha1.Snapshot) (*[]v1beta1.IntegrationTestScenario, error) {
+	var integrationTestScenarios *[]v1beta1.IntegrationTestScenario
+	var err error
+	if gitops.IsSnapshotScheduled(snapshot) {
+		integrationTestScenarios, err = a.loader.GetAllIntegrationTestScenariosForApplication(a.client, a.context, a.application)
+	} else {
+		integrationTestScenarios, err = a.loader.GetRequiredIntegrationTestScenariosForApplication(a.client, a.context, a.application)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return gitops.GetIntegrationTestScenariosForSnapshot(snapshot, integrationTestScenarios), nil
+}
+
 // determineIfAllIntegrationPipelinesPassed checks all Integration pipelines passed all of their test tasks.
 // Returns an error if it can't get the PipelineRun outcomes
 func (a *Adapter) determineIfAllIntegrationPipelinesPassed(integrationPipelineRuns *[]tektonv1beta1.PipelineRun) (bool, error) {
@@ -270,12 +493,43 @@ func (a *Adapter) determineIfAllIntegrationPipelinesPassed(integrationPipelineRu
 }
 
 // getAllPipelineRunsForSnapshot loads from the cluster all Integration PipelineRuns for each IntegrationTestScenario
-// associated with the Snapshot. If the Application doesn't have any IntegrationTestScenarios associated with it,
+// associated with the Snapshot. For IntegrationTestScenarios with a matrix, the latest Integration PipelineRun of each
+// required matrix combination is loaded. If the Application doesn't have any IntegrationTestScenarios associated with it,
 // an error will be returned.
 func (a *Adapter) getAllPipelineRunsForSnapshot(snapshot *applicationapiv1alpha1.Snapshot, integrationTestScenarios *[]v1beta1.IntegrationTestScenario) (*[]tektonv1beta1.PipelineRun, error) {
 	var integrationPipelineRuns []tektonv1beta1.PipelineRun
 	for _, integrationTestScenario := range *integrationTestScenarios {
 		integrationTestScenario := integrationTestScenario // G601
+		if gitops.IsIntegrationTestScenarioSkipped(snapshot, integrationTestScenario.Name) {
+			continue
+		}
+		if gitops.HasIntegrationTestScenarioMatrix(&integrationTestScenario) {
+			for _, matrixCombination := range gitops.GetRequiredIntegrationTestScenarioMatrixCombinations(&integrationTestScenario) {
+				if a.pipelineRun.Labels[tekton.ScenarioNameLabel] == integrationTestScenario.Name &&
+					a.pipelineRun.Labels[tekton.MatrixCombinationLabel] == matrixCombination.Name {
+					integrationPipelineRuns = append(integrationPipelineRuns, *a.pipelineRun)
+					a.logger.Info("The current integrationPipelineRun matches the integration test scenario matrix combination",
+						"integrationTestScenario.Name", integrationTestScenario.Name,
+						"matrixCombination.Name", matrixCombination.Name,
+						"integrationPipelineRun.Name", a.pipelineRun.Name)
+					continue
+				}
+
+				integrationPipelineRun, err := loader.GetLatestPipelineRunForSnapshotScenarioAndMatrixCombination(a.client, a.context, a.loader, snapshot, &integrationTestScenario, matrixCombination.Name)
+				if err != nil {
+					return nil, err
+				}
+				if integrationPipelineRun != nil {
+					a.logger.Info("Found existing integrationPipelineRun",
+						"integrationTestScenario.Name", integrationTestScenario.Name,
+						"matrixCombination.Name", matrixCombination.Name,
+						"integrationPipelineRun.Name", integrationPipelineRun.Name)
+					integrationPipelineRuns = append(integrationPipelineRuns, *integrationPipelineRun)
+				}
+			}
+			continue
+		}
+
 		if a.pipelineRun.Labels[tekton.ScenarioNameLabel] != integrationTestScenario.Name {
 			integrationPipelineRun, err := loader.GetLatestPipelineRunForSnapshotAndScenario(a.client, a.context, a.loader, snapshot, &integrationTestScenario)
 			if err != nil {
@@ -298,6 +552,39 @@ func (a *Adapter) getAllPipelineRunsForSnapshot(snapshot *applicationapiv1alpha1
 	return &integrationPipelineRuns, nil
 }
 
+// countRequiredIntegrationPipelineRuns returns the number of Integration PipelineRuns which need to finish before
+// the outcome of the given IntegrationTestScenarios can be determined, one per IntegrationTestScenario
+// or one per required matrix combination for IntegrationTestScenarios with a matrix.
+// IntegrationTestScenarios skipped for the Snapshot don't have any Integration PipelineRuns.
+func countRequiredIntegrationPipelineRuns(snapshot *applicationapiv1alpha1.Snapshot, integrationTestScenarios *[]v1beta1.IntegrationTestScenario) int {
+	count := 0
+	for _, integrationTestScenario := range *integrationTestScenarios {
+		integrationTestScenario := integrationTestScenario // G601
+		if gitops.IsIntegrationTestScenarioSkipped(snapshot, integrationTestScenario.Name) {
+			continue
+		}
+		if gitops.HasIntegrationTestScenarioMatrix(&integrationTestScenario) {
+			count += len(gitops.GetRequiredIntegrationTestScenarioMatrixCombinations(&integrationTestScenario))
+		} else {
+			count++
+		}
+	}
+
+	return count
+}
+
+// hasRequiredIntegrationTestScenarioBeenSkipped returns true if any of the given IntegrationTestScenarios
+// was skipped for the Snapshot.
+func hasRequiredIntegrationTestScenarioBeenSkipped(snapshot *applicationapiv1alpha1.Snapshot, integrationTestScenarios *[]v1beta1.IntegrationTestScenario) bool {
+	for _, integrationTestScenario := range *integrationTestScenarios {
+		if gitops.IsIntegrationTestScenarioSkipped(snapshot, integrationTestScenario.Name) {
+			return true
+		}
+	}
+
+	return false
+}
+
 // prepareCompositeSnapshot prepares the Composite Snapshot for a given application,
 // componentnew, containerImage and newContainerSource. In case the Snapshot can't be created, an error will be returned.
 func (a *Adapter) prepareCompositeSnapshot(application *applicationapiv1alpha1.Application, component *applicationapiv1alpha1.Component, newContainerImage string, newComponentSource *applicationapiv1alpha1.ComponentSource) (*applicationapiv1alpha1.Snapshot, error) {
@@ -337,6 +624,49 @@ func (a *Adapter) createCompositeSnapshotsIfConflictExists(application *applicat
 		return nil, err
 	}
 
+	// Pinned components don't move with the Global Candidate List, so changes of their pins are ignored
+	// when looking for conflicts with the tested Snapshot
+	applicationComponents, err := a.loader.GetAllApplicationComponents(a.client, a.context, application)
+	if err != nil {
+		return nil, err
+	}
+	pinnedImages, err := gitops.GetPinnedComponentImages(application, applicationComponents)
+	if err != nil {
+		return nil, err
+	}
+	compositeComponents := []applicationapiv1alpha1.SnapshotComponent{}
+	for _, snapshotComponent := range compositeSnapshot.Spec.Components {
+		if _, pinned := pinnedImages[snapshotComponent.Name]; !pinned || snapshotComponent.Name == component.Name {
+			compositeComponents = append(compositeComponents, snapshotComponent)
+			continue
+		}
+		for _, testedComponent := range testedSnapshot.Spec.Components {
+			if testedComponent.Name == snapshotComponent.Name {
+				compositeComponents = append(compositeComponents, testedComponent)
+			}
+		}
+	}
+	compositeSnapshot.Spec.Components = compositeComponents
+
+	// When the Application declares dependencies between its Components, only changes of the Components related
+	// to the tested one trigger a composite retest, for the rest the outcome of the tested Snapshot is carried forward
+	if gitops.HasComponentDependencyGraph(applicationComponents) {
+		relatedComponents := gitops.GetRelatedComponents(component.Name, applicationComponents)
+		hasRelatedConflict := false
+		for _, changedComponent := range gitops.GetChangedSnapshotComponents(compositeSnapshot, testedSnapshot) {
+			if relatedComponents[changedComponent] {
+				hasRelatedConflict = true
+				break
+			}
+		}
+		if !hasRelatedConflict {
+			a.logger.Info("Only Components unrelated to the tested Component changed in the meantime, carrying the outcome of the tested Snapshot forward",
+				"snapshot.Name", testedSnapshot.Name,
+				"component.Name", component.Name)
+			return nil, nil
+		}
+	}
+
 	// Copy PAC annotations/labels from testedSnapshot to compositeSnapshot.
 	h.CopyLabelsByPrefix(&testedSnapshot.ObjectMeta, &compositeSnapshot.ObjectMeta, gitops.PipelinesAsCodePrefix, gitops.PipelinesAsCodePrefix)
 	h.CopyAnnotationsByPrefix(&testedSnapshot.ObjectMeta, &compositeSnapshot.ObjectMeta, gitops.PipelinesAsCodePrefix, gitops.PipelinesAsCodePrefix)
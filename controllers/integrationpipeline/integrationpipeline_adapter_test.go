@@ -64,6 +64,14 @@ func (a *MockStatusAdapter) GetReporters(pipelineRun *tektonv1beta1.PipelineRun)
 	return []status.Reporter{a.Reporter}, a.GetReportersError
 }
 
+func (a *MockStatusAdapter) GetReleaseReporters(snapshot *applicationapiv1alpha1.Snapshot) ([]status.ReleaseReporter, error) {
+	return []status.ReleaseReporter{}, nil
+}
+
+func (a *MockStatusAdapter) GetDeploymentReporters(snapshot *applicationapiv1alpha1.Snapshot) ([]status.DeploymentReporter, error) {
+	return []status.DeploymentReporter{}, nil
+}
+
 var _ = Describe("Pipeline Adapter", Ordered, func() {
 	var (
 		adapter        *Adapter
@@ -428,6 +436,21 @@ var _ = Describe("Pipeline Adapter", Ordered, func() {
 			Expect(compositeSnapshot).To(BeNil())
 		})
 
+		It("ensures changes of the pinned components are ignored when looking for conflicts", func() {
+			pinnedComp := hasComp2.DeepCopy()
+			pinnedComp.Annotations = map[string]string{gitops.ComponentPinnedImageAnnotation: SampleImage}
+			adapter.context = loader.GetMockedContext(ctx, []loader.MockData{
+				{
+					ContextKey: loader.ApplicationComponentsContextKey,
+					Resource:   []applicationapiv1alpha1.Component{*hasComp, *pinnedComp},
+				},
+			})
+
+			compositeSnapshot, err := adapter.createCompositeSnapshotsIfConflictExists(hasApp, hasComp, hasSnapshot)
+			Expect(err).To(BeNil())
+			Expect(compositeSnapshot).To(BeNil())
+		})
+
 		It("ensures the component Snapshot is marked as invalid when the global component list is changed", func() {
 			createdSnapshot, err := adapter.loader.GetSnapshotFromPipelineRun(adapter.client, adapter.context, integrationPipelineRunComponent)
 			Expect(err).To(BeNil())
@@ -613,6 +636,67 @@ var _ = Describe("Pipeline Adapter", Ordered, func() {
 			err = k8sClient.Delete(ctx, hasCompNew)
 			Expect(err == nil || k8serrors.IsNotFound(err)).To(BeTrue())
 		})
+
+		It("ensures compositeSnapshot is only created when a related component changed", func() {
+			var buf bytes.Buffer
+			log := helpers.IntegrationLogger{Logger: buflogr.NewWithBuffer(&buf)}
+
+			createdSnapshot, err := adapter.loader.GetSnapshotFromPipelineRun(adapter.client, adapter.context, integrationPipelineRunComponent)
+			Expect(err).To(BeNil())
+			Expect(createdSnapshot).ToNot(BeNil())
+
+			newComponent := func(name string, dependsOn string) applicationapiv1alpha1.Component {
+				return applicationapiv1alpha1.Component{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:        name,
+						Namespace:   "default",
+						Annotations: map[string]string{gitops.ComponentDependsOnAnnotation: dependsOn},
+					},
+					Spec: applicationapiv1alpha1.ComponentSpec{
+						ComponentName:  name,
+						Application:    hasApp.Name,
+						ContainerImage: SampleImage,
+					},
+				}
+			}
+			// The frontend only depends on the backend, which is unrelated to the tested component
+			backend := newComponent("backend", "")
+			frontend := newComponent("frontend", "backend")
+
+			adapter = NewAdapter(integrationPipelineRunComponent, hasComp, hasApp, log, loader.NewMockLoader(), k8sClient, ctx)
+			adapter.context = loader.GetMockedContext(ctx, []loader.MockData{
+				{
+					ContextKey: loader.ApplicationComponentsContextKey,
+					Resource:   []applicationapiv1alpha1.Component{*hasComp, backend, frontend},
+				},
+				{
+					ContextKey: loader.AllSnapshotsContextKey,
+					Resource:   []applicationapiv1alpha1.Snapshot{},
+				},
+			})
+			compositeSnapshot, err := adapter.createCompositeSnapshotsIfConflictExists(hasApp, hasComp, createdSnapshot)
+			Expect(err).To(BeNil())
+			Expect(compositeSnapshot).To(BeNil())
+			Expect(buf.String()).Should(ContainSubstring("Only Components unrelated to the tested Component changed in the meantime"))
+
+			// Once the backend depends on the tested component, its change conflicts with the tested Snapshot
+			backend.Annotations[gitops.ComponentDependsOnAnnotation] = hasComp.Name
+			adapter.context = loader.GetMockedContext(ctx, []loader.MockData{
+				{
+					ContextKey: loader.ApplicationComponentsContextKey,
+					Resource:   []applicationapiv1alpha1.Component{*hasComp, backend, frontend},
+				},
+				{
+					ContextKey: loader.AllSnapshotsContextKey,
+					Resource:   []applicationapiv1alpha1.Snapshot{},
+				},
+			})
+			compositeSnapshot, err = adapter.createCompositeSnapshotsIfConflictExists(hasApp, hasComp, createdSnapshot)
+			Expect(err).To(BeNil())
+			Expect(compositeSnapshot).NotTo(BeNil())
+			Expect(compositeSnapshot.Spec.Components).To(HaveLen(3))
+			Expect(k8sClient.Delete(ctx, compositeSnapshot)).To(Succeed())
+		})
 	})
 
 	When("Snapshot already exists", func() {
@@ -696,6 +780,67 @@ var _ = Describe("Pipeline Adapter", Ordered, func() {
 			Expect(meta.IsStatusConditionTrue(hasSnapshot.Status.Conditions, gitops.AppStudioTestSuceededCondition)).To(BeTrue())
 		})
 
+		It("ensures the summary of the PipelineRun is kept in a TestRunRecord", func() {
+			adapter.context = loader.GetMockedContext(ctx, []loader.MockData{
+				{
+					ContextKey: loader.SnapshotContextKey,
+					Resource:   hasSnapshot,
+				},
+			})
+
+			result, err := adapter.EnsureTestRunRecorded()
+			Expect(!result.CancelRequest && err == nil).To(BeTrue())
+
+			testRunRecord := &v1beta1.TestRunRecord{}
+			Eventually(func() error {
+				return k8sClient.Get(ctx, types.NamespacedName{Namespace: integrationPipelineRunComponent.Namespace, Name: integrationPipelineRunComponent.Name}, testRunRecord)
+			}, time.Second*10).Should(Succeed())
+			Expect(testRunRecord.Spec.Application).To(Equal(hasApp.Name))
+			Expect(testRunRecord.Spec.Scenario).To(Equal(integrationTestScenario.Name))
+			Expect(testRunRecord.Spec.Snapshot).To(Equal(hasSnapshot.Name))
+			Expect(testRunRecord.Spec.Outcome).To(Equal(gitops.TestRunRecordPassed))
+			Expect(testRunRecord.Spec.Tasks).To(HaveLen(1))
+			Expect(testRunRecord.GetOwnerReferences()).To(HaveLen(1))
+
+			Expect(integrationPipelineRunComponent.Annotations).To(HaveKeyWithValue(gitops.PipelineRunTestRunRecordedAnnotation, "true"))
+
+			// The PipelineRun is recorded only once
+			Expect(k8sClient.Delete(ctx, testRunRecord)).Should(Succeed())
+			result, err = adapter.EnsureTestRunRecorded()
+			Expect(!result.CancelRequest && err == nil).To(BeTrue())
+			Consistently(func() bool {
+				err := k8sClient.Get(ctx, types.NamespacedName{Namespace: integrationPipelineRunComponent.Namespace, Name: integrationPipelineRunComponent.Name}, testRunRecord)
+				return k8serrors.IsNotFound(err)
+			}, time.Second*2).Should(BeTrue())
+		})
+
+		It("ensures the outcome of the PipelineRun is recorded in the IntegrationTestScenario history", func() {
+			scenario := integrationTestScenario.DeepCopy()
+			adapter.context = loader.GetMockedContext(ctx, []loader.MockData{
+				{
+					ContextKey: loader.IntegrationTestScenarioContextKey,
+					Resource:   scenario,
+				},
+				{
+					ContextKey: loader.SnapshotContextKey,
+					Resource:   hasSnapshot,
+				},
+			})
+
+			result, err := adapter.EnsureScenarioFlakinessRecorded()
+			Expect(!result.CancelRequest && err == nil).To(BeTrue())
+			Expect(gitops.HasScenarioRunBeenRecorded(scenario, integrationPipelineRunComponent.Name)).To(BeTrue())
+			Expect(scenario.Status.Flakiness.Runs[0].Passed).To(BeTrue())
+			Expect(scenario.Status.Flakiness.Runs[0].PassedTasks).To(Equal([]string{"task1"}))
+			Expect(integrationPipelineRunComponent.Annotations).To(HaveKeyWithValue(gitops.PipelineRunFlakinessRecordedAnnotation, "true"))
+
+			Eventually(func() bool {
+				recordedScenario := &v1beta1.IntegrationTestScenario{}
+				err := k8sClient.Get(ctx, types.NamespacedName{Namespace: scenario.Namespace, Name: scenario.Name}, recordedScenario)
+				return err == nil && gitops.HasScenarioRunBeenRecorded(recordedScenario, integrationPipelineRunComponent.Name)
+			}, time.Second*10).Should(BeTrue())
+		})
+
 		It("ensures Snapshot failed once one pipeline failed", func() {
 			//Create one failed scenario and its failed pipelineRun
 			integrationTestScenarioFailed = &v1beta1.IntegrationTestScenario{
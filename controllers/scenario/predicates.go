@@ -5,8 +5,8 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 )
 
-// IntegrationPipelineRunStartedPredicate returns a predicate which filters out
-// only created integration scenarios
+// IntegrationScenarioCreatedPredicate returns a predicate which filters out
+// only created integration scenarios and the ones whose spec changed
 func IntegrationScenarioCreatedPredicate() predicate.Predicate {
 	return predicate.Funcs{
 		CreateFunc: func(createEvent event.CreateEvent) bool {
@@ -19,7 +19,7 @@ func IntegrationScenarioCreatedPredicate() predicate.Predicate {
 			return false
 		},
 		UpdateFunc: func(e event.UpdateEvent) bool {
-			return true
+			return e.ObjectNew.GetGeneration() != e.ObjectOld.GetGeneration()
 		},
 	}
 }
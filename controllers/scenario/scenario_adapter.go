@@ -16,7 +16,9 @@ package scenario
 import (
 	"context"
 	"reflect"
+	"time"
 
+	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
@@ -26,6 +28,7 @@ import (
 	"github.com/redhat-appstudio/integration-service/api/v1beta1"
 	"github.com/redhat-appstudio/integration-service/gitops"
 	h "github.com/redhat-appstudio/integration-service/helpers"
+	"github.com/redhat-appstudio/integration-service/loader"
 	"github.com/redhat-appstudio/operator-toolkit/controller"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -35,17 +38,19 @@ type Adapter struct {
 	application *applicationapiv1alpha1.Application
 	scenario    *v1beta1.IntegrationTestScenario
 	logger      h.IntegrationLogger
+	loader      loader.ObjectLoader
 	client      client.Client
 	context     context.Context
 }
 
 // NewAdapter creates and returns an Adapter instance.
-func NewAdapter(application *applicationapiv1alpha1.Application, scenario *v1beta1.IntegrationTestScenario, logger h.IntegrationLogger, client client.Client,
+func NewAdapter(application *applicationapiv1alpha1.Application, scenario *v1beta1.IntegrationTestScenario, logger h.IntegrationLogger, loader loader.ObjectLoader, client client.Client,
 	context context.Context) *Adapter {
 	return &Adapter{
 		application: application,
 		scenario:    scenario,
 		logger:      logger,
+		loader:      loader,
 		client:      client,
 		context:     context,
 	}
@@ -55,64 +60,130 @@ func NewAdapter(application *applicationapiv1alpha1.Application, scenario *v1bet
 // in case it is, set its owner reference
 func (a *Adapter) EnsureCreatedScenarioIsValid() (controller.OperationResult, error) {
 
-	// First check if application exists or not
-	if a.application == nil {
-		a.logger.Info("Application for scenario was not found.")
-
-		patch := client.MergeFrom(a.scenario.DeepCopy())
-		SetScenarioIntegrationStatusAsInvalid(a.scenario, "Failed to get application for scenario.")
-		err := a.client.Status().Patch(a.context, a.scenario, patch)
+	if gitops.HasIntegrationTestScenarioApplicationSelector(a.scenario) {
+		// Scenarios selecting their applications by label aren't owned by any of them, as deleting
+		// one of the selected applications mustn't garbage collect the scenario
+		_, err := metav1.LabelSelectorAsSelector(a.scenario.Spec.ApplicationSelector)
 		if err != nil {
-			a.logger.Error(err, "Failed to update Scenario")
-			return controller.RequeueWithError(err)
+			a.logger.Info("IntegrationTestScenario has an invalid application selector", "error", err)
+			patch := client.MergeFrom(a.scenario.DeepCopy())
+			SetScenarioIntegrationStatusAsInvalid(a.scenario, "IntegrationTestScenario has an invalid application selector: "+err.Error())
+			err = a.client.Status().Patch(a.context, a.scenario, patch)
+			if err != nil {
+				a.logger.Error(err, "Failed to update Scenario")
+				return controller.RequeueWithError(err)
+			}
+			a.logger.LogAuditEvent("IntegrationTestScenario marked as Invalid. The application selector is not valid",
+				a.scenario, h.LogActionUpdate)
+			return controller.ContinueProcessing()
 		}
-		return controller.ContinueProcessing()
-	}
 
-	// application exist, always log it
-	a.logger = a.logger.WithApp(*a.application)
-	// Checks if scenario has ownerReference assigned to it
-	if a.scenario.OwnerReferences == nil {
 		patch := client.MergeFrom(a.scenario.DeepCopy())
-		err := ctrl.SetControllerReference(a.application, a.scenario, a.client.Scheme())
-		if err != nil {
-			a.logger.Error(err, "Error setting owner reference.")
-			return controller.RequeueWithError(err)
+		if removeApplicationOwners(a.scenario) {
+			err = a.client.Patch(a.context, a.scenario, patch)
+			if err != nil {
+				a.logger.Error(err, "Failed to update Scenario")
+				return controller.RequeueWithError(err)
+			}
+			a.logger.LogAuditEvent("Application owner references removed from the IntegrationTestScenario selecting Applications by label",
+				a.scenario, h.LogActionUpdate)
 		}
-		err = a.client.Patch(a.context, a.scenario, patch)
-		if err != nil {
-			a.logger.Error(err, "Failed to update Scenario")
-			return controller.RequeueWithError(err)
+	} else {
+		// First check if application exists or not
+		if a.application == nil {
+			a.logger.Info("Application for scenario was not found.")
+
+			patch := client.MergeFrom(a.scenario.DeepCopy())
+			SetScenarioIntegrationStatusAsInvalid(a.scenario, "Failed to get application for scenario.")
+			err := a.client.Status().Patch(a.context, a.scenario, patch)
+			if err != nil {
+				a.logger.Error(err, "Failed to update Scenario")
+				return controller.RequeueWithError(err)
+			}
+			return controller.ContinueProcessing()
 		}
 
+		// application exist, always log it
+		a.logger = a.logger.WithApp(*a.application)
+		// Checks if scenario has ownerReference assigned to it
+		if a.scenario.OwnerReferences == nil {
+			patch := client.MergeFrom(a.scenario.DeepCopy())
+			err := ctrl.SetControllerReference(a.application, a.scenario, a.client.Scheme())
+			if err != nil {
+				a.logger.Error(err, "Error setting owner reference.")
+				return controller.RequeueWithError(err)
+			}
+			err = a.client.Patch(a.context, a.scenario, patch)
+			if err != nil {
+				a.logger.Error(err, "Failed to update Scenario")
+				return controller.RequeueWithError(err)
+			}
+
+		}
 	}
 	// Checks if scenario has environment defined
-	if reflect.ValueOf(a.scenario.Spec.Environment).IsZero() {
+	if !gitops.HasIntegrationTestScenarioEnvironments(a.scenario) {
 		a.logger.Info("IntegrationTestScenario has no environment defined")
 	} else {
-		//Same as function getEnvironmentFromIntegrationTestScenario - this could be later changed to call only that function
-		ITSEnv := &applicationapiv1alpha1.Environment{}
+		for _, testEnvironment := range gitops.GetIntegrationTestScenarioEnvironments(a.scenario) {
+			//Same as function getEnvironmentFromIntegrationTestScenario - this could be later changed to call only that function
+			ITSEnv := &applicationapiv1alpha1.Environment{}
+
+			err := a.client.Get(a.context, types.NamespacedName{
+				Namespace: a.scenario.Namespace,
+				Name:      testEnvironment.Name,
+			}, ITSEnv)
 
-		err := a.client.Get(a.context, types.NamespacedName{
-			Namespace: a.application.Namespace,
-			Name:      a.scenario.Spec.Environment.Name,
-		}, ITSEnv)
+			if err != nil {
+				a.logger.Info("Environment doesn't exist in same namespace as IntegrationTestScenario.",
+					"environment.Name:", testEnvironment.Name)
+				patch := client.MergeFrom(a.scenario.DeepCopy())
+				SetScenarioIntegrationStatusAsInvalid(a.scenario, "Environment "+testEnvironment.Name+" is located in different namespace than scenario.")
+				err = a.client.Status().Patch(a.context, a.scenario, patch)
+				if err != nil {
+					a.logger.Error(err, "Failed to update Scenario")
+					return controller.RequeueWithError(err)
+				}
+				a.logger.LogAuditEvent("IntegrationTestScenario marked as Invalid. Environment "+testEnvironment.Name+" is located in different namespace than scenario. ",
+					a.scenario, h.LogActionUpdate)
+				return controller.ContinueProcessing()
+			}
+		}
 
+		// The ephemeral copies of the environments are provisioned through a DeploymentTargetClass
+		_, err := a.loader.FindAvailableDeploymentTargetClass(a.client, a.context)
 		if err != nil {
-			a.logger.Info("Environment doesn't exist in same namespace as IntegrationTestScenario.",
-				"environment.Name:", a.scenario.Spec.Environment.Name)
+			a.logger.Info("No DeploymentTargetClass is available to provision the environments of the IntegrationTestScenario",
+				"error", err)
 			patch := client.MergeFrom(a.scenario.DeepCopy())
-			SetScenarioIntegrationStatusAsInvalid(a.scenario, "Environment "+a.scenario.Spec.Environment.Name+" is located in different namespace than scenario.")
+			SetScenarioIntegrationStatusAsInvalid(a.scenario, "No DeploymentTargetClass is available to provision the environments of the scenario: "+err.Error())
 			err = a.client.Status().Patch(a.context, a.scenario, patch)
 			if err != nil {
 				a.logger.Error(err, "Failed to update Scenario")
 				return controller.RequeueWithError(err)
 			}
-			a.logger.LogAuditEvent("IntegrationTestScenario marked as Invalid. Environment "+a.scenario.Spec.Environment.Name+" is located in different namespace than scenario. ",
+			a.logger.LogAuditEvent("IntegrationTestScenario marked as Invalid. No DeploymentTargetClass is available to provision its environments",
 				a.scenario, h.LogActionUpdate)
 			return controller.ContinueProcessing()
 		}
+	}
 
+	// Checks if the schedule of the scenario is a valid cron expression
+	if gitops.IsIntegrationTestScenarioScheduled(a.scenario) {
+		_, err := gitops.GetIntegrationTestScenarioSchedule(a.scenario)
+		if err != nil {
+			a.logger.Info("IntegrationTestScenario has an invalid schedule", "error", err)
+			patch := client.MergeFrom(a.scenario.DeepCopy())
+			SetScenarioIntegrationStatusAsInvalid(a.scenario, "IntegrationTestScenario has an invalid schedule: "+err.Error())
+			err = a.client.Status().Patch(a.context, a.scenario, patch)
+			if err != nil {
+				a.logger.Error(err, "Failed to update Scenario")
+				return controller.RequeueWithError(err)
+			}
+			a.logger.LogAuditEvent("IntegrationTestScenario marked as Invalid. The schedule is not a valid cron expression",
+				a.scenario, h.LogActionUpdate)
+			return controller.ContinueProcessing()
+		}
 	}
 
 	if reflect.ValueOf(a.scenario.Status).IsZero() || (meta.IsStatusConditionFalse(a.scenario.Status.Conditions, gitops.IntegrationTestScenarioValid)) {
@@ -129,6 +200,80 @@ func (a *Adapter) EnsureCreatedScenarioIsValid() (controller.OperationResult, er
 	return controller.ContinueProcessing()
 }
 
+// EnsureScheduledSnapshotCreated is an operation that will ensure that a Snapshot of the Global Candidate List of
+// the Application is created for every scheduled run of the IntegrationTestScenario, and that the results of its
+// previous runs are recorded in its status.
+func (a *Adapter) EnsureScheduledSnapshotCreated() (controller.OperationResult, error) {
+	if a.application == nil || !gitops.IsIntegrationTestScenarioScheduled(a.scenario) ||
+		meta.IsStatusConditionFalse(a.scenario.Status.Conditions, gitops.IntegrationTestScenarioValid) {
+		return controller.ContinueProcessing()
+	}
+
+	if len(a.scenario.Status.ScheduledRuns) > 0 {
+		snapshots, err := a.loader.GetAllSnapshots(a.client, a.context, a.application)
+		if err != nil {
+			a.logger.Error(err, "Failed to get all Snapshots of the Application")
+			return controller.RequeueWithError(err)
+		}
+		patch := client.MergeFrom(a.scenario.DeepCopy())
+		if gitops.UpdateScheduledRunResults(a.scenario, snapshots) {
+			err = a.client.Status().Patch(a.context, a.scenario, patch)
+			if err != nil {
+				a.logger.Error(err, "Failed to update the results of the scheduled runs of the Scenario")
+				return controller.RequeueWithError(err)
+			}
+			a.logger.LogAuditEvent("Results of the scheduled runs of the IntegrationTestScenario updated", a.scenario, h.LogActionUpdate)
+		}
+	}
+
+	nextScheduleTime, err := gitops.GetNextScheduleTime(a.scenario)
+	if err != nil {
+		a.logger.Error(err, "Failed to get the next scheduled run of the IntegrationTestScenario")
+		return controller.ContinueProcessing()
+	}
+	if time.Now().Before(nextScheduleTime) {
+		return controller.RequeueAfter(time.Until(nextScheduleTime), nil)
+	}
+
+	applicationComponents, err := a.loader.GetAllApplicationComponents(a.client, a.context, a.application)
+	if err != nil {
+		a.logger.Error(err, "Failed to get all Components of the Application")
+		return controller.RequeueWithError(err)
+	}
+	snapshot, err := gitops.PrepareScheduledSnapshot(a.client, a.context, a.application, applicationComponents, a.scenario, nextScheduleTime)
+	if err != nil {
+		a.logger.Error(err, "Failed to prepare the Snapshot of the Global Candidate List for the scheduled run")
+		return controller.RequeueWithError(err)
+	}
+	// The Snapshot of the run may have been created before recording the run failed
+	err = a.client.Create(a.context, snapshot)
+	if err != nil && !errors.IsAlreadyExists(err) {
+		a.logger.Error(err, "Failed to create the Snapshot of the Global Candidate List for the scheduled run")
+		return controller.RequeueWithError(err)
+	}
+	if err == nil {
+		a.logger.LogAuditEvent("Created a Snapshot of the Global Candidate List for the scheduled run of the IntegrationTestScenario",
+			snapshot, h.LogActionAdd,
+			"integrationTestScenario.Name", a.scenario.Name)
+	}
+
+	// Missed runs aren't caught up on, the next run is scheduled after the current time
+	patch := client.MergeFrom(a.scenario.DeepCopy())
+	gitops.RecordScheduledRun(a.scenario, snapshot, time.Now())
+	err = a.client.Status().Patch(a.context, a.scenario, patch)
+	if err != nil {
+		a.logger.Error(err, "Failed to record the scheduled run of the Scenario")
+		return controller.RequeueWithError(err)
+	}
+
+	nextScheduleTime, err = gitops.GetNextScheduleTime(a.scenario)
+	if err != nil {
+		return controller.ContinueProcessing()
+	}
+
+	return controller.RequeueAfter(time.Until(nextScheduleTime), nil)
+}
+
 // SetScenarioIntegrationStatusAsInvalid sets the IntegrationTestScenarioValid status condition for the Scenario to invalid.
 func SetScenarioIntegrationStatusAsInvalid(scenario *v1beta1.IntegrationTestScenario, message string) {
 	meta.SetStatusCondition(&scenario.Status.Conditions, metav1.Condition{
@@ -148,3 +293,20 @@ func SetScenarioIntegrationStatusAsValid(scenario *v1beta1.IntegrationTestScenar
 		Message: message,
 	})
 }
+
+// removeApplicationOwners removes the owner references of Applications from the IntegrationTestScenario.
+// Returns true if any owner reference was removed.
+func removeApplicationOwners(scenario *v1beta1.IntegrationTestScenario) bool {
+	ownerReferences := []metav1.OwnerReference{}
+	for _, ownerReference := range scenario.OwnerReferences {
+		if ownerReference.Kind != "Application" {
+			ownerReferences = append(ownerReferences, ownerReference)
+		}
+	}
+	if len(ownerReferences) == len(scenario.OwnerReferences) {
+		return false
+	}
+	scenario.OwnerReferences = ownerReferences
+
+	return true
+}
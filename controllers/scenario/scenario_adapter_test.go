@@ -1,6 +1,7 @@
 package scenario
 
 import (
+	"fmt"
 	"reflect"
 	"time"
 
@@ -13,11 +14,15 @@ import (
 	"github.com/redhat-appstudio/integration-service/api/v1beta1"
 	"github.com/redhat-appstudio/integration-service/gitops"
 	"github.com/redhat-appstudio/integration-service/helpers"
+	"github.com/redhat-appstudio/integration-service/loader"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 )
 
 var _ = Describe("Scenario Adapter", Ordered, func() {
@@ -157,7 +162,7 @@ var _ = Describe("Scenario Adapter", Ordered, func() {
 		}
 		Expect(k8sClient.Create(ctx, &env)).Should(Succeed())
 
-		adapter = NewAdapter(hasApp, integrationTestScenario, logger, k8sClient, ctx)
+		adapter = NewAdapter(hasApp, integrationTestScenario, logger, loader.NewMockLoader(), k8sClient, ctx)
 		Expect(reflect.TypeOf(adapter)).To(Equal(reflect.TypeOf(&Adapter{})))
 	})
 
@@ -177,15 +182,15 @@ var _ = Describe("Scenario Adapter", Ordered, func() {
 	})
 
 	It("can create a new Adapter instance", func() {
-		Expect(reflect.TypeOf(NewAdapter(hasApp, integrationTestScenario, logger, k8sClient, ctx))).To(Equal(reflect.TypeOf(&Adapter{})))
+		Expect(reflect.TypeOf(NewAdapter(hasApp, integrationTestScenario, logger, loader.NewMockLoader(), k8sClient, ctx))).To(Equal(reflect.TypeOf(&Adapter{})))
 	})
 
 	It("can create a new Adapter instance with invalid scenario", func() {
-		Expect(reflect.TypeOf(NewAdapter(hasApp, invalidScenario, logger, k8sClient, ctx))).To(Equal(reflect.TypeOf(&Adapter{})))
+		Expect(reflect.TypeOf(NewAdapter(hasApp, invalidScenario, logger, loader.NewMockLoader(), k8sClient, ctx))).To(Equal(reflect.TypeOf(&Adapter{})))
 	})
 
 	It("EnsureCreatedScenarioIsValid without app", func() {
-		a := NewAdapter(nil, integrationTestScenario, logger, k8sClient, ctx)
+		a := NewAdapter(nil, integrationTestScenario, logger, loader.NewMockLoader(), k8sClient, ctx)
 
 		Eventually(func() bool {
 			result, err := a.EnsureCreatedScenarioIsValid()
@@ -235,6 +240,261 @@ var _ = Describe("Scenario Adapter", Ordered, func() {
 		}, time.Second*20).Should(BeTrue())
 	})
 
+	It("ensures the scenario is valid when a DeploymentTargetClass is available for its environments", func() {
+		adapter.context = loader.GetMockedContext(ctx, []loader.MockData{
+			{
+				ContextKey: loader.DeploymentTargetClassContextKey,
+				Resource: &applicationapiv1alpha1.DeploymentTargetClass{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "dtcls-name",
+					},
+					Spec: applicationapiv1alpha1.DeploymentTargetClassSpec{
+						Provisioner: applicationapiv1alpha1.Provisioner_Devsandbox,
+					},
+				},
+			},
+		})
+		result, err := adapter.EnsureCreatedScenarioIsValid()
+		Expect(!result.CancelRequest && err == nil).To(BeTrue())
+		Expect(meta.IsStatusConditionTrue(integrationTestScenario.Status.Conditions, gitops.IntegrationTestScenarioValid)).To(BeTrue())
+	})
+
+	It("ensures the scenario is invalid when no DeploymentTargetClass is available for its environments", func() {
+		adapter.context = loader.GetMockedContext(ctx, []loader.MockData{
+			{
+				ContextKey: loader.DeploymentTargetClassContextKey,
+				Err:        fmt.Errorf("cannot find the avaiable DeploymentTargetClass"),
+			},
+		})
+		result, err := adapter.EnsureCreatedScenarioIsValid()
+		Expect(!result.CancelRequest && err == nil).To(BeTrue())
+		Expect(meta.IsStatusConditionFalse(integrationTestScenario.Status.Conditions, gitops.IntegrationTestScenarioValid)).To(BeTrue())
+		condition := meta.FindStatusCondition(integrationTestScenario.Status.Conditions, gitops.IntegrationTestScenarioValid)
+		Expect(condition.Message).To(ContainSubstring("No DeploymentTargetClass is available"))
+	})
+
+	When("the scenario selects its applications by label", func() {
+
+		var (
+			selectingScenario *v1beta1.IntegrationTestScenario
+			backendApp        *applicationapiv1alpha1.Application
+		)
+
+		BeforeEach(func() {
+			backendApp = &applicationapiv1alpha1.Application{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "application-backend",
+					Namespace: "default",
+					Labels: map[string]string{
+						"tier": "backend",
+					},
+				},
+				Spec: applicationapiv1alpha1.ApplicationSpec{
+					DisplayName: "application-backend",
+				},
+			}
+			Expect(k8sClient.Create(ctx, backendApp)).Should(Succeed())
+
+			selectingScenario = &v1beta1.IntegrationTestScenario{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "example-selecting",
+					Namespace: "default",
+				},
+				Spec: v1beta1.IntegrationTestScenarioSpec{
+					ApplicationSelector: &metav1.LabelSelector{
+						MatchLabels: map[string]string{
+							"tier": "backend",
+						},
+					},
+					ResolverRef: integrationTestScenario.Spec.ResolverRef,
+				},
+			}
+			Expect(k8sClient.Create(ctx, selectingScenario)).Should(Succeed())
+		})
+
+		AfterEach(func() {
+			err := k8sClient.Delete(ctx, selectingScenario)
+			Expect(err == nil || errors.IsNotFound(err)).To(BeTrue())
+			err = k8sClient.Delete(ctx, backendApp)
+			Expect(err == nil || errors.IsNotFound(err)).To(BeTrue())
+		})
+
+		It("ensures the scenario isn't owned by the applications it selects", func() {
+			patch := client.MergeFrom(selectingScenario.DeepCopy())
+			Expect(controllerutil.SetOwnerReference(backendApp, selectingScenario, k8sClient.Scheme())).To(Succeed())
+			Expect(k8sClient.Patch(ctx, selectingScenario, patch)).Should(Succeed())
+			Expect(selectingScenario.OwnerReferences).To(HaveLen(1))
+
+			adapter = NewAdapter(nil, selectingScenario, logger, loader.NewMockLoader(), k8sClient, ctx)
+			result, err := adapter.EnsureCreatedScenarioIsValid()
+			Expect(!result.CancelRequest && err == nil).To(BeTrue())
+
+			Expect(selectingScenario.OwnerReferences).To(BeEmpty())
+			Expect(meta.IsStatusConditionTrue(selectingScenario.Status.Conditions, gitops.IntegrationTestScenarioValid)).To(BeTrue())
+		})
+
+		It("ensures a scenario with an invalid application selector is marked as invalid", func() {
+			selectingScenario.Spec.ApplicationSelector.MatchExpressions = []metav1.LabelSelectorRequirement{
+				{Key: "tier", Operator: "Matches"},
+			}
+			adapter = NewAdapter(nil, selectingScenario, logger, loader.NewMockLoader(), k8sClient, ctx)
+			result, err := adapter.EnsureCreatedScenarioIsValid()
+			Expect(!result.CancelRequest && err == nil).To(BeTrue())
+
+			condition := meta.FindStatusCondition(selectingScenario.Status.Conditions, gitops.IntegrationTestScenarioValid)
+			Expect(condition).NotTo(BeNil())
+			Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+			Expect(condition.Message).To(ContainSubstring("invalid application selector"))
+		})
+	})
+
+	When("the scenario runs on a schedule", func() {
+
+		var (
+			scheduledScenario *v1beta1.IntegrationTestScenario
+			hasComp           *applicationapiv1alpha1.Component
+		)
+
+		BeforeEach(func() {
+			scheduledScenario = &v1beta1.IntegrationTestScenario{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "example-scheduled",
+					Namespace: "default",
+				},
+				Spec: v1beta1.IntegrationTestScenarioSpec{
+					Application: hasApp.Name,
+					ResolverRef: integrationTestScenario.Spec.ResolverRef,
+					Schedule:    "0 * * * *",
+				},
+			}
+			Expect(k8sClient.Create(ctx, scheduledScenario)).Should(Succeed())
+			SetScenarioIntegrationStatusAsValid(scheduledScenario, "Integration test scenario is Valid.")
+			scheduledScenario.Status.LastScheduleTime = &metav1.Time{Time: time.Now().Add(-2 * time.Hour)}
+			Expect(k8sClient.Status().Update(ctx, scheduledScenario)).Should(Succeed())
+
+			hasComp = &applicationapiv1alpha1.Component{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "component-sample",
+					Namespace: "default",
+				},
+				Spec: applicationapiv1alpha1.ComponentSpec{
+					ComponentName:  "component-sample",
+					Application:    hasApp.Name,
+					ContainerImage: "quay.io/redhat-appstudio/sample-image@sha256:841328df1b9f8c4087adbdcfec6cc99ac8308805dea83f6d415d6fb8d40227c1",
+					Source: applicationapiv1alpha1.ComponentSource{
+						ComponentSourceUnion: applicationapiv1alpha1.ComponentSourceUnion{
+							GitSource: &applicationapiv1alpha1.GitSource{
+								URL: SampleRepoLink,
+							},
+						},
+					},
+				},
+			}
+		})
+
+		AfterEach(func() {
+			err := k8sClient.Delete(ctx, scheduledScenario)
+			Expect(err == nil || errors.IsNotFound(err)).To(BeTrue())
+			snapshots := &applicationapiv1alpha1.SnapshotList{}
+			Expect(k8sClient.List(ctx, snapshots)).To(Succeed())
+			for _, snapshot := range snapshots.Items {
+				snapshot := snapshot
+				err = k8sClient.Delete(ctx, &snapshot)
+				Expect(err == nil || errors.IsNotFound(err)).To(BeTrue())
+			}
+		})
+
+		It("ensures a Snapshot of the Global Candidate List is created at each scheduled run", func() {
+			adapter = NewAdapter(hasApp, scheduledScenario, logger, loader.NewMockLoader(), k8sClient, ctx)
+			adapter.context = loader.GetMockedContext(ctx, []loader.MockData{
+				{
+					ContextKey: loader.ApplicationComponentsContextKey,
+					Resource:   []applicationapiv1alpha1.Component{*hasComp},
+				},
+			})
+
+			result, err := adapter.EnsureScheduledSnapshotCreated()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueDelay).To(BeNumerically(">", 0))
+			Expect(result.RequeueDelay).To(BeNumerically("<=", time.Hour))
+
+			Expect(scheduledScenario.Status.ScheduledRuns).To(HaveLen(1))
+			Expect(scheduledScenario.Status.ScheduledRuns[0].Result).To(Equal(gitops.ScheduledRunInProgress))
+
+			snapshot := &applicationapiv1alpha1.Snapshot{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{
+				Namespace: hasApp.Namespace,
+				Name:      scheduledScenario.Status.ScheduledRuns[0].Snapshot,
+			}, snapshot)).To(Succeed())
+			Expect(gitops.IsSnapshotScheduled(snapshot)).To(BeTrue())
+			Expect(snapshot.Labels[gitops.SnapshotTestScenarioLabel]).To(Equal(scheduledScenario.Name))
+			Expect(snapshot.Spec.Components).To(HaveLen(1))
+			Expect(snapshot.Spec.Components[0].ContainerImage).To(Equal(hasComp.Spec.ContainerImage))
+
+			// The next run isn't due yet, so only the result of the finished run is recorded
+			meta.SetStatusCondition(&snapshot.Status.Conditions, metav1.Condition{
+				Type:    gitops.AppStudioTestSuceededCondition,
+				Status:  metav1.ConditionTrue,
+				Reason:  gitops.AppStudioTestSuceededConditionPassed,
+				Message: "Snapshot passed all the required IntegrationTestScenarios",
+			})
+			adapter.context = loader.GetMockedContext(ctx, []loader.MockData{
+				{
+					ContextKey: loader.AllSnapshotsContextKey,
+					Resource:   []applicationapiv1alpha1.Snapshot{*snapshot},
+				},
+			})
+
+			result, err = adapter.EnsureScheduledSnapshotCreated()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueDelay).To(BeNumerically(">", 0))
+			Expect(scheduledScenario.Status.ScheduledRuns).To(HaveLen(1))
+			Expect(scheduledScenario.Status.ScheduledRuns[0].Result).To(Equal(gitops.ScheduledRunPassed))
+			Expect(scheduledScenario.Status.ScheduledRuns[0].CompletionTime).NotTo(BeNil())
+		})
+
+		It("ensures the Snapshot of a scheduled run which was created before the run was recorded isn't created again", func() {
+			adapter = NewAdapter(hasApp, scheduledScenario, logger, loader.NewMockLoader(), k8sClient, ctx)
+			adapter.context = loader.GetMockedContext(ctx, []loader.MockData{
+				{
+					ContextKey: loader.ApplicationComponentsContextKey,
+					Resource:   []applicationapiv1alpha1.Component{*hasComp},
+				},
+			})
+
+			nextScheduleTime, err := gitops.GetNextScheduleTime(scheduledScenario)
+			Expect(err).NotTo(HaveOccurred())
+			existingSnapshot, err := gitops.PrepareScheduledSnapshot(k8sClient, ctx, hasApp, &[]applicationapiv1alpha1.Component{*hasComp}, scheduledScenario, nextScheduleTime)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Create(ctx, existingSnapshot)).To(Succeed())
+
+			result, err := adapter.EnsureScheduledSnapshotCreated()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueDelay).To(BeNumerically(">", 0))
+
+			Expect(scheduledScenario.Status.ScheduledRuns).To(HaveLen(1))
+			Expect(scheduledScenario.Status.ScheduledRuns[0].Snapshot).To(Equal(existingSnapshot.Name))
+
+			snapshots := &applicationapiv1alpha1.SnapshotList{}
+			Expect(k8sClient.List(ctx, snapshots, client.InNamespace(hasApp.Namespace),
+				client.MatchingLabels{gitops.SnapshotTestScenarioLabel: scheduledScenario.Name})).To(Succeed())
+			Expect(snapshots.Items).To(HaveLen(1))
+		})
+
+		It("ensures a scenario with an invalid schedule is marked as invalid", func() {
+			scheduledScenario.Spec.Schedule = "every day"
+			adapter = NewAdapter(hasApp, scheduledScenario, logger, loader.NewMockLoader(), k8sClient, ctx)
+
+			result, err := adapter.EnsureCreatedScenarioIsValid()
+			Expect(!result.CancelRequest && err == nil).To(BeTrue())
+			Expect(meta.IsStatusConditionFalse(scheduledScenario.Status.Conditions, gitops.IntegrationTestScenarioValid)).To(BeTrue())
+
+			result, err = adapter.EnsureScheduledSnapshotCreated()
+			Expect(!result.CancelRequest && err == nil).To(BeTrue())
+			Expect(scheduledScenario.Status.ScheduledRuns).To(BeEmpty())
+		})
+	})
+
 	It("ensures the Scenario status can be marked as invalid", func() {
 		SetScenarioIntegrationStatusAsInvalid(invalidScenario, "Test message")
 		Expect(invalidScenario).NotTo(BeNil())
@@ -22,14 +22,20 @@ import (
 	"github.com/go-logr/logr"
 	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
 	"github.com/redhat-appstudio/integration-service/api/v1beta1"
+	"github.com/redhat-appstudio/integration-service/gitops"
 	"github.com/redhat-appstudio/integration-service/helpers"
+	"github.com/redhat-appstudio/integration-service/loader"
 	"github.com/redhat-appstudio/operator-toolkit/controller"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 )
 
 // Reconciler reconciles an scenario object
@@ -54,11 +60,15 @@ func NewScenarioReconciler(client client.Client, logger *logr.Logger, scheme *ru
 //+kubebuilder:rbac:groups=appstudio.redhat.com,resources=environments/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=appstudio.redhat.com,resources=applications,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=appstudio.redhat.com,resources=applications/status,verbs=get
+//+kubebuilder:rbac:groups=appstudio.redhat.com,resources=components,verbs=get;list;watch
+//+kubebuilder:rbac:groups=appstudio.redhat.com,resources=snapshots,verbs=get;list;watch;create
+//+kubebuilder:rbac:groups=appstudio.redhat.com,resources=deploymenttargetclasses,verbs=get;list;watch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
 func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	logger := helpers.IntegrationLogger{Logger: r.Log.WithValues("integrationTestScenario", req.NamespacedName)}
+	loader := loader.NewLoader()
 	scenario := &v1beta1.IntegrationTestScenario{}
 	err := r.Get(ctx, req.NamespacedName, scenario)
 	if err != nil {
@@ -70,15 +80,20 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		return ctrl.Result{}, err
 	}
 
-	application, err := r.getApplicationFromScenario(ctx, scenario)
-	if err != nil {
-		logger.Info("Failed to get Application from the IntegrationTestScenario", "error:", err)
+	// Scenarios selecting their Applications by label aren't reconciled for a single Application
+	var application *applicationapiv1alpha1.Application
+	if !gitops.HasIntegrationTestScenarioApplicationSelector(scenario) {
+		application, err = r.getApplicationFromScenario(ctx, scenario)
+		if err != nil {
+			logger.Info("Failed to get Application from the IntegrationTestScenario", "error:", err)
+		}
 	}
 
-	adapter := NewAdapter(application, scenario, logger, r.Client, ctx)
+	adapter := NewAdapter(application, scenario, logger, loader, r.Client, ctx)
 
 	return controller.ReconcileHandler([]controller.Operation{
 		adapter.EnsureCreatedScenarioIsValid,
+		adapter.EnsureScheduledSnapshotCreated,
 	})
 }
 
@@ -101,6 +116,7 @@ func (r *Reconciler) getApplicationFromScenario(context context.Context, scenari
 // AdapterInterface is an interface defining all the operations that should be defined in an Integration adapter.
 type AdapterInterface interface {
 	EnsureCreatedScenarioIsValid() (controller.OperationResult, error)
+	EnsureScheduledSnapshotCreated() (controller.OperationResult, error)
 }
 
 // SetupController creates a new Integration controller and adds it to the Manager.
@@ -110,9 +126,87 @@ func SetupController(manager ctrl.Manager, log *logr.Logger) error {
 
 func setupControllerWithManager(manager ctrl.Manager, controller *Reconciler) error {
 
+	// Scheduled Snapshots which finished testing trigger the reconciliation of their IntegrationTestScenario,
+	// so the result of the scheduled run is recorded. Changes of the Applications, Environments and
+	// DeploymentTargetClasses the IntegrationTestScenarios depend on trigger their revalidation.
 	return ctrl.NewControllerManagedBy(manager).
-		For(&v1beta1.IntegrationTestScenario{}).
-		WithEventFilter(predicate.Or(
-			IntegrationScenarioCreatedPredicate())).
+		For(&v1beta1.IntegrationTestScenario{}, builder.WithPredicates(predicate.Or(
+			IntegrationScenarioCreatedPredicate()))).
+		Watches(&source.Kind{Type: &applicationapiv1alpha1.Snapshot{}}, handler.EnqueueRequestsFromMapFunc(getScenarioForScheduledSnapshot),
+			builder.WithPredicates(gitops.ScheduledSnapshotFinishedPredicate())).
+		Watches(&source.Kind{Type: &applicationapiv1alpha1.Application{}}, handler.EnqueueRequestsFromMapFunc(controller.getScenariosForApplication),
+			builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Watches(&source.Kind{Type: &applicationapiv1alpha1.Environment{}}, handler.EnqueueRequestsFromMapFunc(controller.getScenariosForEnvironment),
+			builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Watches(&source.Kind{Type: &applicationapiv1alpha1.DeploymentTargetClass{}}, handler.EnqueueRequestsFromMapFunc(controller.getScenariosWithEnvironments),
+			builder.WithPredicates(predicate.GenerationChangedPredicate{})).
 		Complete(controller)
 }
+
+// getScenarioForScheduledSnapshot maps a scheduled Snapshot to a reconcile request for the IntegrationTestScenario it was created for.
+func getScenarioForScheduledSnapshot(object client.Object) []reconcile.Request {
+	scenarioName, ok := object.GetLabels()[gitops.SnapshotTestScenarioLabel]
+	if !ok {
+		return []reconcile.Request{}
+	}
+
+	return []reconcile.Request{
+		{
+			NamespacedName: types.NamespacedName{
+				Namespace: object.GetNamespace(),
+				Name:      scenarioName,
+			},
+		},
+	}
+}
+
+// getScenariosForApplication maps an Application to reconcile requests for the IntegrationTestScenarios referencing it.
+func (r *Reconciler) getScenariosForApplication(object client.Object) []reconcile.Request {
+	return r.getScenarioRequests(object.GetNamespace(), func(scenario *v1beta1.IntegrationTestScenario) bool {
+		return scenario.Spec.Application == object.GetName()
+	})
+}
+
+// getScenariosForEnvironment maps an Environment to reconcile requests for the IntegrationTestScenarios referencing it.
+func (r *Reconciler) getScenariosForEnvironment(object client.Object) []reconcile.Request {
+	return r.getScenarioRequests(object.GetNamespace(), func(scenario *v1beta1.IntegrationTestScenario) bool {
+		for _, environment := range gitops.GetIntegrationTestScenarioEnvironments(scenario) {
+			if environment.Name == object.GetName() {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// getScenariosWithEnvironments maps a DeploymentTargetClass to reconcile requests for all the IntegrationTestScenarios
+// which need an ephemeral environment provisioned through it.
+func (r *Reconciler) getScenariosWithEnvironments(object client.Object) []reconcile.Request {
+	return r.getScenarioRequests("", gitops.HasIntegrationTestScenarioEnvironments)
+}
+
+// getScenarioRequests returns reconcile requests for the IntegrationTestScenarios in the given namespace which match
+// the given filter. An empty namespace matches the IntegrationTestScenarios in all namespaces.
+func (r *Reconciler) getScenarioRequests(namespace string, filter func(*v1beta1.IntegrationTestScenario) bool) []reconcile.Request {
+	scenarios := &v1beta1.IntegrationTestScenarioList{}
+	err := r.List(context.Background(), scenarios, client.InNamespace(namespace))
+	if err != nil {
+		r.Log.Error(err, "Failed to list the IntegrationTestScenarios to revalidate", "namespace", namespace)
+		return []reconcile.Request{}
+	}
+
+	requests := []reconcile.Request{}
+	for _, scenario := range scenarios.Items {
+		scenario := scenario // G601
+		if filter(&scenario) {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Namespace: scenario.Namespace,
+					Name:      scenario.Name,
+				},
+			})
+		}
+	}
+
+	return requests
+}
@@ -192,6 +192,30 @@ var _ = Describe("ScenarioController", func() {
 		}).Should(BeNil())
 	})
 
+	It("maps changed dependencies to the scenarios referencing them", func() {
+		requests := scenarioReconciler.getScenariosForApplication(hasApp)
+		Expect(requests).To(ContainElement(req))
+		Expect(requests).NotTo(ContainElement(reconcile.Request{
+			NamespacedName: types.NamespacedName{Namespace: "default", Name: failScenario.Name},
+		}))
+
+		env := &applicationapiv1alpha1.Environment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "envname",
+				Namespace: "default",
+			},
+		}
+		requests = scenarioReconciler.getScenariosForEnvironment(env)
+		Expect(requests).To(ContainElement(req))
+		Expect(requests).To(ContainElement(reconcile.Request{
+			NamespacedName: types.NamespacedName{Namespace: "default", Name: failScenario.Name},
+		}))
+		env.Name = "otherenv"
+		Expect(scenarioReconciler.getScenariosForEnvironment(env)).To(BeEmpty())
+
+		Expect(scenarioReconciler.getScenariosWithEnvironments(&applicationapiv1alpha1.DeploymentTargetClass{})).To(ContainElement(req))
+	})
+
 	It("can setup a new Controller manager and start it", func() {
 		err := SetupController(manager, &ctrl.Log)
 		Expect(err).To(BeNil())
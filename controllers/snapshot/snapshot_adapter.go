@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"reflect"
 	"strings"
+	"time"
 
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -30,12 +31,15 @@ import (
 	"github.com/redhat-appstudio/integration-service/gitops"
 	h "github.com/redhat-appstudio/integration-service/helpers"
 	"github.com/redhat-appstudio/integration-service/release"
+	"github.com/redhat-appstudio/integration-service/status"
 	"github.com/redhat-appstudio/integration-service/tekton"
 
 	"github.com/redhat-appstudio/integration-service/loader"
 	"github.com/redhat-appstudio/operator-toolkit/controller"
 	releasev1alpha1 "github.com/redhat-appstudio/release-service/api/v1alpha1"
 	pipeline "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -49,6 +53,7 @@ type Adapter struct {
 	loader      loader.ObjectLoader
 	client      client.Client
 	context     context.Context
+	status      status.Status
 }
 
 // NewAdapter creates and returns an Adapter instance.
@@ -62,6 +67,7 @@ func NewAdapter(snapshot *applicationapiv1alpha1.Snapshot, application *applicat
 		loader:      loader,
 		client:      client,
 		context:     context,
+		status:      status.NewAdapter(logger.Logger, client),
 	}
 }
 
@@ -73,6 +79,13 @@ func (a *Adapter) EnsureAllIntegrationTestPipelinesExist() (controller.Operation
 		a.logger.Info("The Snapshot has finished testing.")
 		return controller.ContinueProcessing()
 	}
+	paused, err := a.isAutomationPaused(gitops.PauseTestingAnnotation, a.component)
+	if err != nil {
+		return controller.RequeueWithError(err)
+	}
+	if paused {
+		return controller.ContinueProcessing()
+	}
 
 	integrationTestScenarios, err := a.loader.GetAllIntegrationTestScenariosForApplication(a.client, a.context, a.application)
 	if err != nil {
@@ -81,12 +94,36 @@ func (a *Adapter) EnsureAllIntegrationTestPipelinesExist() (controller.Operation
 	}
 
 	if integrationTestScenarios != nil {
+		integrationTestScenarios = gitops.GetIntegrationTestScenariosForSnapshot(a.snapshot, integrationTestScenarios)
 		a.logger.Info("Found IntegrationTestScenarios for application",
 			"Application.Name", a.application.Name,
 			"IntegrationTestScenarios", len(*integrationTestScenarios))
 		for _, integrationTestScenario := range *integrationTestScenarios {
 			integrationTestScenario := integrationTestScenario //G601
-			if !reflect.ValueOf(integrationTestScenario.Spec.Environment).IsZero() {
+			if gitops.IsIntegrationTestScenarioSkipped(a.snapshot, integrationTestScenario.Name) {
+				a.logger.Info("IntegrationTestScenario was skipped for the Snapshot, skipping creation of pipelinerun.",
+					"integrationTestScenario.Name", integrationTestScenario.Name)
+				continue
+			}
+			if gitops.IsIntegrationTestScenarioInvalid(&integrationTestScenario) {
+				err = a.skipInvalidIntegrationTestScenario(&integrationTestScenario)
+				if err != nil {
+					return controller.RequeueWithError(err)
+				}
+				continue
+			}
+			if gitops.HasIntegrationTestScenarioDependencies(&integrationTestScenario) {
+				dependenciesPassed, err := a.haveIntegrationTestScenarioDependenciesPassed(&integrationTestScenario, integrationTestScenarios)
+				if err != nil {
+					a.logger.Error(err, "Failed to determine the outcome of the dependencies of the scenario",
+						"integrationTestScenario.Name", integrationTestScenario.Name)
+					return controller.RequeueWithError(err)
+				}
+				if !dependenciesPassed {
+					continue
+				}
+			}
+			if gitops.HasIntegrationTestScenarioEnvironments(&integrationTestScenario) {
 				// the test pipeline for scenario needing an ephemeral environment will be handled in STONEINTG-333
 				a.logger.Info("IntegrationTestScenario has environment defined, skipping creation of pipelinerun.", "IntegrationTestScenario", integrationTestScenario)
 				continue
@@ -97,14 +134,19 @@ func (a *Adapter) EnsureAllIntegrationTestPipelinesExist() (controller.Operation
 					"integrationTestScenario.Name", integrationTestScenario.Name)
 				return controller.RequeueWithError(err)
 			}
-			if integrationPipelineRuns != nil && len(*integrationPipelineRuns) > 0 {
-				a.logger.Info("Found existing integrationPipelineRuns",
-					"integrationTestScenario.Name", integrationTestScenario.Name,
-					"len(integrationPipelineRuns)", len(*integrationPipelineRuns))
-			} else {
+
+			for _, matrixCombination := range getMatrixCombinations(&integrationTestScenario) {
+				existingPipelineRuns := filterPipelineRunsForMatrixCombination(integrationPipelineRuns, matrixCombination)
+				if len(existingPipelineRuns) > 0 {
+					a.logger.Info("Found existing integrationPipelineRuns",
+						"integrationTestScenario.Name", integrationTestScenario.Name,
+						"len(integrationPipelineRuns)", len(existingPipelineRuns))
+					continue
+				}
+
 				a.logger.Info("Creating new pipelinerun for integrationTestscenario",
 					"integrationTestScenario.Name", integrationTestScenario.Name)
-				pipelineRun, err := a.createIntegrationPipelineRun(a.application, &integrationTestScenario, a.snapshot)
+				pipelineRun, err := a.createIntegrationPipelineRun(a.application, &integrationTestScenario, a.snapshot, matrixCombination)
 				if err != nil {
 					a.logger.Error(err, "Failed to create pipelineRun for snapshot and scenario")
 					return controller.RequeueWithError(err)
@@ -121,12 +163,11 @@ func (a *Adapter) EnsureAllIntegrationTestPipelinesExist() (controller.Operation
 							a.snapshot, h.LogActionUpdate)
 					}
 				}
-
 			}
 		}
 	}
 
-	requiredIntegrationTestScenarios, err := a.loader.GetRequiredIntegrationTestScenariosForApplication(a.client, a.context, a.application)
+	requiredIntegrationTestScenarios, err := a.getRequiredIntegrationTestScenariosForSnapshot()
 	if err != nil {
 		a.logger.Error(err, "Failed to get all required IntegrationTestScenarios")
 		patch := client.MergeFrom(a.snapshot.DeepCopy())
@@ -146,6 +187,29 @@ func (a *Adapter) EnsureAllIntegrationTestPipelinesExist() (controller.Operation
 			"snapshot.Status", updatedSnapshot.Status)
 	}
 
+	// The integration pipeline controller can't see the required IntegrationTestScenarios which were skipped because they
+	// were invalid or their dependencies failed, so the Snapshot has to be marked as failed here once all the other tests finished
+	if len(gitops.GetSkippedIntegrationTestScenarios(a.snapshot)) > 0 {
+		haveRequiredScenariosFinished, hasRequiredScenarioBeenSkipped, err := a.haveRequiredIntegrationTestScenariosFinished(requiredIntegrationTestScenarios)
+		if err != nil {
+			a.logger.Error(err, "Failed to determine the outcome of the required IntegrationTestScenarios")
+			return controller.RequeueWithError(err)
+		}
+		if haveRequiredScenariosFinished && hasRequiredScenarioBeenSkipped {
+			gitops.SetSnapshotIntegrationStatusAsFinished(a.snapshot,
+				"Snapshot integration status condition is finished since all testing pipelines completed or were skipped")
+			updatedSnapshot, err := gitops.MarkSnapshotAsFailed(a.client, a.context, a.snapshot,
+				"Some required IntegrationTestScenarios were skipped because they were invalid or their dependencies failed")
+			if err != nil {
+				a.logger.Error(err, "Failed to Update Snapshot AppStudioTestSucceeded status")
+				return controller.RequeueWithError(err)
+			}
+			a.logger.LogAuditEvent("Snapshot integration status condition marked as failed, some required IntegrationTestScenarios were skipped",
+				updatedSnapshot, h.LogActionUpdate,
+				"skippedScenarios", gitops.GetSkippedIntegrationTestScenarios(updatedSnapshot))
+		}
+	}
+
 	return controller.ContinueProcessing()
 }
 
@@ -157,6 +221,13 @@ func (a *Adapter) EnsureCreationOfEnvironment() (controller.OperationResult, err
 		a.logger.Info("The Snapshot has finished testing.")
 		return controller.ContinueProcessing()
 	}
+	paused, err := a.isAutomationPaused(gitops.PauseTestingAnnotation, a.component)
+	if err != nil {
+		return controller.RequeueWithError(err)
+	}
+	if paused {
+		return controller.ContinueProcessing()
+	}
 
 	integrationTestScenarios, err := a.loader.GetAllIntegrationTestScenariosForApplication(a.client, a.context, a.application)
 
@@ -168,6 +239,7 @@ func (a *Adapter) EnsureCreationOfEnvironment() (controller.OperationResult, err
 		a.logger.Info("No integration test scenario found for Application")
 		return controller.ContinueProcessing()
 	}
+	integrationTestScenarios = gitops.GetIntegrationTestScenariosForSnapshot(a.snapshot, integrationTestScenarios)
 
 	allEnvironments, err := a.loader.GetAllEnvironments(a.client, a.context, a.application)
 	if err != nil {
@@ -180,118 +252,328 @@ func (a *Adapter) EnsureCreationOfEnvironment() (controller.OperationResult, err
 		return controller.RequeueWithError(err)
 	}
 
-TestScenarioLoop:
 	for _, integrationTestScenario := range *integrationTestScenarios {
 		integrationTestScenario := integrationTestScenario //G601
-		if reflect.ValueOf(integrationTestScenario.Spec.Environment).IsZero() {
+		if !gitops.HasIntegrationTestScenarioEnvironments(&integrationTestScenario) ||
+			gitops.IsIntegrationTestScenarioSkipped(a.snapshot, integrationTestScenario.Name) {
 			continue
 		}
-		for _, environment := range *allEnvironments {
-			environment := environment //G601
-			//prevent creating already existing environments
-			if h.HasLabelWithValue(&environment, gitops.SnapshotLabel, a.snapshot.Name) && h.HasLabelWithValue(&environment, gitops.SnapshotTestScenarioLabel, integrationTestScenario.Name) {
-				a.logger.Info("Environment already exists and contains snapshot and scenario:",
-					"environment.Name", environment.Name,
-					"integrationScenario.Name", integrationTestScenario.Name)
-
-				//check if the environmentSnapshotBinding exists for this existing environment, create it if it doesn't exist
-				binding, err := a.loader.FindExistingSnapshotEnvironmentBinding(a.client, a.context, a.application, &environment)
-				if err != nil {
-					a.logger.Error(err, "Failed to find snapshotEnvironmentBinding associated with environment", "environment.Name", environment.Name)
-					return controller.RequeueWithError(err)
-				}
-				if binding == nil {
-					//create bindging and add scenario name to label of binding
-					scenarioLabelAndKey := map[string]string{gitops.SnapshotTestScenarioLabel: integrationTestScenario.Name}
-					binding, err = a.createSnapshotEnvironmentBindingForSnapshot(a.application, &environment, a.snapshot, components, scenarioLabelAndKey)
-					if err != nil {
-						a.logger.Error(err, "Failed to create snapshotEnvironmentbinding for snapshot",
-							"snapshot", a.snapshot.Name,
-							"environment.Name", environment.Name,
-							"snapshot.Spec.Components", a.snapshot.Spec.Components)
-						return controller.RequeueWithError(err)
-					}
-					a.logger.LogAuditEvent("A snapshotEnvironmentbinding is created", binding, h.LogActionAdd,
-						"integrationTestScenario.Name", integrationTestScenario.Name)
-				} else {
-					a.logger.Info("SnapshotEnvironmentBinding already exists for environment",
-						"binding.Name", binding.Name,
-						"environment.Name", environment.Name)
-				}
-
-				continue TestScenarioLoop
+		if gitops.IsIntegrationTestScenarioInvalid(&integrationTestScenario) {
+			err = a.skipInvalidIntegrationTestScenario(&integrationTestScenario)
+			if err != nil {
+				return controller.RequeueWithError(err)
 			}
+			continue
 		}
-
-		//get the existing environment according to environment name from integrationTestScenario
-		existingEnv, err := a.getEnvironmentFromIntegrationTestScenario(&integrationTestScenario)
-		if err != nil {
-			a.logger.Error(err, "Failed to find the env defined in integrationTestScenario",
-				"integrationTestScenario.Namespace", integrationTestScenario.Namespace,
-				"integrationTestScenario.Name", integrationTestScenario.Name)
-			return controller.RequeueWithError(err)
+		if gitops.HasIntegrationTestScenarioDependencies(&integrationTestScenario) {
+			dependenciesPassed, err := a.haveIntegrationTestScenarioDependenciesPassed(&integrationTestScenario, integrationTestScenarios)
+			if err != nil {
+				return controller.RequeueWithError(err)
+			}
+			if !dependenciesPassed {
+				continue
+			}
 		}
+		for _, testEnvironment := range gitops.GetIntegrationTestScenarioEnvironments(&integrationTestScenario) {
+			testEnvironment := testEnvironment //G601
+			err = a.ensureEphemeralEnvironmentAndBindingExist(&integrationTestScenario, &testEnvironment, allEnvironments, components)
+			if err != nil {
+				return controller.RequeueWithError(err)
+			}
+		}
+	}
+	return controller.ContinueProcessing()
+}
 
-		//create an ephemeral copy env of existing environment
-		copyEnv, err := a.createCopyOfExistingEnvironment(existingEnv, a.snapshot.Namespace, &integrationTestScenario, a.snapshot, a.application)
-
+// ensureEphemeralEnvironmentAndBindingExist makes sure that an ephemeral copy of the given testEnvironment of the
+// integrationTestScenario exists for the Snapshot together with the SnapshotEnvironmentBinding deploying the Snapshot to it.
+func (a *Adapter) ensureEphemeralEnvironmentAndBindingExist(integrationTestScenario *v1beta1.IntegrationTestScenario, testEnvironment *v1beta1.TestEnvironment,
+	allEnvironments *[]applicationapiv1alpha1.Environment, components *[]applicationapiv1alpha1.Component) error {
+	scenarioLabelAndKey := map[string]string{gitops.SnapshotTestScenarioLabel: integrationTestScenario.Name}
+
+	//prevent creating already existing environments
+	environment := a.findEphemeralCopyOfTestEnvironment(integrationTestScenario, testEnvironment, allEnvironments)
+	if environment != nil {
+		a.logger.Info("Environment already exists and contains snapshot and scenario:",
+			"environment.Name", environment.Name,
+			"integrationScenario.Name", integrationTestScenario.Name)
+
+		//check if the environmentSnapshotBinding exists for this existing environment, create it if it doesn't exist
+		binding, err := a.loader.FindExistingSnapshotEnvironmentBinding(a.client, a.context, a.application, environment)
 		if err != nil {
-			a.logger.Error(err, "Copying of environment failed")
-			return controller.RequeueWithError(err)
+			a.logger.Error(err, "Failed to find snapshotEnvironmentBinding associated with environment", "environment.Name", environment.Name)
+			return err
+		}
+		if binding != nil {
+			a.logger.Info("SnapshotEnvironmentBinding already exists for environment",
+				"binding.Name", binding.Name,
+				"environment.Name", environment.Name)
+			return nil
 		}
-		a.logger.LogAuditEvent("An ephemeral Environment is created for integrationTestScenario",
-			copyEnv, h.LogActionAdd,
-			"integrationTestScenario.Name", integrationTestScenario.Name)
 
-		//create binding and add scenario to label of binding
-		scenarioLabelAndKey := map[string]string{gitops.SnapshotTestScenarioLabel: integrationTestScenario.Name}
-		binding, err := a.createSnapshotEnvironmentBindingForSnapshot(a.application, copyEnv, a.snapshot, components, scenarioLabelAndKey)
+		//create bindging and add scenario name to label of binding
+		binding, err = a.createSnapshotEnvironmentBindingForSnapshot(a.application, environment, a.snapshot, components, scenarioLabelAndKey)
 		if err != nil {
 			a.logger.Error(err, "Failed to create snapshotEnvironmentbinding for snapshot",
 				"snapshot", a.snapshot.Name,
-				"environment.Name", copyEnv.Name,
+				"environment.Name", environment.Name,
 				"snapshot.Spec.Components", a.snapshot.Spec.Components)
-			return controller.RequeueWithError(err)
+			return err
 		}
 		a.logger.LogAuditEvent("A snapshotEnvironmentbinding is created", binding, h.LogActionAdd,
-			"environment.Name", copyEnv.Name,
 			"integrationTestScenario.Name", integrationTestScenario.Name)
+		return nil
+	}
+
+	//get the existing environment according to environment name from integrationTestScenario
+	existingEnv, err := a.getEnvironmentFromIntegrationTestScenario(integrationTestScenario, testEnvironment)
+	if err != nil {
+		a.logger.Error(err, "Failed to find the env defined in integrationTestScenario",
+			"integrationTestScenario.Namespace", integrationTestScenario.Namespace,
+			"integrationTestScenario.Name", integrationTestScenario.Name)
+		return err
+	}
+
+	//create an ephemeral copy env of existing environment
+	copyEnv, err := a.createCopyOfExistingEnvironment(existingEnv, a.snapshot.Namespace, integrationTestScenario, testEnvironment, a.snapshot, a.application)
+	if err != nil {
+		a.logger.Error(err, "Copying of environment failed")
+		return err
+	}
+	a.logger.LogAuditEvent("An ephemeral Environment is created for integrationTestScenario",
+		copyEnv, h.LogActionAdd,
+		"integrationTestScenario.Name", integrationTestScenario.Name)
+
+	//create binding and add scenario to label of binding
+	binding, err := a.createSnapshotEnvironmentBindingForSnapshot(a.application, copyEnv, a.snapshot, components, scenarioLabelAndKey)
+	if err != nil {
+		a.logger.Error(err, "Failed to create snapshotEnvironmentbinding for snapshot",
+			"snapshot", a.snapshot.Name,
+			"environment.Name", copyEnv.Name,
+			"snapshot.Spec.Components", a.snapshot.Spec.Components)
+		return err
+	}
+	a.logger.LogAuditEvent("A snapshotEnvironmentbinding is created", binding, h.LogActionAdd,
+		"environment.Name", copyEnv.Name,
+		"integrationTestScenario.Name", integrationTestScenario.Name)
+
+	return nil
+}
+
+// findEphemeralCopyOfTestEnvironment looks for the ephemeral environment copied from the given testEnvironment for the
+// Snapshot and integrationTestScenario. Copies created before the source environment label was introduced are matched
+// against the Environment of the integrationTestScenario. If no copy is found, nil is returned.
+func (a *Adapter) findEphemeralCopyOfTestEnvironment(integrationTestScenario *v1beta1.IntegrationTestScenario, testEnvironment *v1beta1.TestEnvironment,
+	allEnvironments *[]applicationapiv1alpha1.Environment) *applicationapiv1alpha1.Environment {
+	for _, environment := range *allEnvironments {
+		environment := environment //G601
+		if !h.HasLabelWithValue(&environment, gitops.SnapshotLabel, a.snapshot.Name) || !h.HasLabelWithValue(&environment, gitops.SnapshotTestScenarioLabel, integrationTestScenario.Name) {
+			continue
+		}
+		if h.HasLabelWithValue(&environment, gitops.SourceEnvironmentLabel, testEnvironment.Name) ||
+			(!h.HasLabel(&environment, gitops.SourceEnvironmentLabel) && testEnvironment.Name == integrationTestScenario.Spec.Environment.Name) {
+			return &environment
+		}
 	}
+
+	return nil
+}
+
+// EnsureSnapshotDiffRecorded is an operation that will ensure that the changes of the Components of a new Snapshot
+// compared to the previous passing Snapshot of the Application are recorded in its annotation.
+func (a *Adapter) EnsureSnapshotDiffRecorded() (controller.OperationResult, error) {
+	if gitops.HasSnapshotDiff(a.snapshot) {
+		return controller.ContinueProcessing()
+	}
+
+	allSnapshots, err := a.loader.GetAllSnapshots(a.client, a.context, a.application)
+	if err != nil {
+		a.logger.Error(err, "Failed to get all Snapshots of the Application")
+		return controller.RequeueWithError(err)
+	}
+
+	baseSnapshot := gitops.FindPreviousPassingSnapshot(allSnapshots, a.snapshot)
+	diff := gitops.DiffSnapshots(baseSnapshot, a.snapshot)
+	err = gitops.AnnotateSnapshotWithDiff(a.client, a.context, a.snapshot, diff)
+	if err != nil {
+		a.logger.Error(err, "Failed to record the diff of the Snapshot")
+		return controller.RequeueWithError(err)
+	}
+	a.logger.LogAuditEvent("Recorded the diff of the Snapshot against the previous passing Snapshot", a.snapshot, h.LogActionUpdate,
+		"baseSnapshot", diff.BaseSnapshot,
+		"changedComponents", len(diff.Components))
+
 	return controller.ContinueProcessing()
 }
 
-// EnsureGlobalCandidateImageUpdated is an operation that ensure the ContainerImage in the Global Candidate List
-// being updated when the Snapshot passed all the integration tests
-func (a *Adapter) EnsureGlobalCandidateImageUpdated() (controller.OperationResult, error) {
-	if (a.component != nil) && gitops.HaveAppStudioTestsSucceeded(a.snapshot) && !gitops.IsSnapshotCreatedByPACPullRequestEvent(a.snapshot) {
-		patch := client.MergeFrom(a.component.DeepCopy())
-		for _, component := range a.snapshot.Spec.Components {
-			if component.Name == a.component.Name {
-				a.component.Spec.ContainerImage = component.ContainerImage
-				err := a.client.Patch(a.context, a.component, patch)
+// EnsureRequestedRerunsStarted is an operation that will ensure that new integration PipelineRuns are created for
+// the IntegrationTestScenarios whose rerun was requested for the Snapshot, and that the outcome of the Snapshot
+// is evaluated again once they finish.
+func (a *Adapter) EnsureRequestedRerunsStarted() (controller.OperationResult, error) {
+	if !gitops.IsSnapshotRerunRequested(a.snapshot) {
+		return controller.ContinueProcessing()
+	}
+
+	integrationTestScenarios, err := a.loader.GetAllIntegrationTestScenariosForApplication(a.client, a.context, a.application)
+	if err != nil {
+		a.logger.Error(err, "Failed to get Integration test scenarios for the following application",
+			"Application.Namespace", a.application.Namespace)
+		return controller.RequeueWithError(err)
+	}
+	integrationTestScenarios = gitops.GetIntegrationTestScenariosForSnapshot(a.snapshot, integrationTestScenarios)
+
+	rerunScenarios := []v1beta1.IntegrationTestScenario{}
+	for _, scenarioName := range gitops.GetRequestedRerunIntegrationTestScenarios(a.snapshot) {
+		integrationTestScenario := findIntegrationTestScenario(integrationTestScenarios, scenarioName)
+		if integrationTestScenario == nil || gitops.IsIntegrationTestScenarioSkipped(a.snapshot, scenarioName) ||
+			gitops.HasIntegrationTestScenarioEnvironments(integrationTestScenario) {
+			a.logger.Info("IntegrationTestScenario can't be rerun for the Snapshot, skipping its rerun.",
+				"integrationTestScenario.Name", scenarioName)
+			continue
+		}
+		rerunScenarios = append(rerunScenarios, *integrationTestScenario)
+	}
+
+	if len(rerunScenarios) > 0 {
+		// The request time tells apart the integration PipelineRuns already created for this request, if the
+		// previous attempt to start the reruns failed after creating some of them
+		err = gitops.RecordRerunRequestTime(a.client, a.context, a.snapshot)
+		if err != nil {
+			a.logger.Error(err, "Failed to record the time of the requested rerun of the Snapshot")
+			return controller.RequeueWithError(err)
+		}
+		requestTime, _ := gitops.GetRerunRequestTime(a.snapshot)
+
+		err = gitops.MarkSnapshotAsRetesting(a.client, a.context, a.snapshot, "Snapshot is being retested by the rerun integrationPipelineRuns")
+		if err != nil {
+			a.logger.Error(err, "Failed to reset the test status of the Snapshot")
+			return controller.RequeueWithError(err)
+		}
+		a.logger.LogAuditEvent("Snapshot integration status marked as In Progress. Snapshot is being retested",
+			a.snapshot, h.LogActionUpdate)
+
+		for _, integrationTestScenario := range rerunScenarios {
+			integrationTestScenario := integrationTestScenario //G601
+			integrationPipelineRuns, err := a.loader.GetAllPipelineRunsForSnapshotAndScenario(a.client, a.context, a.snapshot, &integrationTestScenario)
+			if err != nil {
+				a.logger.Error(err, "Failed to get pipelineRuns for snapshot and scenario",
+					"integrationTestScenario.Name", integrationTestScenario.Name)
+				return controller.RequeueWithError(err)
+			}
+			for _, matrixCombination := range getMatrixCombinations(&integrationTestScenario) {
+				if isRerunPipelineRunCreated(integrationPipelineRuns, matrixCombination, requestTime) {
+					a.logger.Info("The integration pipelineRun was already rerun for the request, skipping its rerun",
+						"integrationTestScenario.Name", integrationTestScenario.Name)
+					continue
+				}
+				pipelineRun, err := a.createIntegrationPipelineRun(a.application, &integrationTestScenario, a.snapshot, matrixCombination)
 				if err != nil {
-					a.logger.Error(err, "Failed to update .Spec.ContainerImage of Global Candidate for the Component",
-						"component.Name", a.component.Name)
+					a.logger.Error(err, "Failed to create pipelineRun for snapshot and scenario")
 					return controller.RequeueWithError(err)
 				}
-				a.logger.LogAuditEvent("Updated .Spec.ContainerImage of Global Candidate for the Component",
-					a.component, h.LogActionUpdate,
-					"containerImage", component.ContainerImage)
-				if reflect.ValueOf(component.Source).IsValid() && component.Source.GitSource != nil && component.Source.GitSource.Revision != "" {
-					a.component.Status.LastBuiltCommit = component.Source.GitSource.Revision
-					err = a.client.Status().Patch(a.context, a.component, patch)
-					if err != nil {
-						a.logger.Error(err, "Failed to update .Status.LastBuiltCommit of Global Candidate for the Component",
-							"component.Name", a.component.Name)
-						return controller.RequeueWithError(err)
-					}
-					a.logger.LogAuditEvent("Updated .Status.LastBuiltCommit of Global Candidate for the Component",
-						a.component, h.LogActionUpdate,
-						"lastBuildCommit", a.component.Status.LastBuiltCommit)
+				a.logger.LogAuditEvent("IntegrationTestscenario pipeline has been rerun", pipelineRun, h.LogActionAdd,
+					"integrationTestScenario.Name", integrationTestScenario.Name)
+				gitops.PrepareToRegisterIntegrationPipelineRun(a.snapshot)
+			}
+		}
+	}
+
+	err = gitops.ClearRequestedRerun(a.client, a.context, a.snapshot)
+	if err != nil {
+		a.logger.Error(err, "Failed to clear the requested rerun of the Snapshot")
+		return controller.RequeueWithError(err)
+	}
+
+	return controller.ContinueProcessing()
+}
+
+// EnsureGlobalCandidateImageUpdated is an operation that ensure the ContainerImage in the Global Candidate List
+// being updated when the Snapshot passed all the integration tests. For batch Snapshots, the Global Candidate List
+// is updated for every Component in the batch.
+func (a *Adapter) EnsureGlobalCandidateImageUpdated() (controller.OperationResult, error) {
+	if !gitops.HaveAppStudioTestsSucceeded(a.snapshot) || gitops.IsSnapshotCreatedByPACPullRequestEvent(a.snapshot) {
+		return controller.ContinueProcessing()
+	}
+	superseded, err := a.isSnapshotSuperseded()
+	if err != nil {
+		return controller.RequeueWithError(err)
+	}
+	if superseded {
+		return controller.ContinueProcessing()
+	}
+
+	var components []*applicationapiv1alpha1.Component
+	if a.component != nil {
+		components = append(components, a.component)
+	} else if gitops.IsSnapshotBatch(a.snapshot) {
+		applicationComponents, err := a.loader.GetAllApplicationComponents(a.client, a.context, a.application)
+		if err != nil {
+			return controller.RequeueWithError(err)
+		}
+		for _, batchComponent := range gitops.GetSnapshotBatchComponents(a.snapshot) {
+			for i := range *applicationComponents {
+				if (*applicationComponents)[i].Name == batchComponent {
+					components = append(components, &(*applicationComponents)[i])
 				}
 			}
 		}
 	}
+
+	for _, component := range components {
+		err := a.updateGlobalCandidateImage(component)
+		if err != nil {
+			return controller.RequeueWithError(err)
+		}
+	}
+
+	return controller.ContinueProcessing()
+}
+
+// EnsureBatchSnapshotBisected is an operation that will ensure that the outcome of a finished batch Snapshot is
+// recorded as the verdict for each Component in the batch. A failed batch Snapshot of several Components is bisected
+// into two batch Snapshots, each testing half of the builds, to find the builds which fail the tests.
+func (a *Adapter) EnsureBatchSnapshotBisected() (controller.OperationResult, error) {
+	if !gitops.IsSnapshotBatch(a.snapshot) || !gitops.HaveAppStudioTestsFinished(a.snapshot) {
+		return controller.ContinueProcessing()
+	}
+
+	batchComponents := gitops.GetSnapshotBatchComponents(a.snapshot)
+	if gitops.HaveAppStudioTestsSucceeded(a.snapshot) {
+		return a.recordBatchVerdicts(batchComponents, gitops.BatchVerdictPassed)
+	}
+	if len(batchComponents) <= 1 {
+		return a.recordBatchVerdicts(batchComponents, gitops.BatchVerdictFailed)
+	}
+
+	applicationComponents, err := a.loader.GetAllApplicationComponents(a.client, a.context, a.application)
+	if err != nil {
+		return controller.RequeueWithError(err)
+	}
+
+	firstHalf, secondHalf := gitops.BisectBatch(batchComponents)
+	for i, half := range [][]string{firstHalf, secondHalf} {
+		bisectedSnapshot, err := gitops.PrepareBatchSnapshot(a.client, a.context, a.application, applicationComponents,
+			gitops.NewBisectedBatchComponents(a.snapshot, half), gitops.GetBisectedBatchSnapshotName(a.snapshot, i+1))
+		if err != nil {
+			return controller.RequeueWithError(err)
+		}
+		bisectedSnapshot.Annotations[gitops.SnapshotBatchParentAnnotation] = a.snapshot.Name
+		bisectedSnapshot.Annotations[gitops.SnapshotBatchRootAnnotation] = gitops.GetSnapshotBatchRoot(a.snapshot)
+
+		// The bisected Snapshots are named after the failed batch Snapshot, so existing ones are found on creation
+		err = a.client.Create(a.context, bisectedSnapshot)
+		if errors.IsAlreadyExists(err) {
+			continue
+		} else if err != nil {
+			a.logger.Error(err, "Failed to create the bisected batch Snapshot")
+			return controller.RequeueWithError(err)
+		}
+		a.logger.LogAuditEvent("Bisected the failed batch Snapshot", bisectedSnapshot, h.LogActionAdd,
+			"snapshot.Name", bisectedSnapshot.Name,
+			"batch.Parent", a.snapshot.Name,
+			"batch.Components", half)
+	}
+
 	return controller.ContinueProcessing()
 }
 
@@ -299,12 +581,27 @@ func (a *Adapter) EnsureGlobalCandidateImageUpdated() (controller.OperationResul
 // to the Snapshot and the Application's ReleasePlans exist.
 // Otherwise, it will create new Releases for each ReleasePlan.
 func (a *Adapter) EnsureAllReleasesExist() (controller.OperationResult, error) {
-	canSnapshotBePromoted, reasons := gitops.CanSnapshotBePromoted(a.snapshot)
-	if !canSnapshotBePromoted {
+	// Snapshots which can't be promoted to any target are skipped, the other ones are decided on per target
+	if canSnapshotBePromotedPerTarget, _ := gitops.CanSnapshotBePromotedPerTarget(a.snapshot); !canSnapshotBePromotedPerTarget {
+		_, reasons := gitops.CanSnapshotBePromoted(a.snapshot)
 		a.logger.Info("The Snapshot won't be released.",
 			"reasons", strings.Join(reasons, ","))
 		return controller.ContinueProcessing()
 	}
+	paused, err := a.isAutomationPaused(gitops.PauseAutoReleaseAnnotation, a.component)
+	if err != nil {
+		return controller.RequeueWithError(err)
+	}
+	if paused {
+		return controller.ContinueProcessing()
+	}
+	superseded, err := a.isSnapshotSuperseded()
+	if err != nil {
+		return controller.RequeueWithError(err)
+	}
+	if superseded {
+		return controller.ContinueProcessing()
+	}
 
 	releasePlans, err := a.loader.GetAutoReleasePlansForApplication(a.client, a.context, a.application)
 	if err != nil {
@@ -316,7 +613,27 @@ func (a *Adapter) EnsureAllReleasesExist() (controller.OperationResult, error) {
 		return controller.RequeueOnErrorOrStop(a.client.Status().Patch(a.context, a.snapshot, patch))
 	}
 
-	err = a.createMissingReleasesForReleasePlans(a.application, releasePlans, a.snapshot)
+	promotedReleasePlans := []releasev1alpha1.ReleasePlan{}
+	decisions := map[string]gitops.PromotionDecision{}
+	for _, releasePlan := range *releasePlans {
+		releasePlan := releasePlan // G601
+		decision, err := a.decidePromotionToTarget(&releasePlan)
+		if err != nil {
+			a.logger.Error(err, "Failed to decide whether the Snapshot can be released",
+				"releasePlan.Name", releasePlan.Name)
+			return controller.RequeueWithError(err)
+		}
+		decisions[gitops.GetPromotionTargetKey(gitops.PromotionTargetReleasePlan, releasePlan.Name)] = decision
+		if decision.Promoted {
+			promotedReleasePlans = append(promotedReleasePlans, releasePlan)
+		}
+	}
+	err = a.recordPromotionDecisions(decisions)
+	if err != nil {
+		return controller.RequeueWithError(err)
+	}
+
+	err = a.createMissingReleasesForReleasePlans(a.application, &promotedReleasePlans, a.snapshot)
 	if err != nil {
 		a.logger.Error(err, "Failed to create new Releases")
 		patch := client.MergeFrom(a.snapshot.DeepCopy())
@@ -328,22 +645,86 @@ func (a *Adapter) EnsureAllReleasesExist() (controller.OperationResult, error) {
 	return controller.ContinueProcessing()
 }
 
+// EnsureReleaseOutcomeReported is an operation that will ensure that the progress and outcome of the Releases created
+// for the Snapshot are mirrored into its AppStudioReleased condition, and reported to the git provider which
+// (indirectly) triggered the build of the Snapshot whenever they change.
+func (a *Adapter) EnsureReleaseOutcomeReported() (controller.OperationResult, error) {
+	releases, err := a.loader.GetReleasesWithSnapshot(a.client, a.context, a.snapshot)
+	if err != nil {
+		a.logger.Error(err, "Failed to get the Releases of the Snapshot")
+		return controller.RequeueWithError(err)
+	}
+	if len(*releases) == 0 {
+		return controller.ContinueProcessing()
+	}
+
+	patch := client.MergeFrom(a.snapshot.DeepCopy())
+	if !gitops.SetSnapshotReleasedCondition(a.snapshot, releases) {
+		return controller.ContinueProcessing()
+	}
+
+	// The outcome is reported before the condition is patched, so a failed report is retried on requeue
+	reporters, err := a.status.GetReleaseReporters(a.snapshot)
+	if err != nil {
+		return controller.RequeueWithError(err)
+	}
+	for _, reporter := range reporters {
+		if err := reporter.ReportReleaseStatus(a.client, a.context, a.snapshot); err != nil {
+			a.logger.Error(err, "Failed to report the Release status of the Snapshot")
+			return controller.RequeueWithError(err)
+		}
+	}
+
+	err = a.client.Status().Patch(a.context, a.snapshot, patch)
+	if err != nil {
+		a.logger.Error(err, "Failed to update the Release status of the Snapshot")
+		return controller.RequeueWithError(err)
+	}
+	condition := meta.FindStatusCondition(a.snapshot.Status.Conditions, gitops.AppStudioReleasedCondition)
+	a.logger.LogAuditEvent("Release status of the Snapshot updated", a.snapshot, h.LogActionUpdate,
+		"reason", condition.Reason,
+		"message", condition.Message)
+
+	return controller.ContinueProcessing()
+}
+
 // EnsureSnapshotEnvironmentBindingExist is an operation that will ensure that all
 // SnapshotEnvironmentBindings for non-ephemeral root environments point to the newly constructed snapshot.
+// Once the snapshot was verified in an environment, the bindings of its child environments are pointed to it as well.
 // If the bindings don't already exist, it will create new ones for each of the environments.
 func (a *Adapter) EnsureSnapshotEnvironmentBindingExist() (controller.OperationResult, error) {
-	canSnapshotBePromoted, reasons := gitops.CanSnapshotBePromoted(a.snapshot)
-	if !canSnapshotBePromoted {
+	// Snapshots which can't be promoted to any target are skipped, the other ones are decided on per target
+	if canSnapshotBePromotedPerTarget, _ := gitops.CanSnapshotBePromotedPerTarget(a.snapshot); !canSnapshotBePromotedPerTarget {
+		_, reasons := gitops.CanSnapshotBePromoted(a.snapshot)
 		a.logger.Info("The Snapshot won't be deployed.",
 			"reasons", strings.Join(reasons, ","))
 		return controller.ContinueProcessing()
 	}
+	paused, err := a.isAutomationPaused(gitops.PauseBindingUpdateAnnotation, a.component)
+	if err != nil {
+		return controller.RequeueWithError(err)
+	}
+	if paused {
+		return controller.ContinueProcessing()
+	}
+	superseded, err := a.isSnapshotSuperseded()
+	if err != nil {
+		return controller.RequeueWithError(err)
+	}
+	if superseded {
+		return controller.ContinueProcessing()
+	}
 
 	availableEnvironments, err := a.findAvailableEnvironments()
 	if err != nil {
 		return controller.RequeueWithError(err)
 	}
 
+	availableEnvironments, err = a.filterEnvironmentsForPromotion(availableEnvironments)
+	if err != nil {
+		return controller.RequeueWithError(err)
+	}
+
 	components, err := a.loader.GetAllSnapshotComponents(a.client, a.context, a.snapshot)
 	if err != nil {
 		return controller.RequeueWithError(err)
@@ -367,6 +748,12 @@ func (a *Adapter) EnsureSnapshotEnvironmentBindingExist() (controller.OperationR
 					a.snapshot, h.LogActionUpdate)
 				return controller.RequeueOnErrorOrStop(a.client.Status().Patch(a.context, a.snapshot, patch))
 			}
+			if snapshotEnvironmentBinding.Spec.Snapshot != a.snapshot.Name {
+				a.logger.Info("The SnapshotEnvironmentBinding is bound to a newer Snapshot, it won't be updated",
+					"snapshotEnvironmentBinding.Environment", snapshotEnvironmentBinding.Spec.Environment,
+					"snapshotEnvironmentBinding.Snapshot", snapshotEnvironmentBinding.Spec.Snapshot)
+				continue
+			}
 			a.logger.LogAuditEvent("Existing SnapshotEnvironmentBinding updated with Snapshot",
 				snapshotEnvironmentBinding, h.LogActionUpdate,
 				"snapshotEnvironmentBinding.Environment", snapshotEnvironmentBinding.Spec.Environment,
@@ -440,7 +827,110 @@ func (a *Adapter) createMissingReleasesForReleasePlans(application *applicationa
 	return nil
 }
 
-// findAvailableEnvironments gets all environments that don't have a ParentEnvironment and are not tagged as ephemeral.
+// filterEnvironmentsForPromotion returns the given environments the Snapshot can be promoted to, recording
+// the promotion decision for each of them on the Snapshot.
+func (a *Adapter) filterEnvironmentsForPromotion(environments *[]applicationapiv1alpha1.Environment) (*[]applicationapiv1alpha1.Environment, error) {
+	promotedEnvironments := []applicationapiv1alpha1.Environment{}
+	decisions := map[string]gitops.PromotionDecision{}
+	for _, environment := range *environments {
+		environment := environment // G601
+		targetKey := gitops.GetPromotionTargetKey(gitops.PromotionTargetEnvironment, environment.Name)
+		if gitops.IsSnapshotDeployedToEnvironment(a.snapshot, environment.Name) {
+			continue
+		}
+		if gitops.HasSnapshotPromotionBeenRolledBack(a.snapshot, targetKey) {
+			a.logger.Info("The Snapshot was rolled back from the Environment after failing to deploy, it won't be deployed again.",
+				"environment.Name", environment.Name)
+			continue
+		}
+		decision, err := a.decidePromotionToTarget(&environment)
+		if err != nil {
+			a.logger.Error(err, "Failed to decide whether the Snapshot can be deployed",
+				"environment.Name", environment.Name)
+			return nil, err
+		}
+		decisions[targetKey] = decision
+		if decision.Promoted {
+			promotedEnvironments = append(promotedEnvironments, environment)
+		}
+	}
+
+	err := a.recordPromotionDecisions(decisions)
+	if err != nil {
+		return nil, err
+	}
+
+	return &promotedEnvironments, nil
+}
+
+// recordPromotionDecisions records the given promotion decisions on the Snapshot. Only the decisions which changed
+// since they were last recorded are logged and recorded, so the Snapshot isn't patched on every reconciliation.
+func (a *Adapter) recordPromotionDecisions(decisions map[string]gitops.PromotionDecision) error {
+	changedDecisions := gitops.GetChangedPromotionDecisions(a.snapshot, decisions)
+	if len(changedDecisions) == 0 {
+		return nil
+	}
+
+	for target, decision := range changedDecisions {
+		if !decision.Promoted {
+			a.logger.Info("The Snapshot won't be promoted to the target.",
+				"target", target,
+				"reasons", strings.Join(decision.Reasons, ","))
+		}
+	}
+
+	err := gitops.RecordSnapshotPromotionDecisions(a.client, a.context, a.snapshot, changedDecisions)
+	if err != nil {
+		a.logger.Error(err, "Failed to record the promotion decisions on the Snapshot")
+		return err
+	}
+	a.logger.LogAuditEvent("Promotion decisions recorded on the Snapshot", a.snapshot, h.LogActionUpdate,
+		"targets", len(changedDecisions))
+
+	return nil
+}
+
+// decidePromotionToTarget decides whether the Snapshot can be promoted to the given ReleasePlan or Environment.
+// Targets naming the IntegrationTestScenarios they require are promoted as soon as those passed, the other ones
+// once the Snapshot passed all the required IntegrationTestScenarios of the Application. Targets requiring
+// approval additionally need the Snapshot to be approved.
+func (a *Adapter) decidePromotionToTarget(target client.Object) (gitops.PromotionDecision, error) {
+	requiredScenarios := gitops.GetPromotionTargetRequiredScenarios(target)
+	if len(requiredScenarios) == 0 {
+		decision := gitops.CanSnapshotBePromotedToTarget(a.snapshot, requiredScenarios, nil)
+		return gitops.CheckSnapshotApprovalForTarget(decision, a.snapshot, target), nil
+	}
+
+	integrationTestScenarios, err := a.loader.GetAllIntegrationTestScenariosForApplication(a.client, a.context, a.application)
+	if err != nil {
+		return gitops.PromotionDecision{}, err
+	}
+	integrationTestScenarios = gitops.GetIntegrationTestScenariosForSnapshot(a.snapshot, integrationTestScenarios)
+
+	passedScenarios := map[string]bool{}
+	for _, scenarioName := range requiredScenarios {
+		integrationTestScenario := findIntegrationTestScenario(integrationTestScenarios, scenarioName)
+		if integrationTestScenario == nil || gitops.IsIntegrationTestScenarioSkipped(a.snapshot, scenarioName) {
+			continue
+		}
+		// Quarantined IntegrationTestScenarios can't block the promotion
+		if gitops.IsIntegrationTestScenarioQuarantined(integrationTestScenario) {
+			passedScenarios[scenarioName] = true
+			continue
+		}
+		_, passed, err := a.getIntegrationTestScenarioOutcome(integrationTestScenario)
+		if err != nil {
+			return gitops.PromotionDecision{}, err
+		}
+		passedScenarios[scenarioName] = passed
+	}
+
+	decision := gitops.CanSnapshotBePromotedToTarget(a.snapshot, requiredScenarios, passedScenarios)
+	return gitops.CheckSnapshotApprovalForTarget(decision, a.snapshot, target), nil
+}
+
+// findAvailableEnvironments gets all environments that are not tagged as ephemeral and either don't have a ParentEnvironment
+// or have a ParentEnvironment the Snapshot was verified in, so Snapshots are promoted along the Environment parent chain.
 func (a *Adapter) findAvailableEnvironments() (*[]applicationapiv1alpha1.Environment, error) {
 	allEnvironments, err := a.loader.GetAllEnvironments(a.client, a.context, a.application)
 	if err != nil {
@@ -448,17 +938,12 @@ func (a *Adapter) findAvailableEnvironments() (*[]applicationapiv1alpha1.Environ
 	}
 	availableEnvironments := []applicationapiv1alpha1.Environment{}
 	for _, environment := range *allEnvironments {
-		if environment.Spec.ParentEnvironment == "" {
-			isEphemeral := false
-			for _, tag := range environment.Spec.Tags {
-				if tag == "ephemeral" {
-					isEphemeral = true
-					break
-				}
-			}
-			if !isEphemeral {
-				availableEnvironments = append(availableEnvironments, environment)
-			}
+		environment := environment // G601
+		if h.IsEnvironmentEphemeral(&environment) {
+			continue
+		}
+		if environment.Spec.ParentEnvironment == "" || gitops.IsSnapshotVerifiedInEnvironment(a.snapshot, environment.Spec.ParentEnvironment) {
+			availableEnvironments = append(availableEnvironments, environment)
 		}
 	}
 	return &availableEnvironments, nil
@@ -466,13 +951,24 @@ func (a *Adapter) findAvailableEnvironments() (*[]applicationapiv1alpha1.Environ
 
 // createIntegrationPipelineRun creates and returns a new integration PipelineRun. The Pipeline information and the parameters to it
 // will be extracted from the given integrationScenario. The integration's Snapshot will also be passed to the integration PipelineRun.
-func (a *Adapter) createIntegrationPipelineRun(application *applicationapiv1alpha1.Application, integrationTestScenario *v1beta1.IntegrationTestScenario, snapshot *applicationapiv1alpha1.Snapshot) (*pipeline.PipelineRun, error) {
-	pipelineRun := tekton.NewIntegrationPipelineRun(snapshot.Name, application.Namespace, *integrationTestScenario).
+// If a matrix combination is given, its param values will be passed to the integration PipelineRun as well.
+func (a *Adapter) createIntegrationPipelineRun(application *applicationapiv1alpha1.Application, integrationTestScenario *v1beta1.IntegrationTestScenario,
+	snapshot *applicationapiv1alpha1.Snapshot, matrixCombination *gitops.MatrixCombination) (*pipeline.PipelineRun, error) {
+	params, err := gitops.ResolveIntegrationTestScenarioParams(a.client, a.context, integrationTestScenario.Namespace, integrationTestScenario.Spec.Params,
+		gitops.NewScenarioParamTemplateData(application, snapshot, a.component, nil))
+	if err != nil {
+		return nil, err
+	}
+
+	integrationPipelineRun := tekton.NewIntegrationPipelineRun(snapshot.Name, application.Namespace, *integrationTestScenario).
 		WithSnapshot(snapshot).
 		WithIntegrationLabels(integrationTestScenario).
 		WithApplicationAndComponent(a.application, a.component).
-		WithExtraParams(integrationTestScenario.Spec.Params).
-		AsPipelineRun()
+		WithExtraParams(params)
+	if matrixCombination != nil {
+		integrationPipelineRun.WithMatrixCombination(matrixCombination.Name, matrixCombination.Params, matrixCombination.Optional)
+	}
+	pipelineRun := integrationPipelineRun.AsPipelineRun()
 	// copy PipelineRun PAC annotations/labels from snapshot to integration test PipelineRuns
 	h.CopyAnnotationsByPrefix(&snapshot.ObjectMeta, &pipelineRun.ObjectMeta, gitops.PipelinesAsCodePrefix, gitops.PipelinesAsCodePrefix)
 	h.CopyLabelsByPrefix(&snapshot.ObjectMeta, &pipelineRun.ObjectMeta, gitops.PipelinesAsCodePrefix, gitops.PipelinesAsCodePrefix)
@@ -481,7 +977,7 @@ func (a *Adapter) createIntegrationPipelineRun(application *applicationapiv1alph
 	h.CopyLabelsByPrefix(&snapshot.ObjectMeta, &pipelineRun.ObjectMeta, gitops.BuildPipelineRunPrefix, gitops.BuildPipelineRunPrefix)
 	h.CopyAnnotationsByPrefix(&snapshot.ObjectMeta, &pipelineRun.ObjectMeta, gitops.BuildPipelineRunPrefix, gitops.BuildPipelineRunPrefix)
 
-	err := ctrl.SetControllerReference(snapshot, pipelineRun, a.client.Scheme())
+	err = ctrl.SetControllerReference(snapshot, pipelineRun, a.client.Scheme())
 	if err != nil {
 		return nil, err
 	}
@@ -518,6 +1014,7 @@ func (a *Adapter) createSnapshotEnvironmentBindingForSnapshot(application *appli
 		if err != nil {
 			return nil, err
 		}
+		gitops.MarkBindingPromotion(snapshotEnvironmentBinding, snapshot)
 	}
 
 	for _, keyAndValue := range optionalLabelKeysAndValues {
@@ -537,13 +1034,28 @@ func (a *Adapter) createSnapshotEnvironmentBindingForSnapshot(application *appli
 }
 
 // updateExistingSnapshotEnvironmentBindingWithSnapshot updates and returns snapshotEnvironmentBinding
-// with the given snapshot and components. If it's not possible to patch, an error will be returned.
+// with the given snapshot and components. If the snapshotEnvironmentBinding is bound to a Snapshot created after
+// the given snapshot, it is returned unchanged so an older Snapshot never replaces a newer one.
+// If it's not possible to patch, an error will be returned.
 func (a *Adapter) updateExistingSnapshotEnvironmentBindingWithSnapshot(snapshotEnvironmentBinding *applicationapiv1alpha1.SnapshotEnvironmentBinding,
 	snapshot *applicationapiv1alpha1.Snapshot,
 	components *[]applicationapiv1alpha1.Component) (*applicationapiv1alpha1.SnapshotEnvironmentBinding, error) {
 
+	if snapshotEnvironmentBinding.Spec.Snapshot != snapshot.Name {
+		boundSnapshot, err := a.loader.GetSnapshot(a.client, a.context, snapshotEnvironmentBinding.Spec.Snapshot, snapshotEnvironmentBinding.Namespace)
+		if err != nil && !errors.IsNotFound(err) {
+			return nil, err
+		}
+		if err == nil && snapshot.CreationTimestamp.Before(&boundSnapshot.CreationTimestamp) {
+			return snapshotEnvironmentBinding, nil
+		}
+	}
+
 	patch := client.MergeFrom(snapshotEnvironmentBinding.DeepCopy())
 
+	if snapshotEnvironmentBinding.Spec.Snapshot != snapshot.Name {
+		gitops.MarkBindingPromotion(snapshotEnvironmentBinding, snapshot)
+	}
 	snapshotEnvironmentBinding.Spec.Snapshot = snapshot.Name
 	snapshotComponents := gitops.NewBindingComponents(*components)
 	snapshotEnvironmentBinding.Spec.Components = *snapshotComponents
@@ -557,10 +1069,10 @@ func (a *Adapter) updateExistingSnapshotEnvironmentBindingWithSnapshot(snapshotE
 }
 
 // createCopyOfExistingEnvironment uses existing env as input, specifies namespace where the environment is situated,
-// integrationTestScenario contains information about existing environment
+// integrationTestScenario and testEnvironment contain information about existing environment
 // snapshot is mainly used for adding labels
 // returns copy of already existing environment with updated envVars
-func (a *Adapter) createCopyOfExistingEnvironment(existingEnvironment *applicationapiv1alpha1.Environment, namespace string, integrationTestScenario *v1beta1.IntegrationTestScenario, snapshot *applicationapiv1alpha1.Snapshot, application *applicationapiv1alpha1.Application) (*applicationapiv1alpha1.Environment, error) {
+func (a *Adapter) createCopyOfExistingEnvironment(existingEnvironment *applicationapiv1alpha1.Environment, namespace string, integrationTestScenario *v1beta1.IntegrationTestScenario, testEnvironment *v1beta1.TestEnvironment, snapshot *applicationapiv1alpha1.Snapshot, application *applicationapiv1alpha1.Application) (*applicationapiv1alpha1.Environment, error) {
 	// Try to find a available DeploymentTargetClass with the right provisioner
 	deploymentTargetClass, err := a.loader.FindAvailableDeploymentTargetClass(a.client, a.context)
 	if err != nil || deploymentTargetClass == nil {
@@ -584,9 +1096,10 @@ func (a *Adapter) createCopyOfExistingEnvironment(existingEnvironment *applicati
 	a.logger.LogAuditEvent("DeploymentTargetClaim is created for environment", dtc, h.LogActionAdd,
 		"integrationTestScenario.Name", integrationTestScenario.Name)
 
-	environment := gitops.NewCopyOfExistingEnvironment(existingEnvironment, namespace, integrationTestScenario, dtc.Name).
+	environment := gitops.NewCopyOfExistingEnvironmentForTestEnvironment(existingEnvironment, namespace, integrationTestScenario, testEnvironment, dtc.Name).
 		WithIntegrationLabels(integrationTestScenario).
 		WithSnapshot(snapshot).
+		WithSourceEnvironment(existingEnvironment).
 		AsEnvironment()
 	ref := ctrl.SetControllerReference(application, environment, a.client.Scheme())
 	if ref != nil {
@@ -621,21 +1134,378 @@ func (a *Adapter) CreateDeploymentTargetClaimForEnvironment(namespace string, de
 	return deploymentTargetClaim, nil
 }
 
-// getEnvironmentFromIntegrationTestScenario looks for already existing environment, if it exists it is returned, if not, nil is returned then together with
-// information about what went wrong
-func (a *Adapter) getEnvironmentFromIntegrationTestScenario(integrationTestScenario *v1beta1.IntegrationTestScenario) (*applicationapiv1alpha1.Environment, error) {
+// getEnvironmentFromIntegrationTestScenario looks for already existing environment requested by the testEnvironment of the integrationTestScenario,
+// if it exists it is returned, if not, nil is returned then together with information about what went wrong
+func (a *Adapter) getEnvironmentFromIntegrationTestScenario(integrationTestScenario *v1beta1.IntegrationTestScenario, testEnvironment *v1beta1.TestEnvironment) (*applicationapiv1alpha1.Environment, error) {
 	existingEnv := &applicationapiv1alpha1.Environment{}
 
 	err := a.client.Get(a.context, types.NamespacedName{
 		Namespace: a.application.Namespace,
-		Name:      integrationTestScenario.Spec.Environment.Name,
+		Name:      testEnvironment.Name,
 	}, existingEnv)
 
 	if err != nil {
 		a.logger.Info("Environment doesn't exist in same namespace as IntegrationTestScenario at all.",
 			"integrationTestScenario:", integrationTestScenario.Name,
-			"environment:", integrationTestScenario.Spec.Environment.Name)
-		return nil, fmt.Errorf("environment %s doesn't exist in same namespace as IntegrationTestScenario at all: %w", integrationTestScenario.Spec.Environment.Name, err)
+			"environment:", testEnvironment.Name)
+		return nil, fmt.Errorf("environment %s doesn't exist in same namespace as IntegrationTestScenario at all: %w", testEnvironment.Name, err)
 	}
 	return existingEnv, nil
 }
+
+// filterPipelineRunsForMatrixCombination returns the pipelineRuns testing the given matrix combination.
+// If no matrix combination is given, all the pipelineRuns are returned.
+func filterPipelineRunsForMatrixCombination(pipelineRuns *[]pipeline.PipelineRun, matrixCombination *gitops.MatrixCombination) []pipeline.PipelineRun {
+	if pipelineRuns == nil {
+		return []pipeline.PipelineRun{}
+	}
+	if matrixCombination == nil {
+		return *pipelineRuns
+	}
+
+	matchingPipelineRuns := []pipeline.PipelineRun{}
+	for _, pipelineRun := range *pipelineRuns {
+		if pipelineRun.Labels[tekton.MatrixCombinationLabel] == matrixCombination.Name {
+			matchingPipelineRuns = append(matchingPipelineRuns, pipelineRun)
+		}
+	}
+
+	return matchingPipelineRuns
+}
+
+// getMatrixCombinations returns the matrix combinations a pipelineRun has to be created for, one for each combination
+// of the matrix params of the IntegrationTestScenario, or a single nil combination if it has no matrix.
+func getMatrixCombinations(integrationTestScenario *v1beta1.IntegrationTestScenario) []*gitops.MatrixCombination {
+	if !gitops.HasIntegrationTestScenarioMatrix(integrationTestScenario) {
+		return []*gitops.MatrixCombination{nil}
+	}
+
+	matrixCombinations := []*gitops.MatrixCombination{}
+	for _, matrixCombination := range gitops.GetIntegrationTestScenarioMatrixCombinations(integrationTestScenario) {
+		matrixCombination := matrixCombination // G601
+		matrixCombinations = append(matrixCombinations, &matrixCombination)
+	}
+
+	return matrixCombinations
+}
+
+// haveIntegrationTestScenarioDependenciesPassed checks whether all the IntegrationTestScenarios the given
+// integrationTestScenario depends on passed for the Snapshot. If any of the dependencies failed or was skipped,
+// the integrationTestScenario and all the IntegrationTestScenarios depending on it are marked as skipped.
+// Dependencies which don't match any of the Application's IntegrationTestScenarios are ignored.
+func (a *Adapter) haveIntegrationTestScenarioDependenciesPassed(integrationTestScenario *v1beta1.IntegrationTestScenario,
+	integrationTestScenarios *[]v1beta1.IntegrationTestScenario) (bool, error) {
+	allDependenciesPassed := true
+	for _, dependencyName := range integrationTestScenario.Spec.DependsOn {
+		dependency := findIntegrationTestScenario(integrationTestScenarios, dependencyName)
+		if dependency == nil {
+			a.logger.Info("IntegrationTestScenario dependency doesn't exist, ignoring it",
+				"integrationTestScenario.Name", integrationTestScenario.Name,
+				"dependency", dependencyName)
+			continue
+		}
+
+		dependencyFailed := gitops.IsIntegrationTestScenarioSkipped(a.snapshot, dependencyName)
+		if !dependencyFailed {
+			finished, passed, err := a.getIntegrationTestScenarioOutcome(dependency)
+			if err != nil {
+				return false, err
+			}
+			if !finished {
+				a.logger.Info("Waiting for IntegrationTestScenario dependency to finish",
+					"integrationTestScenario.Name", integrationTestScenario.Name,
+					"dependency", dependencyName)
+				allDependenciesPassed = false
+				continue
+			}
+			dependencyFailed = !passed
+		}
+
+		if dependencyFailed {
+			skippedScenarios := append([]v1beta1.IntegrationTestScenario{*integrationTestScenario},
+				gitops.GetDependentIntegrationTestScenarios(integrationTestScenario, integrationTestScenarios)...)
+			err := gitops.MarkIntegrationTestScenariosAsSkipped(a.client, a.context, a.snapshot, skippedScenarios)
+			if err != nil {
+				return false, err
+			}
+			a.logger.LogAuditEvent("IntegrationTestScenario dependency didn't pass, marked IntegrationTestScenarios as skipped",
+				a.snapshot, h.LogActionUpdate,
+				"integrationTestScenario.Name", integrationTestScenario.Name,
+				"dependency", dependencyName,
+				"skippedScenarios", len(skippedScenarios))
+			return false, nil
+		}
+	}
+
+	return allDependenciesPassed, nil
+}
+
+// getIntegrationTestScenarioOutcome determines whether the integration PipelineRuns of the integrationTestScenario
+// finished for the Snapshot and whether they passed. For IntegrationTestScenarios with a matrix, only the PipelineRuns
+// of the required matrix combinations are taken into account. The IntegrationTestScenario is considered finished
+// as soon as one of its PipelineRuns failed.
+func (a *Adapter) getIntegrationTestScenarioOutcome(integrationTestScenario *v1beta1.IntegrationTestScenario) (bool, bool, error) {
+	integrationPipelineRuns, err := a.loader.GetAllPipelineRunsForSnapshotAndScenario(a.client, a.context, a.snapshot, integrationTestScenario)
+	if err != nil {
+		return false, false, err
+	}
+
+	matrixCombinations := []*gitops.MatrixCombination{nil}
+	if gitops.HasIntegrationTestScenarioMatrix(integrationTestScenario) {
+		matrixCombinations = []*gitops.MatrixCombination{}
+		for _, matrixCombination := range gitops.GetRequiredIntegrationTestScenarioMatrixCombinations(integrationTestScenario) {
+			matrixCombination := matrixCombination // G601
+			matrixCombinations = append(matrixCombinations, &matrixCombination)
+		}
+	}
+
+	finished := true
+	for _, matrixCombination := range matrixCombinations {
+		pipelineRuns := filterPipelineRunsForMatrixCombination(integrationPipelineRuns, matrixCombination)
+		if len(pipelineRuns) == 0 {
+			finished = false
+		}
+		for _, pipelineRun := range pipelineRuns {
+			pipelineRun := pipelineRun // G601
+			if !h.HasPipelineRunFinished(&pipelineRun) {
+				finished = false
+				continue
+			}
+			passed, err := h.CalculateIntegrationPipelineRunOutcome(a.client, a.context, a.logger.Logger, &pipelineRun)
+			if err != nil {
+				return false, false, err
+			}
+			if !passed {
+				return true, false, nil
+			}
+		}
+	}
+
+	return finished, finished, nil
+}
+
+// haveRequiredIntegrationTestScenariosFinished checks whether all the given required IntegrationTestScenarios either
+// finished or were skipped for the Snapshot. The second return value reports whether any of them was skipped.
+func (a *Adapter) haveRequiredIntegrationTestScenariosFinished(requiredIntegrationTestScenarios *[]v1beta1.IntegrationTestScenario) (bool, bool, error) {
+	anySkipped := false
+	for _, integrationTestScenario := range *requiredIntegrationTestScenarios {
+		integrationTestScenario := integrationTestScenario // G601
+		if gitops.IsIntegrationTestScenarioSkipped(a.snapshot, integrationTestScenario.Name) {
+			anySkipped = true
+			continue
+		}
+		finished, _, err := a.getIntegrationTestScenarioOutcome(&integrationTestScenario)
+		if err != nil {
+			return false, false, err
+		}
+		if !finished {
+			return false, anySkipped, nil
+		}
+	}
+
+	return true, anySkipped, nil
+}
+
+// skipInvalidIntegrationTestScenario records on the Snapshot that the given IntegrationTestScenario was skipped
+// because it is invalid, along with the reason it is invalid for, instead of creating its test pipelines.
+func (a *Adapter) skipInvalidIntegrationTestScenario(integrationTestScenario *v1beta1.IntegrationTestScenario) error {
+	a.logger.Info("IntegrationTestScenario is invalid, skipping it for the Snapshot",
+		"integrationTestScenario.Name", integrationTestScenario.Name,
+		"reason", gitops.GetIntegrationTestScenarioInvalidReason(integrationTestScenario))
+	err := gitops.MarkIntegrationTestScenarioAsInvalid(a.client, a.context, a.snapshot, integrationTestScenario)
+	if err != nil {
+		a.logger.Error(err, "Failed to mark the invalid IntegrationTestScenario as skipped for the Snapshot",
+			"integrationTestScenario.Name", integrationTestScenario.Name)
+		return err
+	}
+	a.logger.LogAuditEvent("Invalid IntegrationTestScenario marked as skipped for the Snapshot", a.snapshot, h.LogActionUpdate,
+		"integrationTestScenario.Name", integrationTestScenario.Name)
+
+	return nil
+}
+
+// updateGlobalCandidateImage updates the ContainerImage and the last built commit of the given Component in the
+// Global Candidate List to the ones in the Snapshot, unless the update is paused or the Component is pinned. If the pin
+// of the Component can't be determined, the Global Candidate isn't updated and the Snapshot is marked accordingly.
+func (a *Adapter) updateGlobalCandidateImage(component *applicationapiv1alpha1.Component) error {
+	paused, err := a.isAutomationPaused(gitops.PauseGlobalCandidateUpdateAnnotation, component)
+	if err != nil || paused {
+		return err
+	}
+
+	// The Global Candidate List isn't updated while the pin is invalid, the Component may be meant to stay at its pinned image
+	pinnedImage, err := gitops.GetPinnedComponentImage(a.application, component)
+	if err != nil {
+		a.logger.Error(err, "Failed to determine if the Component is pinned, won't update its Global Candidate",
+			"component.Name", component.Name)
+		err = gitops.MarkSnapshotComponentPinInvalid(a.client, a.context, a.snapshot,
+			fmt.Sprintf("The Global Candidate of the Component %s isn't updated: %s", component.Name, err.Error()))
+		if err != nil {
+			a.logger.Error(err, "Failed to mark the pin of the Component as invalid on the Snapshot")
+			return err
+		}
+		return nil
+	}
+	err = gitops.MarkSnapshotComponentPinValid(a.client, a.context, a.snapshot)
+	if err != nil {
+		a.logger.Error(err, "Failed to mark the pins of the Components as valid on the Snapshot")
+		return err
+	}
+
+	patch := client.MergeFrom(component.DeepCopy())
+	for _, snapshotComponent := range a.snapshot.Spec.Components {
+		if snapshotComponent.Name == component.Name {
+			if pinnedImage != "" && snapshotComponent.ContainerImage != pinnedImage {
+				a.logger.Info("The Component is pinned, refusing to move its Global Candidate away from the pinned image",
+					"component.Name", component.Name,
+					"pinnedImage", pinnedImage,
+					"containerImage", snapshotComponent.ContainerImage)
+				return nil
+			}
+			component.Spec.ContainerImage = snapshotComponent.ContainerImage
+			err := a.client.Patch(a.context, component, patch)
+			if err != nil {
+				a.logger.Error(err, "Failed to update .Spec.ContainerImage of Global Candidate for the Component",
+					"component.Name", component.Name)
+				return err
+			}
+			a.logger.LogAuditEvent("Updated .Spec.ContainerImage of Global Candidate for the Component",
+				component, h.LogActionUpdate,
+				"containerImage", snapshotComponent.ContainerImage)
+			if reflect.ValueOf(snapshotComponent.Source).IsValid() && snapshotComponent.Source.GitSource != nil && snapshotComponent.Source.GitSource.Revision != "" {
+				component.Status.LastBuiltCommit = snapshotComponent.Source.GitSource.Revision
+				err = a.client.Status().Patch(a.context, component, patch)
+				if err != nil {
+					a.logger.Error(err, "Failed to update .Status.LastBuiltCommit of Global Candidate for the Component",
+						"component.Name", component.Name)
+					return err
+				}
+				a.logger.LogAuditEvent("Updated .Status.LastBuiltCommit of Global Candidate for the Component",
+					component, h.LogActionUpdate,
+					"lastBuildCommit", component.Status.LastBuiltCommit)
+			}
+		}
+	}
+
+	return nil
+}
+
+// recordBatchVerdicts records the given verdict for the Components of the batch on the batch Snapshot
+// originally created for the batch.
+func (a *Adapter) recordBatchVerdicts(batchComponents []string, verdict string) (controller.OperationResult, error) {
+	rootSnapshot := a.snapshot
+	if rootName := gitops.GetSnapshotBatchRoot(a.snapshot); rootName != a.snapshot.Name {
+		rootSnapshot = &applicationapiv1alpha1.Snapshot{}
+		err := a.client.Get(a.context, types.NamespacedName{Namespace: a.snapshot.Namespace, Name: rootName}, rootSnapshot)
+		if errors.IsNotFound(err) {
+			a.logger.Info("The batch Snapshot the verdicts are recorded on no longer exists", "batch.Root", rootName)
+			return controller.ContinueProcessing()
+		} else if err != nil {
+			return controller.RequeueWithError(err)
+		}
+	}
+
+	err := gitops.RecordBatchVerdicts(a.client, a.context, rootSnapshot, batchComponents, verdict)
+	if err != nil {
+		a.logger.Error(err, "Failed to record the batch verdicts",
+			"batch.Root", rootSnapshot.Name)
+		return controller.RequeueWithError(err)
+	}
+	a.logger.Info("Recorded the batch verdicts",
+		"batch.Root", rootSnapshot.Name,
+		"batch.Components", batchComponents,
+		"verdict", verdict)
+
+	return controller.ContinueProcessing()
+}
+
+// isSnapshotSuperseded checks if a newer Snapshot of the same Application and Component was created, so the Snapshot
+// isn't promoted or released anymore when it gets reconciled again, e.g. once one of its PipelineRuns finishes late.
+// If the Snapshots can't be loaded, an error will be returned.
+func (a *Adapter) isSnapshotSuperseded() (bool, error) {
+	snapshots, err := a.loader.GetAllSnapshots(a.client, a.context, a.application)
+	if err != nil {
+		a.logger.Error(err, "Failed to get all Snapshots of the Application")
+		return false, err
+	}
+	if gitops.IsSnapshotSuperseded(a.snapshot, snapshots) {
+		a.logger.Info("The Snapshot was superseded by a newer Snapshot of the same Component, skipping the step")
+		return true, nil
+	}
+
+	return false, nil
+}
+
+// isAutomationPaused checks if the automated step controlled by the given pause annotation is paused on the Application
+// or the given Component of the Snapshot. If it is, the reason is logged and recorded in the AppStudioAutomationPaused
+// condition of the Snapshot, otherwise any reason recorded for it earlier is removed from the condition.
+func (a *Adapter) isAutomationPaused(pauseAnnotation string, component *applicationapiv1alpha1.Component) (bool, error) {
+	reason := gitops.GetAutomationPauseReason(pauseAnnotation, a.application, component)
+	if reason == "" {
+		err := gitops.MarkSnapshotAutomationResumed(a.client, a.context, a.snapshot, pauseAnnotation)
+		if err != nil {
+			a.logger.Error(err, "Failed to record the resumed step on the Snapshot")
+			return false, err
+		}
+		return false, nil
+	}
+
+	a.logger.Info("Skipping a paused step for the Snapshot", "reason", reason)
+	err := gitops.MarkSnapshotAutomationPaused(a.client, a.context, a.snapshot, reason)
+	if err != nil {
+		a.logger.Error(err, "Failed to record the paused step on the Snapshot")
+		return true, err
+	}
+
+	return true, nil
+}
+
+// getRequiredIntegrationTestScenariosForSnapshot returns the required IntegrationTestScenarios which test the Snapshot.
+// The scheduled IntegrationTestScenario decides the outcome of the Snapshot created for its run even if it's optional.
+func (a *Adapter) getRequiredIntegrationTestScenariosForSnapshot() (*[]v1beta1.IntegrationTestScenario, error) {
+	var integrationTestScenarios *[]v1beta1.IntegrationTestScenario
+	var err error
+	if gitops.IsSnapshotScheduled(a.snapshot) {
+		integrationTestScenarios, err = a.loader.GetAllIntegrationTestScenariosForApplication(a.client, a.context, a.application)
+	} else {
+		integrationTestScenarios, err = a.loader.GetRequiredIntegrationTestScenariosForApplication(a.client, a.context, a.application)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return gitops.GetIntegrationTestScenariosForSnapshot(a.snapshot, integrationTestScenarios), nil
+}
+
+// isRerunPipelineRunCreated returns true if any of the integration PipelineRuns was created for the matrix
+// combination, or for the IntegrationTestScenario without a matrix if it's nil, since the rerun was requested.
+func isRerunPipelineRunCreated(integrationPipelineRuns *[]pipeline.PipelineRun,
+	matrixCombination *gitops.MatrixCombination, requestTime time.Time) bool {
+	if integrationPipelineRuns == nil {
+		return false
+	}
+	for _, pipelineRun := range *integrationPipelineRuns {
+		if matrixCombination != nil && pipelineRun.GetLabels()[tekton.MatrixCombinationLabel] != matrixCombination.Name {
+			continue
+		}
+		if !pipelineRun.CreationTimestamp.Time.Before(requestTime) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// findIntegrationTestScenario returns the IntegrationTestScenario with the given name from the list, or nil if there is none.
+func findIntegrationTestScenario(integrationTestScenarios *[]v1beta1.IntegrationTestScenario, name string) *v1beta1.IntegrationTestScenario {
+	if integrationTestScenarios == nil {
+		return nil
+	}
+	for i := range *integrationTestScenarios {
+		if (*integrationTestScenarios)[i].Name == name {
+			return &(*integrationTestScenarios)[i]
+		}
+	}
+
+	return nil
+}
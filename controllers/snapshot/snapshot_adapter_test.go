@@ -2,6 +2,7 @@ package snapshot
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"reflect"
 	"time"
@@ -22,11 +23,41 @@ import (
 
 	"github.com/redhat-appstudio/integration-service/gitops"
 	"github.com/redhat-appstudio/integration-service/helpers"
+	"github.com/redhat-appstudio/integration-service/status"
+	"github.com/redhat-appstudio/integration-service/tekton"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/types"
+	"knative.dev/pkg/apis"
+	v1 "knative.dev/pkg/apis/duck/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
+type MockReleaseReporter struct {
+	Called bool
+}
+
+func (r *MockReleaseReporter) ReportReleaseStatus(client.Client, context.Context, *applicationapiv1alpha1.Snapshot) error {
+	r.Called = true
+	return nil
+}
+
+type MockStatusAdapter struct {
+	ReleaseReporter *MockReleaseReporter
+}
+
+func (a *MockStatusAdapter) GetReporters(pipelineRun *tektonv1beta1.PipelineRun) ([]status.Reporter, error) {
+	return []status.Reporter{}, nil
+}
+
+func (a *MockStatusAdapter) GetReleaseReporters(snapshot *applicationapiv1alpha1.Snapshot) ([]status.ReleaseReporter, error) {
+	return []status.ReleaseReporter{a.ReleaseReporter}, nil
+}
+
+func (a *MockStatusAdapter) GetDeploymentReporters(snapshot *applicationapiv1alpha1.Snapshot) ([]status.DeploymentReporter, error) {
+	return []status.DeploymentReporter{}, nil
+}
+
 var _ = Describe("Snapshot Adapter", Ordered, func() {
 	var (
 		adapter *Adapter
@@ -391,7 +422,7 @@ var _ = Describe("Snapshot Adapter", Ordered, func() {
 		})
 
 		It("Ensure IntegrationPipelineRun can be created for scenario", func() {
-			_, err := adapter.createIntegrationPipelineRun(hasApp, integrationTestScenario, hasSnapshot)
+			_, err := adapter.createIntegrationPipelineRun(hasApp, integrationTestScenario, hasSnapshot, nil)
 			Expect(err == nil).To(BeTrue())
 
 			integrationPipelineRuns := &tektonv1beta1.PipelineRunList{}
@@ -411,6 +442,121 @@ var _ = Describe("Snapshot Adapter", Ordered, func() {
 			Expect(k8sClient.Delete(adapter.context, &integrationPipelineRuns.Items[0])).Should(Succeed())
 		})
 
+		It("Ensure IntegrationPipelineRun can be created for a matrix combination of the scenario", func() {
+			matrixCombination := &gitops.MatrixCombination{
+				Name: "combination",
+				Params: []v1beta1.PipelineParameter{
+					{
+						Name:  "os",
+						Value: "rhel9",
+					},
+				},
+				Optional: true,
+			}
+			pipelineRun, err := adapter.createIntegrationPipelineRun(hasApp, integrationTestScenario, hasSnapshot, matrixCombination)
+			Expect(err).To(BeNil())
+			Expect(pipelineRun.Labels).To(HaveKeyWithValue(tekton.MatrixCombinationLabel, "combination"))
+			Expect(pipelineRun.Labels).To(HaveKeyWithValue(tekton.OptionalLabel, "true"))
+			Expect(pipelineRun.Annotations).To(HaveKeyWithValue(tekton.MatrixParamsAnnotation, "os=rhel9"))
+
+			Expect(k8sClient.Delete(adapter.context, pipelineRun)).Should(Succeed())
+		})
+
+		It("ensures scenarios are started only after their dependencies passed", func() {
+			smokeTests := integrationTestScenarioWithoutEnv.DeepCopy()
+			e2eTests := integrationTestScenarioWithoutEnv.DeepCopy()
+			e2eTests.Name = "e2e-tests"
+			e2eTests.Spec.DependsOn = []string{smokeTests.Name}
+			upgradeTests := integrationTestScenarioWithoutEnv.DeepCopy()
+			upgradeTests.Name = "upgrade-tests"
+			upgradeTests.Spec.DependsOn = []string{e2eTests.Name}
+			integrationTestScenarios := []v1beta1.IntegrationTestScenario{*smokeTests, *e2eTests, *upgradeTests}
+
+			smokeTestsPipelineRun := tektonv1beta1.PipelineRun{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "smoke-tests-pipelinerun",
+					Namespace: "default",
+				},
+			}
+			adapter.context = loader.GetMockedContext(ctx, []loader.MockData{
+				{
+					ContextKey: loader.PipelineRunsContextKey,
+					Resource:   []tektonv1beta1.PipelineRun{smokeTestsPipelineRun},
+				},
+			})
+			dependenciesPassed, err := adapter.haveIntegrationTestScenarioDependenciesPassed(e2eTests, &integrationTestScenarios)
+			Expect(err).To(BeNil())
+			Expect(dependenciesPassed).To(BeFalse())
+			Expect(gitops.GetSkippedIntegrationTestScenarios(hasSnapshot)).To(BeEmpty())
+
+			smokeTestsPipelineRun.Status = tektonv1beta1.PipelineRunStatus{
+				Status: v1.Status{
+					Conditions: v1.Conditions{
+						apis.Condition{
+							Reason: "Failed",
+							Status: "False",
+							Type:   apis.ConditionSucceeded,
+						},
+					},
+				},
+			}
+			adapter.context = loader.GetMockedContext(ctx, []loader.MockData{
+				{
+					ContextKey: loader.PipelineRunsContextKey,
+					Resource:   []tektonv1beta1.PipelineRun{smokeTestsPipelineRun},
+				},
+			})
+			dependenciesPassed, err = adapter.haveIntegrationTestScenarioDependenciesPassed(e2eTests, &integrationTestScenarios)
+			Expect(err).To(BeNil())
+			Expect(dependenciesPassed).To(BeFalse())
+			Expect(gitops.GetSkippedIntegrationTestScenarios(hasSnapshot)).To(Equal([]string{"e2e-tests", "upgrade-tests"}))
+
+			patch := client.MergeFrom(hasSnapshot.DeepCopy())
+			delete(hasSnapshot.Annotations, gitops.SnapshotSkippedScenariosAnnotation)
+			Expect(k8sClient.Patch(ctx, hasSnapshot, patch)).Should(Succeed())
+		})
+
+		It("ensures invalid scenarios are skipped and the reason is recorded on the Snapshot", func() {
+			invalidScenario := integrationTestScenarioWithoutEnv.DeepCopy()
+			invalidScenario.Name = "invalid-tests"
+			meta.SetStatusCondition(&invalidScenario.Status.Conditions, metav1.Condition{
+				Type:    gitops.IntegrationTestScenarioValid,
+				Status:  metav1.ConditionFalse,
+				Reason:  gitops.AppStudioIntegrationStatusInvalid,
+				Message: "Failed to get application for scenario.",
+			})
+
+			log := helpers.IntegrationLogger{Logger: buflogr.NewWithBuffer(&buf)}
+			adapter = NewAdapter(hasSnapshot, hasApp, hasComp, log, loader.NewMockLoader(), k8sClient, ctx)
+			adapter.context = loader.GetMockedContext(ctx, []loader.MockData{
+				{
+					ContextKey: loader.AllIntegrationTestScenariosContextKey,
+					Resource:   []v1beta1.IntegrationTestScenario{*invalidScenario},
+				},
+				{
+					ContextKey: loader.RequiredIntegrationTestScenariosContextKey,
+					Resource:   []v1beta1.IntegrationTestScenario{},
+				},
+			})
+			result, err := adapter.EnsureAllIntegrationTestPipelinesExist()
+			Expect(!result.CancelRequest && err == nil).To(BeTrue())
+
+			Expect(buf.String()).Should(ContainSubstring("IntegrationTestScenario is invalid, skipping it for the Snapshot"))
+			Expect(gitops.IsIntegrationTestScenarioSkipped(hasSnapshot, invalidScenario.Name)).To(BeTrue())
+			invalidScenarios, err := gitops.GetInvalidIntegrationTestScenarios(hasSnapshot)
+			Expect(err).To(BeNil())
+			Expect(invalidScenarios).To(HaveKeyWithValue(invalidScenario.Name, "Failed to get application for scenario."))
+
+			pipelineRuns, err := adapter.loader.GetAllPipelineRunsForSnapshotAndScenario(k8sClient, ctx, hasSnapshot, invalidScenario)
+			Expect(err).To(BeNil())
+			Expect(*pipelineRuns).To(BeEmpty())
+
+			patch := client.MergeFrom(hasSnapshot.DeepCopy())
+			delete(hasSnapshot.Annotations, gitops.SnapshotSkippedScenariosAnnotation)
+			delete(hasSnapshot.Annotations, gitops.SnapshotInvalidScenariosAnnotation)
+			Expect(k8sClient.Patch(ctx, hasSnapshot, patch)).Should(Succeed())
+		})
+
 		It("ensures global Component Image will not be updated in the PR context", func() {
 			gitops.MarkSnapshotAsPassed(k8sClient, ctx, hasSnapshotPR, "test passed")
 			Expect(gitops.HaveAppStudioTestsSucceeded(hasSnapshotPR)).To(BeTrue())
@@ -564,8 +710,185 @@ var _ = Describe("Snapshot Adapter", Ordered, func() {
 			Expect(err == nil || errors.IsNotFound(err)).To(BeTrue())
 		})
 
+		It("ensures an older Snapshot reconciled after a newer one was bound is neither promoted nor released", func() {
+			var buf bytes.Buffer
+			log := helpers.IntegrationLogger{Logger: buflogr.NewWithBuffer(&buf)}
+
+			gitops.MarkSnapshotAsPassed(k8sClient, ctx, hasSnapshot, "test passed")
+			hasSnapshot.Labels[gitops.PipelineAsCodeEventTypeLabel] = gitops.PipelineAsCodePushType
+			newerSnapshot := hasSnapshot.DeepCopy()
+			newerSnapshot.Name = "snapshot-sample-newer"
+			newerSnapshot.CreationTimestamp = metav1.NewTime(hasSnapshot.CreationTimestamp.Add(time.Hour))
+
+			adapter = NewAdapter(hasSnapshot, hasApp, hasComp, log, loader.NewMockLoader(), k8sClient, ctx)
+			components := []applicationapiv1alpha1.Component{*hasComp}
+			binding, err := adapter.createSnapshotEnvironmentBindingForSnapshot(hasApp, env, newerSnapshot, &components)
+			Expect(err).To(BeNil())
+			adapter.context = loader.GetMockedContext(ctx, []loader.MockData{
+				{
+					ContextKey: loader.ApplicationComponentsContextKey,
+					Resource:   components,
+				},
+				{
+					ContextKey: loader.AllSnapshotsContextKey,
+					Resource:   []applicationapiv1alpha1.Snapshot{*hasSnapshot, *newerSnapshot},
+				},
+				{
+					ContextKey: loader.SnapshotEnvironmentBindingContextKey,
+					Resource:   binding,
+				},
+			})
+
+			result, err := adapter.EnsureAllReleasesExist()
+			Expect(!result.CancelRequest && err == nil).To(BeTrue())
+			result, err = adapter.EnsureGlobalCandidateImageUpdated()
+			Expect(!result.CancelRequest && err == nil).To(BeTrue())
+			result, err = adapter.EnsureSnapshotEnvironmentBindingExist()
+			Expect(!result.CancelRequest && err == nil).To(BeTrue())
+			Expect(buf.String()).Should(ContainSubstring("The Snapshot was superseded by a newer Snapshot of the same Component"))
+			Expect(buf.String()).ShouldNot(ContainSubstring("Existing SnapshotEnvironmentBinding updated with Snapshot"))
+
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: binding.Namespace, Name: binding.Name}, binding)).To(Succeed())
+			Expect(binding.Spec.Snapshot).To(Equal(newerSnapshot.Name))
+
+			Expect(k8sClient.Delete(ctx, binding)).To(Succeed())
+		})
+
+		It("ensures the Snapshot is promoted to environments once the scenarios they require passed", func() {
+			smokeTests := integrationTestScenarioWithoutEnv.DeepCopy()
+			stagingEnv := env.DeepCopy()
+			stagingEnv.Annotations = map[string]string{gitops.RequiredScenariosAnnotation: smokeTests.Name}
+			smokeTestsPipelineRun := tektonv1beta1.PipelineRun{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "smoke-tests-pipelinerun",
+					Namespace: "default",
+				},
+			}
+			adapter.context = loader.GetMockedContext(ctx, []loader.MockData{
+				{
+					ContextKey: loader.AllIntegrationTestScenariosContextKey,
+					Resource:   []v1beta1.IntegrationTestScenario{*smokeTests},
+				},
+				{
+					ContextKey: loader.PipelineRunsContextKey,
+					Resource:   []tektonv1beta1.PipelineRun{smokeTestsPipelineRun},
+				},
+			})
+			environments, err := adapter.filterEnvironmentsForPromotion(&[]applicationapiv1alpha1.Environment{*stagingEnv})
+			Expect(err).To(BeNil())
+			Expect(*environments).To(BeEmpty())
+
+			smokeTestsPipelineRun.Status = tektonv1beta1.PipelineRunStatus{
+				Status: v1.Status{
+					Conditions: v1.Conditions{
+						apis.Condition{
+							Reason: "Completed",
+							Status: "True",
+							Type:   apis.ConditionSucceeded,
+						},
+					},
+				},
+			}
+			adapter.context = loader.GetMockedContext(ctx, []loader.MockData{
+				{
+					ContextKey: loader.AllIntegrationTestScenariosContextKey,
+					Resource:   []v1beta1.IntegrationTestScenario{*smokeTests},
+				},
+				{
+					ContextKey: loader.PipelineRunsContextKey,
+					Resource:   []tektonv1beta1.PipelineRun{smokeTestsPipelineRun},
+				},
+			})
+			environments, err = adapter.filterEnvironmentsForPromotion(&[]applicationapiv1alpha1.Environment{*stagingEnv})
+			Expect(err).To(BeNil())
+			Expect(*environments).To(HaveLen(1))
+
+			decisions, err := gitops.GetSnapshotPromotionDecisions(adapter.snapshot)
+			Expect(err).To(BeNil())
+			Expect(decisions).To(HaveKeyWithValue(gitops.GetPromotionTargetKey(gitops.PromotionTargetEnvironment, stagingEnv.Name),
+				gitops.PromotionDecision{Promoted: true}))
+
+			// The unchanged decision isn't recorded again
+			resourceVersion := adapter.snapshot.ResourceVersion
+			environments, err = adapter.filterEnvironmentsForPromotion(&[]applicationapiv1alpha1.Environment{*stagingEnv})
+			Expect(err).To(BeNil())
+			Expect(*environments).To(HaveLen(1))
+			Expect(adapter.snapshot.ResourceVersion).To(Equal(resourceVersion))
+		})
+
+		It("ensures quarantined scenarios required by environments don't block the promotion", func() {
+			flakyTests := integrationTestScenarioWithoutEnv.DeepCopy()
+			flakyTests.Name = "flaky-tests"
+			flakyTests.Labels = map[string]string{gitops.IntegrationTestScenarioQuarantinedLabel: "true"}
+			qaEnv := env.DeepCopy()
+			qaEnv.Name = "qa"
+			qaEnv.Annotations = map[string]string{gitops.RequiredScenariosAnnotation: flakyTests.Name}
+			adapter.context = loader.GetMockedContext(ctx, []loader.MockData{
+				{
+					ContextKey: loader.AllIntegrationTestScenariosContextKey,
+					Resource:   []v1beta1.IntegrationTestScenario{*flakyTests},
+				},
+				{
+					ContextKey: loader.PipelineRunsContextKey,
+					Resource:   []tektonv1beta1.PipelineRun{},
+				},
+			})
+			environments, err := adapter.filterEnvironmentsForPromotion(&[]applicationapiv1alpha1.Environment{*qaEnv})
+			Expect(err).To(BeNil())
+			Expect(*environments).To(HaveLen(1))
+		})
+
+		It("ensures the Snapshot is only promoted to environments requiring approval once it was approved", func() {
+			approvalEnv := env.DeepCopy()
+			approvalEnv.Name = "approval"
+			approvalEnv.Annotations = map[string]string{gitops.ApprovalRequiredAnnotation: "true"}
+
+			decision, err := adapter.decidePromotionToTarget(approvalEnv)
+			Expect(err).To(BeNil())
+			Expect(decision.Promoted).To(BeFalse())
+			Expect(decision.Reasons).To(ContainElement("the Snapshot hasn't been approved"))
+
+			approvedSnapshot := hasSnapshot.DeepCopy()
+			approvedSnapshot.Annotations = map[string]string{gitops.SnapshotApprovedByAnnotation: "example-user"}
+			adapter.snapshot = approvedSnapshot
+			decision, err = adapter.decidePromotionToTarget(approvalEnv)
+			Expect(err).To(BeNil())
+			Expect(decision.Reasons).NotTo(ContainElement("the Snapshot hasn't been approved"))
+			adapter.snapshot = hasSnapshot
+		})
+
+		It("ensures the Snapshot is promoted to child environments once it was verified in the parent environment", func() {
+			productionEnv := env.DeepCopy()
+			productionEnv.Name = "production"
+			productionEnv.Spec.ParentEnvironment = "staging"
+			adapter.context = loader.GetMockedContext(ctx, []loader.MockData{
+				{
+					ContextKey: loader.EnvironmentContextKey,
+					Resource:   productionEnv,
+				},
+			})
+			environments, err := adapter.findAvailableEnvironments()
+			Expect(err).To(BeNil())
+			Expect(*environments).To(BeEmpty())
+
+			Expect(gitops.MarkSnapshotDeployedToEnvironment(k8sClient, ctx, adapter.snapshot, "staging")).To(Succeed())
+			Expect(gitops.MarkSnapshotVerifiedInEnvironment(k8sClient, ctx, adapter.snapshot, "staging")).To(Succeed())
+			environments, err = adapter.findAvailableEnvironments()
+			Expect(err).To(BeNil())
+			Expect(*environments).To(HaveLen(1))
+			Expect((*environments)[0].Name).To(Equal(productionEnv.Name))
+
+			// Environments the Snapshot was already deployed to aren't promoted to again
+			stagingEnv := env.DeepCopy()
+			stagingEnv.Name = "staging"
+			environments, err = adapter.filterEnvironmentsForPromotion(&[]applicationapiv1alpha1.Environment{*stagingEnv})
+			Expect(err).To(BeNil())
+			Expect(*environments).To(BeEmpty())
+			Expect(gitops.IsSnapshotVerifiedInEnvironment(adapter.snapshot, "staging")).To(BeTrue())
+		})
+
 		It("ensures build labels/annotations prefixed with 'build.appstudio' are propagated from snapshot to Integration test PLR", func() {
-			pipelineRun, err := adapter.createIntegrationPipelineRun(hasApp, integrationTestScenario, hasSnapshot)
+			pipelineRun, err := adapter.createIntegrationPipelineRun(hasApp, integrationTestScenario, hasSnapshot, nil)
 			Expect(err).To(BeNil())
 			Expect(pipelineRun).ToNot(BeNil())
 
@@ -579,7 +902,7 @@ var _ = Describe("Snapshot Adapter", Ordered, func() {
 		})
 
 		It("ensures build labels/annotations non-prefixed with 'build.appstudio' are NOT propagated from snapshot to Integration test PLR", func() {
-			pipelineRun, err := adapter.createIntegrationPipelineRun(hasApp, integrationTestScenario, hasSnapshot)
+			pipelineRun, err := adapter.createIntegrationPipelineRun(hasApp, integrationTestScenario, hasSnapshot, nil)
 			Expect(err).To(BeNil())
 			Expect(pipelineRun).ToNot(BeNil())
 
@@ -596,6 +919,45 @@ var _ = Describe("Snapshot Adapter", Ordered, func() {
 			Expect(found).To(BeFalse())
 
 		})
+
+		It("ensures the outcome of the Releases is reported on the Snapshot", func() {
+			releaseReporter := &MockReleaseReporter{}
+			adapter.status = &MockStatusAdapter{ReleaseReporter: releaseReporter}
+
+			succeededRelease := &releasev1alpha1.Release{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "release-sample",
+					Namespace: "default",
+				},
+				Spec: releasev1alpha1.ReleaseSpec{
+					Snapshot:    hasSnapshot.Name,
+					ReleasePlan: testReleasePlan.Name,
+				},
+			}
+			meta.SetStatusCondition(&succeededRelease.Status.Conditions, metav1.Condition{
+				Type:   "Released",
+				Status: metav1.ConditionTrue,
+				Reason: "Succeeded",
+			})
+			adapter.context = loader.GetMockedContext(ctx, []loader.MockData{
+				{
+					ContextKey: loader.ReleaseContextKey,
+					Resource:   succeededRelease,
+				},
+			})
+
+			result, err := adapter.EnsureReleaseOutcomeReported()
+			Expect(!result.CancelRequest && err == nil).To(BeTrue())
+			Expect(releaseReporter.Called).To(BeTrue())
+			Expect(gitops.IsSnapshotStatusConditionSet(adapter.snapshot, gitops.AppStudioReleasedCondition,
+				metav1.ConditionTrue, gitops.AppStudioReleasedSucceeded)).To(BeTrue())
+
+			// The outcome is only reported again once it changes
+			releaseReporter.Called = false
+			result, err = adapter.EnsureReleaseOutcomeReported()
+			Expect(!result.CancelRequest && err == nil).To(BeTrue())
+			Expect(releaseReporter.Called).To(BeFalse())
+		})
 	})
 
 	It("fails when EnsureAllReleasesExist is called and GetAutoReleasePlansForApplication returns an error", func() {
@@ -621,6 +983,385 @@ var _ = Describe("Snapshot Adapter", Ordered, func() {
 		Expect(buf.String()).Should(ContainSubstring("Snapshot integration status marked as Invalid. Failed to get all ReleasePlans"))
 	})
 
+	It("ensures the Global Candidate of a pinned Component isn't moved", func() {
+		var buf bytes.Buffer
+		log := helpers.IntegrationLogger{Logger: buflogr.NewWithBuffer(&buf)}
+
+		passedSnapshot := hasSnapshot.DeepCopy()
+		gitops.SetSnapshotIntegrationStatusAsFinished(passedSnapshot, "Test message")
+		meta.SetStatusCondition(&passedSnapshot.Status.Conditions, metav1.Condition{
+			Type:    gitops.AppStudioTestSuceededCondition,
+			Status:  metav1.ConditionTrue,
+			Reason:  gitops.AppStudioTestSuceededConditionPassed,
+			Message: "test passed",
+		})
+		pinnedComp := hasComp.DeepCopy()
+		pinnedComp.Annotations = map[string]string{
+			gitops.ComponentPinnedImageAnnotation: "quay.io/redhat-appstudio/sample-image@sha256:841328df1b9f8c4087adbdcfec6cc99ac8308805dea83f6d415d6fb8d40227c1",
+		}
+		adapter = NewAdapter(passedSnapshot, hasApp, pinnedComp, log, loader.NewMockLoader(), k8sClient, ctx)
+
+		result, err := adapter.EnsureGlobalCandidateImageUpdated()
+		Expect(!result.CancelRequest && err == nil).To(BeTrue())
+		Expect(buf.String()).Should(ContainSubstring("The Component is pinned, refusing to move its Global Candidate away from the pinned image"))
+		Expect(pinnedComp.Spec.ContainerImage).To(Equal(hasComp.Spec.ContainerImage))
+	})
+
+	It("ensures the Global Candidate isn't updated while the pin of the Component is invalid", func() {
+		var buf bytes.Buffer
+		log := helpers.IntegrationLogger{Logger: buflogr.NewWithBuffer(&buf)}
+
+		passedSnapshot := hasSnapshot.DeepCopy()
+		gitops.SetSnapshotIntegrationStatusAsFinished(passedSnapshot, "Test message")
+		meta.SetStatusCondition(&passedSnapshot.Status.Conditions, metav1.Condition{
+			Type:    gitops.AppStudioTestSuceededCondition,
+			Status:  metav1.ConditionTrue,
+			Reason:  gitops.AppStudioTestSuceededConditionPassed,
+			Message: "test passed",
+		})
+		invalidPinApp := hasApp.DeepCopy()
+		invalidPinApp.Annotations = map[string]string{
+			gitops.ApplicationPinnedImagesAnnotation: "component-sample",
+		}
+		comp := hasComp.DeepCopy()
+		adapter = NewAdapter(passedSnapshot, invalidPinApp, comp, log, loader.NewMockLoader(), k8sClient, ctx)
+
+		result, err := adapter.EnsureGlobalCandidateImageUpdated()
+		Expect(!result.CancelRequest && err == nil).To(BeTrue())
+		Expect(buf.String()).Should(ContainSubstring("Failed to determine if the Component is pinned, won't update its Global Candidate"))
+		Expect(comp.Spec.ContainerImage).To(Equal(hasComp.Spec.ContainerImage))
+		Expect(gitops.IsSnapshotComponentPinInvalid(passedSnapshot)).To(BeTrue())
+		condition := meta.FindStatusCondition(passedSnapshot.Status.Conditions, gitops.AppStudioComponentPinInvalidCondition)
+		Expect(condition.Message).To(ContainSubstring(gitops.ApplicationPinnedImagesAnnotation))
+
+		// Once the pin is fixed, the Snapshot isn't marked anymore
+		invalidPinApp.Annotations = nil
+		comp.Annotations = map[string]string{
+			gitops.ComponentPinnedImageAnnotation: hasComp.Spec.ContainerImage,
+		}
+		result, err = adapter.EnsureGlobalCandidateImageUpdated()
+		Expect(!result.CancelRequest && err == nil).To(BeTrue())
+		Expect(gitops.IsSnapshotComponentPinInvalid(passedSnapshot)).To(BeFalse())
+	})
+
+	It("ensures the diff against the previous passing Snapshot is recorded", func() {
+		var buf bytes.Buffer
+		log := helpers.IntegrationLogger{Logger: buflogr.NewWithBuffer(&buf)}
+
+		previousSnapshot := hasSnapshot.DeepCopy()
+		previousSnapshot.Name = "snapshot-previous"
+		previousSnapshot.CreationTimestamp = metav1.NewTime(hasSnapshot.CreationTimestamp.Add(-time.Hour))
+		previousSnapshot.Spec.Components[0].ContainerImage = "quay.io/redhat-appstudio/sample-image@sha256:0000000000000000000000000000000000000000000000000000000000000000"
+		previousSnapshot.Spec.Components[0].Source.GitSource = &applicationapiv1alpha1.GitSource{
+			URL:      "https://github.com/devfile-samples/devfile-sample-java-springboot-basic",
+			Revision: "previous",
+		}
+		meta.SetStatusCondition(&previousSnapshot.Status.Conditions, metav1.Condition{
+			Type:    gitops.AppStudioTestSuceededCondition,
+			Status:  metav1.ConditionTrue,
+			Reason:  gitops.AppStudioTestSuceededConditionPassed,
+			Message: "test passed",
+		})
+
+		adapter = NewAdapter(hasSnapshot, hasApp, hasComp, log, loader.NewMockLoader(), k8sClient, ctx)
+		adapter.context = loader.GetMockedContext(ctx, []loader.MockData{
+			{
+				ContextKey: loader.AllSnapshotsContextKey,
+				Resource:   []applicationapiv1alpha1.Snapshot{*previousSnapshot, *hasSnapshot},
+			},
+		})
+
+		result, err := adapter.EnsureSnapshotDiffRecorded()
+		Expect(!result.CancelRequest && err == nil).To(BeTrue())
+		Expect(buf.String()).Should(ContainSubstring("Recorded the diff of the Snapshot against the previous passing Snapshot"))
+
+		diff, err := gitops.GetSnapshotDiff(hasSnapshot)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(diff.BaseSnapshot).To(Equal(previousSnapshot.Name))
+		Expect(diff.Components).To(HaveLen(1))
+		Expect(diff.Components[0].Change).To(Equal(gitops.ComponentUpdated))
+		Expect(diff.Components[0].NewRevision).To(Equal(sample_revision))
+
+		// The diff is only recorded once
+		buf.Reset()
+		result, err = adapter.EnsureSnapshotDiffRecorded()
+		Expect(!result.CancelRequest && err == nil).To(BeTrue())
+		Expect(buf.String()).ShouldNot(ContainSubstring("Recorded the diff"))
+	})
+
+	It("ensures the requested reruns of scenarios are started", func() {
+		var buf bytes.Buffer
+		log := helpers.IntegrationLogger{Logger: buflogr.NewWithBuffer(&buf)}
+
+		Expect(gitops.RequestIntegrationTestScenariosRerun(k8sClient, ctx, hasSnapshot, []string{integrationTestScenario.Name})).To(Succeed())
+		adapter = NewAdapter(hasSnapshot, hasApp, hasComp, log, loader.NewMockLoader(), k8sClient, ctx)
+		adapter.context = loader.GetMockedContext(ctx, []loader.MockData{
+			{
+				ContextKey: loader.AllIntegrationTestScenariosContextKey,
+				Resource:   []v1beta1.IntegrationTestScenario{*integrationTestScenario},
+			},
+		})
+
+		result, err := adapter.EnsureRequestedRerunsStarted()
+		Expect(!result.CancelRequest && err == nil).To(BeTrue())
+		Expect(buf.String()).Should(ContainSubstring("IntegrationTestscenario pipeline has been rerun"))
+		Expect(gitops.IsSnapshotRerunRequested(hasSnapshot)).To(BeFalse())
+		Expect(gitops.HaveAppStudioTestsFinished(hasSnapshot)).To(BeFalse())
+
+		integrationPipelineRuns := &tektonv1beta1.PipelineRunList{}
+		opts := []client.ListOption{
+			client.InNamespace(hasApp.Namespace),
+			client.MatchingLabels{
+				"pipelines.appstudio.openshift.io/type": "test",
+				"appstudio.openshift.io/snapshot":       hasSnapshot.Name,
+				"test.appstudio.openshift.io/scenario":  integrationTestScenario.Name,
+			},
+		}
+		Eventually(func() bool {
+			err := k8sClient.List(ctx, integrationPipelineRuns, opts...)
+			return len(integrationPipelineRuns.Items) > 0 && err == nil
+		}, time.Second*10).Should(BeTrue())
+
+		for _, pipelineRun := range integrationPipelineRuns.Items {
+			pipelineRun := pipelineRun // G601
+			Expect(k8sClient.Delete(ctx, &pipelineRun)).Should(Succeed())
+		}
+	})
+
+	It("ensures the scenarios already rerun for the request aren't rerun again when the rerun is retried", func() {
+		var buf bytes.Buffer
+		log := helpers.IntegrationLogger{Logger: buflogr.NewWithBuffer(&buf)}
+
+		Expect(gitops.RequestIntegrationTestScenariosRerun(k8sClient, ctx, hasSnapshot, []string{integrationTestScenario.Name})).To(Succeed())
+		requestTime, found := gitops.GetRerunRequestTime(hasSnapshot)
+		Expect(found).To(BeTrue())
+		rerunPipelineRun := tektonv1beta1.PipelineRun{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              "pipelinerun-rerun",
+				Namespace:         "default",
+				CreationTimestamp: metav1.NewTime(requestTime.Add(time.Second)),
+				Labels: map[string]string{
+					"test.appstudio.openshift.io/scenario": integrationTestScenario.Name,
+				},
+			},
+		}
+		adapter = NewAdapter(hasSnapshot, hasApp, hasComp, log, loader.NewMockLoader(), k8sClient, ctx)
+		adapter.context = loader.GetMockedContext(ctx, []loader.MockData{
+			{
+				ContextKey: loader.AllIntegrationTestScenariosContextKey,
+				Resource:   []v1beta1.IntegrationTestScenario{*integrationTestScenario},
+			},
+			{
+				ContextKey: loader.PipelineRunsContextKey,
+				Resource:   []tektonv1beta1.PipelineRun{rerunPipelineRun},
+			},
+		})
+
+		result, err := adapter.EnsureRequestedRerunsStarted()
+		Expect(!result.CancelRequest && err == nil).To(BeTrue())
+		Expect(buf.String()).Should(ContainSubstring("The integration pipelineRun was already rerun for the request"))
+		Expect(buf.String()).ShouldNot(ContainSubstring("IntegrationTestscenario pipeline has been rerun"))
+		Expect(gitops.IsSnapshotRerunRequested(hasSnapshot)).To(BeFalse())
+		Expect(hasSnapshot.Annotations).NotTo(HaveKey(gitops.SnapshotRerunRequestTimeAnnotation))
+	})
+
+	It("ensures a failed batch Snapshot is bisected and the verdicts are recorded", func() {
+		var buf bytes.Buffer
+		log := helpers.IntegrationLogger{Logger: buflogr.NewWithBuffer(&buf)}
+
+		batchSnapshot := &applicationapiv1alpha1.Snapshot{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "snapshot-batch",
+				Namespace: "default",
+				Labels: map[string]string{
+					gitops.SnapshotTypeLabel: gitops.SnapshotBatchType,
+				},
+				Annotations: map[string]string{
+					gitops.SnapshotBatchComponentsAnnotation: "component-batched," + hasComp.Name,
+				},
+			},
+			Spec: applicationapiv1alpha1.SnapshotSpec{
+				Application: hasApp.Name,
+				Components: []applicationapiv1alpha1.SnapshotComponent{
+					{
+						Name:           hasComp.Name,
+						ContainerImage: sample_image,
+					},
+					{
+						Name:           "component-batched",
+						ContainerImage: sample_image,
+					},
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, batchSnapshot)).Should(Succeed())
+		batchSnapshot, err := gitops.MarkSnapshotAsFailed(k8sClient, ctx, batchSnapshot, "test failed")
+		Expect(err).NotTo(HaveOccurred())
+
+		adapter = NewAdapter(batchSnapshot, hasApp, nil, log, loader.NewMockLoader(), k8sClient, ctx)
+		adapter.context = loader.GetMockedContext(ctx, []loader.MockData{
+			{
+				ContextKey: loader.ApplicationComponentsContextKey,
+				Resource: []applicationapiv1alpha1.Component{*hasComp, {
+					ObjectMeta: metav1.ObjectMeta{Name: "component-batched", Namespace: "default"},
+					Spec:       applicationapiv1alpha1.ComponentSpec{ComponentName: "component-batched", Application: hasApp.Name},
+				}},
+			},
+		})
+
+		result, err := adapter.EnsureBatchSnapshotBisected()
+		Expect(!result.CancelRequest && err == nil).To(BeTrue())
+		Expect(buf.String()).Should(ContainSubstring("Bisected the failed batch Snapshot"))
+
+		snapshots := &applicationapiv1alpha1.SnapshotList{}
+		Expect(k8sClient.List(ctx, snapshots, client.InNamespace("default"))).To(Succeed())
+		bisectedSnapshots := []applicationapiv1alpha1.Snapshot{}
+		for _, snapshot := range snapshots.Items {
+			if snapshot.Annotations[gitops.SnapshotBatchParentAnnotation] == batchSnapshot.Name {
+				Expect(snapshot.Annotations[gitops.SnapshotBatchRootAnnotation]).To(Equal(batchSnapshot.Name))
+				bisectedSnapshots = append(bisectedSnapshots, snapshot)
+			}
+		}
+		Expect(bisectedSnapshots).To(HaveLen(2))
+		Expect(bisectedSnapshots).To(ConsistOf(
+			HaveField("Name", batchSnapshot.Name+"-1"),
+			HaveField("Name", batchSnapshot.Name+"-2"),
+		))
+
+		// Bisecting the batch again doesn't create more Snapshots
+		result, err = adapter.EnsureBatchSnapshotBisected()
+		Expect(!result.CancelRequest && err == nil).To(BeTrue())
+		Expect(k8sClient.List(ctx, snapshots, client.InNamespace("default"))).To(Succeed())
+		halves := 0
+		for _, snapshot := range snapshots.Items {
+			if snapshot.Annotations[gitops.SnapshotBatchParentAnnotation] == batchSnapshot.Name {
+				halves++
+			}
+		}
+		Expect(halves).To(Equal(2))
+
+		// The half that failed on its own is recorded as failed on the original batch Snapshot
+		for i := range bisectedSnapshots {
+			bisectedSnapshot := &bisectedSnapshots[i]
+			if gitops.GetSnapshotBatchComponents(bisectedSnapshot)[0] != hasComp.Name {
+				continue
+			}
+			bisectedSnapshot, err = gitops.MarkSnapshotAsFailed(k8sClient, ctx, bisectedSnapshot, "test failed")
+			Expect(err).NotTo(HaveOccurred())
+			adapter = NewAdapter(bisectedSnapshot, hasApp, nil, log, loader.NewMockLoader(), k8sClient, ctx)
+			result, err = adapter.EnsureBatchSnapshotBisected()
+			Expect(!result.CancelRequest && err == nil).To(BeTrue())
+		}
+
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: "default", Name: batchSnapshot.Name}, batchSnapshot)).To(Succeed())
+		Expect(gitops.GetBatchVerdicts(batchSnapshot)).To(Equal(map[string]string{hasComp.Name: gitops.BatchVerdictFailed}))
+
+		for i := range bisectedSnapshots {
+			Expect(k8sClient.Delete(ctx, &bisectedSnapshots[i])).To(Succeed())
+		}
+		Expect(k8sClient.Delete(ctx, batchSnapshot)).To(Succeed())
+	})
+
+	It("ensures the paused steps are skipped and recorded on the Snapshot", func() {
+		var buf bytes.Buffer
+		log := helpers.IntegrationLogger{Logger: buflogr.NewWithBuffer(&buf)}
+
+		pausedSnapshot := &applicationapiv1alpha1.Snapshot{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "snapshot-paused",
+				Namespace: "default",
+				Labels: map[string]string{
+					gitops.SnapshotTypeLabel:      "component",
+					gitops.SnapshotComponentLabel: hasComp.Name,
+				},
+			},
+			Spec: applicationapiv1alpha1.SnapshotSpec{
+				Application: hasApp.Name,
+				Components: []applicationapiv1alpha1.SnapshotComponent{
+					{
+						Name:           hasComp.Name,
+						ContainerImage: sample_image,
+					},
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, pausedSnapshot)).Should(Succeed())
+		defer func() {
+			err := k8sClient.Delete(ctx, pausedSnapshot)
+			Expect(err == nil || errors.IsNotFound(err)).To(BeTrue())
+		}()
+		_, err := gitops.MarkSnapshotAsPassed(k8sClient, ctx, pausedSnapshot, "test passed")
+		Expect(err).NotTo(HaveOccurred())
+
+		pausedApp := hasApp.DeepCopy()
+		pausedApp.Annotations = map[string]string{gitops.PauseAutoReleaseAnnotation: "true"}
+		pausedComp := hasComp.DeepCopy()
+		pausedComp.Annotations = map[string]string{gitops.PauseGlobalCandidateUpdateAnnotation: "true"}
+		adapter = NewAdapter(pausedSnapshot, pausedApp, pausedComp, log, loader.NewMockLoader(), k8sClient, ctx)
+
+		result, err := adapter.EnsureAllReleasesExist()
+		Expect(!result.CancelRequest && err == nil).To(BeTrue())
+		result, err = adapter.EnsureGlobalCandidateImageUpdated()
+		Expect(!result.CancelRequest && err == nil).To(BeTrue())
+		Expect(buf.String()).Should(ContainSubstring("Skipping a paused step for the Snapshot"))
+
+		condition := meta.FindStatusCondition(pausedSnapshot.Status.Conditions, gitops.AppStudioAutomationPausedCondition)
+		Expect(condition).NotTo(BeNil())
+		Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+		Expect(condition.Message).To(Equal(
+			"automatic release is paused by the " + gitops.PauseAutoReleaseAnnotation + " annotation on the Application " + hasApp.Name + "; " +
+				"Global Candidate List update is paused by the " + gitops.PauseGlobalCandidateUpdateAnnotation + " annotation on the Component " + hasComp.Name))
+	})
+
+	It("ensures the paused steps resume once their pause annotations are removed", func() {
+		var buf bytes.Buffer
+		log := helpers.IntegrationLogger{Logger: buflogr.NewWithBuffer(&buf)}
+
+		pausedSnapshot := &applicationapiv1alpha1.Snapshot{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "snapshot-resumed",
+				Namespace: "default",
+				Labels: map[string]string{
+					gitops.SnapshotTypeLabel:      "component",
+					gitops.SnapshotComponentLabel: hasComp.Name,
+				},
+			},
+			Spec: applicationapiv1alpha1.SnapshotSpec{
+				Application: hasApp.Name,
+				Components: []applicationapiv1alpha1.SnapshotComponent{
+					{
+						Name:           hasComp.Name,
+						ContainerImage: sample_image,
+					},
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, pausedSnapshot)).Should(Succeed())
+		defer func() {
+			err := k8sClient.Delete(ctx, pausedSnapshot)
+			Expect(err == nil || errors.IsNotFound(err)).To(BeTrue())
+		}()
+		_, err := gitops.MarkSnapshotAsPassed(k8sClient, ctx, pausedSnapshot, "test passed")
+		Expect(err).NotTo(HaveOccurred())
+
+		pausedApp := hasApp.DeepCopy()
+		pausedApp.Annotations = map[string]string{gitops.PauseAutoReleaseAnnotation: "true"}
+		adapter = NewAdapter(pausedSnapshot, pausedApp, hasComp, log, loader.NewMockLoader(), k8sClient, ctx)
+
+		result, err := adapter.EnsureAllReleasesExist()
+		Expect(!result.CancelRequest && err == nil).To(BeTrue())
+		Expect(gitops.IsSnapshotAutomationPaused(pausedSnapshot)).To(BeTrue())
+
+		delete(pausedApp.Annotations, gitops.PauseAutoReleaseAnnotation)
+		result, err = adapter.EnsureAllReleasesExist()
+		Expect(!result.CancelRequest && err == nil).To(BeTrue())
+		Expect(gitops.IsSnapshotAutomationPaused(pausedSnapshot)).To(BeFalse())
+
+		condition := meta.FindStatusCondition(pausedSnapshot.Status.Conditions, gitops.AppStudioAutomationPausedCondition)
+		Expect(condition).NotTo(BeNil())
+		Expect(condition.Reason).To(Equal(gitops.AppStudioAutomationResumedReason))
+	})
+
 	When("multiple components exist", func() {
 
 		var (
@@ -716,6 +1457,28 @@ var _ = Describe("Snapshot Adapter", Ordered, func() {
 
 		})
 
+		It("ensures a binding bound to a newer Snapshot isn't updated with an older one", func() {
+			components := []applicationapiv1alpha1.Component{*hasComp}
+			snapshotEnvironmentBinding, err := adapter.createSnapshotEnvironmentBindingForSnapshot(adapter.application, env, hasSnapshotPR, &components)
+			Expect(err).To(BeNil())
+
+			olderSnapshot := hasSnapshot.DeepCopy()
+			olderSnapshot.Name = "older-snapshot"
+			olderSnapshot.CreationTimestamp = metav1.NewTime(hasSnapshotPR.CreationTimestamp.Add(-time.Hour))
+			updatedSnapshotEnvironmentBinding, err := adapter.updateExistingSnapshotEnvironmentBindingWithSnapshot(snapshotEnvironmentBinding, olderSnapshot, &components)
+			Expect(err).To(BeNil())
+			Expect(updatedSnapshotEnvironmentBinding.Spec.Snapshot).To(Equal(hasSnapshotPR.Name))
+
+			newerSnapshot := hasSnapshot.DeepCopy()
+			newerSnapshot.Name = "newer-snapshot"
+			newerSnapshot.CreationTimestamp = metav1.NewTime(hasSnapshotPR.CreationTimestamp.Add(time.Hour))
+			updatedSnapshotEnvironmentBinding, err = adapter.updateExistingSnapshotEnvironmentBindingWithSnapshot(snapshotEnvironmentBinding, newerSnapshot, &components)
+			Expect(err).To(BeNil())
+			Expect(updatedSnapshotEnvironmentBinding.Spec.Snapshot).To(Equal(newerSnapshot.Name))
+
+			Expect(k8sClient.Delete(ctx, snapshotEnvironmentBinding)).To(Succeed())
+		})
+
 		It("ensures the ephemeral copy Environment are created for IntegrationTestScenario", func() {
 			result, err := adapter.EnsureCreationOfEnvironment()
 			Expect(!result.CancelRequest && err == nil).To(BeTrue())
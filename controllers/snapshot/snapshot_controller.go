@@ -26,12 +26,19 @@ import (
 	"github.com/redhat-appstudio/integration-service/gitops"
 	"github.com/redhat-appstudio/integration-service/helpers"
 	"github.com/redhat-appstudio/integration-service/loader"
+	"github.com/redhat-appstudio/integration-service/tekton"
 	"github.com/redhat-appstudio/operator-toolkit/controller"
+	tektonv1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 )
 
 // Reconciler reconciles an Snapshot object
@@ -71,6 +78,8 @@ func NewSnapshotReconciler(client client.Client, logger *logr.Logger, scheme *ru
 //+kubebuilder:rbac:groups=appstudio.redhat.com,resources=releases,verbs=create;delete;get;list;patch;update;watch
 //+kubebuilder:rbac:groups=appstudio.redhat.com,resources=releaseplans,verbs=get;list;watch
 //+kubebuilder:rbac:groups=appstudio.redhat.com,resources=releaseplans/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -105,8 +114,11 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 	adapter := NewAdapter(snapshot, application, component, logger, loader, r.Client, ctx)
 
 	return controller.ReconcileHandler([]controller.Operation{
+		adapter.EnsureSnapshotDiffRecorded,
+		adapter.EnsureRequestedRerunsStarted,
 		adapter.EnsureAllReleasesExist,
 		adapter.EnsureGlobalCandidateImageUpdated,
+		adapter.EnsureBatchSnapshotBisected,
 		adapter.EnsureSnapshotEnvironmentBindingExist,
 		adapter.EnsureCreationOfEnvironment,
 		adapter.EnsureAllIntegrationTestPipelinesExist,
@@ -115,16 +127,26 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 
 // AdapterInterface is an interface defining all the operations that should be defined in an Integration adapter.
 type AdapterInterface interface {
+	EnsureSnapshotDiffRecorded() (controller.OperationResult, error)
+	EnsureRequestedRerunsStarted() (controller.OperationResult, error)
 	EnsureAllReleasesExist() (controller.OperationResult, error)
+	EnsureReleaseOutcomeReported() (controller.OperationResult, error)
 	EnsureCreationOfEnvironment() (controller.OperationResult, error)
 	EnsureAllIntegrationTestPipelinesExist() (controller.OperationResult, error)
 	EnsureGlobalCandidateImageUpdated() (controller.OperationResult, error)
+	EnsureBatchSnapshotBisected() (controller.OperationResult, error)
 	EnsureSnapshotEnvironmentBindingExist() (controller.OperationResult, error)
 }
 
-// SetupController creates a new Integration controller and adds it to the Manager.
+// SetupController creates a new Integration controller and adds it to the Manager together with the controller
+// reporting the outcome of the Releases of the Snapshots.
 func SetupController(manager ctrl.Manager, log *logr.Logger) error {
-	return setupControllerWithManager(manager, NewSnapshotReconciler(manager.GetClient(), log, manager.GetScheme()))
+	err := setupControllerWithManager(manager, NewSnapshotReconciler(manager.GetClient(), log, manager.GetScheme()))
+	if err != nil {
+		return err
+	}
+
+	return setupReleaseControllerWithManager(manager, NewReleaseReconciler(manager.GetClient(), log, manager.GetScheme()))
 }
 
 // setupCache indexes fields for each of the resources used in the release adapter in those cases where filtering by
@@ -152,9 +174,62 @@ func setupControllerWithManager(manager ctrl.Manager, controller *Reconciler) er
 		return err
 	}
 
+	// Finished integration PipelineRuns trigger the reconciliation of their Snapshot,
+	// so the PipelineRuns of the IntegrationTestScenarios depending on them can be created.
+	// Snapshots verified in an Environment are reconciled to promote them to its child Environments
+	// Components and Applications whose pause annotations were removed trigger the reconciliation of their paused Snapshots
 	return ctrl.NewControllerManagedBy(manager).
-		For(&applicationapiv1alpha1.Snapshot{}).
-		WithEventFilter(predicate.Or(
-			gitops.IntegrationSnapshotChangePredicate())).
+		For(&applicationapiv1alpha1.Snapshot{}, builder.WithPredicates(predicate.Or(
+			gitops.IntegrationSnapshotChangePredicate(), gitops.SnapshotPromotionChainAdvancedPredicate(),
+			gitops.SnapshotRerunRequestedPredicate(), gitops.SnapshotApprovedPredicate()))).
+		Owns(&tektonv1beta1.PipelineRun{}, builder.WithPredicates(
+			tekton.IntegrationPipelineRunFinishedPredicate())).
+		Watches(&source.Kind{Type: &applicationapiv1alpha1.Application{}}, handler.EnqueueRequestsFromMapFunc(controller.getPausedSnapshotsForApplication),
+			builder.WithPredicates(gitops.AutomationResumedPredicate())).
+		Watches(&source.Kind{Type: &applicationapiv1alpha1.Component{}}, handler.EnqueueRequestsFromMapFunc(controller.getPausedSnapshotsForComponent),
+			builder.WithPredicates(gitops.AutomationResumedPredicate())).
 		Complete(controller)
 }
+
+// getPausedSnapshotsForApplication maps an Application to reconcile requests for its Snapshots
+// whose automated steps were paused.
+func (r *Reconciler) getPausedSnapshotsForApplication(object client.Object) []reconcile.Request {
+	return r.getPausedSnapshotRequests(object.GetNamespace(), object.GetName())
+}
+
+// getPausedSnapshotsForComponent maps a Component to reconcile requests for the Snapshots of its Application
+// whose automated steps were paused, as the Snapshots of other Components may include its image too.
+func (r *Reconciler) getPausedSnapshotsForComponent(object client.Object) []reconcile.Request {
+	component, ok := object.(*applicationapiv1alpha1.Component)
+	if !ok {
+		return []reconcile.Request{}
+	}
+
+	return r.getPausedSnapshotRequests(component.Namespace, component.Spec.Application)
+}
+
+// getPausedSnapshotRequests returns reconcile requests for the Snapshots of the given Application
+// whose automated steps were paused.
+func (r *Reconciler) getPausedSnapshotRequests(namespace, application string) []reconcile.Request {
+	snapshots := &applicationapiv1alpha1.SnapshotList{}
+	err := r.List(context.Background(), snapshots, client.InNamespace(namespace))
+	if err != nil {
+		r.Log.Error(err, "Failed to list the Snapshots to resume", "namespace", namespace)
+		return []reconcile.Request{}
+	}
+
+	requests := []reconcile.Request{}
+	for _, snapshot := range snapshots.Items {
+		snapshot := snapshot // G601
+		if snapshot.Spec.Application == application && gitops.IsSnapshotAutomationPaused(&snapshot) {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Namespace: snapshot.Namespace,
+					Name:      snapshot.Name,
+				},
+			})
+		}
+	}
+
+	return requests
+}
@@ -151,6 +151,22 @@ var _ = Describe("SnapshotController", func() {
 		Expect(err).To(BeNil())
 	})
 
+	It("maps the Application and Components whose pause annotations were removed to their paused Snapshots", func() {
+		Expect(snapshotReconciler.getPausedSnapshotsForApplication(hasApp)).To(BeEmpty())
+
+		Expect(gitops.MarkSnapshotAutomationPaused(k8sClient, ctx, hasSnapshot, "testing is paused")).To(Succeed())
+		Eventually(func() []reconcile.Request {
+			return snapshotReconciler.getPausedSnapshotsForApplication(hasApp)
+		}).Should(ConsistOf(req))
+		Eventually(func() []reconcile.Request {
+			return snapshotReconciler.getPausedSnapshotsForComponent(hasComp)
+		}).Should(ConsistOf(req))
+
+		otherApp := hasApp.DeepCopy()
+		otherApp.Name = "other-application"
+		Expect(snapshotReconciler.getPausedSnapshotsForApplication(otherApp)).To(BeEmpty())
+	})
+
 	It("can setup the cache by adding a new index field to search for ReleasePlanAdmissions", func() {
 		err := setupCache(manager)
 		Expect(err).ToNot(HaveOccurred())
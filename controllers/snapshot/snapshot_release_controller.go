@@ -0,0 +1,105 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package snapshot
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	"github.com/redhat-appstudio/integration-service/helpers"
+	"github.com/redhat-appstudio/integration-service/loader"
+	"github.com/redhat-appstudio/integration-service/release"
+	"github.com/redhat-appstudio/operator-toolkit/controller"
+	releasev1alpha1 "github.com/redhat-appstudio/release-service/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ReleaseReconciler reconciles the Releases of Snapshots, reporting their outcome on the released Snapshot
+// without running the other operations of the Snapshot controller.
+type ReleaseReconciler struct {
+	client.Client
+	Log    logr.Logger
+	Scheme *runtime.Scheme
+}
+
+// NewReleaseReconciler creates and returns a ReleaseReconciler.
+func NewReleaseReconciler(client client.Client, logger *logr.Logger, scheme *runtime.Scheme) *ReleaseReconciler {
+	return &ReleaseReconciler{
+		Client: client,
+		Log:    logger.WithName("snapshot-release"),
+		Scheme: scheme,
+	}
+}
+
+// Reconcile reports the outcome of the Releases of the Snapshot released by the reconciled Release.
+func (r *ReleaseReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := helpers.IntegrationLogger{Logger: r.Log.WithValues("release", req.NamespacedName)}
+	loader := loader.NewLoader()
+
+	snapshotRelease := &releasev1alpha1.Release{}
+	err := r.Get(ctx, req.NamespacedName, snapshotRelease)
+	if err != nil {
+		logger.Error(err, "Failed to get release for", "req", req.NamespacedName)
+		if errors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+
+		return ctrl.Result{}, err
+	}
+
+	snapshot, err := loader.GetSnapshot(r.Client, ctx, snapshotRelease.Spec.Snapshot, snapshotRelease.Namespace)
+	if err != nil {
+		logger.Error(err, "Failed to get Snapshot from the Release")
+		if errors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+
+		return ctrl.Result{}, err
+	}
+
+	application, err := loader.GetApplicationFromSnapshot(r.Client, ctx, snapshot)
+	if err != nil {
+		logger.Error(err, "Failed to get Application from the Snapshot")
+		return ctrl.Result{}, err
+	}
+	logger = logger.WithApp(*application)
+
+	component, err := loader.GetComponentFromSnapshot(r.Client, ctx, snapshot)
+	if err != nil {
+		logger.Error(err, "Failed to get Component from the Snapshot")
+		return ctrl.Result{}, err
+	}
+
+	adapter := NewAdapter(snapshot, application, component, logger, loader, r.Client, ctx)
+
+	return controller.ReconcileHandler([]controller.Operation{
+		adapter.EnsureReleaseOutcomeReported,
+	})
+}
+
+// setupReleaseControllerWithManager sets up the controller with the Manager which monitors the Releases whose
+// progress or outcome changed.
+func setupReleaseControllerWithManager(manager ctrl.Manager, controller *ReleaseReconciler) error {
+	return ctrl.NewControllerManagedBy(manager).
+		Named("snapshot-release").
+		For(&releasev1alpha1.Release{}, builder.WithPredicates(release.ReleaseStatusChangedPredicate())).
+		Complete(controller)
+}
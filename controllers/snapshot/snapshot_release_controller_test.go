@@ -0,0 +1,93 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package snapshot
+
+import (
+	"reflect"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	releasev1alpha1 "github.com/redhat-appstudio/release-service/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientsetscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var _ = Describe("ReleaseReconciler", func() {
+	var (
+		manager           ctrl.Manager
+		releaseReconciler *ReleaseReconciler
+		scheme            runtime.Scheme
+		snapshotRelease   *releasev1alpha1.Release
+	)
+
+	BeforeEach(func() {
+		snapshotRelease = &releasev1alpha1.Release{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "release-sample",
+				Namespace: "default",
+			},
+			Spec: releasev1alpha1.ReleaseSpec{
+				Snapshot:    "non-existent-snapshot",
+				ReleasePlan: "releaseplan-sample",
+			},
+		}
+		Expect(k8sClient.Create(ctx, snapshotRelease)).Should(Succeed())
+
+		var err error
+		manager, err = ctrl.NewManager(cfg, ctrl.Options{
+			Scheme:             clientsetscheme.Scheme,
+			MetricsBindAddress: "0", // this disables metrics
+			LeaderElection:     false,
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		releaseReconciler = NewReleaseReconciler(k8sClient, &logf.Log, &scheme)
+	})
+
+	AfterEach(func() {
+		err := k8sClient.Delete(ctx, snapshotRelease)
+		Expect(err == nil || errors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("can create and return a new ReleaseReconciler object", func() {
+		Expect(reflect.TypeOf(releaseReconciler)).To(Equal(reflect.TypeOf(&ReleaseReconciler{})))
+	})
+
+	It("stops reconciling Releases which don't exist or whose Snapshot doesn't exist", func() {
+		result, err := releaseReconciler.Reconcile(ctx, ctrl.Request{
+			NamespacedName: types.NamespacedName{Namespace: "default", Name: "non-existent"},
+		})
+		Expect(result).To(Equal(ctrl.Result{}))
+		Expect(err).To(BeNil())
+
+		result, err = releaseReconciler.Reconcile(ctx, ctrl.Request{
+			NamespacedName: types.NamespacedName{Namespace: snapshotRelease.Namespace, Name: snapshotRelease.Name},
+		})
+		Expect(result).To(Equal(ctrl.Result{}))
+		Expect(err).To(BeNil())
+	})
+
+	It("can setup a new controller manager with the given releaseReconciler", func() {
+		Expect(setupReleaseControllerWithManager(manager, releaseReconciler)).To(Succeed())
+	})
+})
@@ -3,6 +3,7 @@ package github
 import (
 	"context"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/bradleyfalzon/ghinstallation/v2"
@@ -39,6 +40,7 @@ func (s *CheckRunAdapter) GetStatus() string {
 // AppsService defines the methods used in the github Apps service.
 type AppsService interface {
 	CreateInstallationToken(ctx context.Context, id int64, opts *ghapi.InstallationTokenOptions) (*ghapi.InstallationToken, *ghapi.Response, error)
+	Get(ctx context.Context, appSlug string) (*ghapi.App, *ghapi.Response, error)
 }
 
 // ChecksService defines the methods used in the github Checks service.
@@ -51,11 +53,22 @@ type ChecksService interface {
 // IssuesService defines the methods used in the github Issues service.
 type IssuesService interface {
 	CreateComment(ctx context.Context, owner string, repo string, number int, comment *ghapi.IssueComment) (*ghapi.IssueComment, *ghapi.Response, error)
+	ListComments(ctx context.Context, owner string, repo string, number int, opts *ghapi.IssueListCommentsOptions) ([]*ghapi.IssueComment, *ghapi.Response, error)
+	EditComment(ctx context.Context, owner string, repo string, commentID int64, comment *ghapi.IssueComment) (*ghapi.IssueComment, *ghapi.Response, error)
 }
 
 // RepositoriesService defines the methods used in the github Repositories service.
 type RepositoriesService interface {
 	CreateStatus(ctx context.Context, owner string, repo string, ref string, status *ghapi.RepoStatus) (*ghapi.RepoStatus, *ghapi.Response, error)
+	GetPermissionLevel(ctx context.Context, owner string, repo string, user string) (*ghapi.RepositoryPermissionLevel, *ghapi.Response, error)
+	ListDeployments(ctx context.Context, owner string, repo string, opts *ghapi.DeploymentsListOptions) ([]*ghapi.Deployment, *ghapi.Response, error)
+	CreateDeployment(ctx context.Context, owner string, repo string, request *ghapi.DeploymentRequest) (*ghapi.Deployment, *ghapi.Response, error)
+	CreateDeploymentStatus(ctx context.Context, owner string, repo string, deployment int64, request *ghapi.DeploymentStatusRequest) (*ghapi.DeploymentStatus, *ghapi.Response, error)
+}
+
+// UsersService defines the methods used in the github Users service.
+type UsersService interface {
+	Get(ctx context.Context, user string) (*ghapi.User, *ghapi.Response, error)
 }
 
 // ClientInterface defines the methods that should be implemented by a GitHub client
@@ -66,7 +79,14 @@ type ClientInterface interface {
 	UpdateCheckRun(ctx context.Context, checkRunID int64, cra *CheckRunAdapter) error
 	GetCheckRunID(ctx context.Context, owner string, repo string, SHA string, externalID string, appID int64) (*int64, error)
 	CreateComment(ctx context.Context, owner string, repo string, issueNumber int, body string) (int64, error)
+	ListComments(ctx context.Context, owner string, repo string, issueNumber int) ([]*ghapi.IssueComment, error)
+	GetCommentIDWithMarker(ctx context.Context, owner string, repo string, issueNumber int, marker string) (*int64, error)
+	EditComment(ctx context.Context, owner string, repo string, commentID int64, body string) (int64, error)
 	CreateCommitStatus(ctx context.Context, owner string, repo string, SHA string, state string, description string, statusContext string) (int64, error)
+	GetUserPermission(ctx context.Context, owner string, repo string, user string) (string, error)
+	GetDeploymentID(ctx context.Context, owner string, repo string, ref string, environment string) (*int64, error)
+	CreateDeployment(ctx context.Context, owner string, repo string, ref string, environment string, description string) (int64, error)
+	CreateDeploymentStatus(ctx context.Context, owner string, repo string, deploymentID int64, state string, description string) (int64, error)
 }
 
 // Client is an abstraction around the API client.
@@ -77,6 +97,15 @@ type Client struct {
 	checks ChecksService
 	issues IssuesService
 	repos  RepositoriesService
+	users  UsersService
+	// token is the token the client is authenticated with
+	token string
+	// installationToken is the last token created by CreateAppInstallationToken and installationApps the Apps
+	// service authenticated as the App it was created for
+	installationToken string
+	installationApps  AppsService
+	// login is the login of the user or App the token belongs to, once it's known
+	login string
 }
 
 // GetAppsService returns either the default or custom Apps service.
@@ -111,6 +140,14 @@ func (c *Client) GetRepositoriesService() RepositoriesService {
 	return c.repos
 }
 
+// GetUsersService returns either the default or custom Users service.
+func (c *Client) GetUsersService() UsersService {
+	if c.users == nil {
+		return c.gh.Users
+	}
+	return c.users
+}
+
 // ClientOption is used to extend Client with optional parameters.
 type ClientOption = func(c *Client)
 
@@ -142,6 +179,13 @@ func WithRepositoriesService(svc RepositoriesService) ClientOption {
 	}
 }
 
+// WithUsersService is an option which allows for overriding the github client's default Users service.
+func WithUsersService(svc UsersService) ClientOption {
+	return func(c *Client) {
+		c.users = svc
+	}
+}
+
 // NewClient constructs a new Client.
 func NewClient(logger logr.Logger, opts ...ClientOption) *Client {
 	client := Client{
@@ -173,6 +217,8 @@ func (c *Client) CreateAppInstallationToken(ctx context.Context, appID int64, in
 	if err != nil {
 		return "", err
 	}
+	c.installationToken = installToken.GetToken()
+	c.installationApps = c.GetAppsService()
 
 	return installToken.GetToken(), nil
 }
@@ -184,6 +230,8 @@ func (c *Client) SetOAuthToken(ctx context.Context, token string) {
 	)
 
 	c.gh = ghapi.NewClient(oauth2.NewClient(ctx, ts))
+	c.token = token
+	c.login = ""
 }
 
 // CreateCheckRun creates a new CheckRun via the GitHub API.
@@ -318,6 +366,89 @@ func (c *Client) CreateComment(ctx context.Context, owner string, repo string, i
 	return *comment.ID, nil
 }
 
+// ListComments returns all the comments of an issue or pull request via the GitHub API, following the pagination.
+func (c *Client) ListComments(ctx context.Context, owner string, repo string, issueNumber int) ([]*ghapi.IssueComment, error) {
+	allComments := []*ghapi.IssueComment{}
+	opts := &ghapi.IssueListCommentsOptions{ListOptions: ghapi.ListOptions{PerPage: 100}}
+	for {
+		comments, res, err := c.GetIssuesService().ListComments(ctx, owner, repo, issueNumber, opts)
+		if err != nil {
+			return nil, err
+		}
+		allComments = append(allComments, comments...)
+
+		if res == nil || res.NextPage == 0 {
+			break
+		}
+		opts.Page = res.NextPage
+	}
+
+	return allComments, nil
+}
+
+// GetCommentIDWithMarker returns the ID of the first comment of the issue or pull request posted by the user or
+// App the client is authenticated as whose body contains the given marker, or nil if there is none. Comments of
+// anyone else are ignored, so they can't take the place of the comment by copying its marker.
+func (c *Client) GetCommentIDWithMarker(ctx context.Context, owner string, repo string, issueNumber int, marker string) (*int64, error) {
+	login, err := c.getAuthenticatedLogin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	comments, err := c.ListComments(ctx, owner, repo, issueNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, comment := range comments {
+		if comment.GetUser().GetLogin() == login && strings.Contains(comment.GetBody(), marker) {
+			return comment.ID, nil
+		}
+	}
+	c.logger.Info("Found no comments with a matching marker", "IssueNumber", issueNumber, "Marker", marker)
+
+	return nil, nil
+}
+
+// getAuthenticatedLogin returns the login of the user or App the client is authenticated as. The comments of an App
+// are posted by its bot user, named after the slug of the App.
+func (c *Client) getAuthenticatedLogin(ctx context.Context) (string, error) {
+	if c.login != "" {
+		return c.login, nil
+	}
+
+	if c.installationApps != nil && c.token == c.installationToken {
+		app, _, err := c.installationApps.Get(ctx, "")
+		if err != nil {
+			return "", err
+		}
+		c.login = app.GetSlug() + "[bot]"
+	} else {
+		user, _, err := c.GetUsersService().Get(ctx, "")
+		if err != nil {
+			return "", err
+		}
+		c.login = user.GetLogin()
+	}
+
+	return c.login, nil
+}
+
+// EditComment replaces the body of an existing issue comment via the GitHub API.
+func (c *Client) EditComment(ctx context.Context, owner string, repo string, commentID int64, body string) (int64, error) {
+	comment, _, err := c.GetIssuesService().EditComment(ctx, owner, repo, commentID, &ghapi.IssueComment{Body: &body})
+	if err != nil {
+		return 0, err
+	}
+
+	c.logger.Info("Updated comment",
+		"ID", comment.ID,
+		"Owner", owner,
+		"Repository", repo,
+	)
+	return *comment.ID, nil
+}
+
 // CreateCommitStatus creates a repository commit status via the GitHub API.
 func (c *Client) CreateCommitStatus(ctx context.Context, owner string, repo string, SHA string, state string, description string, statusContext string) (int64, error) {
 	status, _, err := c.GetRepositoriesService().CreateStatus(ctx, owner, repo, SHA, &ghapi.RepoStatus{State: &state, Description: &description, Context: &statusContext})
@@ -334,3 +465,80 @@ func (c *Client) CreateCommitStatus(ctx context.Context, owner string, repo stri
 	)
 	return *status.ID, nil
 }
+
+// GetUserPermission returns the permission level (admin, write, read or none) the user has on the repository
+// via the GitHub API.
+func (c *Client) GetUserPermission(ctx context.Context, owner string, repo string, user string) (string, error) {
+	permissionLevel, _, err := c.GetRepositoriesService().GetPermissionLevel(ctx, owner, repo, user)
+	if err != nil {
+		return "", err
+	}
+
+	return permissionLevel.GetPermission(), nil
+}
+
+// GetDeploymentID returns the ID of the latest deployment of the ref to the environment, or nil if there is none.
+func (c *Client) GetDeploymentID(ctx context.Context, owner string, repo string, ref string, environment string) (*int64, error) {
+	deployments, _, err := c.GetRepositoriesService().ListDeployments(ctx, owner, repo, &ghapi.DeploymentsListOptions{
+		Ref:         ref,
+		Environment: environment,
+		ListOptions: ghapi.ListOptions{PerPage: 1},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Deployments are listed from the newest to the oldest
+	if len(deployments) == 0 {
+		c.logger.Info("Found no deployments of the ref to the environment", "Ref", ref, "Environment", environment)
+		return nil, nil
+	}
+
+	return deployments[0].ID, nil
+}
+
+// CreateDeployment creates a new deployment of the ref to the environment via the GitHub API. The deployment
+// doesn't merge the default branch into the ref nor wait for the commit statuses of the ref.
+func (c *Client) CreateDeployment(ctx context.Context, owner string, repo string, ref string, environment string, description string) (int64, error) {
+	autoMerge := false
+	requiredContexts := []string{}
+	deployment, _, err := c.GetRepositoriesService().CreateDeployment(ctx, owner, repo, &ghapi.DeploymentRequest{
+		Ref:              &ref,
+		Environment:      &environment,
+		Description:      &description,
+		AutoMerge:        &autoMerge,
+		RequiredContexts: &requiredContexts,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	c.logger.Info("Created deployment",
+		"ID", deployment.ID,
+		"Owner", owner,
+		"Repository", repo,
+		"Ref", ref,
+		"Environment", environment,
+	)
+	return *deployment.ID, nil
+}
+
+// CreateDeploymentStatus creates a new status (in_progress, success, failure or inactive) of a deployment via the GitHub API.
+func (c *Client) CreateDeploymentStatus(ctx context.Context, owner string, repo string, deploymentID int64, state string, description string) (int64, error) {
+	status, _, err := c.GetRepositoriesService().CreateDeploymentStatus(ctx, owner, repo, deploymentID, &ghapi.DeploymentStatusRequest{
+		State:       &state,
+		Description: &description,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	c.logger.Info("Created deployment status",
+		"ID", status.ID,
+		"Owner", owner,
+		"Repository", repo,
+		"DeploymentID", deploymentID,
+		"State", status.State,
+	)
+	return *status.ID, nil
+}
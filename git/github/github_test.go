@@ -37,6 +37,20 @@ func (MockAppsService) CreateInstallationToken(
 	return &ghapi.InstallationToken{Token: &token}, nil, nil
 }
 
+// Get implements github.AppsService
+func (MockAppsService) Get(ctx context.Context, appSlug string) (*ghapi.App, *ghapi.Response, error) {
+	slug := "example-app"
+	return &ghapi.App{Slug: &slug}, nil, nil
+}
+
+type MockUsersService struct{}
+
+// Get implements github.UsersService
+func (MockUsersService) Get(ctx context.Context, user string) (*ghapi.User, *ghapi.Response, error) {
+	login := "example-user"
+	return &ghapi.User{Login: &login}, nil, nil
+}
+
 type MockChecksService struct {
 	ListCheckRunsForRefResult []*ghapi.CheckRun
 }
@@ -78,6 +92,28 @@ func (MockIssuesService) CreateComment(
 	return &ghapi.IssueComment{ID: &id}, nil, nil
 }
 
+// ListComments implements github.IssuesService
+func (MockIssuesService) ListComments(
+	ctx context.Context, owner string, repo string, number int, opts *ghapi.IssueListCommentsOptions,
+) ([]*ghapi.IssueComment, *ghapi.Response, error) {
+	newComment := func(id int64, login string) *ghapi.IssueComment {
+		body := "example-comment\n<!-- example-marker -->"
+		return &ghapi.IssueComment{ID: &id, Body: &body, User: &ghapi.User{Login: &login}}
+	}
+	// The comments are split into two pages, the first comment copies the marker of the others
+	if opts.Page == 0 {
+		return []*ghapi.IssueComment{newComment(50, "other-user")}, &ghapi.Response{NextPage: 2}, nil
+	}
+	return []*ghapi.IssueComment{newComment(60, "example-user"), newComment(70, "example-app[bot]")}, &ghapi.Response{}, nil
+}
+
+// EditComment implements github.IssuesService
+func (MockIssuesService) EditComment(
+	ctx context.Context, owner string, repo string, commentID int64, comment *ghapi.IssueComment,
+) (*ghapi.IssueComment, *ghapi.Response, error) {
+	return &ghapi.IssueComment{ID: &commentID}, nil, nil
+}
+
 type MockRepositoriesService struct{}
 
 // CreateStatus implements github.RepositoriesService
@@ -89,6 +125,41 @@ func (MockRepositoriesService) CreateStatus(
 	return &ghapi.RepoStatus{ID: &id, State: &state}, nil, nil
 }
 
+// GetPermissionLevel implements github.RepositoriesService
+func (MockRepositoriesService) GetPermissionLevel(
+	ctx context.Context, owner string, repo string, user string,
+) (*ghapi.RepositoryPermissionLevel, *ghapi.Response, error) {
+	var permission = "write"
+	return &ghapi.RepositoryPermissionLevel{Permission: &permission}, nil, nil
+}
+
+// ListDeployments implements github.RepositoriesService
+func (MockRepositoriesService) ListDeployments(
+	ctx context.Context, owner string, repo string, opts *ghapi.DeploymentsListOptions,
+) ([]*ghapi.Deployment, *ghapi.Response, error) {
+	var id int64 = 70
+	if opts.Environment != "example-environment" {
+		return []*ghapi.Deployment{}, nil, nil
+	}
+	return []*ghapi.Deployment{{ID: &id, Ref: &opts.Ref, Environment: &opts.Environment}}, nil, nil
+}
+
+// CreateDeployment implements github.RepositoriesService
+func (MockRepositoriesService) CreateDeployment(
+	ctx context.Context, owner string, repo string, request *ghapi.DeploymentRequest,
+) (*ghapi.Deployment, *ghapi.Response, error) {
+	var id int64 = 70
+	return &ghapi.Deployment{ID: &id, Ref: request.Ref, Environment: request.Environment}, nil, nil
+}
+
+// CreateDeploymentStatus implements github.RepositoriesService
+func (MockRepositoriesService) CreateDeploymentStatus(
+	ctx context.Context, owner string, repo string, deployment int64, request *ghapi.DeploymentStatusRequest,
+) (*ghapi.DeploymentStatus, *ghapi.Response, error) {
+	var id int64 = 80
+	return &ghapi.DeploymentStatus{ID: &id, State: request.State}, nil, nil
+}
+
 var _ = Describe("CheckRunAdapter", func() {
 	It("can compute status", func() {
 		adapter := &github.CheckRunAdapter{Conclusion: "success", StartTime: time.Time{}}
@@ -110,6 +181,7 @@ var _ = Describe("Client", func() {
 		mockChecksSvc MockChecksService
 		mockIssuesSvc MockIssuesService
 		mockReposSvc  MockRepositoriesService
+		mockUsersSvc  MockUsersService
 	)
 
 	var checkRunAdapter = &github.CheckRunAdapter{
@@ -131,12 +203,14 @@ var _ = Describe("Client", func() {
 		mockChecksSvc = MockChecksService{}
 		mockIssuesSvc = MockIssuesService{}
 		mockReposSvc = MockRepositoriesService{}
+		mockUsersSvc = MockUsersService{}
 		client = github.NewClient(
 			logr.Discard(),
 			github.WithAppsService(mockAppsSvc),
 			github.WithChecksService(mockChecksSvc),
 			github.WithIssuesService(mockIssuesSvc),
 			github.WithRepositoriesService(mockReposSvc),
+			github.WithUsersService(mockUsersSvc),
 		)
 	})
 
@@ -167,12 +241,76 @@ var _ = Describe("Client", func() {
 		Expect(id).To(Equal(int64(40)))
 	})
 
+	It("can list comments", func() {
+		comments, err := client.ListComments(context.TODO(), "", "", 1)
+		Expect(err).To(BeNil())
+		Expect(comments).To(HaveLen(3))
+	})
+
+	It("can find a comment with a marker", func() {
+		client.SetOAuthToken(context.TODO(), "example-user-token")
+		commentID, err := client.GetCommentIDWithMarker(context.TODO(), "", "", 1, "<!-- example-marker -->")
+		Expect(err).To(BeNil())
+		Expect(commentID).ToNot(BeNil())
+		Expect(*commentID).To(Equal(int64(60)))
+
+		commentID, err = client.GetCommentIDWithMarker(context.TODO(), "", "", 1, "<!-- unknown-marker -->")
+		Expect(err).To(BeNil())
+		Expect(commentID).To(BeNil())
+	})
+
+	It("only finds the comments with a marker posted by the App it's authenticated as", func() {
+		token, err := client.CreateAppInstallationToken(context.TODO(), 1, 1, []byte(samplePrivateKey))
+		Expect(err).To(BeNil())
+		client.SetOAuthToken(context.TODO(), token)
+
+		commentID, err := client.GetCommentIDWithMarker(context.TODO(), "", "", 1, "<!-- example-marker -->")
+		Expect(err).To(BeNil())
+		Expect(commentID).ToNot(BeNil())
+		Expect(*commentID).To(Equal(int64(70)))
+	})
+
+	It("can edit comments", func() {
+		id, err := client.EditComment(context.TODO(), "", "", 60, "example-comment")
+		Expect(err).To(BeNil())
+		Expect(id).To(Equal(int64(60)))
+	})
+
 	It("can create commit statuses", func() {
 		id, err := client.CreateCommitStatus(context.TODO(), "", "", "", "", "", "")
 		Expect(err).To(BeNil())
 		Expect(id).To(Equal(int64(50)))
 	})
 
+	It("can get the permission of a user", func() {
+		permission, err := client.GetUserPermission(context.TODO(), "", "", "example-user")
+		Expect(err).To(BeNil())
+		Expect(permission).To(Equal("write"))
+	})
+
+	It("can get the ID of a deployment", func() {
+		deploymentID, err := client.GetDeploymentID(context.TODO(), "", "", "example-SHA", "example-environment")
+		Expect(err).To(BeNil())
+		Expect(deploymentID).ToNot(BeNil())
+		Expect(*deploymentID).To(Equal(int64(70)))
+
+		deploymentID, err = client.GetDeploymentID(context.TODO(), "", "", "example-SHA", "other-environment")
+		Expect(err).To(BeNil())
+		Expect(deploymentID).To(BeNil())
+	})
+
+	It("can create deployments", func() {
+		id, err := client.CreateDeployment(context.TODO(), "", "", "example-SHA", "example-environment", "example-description")
+		Expect(err).To(BeNil())
+		Expect(id).To(Equal(int64(70)))
+	})
+
+	It("can create deployment statuses", func() {
+		id, err := client.CreateDeploymentStatus(context.TODO(), "", "", 70, "success", "example-description")
+		Expect(err).To(BeNil())
+		Expect(id).To(Equal(int64(80)))
+	})
+
 	It("can create check runs", func() {
 		checkRunID, err := client.CreateCheckRun(context.TODO(), checkRunAdapter)
 		Expect(err).To(BeNil())
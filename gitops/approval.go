@@ -0,0 +1,85 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitops
+
+import (
+	"context"
+
+	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	"github.com/redhat-appstudio/integration-service/helpers"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// ApprovalRequiredAnnotation can be set to "true" on a ReleasePlan or Environment to only promote Snapshots
+	// which were approved, on top of passing the IntegrationTestScenarios the target requires.
+	ApprovalRequiredAnnotation = "test.appstudio.openshift.io/approval-required"
+
+	// SnapshotApprovedByAnnotation contains the name of the user who approved the promotion of the Snapshot.
+	SnapshotApprovedByAnnotation = "test.appstudio.openshift.io/approved-by"
+)
+
+// IsApprovalRequiredForTarget returns true if the given ReleasePlan or Environment only accepts approved Snapshots.
+func IsApprovalRequiredForTarget(target client.Object) bool {
+	return helpers.HasAnnotationWithValue(target, ApprovalRequiredAnnotation, "true")
+}
+
+// IsSnapshotApproved returns true if the promotion of the Snapshot was approved.
+func IsSnapshotApproved(snapshot *applicationapiv1alpha1.Snapshot) bool {
+	return snapshot.GetAnnotations()[SnapshotApprovedByAnnotation] != ""
+}
+
+// ApproveSnapshot records on the Snapshot that its promotion was approved by the given user.
+// If the patch command fails, an error will be returned.
+func ApproveSnapshot(adapterClient client.Client, ctx context.Context, snapshot *applicationapiv1alpha1.Snapshot, user string) error {
+	if IsSnapshotApproved(snapshot) {
+		return nil
+	}
+
+	patch := client.MergeFrom(snapshot.DeepCopy())
+	if snapshot.Annotations == nil {
+		snapshot.Annotations = map[string]string{}
+	}
+	snapshot.Annotations[SnapshotApprovedByAnnotation] = user
+
+	return adapterClient.Patch(ctx, snapshot, patch)
+}
+
+// HasSnapshotBeenApproved returns a boolean that is only true if the second passed object is an approved Snapshot
+// and the first passed object wasn't approved.
+func HasSnapshotBeenApproved(objectOld, objectNew client.Object) bool {
+	oldSnapshot, ok := objectOld.(*applicationapiv1alpha1.Snapshot)
+	if !ok {
+		return false
+	}
+	newSnapshot, ok := objectNew.(*applicationapiv1alpha1.Snapshot)
+	if !ok {
+		return false
+	}
+
+	return !IsSnapshotApproved(oldSnapshot) && IsSnapshotApproved(newSnapshot)
+}
+
+// CheckSnapshotApprovalForTarget returns the given promotion decision for the ReleasePlan or Environment, refused
+// if the target requires approval and the Snapshot wasn't approved.
+func CheckSnapshotApprovalForTarget(decision PromotionDecision, snapshot *applicationapiv1alpha1.Snapshot, target client.Object) PromotionDecision {
+	if !IsApprovalRequiredForTarget(target) || IsSnapshotApproved(snapshot) {
+		return decision
+	}
+
+	return newPromotionDecision(false, append(decision.Reasons, "the Snapshot hasn't been approved"))
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitops_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	"github.com/redhat-appstudio/integration-service/gitops"
+	releasev1alpha1 "github.com/redhat-appstudio/release-service/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("Gitops functions for Snapshot approvals", func() {
+
+	var (
+		hasSnapshot *applicationapiv1alpha1.Snapshot
+		releasePlan *releasev1alpha1.ReleasePlan
+	)
+
+	BeforeEach(func() {
+		hasSnapshot = &applicationapiv1alpha1.Snapshot{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "snapshot-approval",
+				Namespace: "default",
+			},
+			Spec: applicationapiv1alpha1.SnapshotSpec{
+				Application: "application-sample",
+				Components: []applicationapiv1alpha1.SnapshotComponent{
+					{
+						Name:           "component-sample",
+						ContainerImage: "quay.io/redhat-appstudio/sample-image:latest",
+					},
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, hasSnapshot)).Should(Succeed())
+
+		releasePlan = &releasev1alpha1.ReleasePlan{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "releaseplan-approval",
+				Namespace: "default",
+				Annotations: map[string]string{
+					gitops.ApprovalRequiredAnnotation: "true",
+				},
+			},
+		}
+	})
+
+	AfterEach(func() {
+		err := k8sClient.Delete(ctx, hasSnapshot)
+		Expect(err == nil || errors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("only promotes approved Snapshots to targets requiring approval", func() {
+		Expect(gitops.IsApprovalRequiredForTarget(releasePlan)).To(BeTrue())
+		Expect(gitops.IsSnapshotApproved(hasSnapshot)).To(BeFalse())
+
+		decision := gitops.CheckSnapshotApprovalForTarget(gitops.PromotionDecision{Promoted: true}, hasSnapshot, releasePlan)
+		Expect(decision.Promoted).To(BeFalse())
+		Expect(decision.Reasons).To(ContainElement("the Snapshot hasn't been approved"))
+
+		oldSnapshot := hasSnapshot.DeepCopy()
+		Expect(gitops.ApproveSnapshot(k8sClient, ctx, hasSnapshot, "example-user")).To(Succeed())
+		Expect(hasSnapshot.Annotations[gitops.SnapshotApprovedByAnnotation]).To(Equal("example-user"))
+		Expect(gitops.HasSnapshotBeenApproved(oldSnapshot, hasSnapshot)).To(BeTrue())
+		Expect(gitops.HasSnapshotBeenApproved(hasSnapshot, hasSnapshot)).To(BeFalse())
+
+		decision = gitops.CheckSnapshotApprovalForTarget(gitops.PromotionDecision{Promoted: true}, hasSnapshot, releasePlan)
+		Expect(decision.Promoted).To(BeTrue())
+		Expect(decision.Reasons).To(BeEmpty())
+	})
+})
@@ -0,0 +1,418 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitops
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	"github.com/redhat-appstudio/integration-service/helpers"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	utilrand "k8s.io/apimachinery/pkg/util/rand"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// ApplicationBatchWindowAnnotation can be set on an Application to a duration, e.g. "5m", to opt in to batching.
+	// The successful builds of its Components finishing within the window after the first one are collected
+	// into a single batch Snapshot instead of one Snapshot per build.
+	ApplicationBatchWindowAnnotation = "test.appstudio.openshift.io/batch-window"
+
+	// PendingBatchConfigMapSuffix is appended to the name of an Application to name the ConfigMap storing the
+	// batch of builds the Application is collecting.
+	PendingBatchConfigMapSuffix = "-pending-batch"
+
+	// PendingBatchConfigMapKey is the key of the pending batch ConfigMap containing the JSON encoded batch of builds.
+	PendingBatchConfigMapKey = "batch"
+
+	// SnapshotBatchType is the type of Snapshot which was created for a batch of component builds.
+	SnapshotBatchType = "batch"
+
+	// SnapshotBatchComponentsAnnotation contains the comma separated names of the Components whose builds are in the batch Snapshot.
+	SnapshotBatchComponentsAnnotation = "test.appstudio.openshift.io/batch-components"
+
+	// SnapshotBatchParentAnnotation contains the name of the failed batch Snapshot the annotated batch Snapshot bisects.
+	SnapshotBatchParentAnnotation = "test.appstudio.openshift.io/batch-parent"
+
+	// SnapshotBatchRootAnnotation contains the name of the batch Snapshot originally created for the batch of builds
+	// the annotated batch Snapshot bisects.
+	SnapshotBatchRootAnnotation = "test.appstudio.openshift.io/batch-root"
+
+	// SnapshotBatchVerdictsAnnotation contains the JSON encoded verdicts for each Component in the batch, recorded
+	// on the batch Snapshot originally created for the batch.
+	SnapshotBatchVerdictsAnnotation = "test.appstudio.openshift.io/batch-verdicts"
+
+	// BatchVerdictPassed is the verdict for a Component whose build passed the tests in a batch Snapshot.
+	BatchVerdictPassed = "Passed"
+
+	// BatchVerdictFailed is the verdict for a Component whose build was found to fail the tests by bisecting the batch.
+	BatchVerdictFailed = "Failed"
+
+	// BuildPipelineRunBatchSupersededAnnotation contains the name of the build PipelineRun of the same Component which
+	// replaced the annotated build PipelineRun in the batch, so the annotated one isn't batched again.
+	BuildPipelineRunBatchSupersededAnnotation = "test.appstudio.openshift.io/batch-superseded-by"
+)
+
+// PendingBatch contains the builds collected for the next batch Snapshot of an Application.
+type PendingBatch struct {
+	// OpenedAt is the time the first build was added to the batch
+	OpenedAt metav1.Time `json:"openedAt"`
+	// SnapshotName is the name of the batch Snapshot created for the batch once it's closed
+	SnapshotName string `json:"snapshotName"`
+	// Closed is true once the batch window passed and the batch stopped collecting builds
+	Closed bool `json:"closed,omitempty"`
+	// Builds are the builds collected in the batch, at most one per Component
+	Builds []BatchedBuild `json:"builds"`
+}
+
+// HasBuild returns true if the build PipelineRun with the given name is in the batch.
+func (b *PendingBatch) HasBuild(pipelineRunName string) bool {
+	for _, batchedBuild := range b.Builds {
+		if batchedBuild.PipelineRun == pipelineRunName {
+			return true
+		}
+	}
+
+	return false
+}
+
+// GetSupersedingBuild returns the build in the batch of the same Component as the given build which completed after it,
+// or nil if there is none.
+func (b *PendingBatch) GetSupersedingBuild(build BatchedBuild) *BatchedBuild {
+	for i, batchedBuild := range b.Builds {
+		if batchedBuild.Component.Name == build.Component.Name && batchedBuild.PipelineRun != build.PipelineRun &&
+			build.CompletionTime.Before(&batchedBuild.CompletionTime) {
+			return &b.Builds[i]
+		}
+	}
+
+	return nil
+}
+
+// BatchedBuild contains the outcome of a successful build collected in a batch.
+type BatchedBuild struct {
+	// PipelineRun is the name of the build PipelineRun
+	PipelineRun string `json:"pipelineRun"`
+	// Component is the built Component with its new image and source
+	Component applicationapiv1alpha1.SnapshotComponent `json:"component"`
+	// CompletionTime is the time the build PipelineRun completed
+	CompletionTime metav1.Time `json:"completionTime,omitempty"`
+}
+
+// GetBatchWindow returns the batching window of the Application, or zero if it didn't opt in to batching.
+// If the window can't be parsed, an error will be returned.
+func GetBatchWindow(application *applicationapiv1alpha1.Application) (time.Duration, error) {
+	value, found := application.GetAnnotations()[ApplicationBatchWindowAnnotation]
+	if !found || value == "" {
+		return 0, nil
+	}
+
+	batchWindow, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse the %s annotation of the Application %s: %w", ApplicationBatchWindowAnnotation, application.Name, err)
+	}
+
+	return batchWindow, nil
+}
+
+// GetPendingBatchConfigMapName returns the name of the ConfigMap storing the batch of builds the Application is collecting.
+func GetPendingBatchConfigMapName(application *applicationapiv1alpha1.Application) string {
+	return application.Name + PendingBatchConfigMapSuffix
+}
+
+// GetPendingBatch returns the batch of builds the Application is collecting, or nil if there is none.
+// If the ConfigMap of the batch can't be loaded or parsed, an error will be returned.
+func GetPendingBatch(adapterClient client.Client, ctx context.Context, application *applicationapiv1alpha1.Application) (*PendingBatch, error) {
+	pendingBatch, _, err := getPendingBatch(adapterClient, ctx, application)
+
+	return pendingBatch, err
+}
+
+// getPendingBatch returns the batch of builds the Application is collecting together with the ConfigMap storing it,
+// or nil if there is none. If the ConfigMap can't be loaded or parsed, an error will be returned.
+func getPendingBatch(adapterClient client.Client, ctx context.Context, application *applicationapiv1alpha1.Application) (*PendingBatch, *corev1.ConfigMap, error) {
+	configMap := &corev1.ConfigMap{}
+	err := adapterClient.Get(ctx, types.NamespacedName{
+		Namespace: application.Namespace,
+		Name:      GetPendingBatchConfigMapName(application),
+	}, configMap)
+	if errors.IsNotFound(err) {
+		return nil, nil, nil
+	} else if err != nil {
+		return nil, nil, err
+	}
+
+	pendingBatch := &PendingBatch{}
+	err = json.Unmarshal([]byte(configMap.Data[PendingBatchConfigMapKey]), pendingBatch)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse the pending batch ConfigMap %s: %w", configMap.Name, err)
+	}
+
+	return pendingBatch, configMap, nil
+}
+
+// storePendingBatch stores the batch in its ConfigMap, creating the ConfigMap owned by the Application if it doesn't
+// exist yet. The existing ConfigMap is updated with its resource version so concurrent builds don't overwrite each other.
+func storePendingBatch(adapterClient client.Client, ctx context.Context, application *applicationapiv1alpha1.Application, pendingBatch *PendingBatch, configMap *corev1.ConfigMap) error {
+	value, err := json.Marshal(pendingBatch)
+	if err != nil {
+		return err
+	}
+
+	if configMap == nil {
+		configMap = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      GetPendingBatchConfigMapName(application),
+				Namespace: application.Namespace,
+			},
+			Data: map[string]string{PendingBatchConfigMapKey: string(value)},
+		}
+		err = ctrl.SetControllerReference(application, configMap, adapterClient.Scheme())
+		if err != nil {
+			return err
+		}
+
+		return adapterClient.Create(ctx, configMap)
+	}
+
+	configMap.Data = map[string]string{PendingBatchConfigMapKey: string(value)}
+
+	return adapterClient.Update(ctx, configMap)
+}
+
+// AddBuildToPendingBatch adds the build to the batch the Application is collecting, opening a new batch if there is none,
+// and replacing any build of the same Component which didn't complete after it. A closed batch, or a batch with a build
+// of the same Component which completed after the build, is returned unchanged; in the first case the build has to wait
+// for the batch to be removed to be added to the next one. Returns the updated batch and the replaced build, if any.
+func AddBuildToPendingBatch(adapterClient client.Client, ctx context.Context, application *applicationapiv1alpha1.Application, build BatchedBuild) (*PendingBatch, *BatchedBuild, error) {
+	pendingBatch, configMap, err := getPendingBatch(adapterClient, ctx, application)
+	if err != nil {
+		return nil, nil, err
+	}
+	if pendingBatch == nil {
+		pendingBatch = &PendingBatch{
+			OpenedAt:     metav1.Now(),
+			SnapshotName: application.Name + "-batch-" + utilrand.String(5),
+		}
+	}
+	if pendingBatch.Closed || pendingBatch.HasBuild(build.PipelineRun) || pendingBatch.GetSupersedingBuild(build) != nil {
+		return pendingBatch, nil, nil
+	}
+
+	var replacedBuild *BatchedBuild
+	builds := []BatchedBuild{}
+	for _, batchedBuild := range pendingBatch.Builds {
+		batchedBuild := batchedBuild // G601
+		if batchedBuild.Component.Name != build.Component.Name {
+			builds = append(builds, batchedBuild)
+		} else {
+			replacedBuild = &batchedBuild
+		}
+	}
+	pendingBatch.Builds = append(builds, build)
+
+	err = storePendingBatch(adapterClient, ctx, application, pendingBatch, configMap)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return pendingBatch, replacedBuild, nil
+}
+
+// ClosePendingBatch marks the batch the Application is collecting as closed, so no more builds are added to it.
+// The ConfigMap is updated with its resource version so the builds added concurrently aren't left out of the batch.
+// Returns the closed batch, or nil if there is none.
+func ClosePendingBatch(adapterClient client.Client, ctx context.Context, application *applicationapiv1alpha1.Application) (*PendingBatch, error) {
+	pendingBatch, configMap, err := getPendingBatch(adapterClient, ctx, application)
+	if err != nil || pendingBatch == nil || pendingBatch.Closed {
+		return pendingBatch, err
+	}
+
+	pendingBatch.Closed = true
+	err = storePendingBatch(adapterClient, ctx, application, pendingBatch, configMap)
+	if err != nil {
+		return nil, err
+	}
+
+	return pendingBatch, nil
+}
+
+// RemovePendingBatch removes the closed batch the Application was collecting once its batch Snapshot was created,
+// so the next build opens a new batch. Only the ConfigMap of the given batch is deleted.
+func RemovePendingBatch(adapterClient client.Client, ctx context.Context, application *applicationapiv1alpha1.Application, pendingBatch *PendingBatch) error {
+	storedBatch, configMap, err := getPendingBatch(adapterClient, ctx, application)
+	if err != nil || storedBatch == nil || storedBatch.SnapshotName != pendingBatch.SnapshotName {
+		return err
+	}
+
+	err = adapterClient.Delete(ctx, configMap, client.Preconditions{UID: &configMap.UID, ResourceVersion: &configMap.ResourceVersion})
+	if errors.IsNotFound(err) {
+		return nil
+	}
+
+	return err
+}
+
+// PrepareBatchSnapshot prepares a batch Snapshot for the Application containing the given batched Components
+// and the Global Candidate List for all the other Components. The Snapshot is given the name passed, so creating it
+// is idempotent. In case the Snapshot can't be created, an error will be returned.
+func PrepareBatchSnapshot(adapterClient client.Client, ctx context.Context, application *applicationapiv1alpha1.Application, applicationComponents *[]applicationapiv1alpha1.Component, batchedComponents []applicationapiv1alpha1.SnapshotComponent, name string) (*applicationapiv1alpha1.Snapshot, error) {
+	batched := map[string]applicationapiv1alpha1.SnapshotComponent{}
+	for _, batchedComponent := range batchedComponents {
+		batched[batchedComponent.Name] = batchedComponent
+	}
+
+	// The batched Components are prepared with their new images, so the ones which weren't in
+	// the Global Candidate List yet aren't left out of the Snapshot
+	components := []applicationapiv1alpha1.Component{}
+	for _, applicationComponent := range *applicationComponents {
+		applicationComponent := *applicationComponent.DeepCopy()
+		if batchedComponent, ok := batched[applicationComponent.Name]; ok {
+			applicationComponent.Spec.ContainerImage = batchedComponent.ContainerImage
+		}
+		components = append(components, applicationComponent)
+	}
+
+	snapshot, err := PrepareSnapshot(adapterClient, ctx, application, &components, nil, "", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	names := []string{}
+	for i, snapshotComponent := range snapshot.Spec.Components {
+		if batchedComponent, ok := batched[snapshotComponent.Name]; ok {
+			snapshot.Spec.Components[i] = batchedComponent
+			names = append(names, snapshotComponent.Name)
+		}
+	}
+	sort.Strings(names)
+
+	snapshot.GenerateName = ""
+	snapshot.Name = name
+	snapshot.Labels = map[string]string{
+		SnapshotTypeLabel: SnapshotBatchType,
+	}
+	snapshot.Annotations = map[string]string{
+		SnapshotBatchComponentsAnnotation: strings.Join(names, ","),
+	}
+
+	return snapshot, nil
+}
+
+// IsSnapshotBatch returns true if the Snapshot was created for a batch of builds.
+func IsSnapshotBatch(snapshot *applicationapiv1alpha1.Snapshot) bool {
+	return helpers.HasLabelWithValue(snapshot, SnapshotTypeLabel, SnapshotBatchType)
+}
+
+// GetSnapshotBatchComponents returns the names of the Components whose builds are in the batch Snapshot.
+func GetSnapshotBatchComponents(snapshot *applicationapiv1alpha1.Snapshot) []string {
+	value := snapshot.GetAnnotations()[SnapshotBatchComponentsAnnotation]
+	if value == "" {
+		return []string{}
+	}
+
+	return strings.Split(value, ",")
+}
+
+// GetSnapshotBatchRoot returns the name of the batch Snapshot originally created for the batch the Snapshot belongs to.
+func GetSnapshotBatchRoot(snapshot *applicationapiv1alpha1.Snapshot) string {
+	if root := snapshot.GetAnnotations()[SnapshotBatchRootAnnotation]; root != "" {
+		return root
+	}
+
+	return snapshot.Name
+}
+
+// GetBisectedBatchSnapshotName returns the name of the batch Snapshot bisecting the given half of the failed batch Snapshot.
+func GetBisectedBatchSnapshotName(failedSnapshot *applicationapiv1alpha1.Snapshot, half int) string {
+	return fmt.Sprintf("%s-%d", failedSnapshot.Name, half)
+}
+
+// BisectBatch splits the Components of a batch into two halves.
+func BisectBatch(batchComponents []string) ([]string, []string) {
+	middle := len(batchComponents) / 2
+	return batchComponents[:middle], batchComponents[middle:]
+}
+
+// NewBisectedBatchComponents returns the components of the failed batch Snapshot whose names are in the given half of the batch.
+func NewBisectedBatchComponents(failedSnapshot *applicationapiv1alpha1.Snapshot, half []string) []applicationapiv1alpha1.SnapshotComponent {
+	bisectedComponents := []applicationapiv1alpha1.SnapshotComponent{}
+	for _, snapshotComponent := range failedSnapshot.Spec.Components {
+		for _, name := range half {
+			if snapshotComponent.Name == name {
+				bisectedComponents = append(bisectedComponents, snapshotComponent)
+			}
+		}
+	}
+
+	return bisectedComponents
+}
+
+// GetBatchVerdicts returns the verdicts for the Components of the batch recorded on the batch Snapshot, keyed by
+// the Component name. If the annotation can't be parsed, an error will be returned.
+func GetBatchVerdicts(snapshot *applicationapiv1alpha1.Snapshot) (map[string]string, error) {
+	verdicts := map[string]string{}
+	value, found := snapshot.GetAnnotations()[SnapshotBatchVerdictsAnnotation]
+	if !found || value == "" {
+		return verdicts, nil
+	}
+
+	err := json.Unmarshal([]byte(value), &verdicts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the %s annotation of the Snapshot %s: %w", SnapshotBatchVerdictsAnnotation, snapshot.Name, err)
+	}
+
+	return verdicts, nil
+}
+
+// RecordBatchVerdicts records the verdict for the given Components on the batch Snapshot originally created for the batch
+// and patches it if any verdict changed.
+func RecordBatchVerdicts(adapterClient client.Client, ctx context.Context, rootSnapshot *applicationapiv1alpha1.Snapshot, components []string, verdict string) error {
+	verdicts, err := GetBatchVerdicts(rootSnapshot)
+	if err != nil {
+		return err
+	}
+
+	changed := false
+	for _, component := range components {
+		if verdicts[component] != verdict {
+			verdicts[component] = verdict
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+
+	value, err := json.Marshal(verdicts)
+	if err != nil {
+		return err
+	}
+	patch := client.MergeFrom(rootSnapshot.DeepCopy())
+	helpers.AddAnnotation(&rootSnapshot.ObjectMeta, SnapshotBatchVerdictsAnnotation, string(value))
+
+	return adapterClient.Patch(ctx, rootSnapshot, patch)
+}
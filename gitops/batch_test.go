@@ -0,0 +1,228 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitops_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	"github.com/redhat-appstudio/integration-service/gitops"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+var _ = Describe("Gitops functions for batch Snapshots", Ordered, func() {
+
+	const (
+		firstImage  = "quay.io/redhat-appstudio/sample-image@sha256:841328df1b9f8c4087adbdcfec6cc99ac8308805dea83f6d415d6fb8d40227c1"
+		secondImage = "quay.io/redhat-appstudio/sample-image@sha256:1111111111111111111111111111111111111111111111111111111111111111"
+		gclImage    = "quay.io/redhat-appstudio/sample-image@sha256:2222222222222222222222222222222222222222222222222222222222222222"
+	)
+
+	var (
+		hasApp      *applicationapiv1alpha1.Application
+		frontend    applicationapiv1alpha1.Component
+		backend     applicationapiv1alpha1.Component
+		database    applicationapiv1alpha1.Component
+		batchedComp []applicationapiv1alpha1.SnapshotComponent
+	)
+
+	BeforeAll(func() {
+		hasApp = &applicationapiv1alpha1.Application{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "application-batch",
+				Namespace: "default",
+				Annotations: map[string]string{
+					gitops.ApplicationBatchWindowAnnotation: "5m",
+				},
+			},
+			Spec: applicationapiv1alpha1.ApplicationSpec{
+				DisplayName: "application-batch",
+			},
+		}
+		Expect(k8sClient.Create(ctx, hasApp)).Should(Succeed())
+
+		newComponent := func(name, image string) applicationapiv1alpha1.Component {
+			return applicationapiv1alpha1.Component{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      name,
+					Namespace: "default",
+				},
+				Spec: applicationapiv1alpha1.ComponentSpec{
+					ComponentName:  name,
+					Application:    hasApp.Name,
+					ContainerImage: image,
+				},
+			}
+		}
+		frontend = newComponent("frontend", gclImage)
+		backend = newComponent("backend", gclImage)
+		database = newComponent("database", "")
+
+		batchedComp = []applicationapiv1alpha1.SnapshotComponent{
+			{Name: "frontend", ContainerImage: firstImage},
+			{Name: "database", ContainerImage: secondImage},
+		}
+	})
+
+	AfterAll(func() {
+		Expect(k8sClient.Delete(ctx, hasApp)).Should(Succeed())
+	})
+
+	It("returns the batch window of the Application", func() {
+		Expect(gitops.GetBatchWindow(hasApp)).To(Equal(5 * time.Minute))
+		Expect(gitops.GetBatchWindow(&applicationapiv1alpha1.Application{})).To(BeZero())
+
+		_, err := gitops.GetBatchWindow(&applicationapiv1alpha1.Application{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{gitops.ApplicationBatchWindowAnnotation: "soon"},
+			},
+		})
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("collects builds in the pending batch of the Application and closes it", func() {
+		pendingBatch, _, err := gitops.AddBuildToPendingBatch(k8sClient, ctx, hasApp, gitops.BatchedBuild{PipelineRun: "build-frontend-1", Component: batchedComp[0]})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pendingBatch.Builds).To(HaveLen(1))
+		openedAt := pendingBatch.OpenedAt
+
+		// Adding the same build again doesn't change the batch
+		pendingBatch, _, err = gitops.AddBuildToPendingBatch(k8sClient, ctx, hasApp, gitops.BatchedBuild{PipelineRun: "build-frontend-1", Component: batchedComp[0]})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pendingBatch.Builds).To(HaveLen(1))
+
+		// A newer build of the same component replaces the older one
+		newerFrontend := batchedComp[0]
+		newerFrontend.ContainerImage = secondImage
+		_, replacedBuild, err := gitops.AddBuildToPendingBatch(k8sClient, ctx, hasApp, gitops.BatchedBuild{PipelineRun: "build-frontend-2", Component: newerFrontend})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(replacedBuild.PipelineRun).To(Equal("build-frontend-1"))
+		_, _, err = gitops.AddBuildToPendingBatch(k8sClient, ctx, hasApp, gitops.BatchedBuild{PipelineRun: "build-database-1", Component: batchedComp[1]})
+		Expect(err).NotTo(HaveOccurred())
+
+		// The batch is stored in a ConfigMap owned by the Application, not on the Application itself
+		configMap := &corev1.ConfigMap{}
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: hasApp.Namespace, Name: gitops.GetPendingBatchConfigMapName(hasApp)}, configMap)).To(Succeed())
+		Expect(configMap.OwnerReferences).To(HaveLen(1))
+		Expect(configMap.OwnerReferences[0].Name).To(Equal(hasApp.Name))
+
+		pendingBatch, err = gitops.GetPendingBatch(k8sClient, ctx, hasApp)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pendingBatch.OpenedAt.Unix()).To(Equal(openedAt.Unix()))
+		Expect(pendingBatch.SnapshotName).To(HavePrefix(hasApp.Name + "-batch-"))
+		Expect(pendingBatch.Builds).To(Equal([]gitops.BatchedBuild{
+			{PipelineRun: "build-frontend-2", Component: newerFrontend},
+			{PipelineRun: "build-database-1", Component: batchedComp[1]},
+		}))
+
+		// Once closed, the batch doesn't collect builds anymore
+		closedBatch, err := gitops.ClosePendingBatch(k8sClient, ctx, hasApp)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(closedBatch.Closed).To(BeTrue())
+		pendingBatch, _, err = gitops.AddBuildToPendingBatch(k8sClient, ctx, hasApp, gitops.BatchedBuild{PipelineRun: "build-backend-1", Component: batchedComp[0]})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pendingBatch.HasBuild("build-backend-1")).To(BeFalse())
+
+		Expect(gitops.RemovePendingBatch(k8sClient, ctx, hasApp, closedBatch)).To(Succeed())
+		Expect(gitops.GetPendingBatch(k8sClient, ctx, hasApp)).To(BeNil())
+		Expect(gitops.RemovePendingBatch(k8sClient, ctx, hasApp, closedBatch)).To(Succeed())
+	})
+
+	It("never replaces a batched build with a build of the same Component which completed earlier", func() {
+		completionTime := metav1.Now()
+		newerBuild := gitops.BatchedBuild{PipelineRun: "build-frontend-2", Component: batchedComp[0], CompletionTime: completionTime}
+		olderBuild := gitops.BatchedBuild{PipelineRun: "build-frontend-1", Component: batchedComp[0],
+			CompletionTime: metav1.NewTime(completionTime.Add(-time.Minute))}
+
+		_, _, err := gitops.AddBuildToPendingBatch(k8sClient, ctx, hasApp, newerBuild)
+		Expect(err).NotTo(HaveOccurred())
+		pendingBatch, replacedBuild, err := gitops.AddBuildToPendingBatch(k8sClient, ctx, hasApp, olderBuild)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(replacedBuild).To(BeNil())
+		Expect(pendingBatch.HasBuild(olderBuild.PipelineRun)).To(BeFalse())
+		Expect(pendingBatch.GetSupersedingBuild(olderBuild).PipelineRun).To(Equal(newerBuild.PipelineRun))
+		Expect(pendingBatch.GetSupersedingBuild(newerBuild)).To(BeNil())
+
+		closedBatch, err := gitops.ClosePendingBatch(k8sClient, ctx, hasApp)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(gitops.RemovePendingBatch(k8sClient, ctx, hasApp, closedBatch)).To(Succeed())
+	})
+
+	It("prepares a batch Snapshot with the batched builds and the Global Candidate List", func() {
+		applicationComponents := []applicationapiv1alpha1.Component{frontend, backend, database}
+		snapshot, err := gitops.PrepareBatchSnapshot(k8sClient, ctx, hasApp, &applicationComponents, batchedComp, "snapshot-batch")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(snapshot.Name).To(Equal("snapshot-batch"))
+		Expect(snapshot.GenerateName).To(BeEmpty())
+		Expect(gitops.IsSnapshotBatch(snapshot)).To(BeTrue())
+		Expect(gitops.GetSnapshotBatchComponents(snapshot)).To(Equal([]string{"database", "frontend"}))
+		Expect(gitops.GetSnapshotBatchRoot(snapshot)).To(Equal(snapshot.Name))
+		Expect(snapshot.Spec.Components).To(ConsistOf(
+			batchedComp[0],
+			batchedComp[1],
+			HaveField("ContainerImage", gclImage),
+		))
+	})
+
+	It("bisects a batch and selects the components of each half", func() {
+		firstHalf, secondHalf := gitops.BisectBatch([]string{"backend", "database", "frontend"})
+		Expect(firstHalf).To(Equal([]string{"backend"}))
+		Expect(secondHalf).To(Equal([]string{"database", "frontend"}))
+
+		failedSnapshot := &applicationapiv1alpha1.Snapshot{
+			Spec: applicationapiv1alpha1.SnapshotSpec{
+				Components: append([]applicationapiv1alpha1.SnapshotComponent{{Name: "backend", ContainerImage: gclImage}}, batchedComp...),
+			},
+		}
+		Expect(gitops.NewBisectedBatchComponents(failedSnapshot, secondHalf)).To(ConsistOf(batchedComp[0], batchedComp[1]))
+
+		failedSnapshot.Name = "snapshot-batch"
+		Expect(gitops.GetBisectedBatchSnapshotName(failedSnapshot, 2)).To(Equal("snapshot-batch-2"))
+	})
+
+	It("records the verdicts of the components on the batch Snapshot", func() {
+		rootSnapshot := &applicationapiv1alpha1.Snapshot{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "snapshot-batch-root",
+				Namespace: "default",
+				Labels: map[string]string{
+					gitops.SnapshotTypeLabel: gitops.SnapshotBatchType,
+				},
+			},
+			Spec: applicationapiv1alpha1.SnapshotSpec{
+				Application: hasApp.Name,
+				Components:  batchedComp,
+			},
+		}
+		Expect(k8sClient.Create(ctx, rootSnapshot)).Should(Succeed())
+		defer func() {
+			Expect(k8sClient.Delete(ctx, rootSnapshot)).Should(Succeed())
+		}()
+
+		Expect(gitops.RecordBatchVerdicts(k8sClient, ctx, rootSnapshot, []string{"frontend"}, gitops.BatchVerdictPassed)).To(Succeed())
+		Expect(gitops.RecordBatchVerdicts(k8sClient, ctx, rootSnapshot, []string{"database"}, gitops.BatchVerdictFailed)).To(Succeed())
+		Expect(gitops.GetBatchVerdicts(rootSnapshot)).To(Equal(map[string]string{
+			"frontend": gitops.BatchVerdictPassed,
+			"database": gitops.BatchVerdictFailed,
+		}))
+	})
+})
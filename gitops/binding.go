@@ -17,7 +17,11 @@ limitations under the License.
 package gitops
 
 import (
+	"strings"
+	"time"
+
 	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	"github.com/redhat-appstudio/integration-service/helpers"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -31,6 +35,66 @@ const (
 	// BindingErrorOccurredStatusConditionType is the condition to check for failures within the
 	// SnapshotEnvironmentBindingConditions status
 	BindingErrorOccurredStatusConditionType string = "ErrorOccurred"
+
+	// BindingPreviousSnapshotAnnotation contains the name of the Snapshot the SnapshotEnvironmentBinding
+	// pointed to before the last promotion or rollback.
+	BindingPreviousSnapshotAnnotation = "test.appstudio.openshift.io/previous-snapshot"
+
+	// BindingLastKnownGoodSnapshotAnnotation contains the name of the last Snapshot which was successfully
+	// deployed by the SnapshotEnvironmentBinding.
+	BindingLastKnownGoodSnapshotAnnotation = "test.appstudio.openshift.io/last-known-good-snapshot"
+
+	// BindingLastVerifiedSnapshotAnnotation contains the name of the last Snapshot which passed the post-deployment
+	// verification and stayed healthy for the soak time of the environment of the SnapshotEnvironmentBinding.
+	BindingLastVerifiedSnapshotAnnotation = "test.appstudio.openshift.io/last-verified-snapshot"
+
+	// BindingPromotionTimeAnnotation contains the time at which a Snapshot was promoted to the SnapshotEnvironmentBinding.
+	// It is removed once the deployment of the Snapshot succeeded or the SnapshotEnvironmentBinding was rolled back.
+	BindingPromotionTimeAnnotation = "test.appstudio.openshift.io/promotion-time"
+
+	// BindingDeploymentTimeout is the time a promoted Snapshot has to be deployed by the SnapshotEnvironmentBinding
+	// before it's rolled back to the last known-good Snapshot.
+	BindingDeploymentTimeout = 30 * time.Minute
+
+	// BindingErrorTimeout is the time the ErrorOccurred condition of the SnapshotEnvironmentBinding has to stay true
+	// before the deployment is considered failed.
+	BindingErrorTimeout = 5 * time.Minute
+
+	// BindingReportedDeploymentStatusAnnotation contains the name of the Snapshot of the SnapshotEnvironmentBinding
+	// and the state of its deployment last reported to the git provider, e.g. "snapshot-sample/Succeeded".
+	BindingReportedDeploymentStatusAnnotation = "test.appstudio.openshift.io/reported-deployment-status"
+)
+
+// hasBindingSnapshotChanged returns a boolean that is only true if both passed objects are SnapshotEnvironmentBindings
+// pointing to different Snapshots.
+func hasBindingSnapshotChanged(objectOld, objectNew client.Object) bool {
+	oldBinding, ok := objectOld.(*applicationapiv1alpha1.SnapshotEnvironmentBinding)
+	if !ok {
+		return false
+	}
+	newBinding, ok := objectNew.(*applicationapiv1alpha1.SnapshotEnvironmentBinding)
+	if !ok {
+		return false
+	}
+
+	return oldBinding.Spec.Snapshot != newBinding.Spec.Snapshot
+}
+
+// DeploymentState is the state of the deployment of a Snapshot to the Environment of a SnapshotEnvironmentBinding.
+type DeploymentState string
+
+const (
+	// DeploymentInProgress is the state of a Snapshot which is being deployed.
+	DeploymentInProgress DeploymentState = "InProgress"
+
+	// DeploymentSucceeded is the state of a Snapshot which was deployed successfully.
+	DeploymentSucceeded DeploymentState = "Succeeded"
+
+	// DeploymentFailed is the state of a Snapshot which failed to deploy or wasn't deployed before the deadline.
+	DeploymentFailed DeploymentState = "Failed"
+
+	// DeploymentInactive is the state of a Snapshot which was deployed, but was replaced by another one since.
+	DeploymentInactive DeploymentState = "Inactive"
 )
 
 // NewSnapshotEnvironmentBinding creates a new SnapshotEnvironmentBinding using the provided info.
@@ -116,3 +180,122 @@ func hasDeploymentFailed(objectOld, objectNew client.Object) bool {
 	}
 	return (oldCondition == nil || oldCondition.Status != metav1.ConditionTrue) && newCondition.Status == metav1.ConditionTrue
 }
+
+// MarkBindingPromotion records on the SnapshotEnvironmentBinding that the given Snapshot is being promoted to it,
+// keeping the name of the Snapshot it pointed to before. If the replaced Snapshot was deployed successfully and
+// no other promotion was in progress, it becomes the last known-good Snapshot of the SnapshotEnvironmentBinding.
+// The SnapshotEnvironmentBinding isn't updated on the cluster.
+func MarkBindingPromotion(snapshotEnvironmentBinding *applicationapiv1alpha1.SnapshotEnvironmentBinding, snapshot *applicationapiv1alpha1.Snapshot) {
+	previousSnapshot := snapshotEnvironmentBinding.Spec.Snapshot
+	if previousSnapshot != "" && previousSnapshot != snapshot.Name {
+		if !IsBindingPromotionInProgress(snapshotEnvironmentBinding) && IsBindingDeployed(snapshotEnvironmentBinding) && !HaveBindingsFailed(snapshotEnvironmentBinding) {
+			helpers.AddAnnotation(&snapshotEnvironmentBinding.ObjectMeta, BindingLastKnownGoodSnapshotAnnotation, previousSnapshot)
+		}
+		helpers.AddAnnotation(&snapshotEnvironmentBinding.ObjectMeta, BindingPreviousSnapshotAnnotation, previousSnapshot)
+	}
+	helpers.AddAnnotation(&snapshotEnvironmentBinding.ObjectMeta, BindingPromotionTimeAnnotation, time.Now().UTC().Format(time.RFC3339))
+}
+
+// IsBindingPromotionInProgress returns true if a Snapshot was promoted to the SnapshotEnvironmentBinding
+// and its deployment hasn't succeeded or been rolled back yet.
+func IsBindingPromotionInProgress(snapshotEnvironmentBinding *applicationapiv1alpha1.SnapshotEnvironmentBinding) bool {
+	return helpers.HasAnnotation(snapshotEnvironmentBinding, BindingPromotionTimeAnnotation)
+}
+
+// GetBindingPromotionDeadline returns the time by which the Snapshot promoted to the SnapshotEnvironmentBinding
+// has to be deployed. If the promotion time can't be parsed, the zero time is returned so the deadline counts as passed.
+func GetBindingPromotionDeadline(snapshotEnvironmentBinding *applicationapiv1alpha1.SnapshotEnvironmentBinding) time.Time {
+	promotionTime, err := time.Parse(time.RFC3339, snapshotEnvironmentBinding.GetAnnotations()[BindingPromotionTimeAnnotation])
+	if err != nil {
+		return time.Time{}
+	}
+
+	return promotionTime.Add(BindingDeploymentTimeout)
+}
+
+// HasBindingDeploymentFailed returns true if the ErrorOccurred condition of the SnapshotEnvironmentBinding
+// has been true for longer than BindingErrorTimeout.
+func HasBindingDeploymentFailed(snapshotEnvironmentBinding *applicationapiv1alpha1.SnapshotEnvironmentBinding) bool {
+	bindingStatus := meta.FindStatusCondition(snapshotEnvironmentBinding.Status.BindingConditions, BindingErrorOccurredStatusConditionType)
+	if bindingStatus == nil || bindingStatus.Status != metav1.ConditionTrue {
+		return false
+	}
+
+	return time.Since(bindingStatus.LastTransitionTime.Time) >= BindingErrorTimeout
+}
+
+// GetBindingErrorDeadline returns the time at which the ErrorOccurred condition of the SnapshotEnvironmentBinding
+// will have been true for BindingErrorTimeout. If the condition isn't true, the zero time is returned.
+func GetBindingErrorDeadline(snapshotEnvironmentBinding *applicationapiv1alpha1.SnapshotEnvironmentBinding) time.Time {
+	bindingStatus := meta.FindStatusCondition(snapshotEnvironmentBinding.Status.BindingConditions, BindingErrorOccurredStatusConditionType)
+	if bindingStatus == nil || bindingStatus.Status != metav1.ConditionTrue {
+		return time.Time{}
+	}
+
+	return bindingStatus.LastTransitionTime.Add(BindingErrorTimeout)
+}
+
+// hasBindingPromotionStarted returns a boolean that is only true if the second passed object is a
+// SnapshotEnvironmentBinding with a promotion time which differs from the one of the first passed object.
+func hasBindingPromotionStarted(objectOld, objectNew client.Object) bool {
+	newPromotionTime, found := objectNew.GetAnnotations()[BindingPromotionTimeAnnotation]
+	return found && objectOld.GetAnnotations()[BindingPromotionTimeAnnotation] != newPromotionTime
+}
+
+// GetBindingDeploymentState returns the state of the deployment of the Snapshot of the SnapshotEnvironmentBinding,
+// based on its deployment conditions and the deadline of the Snapshot promotion in progress, if any.
+func GetBindingDeploymentState(snapshotEnvironmentBinding *applicationapiv1alpha1.SnapshotEnvironmentBinding) DeploymentState {
+	switch {
+	case IsBindingDeployed(snapshotEnvironmentBinding) && !HaveBindingsFailed(snapshotEnvironmentBinding):
+		return DeploymentSucceeded
+	case HasBindingDeploymentFailed(snapshotEnvironmentBinding):
+		return DeploymentFailed
+	case IsBindingPromotionInProgress(snapshotEnvironmentBinding) && !time.Now().Before(GetBindingPromotionDeadline(snapshotEnvironmentBinding)):
+		return DeploymentFailed
+	default:
+		return DeploymentInProgress
+	}
+}
+
+// GetBindingReportedDeploymentStatus returns the name of the Snapshot and the state of its deployment last reported
+// for the SnapshotEnvironmentBinding. Empty values are returned if nothing was reported yet.
+func GetBindingReportedDeploymentStatus(snapshotEnvironmentBinding *applicationapiv1alpha1.SnapshotEnvironmentBinding) (string, DeploymentState) {
+	reportedStatus, found := snapshotEnvironmentBinding.GetAnnotations()[BindingReportedDeploymentStatusAnnotation]
+	if !found {
+		return "", ""
+	}
+
+	snapshotName, state, _ := strings.Cut(reportedStatus, "/")
+	return snapshotName, DeploymentState(state)
+}
+
+// MarkBindingDeploymentStatusReported records on the SnapshotEnvironmentBinding that the deployment of the given
+// Snapshot was reported with the given state. The SnapshotEnvironmentBinding isn't updated on the cluster.
+func MarkBindingDeploymentStatusReported(snapshotEnvironmentBinding *applicationapiv1alpha1.SnapshotEnvironmentBinding, snapshotName string, state DeploymentState) {
+	helpers.AddAnnotation(&snapshotEnvironmentBinding.ObjectMeta, BindingReportedDeploymentStatusAnnotation, snapshotName+"/"+string(state))
+}
+
+// GetReplacedSnapshotComponents returns the Components of the old Snapshot whose git revision isn't deployed by the
+// new Snapshot anymore, i.e. the ones which were removed or built from a different revision.
+func GetReplacedSnapshotComponents(oldSnapshot, newSnapshot *applicationapiv1alpha1.Snapshot) []applicationapiv1alpha1.SnapshotComponent {
+	newSources := map[string]applicationapiv1alpha1.GitSource{}
+	for _, component := range newSnapshot.Spec.Components {
+		if component.Source.GitSource != nil {
+			newSources[component.Name] = *component.Source.GitSource
+		}
+	}
+
+	replacedComponents := []applicationapiv1alpha1.SnapshotComponent{}
+	for _, component := range oldSnapshot.Spec.Components {
+		oldSource := component.Source.GitSource
+		if oldSource == nil {
+			continue
+		}
+		if newSource, found := newSources[component.Name]; found && newSource.URL == oldSource.URL && newSource.Revision == oldSource.Revision {
+			continue
+		}
+		replacedComponents = append(replacedComponents, component)
+	}
+
+	return replacedComponents
+}
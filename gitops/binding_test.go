@@ -203,4 +203,128 @@ var _ = Describe("Gitops functions for managing Snapshots", Ordered, func() {
 		Expect(gitops.IsBindingDeployed(hasBinding)).NotTo(BeTrue())
 		Expect(gitops.HaveBindingsFailed(hasBinding)).To(BeTrue())
 	})
+
+	It("ensures the promotion of a Snapshot is recorded on the SnapshotEnvironmentBinding", func() {
+		binding := gitops.NewSnapshotEnvironmentBinding("sample", namespace, hasApp.Name, env.Name, hasSnapshot, []applicationapiv1alpha1.Component{*hasComp})
+		binding.Spec.Snapshot = "previous-snapshot"
+		binding.Status.ComponentDeploymentConditions = []metav1.Condition{
+			{
+				Type:   gitops.BindingDeploymentStatusConditionType,
+				Status: metav1.ConditionTrue,
+			},
+		}
+		Expect(gitops.IsBindingPromotionInProgress(binding)).To(BeFalse())
+
+		gitops.MarkBindingPromotion(binding, hasSnapshot)
+		Expect(gitops.IsBindingPromotionInProgress(binding)).To(BeTrue())
+		Expect(binding.Annotations).To(HaveKeyWithValue(gitops.BindingPreviousSnapshotAnnotation, "previous-snapshot"))
+		Expect(binding.Annotations).To(HaveKeyWithValue(gitops.BindingLastKnownGoodSnapshotAnnotation, "previous-snapshot"))
+		Expect(gitops.GetBindingPromotionDeadline(binding)).To(BeTemporally("~", time.Now().Add(gitops.BindingDeploymentTimeout), time.Minute))
+
+		// The Snapshot of a promotion still in progress doesn't become the last known-good Snapshot
+		binding.Spec.Snapshot = hasSnapshot.Name
+		gitops.MarkBindingPromotion(binding, &applicationapiv1alpha1.Snapshot{ObjectMeta: metav1.ObjectMeta{Name: "next-snapshot"}})
+		Expect(binding.Annotations).To(HaveKeyWithValue(gitops.BindingPreviousSnapshotAnnotation, hasSnapshot.Name))
+		Expect(binding.Annotations).To(HaveKeyWithValue(gitops.BindingLastKnownGoodSnapshotAnnotation, "previous-snapshot"))
+	})
+
+	It("ensures a failed deployment is only recognized after the error timeout", func() {
+		hasBinding.Status.BindingConditions = []metav1.Condition{
+			{
+				Reason:             "ErrorOccurred",
+				Status:             "True",
+				Type:               gitops.BindingErrorOccurredStatusConditionType,
+				LastTransitionTime: metav1.Time{Time: time.Now()},
+			},
+		}
+		Expect(gitops.HasBindingDeploymentFailed(hasBinding)).To(BeFalse())
+
+		hasBinding.Status.BindingConditions[0].LastTransitionTime = metav1.Time{Time: time.Now().Add(-gitops.BindingErrorTimeout)}
+		Expect(gitops.HasBindingDeploymentFailed(hasBinding)).To(BeTrue())
+	})
+
+	It("ensures the error deadline of a SnapshotEnvironmentBinding is computed from its ErrorOccurred condition", func() {
+		hasBinding.Status.BindingConditions = nil
+		Expect(gitops.GetBindingErrorDeadline(hasBinding).IsZero()).To(BeTrue())
+
+		errorTime := time.Now().Add(-time.Minute)
+		hasBinding.Status.BindingConditions = []metav1.Condition{
+			{
+				Reason:             "ErrorOccurred",
+				Status:             "True",
+				Type:               gitops.BindingErrorOccurredStatusConditionType,
+				LastTransitionTime: metav1.Time{Time: errorTime},
+			},
+		}
+		Expect(gitops.GetBindingErrorDeadline(hasBinding)).To(BeTemporally("~", errorTime.Add(gitops.BindingErrorTimeout), time.Second))
+
+		hasBinding.Status.BindingConditions[0].Status = "False"
+		Expect(gitops.GetBindingErrorDeadline(hasBinding).IsZero()).To(BeTrue())
+	})
+
+	It("ensures the deployment state of a SnapshotEnvironmentBinding is recognized", func() {
+		binding := gitops.NewSnapshotEnvironmentBinding("sample", namespace, hasApp.Name, env.Name, hasSnapshot, []applicationapiv1alpha1.Component{*hasComp})
+		gitops.MarkBindingPromotion(binding, hasSnapshot)
+		Expect(gitops.GetBindingDeploymentState(binding)).To(Equal(gitops.DeploymentInProgress))
+
+		binding.Annotations[gitops.BindingPromotionTimeAnnotation] = time.Now().Add(-gitops.BindingDeploymentTimeout).UTC().Format(time.RFC3339)
+		Expect(gitops.GetBindingDeploymentState(binding)).To(Equal(gitops.DeploymentFailed))
+
+		binding.Status.ComponentDeploymentConditions = []metav1.Condition{
+			{
+				Type:   gitops.BindingDeploymentStatusConditionType,
+				Status: metav1.ConditionTrue,
+			},
+		}
+		Expect(gitops.GetBindingDeploymentState(binding)).To(Equal(gitops.DeploymentSucceeded))
+	})
+
+	It("ensures the reported deployment status is recorded on the SnapshotEnvironmentBinding", func() {
+		binding := gitops.NewSnapshotEnvironmentBinding("sample", namespace, hasApp.Name, env.Name, hasSnapshot, []applicationapiv1alpha1.Component{*hasComp})
+		snapshotName, state := gitops.GetBindingReportedDeploymentStatus(binding)
+		Expect(snapshotName).To(BeEmpty())
+		Expect(state).To(BeEmpty())
+
+		gitops.MarkBindingDeploymentStatusReported(binding, hasSnapshot.Name, gitops.DeploymentSucceeded)
+		Expect(binding.Annotations).To(HaveKeyWithValue(gitops.BindingReportedDeploymentStatusAnnotation, hasSnapshot.Name+"/Succeeded"))
+		snapshotName, state = gitops.GetBindingReportedDeploymentStatus(binding)
+		Expect(snapshotName).To(Equal(hasSnapshot.Name))
+		Expect(state).To(Equal(gitops.DeploymentSucceeded))
+	})
+
+	It("ensures the Components replaced by a newer Snapshot are found", func() {
+		newComponent := func(name, revision string) applicationapiv1alpha1.SnapshotComponent {
+			return applicationapiv1alpha1.SnapshotComponent{
+				Name:           name,
+				ContainerImage: sampleImage,
+				Source: applicationapiv1alpha1.ComponentSource{
+					ComponentSourceUnion: applicationapiv1alpha1.ComponentSourceUnion{
+						GitSource: &applicationapiv1alpha1.GitSource{URL: SampleRepoLink, Revision: revision},
+					},
+				},
+			}
+		}
+		oldSnapshot := &applicationapiv1alpha1.Snapshot{
+			Spec: applicationapiv1alpha1.SnapshotSpec{
+				Components: []applicationapiv1alpha1.SnapshotComponent{
+					newComponent("component-unchanged", "a1b2c3d"),
+					newComponent("component-updated", "a1b2c3d"),
+					newComponent("component-removed", "a1b2c3d"),
+				},
+			},
+		}
+		newSnapshot := &applicationapiv1alpha1.Snapshot{
+			Spec: applicationapiv1alpha1.SnapshotSpec{
+				Components: []applicationapiv1alpha1.SnapshotComponent{
+					newComponent("component-unchanged", "a1b2c3d"),
+					newComponent("component-updated", "f6e5d4c"),
+				},
+			},
+		}
+
+		replacedComponents := gitops.GetReplacedSnapshotComponents(oldSnapshot, newSnapshot)
+		Expect(replacedComponents).To(HaveLen(2))
+		Expect(replacedComponents[0].Name).To(Equal("component-updated"))
+		Expect(replacedComponents[1].Name).To(Equal("component-removed"))
+	})
 })
@@ -0,0 +1,220 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitops
+
+import (
+	"encoding/json"
+	"fmt"
+
+	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	"github.com/redhat-appstudio/integration-service/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+const (
+	// ClusterScenarioLabel contains the name of the ClusterIntegrationTestScenario an IntegrationTestScenario
+	// was inherited from.
+	ClusterScenarioLabel = "test.appstudio.openshift.io/cluster-scenario"
+
+	// InheritedScenarioParamsAnnotation contains a JSON object mapping the names of the ClusterIntegrationTestScenarios
+	// inherited by the Application to the params overriding the ones of the ClusterIntegrationTestScenario.
+	InheritedScenarioParamsAnnotation = "test.appstudio.openshift.io/inherited-scenario-params"
+)
+
+// DoesClusterIntegrationTestScenarioApplyToApplication returns true if the given Application inherits the
+// ClusterIntegrationTestScenario, i.e. the labels of the Application and of its namespace match the selectors of the
+// ClusterIntegrationTestScenario. A ClusterIntegrationTestScenario without selectors isn't inherited by any Application.
+func DoesClusterIntegrationTestScenarioApplyToApplication(clusterScenario *v1beta1.ClusterIntegrationTestScenario,
+	application *applicationapiv1alpha1.Application, namespace *corev1.Namespace) (bool, error) {
+	if clusterScenario.Spec.ApplicationSelector == nil && clusterScenario.Spec.NamespaceSelector == nil {
+		return false, nil
+	}
+
+	if clusterScenario.Spec.ApplicationSelector != nil {
+		matches, err := matchesLabelSelector(clusterScenario.Spec.ApplicationSelector, application.GetLabels())
+		if err != nil || !matches {
+			return false, err
+		}
+	}
+
+	if clusterScenario.Spec.NamespaceSelector != nil {
+		matches, err := matchesLabelSelector(clusterScenario.Spec.NamespaceSelector, namespace.GetLabels())
+		if err != nil || !matches {
+			return false, err
+		}
+	}
+
+	return true, nil
+}
+
+// ValidateClusterIntegrationTestScenario returns an error if the selectors of the ClusterIntegrationTestScenario are invalid.
+func ValidateClusterIntegrationTestScenario(clusterScenario *v1beta1.ClusterIntegrationTestScenario) error {
+	for name, selector := range map[string]*metav1.LabelSelector{
+		"applicationSelector": clusterScenario.Spec.ApplicationSelector,
+		"namespaceSelector":   clusterScenario.Spec.NamespaceSelector,
+	} {
+		if selector == nil {
+			continue
+		}
+		if _, err := metav1.LabelSelectorAsSelector(selector); err != nil {
+			return fmt.Errorf("invalid %s: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// GetInheritedScenarioParams returns the params the Application sets for the ClusterIntegrationTestScenario with the given name.
+func GetInheritedScenarioParams(application *applicationapiv1alpha1.Application, clusterScenarioName string) ([]v1beta1.PipelineParameter, error) {
+	value, found := application.GetAnnotations()[InheritedScenarioParamsAnnotation]
+	if !found || value == "" {
+		return []v1beta1.PipelineParameter{}, nil
+	}
+
+	inheritedParams := map[string][]v1beta1.PipelineParameter{}
+	err := json.Unmarshal([]byte(value), &inheritedParams)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the params of the inherited IntegrationTestScenarios of the Application: %w", err)
+	}
+
+	return inheritedParams[clusterScenarioName], nil
+}
+
+// NewInheritedIntegrationTestScenario returns the IntegrationTestScenario the given Application inherits from the
+// ClusterIntegrationTestScenario. It lives in the namespace of the Application only in memory, carries the labels
+// of the ClusterIntegrationTestScenario and its params, overridden by the ones the Application sets for it.
+func NewInheritedIntegrationTestScenario(clusterScenario *v1beta1.ClusterIntegrationTestScenario,
+	application *applicationapiv1alpha1.Application) (*v1beta1.IntegrationTestScenario, error) {
+	applicationParams, err := GetInheritedScenarioParams(application, clusterScenario.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	params := []v1beta1.PipelineParameter{}
+	for _, param := range clusterScenario.Spec.Params {
+		overridden := false
+		for _, applicationParam := range applicationParams {
+			if applicationParam.Name == param.Name {
+				overridden = true
+				break
+			}
+		}
+		if !overridden {
+			params = append(params, *param.DeepCopy())
+		}
+	}
+	for _, applicationParam := range applicationParams {
+		params = append(params, *applicationParam.DeepCopy())
+	}
+
+	scenarioLabels := map[string]string{}
+	for key, value := range clusterScenario.GetLabels() {
+		scenarioLabels[key] = value
+	}
+	scenarioLabels[ClusterScenarioLabel] = clusterScenario.Name
+
+	scenario := &v1beta1.IntegrationTestScenario{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      clusterScenario.Name,
+			Namespace: application.Namespace,
+			Labels:    scenarioLabels,
+		},
+		Spec: v1beta1.IntegrationTestScenarioSpec{
+			Application: application.Name,
+			ResolverRef: *clusterScenario.Spec.ResolverRef.DeepCopy(),
+			Params:      params,
+			Contexts:    append([]v1beta1.TestContext{}, clusterScenario.Spec.Contexts...),
+			Matrix:      clusterScenario.Spec.Matrix.DeepCopy(),
+		},
+	}
+	for _, condition := range clusterScenario.Status.Conditions {
+		scenario.Status.Conditions = append(scenario.Status.Conditions, *condition.DeepCopy())
+	}
+
+	return scenario, nil
+}
+
+// GetInheritedIntegrationTestScenarios returns the IntegrationTestScenarios the given Application inherits from the
+// given ClusterIntegrationTestScenarios, i.e. the ones recording the Application in their status, leaving out the ones
+// shadowed by an IntegrationTestScenario of the same name in the existing IntegrationTestScenarios of the Application.
+func GetInheritedIntegrationTestScenarios(application *applicationapiv1alpha1.Application,
+	clusterScenarios *[]v1beta1.ClusterIntegrationTestScenario, existingScenarios *[]v1beta1.IntegrationTestScenario) ([]v1beta1.IntegrationTestScenario, error) {
+	inheritedScenarios := []v1beta1.IntegrationTestScenario{}
+	for _, clusterScenario := range *clusterScenarios {
+		clusterScenario := clusterScenario // G601
+		if IsIntegrationTestScenarioShadowed(clusterScenario.Name, existingScenarios) ||
+			!IsClusterIntegrationTestScenarioInheritedBy(&clusterScenario, application) {
+			continue
+		}
+		inheritedScenario, err := NewInheritedIntegrationTestScenario(&clusterScenario, application)
+		if err != nil {
+			return nil, err
+		}
+		inheritedScenarios = append(inheritedScenarios, *inheritedScenario)
+	}
+
+	return inheritedScenarios, nil
+}
+
+// GetInheritingApplicationName returns the name the Application is recorded under in the status of the
+// ClusterIntegrationTestScenarios it inherits, in the namespace/name format.
+func GetInheritingApplicationName(application *applicationapiv1alpha1.Application) string {
+	return application.Namespace + "/" + application.Name
+}
+
+// IsClusterIntegrationTestScenarioInheritedBy returns true if the ClusterIntegrationTestScenario records the
+// Application as inheriting it in its status.
+func IsClusterIntegrationTestScenarioInheritedBy(clusterScenario *v1beta1.ClusterIntegrationTestScenario,
+	application *applicationapiv1alpha1.Application) bool {
+	applicationName := GetInheritingApplicationName(application)
+	for _, inheritingApplication := range clusterScenario.Status.Applications {
+		if inheritingApplication == applicationName {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsIntegrationTestScenarioShadowed returns true if an IntegrationTestScenario with the given name is among the given ones.
+func IsIntegrationTestScenarioShadowed(name string, integrationTestScenarios *[]v1beta1.IntegrationTestScenario) bool {
+	for _, integrationTestScenario := range *integrationTestScenarios {
+		if integrationTestScenario.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsIntegrationTestScenarioInherited returns true if the IntegrationTestScenario was inherited from a ClusterIntegrationTestScenario.
+func IsIntegrationTestScenarioInherited(integrationTestScenario *v1beta1.IntegrationTestScenario) bool {
+	_, found := integrationTestScenario.GetLabels()[ClusterScenarioLabel]
+	return found
+}
+
+// matchesLabelSelector returns true if the given labels match the label selector.
+func matchesLabelSelector(labelSelector *metav1.LabelSelector, objectLabels map[string]string) (bool, error) {
+	selector, err := metav1.LabelSelectorAsSelector(labelSelector)
+	if err != nil {
+		return false, err
+	}
+
+	return selector.Matches(labels.Set(objectLabels)), nil
+}
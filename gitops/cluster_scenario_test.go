@@ -0,0 +1,167 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitops_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	"github.com/redhat-appstudio/integration-service/api/v1beta1"
+	"github.com/redhat-appstudio/integration-service/gitops"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("Gitops functions for ClusterIntegrationTestScenarios", func() {
+
+	var (
+		hasApp          *applicationapiv1alpha1.Application
+		namespace       *corev1.Namespace
+		clusterScenario *v1beta1.ClusterIntegrationTestScenario
+	)
+
+	BeforeEach(func() {
+		hasApp = &applicationapiv1alpha1.Application{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "application-sample",
+				Namespace: "tenant",
+				Labels: map[string]string{
+					"tier": "production",
+				},
+				Annotations: map[string]string{
+					gitops.InheritedScenarioParamsAnnotation: `{"security-scan":[{"name":"severity","value":"critical"}]}`,
+				},
+			},
+		}
+		namespace = &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "tenant",
+				Labels: map[string]string{
+					"test.appstudio.openshift.io/security-scans": "true",
+				},
+			},
+		}
+		clusterScenario = &v1beta1.ClusterIntegrationTestScenario{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "security-scan",
+				Labels: map[string]string{
+					"test.appstudio.openshift.io/optional": "true",
+				},
+			},
+			Spec: v1beta1.ClusterIntegrationTestScenarioSpec{
+				NamespaceSelector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{
+						"test.appstudio.openshift.io/security-scans": "true",
+					},
+				},
+				ResolverRef: v1beta1.ResolverRef{
+					Resolver: "bundles",
+					Params: []v1beta1.ResolverParameter{
+						{Name: "bundle", Value: "quay.io/redhat-appstudio/example-tekton-bundle:security-scan"},
+					},
+				},
+				Params: []v1beta1.PipelineParameter{
+					{Name: "severity", Value: "high"},
+					{Name: "report-format", Value: "sarif"},
+				},
+			},
+		}
+	})
+
+	It("determines which Applications inherit a ClusterIntegrationTestScenario", func() {
+		applies, err := gitops.DoesClusterIntegrationTestScenarioApplyToApplication(clusterScenario, hasApp, namespace)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(applies).To(BeTrue())
+
+		clusterScenario.Spec.ApplicationSelector = &metav1.LabelSelector{
+			MatchLabels: map[string]string{"tier": "staging"},
+		}
+		applies, err = gitops.DoesClusterIntegrationTestScenarioApplyToApplication(clusterScenario, hasApp, namespace)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(applies).To(BeFalse())
+
+		clusterScenario.Spec.ApplicationSelector = nil
+		clusterScenario.Spec.NamespaceSelector = nil
+		applies, err = gitops.DoesClusterIntegrationTestScenarioApplyToApplication(clusterScenario, hasApp, namespace)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(applies).To(BeFalse())
+	})
+
+	It("rejects ClusterIntegrationTestScenarios with invalid selectors", func() {
+		Expect(gitops.ValidateClusterIntegrationTestScenario(clusterScenario)).To(Succeed())
+
+		clusterScenario.Spec.ApplicationSelector = &metav1.LabelSelector{
+			MatchExpressions: []metav1.LabelSelectorRequirement{
+				{Key: "tier", Operator: "Unknown"},
+			},
+		}
+		Expect(gitops.ValidateClusterIntegrationTestScenario(clusterScenario)).NotTo(Succeed())
+	})
+
+	It("creates the inherited IntegrationTestScenario with the params set by the Application", func() {
+		scenario, err := gitops.NewInheritedIntegrationTestScenario(clusterScenario, hasApp)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(scenario.Name).To(Equal("security-scan"))
+		Expect(scenario.Namespace).To(Equal("tenant"))
+		Expect(scenario.Spec.Application).To(Equal("application-sample"))
+		Expect(scenario.Labels).To(HaveKeyWithValue(gitops.ClusterScenarioLabel, "security-scan"))
+		Expect(scenario.Labels).To(HaveKeyWithValue("test.appstudio.openshift.io/optional", "true"))
+		Expect(gitops.IsIntegrationTestScenarioInherited(scenario)).To(BeTrue())
+		Expect(scenario.Spec.Params).To(Equal([]v1beta1.PipelineParameter{
+			{Name: "report-format", Value: "sarif"},
+			{Name: "severity", Value: "critical"},
+		}))
+	})
+
+	It("leaves out the inherited IntegrationTestScenarios shadowed by the ones of the Application", func() {
+		clusterScenario.Status.Applications = []string{gitops.GetInheritingApplicationName(hasApp)}
+		clusterScenarios := []v1beta1.ClusterIntegrationTestScenario{*clusterScenario}
+		existingScenarios := []v1beta1.IntegrationTestScenario{}
+		inheritedScenarios, err := gitops.GetInheritedIntegrationTestScenarios(hasApp, &clusterScenarios, &existingScenarios)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(inheritedScenarios).To(HaveLen(1))
+
+		existingScenarios = []v1beta1.IntegrationTestScenario{
+			{ObjectMeta: metav1.ObjectMeta{Name: "security-scan", Namespace: "tenant"}},
+		}
+		inheritedScenarios, err = gitops.GetInheritedIntegrationTestScenarios(hasApp, &clusterScenarios, &existingScenarios)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(inheritedScenarios).To(BeEmpty())
+	})
+
+	It("only inherits the ClusterIntegrationTestScenarios recording the Application in their status", func() {
+		clusterScenario.Status.Applications = []string{"tenant/application-other"}
+		Expect(gitops.GetInheritingApplicationName(hasApp)).To(Equal("tenant/application-sample"))
+		Expect(gitops.IsClusterIntegrationTestScenarioInheritedBy(clusterScenario, hasApp)).To(BeFalse())
+
+		clusterScenarios := []v1beta1.ClusterIntegrationTestScenario{*clusterScenario}
+		existingScenarios := []v1beta1.IntegrationTestScenario{}
+		inheritedScenarios, err := gitops.GetInheritedIntegrationTestScenarios(hasApp, &clusterScenarios, &existingScenarios)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(inheritedScenarios).To(BeEmpty())
+
+		clusterScenario.Status.Applications = append(clusterScenario.Status.Applications, "tenant/application-sample")
+		Expect(gitops.IsClusterIntegrationTestScenarioInheritedBy(clusterScenario, hasApp)).To(BeTrue())
+	})
+
+	It("fails to parse malformed inherited params", func() {
+		hasApp.Annotations[gitops.InheritedScenarioParamsAnnotation] = "severity=critical"
+		_, err := gitops.NewInheritedIntegrationTestScenario(clusterScenario, hasApp)
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,112 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitops
+
+import (
+	"reflect"
+	"strings"
+
+	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+)
+
+const (
+	// ComponentDependsOnAnnotation contains the comma separated names of the Components of the same Application
+	// the annotated Component depends on.
+	ComponentDependsOnAnnotation = "test.appstudio.openshift.io/depends-on"
+)
+
+// GetComponentDependencies returns the names of the Components the given Component declares it depends on.
+func GetComponentDependencies(component *applicationapiv1alpha1.Component) []string {
+	dependencies := []string{}
+	for _, dependency := range strings.Split(component.GetAnnotations()[ComponentDependsOnAnnotation], ",") {
+		if dependency = strings.TrimSpace(dependency); dependency != "" {
+			dependencies = append(dependencies, dependency)
+		}
+	}
+
+	return dependencies
+}
+
+// HasComponentDependencyGraph returns true if any of the given Components declares dependencies on other Components.
+// Applications without any declared dependencies treat all of their Components as related to each other.
+func HasComponentDependencyGraph(components *[]applicationapiv1alpha1.Component) bool {
+	for i := range *components {
+		if len(GetComponentDependencies(&(*components)[i])) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// GetRelatedComponents returns the names of the Components related to the Component with the given name, that is
+// the Components it depends on and the Components depending on it, either directly or through other Components.
+func GetRelatedComponents(componentName string, components *[]applicationapiv1alpha1.Component) map[string]bool {
+	dependencies := map[string][]string{}
+	dependents := map[string][]string{}
+	for i := range *components {
+		component := &(*components)[i]
+		for _, dependency := range GetComponentDependencies(component) {
+			dependencies[component.Name] = append(dependencies[component.Name], dependency)
+			dependents[dependency] = append(dependents[dependency], component.Name)
+		}
+	}
+
+	related := map[string]bool{}
+	for _, edges := range []map[string][]string{dependencies, dependents} {
+		visited := map[string]bool{componentName: true}
+		queue := []string{componentName}
+		for len(queue) > 0 {
+			name := queue[0]
+			queue = queue[1:]
+			for _, next := range edges[name] {
+				if !visited[next] {
+					visited[next] = true
+					related[next] = true
+					queue = append(queue, next)
+				}
+			}
+		}
+	}
+
+	return related
+}
+
+// GetChangedSnapshotComponents returns the names of the Components whose version differs between the two Snapshots,
+// including the Components which are only part of one of them.
+func GetChangedSnapshotComponents(expectedSnapshot *applicationapiv1alpha1.Snapshot, foundSnapshot *applicationapiv1alpha1.Snapshot) []string {
+	changedComponents := []string{}
+	foundComponents := map[string]applicationapiv1alpha1.SnapshotComponent{}
+	for _, foundSnapshotComponent := range foundSnapshot.Spec.Components {
+		foundComponents[foundSnapshotComponent.Name] = foundSnapshotComponent
+	}
+
+	for _, expectedSnapshotComponent := range expectedSnapshot.Spec.Components {
+		foundSnapshotComponent, ok := foundComponents[expectedSnapshotComponent.Name]
+		if !ok || !reflect.DeepEqual(expectedSnapshotComponent, foundSnapshotComponent) {
+			changedComponents = append(changedComponents, expectedSnapshotComponent.Name)
+		}
+		delete(foundComponents, expectedSnapshotComponent.Name)
+	}
+	for _, foundSnapshotComponent := range foundSnapshot.Spec.Components {
+		if _, ok := foundComponents[foundSnapshotComponent.Name]; ok {
+			changedComponents = append(changedComponents, foundSnapshotComponent.Name)
+		}
+	}
+
+	return changedComponents
+}
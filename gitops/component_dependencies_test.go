@@ -0,0 +1,102 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitops_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	"github.com/redhat-appstudio/integration-service/gitops"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("Gitops functions for Component dependencies", func() {
+
+	newComponent := func(name string, dependsOn string) applicationapiv1alpha1.Component {
+		return applicationapiv1alpha1.Component{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Annotations: map[string]string{gitops.ComponentDependsOnAnnotation: dependsOn},
+			},
+		}
+	}
+
+	It("returns the dependencies declared by a Component", func() {
+		component := newComponent("frontend", "backend, database,")
+		Expect(gitops.GetComponentDependencies(&component)).To(Equal([]string{"backend", "database"}))
+
+		component = newComponent("database", "")
+		Expect(gitops.GetComponentDependencies(&component)).To(BeEmpty())
+	})
+
+	It("detects if the Components declare a dependency graph", func() {
+		components := []applicationapiv1alpha1.Component{newComponent("backend", ""), newComponent("frontend", "")}
+		Expect(gitops.HasComponentDependencyGraph(&components)).To(BeFalse())
+
+		components = append(components, newComponent("gateway", "frontend"))
+		Expect(gitops.HasComponentDependencyGraph(&components)).To(BeTrue())
+	})
+
+	It("returns the Components related to a Component through its dependencies and dependents", func() {
+		components := []applicationapiv1alpha1.Component{
+			newComponent("database", ""),
+			newComponent("backend", "database"),
+			newComponent("frontend", "backend"),
+			newComponent("worker", "database"),
+			newComponent("docs", ""),
+		}
+
+		Expect(gitops.GetRelatedComponents("backend", &components)).To(Equal(map[string]bool{
+			"database": true,
+			"frontend": true,
+		}))
+		Expect(gitops.GetRelatedComponents("frontend", &components)).To(Equal(map[string]bool{
+			"backend":  true,
+			"database": true,
+		}))
+		Expect(gitops.GetRelatedComponents("database", &components)).To(Equal(map[string]bool{
+			"backend":  true,
+			"frontend": true,
+			"worker":   true,
+		}))
+		Expect(gitops.GetRelatedComponents("docs", &components)).To(BeEmpty())
+	})
+
+	It("returns the Components whose versions differ between two Snapshots", func() {
+		expectedSnapshot := &applicationapiv1alpha1.Snapshot{
+			Spec: applicationapiv1alpha1.SnapshotSpec{
+				Components: []applicationapiv1alpha1.SnapshotComponent{
+					{Name: "backend", ContainerImage: "quay.io/sample/backend@sha256:2"},
+					{Name: "frontend", ContainerImage: "quay.io/sample/frontend@sha256:1"},
+					{Name: "worker", ContainerImage: "quay.io/sample/worker@sha256:1"},
+				},
+			},
+		}
+		foundSnapshot := &applicationapiv1alpha1.Snapshot{
+			Spec: applicationapiv1alpha1.SnapshotSpec{
+				Components: []applicationapiv1alpha1.SnapshotComponent{
+					{Name: "backend", ContainerImage: "quay.io/sample/backend@sha256:1"},
+					{Name: "frontend", ContainerImage: "quay.io/sample/frontend@sha256:1"},
+					{Name: "docs", ContainerImage: "quay.io/sample/docs@sha256:1"},
+				},
+			},
+		}
+
+		Expect(gitops.GetChangedSnapshotComponents(expectedSnapshot, foundSnapshot)).To(ConsistOf("backend", "worker", "docs"))
+	})
+})
@@ -17,13 +17,24 @@ limitations under the License.
 package gitops
 
 import (
+	"fmt"
 	"reflect"
+	"time"
 
 	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
 	"github.com/redhat-appstudio/integration-service/api/v1beta1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+const (
+	// SourceEnvironmentLabel contains the name of the Environment that an ephemeral Environment was copied from.
+	SourceEnvironmentLabel = "test.appstudio.openshift.io/source-environment"
+
+	// EnvironmentSoakTimeAnnotation contains the duration a Snapshot has to stay deployed and healthy in the
+	// annotated Environment before it can be promoted to the child Environments, e.g. "1h30m".
+	EnvironmentSoakTimeAnnotation = "test.appstudio.openshift.io/soak-time"
+)
+
 type CopiedEnvironment struct {
 	applicationapiv1alpha1.Environment
 }
@@ -35,11 +46,17 @@ func (r *CopiedEnvironment) AsEnvironment() *applicationapiv1alpha1.Environment
 // NewCopyOfExistingEnvironment gets the existing environment from current namespace and makes copy of it with the DeploymentTargetClaimName as Target
 // new name is generated consisting of existing environment name and integrationTestScenario name
 func NewCopyOfExistingEnvironment(existingEnvironment *applicationapiv1alpha1.Environment, namespace string, integrationTestScenario *v1beta1.IntegrationTestScenario, deploymentTargetClaimName string) *CopiedEnvironment {
+	return NewCopyOfExistingEnvironmentForTestEnvironment(existingEnvironment, namespace, integrationTestScenario, &integrationTestScenario.Spec.Environment, deploymentTargetClaimName)
+}
+
+// NewCopyOfExistingEnvironmentForTestEnvironment gets the existing environment from current namespace and makes copy of it with the DeploymentTargetClaimName
+// as Target, the EnvVars defined in the given testEnvironment of the integrationTestScenario are merged into the copied environment configuration
+func NewCopyOfExistingEnvironmentForTestEnvironment(existingEnvironment *applicationapiv1alpha1.Environment, namespace string, integrationTestScenario *v1beta1.IntegrationTestScenario, testEnvironment *v1beta1.TestEnvironment, deploymentTargetClaimName string) *CopiedEnvironment {
 	copiedEnvConfiguration := applicationapiv1alpha1.EnvironmentConfiguration{}
 	copiedEnvConfiguration = *existingEnvironment.Spec.Configuration.DeepCopy()
 
-	if !reflect.ValueOf(integrationTestScenario.Spec.Environment.Configuration).IsZero() {
-		copiedEnvConfigFromIntTestScenario := *integrationTestScenario.Spec.Environment.Configuration.DeepCopy()
+	if !reflect.ValueOf(testEnvironment.Configuration).IsZero() {
+		copiedEnvConfigFromIntTestScenario := *testEnvironment.Configuration.DeepCopy()
 		// if existing environment does not contain EnvVars, copy ones from IntegrationTestScenario
 		if existingEnvironment.Spec.Configuration.Env == nil {
 			copiedEnvConfiguration.Env = copiedEnvConfigFromIntTestScenario.Env
@@ -102,6 +119,56 @@ func (e *CopiedEnvironment) WithSnapshot(snapshot *applicationapiv1alpha1.Snapsh
 	return e
 }
 
+// WithSourceEnvironment adds the name of the environment the copy was created from as label to the copied environment.
+func (e *CopiedEnvironment) WithSourceEnvironment(existingEnvironment *applicationapiv1alpha1.Environment) *CopiedEnvironment {
+	if e.ObjectMeta.Labels == nil {
+		e.ObjectMeta.Labels = map[string]string{}
+	}
+	e.ObjectMeta.Labels[SourceEnvironmentLabel] = existingEnvironment.Name
+
+	return e
+}
+
+// GetIntegrationTestScenarioEnvironments returns all the environments requested by the given IntegrationTestScenario,
+// the Environment is returned first, followed by the entries of Environments in the order they were defined.
+func GetIntegrationTestScenarioEnvironments(integrationTestScenario *v1beta1.IntegrationTestScenario) []v1beta1.TestEnvironment {
+	testEnvironments := []v1beta1.TestEnvironment{}
+	if !reflect.ValueOf(integrationTestScenario.Spec.Environment).IsZero() {
+		testEnvironments = append(testEnvironments, integrationTestScenario.Spec.Environment)
+	}
+	for _, testEnvironment := range integrationTestScenario.Spec.Environments {
+		if !reflect.ValueOf(testEnvironment).IsZero() {
+			testEnvironments = append(testEnvironments, testEnvironment)
+		}
+	}
+
+	return testEnvironments
+}
+
+// HasIntegrationTestScenarioEnvironments returns true if the given IntegrationTestScenario requests at least one environment.
+func HasIntegrationTestScenarioEnvironments(integrationTestScenario *v1beta1.IntegrationTestScenario) bool {
+	return len(GetIntegrationTestScenarioEnvironments(integrationTestScenario)) > 0
+}
+
+// GetEnvironmentSoakTime returns the soak time of the given Environment. Environments without the soak time
+// annotation have no soak time. If the annotation can't be parsed, an error will be returned.
+func GetEnvironmentSoakTime(environment *applicationapiv1alpha1.Environment) (time.Duration, error) {
+	value, found := environment.GetAnnotations()[EnvironmentSoakTimeAnnotation]
+	if !found || value == "" {
+		return 0, nil
+	}
+
+	soakTime, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse the soak time of the Environment %s: %w", environment.Name, err)
+	}
+	if soakTime < 0 {
+		return 0, fmt.Errorf("the soak time of the Environment %s can't be negative", environment.Name)
+	}
+
+	return soakTime, nil
+}
+
 // NewDeploymentTargetClaim prepares a new DeploymentTargetClaim using the provided info
 func NewDeploymentTargetClaim(namespace string, deploymentTargetClassName string) *applicationapiv1alpha1.DeploymentTargetClaim {
 	dtc := &applicationapiv1alpha1.DeploymentTargetClaim{
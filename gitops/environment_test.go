@@ -382,6 +382,62 @@ var _ = Describe("Gitops functions for managing Snapshots", Ordered, func() {
 				To(Equal(hasIntTestSc.Name))
 		})
 
+		It("can append the source environment label to Environment and make sure that label value matches the existing environment name", func() {
+			copiedEnvWithEnvVarsDiff.WithSourceEnvironment(envWithEnvVars)
+			Expect(copiedEnvWithEnvVarsDiff.Labels[gitops.SourceEnvironmentLabel]).
+				To(Equal(envWithEnvVars.Name))
+		})
+
+		It("copies the envVars of the given test environment instead of the Environment of the ITS", func() {
+			testEnvironment := hasIntTestScDiff.Spec.Environment.DeepCopy()
+			copiedEnv := gitops.NewCopyOfExistingEnvironmentForTestEnvironment(envWithEnvVars, namespace, hasIntTestScWithNoEnv, testEnvironment, "dtcName")
+			Expect(copiedEnv.Spec.Configuration.Env).To(Equal(expectEnv.Spec.Configuration.Env))
+			Expect(copiedEnv.Spec.Configuration.Target.DeploymentTargetClaim.ClaimName).To(Equal("dtcName"))
+		})
+
+		It("returns all environments of an IntegrationTestScenario in order", func() {
+			multiEnvScenario := hasIntTestSc.DeepCopy()
+			multiEnvScenario.Spec.Environments = []v1beta1.TestEnvironment{
+				{
+					Name: "dr-envname",
+					Type: "POC",
+				},
+			}
+			testEnvironments := gitops.GetIntegrationTestScenarioEnvironments(multiEnvScenario)
+			Expect(testEnvironments).To(HaveLen(2))
+			Expect(testEnvironments[0].Name).To(Equal(hasIntTestSc.Spec.Environment.Name))
+			Expect(testEnvironments[1].Name).To(Equal("dr-envname"))
+			Expect(gitops.HasIntegrationTestScenarioEnvironments(multiEnvScenario)).To(BeTrue())
+
+			multiEnvScenario.Spec.Environment = v1beta1.TestEnvironment{}
+			testEnvironments = gitops.GetIntegrationTestScenarioEnvironments(multiEnvScenario)
+			Expect(testEnvironments).To(HaveLen(1))
+			Expect(testEnvironments[0].Name).To(Equal("dr-envname"))
+
+			multiEnvScenario.Spec.Environments = nil
+			Expect(gitops.HasIntegrationTestScenarioEnvironments(multiEnvScenario)).To(BeFalse())
+		})
+
+		It("reads the soak time of an Environment", func() {
+			soakEnv := expectEnv.DeepCopy()
+			soakTime, err := gitops.GetEnvironmentSoakTime(soakEnv)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(soakTime).To(BeZero())
+
+			soakEnv.Annotations = map[string]string{gitops.EnvironmentSoakTimeAnnotation: "1h30m"}
+			soakTime, err = gitops.GetEnvironmentSoakTime(soakEnv)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(soakTime).To(Equal(90 * time.Minute))
+
+			soakEnv.Annotations[gitops.EnvironmentSoakTimeAnnotation] = "a while"
+			_, err = gitops.GetEnvironmentSoakTime(soakEnv)
+			Expect(err).To(HaveOccurred())
+
+			soakEnv.Annotations[gitops.EnvironmentSoakTimeAnnotation] = "-1h"
+			_, err = gitops.GetEnvironmentSoakTime(soakEnv)
+			Expect(err).To(HaveOccurred())
+		})
+
 		It("Can return DeploymentTargetClaim object", func() {
 			dtc := gitops.NewDeploymentTargetClaim("default", deploymentTargetClass.Name)
 			Expect(dtc.Spec.DeploymentTargetClassName == applicationapiv1alpha1.DeploymentTargetClassName(deploymentTargetClass.Name)).To(BeTrue())
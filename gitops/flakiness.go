@@ -0,0 +1,266 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitops
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	"github.com/redhat-appstudio/integration-service/api/v1beta1"
+	"github.com/redhat-appstudio/integration-service/helpers"
+	"github.com/redhat-appstudio/integration-service/tekton"
+	tektonv1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// IntegrationTestScenarioQuarantinedLabel quarantines the IntegrationTestScenario manually when set to "true".
+	// When set to "false" the IntegrationTestScenario is never quarantined automatically.
+	IntegrationTestScenarioQuarantinedLabel = "test.appstudio.openshift.io/quarantined"
+
+	// QuarantineThresholdAnnotation contains the flakiness score, in percent, at which the IntegrationTestScenario
+	// is quarantined automatically. IntegrationTestScenarios without it are never quarantined automatically.
+	QuarantineThresholdAnnotation = "test.appstudio.openshift.io/quarantine-threshold"
+
+	// PipelineRunFlakinessRecordedAnnotation marks the integration PipelineRuns whose outcome was recorded in the
+	// history of their IntegrationTestScenario.
+	PipelineRunFlakinessRecordedAnnotation = "test.appstudio.openshift.io/flakiness-recorded"
+
+	// IntegrationTestScenarioQuarantined is the condition set on IntegrationTestScenarios which were quarantined
+	// automatically because they are flaky.
+	IntegrationTestScenarioQuarantined = "Quarantined"
+
+	// IntegrationTestScenarioQuarantinedReasonFlaky is the reason of the Quarantined condition of IntegrationTestScenarios
+	// whose flakiness reached their quarantine threshold.
+	IntegrationTestScenarioQuarantinedReasonFlaky = "Flaky"
+
+	// IntegrationTestScenarioQuarantinedReasonStable is the reason of the Quarantined condition of IntegrationTestScenarios
+	// whose flakiness is below their quarantine threshold.
+	IntegrationTestScenarioQuarantinedReasonStable = "Stable"
+
+	// MaxScenarioRunsHistory is the number of the most recent runs kept in the IntegrationTestScenario status.
+	MaxScenarioRunsHistory = 50
+
+	// MinRepeatedInputsForQuarantine is the number of inputs which have to be tested more than once before the
+	// flakiness of an IntegrationTestScenario is trusted to quarantine it automatically.
+	MinRepeatedInputsForQuarantine = 3
+)
+
+// GetSnapshotContentHash returns a hash of the images of the Components of the Snapshot, so Snapshots testing
+// identical content have the same hash regardless of their names and the order of their Components.
+func GetSnapshotContentHash(snapshot *applicationapiv1alpha1.Snapshot) string {
+	components := []string{}
+	for _, component := range snapshot.Spec.Components {
+		components = append(components, component.Name+"="+component.ContainerImage)
+	}
+	sort.Strings(components)
+
+	hash := sha256.Sum256([]byte(strings.Join(components, "\n")))
+	return hex.EncodeToString(hash[:])
+}
+
+// NewScenarioRun creates the record of a finished run of an IntegrationTestScenario from its integration PipelineRun,
+// the Snapshot it tested and the TEST_OUTPUT results of its tasks.
+func NewScenarioRun(snapshot *applicationapiv1alpha1.Snapshot, pipelineRun *tektonv1beta1.PipelineRun, passed bool,
+	taskRuns []*helpers.TaskRun) (v1beta1.ScenarioRun, error) {
+	input := GetSnapshotContentHash(snapshot)
+	if matrixParams := pipelineRun.GetAnnotations()[tekton.MatrixParamsAnnotation]; matrixParams != "" {
+		input = input + " (" + matrixParams + ")"
+	}
+
+	run := v1beta1.ScenarioRun{
+		Input:       input,
+		Snapshot:    snapshot.Name,
+		PipelineRun: pipelineRun.Name,
+		Passed:      passed,
+	}
+	for _, taskRun := range taskRuns {
+		result, err := taskRun.GetTestResult()
+		if err != nil {
+			return v1beta1.ScenarioRun{}, err
+		}
+		if result == nil {
+			continue
+		}
+		switch result.Result {
+		case helpers.AppStudioTestOutputSuccess, helpers.AppStudioTestOutputWarning:
+			run.PassedTasks = append(run.PassedTasks, taskRun.GetPipelineTaskName())
+		case helpers.AppStudioTestOutputFailure, helpers.AppStudioTestOutputError:
+			run.FailedTasks = append(run.FailedTasks, taskRun.GetPipelineTaskName())
+		}
+	}
+
+	return run, nil
+}
+
+// HasScenarioRunBeenRecorded returns true if the IntegrationTestScenario history contains the run of the given PipelineRun.
+func HasScenarioRunBeenRecorded(integrationTestScenario *v1beta1.IntegrationTestScenario, pipelineRunName string) bool {
+	if integrationTestScenario.Status.Flakiness == nil {
+		return false
+	}
+	for _, run := range integrationTestScenario.Status.Flakiness.Runs {
+		if run.PipelineRun == pipelineRunName {
+			return true
+		}
+	}
+
+	return false
+}
+
+// RecordScenarioRun adds the run to the history of the IntegrationTestScenario, dropping the oldest runs beyond
+// MaxScenarioRunsHistory, and computes the flakiness of the IntegrationTestScenario and of its tasks again.
+func RecordScenarioRun(integrationTestScenario *v1beta1.IntegrationTestScenario, run v1beta1.ScenarioRun) {
+	if integrationTestScenario.Status.Flakiness == nil {
+		integrationTestScenario.Status.Flakiness = &v1beta1.ScenarioFlakiness{}
+	}
+	flakiness := integrationTestScenario.Status.Flakiness
+	flakiness.Runs = append(flakiness.Runs, run)
+	if len(flakiness.Runs) > MaxScenarioRunsHistory {
+		flakiness.Runs = flakiness.Runs[len(flakiness.Runs)-MaxScenarioRunsHistory:]
+	}
+
+	outcomes := map[string][]bool{}
+	taskOutcomes := map[string]map[string][]bool{}
+	for _, run := range flakiness.Runs {
+		outcomes[run.Input] = append(outcomes[run.Input], run.Passed)
+		for _, tasks := range []struct {
+			names  []string
+			passed bool
+		}{{run.PassedTasks, true}, {run.FailedTasks, false}} {
+			for _, task := range tasks.names {
+				if taskOutcomes[task] == nil {
+					taskOutcomes[task] = map[string][]bool{}
+				}
+				taskOutcomes[task][run.Input] = append(taskOutcomes[task][run.Input], tasks.passed)
+			}
+		}
+	}
+
+	flakiness.Score, flakiness.RepeatedInputs = computeFlakinessScore(outcomes)
+	flakiness.Tasks = []v1beta1.TaskFlakiness{}
+	for task, outcomes := range taskOutcomes {
+		score, repeatedInputs := computeFlakinessScore(outcomes)
+		if repeatedInputs == 0 {
+			continue
+		}
+		flakiness.Tasks = append(flakiness.Tasks, v1beta1.TaskFlakiness{Name: task, Score: score, RepeatedInputs: repeatedInputs})
+	}
+	sort.Slice(flakiness.Tasks, func(i, j int) bool {
+		return flakiness.Tasks[i].Name < flakiness.Tasks[j].Name
+	})
+}
+
+// computeFlakinessScore returns the percentage of the inputs tested more than once whose outcomes differed,
+// and the number of inputs tested more than once.
+func computeFlakinessScore(outcomes map[string][]bool) (int, int) {
+	repeatedInputs, flakyInputs := 0, 0
+	for _, inputOutcomes := range outcomes {
+		if len(inputOutcomes) < 2 {
+			continue
+		}
+		repeatedInputs++
+		for _, outcome := range inputOutcomes[1:] {
+			if outcome != inputOutcomes[0] {
+				flakyInputs++
+				break
+			}
+		}
+	}
+	if repeatedInputs == 0 {
+		return 0, 0
+	}
+
+	return flakyInputs * 100 / repeatedInputs, repeatedInputs
+}
+
+// GetIntegrationTestScenarioFlakinessScore returns the flakiness score of the IntegrationTestScenario, 0 if no
+// input was tested more than once yet.
+func GetIntegrationTestScenarioFlakinessScore(integrationTestScenario *v1beta1.IntegrationTestScenario) int {
+	if integrationTestScenario.Status.Flakiness == nil {
+		return 0
+	}
+
+	return integrationTestScenario.Status.Flakiness.Score
+}
+
+// GetQuarantineThreshold returns the flakiness score at which the IntegrationTestScenario is quarantined automatically
+// and whether it is quarantined automatically at all. An error is returned if the threshold is not a percentage.
+func GetQuarantineThreshold(integrationTestScenario *v1beta1.IntegrationTestScenario) (int, bool, error) {
+	value, found := integrationTestScenario.GetAnnotations()[QuarantineThresholdAnnotation]
+	if !found || helpers.HasLabelWithValue(integrationTestScenario, IntegrationTestScenarioQuarantinedLabel, "false") {
+		return 0, false, nil
+	}
+
+	threshold, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || threshold < 1 || threshold > 100 {
+		return 0, false, fmt.Errorf("invalid quarantine threshold %q, it has to be a percentage between 1 and 100", value)
+	}
+
+	return threshold, true, nil
+}
+
+// UpdateIntegrationTestScenarioQuarantine sets the Quarantined condition of the IntegrationTestScenario according to
+// its flakiness and quarantine threshold. The condition is removed from IntegrationTestScenarios which aren't
+// quarantined automatically. Returns true if the condition changed.
+func UpdateIntegrationTestScenarioQuarantine(integrationTestScenario *v1beta1.IntegrationTestScenario) (bool, error) {
+	threshold, automatic, err := GetQuarantineThreshold(integrationTestScenario)
+	if err != nil || !automatic {
+		if meta.FindStatusCondition(integrationTestScenario.Status.Conditions, IntegrationTestScenarioQuarantined) == nil {
+			return false, err
+		}
+		meta.RemoveStatusCondition(&integrationTestScenario.Status.Conditions, IntegrationTestScenarioQuarantined)
+		return true, err
+	}
+
+	flakiness := integrationTestScenario.Status.Flakiness
+	condition := metav1.Condition{
+		Type:    IntegrationTestScenarioQuarantined,
+		Status:  metav1.ConditionFalse,
+		Reason:  IntegrationTestScenarioQuarantinedReasonStable,
+		Message: fmt.Sprintf("The flakiness score is below the quarantine threshold of %d%%", threshold),
+	}
+	if flakiness != nil && flakiness.RepeatedInputs >= MinRepeatedInputsForQuarantine && flakiness.Score >= threshold {
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = IntegrationTestScenarioQuarantinedReasonFlaky
+		condition.Message = fmt.Sprintf("The flakiness score of %d%% reached the quarantine threshold of %d%%", flakiness.Score, threshold)
+	}
+
+	existingCondition := meta.FindStatusCondition(integrationTestScenario.Status.Conditions, IntegrationTestScenarioQuarantined)
+	if existingCondition != nil && existingCondition.Status == condition.Status && existingCondition.Message == condition.Message {
+		return false, nil
+	}
+	meta.SetStatusCondition(&integrationTestScenario.Status.Conditions, condition)
+
+	return true, nil
+}
+
+// IsIntegrationTestScenarioQuarantined returns true if the IntegrationTestScenario was quarantined, either manually
+// through its label or automatically because of its flakiness. Quarantined IntegrationTestScenarios are still run,
+// but are treated like optional ones and can't block the promotion of Snapshots.
+func IsIntegrationTestScenarioQuarantined(integrationTestScenario *v1beta1.IntegrationTestScenario) bool {
+	if value, found := integrationTestScenario.GetLabels()[IntegrationTestScenarioQuarantinedLabel]; found {
+		return value == "true"
+	}
+
+	return meta.IsStatusConditionTrue(integrationTestScenario.Status.Conditions, IntegrationTestScenarioQuarantined)
+}
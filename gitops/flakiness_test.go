@@ -0,0 +1,189 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitops_test
+
+import (
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	"github.com/redhat-appstudio/integration-service/api/v1beta1"
+	"github.com/redhat-appstudio/integration-service/gitops"
+	tektonv1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("Gitops functions for tracking the flakiness of IntegrationTestScenarios", func() {
+
+	var (
+		integrationTestScenario *v1beta1.IntegrationTestScenario
+		snapshot                *applicationapiv1alpha1.Snapshot
+		runCount                int
+	)
+
+	newRun := func(input string, passed bool, passedTasks, failedTasks []string) v1beta1.ScenarioRun {
+		runCount++
+		return v1beta1.ScenarioRun{
+			Input:       input,
+			Snapshot:    "snapshot-" + input,
+			PipelineRun: fmt.Sprintf("pipelinerun-%d", runCount),
+			Passed:      passed,
+			PassedTasks: passedTasks,
+			FailedTasks: failedTasks,
+		}
+	}
+
+	BeforeEach(func() {
+		runCount = 0
+		integrationTestScenario = &v1beta1.IntegrationTestScenario{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "example-pass",
+				Namespace: "default",
+			},
+			Spec: v1beta1.IntegrationTestScenarioSpec{
+				Application: "application-sample",
+			},
+		}
+		snapshot = &applicationapiv1alpha1.Snapshot{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "snapshot-sample",
+				Namespace: "default",
+			},
+			Spec: applicationapiv1alpha1.SnapshotSpec{
+				Application: "application-sample",
+				Components: []applicationapiv1alpha1.SnapshotComponent{
+					{Name: "api", ContainerImage: "quay.io/org/api@sha256:1111"},
+					{Name: "ui", ContainerImage: "quay.io/org/ui@sha256:2222"},
+				},
+			},
+		}
+	})
+
+	It("ensures Snapshots with the same content have the same hash regardless of their names and order", func() {
+		otherSnapshot := snapshot.DeepCopy()
+		otherSnapshot.Name = "snapshot-rerun"
+		otherSnapshot.Spec.Components = []applicationapiv1alpha1.SnapshotComponent{
+			snapshot.Spec.Components[1],
+			snapshot.Spec.Components[0],
+		}
+		Expect(gitops.GetSnapshotContentHash(otherSnapshot)).To(Equal(gitops.GetSnapshotContentHash(snapshot)))
+
+		otherSnapshot.Spec.Components[0].ContainerImage = "quay.io/org/ui@sha256:3333"
+		Expect(gitops.GetSnapshotContentHash(otherSnapshot)).NotTo(Equal(gitops.GetSnapshotContentHash(snapshot)))
+	})
+
+	It("ensures the matrix params of the PipelineRun are part of the tested input", func() {
+		pipelineRun := &tektonv1beta1.PipelineRun{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "pipelinerun-sample",
+				Namespace: "default",
+				Annotations: map[string]string{
+					"test.appstudio.openshift.io/matrix-params": "os=rhel9",
+				},
+			},
+		}
+		run, err := gitops.NewScenarioRun(snapshot, pipelineRun, true, nil)
+		Expect(err).To(BeNil())
+		Expect(run.Input).To(Equal(gitops.GetSnapshotContentHash(snapshot) + " (os=rhel9)"))
+		Expect(run.Snapshot).To(Equal(snapshot.Name))
+		Expect(run.PipelineRun).To(Equal(pipelineRun.Name))
+		Expect(run.Passed).To(BeTrue())
+	})
+
+	It("ensures the flakiness score only counts inputs tested more than once", func() {
+		gitops.RecordScenarioRun(integrationTestScenario, newRun("a", true, nil, nil))
+		gitops.RecordScenarioRun(integrationTestScenario, newRun("b", false, nil, nil))
+		Expect(gitops.GetIntegrationTestScenarioFlakinessScore(integrationTestScenario)).To(Equal(0))
+		Expect(integrationTestScenario.Status.Flakiness.RepeatedInputs).To(Equal(0))
+
+		gitops.RecordScenarioRun(integrationTestScenario, newRun("a", false, nil, nil))
+		gitops.RecordScenarioRun(integrationTestScenario, newRun("b", false, nil, nil))
+		Expect(gitops.GetIntegrationTestScenarioFlakinessScore(integrationTestScenario)).To(Equal(50))
+		Expect(integrationTestScenario.Status.Flakiness.RepeatedInputs).To(Equal(2))
+		Expect(gitops.HasScenarioRunBeenRecorded(integrationTestScenario, "pipelinerun-1")).To(BeTrue())
+		Expect(gitops.HasScenarioRunBeenRecorded(integrationTestScenario, "pipelinerun-5")).To(BeFalse())
+	})
+
+	It("ensures the flakiness of the tasks is computed from their test results", func() {
+		gitops.RecordScenarioRun(integrationTestScenario, newRun("a", true, []string{"lint", "e2e"}, nil))
+		gitops.RecordScenarioRun(integrationTestScenario, newRun("a", false, []string{"lint"}, []string{"e2e"}))
+		Expect(integrationTestScenario.Status.Flakiness.Tasks).To(Equal([]v1beta1.TaskFlakiness{
+			{Name: "e2e", Score: 100, RepeatedInputs: 1},
+			{Name: "lint", Score: 0, RepeatedInputs: 1},
+		}))
+	})
+
+	It("ensures only the most recent runs are kept", func() {
+		for i := 0; i < gitops.MaxScenarioRunsHistory+5; i++ {
+			gitops.RecordScenarioRun(integrationTestScenario, newRun("a", true, nil, nil))
+		}
+		Expect(integrationTestScenario.Status.Flakiness.Runs).To(HaveLen(gitops.MaxScenarioRunsHistory))
+		Expect(integrationTestScenario.Status.Flakiness.Runs[0].PipelineRun).To(Equal("pipelinerun-6"))
+	})
+
+	It("ensures IntegrationTestScenarios are quarantined automatically once their flakiness reaches the threshold", func() {
+		integrationTestScenario.Annotations = map[string]string{gitops.QuarantineThresholdAnnotation: "50"}
+		for _, input := range []string{"a", "b", "c"} {
+			gitops.RecordScenarioRun(integrationTestScenario, newRun(input, true, nil, nil))
+			gitops.RecordScenarioRun(integrationTestScenario, newRun(input, input == "c", nil, nil))
+		}
+		changed, err := gitops.UpdateIntegrationTestScenarioQuarantine(integrationTestScenario)
+		Expect(err).To(BeNil())
+		Expect(changed).To(BeTrue())
+		Expect(gitops.IsIntegrationTestScenarioQuarantined(integrationTestScenario)).To(BeTrue())
+		condition := meta.FindStatusCondition(integrationTestScenario.Status.Conditions, gitops.IntegrationTestScenarioQuarantined)
+		Expect(condition.Reason).To(Equal(gitops.IntegrationTestScenarioQuarantinedReasonFlaky))
+
+		changed, err = gitops.UpdateIntegrationTestScenarioQuarantine(integrationTestScenario)
+		Expect(err).To(BeNil())
+		Expect(changed).To(BeFalse())
+
+		// Opting out of the automatic quarantine removes the condition
+		integrationTestScenario.Labels = map[string]string{gitops.IntegrationTestScenarioQuarantinedLabel: "false"}
+		changed, err = gitops.UpdateIntegrationTestScenarioQuarantine(integrationTestScenario)
+		Expect(err).To(BeNil())
+		Expect(changed).To(BeTrue())
+		Expect(gitops.IsIntegrationTestScenarioQuarantined(integrationTestScenario)).To(BeFalse())
+	})
+
+	It("ensures IntegrationTestScenarios aren't quarantined automatically before enough inputs were repeated", func() {
+		integrationTestScenario.Annotations = map[string]string{gitops.QuarantineThresholdAnnotation: "50"}
+		gitops.RecordScenarioRun(integrationTestScenario, newRun("a", true, nil, nil))
+		gitops.RecordScenarioRun(integrationTestScenario, newRun("a", false, nil, nil))
+		Expect(gitops.GetIntegrationTestScenarioFlakinessScore(integrationTestScenario)).To(Equal(100))
+
+		_, err := gitops.UpdateIntegrationTestScenarioQuarantine(integrationTestScenario)
+		Expect(err).To(BeNil())
+		Expect(gitops.IsIntegrationTestScenarioQuarantined(integrationTestScenario)).To(BeFalse())
+	})
+
+	It("ensures invalid quarantine thresholds are reported", func() {
+		integrationTestScenario.Annotations = map[string]string{gitops.QuarantineThresholdAnnotation: "150"}
+		_, automatic, err := gitops.GetQuarantineThreshold(integrationTestScenario)
+		Expect(err).NotTo(BeNil())
+		Expect(automatic).To(BeFalse())
+	})
+
+	It("ensures IntegrationTestScenarios can be quarantined manually", func() {
+		Expect(gitops.IsIntegrationTestScenarioQuarantined(integrationTestScenario)).To(BeFalse())
+		integrationTestScenario.Labels = map[string]string{gitops.IntegrationTestScenarioQuarantinedLabel: "true"}
+		Expect(gitops.IsIntegrationTestScenarioQuarantined(integrationTestScenario)).To(BeTrue())
+	})
+})
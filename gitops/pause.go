@@ -0,0 +1,164 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitops
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	"github.com/redhat-appstudio/integration-service/helpers"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// PauseSnapshotCreationAnnotation can be set to "true" on a Component or Application to stop creating Snapshots
+	// for their successful builds.
+	PauseSnapshotCreationAnnotation = "test.appstudio.openshift.io/pause-snapshot-creation"
+
+	// PauseTestingAnnotation can be set to "true" on a Component or Application to stop testing their Snapshots.
+	PauseTestingAnnotation = "test.appstudio.openshift.io/pause-testing"
+
+	// PauseGlobalCandidateUpdateAnnotation can be set to "true" on a Component or Application to stop updating
+	// the Global Candidate List with the images of their tested Snapshots.
+	PauseGlobalCandidateUpdateAnnotation = "test.appstudio.openshift.io/pause-global-candidate-update"
+
+	// PauseBindingUpdateAnnotation can be set to "true" on a Component or Application to stop deploying their Snapshots
+	// by creating or updating SnapshotEnvironmentBindings.
+	PauseBindingUpdateAnnotation = "test.appstudio.openshift.io/pause-binding-update"
+
+	// PauseAutoReleaseAnnotation can be set to "true" on a Component or Application to stop automatically releasing
+	// their Snapshots.
+	PauseAutoReleaseAnnotation = "test.appstudio.openshift.io/pause-auto-release"
+
+	// AppStudioAutomationPausedCondition is the condition listing the automated steps skipped for the Snapshot
+	// because they were paused on its Component or Application.
+	AppStudioAutomationPausedCondition = "AppStudioAutomationPaused"
+
+	// AppStudioAutomationPausedReason is the reason that's set when an automated step is skipped for the Snapshot.
+	AppStudioAutomationPausedReason = "Paused"
+
+	// AppStudioAutomationResumedReason is the reason that's set once none of the automated steps is paused for the Snapshot anymore.
+	AppStudioAutomationResumedReason = "Resumed"
+)
+
+// pausedSteps maps the pause annotations to the names of the automated steps they pause.
+var pausedSteps = map[string]string{
+	PauseSnapshotCreationAnnotation:      "Snapshot creation",
+	PauseTestingAnnotation:               "testing",
+	PauseGlobalCandidateUpdateAnnotation: "Global Candidate List update",
+	PauseBindingUpdateAnnotation:         "SnapshotEnvironmentBinding update",
+	PauseAutoReleaseAnnotation:           "automatic release",
+}
+
+// GetAutomationPauseReason checks if the automated step controlled by the given pause annotation is paused on
+// the Application or the Component, which may be nil. Returns the reason why it is paused, or an empty string if it isn't.
+func GetAutomationPauseReason(pauseAnnotation string, application *applicationapiv1alpha1.Application, component *applicationapiv1alpha1.Component) string {
+	if application != nil && helpers.HasAnnotationWithValue(application, pauseAnnotation, "true") {
+		return fmt.Sprintf("%s is paused by the %s annotation on the Application %s", pausedSteps[pauseAnnotation], pauseAnnotation, application.Name)
+	}
+	if component != nil && helpers.HasAnnotationWithValue(component, pauseAnnotation, "true") {
+		return fmt.Sprintf("%s is paused by the %s annotation on the Component %s", pausedSteps[pauseAnnotation], pauseAnnotation, component.Name)
+	}
+
+	return ""
+}
+
+// MarkSnapshotAutomationPaused adds the reason why an automated step was skipped for the Snapshot to its
+// AppStudioAutomationPaused condition and patches the Snapshot status if it changed.
+func MarkSnapshotAutomationPaused(adapterClient client.Client, ctx context.Context, snapshot *applicationapiv1alpha1.Snapshot, reason string) error {
+	reasons := []string{}
+	condition := meta.FindStatusCondition(snapshot.Status.Conditions, AppStudioAutomationPausedCondition)
+	if condition != nil && condition.Status == metav1.ConditionTrue && condition.Message != "" {
+		reasons = strings.Split(condition.Message, "; ")
+	}
+	for _, existingReason := range reasons {
+		if existingReason == reason {
+			return nil
+		}
+	}
+
+	patch := client.MergeFrom(snapshot.DeepCopy())
+	meta.SetStatusCondition(&snapshot.Status.Conditions, metav1.Condition{
+		Type:    AppStudioAutomationPausedCondition,
+		Status:  metav1.ConditionTrue,
+		Reason:  AppStudioAutomationPausedReason,
+		Message: strings.Join(append(reasons, reason), "; "),
+	})
+	return adapterClient.Status().Patch(ctx, snapshot, patch)
+}
+
+// MarkSnapshotAutomationResumed removes the reasons why the automated step controlled by the given pause annotation
+// was skipped from the AppStudioAutomationPaused condition of the Snapshot and patches the Snapshot status if it changed.
+// The condition is set to false once none of the automated steps is paused anymore.
+func MarkSnapshotAutomationResumed(adapterClient client.Client, ctx context.Context, snapshot *applicationapiv1alpha1.Snapshot, pauseAnnotation string) error {
+	if !IsSnapshotAutomationPaused(snapshot) {
+		return nil
+	}
+
+	condition := meta.FindStatusCondition(snapshot.Status.Conditions, AppStudioAutomationPausedCondition)
+	existingReasons := strings.Split(condition.Message, "; ")
+	reasons := []string{}
+	for _, reason := range existingReasons {
+		if reason != "" && !strings.Contains(reason, fmt.Sprintf(" by the %s annotation ", pauseAnnotation)) {
+			reasons = append(reasons, reason)
+		}
+	}
+	if len(reasons) == len(existingReasons) {
+		return nil
+	}
+
+	patch := client.MergeFrom(snapshot.DeepCopy())
+	if len(reasons) == 0 {
+		meta.SetStatusCondition(&snapshot.Status.Conditions, metav1.Condition{
+			Type:    AppStudioAutomationPausedCondition,
+			Status:  metav1.ConditionFalse,
+			Reason:  AppStudioAutomationResumedReason,
+			Message: "None of the automated steps is paused",
+		})
+	} else {
+		meta.SetStatusCondition(&snapshot.Status.Conditions, metav1.Condition{
+			Type:    AppStudioAutomationPausedCondition,
+			Status:  metav1.ConditionTrue,
+			Reason:  AppStudioAutomationPausedReason,
+			Message: strings.Join(reasons, "; "),
+		})
+	}
+	return adapterClient.Status().Patch(ctx, snapshot, patch)
+}
+
+// IsSnapshotAutomationPaused returns true if any of the automated steps was skipped for the Snapshot
+// because it was paused on its Component or Application.
+func IsSnapshotAutomationPaused(snapshot *applicationapiv1alpha1.Snapshot) bool {
+	return meta.IsStatusConditionTrue(snapshot.Status.Conditions, AppStudioAutomationPausedCondition)
+}
+
+// hasAutomationResumed returns a boolean that is only true if any of the pause annotations set to "true"
+// on the first passed object isn't set to "true" on the second one anymore.
+func hasAutomationResumed(objectOld, objectNew client.Object) bool {
+	for pauseAnnotation := range pausedSteps {
+		if helpers.HasAnnotationWithValue(objectOld, pauseAnnotation, "true") &&
+			!helpers.HasAnnotationWithValue(objectNew, pauseAnnotation, "true") {
+			return true
+		}
+	}
+
+	return false
+}
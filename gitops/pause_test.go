@@ -0,0 +1,136 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitops_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	"github.com/redhat-appstudio/integration-service/gitops"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+var _ = Describe("Gitops functions for pausing the automation", func() {
+
+	var (
+		hasApp      *applicationapiv1alpha1.Application
+		hasComp     *applicationapiv1alpha1.Component
+		hasSnapshot *applicationapiv1alpha1.Snapshot
+	)
+
+	BeforeEach(func() {
+		hasApp = &applicationapiv1alpha1.Application{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "application-paused",
+				Namespace: "default",
+			},
+		}
+		hasComp = &applicationapiv1alpha1.Component{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "component-paused",
+				Namespace: "default",
+			},
+		}
+		hasSnapshot = &applicationapiv1alpha1.Snapshot{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "snapshot-paused",
+				Namespace: "default",
+			},
+			Spec: applicationapiv1alpha1.SnapshotSpec{
+				Application: hasApp.Name,
+				Components: []applicationapiv1alpha1.SnapshotComponent{
+					{
+						Name:           hasComp.Name,
+						ContainerImage: "quay.io/redhat-appstudio/sample-image:latest",
+					},
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, hasSnapshot)).Should(Succeed())
+	})
+
+	AfterEach(func() {
+		err := k8sClient.Delete(ctx, hasSnapshot)
+		Expect(err == nil || errors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("explains which annotation paused an automated step", func() {
+		Expect(gitops.GetAutomationPauseReason(gitops.PauseTestingAnnotation, hasApp, hasComp)).To(BeEmpty())
+		Expect(gitops.GetAutomationPauseReason(gitops.PauseTestingAnnotation, nil, nil)).To(BeEmpty())
+
+		hasComp.Annotations = map[string]string{gitops.PauseTestingAnnotation: "true"}
+		Expect(gitops.GetAutomationPauseReason(gitops.PauseTestingAnnotation, hasApp, hasComp)).To(Equal(
+			"testing is paused by the test.appstudio.openshift.io/pause-testing annotation on the Component component-paused"))
+		Expect(gitops.GetAutomationPauseReason(gitops.PauseAutoReleaseAnnotation, hasApp, hasComp)).To(BeEmpty())
+
+		hasApp.Annotations = map[string]string{gitops.PauseAutoReleaseAnnotation: "true"}
+		Expect(gitops.GetAutomationPauseReason(gitops.PauseAutoReleaseAnnotation, hasApp, nil)).To(Equal(
+			"automatic release is paused by the test.appstudio.openshift.io/pause-auto-release annotation on the Application application-paused"))
+
+		hasApp.Annotations[gitops.PauseAutoReleaseAnnotation] = "false"
+		Expect(gitops.GetAutomationPauseReason(gitops.PauseAutoReleaseAnnotation, hasApp, nil)).To(BeEmpty())
+	})
+
+	It("records each skipped step once on the Snapshot", func() {
+		Expect(gitops.MarkSnapshotAutomationPaused(k8sClient, ctx, hasSnapshot, "testing is paused")).To(Succeed())
+		Expect(gitops.MarkSnapshotAutomationPaused(k8sClient, ctx, hasSnapshot, "automatic release is paused")).To(Succeed())
+		Expect(gitops.MarkSnapshotAutomationPaused(k8sClient, ctx, hasSnapshot, "testing is paused")).To(Succeed())
+
+		condition := meta.FindStatusCondition(hasSnapshot.Status.Conditions, gitops.AppStudioAutomationPausedCondition)
+		Expect(condition).NotTo(BeNil())
+		Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+		Expect(condition.Reason).To(Equal(gitops.AppStudioAutomationPausedReason))
+		Expect(condition.Message).To(Equal("testing is paused; automatic release is paused"))
+	})
+
+	It("removes the skipped steps which resumed from the Snapshot", func() {
+		testingReason := "testing is paused by the " + gitops.PauseTestingAnnotation + " annotation on the Component component-paused"
+		releaseReason := "automatic release is paused by the " + gitops.PauseAutoReleaseAnnotation + " annotation on the Application application-paused"
+		Expect(gitops.MarkSnapshotAutomationPaused(k8sClient, ctx, hasSnapshot, testingReason)).To(Succeed())
+		Expect(gitops.MarkSnapshotAutomationPaused(k8sClient, ctx, hasSnapshot, releaseReason)).To(Succeed())
+		Expect(gitops.IsSnapshotAutomationPaused(hasSnapshot)).To(BeTrue())
+
+		Expect(gitops.MarkSnapshotAutomationResumed(k8sClient, ctx, hasSnapshot, gitops.PauseTestingAnnotation)).To(Succeed())
+		condition := meta.FindStatusCondition(hasSnapshot.Status.Conditions, gitops.AppStudioAutomationPausedCondition)
+		Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+		Expect(condition.Message).To(Equal(releaseReason))
+
+		Expect(gitops.MarkSnapshotAutomationResumed(k8sClient, ctx, hasSnapshot, gitops.PauseAutoReleaseAnnotation)).To(Succeed())
+		Expect(gitops.IsSnapshotAutomationPaused(hasSnapshot)).To(BeFalse())
+		condition = meta.FindStatusCondition(hasSnapshot.Status.Conditions, gitops.AppStudioAutomationPausedCondition)
+		Expect(condition.Reason).To(Equal(gitops.AppStudioAutomationResumedReason))
+	})
+
+	It("lets through only the updates removing a pause annotation", func() {
+		instance := gitops.AutomationResumedPredicate()
+		pausedComp := hasComp.DeepCopy()
+		pausedComp.Annotations = map[string]string{gitops.PauseTestingAnnotation: "true"}
+
+		Expect(instance.Create(event.CreateEvent{Object: pausedComp})).To(BeFalse())
+		Expect(instance.Update(event.UpdateEvent{ObjectOld: hasComp, ObjectNew: pausedComp})).To(BeFalse())
+		Expect(instance.Update(event.UpdateEvent{ObjectOld: pausedComp, ObjectNew: hasComp})).To(BeTrue())
+
+		unpausedComp := pausedComp.DeepCopy()
+		unpausedComp.Annotations[gitops.PauseTestingAnnotation] = "false"
+		Expect(instance.Update(event.UpdateEvent{ObjectOld: pausedComp, ObjectNew: unpausedComp})).To(BeTrue())
+		Expect(instance.Update(event.UpdateEvent{ObjectOld: pausedComp, ObjectNew: pausedComp.DeepCopy()})).To(BeFalse())
+	})
+})
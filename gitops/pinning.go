@@ -0,0 +1,132 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitops
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// ComponentPinnedImageAnnotation can be set on a Component to the image pullspec, including its digest, the Component
+	// is pinned to. Snapshots use the pinned image for the Component unless they were created for a build of the Component
+	// itself, and the Global Candidate List of the Component is never moved away from it.
+	ComponentPinnedImageAnnotation = "test.appstudio.openshift.io/pinned-image"
+
+	// ApplicationPinnedImagesAnnotation can be set on an Application to a JSON object mapping the names of its Components
+	// to the image pullspecs they are pinned to, e.g. {"database-migration": "quay.io/org/migration@sha256:..."}.
+	ApplicationPinnedImagesAnnotation = "test.appstudio.openshift.io/pinned-images"
+
+	// AppStudioComponentPinInvalidCondition is the condition set on Snapshots whose Global Candidate List update is held
+	// back because the pin of one of their Components can't be determined.
+	AppStudioComponentPinInvalidCondition = "AppStudioComponentPinInvalid"
+
+	// AppStudioComponentPinInvalidReason is the reason that's set when the pin of a Component of the Snapshot is invalid.
+	AppStudioComponentPinInvalidReason = "InvalidPin"
+
+	// AppStudioComponentPinValidReason is the reason that's set once the pins of the Components of the Snapshot are valid again.
+	AppStudioComponentPinValidReason = "ValidPin"
+)
+
+// GetPinnedComponentImage returns the image the Component is pinned to, either by its own annotation or by the pin list
+// of the Application, which may be nil. The pin on the Component takes precedence. If the Component isn't pinned,
+// an empty string is returned. If the pin list of the Application can't be parsed, an error will be returned.
+func GetPinnedComponentImage(application *applicationapiv1alpha1.Application, component *applicationapiv1alpha1.Component) (string, error) {
+	if pinnedImage := component.GetAnnotations()[ComponentPinnedImageAnnotation]; pinnedImage != "" {
+		return pinnedImage, nil
+	}
+	if application == nil {
+		return "", nil
+	}
+
+	value, found := application.GetAnnotations()[ApplicationPinnedImagesAnnotation]
+	if !found || value == "" {
+		return "", nil
+	}
+	pinnedImages := map[string]string{}
+	err := json.Unmarshal([]byte(value), &pinnedImages)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse the %s annotation of the Application %s: %w", ApplicationPinnedImagesAnnotation, application.Name, err)
+	}
+
+	return pinnedImages[component.Name], nil
+}
+
+// GetPinnedComponentImages returns the images the given Components are pinned to, keyed by the Component name.
+// Components which aren't pinned are left out.
+func GetPinnedComponentImages(application *applicationapiv1alpha1.Application, components *[]applicationapiv1alpha1.Component) (map[string]string, error) {
+	pinnedImages := map[string]string{}
+	for _, component := range *components {
+		component := component // G601
+		pinnedImage, err := GetPinnedComponentImage(application, &component)
+		if err != nil {
+			return nil, err
+		}
+		if pinnedImage != "" {
+			pinnedImages[component.Name] = pinnedImage
+		}
+	}
+
+	return pinnedImages, nil
+}
+
+// MarkSnapshotComponentPinInvalid sets the AppStudioComponentPinInvalid condition of the Snapshot to true with the given
+// message and patches the Snapshot status if it changed.
+func MarkSnapshotComponentPinInvalid(adapterClient client.Client, ctx context.Context, snapshot *applicationapiv1alpha1.Snapshot, message string) error {
+	condition := meta.FindStatusCondition(snapshot.Status.Conditions, AppStudioComponentPinInvalidCondition)
+	if condition != nil && condition.Status == metav1.ConditionTrue && condition.Message == message {
+		return nil
+	}
+
+	patch := client.MergeFrom(snapshot.DeepCopy())
+	meta.SetStatusCondition(&snapshot.Status.Conditions, metav1.Condition{
+		Type:    AppStudioComponentPinInvalidCondition,
+		Status:  metav1.ConditionTrue,
+		Reason:  AppStudioComponentPinInvalidReason,
+		Message: message,
+	})
+	return adapterClient.Status().Patch(ctx, snapshot, patch)
+}
+
+// MarkSnapshotComponentPinValid sets the AppStudioComponentPinInvalid condition of the Snapshot to false once the pins
+// of its Components are valid again and patches the Snapshot status. Snapshots without an invalid pin are left as they are.
+func MarkSnapshotComponentPinValid(adapterClient client.Client, ctx context.Context, snapshot *applicationapiv1alpha1.Snapshot) error {
+	if !IsSnapshotComponentPinInvalid(snapshot) {
+		return nil
+	}
+
+	patch := client.MergeFrom(snapshot.DeepCopy())
+	meta.SetStatusCondition(&snapshot.Status.Conditions, metav1.Condition{
+		Type:    AppStudioComponentPinInvalidCondition,
+		Status:  metav1.ConditionFalse,
+		Reason:  AppStudioComponentPinValidReason,
+		Message: "The pins of the Components are valid",
+	})
+	return adapterClient.Status().Patch(ctx, snapshot, patch)
+}
+
+// IsSnapshotComponentPinInvalid returns true if the Global Candidate List update of the Snapshot is held back
+// by an invalid pin of one of its Components.
+func IsSnapshotComponentPinInvalid(snapshot *applicationapiv1alpha1.Snapshot) bool {
+	return meta.IsStatusConditionTrue(snapshot.Status.Conditions, AppStudioComponentPinInvalidCondition)
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitops_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	"github.com/redhat-appstudio/integration-service/gitops"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("Gitops functions for pinned components", func() {
+
+	const (
+		pinnedImage         = "quay.io/redhat-appstudio/migration@sha256:841328df1b9f8c4087adbdcfec6cc99ac8308805dea83f6d415d6fb8d40227c1"
+		applicationPinImage = "quay.io/redhat-appstudio/migration@sha256:1111111111111111111111111111111111111111111111111111111111111111"
+	)
+
+	var (
+		hasApp       *applicationapiv1alpha1.Application
+		pinnedComp   *applicationapiv1alpha1.Component
+		unpinnedComp *applicationapiv1alpha1.Component
+	)
+
+	BeforeEach(func() {
+		hasApp = &applicationapiv1alpha1.Application{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "application-sample",
+			},
+		}
+		pinnedComp = &applicationapiv1alpha1.Component{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "database-migration",
+			},
+		}
+		unpinnedComp = &applicationapiv1alpha1.Component{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "frontend",
+			},
+		}
+	})
+
+	It("returns the image a component is pinned to on itself or on its application", func() {
+		Expect(gitops.GetPinnedComponentImage(hasApp, pinnedComp)).To(BeEmpty())
+		Expect(gitops.GetPinnedComponentImage(nil, pinnedComp)).To(BeEmpty())
+
+		hasApp.Annotations = map[string]string{
+			gitops.ApplicationPinnedImagesAnnotation: `{"database-migration": "` + applicationPinImage + `"}`,
+		}
+		Expect(gitops.GetPinnedComponentImage(hasApp, pinnedComp)).To(Equal(applicationPinImage))
+		Expect(gitops.GetPinnedComponentImage(hasApp, unpinnedComp)).To(BeEmpty())
+
+		pinnedComp.Annotations = map[string]string{gitops.ComponentPinnedImageAnnotation: pinnedImage}
+		Expect(gitops.GetPinnedComponentImage(hasApp, pinnedComp)).To(Equal(pinnedImage))
+
+		pinnedImages, err := gitops.GetPinnedComponentImages(hasApp, &[]applicationapiv1alpha1.Component{*pinnedComp, *unpinnedComp})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pinnedImages).To(Equal(map[string]string{"database-migration": pinnedImage}))
+	})
+
+	It("fails when the pin list of the application can't be parsed", func() {
+		hasApp.Annotations = map[string]string{gitops.ApplicationPinnedImagesAnnotation: "database-migration"}
+		_, err := gitops.GetPinnedComponentImage(hasApp, pinnedComp)
+		Expect(err).To(HaveOccurred())
+
+		_, err = gitops.GetPinnedComponentImages(hasApp, &[]applicationapiv1alpha1.Component{*unpinnedComp})
+		Expect(err).To(HaveOccurred())
+	})
+})
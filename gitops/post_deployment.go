@@ -0,0 +1,175 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitops
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	"github.com/redhat-appstudio/integration-service/api/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// PostDeploymentContext is the name of the IntegrationTestScenario context marking it as a post-deployment
+	// verification scenario. Such scenarios aren't run when a Snapshot is tested, but every time a Snapshot is deployed
+	// to the persistent Environments named in their environment or environments fields, or to all of them if none are named.
+	PostDeploymentContext = "post-deployment"
+
+	// EnvironmentVerificationAnnotation contains the JSON encoded result of the last post-deployment verification
+	// of the annotated Environment.
+	EnvironmentVerificationAnnotation = "test.appstudio.openshift.io/verification"
+
+	// EnvironmentRollbackOnFailedVerificationAnnotation can be set to "true" on an Environment to roll its
+	// SnapshotEnvironmentBinding back to the last verified Snapshot when a Snapshot fails the post-deployment verification.
+	EnvironmentRollbackOnFailedVerificationAnnotation = "test.appstudio.openshift.io/rollback-on-failed-verification"
+)
+
+// EnvironmentVerification records the result of the post-deployment verification of a Snapshot in an Environment.
+type EnvironmentVerification struct {
+	// Snapshot is the name of the verified Snapshot
+	Snapshot string `json:"snapshot"`
+	// Passed is true if all the post-deployment scenarios of the Environment passed
+	Passed bool `json:"passed"`
+	// FailedScenarios contains the names of the post-deployment scenarios which failed
+	FailedScenarios []string `json:"failedScenarios,omitempty"`
+	// Time the verification finished
+	Time metav1.Time `json:"time"`
+}
+
+// IsPostDeploymentIntegrationTestScenario returns true if the IntegrationTestScenario is a post-deployment verification scenario.
+func IsPostDeploymentIntegrationTestScenario(integrationTestScenario *v1beta1.IntegrationTestScenario) bool {
+	for _, testContext := range integrationTestScenario.Spec.Contexts {
+		if testContext.Name == PostDeploymentContext {
+			return true
+		}
+	}
+
+	return false
+}
+
+// GetPreDeploymentIntegrationTestScenarios returns the IntegrationTestScenarios from the given list which test Snapshots
+// before they are promoted, leaving out the post-deployment verification scenarios.
+func GetPreDeploymentIntegrationTestScenarios(integrationTestScenarios *[]v1beta1.IntegrationTestScenario) *[]v1beta1.IntegrationTestScenario {
+	preDeploymentScenarios := []v1beta1.IntegrationTestScenario{}
+	for _, integrationTestScenario := range *integrationTestScenarios {
+		integrationTestScenario := integrationTestScenario // G601
+		if !IsPostDeploymentIntegrationTestScenario(&integrationTestScenario) {
+			preDeploymentScenarios = append(preDeploymentScenarios, integrationTestScenario)
+		}
+	}
+
+	return &preDeploymentScenarios
+}
+
+// GetPostDeploymentIntegrationTestScenariosForEnvironment returns the post-deployment verification scenarios from the
+// given list which verify the Environment with the given name.
+func GetPostDeploymentIntegrationTestScenariosForEnvironment(integrationTestScenarios *[]v1beta1.IntegrationTestScenario, environmentName string) []v1beta1.IntegrationTestScenario {
+	postDeploymentScenarios := []v1beta1.IntegrationTestScenario{}
+	for _, integrationTestScenario := range *integrationTestScenarios {
+		integrationTestScenario := integrationTestScenario // G601
+		if !IsPostDeploymentIntegrationTestScenario(&integrationTestScenario) {
+			continue
+		}
+		testEnvironments := GetIntegrationTestScenarioEnvironments(&integrationTestScenario)
+		if len(testEnvironments) == 0 {
+			postDeploymentScenarios = append(postDeploymentScenarios, integrationTestScenario)
+			continue
+		}
+		for _, testEnvironment := range testEnvironments {
+			if testEnvironment.Name == environmentName {
+				postDeploymentScenarios = append(postDeploymentScenarios, integrationTestScenario)
+				break
+			}
+		}
+	}
+
+	return postDeploymentScenarios
+}
+
+// IsRollbackOnFailedVerificationEnabled returns true if the SnapshotEnvironmentBinding of the Environment should be
+// rolled back when a Snapshot fails its post-deployment verification.
+func IsRollbackOnFailedVerificationEnabled(environment *applicationapiv1alpha1.Environment) bool {
+	return environment.GetAnnotations()[EnvironmentRollbackOnFailedVerificationAnnotation] == "true"
+}
+
+// GetEnvironmentVerification returns the result of the last post-deployment verification recorded on the Environment.
+// If no verification was recorded, nil is returned. If the annotation can't be parsed, an error will be returned.
+func GetEnvironmentVerification(environment *applicationapiv1alpha1.Environment) (*EnvironmentVerification, error) {
+	value, found := environment.GetAnnotations()[EnvironmentVerificationAnnotation]
+	if !found || value == "" {
+		return nil, nil
+	}
+
+	verification := &EnvironmentVerification{}
+	err := json.Unmarshal([]byte(value), verification)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the verification of the Environment: %w", err)
+	}
+
+	return verification, nil
+}
+
+// RecordEnvironmentVerification records on the Environment the result of the post-deployment verification of the given
+// Snapshot. The verification passed if no scenarios failed. If the patch command fails, an error will be returned.
+func RecordEnvironmentVerification(adapterClient client.Client, ctx context.Context, environment *applicationapiv1alpha1.Environment, snapshot *applicationapiv1alpha1.Snapshot, failedScenarios []string) error {
+	verification := EnvironmentVerification{
+		Snapshot:        snapshot.Name,
+		Passed:          len(failedScenarios) == 0,
+		FailedScenarios: failedScenarios,
+		Time:            metav1.Time{Time: time.Now().UTC().Truncate(time.Second)},
+	}
+	value, err := json.Marshal(verification)
+	if err != nil {
+		return err
+	}
+
+	patch := client.MergeFrom(environment.DeepCopy())
+	if environment.Annotations == nil {
+		environment.Annotations = map[string]string{}
+	}
+	environment.Annotations[EnvironmentVerificationAnnotation] = string(value)
+
+	return adapterClient.Patch(ctx, environment, patch)
+}
+
+// MarkSnapshotVerificationFailedInEnvironment records on the Snapshot that it failed the post-deployment verification
+// of the Environment with the given name, and whether it was rolled back because of it.
+// If the patch command fails, an error will be returned.
+func MarkSnapshotVerificationFailedInEnvironment(adapterClient client.Client, ctx context.Context, snapshot *applicationapiv1alpha1.Snapshot, environmentName string, failedScenarios []string, rolledBack bool) error {
+	return updateSnapshotEnvironmentPromotionDecision(adapterClient, ctx, snapshot, environmentName, func(decision *PromotionDecision) {
+		decision.VerificationFailed = true
+		decision.RolledBack = rolledBack
+		decision.Reasons = []string{fmt.Sprintf("the Snapshot failed the post-deployment verification scenarios %s", strings.Join(failedScenarios, ", "))}
+	})
+}
+
+// HasSnapshotFailedVerificationInEnvironment returns true if the Snapshot was recorded as having failed the
+// post-deployment verification of the Environment with the given name.
+func HasSnapshotFailedVerificationInEnvironment(snapshot *applicationapiv1alpha1.Snapshot, environmentName string) bool {
+	decisions, err := GetSnapshotPromotionDecisions(snapshot)
+	if err != nil {
+		return false
+	}
+
+	return decisions[GetPromotionTargetKey(PromotionTargetEnvironment, environmentName)].VerificationFailed
+}
@@ -0,0 +1,142 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitops_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	"github.com/redhat-appstudio/integration-service/api/v1beta1"
+	"github.com/redhat-appstudio/integration-service/gitops"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("Gitops functions for post-deployment verification", func() {
+
+	var (
+		hasSnapshot  *applicationapiv1alpha1.Snapshot
+		environment  *applicationapiv1alpha1.Environment
+		allScenarios []v1beta1.IntegrationTestScenario
+	)
+
+	BeforeEach(func() {
+		hasSnapshot = &applicationapiv1alpha1.Snapshot{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "snapshot-verification",
+				Namespace: "default",
+			},
+			Spec: applicationapiv1alpha1.SnapshotSpec{
+				Application: "application-sample",
+				Components: []applicationapiv1alpha1.SnapshotComponent{
+					{
+						Name:           "component-sample",
+						ContainerImage: "quay.io/redhat-appstudio/sample-image:latest",
+					},
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, hasSnapshot)).Should(Succeed())
+
+		environment = &applicationapiv1alpha1.Environment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "staging-verification",
+				Namespace: "default",
+			},
+			Spec: applicationapiv1alpha1.EnvironmentSpec{
+				Type:               "POC",
+				DisplayName:        "staging",
+				DeploymentStrategy: applicationapiv1alpha1.DeploymentStrategy_Manual,
+			},
+		}
+		Expect(k8sClient.Create(ctx, environment)).Should(Succeed())
+
+		allScenarios = []v1beta1.IntegrationTestScenario{
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "unit-tests"},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "smoke-tests"},
+				Spec: v1beta1.IntegrationTestScenarioSpec{
+					Contexts: []v1beta1.TestContext{{Name: gitops.PostDeploymentContext}},
+				},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "prod-smoke-tests"},
+				Spec: v1beta1.IntegrationTestScenarioSpec{
+					Contexts:    []v1beta1.TestContext{{Name: gitops.PostDeploymentContext}},
+					Environment: v1beta1.TestEnvironment{Name: "production", Type: "POC"},
+				},
+			},
+		}
+	})
+
+	AfterEach(func() {
+		err := k8sClient.Delete(ctx, hasSnapshot)
+		Expect(err == nil || errors.IsNotFound(err)).To(BeTrue())
+		err = k8sClient.Delete(ctx, environment)
+		Expect(err == nil || errors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("separates the pre-deployment scenarios from the post-deployment ones of each Environment", func() {
+		preDeploymentScenarios := gitops.GetPreDeploymentIntegrationTestScenarios(&allScenarios)
+		Expect(*preDeploymentScenarios).To(HaveLen(1))
+		Expect((*preDeploymentScenarios)[0].Name).To(Equal("unit-tests"))
+
+		stagingScenarios := gitops.GetPostDeploymentIntegrationTestScenariosForEnvironment(&allScenarios, "staging")
+		Expect(stagingScenarios).To(HaveLen(1))
+		Expect(stagingScenarios[0].Name).To(Equal("smoke-tests"))
+
+		productionScenarios := gitops.GetPostDeploymentIntegrationTestScenariosForEnvironment(&allScenarios, "production")
+		Expect(productionScenarios).To(HaveLen(2))
+	})
+
+	It("records the verification of a Snapshot on the Environment", func() {
+		verification, err := gitops.GetEnvironmentVerification(environment)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(verification).To(BeNil())
+
+		err = gitops.RecordEnvironmentVerification(k8sClient, ctx, environment, hasSnapshot, []string{"smoke-tests"})
+		Expect(err).NotTo(HaveOccurred())
+
+		verification, err = gitops.GetEnvironmentVerification(environment)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(verification.Snapshot).To(Equal(hasSnapshot.Name))
+		Expect(verification.Passed).To(BeFalse())
+		Expect(verification.FailedScenarios).To(Equal([]string{"smoke-tests"}))
+
+		environment.Annotations[gitops.EnvironmentVerificationAnnotation] = "{"
+		_, err = gitops.GetEnvironmentVerification(environment)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("records a failed verification of the Snapshot in the Environment", func() {
+		Expect(gitops.HasSnapshotFailedVerificationInEnvironment(hasSnapshot, environment.Name)).To(BeFalse())
+		Expect(gitops.IsRollbackOnFailedVerificationEnabled(environment)).To(BeFalse())
+
+		err := gitops.MarkSnapshotVerificationFailedInEnvironment(k8sClient, ctx, hasSnapshot, environment.Name, []string{"smoke-tests"}, true)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(gitops.HasSnapshotFailedVerificationInEnvironment(hasSnapshot, environment.Name)).To(BeTrue())
+
+		decisions, err := gitops.GetSnapshotPromotionDecisions(hasSnapshot)
+		Expect(err).NotTo(HaveOccurred())
+		decision := decisions[gitops.GetPromotionTargetKey(gitops.PromotionTargetEnvironment, environment.Name)]
+		Expect(decision.RolledBack).To(BeTrue())
+		Expect(decision.Reasons).To(Equal([]string{"the Snapshot failed the post-deployment verification scenarios smoke-tests"}))
+	})
+})
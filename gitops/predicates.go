@@ -57,3 +57,68 @@ func IntegrationSnapshotEnvironmentBindingPredicate() predicate.Predicate {
 		},
 	}
 }
+
+// PromotedSnapshotEnvironmentBindingPredicate returns a predicate which filters out all events except the ones
+// for SnapshotEnvironmentBindings not associated with an IntegrationTestScenario which have a Snapshot promotion
+// in progress. Update events are only let through if the promotion just started or the deployment succeeded or failed.
+func PromotedSnapshotEnvironmentBindingPredicate() predicate.Predicate {
+	return predicate.Funcs{
+		CreateFunc: func(createEvent event.CreateEvent) bool {
+			return !helpers.HasLabel(createEvent.Object, SnapshotTestScenarioLabel) &&
+				helpers.HasAnnotation(createEvent.Object, BindingPromotionTimeAnnotation)
+		},
+		DeleteFunc: func(deleteEvent event.DeleteEvent) bool {
+			return false
+		},
+		GenericFunc: func(genericEvent event.GenericEvent) bool {
+			return false
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return !helpers.HasLabel(e.ObjectNew, SnapshotTestScenarioLabel) &&
+				helpers.HasAnnotation(e.ObjectNew, BindingPromotionTimeAnnotation) &&
+				(hasBindingPromotionStarted(e.ObjectOld, e.ObjectNew) || hasDeploymentSucceeded(e.ObjectOld, e.ObjectNew) ||
+					hasDeploymentFailed(e.ObjectOld, e.ObjectNew))
+		},
+	}
+}
+
+// DeploymentStatusChangedBindingPredicate returns a predicate which filters out all events except the update events
+// for SnapshotEnvironmentBindings not associated with an IntegrationTestScenario whose Snapshot changed or whose
+// deployment succeeded or failed, e.g. after being rolled back, so the deployment status can be reported.
+func DeploymentStatusChangedBindingPredicate() predicate.Predicate {
+	return predicate.Funcs{
+		CreateFunc: func(createEvent event.CreateEvent) bool {
+			return false
+		},
+		DeleteFunc: func(deleteEvent event.DeleteEvent) bool {
+			return false
+		},
+		GenericFunc: func(genericEvent event.GenericEvent) bool {
+			return false
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return !helpers.HasLabel(e.ObjectNew, SnapshotTestScenarioLabel) &&
+				(hasBindingSnapshotChanged(e.ObjectOld, e.ObjectNew) || hasDeploymentSucceeded(e.ObjectOld, e.ObjectNew) ||
+					hasDeploymentFailed(e.ObjectOld, e.ObjectNew))
+		},
+	}
+}
+
+// AutomationResumedPredicate returns a predicate which filters out all events except the updates of Components
+// and Applications removing any of the pause annotations, so the Snapshots whose automated steps were paused resume.
+func AutomationResumedPredicate() predicate.Predicate {
+	return predicate.Funcs{
+		CreateFunc: func(createEvent event.CreateEvent) bool {
+			return false
+		},
+		DeleteFunc: func(deleteEvent event.DeleteEvent) bool {
+			return false
+		},
+		GenericFunc: func(genericEvent event.GenericEvent) bool {
+			return false
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return hasAutomationResumed(e.ObjectOld, e.ObjectNew)
+		},
+	}
+}
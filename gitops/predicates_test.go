@@ -221,4 +221,56 @@ var _ = Describe("Predicates", Ordered, func() {
 			Expect(instance.Generic(contextEvent)).To(BeFalse())
 		})
 	})
+	Context("when testing PromotedSnapshotEnvironmentBindingPredicate predicate", func() {
+		instance := gitops.PromotedSnapshotEnvironmentBindingPredicate()
+		It("returns true when a Snapshot is promoted to a SEB without the SnapshotTestScenarioLabel", func() {
+			promotedBinding := bindingMissingStatus.DeepCopy()
+			promotedBinding.Labels = map[string]string{}
+			promotedBinding.Annotations = map[string]string{gitops.BindingPromotionTimeAnnotation: "2023-07-01T12:00:00Z"}
+
+			Expect(instance.Create(event.CreateEvent{Object: promotedBinding})).To(BeTrue())
+			Expect(instance.Update(event.UpdateEvent{ObjectOld: bindingMissingStatus, ObjectNew: promotedBinding})).To(BeTrue())
+			Expect(instance.Update(event.UpdateEvent{ObjectOld: promotedBinding, ObjectNew: promotedBinding})).To(BeFalse())
+
+			deployedBinding := promotedBinding.DeepCopy()
+			deployedBinding.Status.ComponentDeploymentConditions = bindingFalseStatus.Status.ComponentDeploymentConditions
+			deployedBinding.Status.ComponentDeploymentConditions[0].Status = metav1.ConditionTrue
+			Expect(instance.Update(event.UpdateEvent{ObjectOld: promotedBinding, ObjectNew: deployedBinding})).To(BeTrue())
+		})
+
+		It("returns false for SEBs with the SnapshotTestScenarioLabel or without a promotion in progress", func() {
+			promotedBinding := bindingMissingStatus.DeepCopy()
+			promotedBinding.Labels = map[string]string{gitops.SnapshotTestScenarioLabel: "test-scenario"}
+			promotedBinding.Annotations = map[string]string{gitops.BindingPromotionTimeAnnotation: "2023-07-01T12:00:00Z"}
+			Expect(instance.Create(event.CreateEvent{Object: promotedBinding})).To(BeFalse())
+
+			Expect(instance.Update(event.UpdateEvent{ObjectOld: bindingFalseStatus, ObjectNew: bindingTrueStatus})).To(BeFalse())
+		})
+	})
+	Context("when testing DeploymentStatusChangedBindingPredicate predicate", func() {
+		instance := gitops.DeploymentStatusChangedBindingPredicate()
+		It("returns true when the Snapshot or the deployment of a SEB without the SnapshotTestScenarioLabel changes", func() {
+			binding := bindingMissingStatus.DeepCopy()
+			binding.Labels = map[string]string{}
+			Expect(instance.Create(event.CreateEvent{Object: binding})).To(BeFalse())
+			Expect(instance.Update(event.UpdateEvent{ObjectOld: binding, ObjectNew: binding})).To(BeFalse())
+
+			rolledBackBinding := binding.DeepCopy()
+			rolledBackBinding.Spec.Snapshot = "previous-snapshot"
+			Expect(instance.Update(event.UpdateEvent{ObjectOld: binding, ObjectNew: rolledBackBinding})).To(BeTrue())
+
+			deployedBinding := binding.DeepCopy()
+			deployedBinding.Status.ComponentDeploymentConditions = []metav1.Condition{
+				{
+					Type:   gitops.BindingDeploymentStatusConditionType,
+					Status: metav1.ConditionTrue,
+				},
+			}
+			Expect(instance.Update(event.UpdateEvent{ObjectOld: binding, ObjectNew: deployedBinding})).To(BeTrue())
+		})
+
+		It("returns false for SEBs with the SnapshotTestScenarioLabel", func() {
+			Expect(instance.Update(event.UpdateEvent{ObjectOld: bindingFalseStatus, ObjectNew: bindingTrueStatus})).To(BeFalse())
+		})
+	})
 })
@@ -0,0 +1,322 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitops
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// RequiredScenariosAnnotation contains the comma separated names of the IntegrationTestScenarios which have to pass
+	// before a Snapshot is promoted to the annotated ReleasePlan or Environment. Targets without it require all the
+	// non-optional IntegrationTestScenarios of the Application to pass.
+	RequiredScenariosAnnotation = "test.appstudio.openshift.io/required-scenarios"
+
+	// SnapshotPromotionAnnotation contains the JSON encoded promotion decisions made for the Snapshot,
+	// keyed by the promotion target.
+	SnapshotPromotionAnnotation = "test.appstudio.openshift.io/promotion"
+
+	// PromotionTargetReleasePlan is the kind of promotion target used for ReleasePlans.
+	PromotionTargetReleasePlan = "ReleasePlan"
+
+	// PromotionTargetEnvironment is the kind of promotion target used for Environments.
+	PromotionTargetEnvironment = "Environment"
+)
+
+// PromotionDecision records whether a Snapshot can be promoted to a single ReleasePlan or Environment.
+type PromotionDecision struct {
+	// Promoted is true if the Snapshot can be promoted to the target
+	Promoted bool `json:"promoted"`
+	// RolledBack is true if the Snapshot was promoted to the target but failed to deploy and was rolled back
+	RolledBack bool `json:"rolledBack,omitempty"`
+	// Deployed is true if the Snapshot was deployed to the target Environment
+	Deployed bool `json:"deployed,omitempty"`
+	// DeploymentTime is the time the Snapshot was deployed to the target Environment
+	DeploymentTime *metav1.Time `json:"deploymentTime,omitempty"`
+	// Verified is true if the Snapshot stayed healthy in the target Environment for its soak time and passed its
+	// post-deployment verification, making it eligible for promotion to the child Environments
+	Verified bool `json:"verified,omitempty"`
+	// VerificationFailed is true if the Snapshot failed the post-deployment verification of the target Environment
+	VerificationFailed bool `json:"verificationFailed,omitempty"`
+	// Reasons why the Snapshot can't be promoted to the target
+	Reasons []string `json:"reasons,omitempty"`
+}
+
+// GetPromotionTargetKey returns the key under which the promotion decision for the target of the given kind
+// and name is recorded on the Snapshot.
+func GetPromotionTargetKey(kind, name string) string {
+	return fmt.Sprintf("%s/%s", kind, name)
+}
+
+// GetPromotionTargetRequiredScenarios returns the names of the IntegrationTestScenarios the given ReleasePlan or
+// Environment requires to pass. An empty list is returned if the target doesn't name any IntegrationTestScenarios.
+func GetPromotionTargetRequiredScenarios(target client.Object) []string {
+	requiredScenarios := []string{}
+	for _, scenario := range strings.Split(target.GetAnnotations()[RequiredScenariosAnnotation], ",") {
+		if scenario = strings.TrimSpace(scenario); scenario != "" {
+			requiredScenarios = append(requiredScenarios, scenario)
+		}
+	}
+
+	return requiredScenarios
+}
+
+// CanSnapshotBePromotedToTarget checks if the Snapshot in question can be promoted to a ReleasePlan or Environment
+// which requires the given IntegrationTestScenarios to pass. If no IntegrationTestScenarios are required,
+// the decision is the same as the one of CanSnapshotBePromoted.
+func CanSnapshotBePromotedToTarget(snapshot *applicationapiv1alpha1.Snapshot, requiredScenarios []string, passedScenarios map[string]bool) PromotionDecision {
+	if len(requiredScenarios) == 0 {
+		return newPromotionDecision(CanSnapshotBePromoted(snapshot))
+	}
+
+	canBePromoted, reasons := CanSnapshotBePromotedPerTarget(snapshot)
+	for _, scenario := range requiredScenarios {
+		if !passedScenarios[scenario] {
+			canBePromoted = false
+			reasons = append(reasons, fmt.Sprintf("the Snapshot hasn't passed the required IntegrationTestScenario %s", scenario))
+		}
+	}
+
+	return newPromotionDecision(canBePromoted, reasons)
+}
+
+// newPromotionDecision creates a PromotionDecision, leaving out the reasons if there are none
+// so the decision compares equal to the one parsed back from the Snapshot annotation.
+func newPromotionDecision(canBePromoted bool, reasons []string) PromotionDecision {
+	if len(reasons) == 0 {
+		reasons = nil
+	}
+
+	return PromotionDecision{Promoted: canBePromoted, Reasons: reasons}
+}
+
+// CanSnapshotBePromotedPerTarget checks if the Snapshot in question could be promoted to any ReleasePlan or
+// Environment, regardless of the outcome of its integration tests.
+func CanSnapshotBePromotedPerTarget(snapshot *applicationapiv1alpha1.Snapshot) (bool, []string) {
+	canBePromoted := true
+	reasons := make([]string, 0)
+	if !IsSnapshotValid(snapshot) {
+		canBePromoted = false
+		reasons = append(reasons, "the Snapshot is invalid")
+	}
+	if IsSnapshotCreatedByPACPullRequestEvent(snapshot) {
+		canBePromoted = false
+		reasons = append(reasons, "the Snapshot was created for a PaC pull request event")
+	}
+	if IsSnapshotScheduled(snapshot) {
+		canBePromoted = false
+		reasons = append(reasons, "the Snapshot was created for a scheduled run of an IntegrationTestScenario")
+	}
+	return canBePromoted, reasons
+}
+
+// GetSnapshotPromotionDecisions returns the promotion decisions recorded on the Snapshot, keyed by the promotion target.
+// If the annotation can't be parsed, an error will be returned.
+func GetSnapshotPromotionDecisions(snapshot *applicationapiv1alpha1.Snapshot) (map[string]PromotionDecision, error) {
+	decisions := map[string]PromotionDecision{}
+	value, found := snapshot.GetAnnotations()[SnapshotPromotionAnnotation]
+	if !found || value == "" {
+		return decisions, nil
+	}
+
+	err := json.Unmarshal([]byte(value), &decisions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the promotion decisions of the Snapshot: %w", err)
+	}
+
+	return decisions, nil
+}
+
+// RecordSnapshotPromotionDecisions merges the given promotion decisions into the ones recorded on the Snapshot.
+// The Snapshot is only patched if any of the decisions changed. If the patch command fails, an error will be returned.
+func RecordSnapshotPromotionDecisions(adapterClient client.Client, ctx context.Context, snapshot *applicationapiv1alpha1.Snapshot, decisions map[string]PromotionDecision) error {
+	recordedDecisions, err := GetSnapshotPromotionDecisions(snapshot)
+	if err != nil {
+		// A malformed annotation is overwritten by the current decisions
+		recordedDecisions = map[string]PromotionDecision{}
+	}
+
+	updated := false
+	for target, decision := range decisions {
+		if recordedDecision, found := recordedDecisions[target]; !found || !isPromotionDecisionEqual(recordedDecision, decision) {
+			recordedDecisions[target] = decision
+			updated = true
+		}
+	}
+	if !updated && err == nil {
+		return nil
+	}
+
+	value, err := json.Marshal(recordedDecisions)
+	if err != nil {
+		return err
+	}
+
+	patch := client.MergeFrom(snapshot.DeepCopy())
+	if snapshot.Annotations == nil {
+		snapshot.Annotations = map[string]string{}
+	}
+	snapshot.Annotations[SnapshotPromotionAnnotation] = string(value)
+
+	return adapterClient.Patch(ctx, snapshot, patch)
+}
+
+// GetChangedPromotionDecisions returns the promotion decisions from the given ones which differ from the ones
+// recorded on the Snapshot for the same targets, so only the decisions which changed are recorded and reported.
+func GetChangedPromotionDecisions(snapshot *applicationapiv1alpha1.Snapshot, decisions map[string]PromotionDecision) map[string]PromotionDecision {
+	recordedDecisions, err := GetSnapshotPromotionDecisions(snapshot)
+	if err != nil {
+		return decisions
+	}
+
+	changedDecisions := map[string]PromotionDecision{}
+	for target, decision := range decisions {
+		if recordedDecision, found := recordedDecisions[target]; !found || !isPromotionDecisionEqual(recordedDecision, decision) {
+			changedDecisions[target] = decision
+		}
+	}
+
+	return changedDecisions
+}
+
+// isPromotionDecisionEqual returns true if both promotion decisions are recorded the same way on the Snapshot,
+// so a decision compares equal to the one parsed back from the Snapshot annotation.
+func isPromotionDecisionEqual(decision, otherDecision PromotionDecision) bool {
+	value, err := json.Marshal(decision)
+	if err != nil {
+		return false
+	}
+	otherValue, err := json.Marshal(otherDecision)
+	if err != nil {
+		return false
+	}
+
+	return string(value) == string(otherValue)
+}
+
+// HasSnapshotPromotionBeenRolledBack returns true if the Snapshot was rolled back from the promotion target
+// with the given key after it failed to deploy.
+func HasSnapshotPromotionBeenRolledBack(snapshot *applicationapiv1alpha1.Snapshot, targetKey string) bool {
+	decisions, err := GetSnapshotPromotionDecisions(snapshot)
+	if err != nil {
+		return false
+	}
+
+	return decisions[targetKey].RolledBack
+}
+
+// IsSnapshotDeployedToEnvironment returns true if the Snapshot was recorded as deployed to the Environment with the given name.
+func IsSnapshotDeployedToEnvironment(snapshot *applicationapiv1alpha1.Snapshot, environmentName string) bool {
+	decisions, err := GetSnapshotPromotionDecisions(snapshot)
+	if err != nil {
+		return false
+	}
+
+	return decisions[GetPromotionTargetKey(PromotionTargetEnvironment, environmentName)].Deployed
+}
+
+// IsSnapshotVerifiedInEnvironment returns true if the Snapshot was recorded as verified in the Environment with the given name,
+// which makes it eligible for promotion to the child Environments.
+func IsSnapshotVerifiedInEnvironment(snapshot *applicationapiv1alpha1.Snapshot, environmentName string) bool {
+	decisions, err := GetSnapshotPromotionDecisions(snapshot)
+	if err != nil {
+		return false
+	}
+
+	return decisions[GetPromotionTargetKey(PromotionTargetEnvironment, environmentName)].Verified
+}
+
+// GetSnapshotEnvironmentDeploymentTime returns the time the Snapshot was recorded as deployed to the Environment with
+// the given name. If the Snapshot wasn't deployed to the Environment, nil is returned.
+func GetSnapshotEnvironmentDeploymentTime(snapshot *applicationapiv1alpha1.Snapshot, environmentName string) *metav1.Time {
+	decisions, err := GetSnapshotPromotionDecisions(snapshot)
+	if err != nil {
+		return nil
+	}
+
+	return decisions[GetPromotionTargetKey(PromotionTargetEnvironment, environmentName)].DeploymentTime
+}
+
+// HasSnapshotBeenVerifiedInNewEnvironment returns a boolean that is only true if the second passed object
+// is a Snapshot verified in an Environment the first passed object wasn't verified in.
+func HasSnapshotBeenVerifiedInNewEnvironment(objectOld, objectNew client.Object) bool {
+	oldSnapshot, ok := objectOld.(*applicationapiv1alpha1.Snapshot)
+	if !ok {
+		return false
+	}
+	newSnapshot, ok := objectNew.(*applicationapiv1alpha1.Snapshot)
+	if !ok {
+		return false
+	}
+
+	newDecisions, err := GetSnapshotPromotionDecisions(newSnapshot)
+	if err != nil {
+		return false
+	}
+	oldDecisions, err := GetSnapshotPromotionDecisions(oldSnapshot)
+	if err != nil {
+		oldDecisions = map[string]PromotionDecision{}
+	}
+	for target, decision := range newDecisions {
+		if decision.Verified && !oldDecisions[target].Verified {
+			return true
+		}
+	}
+
+	return false
+}
+
+// MarkSnapshotDeployedToEnvironment records on the Snapshot that it was deployed to the Environment with the given name.
+// If the patch command fails, an error will be returned.
+func MarkSnapshotDeployedToEnvironment(adapterClient client.Client, ctx context.Context, snapshot *applicationapiv1alpha1.Snapshot, environmentName string) error {
+	return updateSnapshotEnvironmentPromotionDecision(adapterClient, ctx, snapshot, environmentName, func(decision *PromotionDecision) {
+		decision.Promoted = true
+		decision.Deployed = true
+		decision.DeploymentTime = &metav1.Time{Time: time.Now().UTC().Truncate(time.Second)}
+	})
+}
+
+// MarkSnapshotVerifiedInEnvironment records on the Snapshot that it was verified in the Environment with the given name.
+// If the patch command fails, an error will be returned.
+func MarkSnapshotVerifiedInEnvironment(adapterClient client.Client, ctx context.Context, snapshot *applicationapiv1alpha1.Snapshot, environmentName string) error {
+	return updateSnapshotEnvironmentPromotionDecision(adapterClient, ctx, snapshot, environmentName, func(decision *PromotionDecision) {
+		decision.Verified = true
+	})
+}
+
+// updateSnapshotEnvironmentPromotionDecision applies the given update to the promotion decision recorded on the Snapshot
+// for the Environment with the given name and records it back on the Snapshot.
+func updateSnapshotEnvironmentPromotionDecision(adapterClient client.Client, ctx context.Context, snapshot *applicationapiv1alpha1.Snapshot, environmentName string, update func(decision *PromotionDecision)) error {
+	decisions, err := GetSnapshotPromotionDecisions(snapshot)
+	if err != nil {
+		decisions = map[string]PromotionDecision{}
+	}
+
+	targetKey := GetPromotionTargetKey(PromotionTargetEnvironment, environmentName)
+	decision := decisions[targetKey]
+	update(&decision)
+
+	return RecordSnapshotPromotionDecisions(adapterClient, ctx, snapshot, map[string]PromotionDecision{targetKey: decision})
+}
@@ -0,0 +1,178 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitops_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	"github.com/redhat-appstudio/integration-service/gitops"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("Gitops functions for deciding Snapshot promotion per target", func() {
+
+	var (
+		hasSnapshot *applicationapiv1alpha1.Snapshot
+		environment *applicationapiv1alpha1.Environment
+	)
+
+	BeforeEach(func() {
+		hasSnapshot = &applicationapiv1alpha1.Snapshot{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "snapshot-promotion",
+				Namespace: "default",
+			},
+			Spec: applicationapiv1alpha1.SnapshotSpec{
+				Application: "application-sample",
+				Components: []applicationapiv1alpha1.SnapshotComponent{
+					{
+						Name:           "component-sample",
+						ContainerImage: "quay.io/redhat-appstudio/sample-image:latest",
+					},
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, hasSnapshot)).Should(Succeed())
+
+		environment = &applicationapiv1alpha1.Environment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "staging",
+				Namespace: "default",
+				Annotations: map[string]string{
+					gitops.RequiredScenariosAnnotation: "smoke-tests, ,unit-tests",
+				},
+			},
+		}
+	})
+
+	AfterEach(func() {
+		err := k8sClient.Delete(ctx, hasSnapshot)
+		Expect(err == nil || errors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("reads the scenarios required by a promotion target", func() {
+		Expect(gitops.GetPromotionTargetRequiredScenarios(environment)).To(Equal([]string{"smoke-tests", "unit-tests"}))
+
+		environment.Annotations = nil
+		Expect(gitops.GetPromotionTargetRequiredScenarios(environment)).To(BeEmpty())
+	})
+
+	It("promotes to targets requiring scenarios as soon as those passed", func() {
+		requiredScenarios := gitops.GetPromotionTargetRequiredScenarios(environment)
+
+		decision := gitops.CanSnapshotBePromotedToTarget(hasSnapshot, requiredScenarios, map[string]bool{"smoke-tests": true})
+		Expect(decision.Promoted).To(BeFalse())
+		Expect(decision.Reasons).To(Equal([]string{"the Snapshot hasn't passed the required IntegrationTestScenario unit-tests"}))
+
+		decision = gitops.CanSnapshotBePromotedToTarget(hasSnapshot, requiredScenarios, map[string]bool{"smoke-tests": true, "unit-tests": true})
+		Expect(decision.Promoted).To(BeTrue())
+		Expect(decision.Reasons).To(BeNil())
+
+		hasSnapshot.Labels = map[string]string{gitops.PipelineAsCodeEventTypeLabel: gitops.PipelineAsCodePullRequestType}
+		decision = gitops.CanSnapshotBePromotedToTarget(hasSnapshot, requiredScenarios, map[string]bool{"smoke-tests": true, "unit-tests": true})
+		Expect(decision.Promoted).To(BeFalse())
+	})
+
+	It("requires all the required scenarios to pass for targets without the annotation", func() {
+		decision := gitops.CanSnapshotBePromotedToTarget(hasSnapshot, []string{}, nil)
+		Expect(decision.Promoted).To(BeFalse())
+		Expect(decision.Reasons).To(ContainElement("the Snapshot hasn't passed all required integration tests"))
+
+		updatedSnapshot, err := gitops.MarkSnapshotAsPassed(k8sClient, ctx, hasSnapshot, "Test message")
+		Expect(err).NotTo(HaveOccurred())
+		decision = gitops.CanSnapshotBePromotedToTarget(updatedSnapshot, []string{}, nil)
+		Expect(decision.Promoted).To(BeTrue())
+	})
+
+	It("records the promotion decisions on the Snapshot", func() {
+		stagingKey := gitops.GetPromotionTargetKey(gitops.PromotionTargetEnvironment, "staging")
+		prodKey := gitops.GetPromotionTargetKey(gitops.PromotionTargetReleasePlan, "prod")
+
+		err := gitops.RecordSnapshotPromotionDecisions(k8sClient, ctx, hasSnapshot, map[string]gitops.PromotionDecision{
+			stagingKey: {Promoted: true},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		err = gitops.RecordSnapshotPromotionDecisions(k8sClient, ctx, hasSnapshot, map[string]gitops.PromotionDecision{
+			prodKey: {Promoted: false, Reasons: []string{"the Snapshot hasn't passed all required integration tests"}},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		decisions, err := gitops.GetSnapshotPromotionDecisions(hasSnapshot)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(decisions).To(HaveLen(2))
+		Expect(decisions[stagingKey].Promoted).To(BeTrue())
+		Expect(decisions[prodKey].Promoted).To(BeFalse())
+		Expect(hasSnapshot.Annotations[gitops.SnapshotPromotionAnnotation]).To(ContainSubstring(`"Environment/staging":{"promoted":true}`))
+	})
+
+	It("only returns the promotion decisions which differ from the recorded ones", func() {
+		stagingKey := gitops.GetPromotionTargetKey(gitops.PromotionTargetEnvironment, "staging")
+		prodKey := gitops.GetPromotionTargetKey(gitops.PromotionTargetReleasePlan, "prod")
+		deploymentTime := metav1.NewTime(time.Now().UTC().Truncate(time.Second))
+
+		err := gitops.RecordSnapshotPromotionDecisions(k8sClient, ctx, hasSnapshot, map[string]gitops.PromotionDecision{
+			stagingKey: {Promoted: true, Deployed: true, DeploymentTime: &deploymentTime},
+			prodKey:    {Promoted: true},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		// The same deployment time in another location is recorded the same way
+		localDeploymentTime := metav1.NewTime(deploymentTime.In(time.FixedZone("UTC+2", 2*60*60)))
+		changedDecisions := gitops.GetChangedPromotionDecisions(hasSnapshot, map[string]gitops.PromotionDecision{
+			stagingKey: {Promoted: true, Deployed: true, DeploymentTime: &localDeploymentTime},
+			prodKey:    {Promoted: false, Reasons: []string{"the Snapshot hasn't been approved"}},
+		})
+		Expect(changedDecisions).To(HaveLen(1))
+		Expect(changedDecisions).To(HaveKey(prodKey))
+	})
+
+	It("recognizes promotions which were rolled back", func() {
+		stagingKey := gitops.GetPromotionTargetKey(gitops.PromotionTargetEnvironment, "staging")
+		Expect(gitops.HasSnapshotPromotionBeenRolledBack(hasSnapshot, stagingKey)).To(BeFalse())
+
+		err := gitops.RecordSnapshotPromotionDecisions(k8sClient, ctx, hasSnapshot, map[string]gitops.PromotionDecision{
+			stagingKey: {Promoted: false, RolledBack: true, Reasons: []string{"the Snapshot failed to deploy"}},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(gitops.HasSnapshotPromotionBeenRolledBack(hasSnapshot, stagingKey)).To(BeTrue())
+	})
+
+	It("records the progress of the Snapshot along the Environment chain", func() {
+		Expect(gitops.IsSnapshotDeployedToEnvironment(hasSnapshot, "staging")).To(BeFalse())
+		Expect(gitops.GetSnapshotEnvironmentDeploymentTime(hasSnapshot, "staging")).To(BeNil())
+		oldSnapshot := hasSnapshot.DeepCopy()
+
+		err := gitops.MarkSnapshotDeployedToEnvironment(k8sClient, ctx, hasSnapshot, "staging")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(gitops.IsSnapshotDeployedToEnvironment(hasSnapshot, "staging")).To(BeTrue())
+		Expect(gitops.IsSnapshotVerifiedInEnvironment(hasSnapshot, "staging")).To(BeFalse())
+		Expect(gitops.GetSnapshotEnvironmentDeploymentTime(hasSnapshot, "staging").Time).To(BeTemporally("~", time.Now(), time.Minute))
+		Expect(gitops.HasSnapshotBeenVerifiedInNewEnvironment(oldSnapshot, hasSnapshot)).To(BeFalse())
+
+		err = gitops.MarkSnapshotVerifiedInEnvironment(k8sClient, ctx, hasSnapshot, "staging")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(gitops.IsSnapshotVerifiedInEnvironment(hasSnapshot, "staging")).To(BeTrue())
+		Expect(gitops.IsSnapshotDeployedToEnvironment(hasSnapshot, "staging")).To(BeTrue())
+		Expect(gitops.HasSnapshotBeenVerifiedInNewEnvironment(oldSnapshot, hasSnapshot)).To(BeTrue())
+		Expect(gitops.HasSnapshotBeenVerifiedInNewEnvironment(hasSnapshot, hasSnapshot)).To(BeFalse())
+	})
+})
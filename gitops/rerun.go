@@ -0,0 +1,175 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitops
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// SnapshotRerunScenariosAnnotation contains the comma separated names of the IntegrationTestScenarios whose
+	// integration PipelineRuns were requested to be rerun for the Snapshot.
+	SnapshotRerunScenariosAnnotation = "test.appstudio.openshift.io/rerun-scenarios"
+
+	// SnapshotRerunRequestTimeAnnotation contains the time the pending rerun was first requested for the Snapshot,
+	// so the integration PipelineRuns already created for it aren't created again.
+	SnapshotRerunRequestTimeAnnotation = "test.appstudio.openshift.io/rerun-requested-at"
+
+	// AppStudioTestSuceededConditionRetesting is the reason that's set when the AppStudio tests of the Snapshot
+	// are run again.
+	AppStudioTestSuceededConditionRetesting = "Retesting"
+)
+
+// GetRequestedRerunIntegrationTestScenarios returns the names of the IntegrationTestScenarios which were requested
+// to be rerun for the Snapshot.
+func GetRequestedRerunIntegrationTestScenarios(snapshot *applicationapiv1alpha1.Snapshot) []string {
+	rerunScenarios := []string{}
+	for _, scenario := range strings.Split(snapshot.GetAnnotations()[SnapshotRerunScenariosAnnotation], ",") {
+		if scenario = strings.TrimSpace(scenario); scenario != "" {
+			rerunScenarios = append(rerunScenarios, scenario)
+		}
+	}
+
+	return rerunScenarios
+}
+
+// IsSnapshotRerunRequested returns true if a rerun of any IntegrationTestScenario was requested for the Snapshot.
+func IsSnapshotRerunRequested(snapshot *applicationapiv1alpha1.Snapshot) bool {
+	return len(GetRequestedRerunIntegrationTestScenarios(snapshot)) > 0
+}
+
+// GetRerunRequestTime returns the time the pending rerun was first requested for the Snapshot and true, or false
+// if the time wasn't recorded or isn't valid.
+func GetRerunRequestTime(snapshot *applicationapiv1alpha1.Snapshot) (time.Time, bool) {
+	requestTime, err := time.Parse(time.RFC3339, snapshot.GetAnnotations()[SnapshotRerunRequestTimeAnnotation])
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return requestTime, true
+}
+
+// RecordRerunRequestTime records the current time as the time the pending rerun was requested for the Snapshot,
+// unless a valid time was already recorded. If the patch command fails, an error will be returned.
+func RecordRerunRequestTime(adapterClient client.Client, ctx context.Context, snapshot *applicationapiv1alpha1.Snapshot) error {
+	if _, found := GetRerunRequestTime(snapshot); found {
+		return nil
+	}
+
+	patch := client.MergeFrom(snapshot.DeepCopy())
+	if snapshot.Annotations == nil {
+		snapshot.Annotations = map[string]string{}
+	}
+	snapshot.Annotations[SnapshotRerunRequestTimeAnnotation] = time.Now().UTC().Format(time.RFC3339)
+
+	return adapterClient.Patch(ctx, snapshot, patch)
+}
+
+// RequestIntegrationTestScenariosRerun adds the given IntegrationTestScenario names to the rerun annotation of
+// the Snapshot. The time of the request is only recorded if no rerun is pending, so the scenarios already rerun
+// for the pending request aren't rerun again. If the patch command fails, an error will be returned.
+func RequestIntegrationTestScenariosRerun(adapterClient client.Client, ctx context.Context, snapshot *applicationapiv1alpha1.Snapshot, integrationTestScenarioNames []string) error {
+	rerunScenarios := GetRequestedRerunIntegrationTestScenarios(snapshot)
+	requested := map[string]bool{}
+	for _, scenario := range rerunScenarios {
+		requested[scenario] = true
+	}
+	updated := false
+	for _, scenario := range integrationTestScenarioNames {
+		if !requested[scenario] {
+			requested[scenario] = true
+			rerunScenarios = append(rerunScenarios, scenario)
+			updated = true
+		}
+	}
+	if !updated {
+		return nil
+	}
+	sort.Strings(rerunScenarios)
+
+	patch := client.MergeFrom(snapshot.DeepCopy())
+	if snapshot.Annotations == nil {
+		snapshot.Annotations = map[string]string{}
+	}
+	snapshot.Annotations[SnapshotRerunScenariosAnnotation] = strings.Join(rerunScenarios, ",")
+	if _, found := GetRerunRequestTime(snapshot); !found {
+		snapshot.Annotations[SnapshotRerunRequestTimeAnnotation] = time.Now().UTC().Format(time.RFC3339)
+	}
+
+	return adapterClient.Patch(ctx, snapshot, patch)
+}
+
+// ClearRequestedRerun removes the rerun annotations from the Snapshot once the requested integration PipelineRuns
+// were created. If the patch command fails, an error will be returned.
+func ClearRequestedRerun(adapterClient client.Client, ctx context.Context, snapshot *applicationapiv1alpha1.Snapshot) error {
+	_, scenariosFound := snapshot.GetAnnotations()[SnapshotRerunScenariosAnnotation]
+	_, requestTimeFound := snapshot.GetAnnotations()[SnapshotRerunRequestTimeAnnotation]
+	if !scenariosFound && !requestTimeFound {
+		return nil
+	}
+
+	patch := client.MergeFrom(snapshot.DeepCopy())
+	delete(snapshot.Annotations, SnapshotRerunScenariosAnnotation)
+	delete(snapshot.Annotations, SnapshotRerunRequestTimeAnnotation)
+
+	return adapterClient.Patch(ctx, snapshot, patch)
+}
+
+// MarkSnapshotAsRetesting resets the AppStudio Test succeeded condition of the Snapshot and sets its AppStudio
+// integration status condition to In Progress, so the outcome of the rerun integration PipelineRuns is evaluated again.
+// If the patch command fails, an error will be returned.
+func MarkSnapshotAsRetesting(adapterClient client.Client, ctx context.Context, snapshot *applicationapiv1alpha1.Snapshot, message string) error {
+	patch := client.MergeFrom(snapshot.DeepCopy())
+	meta.SetStatusCondition(&snapshot.Status.Conditions, metav1.Condition{
+		Type:    AppStudioTestSuceededCondition,
+		Status:  metav1.ConditionUnknown,
+		Reason:  AppStudioTestSuceededConditionRetesting,
+		Message: message,
+	})
+	meta.SetStatusCondition(&snapshot.Status.Conditions, metav1.Condition{
+		Type:    AppStudioIntegrationStatusCondition,
+		Status:  metav1.ConditionUnknown,
+		Reason:  AppStudioIntegrationStatusInProgress,
+		Message: message,
+	})
+
+	return adapterClient.Status().Patch(ctx, snapshot, patch)
+}
+
+// HasSnapshotRerunBeenRequested returns a boolean that is only true if the second passed object is a Snapshot
+// with a rerun request the first passed object didn't have.
+func HasSnapshotRerunBeenRequested(objectOld, objectNew client.Object) bool {
+	oldSnapshot, ok := objectOld.(*applicationapiv1alpha1.Snapshot)
+	if !ok {
+		return false
+	}
+	newSnapshot, ok := objectNew.(*applicationapiv1alpha1.Snapshot)
+	if !ok {
+		return false
+	}
+
+	return IsSnapshotRerunRequested(newSnapshot) &&
+		oldSnapshot.GetAnnotations()[SnapshotRerunScenariosAnnotation] != newSnapshot.GetAnnotations()[SnapshotRerunScenariosAnnotation]
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitops_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	"github.com/redhat-appstudio/integration-service/gitops"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("Gitops functions for IntegrationTestScenario reruns", func() {
+
+	var hasSnapshot *applicationapiv1alpha1.Snapshot
+
+	BeforeEach(func() {
+		hasSnapshot = &applicationapiv1alpha1.Snapshot{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "snapshot-rerun",
+				Namespace: "default",
+			},
+			Spec: applicationapiv1alpha1.SnapshotSpec{
+				Application: "application-sample",
+				Components: []applicationapiv1alpha1.SnapshotComponent{
+					{
+						Name:           "component-sample",
+						ContainerImage: "quay.io/redhat-appstudio/sample-image:latest",
+					},
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, hasSnapshot)).Should(Succeed())
+	})
+
+	AfterEach(func() {
+		err := k8sClient.Delete(ctx, hasSnapshot)
+		Expect(err == nil || errors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("requests and clears reruns of scenarios for the Snapshot", func() {
+		Expect(gitops.IsSnapshotRerunRequested(hasSnapshot)).To(BeFalse())
+		oldSnapshot := hasSnapshot.DeepCopy()
+
+		err := gitops.RequestIntegrationTestScenariosRerun(k8sClient, ctx, hasSnapshot, []string{"e2e-tests"})
+		Expect(err).NotTo(HaveOccurred())
+		err = gitops.RequestIntegrationTestScenariosRerun(k8sClient, ctx, hasSnapshot, []string{"unit-tests", "e2e-tests"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(hasSnapshot.Annotations[gitops.SnapshotRerunScenariosAnnotation]).To(Equal("e2e-tests,unit-tests"))
+		requestTime, found := gitops.GetRerunRequestTime(hasSnapshot)
+		Expect(found).To(BeTrue())
+		Expect(requestTime).To(BeTemporally("~", time.Now(), time.Minute))
+		Expect(gitops.GetRequestedRerunIntegrationTestScenarios(hasSnapshot)).To(Equal([]string{"e2e-tests", "unit-tests"}))
+		Expect(gitops.HasSnapshotRerunBeenRequested(oldSnapshot, hasSnapshot)).To(BeTrue())
+		Expect(gitops.HasSnapshotRerunBeenRequested(hasSnapshot, hasSnapshot)).To(BeFalse())
+
+		err = gitops.ClearRequestedRerun(k8sClient, ctx, hasSnapshot)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(gitops.IsSnapshotRerunRequested(hasSnapshot)).To(BeFalse())
+		Expect(hasSnapshot.Annotations).NotTo(HaveKey(gitops.SnapshotRerunScenariosAnnotation))
+		Expect(hasSnapshot.Annotations).NotTo(HaveKey(gitops.SnapshotRerunRequestTimeAnnotation))
+	})
+
+	It("keeps the time of the pending rerun request when more scenarios are requested to be rerun", func() {
+		patch := client.MergeFrom(hasSnapshot.DeepCopy())
+		hasSnapshot.Annotations = map[string]string{
+			gitops.SnapshotRerunScenariosAnnotation:   "e2e-tests",
+			gitops.SnapshotRerunRequestTimeAnnotation: "2023-01-02T03:04:05Z",
+		}
+		Expect(k8sClient.Patch(ctx, hasSnapshot, patch)).To(Succeed())
+		Expect(gitops.RequestIntegrationTestScenariosRerun(k8sClient, ctx, hasSnapshot, []string{"unit-tests"})).To(Succeed())
+		Expect(gitops.RecordRerunRequestTime(k8sClient, ctx, hasSnapshot)).To(Succeed())
+		Expect(hasSnapshot.Annotations[gitops.SnapshotRerunRequestTimeAnnotation]).To(Equal("2023-01-02T03:04:05Z"))
+
+		patch = client.MergeFrom(hasSnapshot.DeepCopy())
+		hasSnapshot.Annotations[gitops.SnapshotRerunRequestTimeAnnotation] = "not a time"
+		Expect(k8sClient.Patch(ctx, hasSnapshot, patch)).To(Succeed())
+		_, found := gitops.GetRerunRequestTime(hasSnapshot)
+		Expect(found).To(BeFalse())
+		Expect(gitops.RecordRerunRequestTime(k8sClient, ctx, hasSnapshot)).To(Succeed())
+		_, found = gitops.GetRerunRequestTime(hasSnapshot)
+		Expect(found).To(BeTrue())
+	})
+
+	It("resets the test status of the Snapshot when it's retested", func() {
+		_, err := gitops.MarkSnapshotAsFailed(k8sClient, ctx, hasSnapshot, "Some Integration pipeline tests failed")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(gitops.HaveAppStudioTestsFinished(hasSnapshot)).To(BeTrue())
+
+		err = gitops.MarkSnapshotAsRetesting(k8sClient, ctx, hasSnapshot, "Retesting the Snapshot")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(gitops.HaveAppStudioTestsFinished(hasSnapshot)).To(BeFalse())
+		Expect(gitops.IsSnapshotNotStarted(hasSnapshot)).To(BeFalse())
+	})
+})
@@ -0,0 +1,111 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitops
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	"github.com/redhat-appstudio/integration-service/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// SnapshotSkippedScenariosAnnotation contains the comma separated names of the IntegrationTestScenarios which
+	// were skipped for the Snapshot because they were invalid or one of the IntegrationTestScenarios they depend on failed.
+	SnapshotSkippedScenariosAnnotation = "test.appstudio.openshift.io/skipped-scenarios"
+)
+
+// HasIntegrationTestScenarioDependencies returns true if the IntegrationTestScenario depends on other IntegrationTestScenarios.
+func HasIntegrationTestScenarioDependencies(integrationTestScenario *v1beta1.IntegrationTestScenario) bool {
+	return len(integrationTestScenario.Spec.DependsOn) > 0
+}
+
+// GetDependentIntegrationTestScenarios returns the IntegrationTestScenarios from the given list which depend on the
+// given IntegrationTestScenario, either directly or through other IntegrationTestScenarios.
+func GetDependentIntegrationTestScenarios(integrationTestScenario *v1beta1.IntegrationTestScenario, integrationTestScenarios *[]v1beta1.IntegrationTestScenario) []v1beta1.IntegrationTestScenario {
+	dependentScenarios := []v1beta1.IntegrationTestScenario{}
+	found := map[string]bool{integrationTestScenario.Name: true}
+	queue := []string{integrationTestScenario.Name}
+	for len(queue) > 0 {
+		dependencyName := queue[0]
+		queue = queue[1:]
+		for _, scenario := range *integrationTestScenarios {
+			if found[scenario.Name] {
+				continue
+			}
+			for _, dependency := range scenario.Spec.DependsOn {
+				if dependency == dependencyName {
+					found[scenario.Name] = true
+					dependentScenarios = append(dependentScenarios, scenario)
+					queue = append(queue, scenario.Name)
+					break
+				}
+			}
+		}
+	}
+
+	return dependentScenarios
+}
+
+// GetSkippedIntegrationTestScenarios returns the names of the IntegrationTestScenarios which were skipped for the Snapshot.
+func GetSkippedIntegrationTestScenarios(snapshot *applicationapiv1alpha1.Snapshot) []string {
+	skippedScenarios, found := snapshot.GetAnnotations()[SnapshotSkippedScenariosAnnotation]
+	if !found || skippedScenarios == "" {
+		return []string{}
+	}
+
+	return strings.Split(skippedScenarios, ",")
+}
+
+// IsIntegrationTestScenarioSkipped returns true if the IntegrationTestScenario with the given name was skipped for the Snapshot.
+func IsIntegrationTestScenarioSkipped(snapshot *applicationapiv1alpha1.Snapshot, integrationTestScenarioName string) bool {
+	for _, skippedScenario := range GetSkippedIntegrationTestScenarios(snapshot) {
+		if skippedScenario == integrationTestScenarioName {
+			return true
+		}
+	}
+
+	return false
+}
+
+// MarkIntegrationTestScenariosAsSkipped adds the names of the given IntegrationTestScenarios to the skipped
+// IntegrationTestScenarios annotation of the Snapshot. If the patch command fails, an error will be returned.
+func MarkIntegrationTestScenariosAsSkipped(adapterClient client.Client, ctx context.Context, snapshot *applicationapiv1alpha1.Snapshot, integrationTestScenarios []v1beta1.IntegrationTestScenario) error {
+	skippedScenarios := GetSkippedIntegrationTestScenarios(snapshot)
+	updated := false
+	for _, integrationTestScenario := range integrationTestScenarios {
+		if !IsIntegrationTestScenarioSkipped(snapshot, integrationTestScenario.Name) {
+			skippedScenarios = append(skippedScenarios, integrationTestScenario.Name)
+			updated = true
+		}
+	}
+	if !updated {
+		return nil
+	}
+	sort.Strings(skippedScenarios)
+
+	patch := client.MergeFrom(snapshot.DeepCopy())
+	if snapshot.Annotations == nil {
+		snapshot.Annotations = map[string]string{}
+	}
+	snapshot.Annotations[SnapshotSkippedScenariosAnnotation] = strings.Join(skippedScenarios, ",")
+
+	return adapterClient.Patch(ctx, snapshot, patch)
+}
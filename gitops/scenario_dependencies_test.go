@@ -0,0 +1,106 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitops_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	"github.com/redhat-appstudio/integration-service/api/v1beta1"
+	"github.com/redhat-appstudio/integration-service/gitops"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("Gitops functions for IntegrationTestScenario dependencies", func() {
+
+	var (
+		hasSnapshot              *applicationapiv1alpha1.Snapshot
+		integrationTestScenarios []v1beta1.IntegrationTestScenario
+	)
+
+	newScenario := func(name string, dependsOn ...string) v1beta1.IntegrationTestScenario {
+		return v1beta1.IntegrationTestScenario{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+			},
+			Spec: v1beta1.IntegrationTestScenarioSpec{
+				Application: "application-sample",
+				DependsOn:   dependsOn,
+			},
+		}
+	}
+
+	BeforeEach(func() {
+		integrationTestScenarios = []v1beta1.IntegrationTestScenario{
+			newScenario("smoke-tests"),
+			newScenario("e2e-tests", "smoke-tests"),
+			newScenario("upgrade-tests", "e2e-tests"),
+			newScenario("unit-tests"),
+		}
+
+		hasSnapshot = &applicationapiv1alpha1.Snapshot{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "snapshot-dependencies",
+				Namespace: "default",
+			},
+			Spec: applicationapiv1alpha1.SnapshotSpec{
+				Application: "application-sample",
+				Components: []applicationapiv1alpha1.SnapshotComponent{
+					{
+						Name:           "component-sample",
+						ContainerImage: "quay.io/redhat-appstudio/sample-image:latest",
+					},
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, hasSnapshot)).Should(Succeed())
+	})
+
+	AfterEach(func() {
+		err := k8sClient.Delete(ctx, hasSnapshot)
+		Expect(err == nil || errors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("finds the scenarios depending on a scenario directly or transitively", func() {
+		Expect(gitops.HasIntegrationTestScenarioDependencies(&integrationTestScenarios[0])).To(BeFalse())
+		Expect(gitops.HasIntegrationTestScenarioDependencies(&integrationTestScenarios[1])).To(BeTrue())
+
+		dependentScenarios := gitops.GetDependentIntegrationTestScenarios(&integrationTestScenarios[0], &integrationTestScenarios)
+		Expect(dependentScenarios).To(HaveLen(2))
+		Expect(dependentScenarios[0].Name).To(Equal("e2e-tests"))
+		Expect(dependentScenarios[1].Name).To(Equal("upgrade-tests"))
+
+		Expect(gitops.GetDependentIntegrationTestScenarios(&integrationTestScenarios[3], &integrationTestScenarios)).To(BeEmpty())
+	})
+
+	It("marks scenarios as skipped for the Snapshot", func() {
+		Expect(gitops.GetSkippedIntegrationTestScenarios(hasSnapshot)).To(BeEmpty())
+
+		err := gitops.MarkIntegrationTestScenariosAsSkipped(k8sClient, ctx, hasSnapshot, integrationTestScenarios[1:3])
+		Expect(err).NotTo(HaveOccurred())
+		Expect(hasSnapshot.Annotations[gitops.SnapshotSkippedScenariosAnnotation]).To(Equal("e2e-tests,upgrade-tests"))
+
+		err = gitops.MarkIntegrationTestScenariosAsSkipped(k8sClient, ctx, hasSnapshot, integrationTestScenarios[2:])
+		Expect(err).NotTo(HaveOccurred())
+		Expect(gitops.GetSkippedIntegrationTestScenarios(hasSnapshot)).To(Equal([]string{"e2e-tests", "unit-tests", "upgrade-tests"}))
+		Expect(gitops.IsIntegrationTestScenarioSkipped(hasSnapshot, "unit-tests")).To(BeTrue())
+		Expect(gitops.IsIntegrationTestScenarioSkipped(hasSnapshot, "smoke-tests")).To(BeFalse())
+	})
+})
@@ -0,0 +1,123 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitops
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+
+	"github.com/redhat-appstudio/integration-service/api/v1beta1"
+)
+
+// MatrixCombination is a single combination of the matrix param values of an IntegrationTestScenario.
+type MatrixCombination struct {
+	// Name identifies the combination, it's stable for the same param values and can be used as a label value
+	Name string
+	// Params contains the value of each matrix param in the combination
+	Params []v1beta1.PipelineParameter
+	// Optional is true when the combination is allowed to fail
+	Optional bool
+}
+
+// HasIntegrationTestScenarioMatrix returns true if the IntegrationTestScenario has a matrix with at least one param.
+func HasIntegrationTestScenarioMatrix(integrationTestScenario *v1beta1.IntegrationTestScenario) bool {
+	return integrationTestScenario.Spec.Matrix != nil && len(integrationTestScenario.Spec.Matrix.Params) > 0
+}
+
+// GetIntegrationTestScenarioMatrixCombinations expands the matrix of the IntegrationTestScenario into all the
+// combinations of its param values. The values of the first param change the least often between the returned
+// combinations. If the IntegrationTestScenario doesn't have a matrix, an empty list is returned.
+func GetIntegrationTestScenarioMatrixCombinations(integrationTestScenario *v1beta1.IntegrationTestScenario) []MatrixCombination {
+	if !HasIntegrationTestScenarioMatrix(integrationTestScenario) {
+		return []MatrixCombination{}
+	}
+
+	combinations := [][]v1beta1.PipelineParameter{{}}
+	for _, matrixParam := range integrationTestScenario.Spec.Matrix.Params {
+		var expandedCombinations [][]v1beta1.PipelineParameter
+		for _, combination := range combinations {
+			for _, value := range matrixParam.Values {
+				expandedCombination := make([]v1beta1.PipelineParameter, len(combination), len(combination)+1)
+				copy(expandedCombination, combination)
+				expandedCombination = append(expandedCombination, v1beta1.PipelineParameter{Name: matrixParam.Name, Value: value})
+				expandedCombinations = append(expandedCombinations, expandedCombination)
+			}
+		}
+		combinations = expandedCombinations
+	}
+
+	matrixCombinations := make([]MatrixCombination, 0, len(combinations))
+	for _, params := range combinations {
+		matrixCombinations = append(matrixCombinations, MatrixCombination{
+			Name:     getMatrixCombinationName(params),
+			Params:   params,
+			Optional: isMatrixCombinationOptional(integrationTestScenario.Spec.Matrix, params),
+		})
+	}
+
+	return matrixCombinations
+}
+
+// GetRequiredIntegrationTestScenarioMatrixCombinations returns the matrix combinations of the
+// IntegrationTestScenario which are not allowed to fail.
+func GetRequiredIntegrationTestScenarioMatrixCombinations(integrationTestScenario *v1beta1.IntegrationTestScenario) []MatrixCombination {
+	requiredCombinations := []MatrixCombination{}
+	for _, combination := range GetIntegrationTestScenarioMatrixCombinations(integrationTestScenario) {
+		if !combination.Optional {
+			requiredCombinations = append(requiredCombinations, combination)
+		}
+	}
+
+	return requiredCombinations
+}
+
+// getMatrixCombinationName returns a short hash of the combination params, so it can be used as a label value.
+func getMatrixCombinationName(params []v1beta1.PipelineParameter) string {
+	// We ignore the error here because none should be raised when marshalling the params.
+	paramsString, _ := json.Marshal(params)
+	hash := sha256.Sum256(paramsString)
+
+	return hex.EncodeToString(hash[:])[:10]
+}
+
+// isMatrixCombinationOptional returns true if the combination params contain all the param values of any of the
+// optional combination selectors of the matrix.
+func isMatrixCombinationOptional(matrix *v1beta1.TestMatrix, params []v1beta1.PipelineParameter) bool {
+	combinationValues := map[string]string{}
+	for _, param := range params {
+		combinationValues[param.Name] = param.Value
+	}
+
+	for _, selector := range matrix.Optional {
+		if len(selector.Params) == 0 {
+			continue
+		}
+		matches := true
+		for _, selectorParam := range selector.Params {
+			if value, found := combinationValues[selectorParam.Name]; !found || value != selectorParam.Value {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			return true
+		}
+	}
+
+	return false
+}
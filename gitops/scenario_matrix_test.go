@@ -0,0 +1,105 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitops_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-appstudio/integration-service/api/v1beta1"
+	"github.com/redhat-appstudio/integration-service/gitops"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("Gitops functions for expanding IntegrationTestScenario matrices", func() {
+
+	var integrationTestScenario *v1beta1.IntegrationTestScenario
+
+	BeforeEach(func() {
+		integrationTestScenario = &v1beta1.IntegrationTestScenario{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "example-matrix",
+				Namespace: "default",
+			},
+			Spec: v1beta1.IntegrationTestScenarioSpec{
+				Application: "application-sample",
+				Matrix: &v1beta1.TestMatrix{
+					Params: []v1beta1.MatrixParameter{
+						{
+							Name:   "os",
+							Values: []string{"rhel8", "rhel9"},
+						},
+						{
+							Name:   "arch",
+							Values: []string{"amd64", "arm64", "s390x"},
+						},
+					},
+					Optional: []v1beta1.MatrixCombinationSelector{
+						{
+							Params: []v1beta1.MatrixParameterValue{
+								{Name: "arch", Value: "s390x"},
+							},
+						},
+						{
+							Params: []v1beta1.MatrixParameterValue{
+								{Name: "os", Value: "rhel8"},
+								{Name: "arch", Value: "arm64"},
+							},
+						},
+					},
+				},
+			},
+		}
+	})
+
+	It("doesn't return any combination for scenarios without a matrix", func() {
+		integrationTestScenario.Spec.Matrix = nil
+		Expect(gitops.HasIntegrationTestScenarioMatrix(integrationTestScenario)).To(BeFalse())
+		Expect(gitops.GetIntegrationTestScenarioMatrixCombinations(integrationTestScenario)).To(BeEmpty())
+	})
+
+	It("expands the matrix into all the combinations of its param values", func() {
+		Expect(gitops.HasIntegrationTestScenarioMatrix(integrationTestScenario)).To(BeTrue())
+		combinations := gitops.GetIntegrationTestScenarioMatrixCombinations(integrationTestScenario)
+		Expect(combinations).To(HaveLen(6))
+		Expect(combinations[0].Params).To(Equal([]v1beta1.PipelineParameter{
+			{Name: "os", Value: "rhel8"},
+			{Name: "arch", Value: "amd64"},
+		}))
+		Expect(combinations[5].Params).To(Equal([]v1beta1.PipelineParameter{
+			{Name: "os", Value: "rhel9"},
+			{Name: "arch", Value: "s390x"},
+		}))
+
+		names := map[string]bool{}
+		for _, combination := range combinations {
+			Expect(combination.Name).To(HaveLen(10))
+			names[combination.Name] = true
+		}
+		Expect(names).To(HaveLen(6))
+		Expect(gitops.GetIntegrationTestScenarioMatrixCombinations(integrationTestScenario)[0].Name).To(Equal(combinations[0].Name))
+	})
+
+	It("marks the combinations matching the optional selectors as optional", func() {
+		requiredCombinations := gitops.GetRequiredIntegrationTestScenarioMatrixCombinations(integrationTestScenario)
+		Expect(requiredCombinations).To(HaveLen(3))
+		for _, combination := range requiredCombinations {
+			Expect(combination.Optional).To(BeFalse())
+			Expect(combination.Params).NotTo(ContainElement(v1beta1.PipelineParameter{Name: "arch", Value: "s390x"}))
+		}
+	})
+})
@@ -0,0 +1,203 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitops
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"text/template"
+
+	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	"github.com/redhat-appstudio/integration-service/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ScenarioParamTemplateData contains the data that can be referenced by the templates in the values of the
+// IntegrationTestScenario params. Its fields must match the ones listed in v1beta1.ParamTemplateFields.
+type ScenarioParamTemplateData struct {
+	// Application is the Application being tested
+	Application *applicationapiv1alpha1.Application
+	// Snapshot is the Snapshot being tested
+	Snapshot *applicationapiv1alpha1.Snapshot
+	// Component is the Snapshot component which was built to trigger the testing, empty for composite Snapshots
+	Component ParamTemplateComponent
+	// Components contains all the Snapshot components indexed by their names
+	Components map[string]ParamTemplateComponent
+	// PullRequest contains the pull request details for Snapshots created by pull request events
+	PullRequest ParamTemplatePullRequest
+	// Environment is the Environment the Snapshot is deployed to for testing, if any
+	Environment ParamTemplateEnvironment
+}
+
+// ParamTemplateComponent contains the details of a Snapshot component which can be referenced in param templates.
+type ParamTemplateComponent struct {
+	Name           string
+	ContainerImage string
+	Source         ParamTemplateComponentSource
+}
+
+// ParamTemplateComponentSource contains the git source of a Snapshot component which can be referenced in param templates.
+type ParamTemplateComponentSource struct {
+	URL      string
+	Revision string
+}
+
+// ParamTemplatePullRequest contains the pull request details which can be referenced in param templates.
+type ParamTemplatePullRequest struct {
+	Number       string
+	SHA          string
+	Organization string
+	Repository   string
+}
+
+// ParamTemplateEnvironment contains the Environment details which can be referenced in param templates.
+type ParamTemplateEnvironment struct {
+	Name string
+}
+
+// NewScenarioParamTemplateData prepares the data used to resolve the templates in the IntegrationTestScenario params
+// for the given Snapshot. The component and environment are optional and can be nil.
+func NewScenarioParamTemplateData(application *applicationapiv1alpha1.Application, snapshot *applicationapiv1alpha1.Snapshot,
+	component *applicationapiv1alpha1.Component, environment *applicationapiv1alpha1.Environment) *ScenarioParamTemplateData {
+	data := &ScenarioParamTemplateData{
+		Application: application,
+		Snapshot:    snapshot,
+		Components:  map[string]ParamTemplateComponent{},
+	}
+
+	for _, snapshotComponent := range snapshot.Spec.Components {
+		templateComponent := ParamTemplateComponent{
+			Name:           snapshotComponent.Name,
+			ContainerImage: snapshotComponent.ContainerImage,
+		}
+		if snapshotComponent.Source.GitSource != nil {
+			templateComponent.Source = ParamTemplateComponentSource{
+				URL:      snapshotComponent.Source.GitSource.URL,
+				Revision: snapshotComponent.Source.GitSource.Revision,
+			}
+		}
+		data.Components[snapshotComponent.Name] = templateComponent
+		if component != nil && component.Name == snapshotComponent.Name {
+			data.Component = templateComponent
+		}
+	}
+
+	if IsSnapshotCreatedByPACPullRequestEvent(snapshot) {
+		data.PullRequest = ParamTemplatePullRequest{
+			Number:       snapshot.GetAnnotations()[PipelineAsCodePullRequestAnnotation],
+			SHA:          snapshot.GetLabels()[PipelineAsCodeSHALabel],
+			Organization: snapshot.GetLabels()[PipelineAsCodeURLOrgLabel],
+			Repository:   snapshot.GetLabels()[PipelineAsCodeURLRepositoryLabel],
+		}
+	}
+
+	if environment != nil {
+		data.Environment = ParamTemplateEnvironment{Name: environment.Name}
+	}
+
+	return data
+}
+
+// ResolveIntegrationTestScenarioParams returns a copy of the given IntegrationTestScenario params with the templates
+// in their values resolved using the given data and the values referenced from ConfigMaps loaded from the given
+// namespace. The values referenced from Secrets are never resolved, so they don't end up in the PipelineRun specs;
+// the params keep referencing their Secret keys so they can be mounted into the PipelineRuns instead.
+// If a template can't be resolved or a referenced ConfigMap value can't be found, an error will be returned.
+func ResolveIntegrationTestScenarioParams(adapterClient client.Client, ctx context.Context, namespace string,
+	params []v1beta1.PipelineParameter, data *ScenarioParamTemplateData) ([]v1beta1.PipelineParameter, error) {
+	resolvedParams := []v1beta1.PipelineParameter{}
+	for _, param := range params {
+		resolvedParam := v1beta1.PipelineParameter{Name: param.Name}
+
+		if param.ValueFrom != nil && param.ValueFrom.SecretKeyRef != nil {
+			resolvedParam.ValueFrom = &v1beta1.PipelineParameterSource{SecretKeyRef: param.ValueFrom.SecretKeyRef.DeepCopy()}
+			resolvedParams = append(resolvedParams, resolvedParam)
+			continue
+		}
+
+		if param.ValueFrom != nil {
+			value, err := getParamValueFromConfigMap(adapterClient, ctx, namespace, param.ValueFrom.ConfigMapKeyRef)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get the value of param %s: %w", param.Name, err)
+			}
+			resolvedParam.Value = value
+			resolvedParams = append(resolvedParams, resolvedParam)
+			continue
+		}
+
+		value, err := renderParamTemplate(param.Name, param.Value, data)
+		if err != nil {
+			return nil, err
+		}
+		resolvedParam.Value = value
+		for _, paramValue := range param.Values {
+			value, err := renderParamTemplate(param.Name, paramValue, data)
+			if err != nil {
+				return nil, err
+			}
+			resolvedParam.Values = append(resolvedParam.Values, value)
+		}
+		resolvedParams = append(resolvedParams, resolvedParam)
+	}
+
+	return resolvedParams, nil
+}
+
+// renderParamTemplate resolves the templates in the given param value. Templates referencing missing
+// map keys, e.g. unknown component names, result in an error.
+func renderParamTemplate(name, value string, data *ScenarioParamTemplateData) (string, error) {
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(value)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse the template of param %s: %w", name, err)
+	}
+
+	var rendered bytes.Buffer
+	err = tmpl.Execute(&rendered, data)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve the template of param %s: %w", name, err)
+	}
+
+	return rendered.String(), nil
+}
+
+// getParamValueFromConfigMap loads the value of a param from the ConfigMap key it references.
+// Missing optional references result in an empty value.
+func getParamValueFromConfigMap(adapterClient client.Client, ctx context.Context, namespace string, configMapKeyRef *corev1.ConfigMapKeySelector) (string, error) {
+	if configMapKeyRef == nil {
+		return "", fmt.Errorf("the param doesn't reference any Secret or ConfigMap")
+	}
+
+	optional := configMapKeyRef.Optional != nil && *configMapKeyRef.Optional
+	configMap := &corev1.ConfigMap{}
+	err := adapterClient.Get(ctx, types.NamespacedName{Namespace: namespace, Name: configMapKeyRef.Name}, configMap)
+	if err != nil {
+		if optional && errors.IsNotFound(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	value, found := configMap.Data[configMapKeyRef.Key]
+	if !found && !optional {
+		return "", fmt.Errorf("key %s not found in ConfigMap %s", configMapKeyRef.Key, configMapKeyRef.Name)
+	}
+
+	return value, nil
+}
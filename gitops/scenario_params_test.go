@@ -0,0 +1,219 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitops_test
+
+import (
+	"reflect"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	"github.com/redhat-appstudio/integration-service/api/v1beta1"
+	"github.com/redhat-appstudio/integration-service/gitops"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("Gitops functions for resolving IntegrationTestScenario params", Ordered, func() {
+
+	var (
+		hasApp       *applicationapiv1alpha1.Application
+		hasComp      *applicationapiv1alpha1.Component
+		hasSnapshot  *applicationapiv1alpha1.Snapshot
+		hasSecret    *corev1.Secret
+		hasConfigMap *corev1.ConfigMap
+	)
+	const (
+		namespace       = "default"
+		applicationName = "application-params"
+		componentName   = "component-params"
+		sampleImage     = "quay.io/redhat-appstudio/sample-image:latest"
+		sampleRepoLink  = "https://github.com/devfile-samples/devfile-sample-java-springboot-basic"
+		sampleRevision  = "a2ba645d50e471d5f084b"
+	)
+
+	BeforeAll(func() {
+		hasApp = &applicationapiv1alpha1.Application{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      applicationName,
+				Namespace: namespace,
+			},
+		}
+		hasComp = &applicationapiv1alpha1.Component{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      componentName,
+				Namespace: namespace,
+			},
+		}
+		hasSnapshot = &applicationapiv1alpha1.Snapshot{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "snapshot-params",
+				Namespace: namespace,
+				Labels: map[string]string{
+					gitops.SnapshotTypeLabel:                "component",
+					gitops.PipelineAsCodeEventTypeLabel:     gitops.PipelineAsCodePullRequestType,
+					gitops.PipelineAsCodeSHALabel:           sampleRevision,
+					gitops.PipelineAsCodeURLOrgLabel:        "redhat-appstudio",
+					gitops.PipelineAsCodeURLRepositoryLabel: "integration-service",
+				},
+				Annotations: map[string]string{
+					gitops.PipelineAsCodePullRequestAnnotation: "42",
+				},
+			},
+			Spec: applicationapiv1alpha1.SnapshotSpec{
+				Application: applicationName,
+				Components: []applicationapiv1alpha1.SnapshotComponent{
+					{
+						Name:           componentName,
+						ContainerImage: sampleImage,
+						Source: applicationapiv1alpha1.ComponentSource{
+							ComponentSourceUnion: applicationapiv1alpha1.ComponentSourceUnion{
+								GitSource: &applicationapiv1alpha1.GitSource{
+									URL:      sampleRepoLink,
+									Revision: sampleRevision,
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+
+		hasSecret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "secret-params",
+				Namespace: namespace,
+			},
+			Data: map[string][]byte{"token": []byte("secret-token")},
+		}
+		Expect(k8sClient.Create(ctx, hasSecret)).Should(Succeed())
+
+		hasConfigMap = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "configmap-params",
+				Namespace: namespace,
+			},
+			Data: map[string]string{"url": "https://example.com"},
+		}
+		Expect(k8sClient.Create(ctx, hasConfigMap)).Should(Succeed())
+	})
+
+	AfterAll(func() {
+		Expect(k8sClient.Delete(ctx, hasSecret)).Should(Succeed())
+		Expect(k8sClient.Delete(ctx, hasConfigMap)).Should(Succeed())
+	})
+
+	It("exposes exactly the template fields accepted by the IntegrationTestScenario validation", func() {
+		dataType := reflect.TypeOf(gitops.ScenarioParamTemplateData{})
+		fields := []string{}
+		for i := 0; i < dataType.NumField(); i++ {
+			fields = append(fields, dataType.Field(i).Name)
+		}
+		Expect(fields).To(ConsistOf(v1beta1.ParamTemplateFields))
+	})
+
+	It("prepares the template data for a Snapshot created by a pull request", func() {
+		data := gitops.NewScenarioParamTemplateData(hasApp, hasSnapshot, hasComp, nil)
+		Expect(data.Component.Name).To(Equal(componentName))
+		Expect(data.Component.ContainerImage).To(Equal(sampleImage))
+		Expect(data.Components).To(HaveKey(componentName))
+		Expect(data.Components[componentName].Source.Revision).To(Equal(sampleRevision))
+		Expect(data.PullRequest.Number).To(Equal("42"))
+		Expect(data.PullRequest.Repository).To(Equal("integration-service"))
+		Expect(data.Environment.Name).To(BeEmpty())
+	})
+
+	It("resolves templated params and params referencing ConfigMaps but not the ones referencing Secrets", func() {
+		optional := true
+		params := []v1beta1.PipelineParameter{
+			{
+				Name:  "image",
+				Value: "{{ .Component.ContainerImage }}",
+			},
+			{
+				Name:   "revisions",
+				Values: []string{"{{ .Application.Name }}", "pr-{{ .PullRequest.Number }}"},
+			},
+			{
+				Name: "token",
+				ValueFrom: &v1beta1.PipelineParameterSource{
+					SecretKeyRef: &corev1.SecretKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: hasSecret.Name},
+						Key:                  "token",
+					},
+				},
+			},
+			{
+				Name: "url",
+				ValueFrom: &v1beta1.PipelineParameterSource{
+					ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: hasConfigMap.Name},
+						Key:                  "url",
+					},
+				},
+			},
+			{
+				Name: "optional",
+				ValueFrom: &v1beta1.PipelineParameterSource{
+					SecretKeyRef: &corev1.SecretKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: "missing-secret"},
+						Key:                  "token",
+						Optional:             &optional,
+					},
+				},
+			},
+		}
+
+		data := gitops.NewScenarioParamTemplateData(hasApp, hasSnapshot, hasComp, nil)
+		resolvedParams, err := gitops.ResolveIntegrationTestScenarioParams(k8sClient, ctx, namespace, params, data)
+		Expect(err).To(BeNil())
+		Expect(resolvedParams).To(HaveLen(5))
+		Expect(resolvedParams[0].Value).To(Equal(sampleImage))
+		Expect(resolvedParams[1].Values).To(Equal([]string{applicationName, "pr-42"}))
+		Expect(resolvedParams[2].Value).To(BeEmpty())
+		Expect(resolvedParams[2].ValueFrom.SecretKeyRef.Name).To(Equal(hasSecret.Name))
+		Expect(resolvedParams[2].ValueFrom.SecretKeyRef.Key).To(Equal("token"))
+		Expect(resolvedParams[3].Value).To(Equal("https://example.com"))
+		Expect(resolvedParams[4].Value).To(BeEmpty())
+		Expect(resolvedParams[4].ValueFrom.SecretKeyRef.Name).To(Equal("missing-secret"))
+	})
+
+	It("fails to resolve params referencing unknown components or missing keys", func() {
+		data := gitops.NewScenarioParamTemplateData(hasApp, hasSnapshot, hasComp, nil)
+		_, err := gitops.ResolveIntegrationTestScenarioParams(k8sClient, ctx, namespace, []v1beta1.PipelineParameter{
+			{
+				Name:  "image",
+				Value: `{{ (index .Components "unknown").ContainerImage }}`,
+			},
+		}, data)
+		Expect(err).To(HaveOccurred())
+
+		_, err = gitops.ResolveIntegrationTestScenarioParams(k8sClient, ctx, namespace, []v1beta1.PipelineParameter{
+			{
+				Name: "url",
+				ValueFrom: &v1beta1.PipelineParameterSource{
+					ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: hasConfigMap.Name},
+						Key:                  "missing",
+					},
+				},
+			},
+		}, data)
+		Expect(err).To(HaveOccurred())
+	})
+})
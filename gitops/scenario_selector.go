@@ -0,0 +1,61 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitops
+
+import (
+	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	"github.com/redhat-appstudio/integration-service/api/v1beta1"
+)
+
+// HasIntegrationTestScenarioApplicationSelector returns true if the IntegrationTestScenario selects the Applications
+// it is associated with by their labels instead of by name.
+func HasIntegrationTestScenarioApplicationSelector(integrationTestScenario *v1beta1.IntegrationTestScenario) bool {
+	return integrationTestScenario.Spec.ApplicationSelector != nil
+}
+
+// DoesIntegrationTestScenarioApplyToApplication returns true if the IntegrationTestScenario is associated with the
+// given Application, either by its name or because the labels of the Application match its application selector.
+func DoesIntegrationTestScenarioApplyToApplication(integrationTestScenario *v1beta1.IntegrationTestScenario,
+	application *applicationapiv1alpha1.Application) (bool, error) {
+	if integrationTestScenario.Namespace != application.Namespace {
+		return false, nil
+	}
+	if !HasIntegrationTestScenarioApplicationSelector(integrationTestScenario) {
+		return integrationTestScenario.Spec.Application == application.Name, nil
+	}
+
+	return matchesLabelSelector(integrationTestScenario.Spec.ApplicationSelector, application.GetLabels())
+}
+
+// GetIntegrationTestScenarioApplications returns the Applications from the given list which the IntegrationTestScenario
+// is associated with.
+func GetIntegrationTestScenarioApplications(integrationTestScenario *v1beta1.IntegrationTestScenario,
+	applications *[]applicationapiv1alpha1.Application) ([]applicationapiv1alpha1.Application, error) {
+	scenarioApplications := []applicationapiv1alpha1.Application{}
+	for _, application := range *applications {
+		application := application // G601
+		applies, err := DoesIntegrationTestScenarioApplyToApplication(integrationTestScenario, &application)
+		if err != nil {
+			return nil, err
+		}
+		if applies {
+			scenarioApplications = append(scenarioApplications, application)
+		}
+	}
+
+	return scenarioApplications, nil
+}
@@ -0,0 +1,110 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitops_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	"github.com/redhat-appstudio/integration-service/api/v1beta1"
+	"github.com/redhat-appstudio/integration-service/gitops"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("Gitops functions for IntegrationTestScenarios selecting Applications", func() {
+
+	var (
+		backendApp  *applicationapiv1alpha1.Application
+		frontendApp *applicationapiv1alpha1.Application
+		scenario    *v1beta1.IntegrationTestScenario
+	)
+
+	BeforeEach(func() {
+		backendApp = &applicationapiv1alpha1.Application{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "application-backend",
+				Namespace: "default",
+				Labels: map[string]string{
+					"tier": "backend",
+				},
+			},
+		}
+		frontendApp = &applicationapiv1alpha1.Application{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "application-frontend",
+				Namespace: "default",
+				Labels: map[string]string{
+					"tier": "frontend",
+				},
+			},
+		}
+		scenario = &v1beta1.IntegrationTestScenario{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "example-selecting",
+				Namespace: "default",
+			},
+			Spec: v1beta1.IntegrationTestScenarioSpec{
+				ApplicationSelector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{
+						"tier": "backend",
+					},
+				},
+			},
+		}
+	})
+
+	It("applies a scenario naming an application only to that application", func() {
+		scenario.Spec.ApplicationSelector = nil
+		scenario.Spec.Application = frontendApp.Name
+		Expect(gitops.HasIntegrationTestScenarioApplicationSelector(scenario)).To(BeFalse())
+
+		applies, err := gitops.DoesIntegrationTestScenarioApplyToApplication(scenario, frontendApp)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(applies).To(BeTrue())
+		applies, err = gitops.DoesIntegrationTestScenarioApplyToApplication(scenario, backendApp)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(applies).To(BeFalse())
+	})
+
+	It("applies a scenario selecting applications to the ones in its namespace matching the selector", func() {
+		Expect(gitops.HasIntegrationTestScenarioApplicationSelector(scenario)).To(BeTrue())
+
+		applies, err := gitops.DoesIntegrationTestScenarioApplyToApplication(scenario, backendApp)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(applies).To(BeTrue())
+
+		backendApp.Namespace = "other"
+		applies, err = gitops.DoesIntegrationTestScenarioApplyToApplication(scenario, backendApp)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(applies).To(BeFalse())
+	})
+
+	It("returns the applications selected by the scenario", func() {
+		applications := []applicationapiv1alpha1.Application{*backendApp, *frontendApp}
+		selected, err := gitops.GetIntegrationTestScenarioApplications(scenario, &applications)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(selected).To(HaveLen(1))
+		Expect(selected[0].Name).To(Equal(backendApp.Name))
+
+		scenario.Spec.ApplicationSelector.MatchExpressions = []metav1.LabelSelectorRequirement{
+			{Key: "tier", Operator: "Unknown"},
+		}
+		_, err = gitops.GetIntegrationTestScenarioApplications(scenario, &applications)
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,104 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitops
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	"github.com/redhat-appstudio/integration-service/api/v1beta1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// SnapshotInvalidScenariosAnnotation contains a JSON object mapping the names of the IntegrationTestScenarios
+	// which were skipped for the Snapshot because they were invalid to the reason they were invalid for.
+	SnapshotInvalidScenariosAnnotation = "test.appstudio.openshift.io/invalid-scenarios"
+)
+
+// IsIntegrationTestScenarioInvalid returns true if the IntegrationTestScenario was marked as invalid.
+func IsIntegrationTestScenarioInvalid(integrationTestScenario *v1beta1.IntegrationTestScenario) bool {
+	return meta.IsStatusConditionFalse(integrationTestScenario.Status.Conditions, IntegrationTestScenarioValid)
+}
+
+// GetIntegrationTestScenarioInvalidReason returns the reason the IntegrationTestScenario was marked as invalid for.
+func GetIntegrationTestScenarioInvalidReason(integrationTestScenario *v1beta1.IntegrationTestScenario) string {
+	condition := meta.FindStatusCondition(integrationTestScenario.Status.Conditions, IntegrationTestScenarioValid)
+	if condition == nil {
+		return ""
+	}
+
+	return condition.Message
+}
+
+// GetInvalidIntegrationTestScenarios returns the names of the IntegrationTestScenarios which were skipped for the
+// Snapshot because they were invalid, mapped to the reason they were invalid for.
+func GetInvalidIntegrationTestScenarios(snapshot *applicationapiv1alpha1.Snapshot) (map[string]string, error) {
+	invalidScenarios := map[string]string{}
+	value, found := snapshot.GetAnnotations()[SnapshotInvalidScenariosAnnotation]
+	if !found || value == "" {
+		return invalidScenarios, nil
+	}
+
+	err := json.Unmarshal([]byte(value), &invalidScenarios)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the invalid IntegrationTestScenarios of the Snapshot: %w", err)
+	}
+
+	return invalidScenarios, nil
+}
+
+// MarkIntegrationTestScenarioAsInvalid records the given invalid IntegrationTestScenario and the reason it is invalid for
+// in the invalid IntegrationTestScenarios annotation of the Snapshot and adds it to its skipped IntegrationTestScenarios,
+// so neither it nor the IntegrationTestScenarios depending on it are run for the Snapshot.
+// If the patch command fails, an error will be returned.
+func MarkIntegrationTestScenarioAsInvalid(adapterClient client.Client, ctx context.Context, snapshot *applicationapiv1alpha1.Snapshot, integrationTestScenario *v1beta1.IntegrationTestScenario) error {
+	invalidScenarios, err := GetInvalidIntegrationTestScenarios(snapshot)
+	if err != nil {
+		return err
+	}
+	reason := GetIntegrationTestScenarioInvalidReason(integrationTestScenario)
+	if existingReason, ok := invalidScenarios[integrationTestScenario.Name]; ok && existingReason == reason &&
+		IsIntegrationTestScenarioSkipped(snapshot, integrationTestScenario.Name) {
+		return nil
+	}
+	invalidScenarios[integrationTestScenario.Name] = reason
+	value, err := json.Marshal(invalidScenarios)
+	if err != nil {
+		return err
+	}
+
+	skippedScenarios := GetSkippedIntegrationTestScenarios(snapshot)
+	if !IsIntegrationTestScenarioSkipped(snapshot, integrationTestScenario.Name) {
+		skippedScenarios = append(skippedScenarios, integrationTestScenario.Name)
+		sort.Strings(skippedScenarios)
+	}
+
+	patch := client.MergeFrom(snapshot.DeepCopy())
+	if snapshot.Annotations == nil {
+		snapshot.Annotations = map[string]string{}
+	}
+	snapshot.Annotations[SnapshotInvalidScenariosAnnotation] = string(value)
+	snapshot.Annotations[SnapshotSkippedScenariosAnnotation] = strings.Join(skippedScenarios, ",")
+
+	return adapterClient.Patch(ctx, snapshot, patch)
+}
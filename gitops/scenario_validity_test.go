@@ -0,0 +1,110 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitops_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	"github.com/redhat-appstudio/integration-service/api/v1beta1"
+	"github.com/redhat-appstudio/integration-service/gitops"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("Gitops functions for invalid IntegrationTestScenarios", func() {
+
+	var (
+		hasSnapshot     *applicationapiv1alpha1.Snapshot
+		invalidScenario *v1beta1.IntegrationTestScenario
+	)
+
+	BeforeEach(func() {
+		invalidScenario = &v1beta1.IntegrationTestScenario{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "invalid-tests",
+				Namespace: "default",
+			},
+			Spec: v1beta1.IntegrationTestScenarioSpec{
+				Application: "application-sample",
+			},
+		}
+
+		hasSnapshot = &applicationapiv1alpha1.Snapshot{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "snapshot-invalid-scenarios",
+				Namespace: "default",
+			},
+			Spec: applicationapiv1alpha1.SnapshotSpec{
+				Application: "application-sample",
+				Components: []applicationapiv1alpha1.SnapshotComponent{
+					{
+						Name:           "component-sample",
+						ContainerImage: "quay.io/redhat-appstudio/sample-image:latest",
+					},
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, hasSnapshot)).Should(Succeed())
+	})
+
+	AfterEach(func() {
+		err := k8sClient.Delete(ctx, hasSnapshot)
+		Expect(err == nil || errors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("determines if a scenario is invalid and why", func() {
+		Expect(gitops.IsIntegrationTestScenarioInvalid(invalidScenario)).To(BeFalse())
+		Expect(gitops.GetIntegrationTestScenarioInvalidReason(invalidScenario)).To(BeEmpty())
+
+		meta.SetStatusCondition(&invalidScenario.Status.Conditions, metav1.Condition{
+			Type:    gitops.IntegrationTestScenarioValid,
+			Status:  metav1.ConditionFalse,
+			Reason:  gitops.AppStudioIntegrationStatusInvalid,
+			Message: "Failed to get application for scenario.",
+		})
+		Expect(gitops.IsIntegrationTestScenarioInvalid(invalidScenario)).To(BeTrue())
+		Expect(gitops.GetIntegrationTestScenarioInvalidReason(invalidScenario)).To(Equal("Failed to get application for scenario."))
+	})
+
+	It("records invalid scenarios as skipped on the Snapshot along with the reason", func() {
+		meta.SetStatusCondition(&invalidScenario.Status.Conditions, metav1.Condition{
+			Type:    gitops.IntegrationTestScenarioValid,
+			Status:  metav1.ConditionFalse,
+			Reason:  gitops.AppStudioIntegrationStatusInvalid,
+			Message: "Failed to get application for scenario.",
+		})
+		Expect(gitops.MarkIntegrationTestScenarioAsInvalid(k8sClient, ctx, hasSnapshot, invalidScenario)).To(Succeed())
+
+		Expect(gitops.IsIntegrationTestScenarioSkipped(hasSnapshot, invalidScenario.Name)).To(BeTrue())
+		invalidScenarios, err := gitops.GetInvalidIntegrationTestScenarios(hasSnapshot)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(invalidScenarios).To(Equal(map[string]string{
+			"invalid-tests": "Failed to get application for scenario.",
+		}))
+	})
+
+	It("fails to parse a malformed invalid scenarios annotation", func() {
+		hasSnapshot.Annotations = map[string]string{
+			gitops.SnapshotInvalidScenariosAnnotation: "invalid-tests",
+		}
+		_, err := gitops.GetInvalidIntegrationTestScenarios(hasSnapshot)
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,179 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitops
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	"github.com/redhat-appstudio/integration-service/api/v1beta1"
+	"github.com/redhat-appstudio/integration-service/helpers"
+	"github.com/robfig/cron/v3"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// SnapshotScheduledType is the type of Snapshot which was created from the Global Candidate List
+	// for a scheduled run of an IntegrationTestScenario.
+	SnapshotScheduledType = "scheduled"
+
+	// ScheduledRunInProgress is the result of a scheduled run whose Snapshot is still being tested.
+	ScheduledRunInProgress = "InProgress"
+
+	// ScheduledRunPassed is the result of a scheduled run whose Snapshot passed the IntegrationTestScenario.
+	ScheduledRunPassed = "Passed"
+
+	// ScheduledRunFailed is the result of a scheduled run whose Snapshot failed the IntegrationTestScenario.
+	ScheduledRunFailed = "Failed"
+
+	// MaxScheduledRunsHistory is the number of the most recent scheduled runs kept in the IntegrationTestScenario status.
+	MaxScheduledRunsHistory = 10
+)
+
+// IsIntegrationTestScenarioScheduled returns true if the IntegrationTestScenario is run on a schedule against
+// the Global Candidate List instead of against the Snapshots of new builds.
+func IsIntegrationTestScenarioScheduled(integrationTestScenario *v1beta1.IntegrationTestScenario) bool {
+	return integrationTestScenario.Spec.Schedule != ""
+}
+
+// IsSnapshotScheduled returns true if the Snapshot was created for a scheduled run of an IntegrationTestScenario.
+func IsSnapshotScheduled(snapshot *applicationapiv1alpha1.Snapshot) bool {
+	return helpers.HasLabelWithValue(snapshot, SnapshotTypeLabel, SnapshotScheduledType)
+}
+
+// GetIntegrationTestScenarioSchedule parses the cron expression in the schedule field of the IntegrationTestScenario.
+// If the expression is invalid, an error will be returned.
+func GetIntegrationTestScenarioSchedule(integrationTestScenario *v1beta1.IntegrationTestScenario) (cron.Schedule, error) {
+	schedule, err := cron.ParseStandard(integrationTestScenario.Spec.Schedule)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schedule %q: %w", integrationTestScenario.Spec.Schedule, err)
+	}
+
+	return schedule, nil
+}
+
+// GetNextScheduleTime returns the time of the next scheduled run of the IntegrationTestScenario, counting from its
+// last scheduled run or from its creation if it hasn't been run yet.
+func GetNextScheduleTime(integrationTestScenario *v1beta1.IntegrationTestScenario) (time.Time, error) {
+	schedule, err := GetIntegrationTestScenarioSchedule(integrationTestScenario)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	lastScheduleTime := integrationTestScenario.CreationTimestamp.Time
+	if integrationTestScenario.Status.LastScheduleTime != nil {
+		lastScheduleTime = integrationTestScenario.Status.LastScheduleTime.Time
+	}
+
+	return schedule.Next(lastScheduleTime), nil
+}
+
+// GetIntegrationTestScenariosForSnapshot returns the IntegrationTestScenarios from the given list which test the Snapshot
+// before it is promoted. Snapshots of scheduled runs are only tested by the scheduled IntegrationTestScenario they were
+// created for, while the scheduled IntegrationTestScenarios are left out for all other Snapshots.
+func GetIntegrationTestScenariosForSnapshot(snapshot *applicationapiv1alpha1.Snapshot, integrationTestScenarios *[]v1beta1.IntegrationTestScenario) *[]v1beta1.IntegrationTestScenario {
+	snapshotScenarios := []v1beta1.IntegrationTestScenario{}
+	for _, integrationTestScenario := range *GetPreDeploymentIntegrationTestScenarios(integrationTestScenarios) {
+		integrationTestScenario := integrationTestScenario // G601
+		if IsSnapshotScheduled(snapshot) {
+			if helpers.HasLabelWithValue(snapshot, SnapshotTestScenarioLabel, integrationTestScenario.Name) {
+				snapshotScenarios = append(snapshotScenarios, integrationTestScenario)
+			}
+		} else if !IsIntegrationTestScenarioScheduled(&integrationTestScenario) {
+			snapshotScenarios = append(snapshotScenarios, integrationTestScenario)
+		}
+	}
+
+	return &snapshotScenarios
+}
+
+// GetScheduledSnapshotName returns the name of the Snapshot of the scheduled run of the IntegrationTestScenario
+// due at the given time. The name is the same for every attempt to create the Snapshot of the run, so it's only
+// created once even if recording the run fails.
+func GetScheduledSnapshotName(integrationTestScenario *v1beta1.IntegrationTestScenario, scheduleTime time.Time) string {
+	return fmt.Sprintf("%s-%d", integrationTestScenario.Name, scheduleTime.Unix())
+}
+
+// PrepareScheduledSnapshot prepares a Snapshot of the Global Candidate List of the Application for the scheduled run
+// of the given IntegrationTestScenario due at the given time. In case the Snapshot can't be created, an error will be returned.
+func PrepareScheduledSnapshot(adapterClient client.Client, ctx context.Context, application *applicationapiv1alpha1.Application, applicationComponents *[]applicationapiv1alpha1.Component, integrationTestScenario *v1beta1.IntegrationTestScenario, scheduleTime time.Time) (*applicationapiv1alpha1.Snapshot, error) {
+	snapshot, err := PrepareSnapshot(adapterClient, ctx, application, applicationComponents, nil, "", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot.GenerateName = ""
+	snapshot.Name = GetScheduledSnapshotName(integrationTestScenario, scheduleTime)
+	snapshot.Labels = map[string]string{
+		SnapshotTypeLabel:         SnapshotScheduledType,
+		SnapshotTestScenarioLabel: integrationTestScenario.Name,
+	}
+
+	return snapshot, nil
+}
+
+// RecordScheduledRun records a new scheduled run of the IntegrationTestScenario for the given Snapshot in its status,
+// keeping only the most recent MaxScheduledRunsHistory runs.
+func RecordScheduledRun(integrationTestScenario *v1beta1.IntegrationTestScenario, snapshot *applicationapiv1alpha1.Snapshot, scheduleTime time.Time) {
+	integrationTestScenario.Status.LastScheduleTime = &metav1.Time{Time: scheduleTime}
+	integrationTestScenario.Status.ScheduledRuns = append(integrationTestScenario.Status.ScheduledRuns, v1beta1.ScheduledRun{
+		Snapshot:     snapshot.Name,
+		ScheduleTime: metav1.Time{Time: scheduleTime},
+		Result:       ScheduledRunInProgress,
+	})
+
+	if len(integrationTestScenario.Status.ScheduledRuns) > MaxScheduledRunsHistory {
+		integrationTestScenario.Status.ScheduledRuns =
+			integrationTestScenario.Status.ScheduledRuns[len(integrationTestScenario.Status.ScheduledRuns)-MaxScheduledRunsHistory:]
+	}
+}
+
+// UpdateScheduledRunResults updates the result of the in-progress scheduled runs of the IntegrationTestScenario whose
+// Snapshots finished testing. Returns true if any of the results changed.
+func UpdateScheduledRunResults(integrationTestScenario *v1beta1.IntegrationTestScenario, snapshots *[]applicationapiv1alpha1.Snapshot) bool {
+	changed := false
+	for i, scheduledRun := range integrationTestScenario.Status.ScheduledRuns {
+		if scheduledRun.Result != ScheduledRunInProgress {
+			continue
+		}
+		for _, snapshot := range *snapshots {
+			snapshot := snapshot // G601
+			if snapshot.Name != scheduledRun.Snapshot || !HaveAppStudioTestsFinished(&snapshot) {
+				continue
+			}
+
+			result := ScheduledRunFailed
+			if HaveAppStudioTestsSucceeded(&snapshot) {
+				result = ScheduledRunPassed
+			}
+			completionTime := metav1.Now()
+			if condition := meta.FindStatusCondition(snapshot.Status.Conditions, AppStudioTestSuceededCondition); condition != nil {
+				completionTime = condition.LastTransitionTime
+			}
+
+			integrationTestScenario.Status.ScheduledRuns[i].Result = result
+			integrationTestScenario.Status.ScheduledRuns[i].CompletionTime = &completionTime
+			changed = true
+		}
+	}
+
+	return changed
+}
@@ -0,0 +1,163 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitops_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	"github.com/redhat-appstudio/integration-service/api/v1beta1"
+	"github.com/redhat-appstudio/integration-service/gitops"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+var _ = Describe("Gitops functions for scheduled IntegrationTestScenarios", func() {
+
+	var (
+		hasSnapshot       *applicationapiv1alpha1.Snapshot
+		scheduledSnapshot *applicationapiv1alpha1.Snapshot
+		scheduledScenario *v1beta1.IntegrationTestScenario
+		allScenarios      []v1beta1.IntegrationTestScenario
+	)
+
+	BeforeEach(func() {
+		hasSnapshot = &applicationapiv1alpha1.Snapshot{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "snapshot-component",
+				Namespace: "default",
+				Labels: map[string]string{
+					gitops.SnapshotTypeLabel: gitops.SnapshotComponentType,
+				},
+			},
+		}
+		scheduledSnapshot = &applicationapiv1alpha1.Snapshot{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "snapshot-scheduled",
+				Namespace: "default",
+				Labels: map[string]string{
+					gitops.SnapshotTypeLabel:         gitops.SnapshotScheduledType,
+					gitops.SnapshotTestScenarioLabel: "nightly-tests",
+				},
+				Annotations: map[string]string{},
+			},
+		}
+		scheduledScenario = &v1beta1.IntegrationTestScenario{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              "nightly-tests",
+				CreationTimestamp: metav1.Date(2023, 6, 1, 12, 30, 0, 0, time.UTC),
+			},
+			Spec: v1beta1.IntegrationTestScenarioSpec{
+				Schedule: "0 2 * * *",
+			},
+		}
+		allScenarios = []v1beta1.IntegrationTestScenario{
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "unit-tests"},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "smoke-tests"},
+				Spec: v1beta1.IntegrationTestScenarioSpec{
+					Contexts: []v1beta1.TestContext{{Name: gitops.PostDeploymentContext}},
+				},
+			},
+			*scheduledScenario,
+		}
+	})
+
+	It("runs the scheduled scenarios only against the Snapshots of their scheduled runs", func() {
+		Expect(gitops.IsSnapshotScheduled(hasSnapshot)).To(BeFalse())
+		Expect(gitops.IsSnapshotScheduled(scheduledSnapshot)).To(BeTrue())
+
+		snapshotScenarios := gitops.GetIntegrationTestScenariosForSnapshot(hasSnapshot, &allScenarios)
+		Expect(*snapshotScenarios).To(HaveLen(1))
+		Expect((*snapshotScenarios)[0].Name).To(Equal("unit-tests"))
+
+		snapshotScenarios = gitops.GetIntegrationTestScenariosForSnapshot(scheduledSnapshot, &allScenarios)
+		Expect(*snapshotScenarios).To(HaveLen(1))
+		Expect((*snapshotScenarios)[0].Name).To(Equal("nightly-tests"))
+	})
+
+	It("computes the next scheduled run from the last one", func() {
+		nextScheduleTime, err := gitops.GetNextScheduleTime(scheduledScenario)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(nextScheduleTime).To(Equal(time.Date(2023, 6, 2, 2, 0, 0, 0, time.UTC)))
+
+		scheduledScenario.Status.LastScheduleTime = &metav1.Time{Time: time.Date(2023, 6, 2, 2, 0, 0, 0, time.UTC)}
+		nextScheduleTime, err = gitops.GetNextScheduleTime(scheduledScenario)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(nextScheduleTime).To(Equal(time.Date(2023, 6, 3, 2, 0, 0, 0, time.UTC)))
+
+		scheduledScenario.Spec.Schedule = "every night"
+		_, err = gitops.GetNextScheduleTime(scheduledScenario)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("names the Snapshots of the scheduled runs after the scenario and the time they're due", func() {
+		scheduleTime := time.Date(2023, 6, 2, 2, 0, 0, 0, time.UTC)
+		Expect(gitops.GetScheduledSnapshotName(scheduledScenario, scheduleTime)).To(Equal("nightly-tests-1685671200"))
+		Expect(gitops.GetScheduledSnapshotName(scheduledScenario, scheduleTime)).NotTo(Equal(gitops.GetScheduledSnapshotName(scheduledScenario, scheduleTime.Add(24*time.Hour))))
+	})
+
+	It("keeps the history of the most recent scheduled runs and their results", func() {
+		for i := 0; i < gitops.MaxScheduledRunsHistory+2; i++ {
+			gitops.RecordScheduledRun(scheduledScenario, scheduledSnapshot, time.Now())
+		}
+		Expect(scheduledScenario.Status.ScheduledRuns).To(HaveLen(gitops.MaxScheduledRunsHistory))
+		Expect(scheduledScenario.Status.LastScheduleTime).NotTo(BeNil())
+
+		Expect(gitops.UpdateScheduledRunResults(scheduledScenario, &[]applicationapiv1alpha1.Snapshot{*scheduledSnapshot})).To(BeFalse())
+
+		meta.SetStatusCondition(&scheduledSnapshot.Status.Conditions, metav1.Condition{
+			Type:    gitops.AppStudioTestSuceededCondition,
+			Status:  metav1.ConditionFalse,
+			Reason:  gitops.AppStudioTestSuceededConditionFailed,
+			Message: "Snapshot failed",
+		})
+		Expect(gitops.UpdateScheduledRunResults(scheduledScenario, &[]applicationapiv1alpha1.Snapshot{*scheduledSnapshot})).To(BeTrue())
+		for _, scheduledRun := range scheduledScenario.Status.ScheduledRuns {
+			Expect(scheduledRun.Result).To(Equal(gitops.ScheduledRunFailed))
+			Expect(scheduledRun.CompletionTime).NotTo(BeNil())
+		}
+	})
+
+	It("never promotes the Snapshots of scheduled runs", func() {
+		canBePromoted, reasons := gitops.CanSnapshotBePromotedPerTarget(scheduledSnapshot)
+		Expect(canBePromoted).To(BeFalse())
+		Expect(reasons).To(ContainElement("the Snapshot was created for a scheduled run of an IntegrationTestScenario"))
+	})
+
+	It("filters out the events of scheduled Snapshots which didn't finish testing", func() {
+		finishedSnapshot := scheduledSnapshot.DeepCopy()
+		meta.SetStatusCondition(&finishedSnapshot.Status.Conditions, metav1.Condition{
+			Type:    gitops.AppStudioTestSuceededCondition,
+			Status:  metav1.ConditionTrue,
+			Reason:  gitops.AppStudioTestSuceededConditionPassed,
+			Message: "Snapshot passed",
+		})
+		instance := gitops.ScheduledSnapshotFinishedPredicate()
+
+		Expect(instance.Update(event.UpdateEvent{ObjectOld: scheduledSnapshot, ObjectNew: finishedSnapshot})).To(BeTrue())
+		Expect(instance.Update(event.UpdateEvent{ObjectOld: finishedSnapshot, ObjectNew: finishedSnapshot})).To(BeFalse())
+		Expect(instance.Update(event.UpdateEvent{ObjectOld: hasSnapshot, ObjectNew: hasSnapshot})).To(BeFalse())
+		Expect(instance.Create(event.CreateEvent{Object: scheduledSnapshot})).To(BeFalse())
+	})
+})
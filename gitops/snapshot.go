@@ -4,14 +4,18 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/go-containerregistry/pkg/name"
 	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
 	"github.com/redhat-appstudio/integration-service/helpers"
 	"github.com/redhat-appstudio/integration-service/metrics"
+	"github.com/redhat-appstudio/integration-service/release"
 	"github.com/redhat-appstudio/integration-service/tekton"
+	releasev1alpha1 "github.com/redhat-appstudio/release-service/api/v1alpha1"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -115,6 +119,18 @@ const (
 
 	//AppStudioIntegrationStatusFinished is the reason that's set when the AppStudio tests finish.
 	AppStudioIntegrationStatusFinished = "Finished"
+
+	// AppStudioReleasedCondition is the condition for marking the progress and outcome of the Releases of the Snapshot.
+	AppStudioReleasedCondition = "AppStudioReleased"
+
+	// AppStudioReleasedInProgress is the reason that's set when some Releases of the Snapshot haven't finished yet.
+	AppStudioReleasedInProgress = "InProgress"
+
+	// AppStudioReleasedSucceeded is the reason that's set when all the Releases of the Snapshot succeeded.
+	AppStudioReleasedSucceeded = "Succeeded"
+
+	// AppStudioReleasedFailed is the reason that's set when a Release of the Snapshot failed.
+	AppStudioReleasedFailed = "Failed"
 )
 
 // IntegrationTestScenario test runs status
@@ -251,6 +267,52 @@ func SetSnapshotIntegrationStatusAsFinished(snapshot *applicationapiv1alpha1.Sna
 	meta.SetStatusCondition(&snapshot.Status.Conditions, condition)
 }
 
+// SetSnapshotReleasedCondition sets the AppStudio released condition of the Snapshot from the outcomes of the given
+// Releases, listing the outcome for each ReleasePlan in its message. The condition is in progress while any Release
+// hasn't finished and failed as soon as any Release failed. Returns true if the condition changed.
+func SetSnapshotReleasedCondition(snapshot *applicationapiv1alpha1.Snapshot, releases *[]releasev1alpha1.Release) bool {
+	sortedReleases := make([]releasev1alpha1.Release, len(*releases))
+	copy(sortedReleases, *releases)
+	sort.Slice(sortedReleases, func(i, j int) bool {
+		return sortedReleases[i].Spec.ReleasePlan < sortedReleases[j].Spec.ReleasePlan
+	})
+
+	condition := metav1.Condition{
+		Type:   AppStudioReleasedCondition,
+		Status: metav1.ConditionTrue,
+		Reason: AppStudioReleasedSucceeded,
+	}
+	details := []string{}
+	for _, snapshotRelease := range sortedReleases {
+		snapshotRelease := snapshotRelease // G601
+		outcome, message := release.GetReleaseOutcome(&snapshotRelease)
+		detail := fmt.Sprintf("ReleasePlan %s: Release %s %s", snapshotRelease.Spec.ReleasePlan, snapshotRelease.Name, outcome)
+		if message != "" {
+			detail += " (" + message + ")"
+		}
+		details = append(details, detail)
+
+		switch {
+		case outcome == release.ReleaseOutcomeFailed:
+			condition.Status = metav1.ConditionFalse
+			condition.Reason = AppStudioReleasedFailed
+		case outcome == release.ReleaseOutcomeInProgress && condition.Reason != AppStudioReleasedFailed:
+			condition.Status = metav1.ConditionUnknown
+			condition.Reason = AppStudioReleasedInProgress
+		}
+	}
+	condition.Message = strings.Join(details, "; ")
+
+	existingCondition := meta.FindStatusCondition(snapshot.Status.Conditions, AppStudioReleasedCondition)
+	if existingCondition != nil && existingCondition.Status == condition.Status &&
+		existingCondition.Reason == condition.Reason && existingCondition.Message == condition.Message {
+		return false
+	}
+	meta.SetStatusCondition(&snapshot.Status.Conditions, condition)
+
+	return true
+}
+
 // IsSnapshotNotStarted checks if the AppStudio Integration Status condition is not in progress status.
 func IsSnapshotNotStarted(snapshot *applicationapiv1alpha1.Snapshot) bool {
 	condition := meta.FindStatusCondition(snapshot.Status.Conditions, AppStudioIntegrationStatusCondition)
@@ -337,15 +399,8 @@ func CanSnapshotBePromoted(snapshot *applicationapiv1alpha1.Snapshot) (bool, []s
 		canBePromoted = false
 		reasons = append(reasons, "the Snapshot hasn't passed all required integration tests")
 	}
-	if !IsSnapshotValid(snapshot) {
-		canBePromoted = false
-		reasons = append(reasons, "the Snapshot is invalid")
-	}
-	if IsSnapshotCreatedByPACPullRequestEvent(snapshot) {
-		canBePromoted = false
-		reasons = append(reasons, "the Snapshot was created for a PaC pull request event")
-	}
-	return canBePromoted, reasons
+	canBePromotedPerTarget, targetReasons := CanSnapshotBePromotedPerTarget(snapshot)
+	return canBePromoted && canBePromotedPerTarget, append(reasons, targetReasons...)
 }
 
 // NewSnapshot creates a new snapshot based on the supplied application and components
@@ -396,6 +451,33 @@ func IsSnapshotCreatedByPACPullRequestEvent(snapshot *applicationapiv1alpha1.Sna
 	return helpers.HasLabelWithValue(snapshot, PipelineAsCodeEventTypeLabel, PipelineAsCodePullRequestType)
 }
 
+// IsSnapshotSuperseded returns true if one of the given Snapshots of the same Application and Component was created
+// after the Snapshot, so it is promoted instead of it. Pull request, scheduled and batch Snapshots neither supersede
+// other Snapshots nor get superseded.
+func IsSnapshotSuperseded(snapshot *applicationapiv1alpha1.Snapshot, snapshots *[]applicationapiv1alpha1.Snapshot) bool {
+	if !canSnapshotSupersede(snapshot) {
+		return false
+	}
+
+	componentName := snapshot.GetLabels()[SnapshotComponentLabel]
+	for _, candidate := range *snapshots {
+		candidate := candidate // G601
+		if candidate.Name != snapshot.Name && candidate.Spec.Application == snapshot.Spec.Application &&
+			canSnapshotSupersede(&candidate) && candidate.GetLabels()[SnapshotComponentLabel] == componentName &&
+			snapshot.CreationTimestamp.Before(&candidate.CreationTimestamp) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// canSnapshotSupersede returns true if the Snapshot can supersede or be superseded by the other Snapshots
+// of its Application and Component.
+func canSnapshotSupersede(snapshot *applicationapiv1alpha1.Snapshot) bool {
+	return !IsSnapshotCreatedByPACPullRequestEvent(snapshot) && !IsSnapshotScheduled(snapshot) && !IsSnapshotBatch(snapshot)
+}
+
 // HasSnapshotTestingChangedToFinished returns a boolean indicating whether the Snapshot testing status has
 // changed to finished. If the objects passed to this function are not Snapshots, the function will return false.
 func HasSnapshotTestingChangedToFinished(objectOld, objectNew client.Object) bool {
@@ -408,6 +490,7 @@ func HasSnapshotTestingChangedToFinished(objectOld, objectNew client.Object) boo
 }
 
 // PrepareSnapshot prepares the Snapshot for a given application, components and the updated component (if any).
+// The components other than the updated one which are pinned use their pinned image.
 // In case the Snapshot can't be created, an error will be returned.
 func PrepareSnapshot(adapterClient client.Client, ctx context.Context, application *applicationapiv1alpha1.Application, applicationComponents *[]applicationapiv1alpha1.Component, component *applicationapiv1alpha1.Component, newContainerImage string, newComponentSource *applicationapiv1alpha1.ComponentSource) (*applicationapiv1alpha1.Snapshot, error) {
 	log := log.FromContext(ctx)
@@ -417,12 +500,21 @@ func PrepareSnapshot(adapterClient client.Client, ctx context.Context, applicati
 		containerImage := applicationComponent.Spec.ContainerImage
 
 		var componentSource *applicationapiv1alpha1.ComponentSource
-		if applicationComponent.Name == component.Name {
+		if component != nil && applicationComponent.Name == component.Name {
 			containerImage = newContainerImage
 			componentSource = newComponentSource
 		} else {
 			// Get ComponentSource for the component which is not built in this pipeline
 			componentSource = GetComponentSourceFromComponent(&applicationComponent)
+			// Pinned components are held at their pinned image instead of following the Global Candidate List
+			pinnedImage, err := GetPinnedComponentImage(application, &applicationComponent)
+			if err != nil {
+				return nil, err
+			}
+			if pinnedImage != "" {
+				containerImage = pinnedImage
+				componentSource = applicationComponent.Spec.Source.DeepCopy()
+			}
 		}
 
 		// If containerImage is empty, we have run into a race condition in
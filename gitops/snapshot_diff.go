@@ -0,0 +1,248 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitops
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// SnapshotDiffAnnotation contains the JSON encoded SnapshotDiff of the Snapshot against the previous passing
+	// Snapshot of its Application.
+	SnapshotDiffAnnotation = "test.appstudio.openshift.io/snapshot-diff"
+
+	// ComponentAdded is the change of a Component which is only part of the new Snapshot.
+	ComponentAdded = "added"
+
+	// ComponentRemoved is the change of a Component which is only part of the base Snapshot.
+	ComponentRemoved = "removed"
+
+	// ComponentUpdated is the change of a Component whose image or source differs between the Snapshots.
+	ComponentUpdated = "updated"
+)
+
+// SnapshotDiff describes what changed between a base Snapshot and a newer Snapshot of the same Application.
+type SnapshotDiff struct {
+	// BaseSnapshot is the name of the Snapshot the diff is computed against, empty if there was none
+	BaseSnapshot string `json:"baseSnapshot,omitempty"`
+
+	// Components are the changes of the Components, sorted by their names
+	Components []SnapshotComponentDiff `json:"components"`
+}
+
+// SnapshotComponentDiff describes how a single Component changed between two Snapshots.
+type SnapshotComponentDiff struct {
+	// Name is the name of the Component
+	Name string `json:"name"`
+
+	// Change is one of ComponentAdded, ComponentRemoved or ComponentUpdated
+	Change string `json:"change"`
+
+	// OldImage is the container image of the Component in the base Snapshot
+	OldImage string `json:"oldImage,omitempty"`
+
+	// NewImage is the container image of the Component in the new Snapshot
+	NewImage string `json:"newImage,omitempty"`
+
+	// OldDigest is the digest of the container image of the Component in the base Snapshot
+	OldDigest string `json:"oldDigest,omitempty"`
+
+	// NewDigest is the digest of the container image of the Component in the new Snapshot
+	NewDigest string `json:"newDigest,omitempty"`
+
+	// GitURL is the URL of the git repository the Component is built from
+	GitURL string `json:"gitUrl,omitempty"`
+
+	// OldRevision is the git revision of the Component in the base Snapshot
+	OldRevision string `json:"oldRevision,omitempty"`
+
+	// NewRevision is the git revision of the Component in the new Snapshot
+	NewRevision string `json:"newRevision,omitempty"`
+
+	// CompareURL is a link to the range of commits between the old and the new revision, if the git host is known
+	CompareURL string `json:"compareUrl,omitempty"`
+}
+
+// DiffSnapshots returns the changes of the Components of the Snapshot compared to the base Snapshot. Components which
+// didn't change are left out. A nil base Snapshot reports all the Components of the Snapshot as added.
+func DiffSnapshots(baseSnapshot *applicationapiv1alpha1.Snapshot, snapshot *applicationapiv1alpha1.Snapshot) *SnapshotDiff {
+	diff := &SnapshotDiff{Components: []SnapshotComponentDiff{}}
+	baseComponents := map[string]applicationapiv1alpha1.SnapshotComponent{}
+	if baseSnapshot != nil {
+		diff.BaseSnapshot = baseSnapshot.Name
+		for _, component := range baseSnapshot.Spec.Components {
+			baseComponents[component.Name] = component
+		}
+	}
+
+	components := map[string]bool{}
+	for _, component := range snapshot.Spec.Components {
+		components[component.Name] = true
+		componentDiff := SnapshotComponentDiff{
+			Name:      component.Name,
+			NewImage:  component.ContainerImage,
+			NewDigest: getImageDigest(component.ContainerImage),
+		}
+		if gitSource := component.Source.GitSource; gitSource != nil {
+			componentDiff.GitURL = gitSource.URL
+			componentDiff.NewRevision = gitSource.Revision
+		}
+
+		baseComponent, found := baseComponents[component.Name]
+		if !found {
+			componentDiff.Change = ComponentAdded
+			diff.Components = append(diff.Components, componentDiff)
+			continue
+		}
+
+		componentDiff.OldImage = baseComponent.ContainerImage
+		componentDiff.OldDigest = getImageDigest(baseComponent.ContainerImage)
+		if gitSource := baseComponent.Source.GitSource; gitSource != nil {
+			componentDiff.OldRevision = gitSource.Revision
+			if componentDiff.GitURL == gitSource.URL {
+				componentDiff.CompareURL = GetCommitCompareURL(gitSource.URL, componentDiff.OldRevision, componentDiff.NewRevision)
+			}
+		}
+		if componentDiff.OldImage == componentDiff.NewImage && componentDiff.OldRevision == componentDiff.NewRevision {
+			continue
+		}
+		componentDiff.Change = ComponentUpdated
+		diff.Components = append(diff.Components, componentDiff)
+	}
+
+	for _, baseComponent := range baseComponents {
+		if components[baseComponent.Name] {
+			continue
+		}
+		componentDiff := SnapshotComponentDiff{
+			Name:      baseComponent.Name,
+			Change:    ComponentRemoved,
+			OldImage:  baseComponent.ContainerImage,
+			OldDigest: getImageDigest(baseComponent.ContainerImage),
+		}
+		if gitSource := baseComponent.Source.GitSource; gitSource != nil {
+			componentDiff.GitURL = gitSource.URL
+			componentDiff.OldRevision = gitSource.Revision
+		}
+		diff.Components = append(diff.Components, componentDiff)
+	}
+
+	sort.Slice(diff.Components, func(i, j int) bool {
+		return diff.Components[i].Name < diff.Components[j].Name
+	})
+
+	return diff
+}
+
+// GetCommitCompareURL returns a link to the range of commits between the old and the new revision of the given git
+// repository. An empty string is returned if either revision is missing, they are equal or the git host is not
+// GitHub or GitLab.
+func GetCommitCompareURL(gitURL, oldRevision, newRevision string) string {
+	if oldRevision == "" || newRevision == "" || oldRevision == newRevision {
+		return ""
+	}
+
+	repoURL, err := url.Parse(strings.TrimSuffix(strings.TrimSuffix(gitURL, "/"), ".git"))
+	if err != nil || repoURL.Host == "" {
+		return ""
+	}
+	repoURL.Scheme = "https"
+	repoURL.User = nil
+
+	switch {
+	case repoURL.Host == "github.com":
+		return repoURL.String() + "/compare/" + oldRevision + "..." + newRevision
+	case strings.Contains(repoURL.Host, "gitlab"):
+		return repoURL.String() + "/-/compare/" + oldRevision + "..." + newRevision
+	default:
+		return ""
+	}
+}
+
+// FindPreviousPassingSnapshot returns the most recent Snapshot of the list which passed its tests and was created
+// before the given Snapshot, or nil if there is none. Snapshots created for pull requests are never used as base.
+func FindPreviousPassingSnapshot(allSnapshots *[]applicationapiv1alpha1.Snapshot, snapshot *applicationapiv1alpha1.Snapshot) *applicationapiv1alpha1.Snapshot {
+	var previousSnapshot *applicationapiv1alpha1.Snapshot
+	for i, candidate := range *allSnapshots {
+		if candidate.Name == snapshot.Name || IsSnapshotCreatedByPACPullRequestEvent(&candidate) ||
+			!HaveAppStudioTestsSucceeded(&candidate) || !candidate.CreationTimestamp.Before(&snapshot.CreationTimestamp) {
+			continue
+		}
+		if previousSnapshot == nil || previousSnapshot.CreationTimestamp.Before(&candidate.CreationTimestamp) {
+			previousSnapshot = &(*allSnapshots)[i]
+		}
+	}
+
+	return previousSnapshot
+}
+
+// HasSnapshotDiff returns true if the diff of the Snapshot was already recorded.
+func HasSnapshotDiff(snapshot *applicationapiv1alpha1.Snapshot) bool {
+	_, found := snapshot.GetAnnotations()[SnapshotDiffAnnotation]
+	return found
+}
+
+// GetSnapshotDiff returns the diff recorded in the annotation of the Snapshot, or nil if there is none.
+func GetSnapshotDiff(snapshot *applicationapiv1alpha1.Snapshot) (*SnapshotDiff, error) {
+	value, found := snapshot.GetAnnotations()[SnapshotDiffAnnotation]
+	if !found || value == "" {
+		return nil, nil
+	}
+
+	diff := &SnapshotDiff{}
+	err := json.Unmarshal([]byte(value), diff)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the diff of the Snapshot: %w", err)
+	}
+
+	return diff, nil
+}
+
+// AnnotateSnapshotWithDiff records the given diff in the annotation of the Snapshot.
+// If the patch command fails, an error will be returned.
+func AnnotateSnapshotWithDiff(adapterClient client.Client, ctx context.Context, snapshot *applicationapiv1alpha1.Snapshot, diff *SnapshotDiff) error {
+	value, err := json.Marshal(diff)
+	if err != nil {
+		return err
+	}
+
+	patch := client.MergeFrom(snapshot.DeepCopy())
+	if snapshot.Annotations == nil {
+		snapshot.Annotations = map[string]string{}
+	}
+	snapshot.Annotations[SnapshotDiffAnnotation] = string(value)
+
+	return adapterClient.Patch(ctx, snapshot, patch)
+}
+
+// getImageDigest returns the digest of the given image pullspec, or an empty string if it isn't pinned to one.
+func getImageDigest(image string) string {
+	_, digest, found := strings.Cut(image, "@")
+	if !found {
+		return ""
+	}
+
+	return digest
+}
@@ -0,0 +1,176 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitops_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	"github.com/redhat-appstudio/integration-service/gitops"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("Gitops functions for comparing Snapshots", func() {
+
+	const (
+		apiRepo     = "https://github.com/org/api.git"
+		oldImage    = "quay.io/org/api@sha256:1111111111111111111111111111111111111111111111111111111111111111"
+		newImage    = "quay.io/org/api@sha256:2222222222222222222222222222222222222222222222222222222222222222"
+		uiImage     = "quay.io/org/ui@sha256:3333333333333333333333333333333333333333333333333333333333333333"
+		workerImage = "quay.io/org/worker@sha256:4444444444444444444444444444444444444444444444444444444444444444"
+	)
+
+	var (
+		baseSnapshot *applicationapiv1alpha1.Snapshot
+		snapshot     *applicationapiv1alpha1.Snapshot
+	)
+
+	newSnapshotComponent := func(name, image, gitURL, revision string) applicationapiv1alpha1.SnapshotComponent {
+		return applicationapiv1alpha1.SnapshotComponent{
+			Name:           name,
+			ContainerImage: image,
+			Source: applicationapiv1alpha1.ComponentSource{
+				ComponentSourceUnion: applicationapiv1alpha1.ComponentSourceUnion{
+					GitSource: &applicationapiv1alpha1.GitSource{
+						URL:      gitURL,
+						Revision: revision,
+					},
+				},
+			},
+		}
+	}
+
+	BeforeEach(func() {
+		now := time.Now()
+		baseSnapshot = &applicationapiv1alpha1.Snapshot{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              "snapshot-base",
+				Namespace:         "default",
+				CreationTimestamp: metav1.NewTime(now.Add(-time.Hour)),
+			},
+			Spec: applicationapiv1alpha1.SnapshotSpec{
+				Application: "application-sample",
+				Components: []applicationapiv1alpha1.SnapshotComponent{
+					newSnapshotComponent("api", oldImage, apiRepo, "v1"),
+					newSnapshotComponent("ui", uiImage, "https://github.com/org/ui", "main"),
+					newSnapshotComponent("worker", workerImage, "https://github.com/org/worker", "main"),
+				},
+			},
+		}
+		snapshot = &applicationapiv1alpha1.Snapshot{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              "snapshot-new",
+				Namespace:         "default",
+				CreationTimestamp: metav1.NewTime(now),
+			},
+			Spec: applicationapiv1alpha1.SnapshotSpec{
+				Application: "application-sample",
+				Components: []applicationapiv1alpha1.SnapshotComponent{
+					newSnapshotComponent("api", newImage, apiRepo, "v2"),
+					newSnapshotComponent("ui", uiImage, "https://github.com/org/ui", "main"),
+					newSnapshotComponent("docs", "quay.io/org/docs:latest", "https://gitlab.com/org/docs", "main"),
+				},
+			},
+		}
+	})
+
+	It("reports the added, removed and updated Components", func() {
+		diff := gitops.DiffSnapshots(baseSnapshot, snapshot)
+		Expect(diff.BaseSnapshot).To(Equal(baseSnapshot.Name))
+		Expect(diff.Components).To(HaveLen(3))
+
+		Expect(diff.Components[0].Name).To(Equal("api"))
+		Expect(diff.Components[0].Change).To(Equal(gitops.ComponentUpdated))
+		Expect(diff.Components[0].OldDigest).To(Equal("sha256:1111111111111111111111111111111111111111111111111111111111111111"))
+		Expect(diff.Components[0].NewDigest).To(Equal("sha256:2222222222222222222222222222222222222222222222222222222222222222"))
+		Expect(diff.Components[0].OldRevision).To(Equal("v1"))
+		Expect(diff.Components[0].NewRevision).To(Equal("v2"))
+		Expect(diff.Components[0].CompareURL).To(Equal("https://github.com/org/api/compare/v1...v2"))
+
+		Expect(diff.Components[1].Name).To(Equal("docs"))
+		Expect(diff.Components[1].Change).To(Equal(gitops.ComponentAdded))
+		Expect(diff.Components[1].NewDigest).To(BeEmpty())
+
+		Expect(diff.Components[2].Name).To(Equal("worker"))
+		Expect(diff.Components[2].Change).To(Equal(gitops.ComponentRemoved))
+		Expect(diff.Components[2].OldImage).To(Equal(workerImage))
+	})
+
+	It("reports all the Components as added when there is no base Snapshot", func() {
+		diff := gitops.DiffSnapshots(nil, snapshot)
+		Expect(diff.BaseSnapshot).To(BeEmpty())
+		Expect(diff.Components).To(HaveLen(3))
+		for _, componentDiff := range diff.Components {
+			Expect(componentDiff.Change).To(Equal(gitops.ComponentAdded))
+		}
+	})
+
+	It("builds commit compare links for known git hosts only", func() {
+		Expect(gitops.GetCommitCompareURL("https://github.com/org/api.git", "a", "b")).To(Equal("https://github.com/org/api/compare/a...b"))
+		Expect(gitops.GetCommitCompareURL("https://gitlab.com/group/project/", "a", "b")).To(Equal("https://gitlab.com/group/project/-/compare/a...b"))
+		Expect(gitops.GetCommitCompareURL("https://git.example.com/org/api", "a", "b")).To(BeEmpty())
+		Expect(gitops.GetCommitCompareURL("https://github.com/org/api", "", "b")).To(BeEmpty())
+		Expect(gitops.GetCommitCompareURL("https://github.com/org/api", "a", "a")).To(BeEmpty())
+	})
+
+	It("finds the previous passing Snapshot", func() {
+		passed := metav1.Condition{
+			Type:    gitops.AppStudioTestSuceededCondition,
+			Status:  metav1.ConditionTrue,
+			Reason:  gitops.AppStudioTestSuceededConditionPassed,
+			Message: "test passed",
+		}
+		olderSnapshot := baseSnapshot.DeepCopy()
+		olderSnapshot.Name = "snapshot-older"
+		olderSnapshot.CreationTimestamp = metav1.NewTime(baseSnapshot.CreationTimestamp.Add(-time.Hour))
+		meta.SetStatusCondition(&olderSnapshot.Status.Conditions, passed)
+		pullRequestSnapshot := baseSnapshot.DeepCopy()
+		pullRequestSnapshot.Name = "snapshot-pull-request"
+		pullRequestSnapshot.Labels = map[string]string{gitops.PipelineAsCodeEventTypeLabel: gitops.PipelineAsCodePullRequestType}
+		meta.SetStatusCondition(&pullRequestSnapshot.Status.Conditions, passed)
+
+		allSnapshots := []applicationapiv1alpha1.Snapshot{*olderSnapshot, *baseSnapshot, *pullRequestSnapshot, *snapshot}
+		Expect(gitops.FindPreviousPassingSnapshot(&allSnapshots, snapshot).Name).To(Equal(olderSnapshot.Name))
+
+		meta.SetStatusCondition(&allSnapshots[1].Status.Conditions, passed)
+		Expect(gitops.FindPreviousPassingSnapshot(&allSnapshots, snapshot).Name).To(Equal(baseSnapshot.Name))
+		Expect(gitops.FindPreviousPassingSnapshot(&allSnapshots, olderSnapshot)).To(BeNil())
+	})
+
+	It("reads the diff recorded on the Snapshot", func() {
+		Expect(gitops.HasSnapshotDiff(snapshot)).To(BeFalse())
+		diff, err := gitops.GetSnapshotDiff(snapshot)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(diff).To(BeNil())
+
+		snapshot.Annotations = map[string]string{
+			gitops.SnapshotDiffAnnotation: `{"baseSnapshot":"snapshot-base","components":[{"name":"api","change":"updated"}]}`,
+		}
+		Expect(gitops.HasSnapshotDiff(snapshot)).To(BeTrue())
+		diff, err = gitops.GetSnapshotDiff(snapshot)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(diff.Components[0].Name).To(Equal("api"))
+
+		snapshot.Annotations[gitops.SnapshotDiffAnnotation] = "not json"
+		_, err = gitops.GetSnapshotDiff(snapshot)
+		Expect(err).To(HaveOccurred())
+	})
+})
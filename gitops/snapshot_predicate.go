@@ -1,6 +1,7 @@
 package gitops
 
 import (
+	"github.com/redhat-appstudio/integration-service/helpers"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 )
@@ -23,3 +24,80 @@ func IntegrationSnapshotChangePredicate() predicate.Predicate {
 		},
 	}
 }
+
+// SnapshotPromotionChainAdvancedPredicate returns a predicate which filters out all events except the update events
+// of Snapshots which were verified in a new Environment, making them eligible for promotion to its child Environments.
+func SnapshotPromotionChainAdvancedPredicate() predicate.Predicate {
+	return predicate.Funcs{
+		CreateFunc: func(createEvent event.CreateEvent) bool {
+			return false
+		},
+		DeleteFunc: func(deleteEvent event.DeleteEvent) bool {
+			return false
+		},
+		GenericFunc: func(genericEvent event.GenericEvent) bool {
+			return false
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return HasSnapshotBeenVerifiedInNewEnvironment(e.ObjectOld, e.ObjectNew)
+		},
+	}
+}
+
+// ScheduledSnapshotFinishedPredicate returns a predicate which filters out all events except the update events
+// of Snapshots created for a scheduled run of an IntegrationTestScenario which finished testing.
+func ScheduledSnapshotFinishedPredicate() predicate.Predicate {
+	return predicate.Funcs{
+		CreateFunc: func(createEvent event.CreateEvent) bool {
+			return false
+		},
+		DeleteFunc: func(deleteEvent event.DeleteEvent) bool {
+			return false
+		},
+		GenericFunc: func(genericEvent event.GenericEvent) bool {
+			return false
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return helpers.HasLabelWithValue(e.ObjectNew, SnapshotTypeLabel, SnapshotScheduledType) &&
+				HasSnapshotTestingChangedToFinished(e.ObjectOld, e.ObjectNew)
+		},
+	}
+}
+
+// SnapshotRerunRequestedPredicate returns a predicate which filters out all events except the update events
+// of Snapshots for which a rerun of their IntegrationTestScenarios was requested.
+func SnapshotRerunRequestedPredicate() predicate.Predicate {
+	return predicate.Funcs{
+		CreateFunc: func(createEvent event.CreateEvent) bool {
+			return false
+		},
+		DeleteFunc: func(deleteEvent event.DeleteEvent) bool {
+			return false
+		},
+		GenericFunc: func(genericEvent event.GenericEvent) bool {
+			return false
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return HasSnapshotRerunBeenRequested(e.ObjectOld, e.ObjectNew)
+		},
+	}
+}
+
+// SnapshotApprovedPredicate returns a predicate which filters out all events except the update events
+// of Snapshots whose promotion was approved, making them eligible for promotion to the targets requiring approval.
+func SnapshotApprovedPredicate() predicate.Predicate {
+	return predicate.Funcs{
+		CreateFunc: func(createEvent event.CreateEvent) bool {
+			return false
+		},
+		DeleteFunc: func(deleteEvent event.DeleteEvent) bool {
+			return false
+		},
+		GenericFunc: func(genericEvent event.GenericEvent) bool {
+			return false
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return HasSnapshotBeenApproved(e.ObjectOld, e.ObjectNew)
+		},
+	}
+}
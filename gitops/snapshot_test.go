@@ -8,6 +8,7 @@ import (
 
 	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
 	"github.com/redhat-appstudio/integration-service/gitops"
+	releasev1alpha1 "github.com/redhat-appstudio/release-service/api/v1alpha1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/types"
@@ -194,6 +195,43 @@ var _ = Describe("Gitops functions for managing Snapshots", Ordered, func() {
 		Expect(foundStatusCondition.Reason).To(Equal(gitops.AppStudioIntegrationStatusInProgress))
 	})
 
+	It("ensures the Snapshots released condition mirrors the outcome of its Releases", func() {
+		releases := []releasev1alpha1.Release{
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "release-prod"},
+				Spec:       releasev1alpha1.ReleaseSpec{Snapshot: hasSnapshot.Name, ReleasePlan: "prod"},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "release-stage"},
+				Spec:       releasev1alpha1.ReleaseSpec{Snapshot: hasSnapshot.Name, ReleasePlan: "stage"},
+			},
+		}
+		meta.SetStatusCondition(&releases[1].Status.Conditions, metav1.Condition{
+			Type:   "Released",
+			Status: metav1.ConditionTrue,
+			Reason: "Succeeded",
+		})
+
+		Expect(gitops.SetSnapshotReleasedCondition(hasSnapshot, &releases)).To(BeTrue())
+		condition := meta.FindStatusCondition(hasSnapshot.Status.Conditions, gitops.AppStudioReleasedCondition)
+		Expect(condition.Status).To(Equal(metav1.ConditionUnknown))
+		Expect(condition.Reason).To(Equal(gitops.AppStudioReleasedInProgress))
+		Expect(condition.Message).To(Equal("ReleasePlan prod: Release release-prod InProgress; ReleasePlan stage: Release release-stage Succeeded"))
+		Expect(gitops.SetSnapshotReleasedCondition(hasSnapshot, &releases)).To(BeFalse())
+
+		meta.SetStatusCondition(&releases[0].Status.Conditions, metav1.Condition{
+			Type:    "Released",
+			Status:  metav1.ConditionFalse,
+			Reason:  "Failed",
+			Message: "the release pipeline failed",
+		})
+		Expect(gitops.SetSnapshotReleasedCondition(hasSnapshot, &releases)).To(BeTrue())
+		condition = meta.FindStatusCondition(hasSnapshot.Status.Conditions, gitops.AppStudioReleasedCondition)
+		Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+		Expect(condition.Reason).To(Equal(gitops.AppStudioReleasedFailed))
+		Expect(condition.Message).To(ContainSubstring("ReleasePlan prod: Release release-prod Failed (the release pipeline failed)"))
+	})
+
 	It("ensures the Snapshots can be checked for the AppStudioTestSuceededCondition", func() {
 		checkResult := gitops.HaveAppStudioTestsFinished(hasSnapshot)
 		Expect(checkResult).To(BeFalse())
@@ -312,6 +350,27 @@ var _ = Describe("Gitops functions for managing Snapshots", Ordered, func() {
 		Expect(snapshot.Spec.Components[0].Name).To(Equal(hasComp.Name), "The built component should have been added to the snapshot")
 	})
 
+	It("ensure snapshot is prepared with the pinned images of the other components", func() {
+		imagePullSpec := "quay.io/redhat-appstudio/sample-image@sha256:841328df1b9f8c4087adbdcfec6cc99ac8308805dea83f6d415d6fb8d40227c1"
+		pinnedImage := "quay.io/redhat-appstudio/migration@sha256:841328df1b9f8c4087adbdcfec6cc99ac8308805dea83f6d415d6fb8d40227c1"
+		pinnedComp := hasComp.DeepCopy()
+		pinnedComp.Name = "component-pinned"
+		pinnedComp.Spec.ContainerImage = imagePullSpec
+		pinnedComp.Annotations = map[string]string{gitops.ComponentPinnedImageAnnotation: pinnedImage}
+		allApplicationComponents := &[]applicationapiv1alpha1.Component{*hasComp, *pinnedComp}
+
+		snapshot, err := gitops.PrepareSnapshot(k8sClient, ctx, hasApp, allApplicationComponents, hasComp, imagePullSpec, &hasComp.Spec.Source)
+		Expect(err).To(BeNil())
+		Expect(snapshot.Spec.Components).To(HaveLen(2))
+		Expect(snapshot.Spec.Components[1].Name).To(Equal(pinnedComp.Name))
+		Expect(snapshot.Spec.Components[1].ContainerImage).To(Equal(pinnedImage))
+
+		// The pin doesn't apply to the builds of the pinned component itself
+		snapshot, err = gitops.PrepareSnapshot(k8sClient, ctx, hasApp, allApplicationComponents, pinnedComp, imagePullSpec, &pinnedComp.Spec.Source)
+		Expect(err).To(BeNil())
+		Expect(snapshot.Spec.Components[1].ContainerImage).To(Equal(imagePullSpec))
+	})
+
 	It("Return false when the image url contains invalid digest", func() {
 		imageUrl := "quay.io/redhat-appstudio/sample-image:latest"
 		Expect(gitops.ValidateImageDigest(imageUrl)).NotTo(BeNil())
@@ -335,6 +394,26 @@ var _ = Describe("Gitops functions for managing Snapshots", Ordered, func() {
 		Expect(existingSnapshot.Name).To(Equal(hasSnapshot.Name))
 	})
 
+	It("ensures a Snapshot is superseded only by newer Snapshots of the same Component", func() {
+		newerSnapshot := hasSnapshot.DeepCopy()
+		newerSnapshot.Name = "snapshot-newer"
+		newerSnapshot.CreationTimestamp = metav1.NewTime(hasSnapshot.CreationTimestamp.Add(time.Hour))
+		olderSnapshot := hasSnapshot.DeepCopy()
+		olderSnapshot.Name = "snapshot-older"
+		olderSnapshot.CreationTimestamp = metav1.NewTime(hasSnapshot.CreationTimestamp.Add(-time.Hour))
+		Expect(gitops.IsSnapshotSuperseded(hasSnapshot, &[]applicationapiv1alpha1.Snapshot{*hasSnapshot, *olderSnapshot})).To(BeFalse())
+		Expect(gitops.IsSnapshotSuperseded(hasSnapshot, &[]applicationapiv1alpha1.Snapshot{*hasSnapshot, *newerSnapshot})).To(BeTrue())
+
+		newerSnapshot.Labels = map[string]string{gitops.SnapshotComponentLabel: "other-component"}
+		Expect(gitops.IsSnapshotSuperseded(hasSnapshot, &[]applicationapiv1alpha1.Snapshot{*newerSnapshot})).To(BeFalse())
+
+		newerSnapshot.Labels = map[string]string{
+			gitops.SnapshotComponentLabel:       hasSnapshot.Labels[gitops.SnapshotComponentLabel],
+			gitops.PipelineAsCodeEventTypeLabel: gitops.PipelineAsCodePullRequestType,
+		}
+		Expect(gitops.IsSnapshotSuperseded(hasSnapshot, &[]applicationapiv1alpha1.Snapshot{*newerSnapshot})).To(BeFalse())
+	})
+
 	Context("TestStatus type tests", func() {
 		DescribeTable("Status to string and vice versa",
 			func(st gitops.IntegrationTestStatus, expectedStr string) {
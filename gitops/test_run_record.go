@@ -0,0 +1,214 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitops
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	"github.com/redhat-appstudio/integration-service/api/v1beta1"
+	"github.com/redhat-appstudio/integration-service/helpers"
+	"github.com/redhat-appstudio/integration-service/tekton"
+	tektonv1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/apis"
+)
+
+const (
+	// TestRunRecordApplicationLabel contains the name of the Application the TestRunRecord belongs to.
+	TestRunRecordApplicationLabel = "appstudio.openshift.io/application"
+
+	// TestRunRecordOutcomeLabel contains the outcome of the tests recorded in the TestRunRecord.
+	TestRunRecordOutcomeLabel = "test.appstudio.openshift.io/outcome"
+
+	// TestRunRecordPassed is the outcome of TestRunRecords whose integration PipelineRun passed.
+	TestRunRecordPassed = "Passed"
+
+	// TestRunRecordFailed is the outcome of TestRunRecords whose integration PipelineRun failed.
+	TestRunRecordFailed = "Failed"
+
+	// PipelineRunTestRunRecordedAnnotation marks the integration PipelineRuns whose summary was kept in a TestRunRecord.
+	PipelineRunTestRunRecordedAnnotation = "test.appstudio.openshift.io/test-run-recorded"
+
+	// MaxTestRunRecordsPerApplication is the number of the most recent TestRunRecords kept for each Application,
+	// older ones are deleted when new ones are created.
+	MaxTestRunRecordsPerApplication = 500
+)
+
+// TestRunRecordFilter selects TestRunRecords by their fields, empty fields match all TestRunRecords.
+type TestRunRecordFilter struct {
+	// Scenario is the name of the IntegrationTestScenario which was run
+	Scenario string
+
+	// Snapshot is the name of the Snapshot which was tested
+	Snapshot string
+
+	// Component is the name of a Component of the tested Snapshot
+	Component string
+
+	// Outcome is either TestRunRecordPassed or TestRunRecordFailed
+	Outcome string
+
+	// Since leaves out the TestRunRecords which finished before it
+	Since time.Time
+
+	// Limit is the maximum number of TestRunRecords returned, 0 returns all of them
+	Limit int
+}
+
+// NewTestRunRecord creates the summary of a finished integration PipelineRun, which tested the Snapshot of the
+// Application, from its outcome and the TEST_OUTPUT results of its tasks. The TestRunRecord is named after the
+// PipelineRun, so each PipelineRun is recorded once.
+func NewTestRunRecord(application *applicationapiv1alpha1.Application, snapshot *applicationapiv1alpha1.Snapshot,
+	pipelineRun *tektonv1beta1.PipelineRun, passed bool, taskRuns []*helpers.TaskRun) (*v1beta1.TestRunRecord, error) {
+	scenario := pipelineRun.GetLabels()[tekton.ScenarioNameLabel]
+	outcome := TestRunRecordFailed
+	if passed {
+		outcome = TestRunRecordPassed
+	}
+
+	testRunRecord := &v1beta1.TestRunRecord{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      pipelineRun.Name,
+			Namespace: pipelineRun.Namespace,
+			Labels: map[string]string{
+				TestRunRecordApplicationLabel: application.Name,
+				SnapshotTestScenarioLabel:     scenario,
+				SnapshotLabel:                 snapshot.Name,
+				TestRunRecordOutcomeLabel:     outcome,
+			},
+		},
+		Spec: v1beta1.TestRunRecordSpec{
+			Application:    application.Name,
+			Scenario:       scenario,
+			Snapshot:       snapshot.Name,
+			PipelineRun:    pipelineRun.Name,
+			MatrixParams:   pipelineRun.GetAnnotations()[tekton.MatrixParamsAnnotation],
+			Outcome:        outcome,
+			StartTime:      pipelineRun.Status.StartTime,
+			CompletionTime: pipelineRun.Status.CompletionTime,
+		},
+	}
+	for _, component := range snapshot.Spec.Components {
+		testRunRecord.Spec.Components = append(testRunRecord.Spec.Components, v1beta1.TestRunComponent{
+			Name:           component.Name,
+			ContainerImage: component.ContainerImage,
+		})
+	}
+	if start, completion := pipelineRun.Status.StartTime, pipelineRun.Status.CompletionTime; start != nil && completion != nil {
+		testRunRecord.Spec.Duration = &metav1.Duration{Duration: completion.Sub(start.Time)}
+	}
+
+	failedTasks := []string{}
+	for _, taskRun := range taskRuns {
+		result, err := taskRun.GetTestResult()
+		if err != nil {
+			return nil, err
+		}
+		if result == nil {
+			continue
+		}
+		testRunRecord.Spec.Counts.Successes += result.Successes
+		testRunRecord.Spec.Counts.Failures += result.Failures
+		testRunRecord.Spec.Counts.Warnings += result.Warnings
+		testRunRecord.Spec.Tasks = append(testRunRecord.Spec.Tasks, v1beta1.TestRunTask{
+			Name:   taskRun.GetPipelineTaskName(),
+			Result: result.Result,
+			Note:   result.Note,
+		})
+		if result.Result == helpers.AppStudioTestOutputFailure || result.Result == helpers.AppStudioTestOutputError {
+			failedTasks = append(failedTasks, taskRun.GetPipelineTaskName())
+		}
+	}
+
+	if !passed {
+		if len(failedTasks) > 0 {
+			testRunRecord.Spec.FailureReason = "Failed tasks: " + strings.Join(failedTasks, ", ")
+		} else if condition := pipelineRun.Status.GetCondition(apis.ConditionSucceeded); condition != nil {
+			testRunRecord.Spec.FailureReason = condition.Message
+		}
+	}
+
+	return testRunRecord, nil
+}
+
+// FilterTestRunRecords returns the TestRunRecords matching the filter, the most recently finished first.
+func FilterTestRunRecords(testRunRecords []v1beta1.TestRunRecord, filter TestRunRecordFilter) []v1beta1.TestRunRecord {
+	filteredTestRunRecords := []v1beta1.TestRunRecord{}
+	for _, testRunRecord := range testRunRecords {
+		if filter.Scenario != "" && testRunRecord.Spec.Scenario != filter.Scenario ||
+			filter.Snapshot != "" && testRunRecord.Spec.Snapshot != filter.Snapshot ||
+			filter.Outcome != "" && testRunRecord.Spec.Outcome != filter.Outcome ||
+			!filter.Since.IsZero() && getTestRunRecordCompletionTime(&testRunRecord).Before(filter.Since) {
+			continue
+		}
+		if filter.Component != "" && !hasTestRunRecordComponent(&testRunRecord, filter.Component) {
+			continue
+		}
+		filteredTestRunRecords = append(filteredTestRunRecords, testRunRecord)
+	}
+
+	SortTestRunRecordsByCompletionTime(filteredTestRunRecords)
+	if filter.Limit > 0 && len(filteredTestRunRecords) > filter.Limit {
+		filteredTestRunRecords = filteredTestRunRecords[:filter.Limit]
+	}
+
+	return filteredTestRunRecords
+}
+
+// SortTestRunRecordsByCompletionTime sorts the TestRunRecords, the most recently finished first.
+func SortTestRunRecordsByCompletionTime(testRunRecords []v1beta1.TestRunRecord) {
+	sort.SliceStable(testRunRecords, func(i, j int) bool {
+		return getTestRunRecordCompletionTime(&testRunRecords[j]).Before(getTestRunRecordCompletionTime(&testRunRecords[i]))
+	})
+}
+
+// GetTestRunRecordsToPrune returns the TestRunRecords beyond the given number of the most recently finished ones.
+func GetTestRunRecordsToPrune(testRunRecords []v1beta1.TestRunRecord, maxTestRunRecords int) []v1beta1.TestRunRecord {
+	if len(testRunRecords) <= maxTestRunRecords {
+		return nil
+	}
+
+	sortedTestRunRecords := make([]v1beta1.TestRunRecord, len(testRunRecords))
+	copy(sortedTestRunRecords, testRunRecords)
+	SortTestRunRecordsByCompletionTime(sortedTestRunRecords)
+
+	return sortedTestRunRecords[maxTestRunRecords:]
+}
+
+// getTestRunRecordCompletionTime returns the time the recorded integration PipelineRun finished, falling back to
+// the time the TestRunRecord was created.
+func getTestRunRecordCompletionTime(testRunRecord *v1beta1.TestRunRecord) time.Time {
+	if testRunRecord.Spec.CompletionTime != nil {
+		return testRunRecord.Spec.CompletionTime.Time
+	}
+
+	return testRunRecord.CreationTimestamp.Time
+}
+
+// hasTestRunRecordComponent returns true if the Snapshot tested in the TestRunRecord contains the Component.
+func hasTestRunRecordComponent(testRunRecord *v1beta1.TestRunRecord, componentName string) bool {
+	for _, component := range testRunRecord.Spec.Components {
+		if component.Name == componentName {
+			return true
+		}
+	}
+
+	return false
+}
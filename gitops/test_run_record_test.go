@@ -0,0 +1,148 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitops_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	"github.com/redhat-appstudio/integration-service/api/v1beta1"
+	"github.com/redhat-appstudio/integration-service/gitops"
+	tektonv1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/apis"
+)
+
+var _ = Describe("Gitops functions for recording the test history of Applications", func() {
+
+	var (
+		application *applicationapiv1alpha1.Application
+		snapshot    *applicationapiv1alpha1.Snapshot
+		pipelineRun *tektonv1beta1.PipelineRun
+		now         time.Time
+	)
+
+	newTestRunRecord := func(name, scenario, component, outcome string, completedAgo time.Duration) v1beta1.TestRunRecord {
+		return v1beta1.TestRunRecord{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+			},
+			Spec: v1beta1.TestRunRecordSpec{
+				Application:    application.Name,
+				Scenario:       scenario,
+				Snapshot:       "snapshot-" + name,
+				PipelineRun:    name,
+				Components:     []v1beta1.TestRunComponent{{Name: component, ContainerImage: "quay.io/org/" + component}},
+				Outcome:        outcome,
+				CompletionTime: &metav1.Time{Time: now.Add(-completedAgo)},
+			},
+		}
+	}
+
+	BeforeEach(func() {
+		now = time.Now()
+		application = &applicationapiv1alpha1.Application{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "application-sample",
+				Namespace: "default",
+			},
+		}
+		snapshot = &applicationapiv1alpha1.Snapshot{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "snapshot-sample",
+				Namespace: "default",
+			},
+			Spec: applicationapiv1alpha1.SnapshotSpec{
+				Application: application.Name,
+				Components: []applicationapiv1alpha1.SnapshotComponent{
+					{Name: "api", ContainerImage: "quay.io/org/api@sha256:1111"},
+				},
+			},
+		}
+		pipelineRun = &tektonv1beta1.PipelineRun{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "example-pass-x7k2p",
+				Namespace: "default",
+				Labels: map[string]string{
+					"test.appstudio.openshift.io/scenario": "example-pass",
+				},
+			},
+			Status: tektonv1beta1.PipelineRunStatus{
+				PipelineRunStatusFields: tektonv1beta1.PipelineRunStatusFields{
+					StartTime:      &metav1.Time{Time: now.Add(-10 * time.Minute)},
+					CompletionTime: &metav1.Time{Time: now},
+				},
+			},
+		}
+		pipelineRun.Status.SetCondition(&apis.Condition{
+			Type:    apis.ConditionSucceeded,
+			Status:  "False",
+			Reason:  "Failed",
+			Message: "Tasks Completed: 1 (Failed: 1, Cancelled 0), Skipped: 0",
+		})
+	})
+
+	It("ensures the summary of a PipelineRun is recorded", func() {
+		testRunRecord, err := gitops.NewTestRunRecord(application, snapshot, pipelineRun, false, nil)
+		Expect(err).To(BeNil())
+		Expect(testRunRecord.Name).To(Equal(pipelineRun.Name))
+		Expect(testRunRecord.Labels).To(HaveKeyWithValue(gitops.TestRunRecordApplicationLabel, application.Name))
+		Expect(testRunRecord.Labels).To(HaveKeyWithValue(gitops.TestRunRecordOutcomeLabel, gitops.TestRunRecordFailed))
+		Expect(testRunRecord.Spec.Scenario).To(Equal("example-pass"))
+		Expect(testRunRecord.Spec.Snapshot).To(Equal(snapshot.Name))
+		Expect(testRunRecord.Spec.Components).To(Equal([]v1beta1.TestRunComponent{
+			{Name: "api", ContainerImage: "quay.io/org/api@sha256:1111"},
+		}))
+		Expect(testRunRecord.Spec.Duration.Duration).To(Equal(10 * time.Minute))
+		Expect(testRunRecord.Spec.FailureReason).To(Equal("Tasks Completed: 1 (Failed: 1, Cancelled 0), Skipped: 0"))
+	})
+
+	It("ensures TestRunRecords are filtered and sorted by their completion time", func() {
+		testRunRecords := []v1beta1.TestRunRecord{
+			newTestRunRecord("run-1", "example-pass", "api", gitops.TestRunRecordPassed, 3*time.Hour),
+			newTestRunRecord("run-2", "example-pass", "ui", gitops.TestRunRecordFailed, 2*time.Hour),
+			newTestRunRecord("run-3", "example-fail", "api", gitops.TestRunRecordFailed, time.Hour),
+			newTestRunRecord("run-4", "example-pass", "api", gitops.TestRunRecordPassed, time.Minute),
+		}
+
+		getNames := func(testRunRecords []v1beta1.TestRunRecord) []string {
+			names := []string{}
+			for _, testRunRecord := range testRunRecords {
+				names = append(names, testRunRecord.Name)
+			}
+			return names
+		}
+
+		Expect(getNames(gitops.FilterTestRunRecords(testRunRecords, gitops.TestRunRecordFilter{}))).To(
+			Equal([]string{"run-4", "run-3", "run-2", "run-1"}))
+		Expect(getNames(gitops.FilterTestRunRecords(testRunRecords, gitops.TestRunRecordFilter{Scenario: "example-pass"}))).To(
+			Equal([]string{"run-4", "run-2", "run-1"}))
+		Expect(getNames(gitops.FilterTestRunRecords(testRunRecords, gitops.TestRunRecordFilter{Component: "api", Outcome: gitops.TestRunRecordFailed}))).To(
+			Equal([]string{"run-3"}))
+		Expect(getNames(gitops.FilterTestRunRecords(testRunRecords, gitops.TestRunRecordFilter{Since: now.Add(-90 * time.Minute)}))).To(
+			Equal([]string{"run-4", "run-3"}))
+		Expect(getNames(gitops.FilterTestRunRecords(testRunRecords, gitops.TestRunRecordFilter{Limit: 1}))).To(
+			Equal([]string{"run-4"}))
+
+		Expect(getNames(gitops.GetTestRunRecordsToPrune(testRunRecords, 2))).To(Equal([]string{"run-2", "run-1"}))
+		Expect(gitops.GetTestRunRecordsToPrune(testRunRecords, 4)).To(BeEmpty())
+	})
+})
@@ -1,6 +1,7 @@
 package helpers
 
 import (
+	"sort"
 	"strings"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -95,3 +96,21 @@ func CopyAnnotationsByPrefix(src, dest *metav1.ObjectMeta, prefix, replacementPr
 	}
 	copyWithNewPrefix(src.GetAnnotations(), dest.GetAnnotations(), prefix, replacementPrefix)
 }
+
+// GetChangedLabelKeys returns the keys of the labels which were added, removed or changed between the old and the new object.
+func GetChangedLabelKeys(oldObject, newObject client.Object) []string {
+	changedKeys := []string{}
+	for key, value := range oldObject.GetLabels() {
+		if newValue, found := newObject.GetLabels()[key]; !found || newValue != value {
+			changedKeys = append(changedKeys, key)
+		}
+	}
+	for key := range newObject.GetLabels() {
+		if _, found := oldObject.GetLabels()[key]; !found {
+			changedKeys = append(changedKeys, key)
+		}
+	}
+	sort.Strings(changedKeys)
+
+	return changedKeys
+}
@@ -191,5 +191,21 @@ var _ = Describe("Helpers for labels and annotation", Ordered, func() {
 			Expect(testpipelineLabel.ObjectMeta.Labels["test/test"]).To(Equal("test"))
 			Expect(testpipelineLabel.ObjectMeta.Labels["test/test2"]).To(Equal("test2"))
 		})
+		It("GetChangedLabelKeys returns the added, removed and changed labels", func() {
+			testpipelineLabel.ObjectMeta.Labels = map[string]string{
+				"test/unchanged": "test",
+				"test/changed":   "old",
+				"test/removed":   "test",
+			}
+			newPipelineLabel := testpipelineLabel.DeepCopy()
+			newPipelineLabel.ObjectMeta.Labels = map[string]string{
+				"test/unchanged": "test",
+				"test/changed":   "new",
+				"test/added":     "test",
+			}
+			Expect(helpers.GetChangedLabelKeys(testpipelineLabel, newPipelineLabel)).
+				To(Equal([]string{"test/added", "test/changed", "test/removed"}))
+			Expect(helpers.GetChangedLabelKeys(testpipelineLabel, testpipelineLabel.DeepCopy())).To(BeEmpty())
+		})
 	})
 })
@@ -21,6 +21,7 @@ import (
 
 	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
 	"github.com/redhat-appstudio/integration-service/api/v1beta1"
+	"github.com/redhat-appstudio/integration-service/tekton"
 	tektonv1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	"knative.dev/pkg/apis"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -35,10 +36,30 @@ func GetLatestPipelineRunForSnapshotAndScenario(adapterClient client.Client, ctx
 		return nil, err
 	}
 
-	var latestIntegrationPipelineRun = &tektonv1beta1.PipelineRun{}
-	latestIntegrationPipelineRun = nil
+	return getLatestFinishedPipelineRun(integrationPipelineRuns, ""), nil
+}
+
+// GetLatestPipelineRunForSnapshotScenarioAndMatrixCombination returns the latest Integration PipelineRun for the
+// associated Snapshot, IntegrationTestScenario and the matrix combination with the given name.
+// In the case the List operation fails, an error will be returned.
+func GetLatestPipelineRunForSnapshotScenarioAndMatrixCombination(adapterClient client.Client, ctx context.Context, loader ObjectLoader, snapshot *applicationapiv1alpha1.Snapshot, integrationTestScenario *v1beta1.IntegrationTestScenario, matrixCombinationName string) (*tektonv1beta1.PipelineRun, error) {
+	integrationPipelineRuns, err := loader.GetAllPipelineRunsForSnapshotAndScenario(adapterClient, ctx, snapshot, integrationTestScenario)
+	if err != nil {
+		return nil, err
+	}
+
+	return getLatestFinishedPipelineRun(integrationPipelineRuns, matrixCombinationName), nil
+}
+
+// getLatestFinishedPipelineRun returns the finished PipelineRun with the latest completion time, only considering
+// the PipelineRuns labeled with the given matrix combination name if it's not empty.
+func getLatestFinishedPipelineRun(integrationPipelineRuns *[]tektonv1beta1.PipelineRun, matrixCombinationName string) *tektonv1beta1.PipelineRun {
+	var latestIntegrationPipelineRun *tektonv1beta1.PipelineRun
 	for _, pipelineRun := range *integrationPipelineRuns {
 		pipelineRun := pipelineRun // G601
+		if matrixCombinationName != "" && pipelineRun.Labels[tekton.MatrixCombinationLabel] != matrixCombinationName {
+			continue
+		}
 		if !pipelineRun.Status.GetCondition(apis.ConditionSucceeded).IsUnknown() {
 			if latestIntegrationPipelineRun == nil {
 				latestIntegrationPipelineRun = &pipelineRun
@@ -49,9 +70,6 @@ func GetLatestPipelineRunForSnapshotAndScenario(adapterClient client.Client, ctx
 			}
 		}
 	}
-	if latestIntegrationPipelineRun != nil {
-		return latestIntegrationPipelineRun, nil
-	}
 
-	return nil, err
+	return latestIntegrationPipelineRun
 }
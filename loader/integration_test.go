@@ -174,6 +174,7 @@ var _ = Describe("Pipeline Adapter", Ordered, func() {
 					"pac.test.appstudio.openshift.io/repository":      "build-service-pac",
 					"appstudio.openshift.io/snapshot":                 "snapshot-sample",
 					"test.appstudio.openshift.io/scenario":            integrationTestScenario.Name,
+					"test.appstudio.openshift.io/matrix-combination":  "combination-1",
 				},
 
 				Annotations: map[string]string{
@@ -299,4 +300,15 @@ var _ = Describe("Pipeline Adapter", Ordered, func() {
 		Expect(pipelineRun.Name == integrationPipelineRun2.Name).To(BeTrue())
 		Expect(err).To(BeNil())
 	})
+
+	It("can fetch latest pipelineRun for snapshot, scenario and matrix combination", func() {
+		pipelineRun, err := GetLatestPipelineRunForSnapshotScenarioAndMatrixCombination(k8sClient, ctx, loader, hasSnapshot, integrationTestScenario, "combination-1")
+		Expect(err).To(BeNil())
+		Expect(pipelineRun).NotTo(BeNil())
+		Expect(pipelineRun.Name).To(Equal(integrationPipelineRun1.Name))
+
+		pipelineRun, err = GetLatestPipelineRunForSnapshotScenarioAndMatrixCombination(k8sClient, ctx, loader, hasSnapshot, integrationTestScenario, "combination-2")
+		Expect(err).To(BeNil())
+		Expect(pipelineRun).To(BeNil())
+	})
 })
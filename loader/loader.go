@@ -20,13 +20,17 @@ package loader
 import (
 	"context"
 	"fmt"
+	"strings"
 
+	pacv1alpha1 "github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
 	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
 	"github.com/redhat-appstudio/integration-service/api/v1beta1"
+	"github.com/redhat-appstudio/integration-service/cache"
 	"github.com/redhat-appstudio/integration-service/gitops"
 	"github.com/redhat-appstudio/integration-service/tekton"
 	releasev1alpha1 "github.com/redhat-appstudio/release-service/api/v1alpha1"
 	tektonv1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/selection"
@@ -46,16 +50,23 @@ type ObjectLoader interface {
 	GetApplicationFromComponent(c client.Client, ctx context.Context, component *applicationapiv1alpha1.Component) (*applicationapiv1alpha1.Application, error)
 	GetEnvironmentFromIntegrationPipelineRun(c client.Client, ctx context.Context, pipelineRun *tektonv1beta1.PipelineRun) (*applicationapiv1alpha1.Environment, error)
 	GetSnapshotFromPipelineRun(c client.Client, ctx context.Context, pipelineRun *tektonv1beta1.PipelineRun) (*applicationapiv1alpha1.Snapshot, error)
+	GetScenarioFromPipelineRun(c client.Client, ctx context.Context, pipelineRun *tektonv1beta1.PipelineRun) (*v1beta1.IntegrationTestScenario, error)
 	FindAvailableDeploymentTargetClass(c client.Client, ctx context.Context) (*applicationapiv1alpha1.DeploymentTargetClass, error)
 	GetAllIntegrationTestScenariosForApplication(c client.Client, ctx context.Context, application *applicationapiv1alpha1.Application) (*[]v1beta1.IntegrationTestScenario, error)
 	GetRequiredIntegrationTestScenariosForApplication(c client.Client, ctx context.Context, application *applicationapiv1alpha1.Application) (*[]v1beta1.IntegrationTestScenario, error)
 	GetDeploymentTargetClaimForEnvironment(c client.Client, ctx context.Context, environment *applicationapiv1alpha1.Environment) (*applicationapiv1alpha1.DeploymentTargetClaim, error)
 	GetDeploymentTargetForDeploymentTargetClaim(c client.Client, ctx context.Context, dtc *applicationapiv1alpha1.DeploymentTargetClaim) (*applicationapiv1alpha1.DeploymentTarget, error)
 	FindExistingSnapshotEnvironmentBinding(c client.Client, ctx context.Context, application *applicationapiv1alpha1.Application, environment *applicationapiv1alpha1.Environment) (*applicationapiv1alpha1.SnapshotEnvironmentBinding, error)
+	GetAllSnapshotEnvironmentBindingsForScenario(c client.Client, ctx context.Context, snapshot *applicationapiv1alpha1.Snapshot, integrationTestScenario *v1beta1.IntegrationTestScenario) (*[]applicationapiv1alpha1.SnapshotEnvironmentBinding, error)
 	GetAllPipelineRunsForSnapshotAndScenario(c client.Client, ctx context.Context, snapshot *applicationapiv1alpha1.Snapshot, integrationTestScenario *v1beta1.IntegrationTestScenario) (*[]tektonv1beta1.PipelineRun, error)
+	GetAllVerificationPipelineRunsForSnapshotAndEnvironment(c client.Client, ctx context.Context, snapshot *applicationapiv1alpha1.Snapshot, environment *applicationapiv1alpha1.Environment) (*[]tektonv1beta1.PipelineRun, error)
 	GetAllBuildPipelineRunsForComponent(c client.Client, ctx context.Context, component *applicationapiv1alpha1.Component) (*[]tektonv1beta1.PipelineRun, error)
 	GetAllSnapshots(c client.Client, ctx context.Context, application *applicationapiv1alpha1.Application) (*[]applicationapiv1alpha1.Snapshot, error)
+	GetSnapshot(c client.Client, ctx context.Context, name, namespace string) (*applicationapiv1alpha1.Snapshot, error)
+	GetAllSnapshotsForPullRequest(c client.Client, ctx context.Context, namespace, owner, repository, pullRequest string) (*[]applicationapiv1alpha1.Snapshot, error)
 	GetAutoReleasePlansForApplication(c client.Client, ctx context.Context, application *applicationapiv1alpha1.Application) (*[]releasev1alpha1.ReleasePlan, error)
+	GetAllTestRunRecordsForApplication(c client.Client, ctx context.Context, application *applicationapiv1alpha1.Application) (*[]v1beta1.TestRunRecord, error)
+	GetAllRepositoriesForURL(c client.Client, ctx context.Context, url string) (*[]pacv1alpha1.Repository, error)
 }
 
 type loader struct{}
@@ -274,46 +285,148 @@ func (l *loader) GetSnapshotFromPipelineRun(c client.Client, ctx context.Context
 	return nil, fmt.Errorf("the pipeline has no snapshot associated with it")
 }
 
+// GetScenarioFromPipelineRun loads from the cluster the IntegrationTestScenario referenced in the given PipelineRun.
+// If the PipelineRun doesn't specify an IntegrationTestScenario, nil is returned. If the IntegrationTestScenario is not
+// found in the cluster, e.g. because it is inherited from a ClusterIntegrationTestScenario, an error will be returned.
+func (l *loader) GetScenarioFromPipelineRun(c client.Client, ctx context.Context, pipelineRun *tektonv1beta1.PipelineRun) (*v1beta1.IntegrationTestScenario, error) {
+	scenarioName, found := pipelineRun.Labels[tekton.ScenarioNameLabel]
+	if !found {
+		return nil, nil
+	}
+
+	integrationTestScenario := &v1beta1.IntegrationTestScenario{}
+	err := c.Get(ctx, types.NamespacedName{
+		Namespace: pipelineRun.Namespace,
+		Name:      scenarioName,
+	}, integrationTestScenario)
+	if err != nil {
+		return nil, err
+	}
+
+	return integrationTestScenario, nil
+}
+
 // GetAllIntegrationTestScenariosForApplication returns all IntegrationTestScenarios used by the application being processed.
 func (l *loader) GetAllIntegrationTestScenariosForApplication(c client.Client, ctx context.Context, application *applicationapiv1alpha1.Application) (*[]v1beta1.IntegrationTestScenario, error) {
-	integrationList := &v1beta1.IntegrationTestScenarioList{}
-
-	opts := &client.ListOptions{
-		Namespace:     application.Namespace,
-		FieldSelector: fields.OneTermEqualSelector("spec.application", application.Name),
+	scenarios, err := listIntegrationTestScenariosForApplication(c, ctx, application, nil)
+	if err != nil {
+		return nil, err
 	}
 
-	err := c.List(ctx, integrationList, opts)
+	inheritedScenarios, err := getInheritedIntegrationTestScenarios(c, ctx, application, &scenarios)
 	if err != nil {
 		return nil, err
 	}
+	integrationTestScenarios := append(scenarios, inheritedScenarios...)
 
-	return &integrationList.Items, nil
+	return &integrationTestScenarios, nil
 }
 
 // GetRequiredIntegrationTestScenariosForApplication returns the IntegrationTestScenarios used by the application being processed.
 // An IntegrationTestScenarios will only be returned if it has the test.appstudio.openshift.io/optional
-// label not set to true or if it is missing the label entirely.
+// label not set to true or if it is missing the label entirely, and if it isn't quarantined.
 func (l *loader) GetRequiredIntegrationTestScenariosForApplication(c client.Client, ctx context.Context, application *applicationapiv1alpha1.Application) (*[]v1beta1.IntegrationTestScenario, error) {
-	integrationList := &v1beta1.IntegrationTestScenarioList{}
 	labelRequirement, err := labels.NewRequirement("test.appstudio.openshift.io/optional", selection.NotIn, []string{"true"})
 	if err != nil {
 		return nil, err
 	}
 	labelSelector := labels.NewSelector().Add(*labelRequirement)
 
-	opts := &client.ListOptions{
+	requiredScenarios, err := listIntegrationTestScenariosForApplication(c, ctx, application, labelSelector)
+	if err != nil {
+		return nil, err
+	}
+
+	// The IntegrationTestScenarios shadowing the inherited ones are looked up among all the IntegrationTestScenarios
+	// of the Application, an optional one shadows a required inherited IntegrationTestScenario as well
+	allScenarios, err := listIntegrationTestScenariosForApplication(c, ctx, application, nil)
+	if err != nil {
+		return nil, err
+	}
+	inheritedScenarios, err := getInheritedIntegrationTestScenarios(c, ctx, application, &allScenarios)
+	if err != nil {
+		return nil, err
+	}
+	candidateScenarios := requiredScenarios
+	for _, inheritedScenario := range inheritedScenarios {
+		if labelSelector.Matches(labels.Set(inheritedScenario.GetLabels())) {
+			candidateScenarios = append(candidateScenarios, inheritedScenario)
+		}
+	}
+
+	// Quarantined IntegrationTestScenarios are treated like optional ones
+	integrationTestScenarios := []v1beta1.IntegrationTestScenario{}
+	for _, scenario := range candidateScenarios {
+		if !gitops.IsIntegrationTestScenarioQuarantined(&scenario) {
+			integrationTestScenarios = append(integrationTestScenarios, scenario)
+		}
+	}
+
+	return &integrationTestScenarios, nil
+}
+
+// listIntegrationTestScenariosForApplication returns the IntegrationTestScenarios in the namespace of the Application
+// which either name it or select it by its labels, optionally filtered by the given label selector. Both lookups go
+// through the spec.application field index, the selecting IntegrationTestScenarios being indexed under
+// cache.ApplicationSelectorIndexValue.
+func listIntegrationTestScenariosForApplication(c client.Client, ctx context.Context, application *applicationapiv1alpha1.Application,
+	labelSelector labels.Selector) ([]v1beta1.IntegrationTestScenario, error) {
+	integrationList := &v1beta1.IntegrationTestScenarioList{}
+	err := c.List(ctx, integrationList, &client.ListOptions{
 		Namespace:     application.Namespace,
 		FieldSelector: fields.OneTermEqualSelector("spec.application", application.Name),
 		LabelSelector: labelSelector,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	selectingIntegrationList := &v1beta1.IntegrationTestScenarioList{}
+	err = c.List(ctx, selectingIntegrationList, &client.ListOptions{
+		Namespace:     application.Namespace,
+		FieldSelector: fields.OneTermEqualSelector("spec.application", cache.ApplicationSelectorIndexValue),
+		LabelSelector: labelSelector,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	integrationTestScenarios := integrationList.Items
+	for _, scenario := range selectingIntegrationList.Items {
+		scenario := scenario // G601
+		applies, err := gitops.DoesIntegrationTestScenarioApplyToApplication(&scenario, application)
+		if err != nil {
+			// A selector the webhook let through but which can't be parsed matches nothing
+			continue
+		}
+		if applies {
+			integrationTestScenarios = append(integrationTestScenarios, scenario)
+		}
+	}
+
+	return integrationTestScenarios, nil
+}
+
+// getInheritedIntegrationTestScenarios returns the IntegrationTestScenarios the Application inherits from the
+// ClusterIntegrationTestScenarios recording it in their status, leaving out the ones shadowed by the given
+// IntegrationTestScenarios of the Application.
+func getInheritedIntegrationTestScenarios(c client.Client, ctx context.Context, application *applicationapiv1alpha1.Application,
+	existingScenarios *[]v1beta1.IntegrationTestScenario) ([]v1beta1.IntegrationTestScenario, error) {
+	clusterScenarios := &v1beta1.ClusterIntegrationTestScenarioList{}
+	opts := []client.ListOption{
+		client.MatchingFields{"status.applications": gitops.GetInheritingApplicationName(application)},
 	}
 
-	err = c.List(ctx, integrationList, opts)
+	err := c.List(ctx, clusterScenarios, opts...)
 	if err != nil {
+		// Clusters without the ClusterIntegrationTestScenario CRD have no scenarios to inherit
+		if meta.IsNoMatchError(err) {
+			return []v1beta1.IntegrationTestScenario{}, nil
+		}
 		return nil, err
 	}
 
-	return &integrationList.Items, nil
+	return gitops.GetInheritedIntegrationTestScenarios(application, &clusterScenarios.Items, existingScenarios)
 }
 
 // FindAvailableDeploymentTargetClass attempts to find a DeploymentTargetClass with applicationapiv1alpha1.Provisioner_Devsandbox as provisioner.
@@ -400,6 +513,33 @@ func (l *loader) FindExistingSnapshotEnvironmentBinding(c client.Client, ctx con
 	return nil, nil
 }
 
+// GetAllSnapshotEnvironmentBindingsForScenario returns all SnapshotEnvironmentBindings created to deploy the
+// given Snapshot to the ephemeral environments of the given IntegrationTestScenario. In the case the List
+// operation fails, an error will be returned.
+func (l *loader) GetAllSnapshotEnvironmentBindingsForScenario(c client.Client, ctx context.Context, snapshot *applicationapiv1alpha1.Snapshot, integrationTestScenario *v1beta1.IntegrationTestScenario) (*[]applicationapiv1alpha1.SnapshotEnvironmentBinding, error) {
+	snapshotEnvironmentBindingList := &applicationapiv1alpha1.SnapshotEnvironmentBindingList{}
+	opts := []client.ListOption{
+		client.InNamespace(snapshot.Namespace),
+		client.MatchingLabels{
+			gitops.SnapshotTestScenarioLabel: integrationTestScenario.Name,
+		},
+	}
+
+	err := c.List(ctx, snapshotEnvironmentBindingList, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshotEnvironmentBindings := []applicationapiv1alpha1.SnapshotEnvironmentBinding{}
+	for _, binding := range snapshotEnvironmentBindingList.Items {
+		if binding.Spec.Snapshot == snapshot.Name {
+			snapshotEnvironmentBindings = append(snapshotEnvironmentBindings, binding)
+		}
+	}
+
+	return &snapshotEnvironmentBindings, nil
+}
+
 // GetAllPipelineRunsForSnapshotAndScenario returns all Integration PipelineRun for the
 // associated Snapshot and IntegrationTestScenario. In the case the List operation fails,
 // an error will be returned.
@@ -421,6 +561,26 @@ func (l *loader) GetAllPipelineRunsForSnapshotAndScenario(adapterClient client.C
 	return &integrationPipelineRuns.Items, nil
 }
 
+// GetAllVerificationPipelineRunsForSnapshotAndEnvironment returns all post-deployment verification PipelineRuns
+// for the associated Snapshot and Environment. In the case the List operation fails, an error will be returned.
+func (l *loader) GetAllVerificationPipelineRunsForSnapshotAndEnvironment(adapterClient client.Client, ctx context.Context, snapshot *applicationapiv1alpha1.Snapshot, environment *applicationapiv1alpha1.Environment) (*[]tektonv1beta1.PipelineRun, error) {
+	verificationPipelineRuns := &tektonv1beta1.PipelineRunList{}
+	opts := []client.ListOption{
+		client.InNamespace(snapshot.Namespace),
+		client.MatchingLabels{
+			"pipelines.appstudio.openshift.io/type": "verification",
+			"appstudio.openshift.io/snapshot":       snapshot.Name,
+			"appstudio.openshift.io/environment":    environment.Name,
+		},
+	}
+
+	err := adapterClient.List(ctx, verificationPipelineRuns, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &verificationPipelineRuns.Items, nil
+}
+
 // GetAllBuildPipelineRunsForComponent returns all PipelineRun for the
 // associated component. In the case the List operation fails,
 // an error will be returned.
@@ -458,6 +618,85 @@ func (l *loader) GetAllSnapshots(c client.Client, ctx context.Context, applicati
 	return &snapshots.Items, nil
 }
 
+// GetSnapshot loads from the cluster the Snapshot with the given name and namespace.
+// If the Snapshot is not found in the cluster, an error will be returned.
+func (l *loader) GetSnapshot(c client.Client, ctx context.Context, name, namespace string) (*applicationapiv1alpha1.Snapshot, error) {
+	snapshot := &applicationapiv1alpha1.Snapshot{}
+	err := c.Get(ctx, types.NamespacedName{
+		Namespace: namespace,
+		Name:      name,
+	}, snapshot)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return snapshot, nil
+}
+
+// GetAllSnapshotsForPullRequest returns all Snapshots in the namespace created for the given pull request of the
+// owner's repository by Pipelines as Code. In the case the List operation fails, an error will be returned.
+func (l *loader) GetAllSnapshotsForPullRequest(c client.Client, ctx context.Context, namespace, owner, repository, pullRequest string) (*[]applicationapiv1alpha1.Snapshot, error) {
+	snapshots := &applicationapiv1alpha1.SnapshotList{}
+	opts := []client.ListOption{
+		client.InNamespace(namespace),
+		client.MatchingLabels{
+			gitops.PipelineAsCodeEventTypeLabel:     gitops.PipelineAsCodePullRequestType,
+			gitops.PipelineAsCodeURLOrgLabel:        owner,
+			gitops.PipelineAsCodeURLRepositoryLabel: repository,
+		},
+	}
+
+	err := c.List(ctx, snapshots, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	pullRequestSnapshots := []applicationapiv1alpha1.Snapshot{}
+	for _, snapshot := range snapshots.Items {
+		if snapshot.GetAnnotations()[gitops.PipelineAsCodePullRequestAnnotation] == pullRequest {
+			pullRequestSnapshots = append(pullRequestSnapshots, snapshot)
+		}
+	}
+
+	return &pullRequestSnapshots, nil
+}
+
+// GetAllRepositoriesForURL returns the Pipelines as Code Repositories of all namespaces bound to the git repository
+// with the given URL. In the case the List operation fails, an error will be returned.
+func (l *loader) GetAllRepositoriesForURL(c client.Client, ctx context.Context, url string) (*[]pacv1alpha1.Repository, error) {
+	repositories := &pacv1alpha1.RepositoryList{}
+	err := c.List(ctx, repositories)
+	if err != nil {
+		return nil, err
+	}
+
+	matchingRepositories := []pacv1alpha1.Repository{}
+	for _, repository := range repositories.Items {
+		if strings.EqualFold(strings.TrimSuffix(repository.Spec.URL, "/"), strings.TrimSuffix(url, "/")) {
+			matchingRepositories = append(matchingRepositories, repository)
+		}
+	}
+
+	return &matchingRepositories, nil
+}
+
+// GetAllTestRunRecordsForApplication returns all the TestRunRecords kept for the Application, in no particular order.
+func (l *loader) GetAllTestRunRecordsForApplication(c client.Client, ctx context.Context, application *applicationapiv1alpha1.Application) (*[]v1beta1.TestRunRecord, error) {
+	testRunRecords := &v1beta1.TestRunRecordList{}
+	opts := []client.ListOption{
+		client.InNamespace(application.Namespace),
+		client.MatchingLabels{gitops.TestRunRecordApplicationLabel: application.Name},
+	}
+
+	err := c.List(ctx, testRunRecords, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &testRunRecords.Items, nil
+}
+
 // GetAutoReleasePlansForApplication returns the ReleasePlans used by the application being processed. If matching
 // ReleasePlans are not found, an error will be returned. A ReleasePlan will only be returned if it has the
 // release.appstudio.openshift.io/auto-release label set to true or if it is missing the label entirely.
@@ -19,6 +19,7 @@ package loader
 import (
 	"context"
 
+	pacv1alpha1 "github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
 	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
 	"github.com/redhat-appstudio/integration-service/api/v1beta1"
 	releasev1alpha1 "github.com/redhat-appstudio/release-service/api/v1alpha1"
@@ -57,6 +58,9 @@ const (
 	RequiredIntegrationTestScenariosContextKey contextKey = iota
 	AllSnapshotsContextKey                     contextKey = iota
 	AutoReleasePlansContextKey                 contextKey = iota
+	ScenarioBindingsContextKey                 contextKey = iota
+	TestRunRecordsContextKey                   contextKey = iota
+	RepositoriesContextKey                     contextKey = iota
 )
 
 func GetMockedContext(ctx context.Context, data []MockData) context.Context {
@@ -181,6 +185,14 @@ func (l *mockLoader) GetEnvironmentFromIntegrationPipelineRun(c client.Client, c
 	return getMockedResourceAndErrorFromContext(ctx, EnvironmentContextKey, &applicationapiv1alpha1.Environment{})
 }
 
+// GetScenarioFromPipelineRun returns the resource and error passed as values of the context.
+func (l *mockLoader) GetScenarioFromPipelineRun(c client.Client, ctx context.Context, pipelineRun *tektonv1beta1.PipelineRun) (*v1beta1.IntegrationTestScenario, error) {
+	if ctx.Value(IntegrationTestScenarioContextKey) == nil {
+		return l.loader.GetScenarioFromPipelineRun(c, ctx, pipelineRun)
+	}
+	return getMockedResourceAndErrorFromContext(ctx, IntegrationTestScenarioContextKey, &v1beta1.IntegrationTestScenario{})
+}
+
 // GetSnapshotFromPipelineRun returns the resource and error passed as values of the context.
 func (l *mockLoader) GetSnapshotFromPipelineRun(c client.Client, ctx context.Context, pipelineRun *tektonv1beta1.PipelineRun) (*applicationapiv1alpha1.Snapshot, error) {
 	if ctx.Value(SnapshotContextKey) == nil {
@@ -248,6 +260,15 @@ func (l *mockLoader) GetAllPipelineRunsForSnapshotAndScenario(c client.Client, c
 	return &pipelineRuns, err
 }
 
+// GetAllVerificationPipelineRunsForSnapshotAndEnvironment returns the resource and error passed as values of the context.
+func (l *mockLoader) GetAllVerificationPipelineRunsForSnapshotAndEnvironment(c client.Client, ctx context.Context, snapshot *applicationapiv1alpha1.Snapshot, environment *applicationapiv1alpha1.Environment) (*[]tektonv1beta1.PipelineRun, error) {
+	if ctx.Value(PipelineRunsContextKey) == nil {
+		return l.loader.GetAllVerificationPipelineRunsForSnapshotAndEnvironment(c, ctx, snapshot, environment)
+	}
+	pipelineRuns, err := getMockedResourceAndErrorFromContext(ctx, PipelineRunsContextKey, []tektonv1beta1.PipelineRun{})
+	return &pipelineRuns, err
+}
+
 // GetAllBuildPipelineRunsForComponent returns the resource and error passed as values of the context.
 func (l *mockLoader) GetAllBuildPipelineRunsForComponent(c client.Client, ctx context.Context, component *applicationapiv1alpha1.Component) (*[]tektonv1beta1.PipelineRun, error) {
 	if ctx.Value(PipelineRunsContextKey) == nil {
@@ -266,6 +287,23 @@ func (l *mockLoader) GetAllSnapshots(c client.Client, ctx context.Context, appli
 	return &snapshots, err
 }
 
+// GetSnapshot returns the resource and error passed as values of the context.
+func (l *mockLoader) GetSnapshot(c client.Client, ctx context.Context, name, namespace string) (*applicationapiv1alpha1.Snapshot, error) {
+	if ctx.Value(SnapshotContextKey) == nil {
+		return l.loader.GetSnapshot(c, ctx, name, namespace)
+	}
+	return getMockedResourceAndErrorFromContext(ctx, SnapshotContextKey, &applicationapiv1alpha1.Snapshot{})
+}
+
+// GetAllSnapshotsForPullRequest returns the resource and error passed as values of the context.
+func (l *mockLoader) GetAllSnapshotsForPullRequest(c client.Client, ctx context.Context, namespace, owner, repository, pullRequest string) (*[]applicationapiv1alpha1.Snapshot, error) {
+	if ctx.Value(AllSnapshotsContextKey) == nil {
+		return l.loader.GetAllSnapshotsForPullRequest(c, ctx, namespace, owner, repository, pullRequest)
+	}
+	snapshots, err := getMockedResourceAndErrorFromContext(ctx, AllSnapshotsContextKey, []applicationapiv1alpha1.Snapshot{})
+	return &snapshots, err
+}
+
 // GetAutoReleasePlansForApplication returns the resource and error passed as values of the context.
 func (l *mockLoader) GetAutoReleasePlansForApplication(c client.Client, ctx context.Context, application *applicationapiv1alpha1.Application) (*[]releasev1alpha1.ReleasePlan, error) {
 	if ctx.Value(AutoReleasePlansContextKey) == nil {
@@ -274,3 +312,30 @@ func (l *mockLoader) GetAutoReleasePlansForApplication(c client.Client, ctx cont
 	autoReleasePlans, err := getMockedResourceAndErrorFromContext(ctx, AutoReleasePlansContextKey, []releasev1alpha1.ReleasePlan{})
 	return &autoReleasePlans, err
 }
+
+// GetAllTestRunRecordsForApplication returns the resource and error passed as values of the context.
+func (l *mockLoader) GetAllTestRunRecordsForApplication(c client.Client, ctx context.Context, application *applicationapiv1alpha1.Application) (*[]v1beta1.TestRunRecord, error) {
+	if ctx.Value(TestRunRecordsContextKey) == nil {
+		return l.loader.GetAllTestRunRecordsForApplication(c, ctx, application)
+	}
+	testRunRecords, err := getMockedResourceAndErrorFromContext(ctx, TestRunRecordsContextKey, []v1beta1.TestRunRecord{})
+	return &testRunRecords, err
+}
+
+// GetAllRepositoriesForURL returns the resource and error passed as values of the context.
+func (l *mockLoader) GetAllRepositoriesForURL(c client.Client, ctx context.Context, url string) (*[]pacv1alpha1.Repository, error) {
+	if ctx.Value(RepositoriesContextKey) == nil {
+		return l.loader.GetAllRepositoriesForURL(c, ctx, url)
+	}
+	repositories, err := getMockedResourceAndErrorFromContext(ctx, RepositoriesContextKey, []pacv1alpha1.Repository{})
+	return &repositories, err
+}
+
+// GetAllSnapshotEnvironmentBindingsForScenario returns the resource and error passed as values of the context.
+func (l *mockLoader) GetAllSnapshotEnvironmentBindingsForScenario(c client.Client, ctx context.Context, snapshot *applicationapiv1alpha1.Snapshot, integrationTestScenario *v1beta1.IntegrationTestScenario) (*[]applicationapiv1alpha1.SnapshotEnvironmentBinding, error) {
+	if ctx.Value(ScenarioBindingsContextKey) == nil {
+		return l.loader.GetAllSnapshotEnvironmentBindingsForScenario(c, ctx, snapshot, integrationTestScenario)
+	}
+	bindings, err := getMockedResourceAndErrorFromContext(ctx, ScenarioBindingsContextKey, []applicationapiv1alpha1.SnapshotEnvironmentBinding{})
+	return &bindings, err
+}
@@ -21,6 +21,7 @@ import (
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	pacv1alpha1 "github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
 	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
 	"github.com/redhat-appstudio/integration-service/api/v1beta1"
 	releasev1alpha1 "github.com/redhat-appstudio/release-service/api/v1alpha1"
@@ -259,6 +260,21 @@ var _ = Describe("Release Adapter", Ordered, func() {
 		})
 	})
 
+	Context("When calling GetScenarioFromPipelineRun", func() {
+		It("returns resource and error from the context", func() {
+			scenario := &v1beta1.IntegrationTestScenario{}
+			mockContext := GetMockedContext(ctx, []MockData{
+				{
+					ContextKey: IntegrationTestScenarioContextKey,
+					Resource:   scenario,
+				},
+			})
+			resource, err := loader.GetScenarioFromPipelineRun(nil, mockContext, nil)
+			Expect(resource).To(Equal(scenario))
+			Expect(err).To(BeNil())
+		})
+	})
+
 	Context("When calling FindAvailableDeploymentTargetClass", func() {
 		It("returns deploymentTargetClassre source and error from the context", func() {
 			dtcls := &applicationapiv1alpha1.DeploymentTargetClass{}
@@ -378,4 +394,79 @@ var _ = Describe("Release Adapter", Ordered, func() {
 			Expect(err).To(BeNil())
 		})
 	})
+
+	Context("When calling GetSnapshot", func() {
+		It("returns resource and error from the context", func() {
+			snapshot := &applicationapiv1alpha1.Snapshot{}
+			mockContext := GetMockedContext(ctx, []MockData{
+				{
+					ContextKey: SnapshotContextKey,
+					Resource:   snapshot,
+				},
+			})
+			resource, err := loader.GetSnapshot(nil, mockContext, "", "")
+			Expect(resource).To(Equal(snapshot))
+			Expect(err).To(BeNil())
+		})
+	})
+
+	Context("When calling GetAllSnapshotsForPullRequest", func() {
+		It("returns snapshots and error from the context", func() {
+			snapshots := []applicationapiv1alpha1.Snapshot{}
+			mockContext := GetMockedContext(ctx, []MockData{
+				{
+					ContextKey: AllSnapshotsContextKey,
+					Resource:   snapshots,
+				},
+			})
+			resource, err := loader.GetAllSnapshotsForPullRequest(nil, mockContext, "", "", "", "")
+			Expect(resource).To(Equal(&snapshots))
+			Expect(err).To(BeNil())
+		})
+	})
+
+	Context("When calling GetAllSnapshotEnvironmentBindingsForScenario", func() {
+		It("returns bindings and error from the context", func() {
+			bindings := []applicationapiv1alpha1.SnapshotEnvironmentBinding{}
+			mockContext := GetMockedContext(ctx, []MockData{
+				{
+					ContextKey: ScenarioBindingsContextKey,
+					Resource:   bindings,
+				},
+			})
+			resource, err := loader.GetAllSnapshotEnvironmentBindingsForScenario(nil, mockContext, nil, nil)
+			Expect(resource).To(Equal(&bindings))
+			Expect(err).To(BeNil())
+		})
+	})
+
+	Context("When calling GetAllRepositoriesForURL", func() {
+		It("returns repositories and error from the context", func() {
+			repositories := []pacv1alpha1.Repository{}
+			mockContext := GetMockedContext(ctx, []MockData{
+				{
+					ContextKey: RepositoriesContextKey,
+					Resource:   repositories,
+				},
+			})
+			resource, err := loader.GetAllRepositoriesForURL(nil, mockContext, "")
+			Expect(resource).To(Equal(&repositories))
+			Expect(err).To(BeNil())
+		})
+	})
+
+	Context("When calling GetAllTestRunRecordsForApplication", func() {
+		It("returns testRunRecords and error from the context", func() {
+			testRunRecords := []v1beta1.TestRunRecord{}
+			mockContext := GetMockedContext(ctx, []MockData{
+				{
+					ContextKey: TestRunRecordsContextKey,
+					Resource:   testRunRecords,
+				},
+			})
+			resource, err := loader.GetAllTestRunRecordsForApplication(nil, mockContext, nil)
+			Expect(resource).To(Equal(&testRunRecords))
+			Expect(err).To(BeNil())
+		})
+	})
 })
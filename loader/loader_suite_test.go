@@ -102,6 +102,7 @@ var _ = BeforeSuite(func() {
 	go func() {
 		defer GinkgoRecover()
 		Expect(cache.SetupIntegrationTestScenarioCache(k8sManager)).To(Succeed())
+		Expect(cache.SetupClusterIntegrationTestScenarioCache(k8sManager)).To(Succeed())
 		Expect(cache.SetupReleaseCache(k8sManager)).To(Succeed())
 		Expect(cache.SetupReleasePlanCache(k8sManager)).To(Succeed())
 		Expect(cache.SetupApplicationComponentCache(k8sManager)).To(Succeed())
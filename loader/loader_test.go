@@ -107,11 +107,15 @@ var _ = Describe("Loader", Ordered, func() {
 				Name:      snapshotName,
 				Namespace: "default",
 				Labels: map[string]string{
-					gitops.SnapshotTypeLabel:      "component",
-					gitops.SnapshotComponentLabel: "component-sample",
+					gitops.SnapshotTypeLabel:                "component",
+					gitops.SnapshotComponentLabel:           "component-sample",
+					gitops.PipelineAsCodeEventTypeLabel:     gitops.PipelineAsCodePullRequestType,
+					gitops.PipelineAsCodeURLOrgLabel:        "redhat-appstudio",
+					gitops.PipelineAsCodeURLRepositoryLabel: "integration-service",
 				},
 				Annotations: map[string]string{
 					gitops.PipelineAsCodeInstallationIDAnnotation: "123",
+					gitops.PipelineAsCodePullRequestAnnotation:    "1",
 				},
 			},
 			Spec: applicationapiv1alpha1.SnapshotSpec{
@@ -534,6 +538,13 @@ var _ = Describe("Loader", Ordered, func() {
 		Expect(env.ObjectMeta).To(Equal(hasEnv.ObjectMeta))
 	})
 
+	It("ensures we can get the IntegrationTestScenario from a Pipeline Run", func() {
+		scenario, err := loader.GetScenarioFromPipelineRun(k8sClient, ctx, buildPipelineRun)
+		Expect(err).To(BeNil())
+		Expect(scenario).NotTo(BeNil())
+		Expect(scenario.Name).To(Equal(integrationTestScenario.Name))
+	})
+
 	It("can fetch all build pipelineRuns", func() {
 		pipelineRuns, err := loader.GetAllBuildPipelineRunsForComponent(k8sClient, ctx, hasComp)
 		Expect(err).To(BeNil())
@@ -566,6 +577,95 @@ var _ = Describe("Loader", Ordered, func() {
 		Expect((*integrationTestScenarios)[0].Name == integrationTestScenario.Name)
 	})
 
+	It("doesn't fetch quarantined integrationTestScenarios as required for application", func() {
+		patch := client.MergeFrom(integrationTestScenario.DeepCopy())
+		if integrationTestScenario.Labels == nil {
+			integrationTestScenario.Labels = map[string]string{}
+		}
+		integrationTestScenario.Labels["test.appstudio.openshift.io/quarantined"] = "true"
+		Expect(k8sClient.Patch(ctx, integrationTestScenario, patch)).Should(Succeed())
+		defer func() {
+			patch := client.MergeFrom(integrationTestScenario.DeepCopy())
+			delete(integrationTestScenario.Labels, "test.appstudio.openshift.io/quarantined")
+			Expect(k8sClient.Patch(ctx, integrationTestScenario, patch)).Should(Succeed())
+		}()
+
+		Eventually(func() bool {
+			integrationTestScenarios, err := loader.GetRequiredIntegrationTestScenariosForApplication(k8sClient, ctx, hasApp)
+			return err == nil && len(*integrationTestScenarios) == 0
+		}, time.Second*10).Should(BeTrue())
+	})
+
+	It("can fetch the integrationTestScenarios inherited by the application", func() {
+		clusterScenario := &v1beta1.ClusterIntegrationTestScenario{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "inherited-scenario",
+				Labels: map[string]string{
+					"test.appstudio.openshift.io/optional": "true",
+				},
+			},
+			Spec: v1beta1.ClusterIntegrationTestScenarioSpec{
+				NamespaceSelector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{
+						"kubernetes.io/metadata.name": hasApp.Namespace,
+					},
+				},
+				ResolverRef: integrationTestScenario.Spec.ResolverRef,
+			},
+		}
+		Expect(k8sClient.Create(ctx, clusterScenario)).Should(Succeed())
+		defer func() {
+			Expect(k8sClient.Delete(ctx, clusterScenario)).Should(Succeed())
+		}()
+
+		// The ClusterIntegrationTestScenario controller records the Applications inheriting the ClusterIntegrationTestScenario
+		Eventually(func() bool {
+			integrationTestScenarios, err := loader.GetAllIntegrationTestScenariosForApplication(k8sClient, ctx, hasApp)
+			return err == nil && len(*integrationTestScenarios) == 1
+		}, time.Second*10).Should(BeTrue())
+		clusterScenario.Status.Applications = []string{gitops.GetInheritingApplicationName(hasApp)}
+		Expect(k8sClient.Status().Update(ctx, clusterScenario)).Should(Succeed())
+
+		Eventually(func() bool {
+			integrationTestScenarios, err := loader.GetAllIntegrationTestScenariosForApplication(k8sClient, ctx, hasApp)
+			return err == nil && len(*integrationTestScenarios) == 2 &&
+				gitops.IsIntegrationTestScenarioInherited(&(*integrationTestScenarios)[1])
+		}, time.Second*10).Should(BeTrue())
+
+		// The inherited integrationTestScenario is optional
+		integrationTestScenarios, err := loader.GetRequiredIntegrationTestScenariosForApplication(k8sClient, ctx, hasApp)
+		Expect(err).To(BeNil())
+		Expect(*integrationTestScenarios).To(HaveLen(1))
+	})
+
+	It("can fetch all testRunRecords for application", func() {
+		testRunRecord := &v1beta1.TestRunRecord{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "example-pass-run",
+				Namespace: hasApp.Namespace,
+				Labels: map[string]string{
+					gitops.TestRunRecordApplicationLabel: hasApp.Name,
+				},
+			},
+			Spec: v1beta1.TestRunRecordSpec{
+				Application: hasApp.Name,
+				Scenario:    integrationTestScenario.Name,
+				Snapshot:    hasSnapshot.Name,
+				PipelineRun: "example-pass-run",
+				Outcome:     gitops.TestRunRecordPassed,
+			},
+		}
+		Expect(k8sClient.Create(ctx, testRunRecord)).Should(Succeed())
+		defer func() {
+			Expect(k8sClient.Delete(ctx, testRunRecord)).Should(Succeed())
+		}()
+
+		Eventually(func() bool {
+			testRunRecords, err := loader.GetAllTestRunRecordsForApplication(k8sClient, ctx, hasApp)
+			return err == nil && len(*testRunRecords) == 1 && (*testRunRecords)[0].Name == testRunRecord.Name
+		}, time.Second*10).Should(BeTrue())
+	})
+
 	It("can find available DeploymentTargetClass for application", func() {
 		dtcls, err := loader.FindAvailableDeploymentTargetClass(k8sClient, ctx)
 		Expect(err).To(BeNil())
@@ -590,12 +690,36 @@ var _ = Describe("Loader", Ordered, func() {
 		Expect(binding.Name == hasBinding.Name)
 	})
 
+	It("can fetch all snapshotEnvironmentBindings for snapshot and scenario", func() {
+		bindings, err := loader.GetAllSnapshotEnvironmentBindingsForScenario(k8sClient, ctx, hasSnapshot, integrationTestScenario)
+		Expect(err).To(BeNil())
+		Expect(*bindings).To(HaveLen(1))
+		Expect((*bindings)[0].Name).To(Equal(hasBinding.Name))
+	})
+
 	It("ensures that all Snapshots for a given application can be found", func() {
 		snapshots, err := loader.GetAllSnapshots(k8sClient, ctx, hasApp)
 		Expect(err).To(BeNil())
 		Expect(len(*snapshots)).To(Equal(1))
 	})
 
+	It("ensures the Snapshot can be found by its name", func() {
+		snapshot, err := loader.GetSnapshot(k8sClient, ctx, hasSnapshot.Name, hasSnapshot.Namespace)
+		Expect(err).To(BeNil())
+		Expect(snapshot.Name).To(Equal(hasSnapshot.Name))
+	})
+
+	It("ensures that all Snapshots for a given pull request can be found", func() {
+		snapshots, err := loader.GetAllSnapshotsForPullRequest(k8sClient, ctx, hasSnapshot.Namespace, "redhat-appstudio", "integration-service", "1")
+		Expect(err).To(BeNil())
+		Expect(*snapshots).To(HaveLen(1))
+		Expect((*snapshots)[0].Name).To(Equal(hasSnapshot.Name))
+
+		snapshots, err = loader.GetAllSnapshotsForPullRequest(k8sClient, ctx, hasSnapshot.Namespace, "redhat-appstudio", "integration-service", "2")
+		Expect(err).To(BeNil())
+		Expect(*snapshots).To(BeEmpty())
+	})
+
 	It("ensures the ReleasePlan can be gotten for Application", func() {
 		gottenReleasePlanItems, err := loader.GetAutoReleasePlansForApplication(k8sClient, ctx, hasApp)
 		Expect(err).To(BeNil())
@@ -20,7 +20,9 @@ import (
 	"flag"
 	"os"
 
+	"github.com/redhat-appstudio/integration-service/chatops"
 	"github.com/redhat-appstudio/integration-service/controllers"
+	"github.com/redhat-appstudio/integration-service/server"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
@@ -65,8 +67,15 @@ func main() {
 	var metricsAddr string
 	var enableLeaderElection bool
 	var probeAddr string
+	var apiAddr string
+	var apiCertDir string
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
+	flag.StringVar(&apiAddr, "api-bind-address", "127.0.0.1:8082",
+		"The address the Snapshot status API binds to. Set it to \"0\" to disable the API.")
+	flag.StringVar(&apiCertDir, "api-cert-dir", "",
+		"The directory with the tls.crt and tls.key files the Snapshot status API serves HTTPS with. "+
+			"The API serves HTTP if it's not set, which is only allowed on a loopback bind address.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
@@ -104,6 +113,20 @@ func main() {
 	}
 	//+kubebuilder:scaffold:builder
 
+	if apiAddr != "0" {
+		serverOpts := []server.ServerOption{server.WithCertDir(apiCertDir)}
+		// The GitHub webhook receiver handling the pull request comment commands is only enabled with a secret
+		if webhookSecret := os.Getenv("GITHUB_WEBHOOK_SECRET"); webhookSecret != "" {
+			commentHandler := chatops.NewHandler(ctrl.Log.WithName("chatops"), mgr.GetClient())
+			serverOpts = append(serverOpts, server.WithGitHubWebhook([]byte(webhookSecret), commentHandler))
+		}
+		apiServer := server.NewServer(ctrl.Log.WithName("api"), mgr.GetClient(), apiAddr, serverOpts...)
+		if err := mgr.Add(apiServer); err != nil {
+			setupLog.Error(err, "unable to set up the API server")
+			os.Exit(1)
+		}
+	}
+
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
 		setupLog.Error(err, "unable to set up health check")
 		os.Exit(1)
@@ -61,6 +61,22 @@ var (
 		},
 		[]string{"type", "reason"},
 	)
+
+	IntegrationTestScenarioFlakinessScore = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "integration_test_scenario_flakiness_score",
+			Help: "Percentage of the inputs tested more than once by the IntegrationTestScenario whose outcomes differed",
+		},
+		[]string{"namespace", "scenario"},
+	)
+
+	IntegrationTestScenarioQuarantined = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "integration_test_scenario_quarantined",
+			Help: "Whether the IntegrationTestScenario is quarantined, 1 if it is and 0 otherwise",
+		},
+		[]string{"namespace", "scenario"},
+	)
 )
 
 func RegisterCompletedSnapshot(conditiontype, reason string, startTime metav1.Time, completionTime *metav1.Time) {
@@ -100,6 +116,20 @@ func RegisterNewIntegrationPipelineRun(snapshotCreatedTime metav1.Time, pipeline
 	RegisterPipelineRunStarted(snapshotCreatedTime, pipelineRunStartTime)
 }
 
+func RegisterIntegrationTestScenarioFlakiness(namespace, scenario string, score int, quarantined bool) {
+	labels := prometheus.Labels{
+		"namespace": namespace,
+		"scenario":  scenario,
+	}
+
+	IntegrationTestScenarioFlakinessScore.With(labels).Set(float64(score))
+	if quarantined {
+		IntegrationTestScenarioQuarantined.With(labels).Set(1)
+	} else {
+		IntegrationTestScenarioQuarantined.With(labels).Set(0)
+	}
+}
+
 func init() {
 	metrics.Registry.MustRegister(
 		SnapshotCreatedToPipelineRunStartedSeconds,
@@ -109,5 +139,7 @@ func init() {
 		SnapshotDurationSeconds,
 		SnapshotInvalidTotal,
 		SnapshotTotal,
+		IntegrationTestScenarioFlakinessScore,
+		IntegrationTestScenarioQuarantined,
 	)
 }
@@ -196,4 +196,16 @@ var _ = Describe("Metrics Integration", Ordered, func() {
 				strings.NewReader(readerData))).To(Succeed())
 		})
 	})
+
+	Context("When RegisterIntegrationTestScenarioFlakiness is called", func() {
+		It("sets the 'IntegrationTestScenarioFlakinessScore' and 'IntegrationTestScenarioQuarantined' metrics", func() {
+			RegisterIntegrationTestScenarioFlakiness("default", "example-scenario", 40, true)
+			Expect(testutil.ToFloat64(IntegrationTestScenarioFlakinessScore.WithLabelValues("default", "example-scenario"))).To(Equal(float64(40)))
+			Expect(testutil.ToFloat64(IntegrationTestScenarioQuarantined.WithLabelValues("default", "example-scenario"))).To(Equal(float64(1)))
+
+			RegisterIntegrationTestScenarioFlakiness("default", "example-scenario", 10, false)
+			Expect(testutil.ToFloat64(IntegrationTestScenarioFlakinessScore.WithLabelValues("default", "example-scenario"))).To(Equal(float64(10)))
+			Expect(testutil.ToFloat64(IntegrationTestScenarioQuarantined.WithLabelValues("default", "example-scenario"))).To(Equal(float64(0)))
+		})
+	})
 })
@@ -0,0 +1,76 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package release
+
+import (
+	"reflect"
+
+	releasev1alpha1 "github.com/redhat-appstudio/release-service/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// ReleaseOutcomeInProgress is the outcome of a Release which hasn't finished yet.
+	ReleaseOutcomeInProgress = "InProgress"
+
+	// ReleaseOutcomeSucceeded is the outcome of a Release which finished successfully.
+	ReleaseOutcomeSucceeded = "Succeeded"
+
+	// ReleaseOutcomeFailed is the outcome of a Release which failed validation or failed to release.
+	ReleaseOutcomeFailed = "Failed"
+
+	// releaseValidatedConditionType is the condition the release-service uses to track the validation of a Release.
+	releaseValidatedConditionType = "Validated"
+
+	// releaseReleasedConditionType is the condition the release-service uses to track the outcome of a Release.
+	releaseReleasedConditionType = "Released"
+)
+
+// GetReleaseOutcome returns the outcome of the given Release together with the message the release-service set
+// when it failed.
+func GetReleaseOutcome(release *releasev1alpha1.Release) (string, string) {
+	validatedCondition := meta.FindStatusCondition(release.Status.Conditions, releaseValidatedConditionType)
+	if validatedCondition != nil && validatedCondition.Status == metav1.ConditionFalse {
+		return ReleaseOutcomeFailed, validatedCondition.Message
+	}
+
+	switch {
+	case release.IsReleased():
+		return ReleaseOutcomeSucceeded, ""
+	case release.HasReleaseFinished():
+		releasedCondition := meta.FindStatusCondition(release.Status.Conditions, releaseReleasedConditionType)
+		return ReleaseOutcomeFailed, releasedCondition.Message
+	default:
+		return ReleaseOutcomeInProgress, ""
+	}
+}
+
+// HasReleaseStatusChanged returns true if the conditions of the Release changed between the given objects.
+func HasReleaseStatusChanged(objectOld, objectNew client.Object) bool {
+	oldRelease, ok := objectOld.(*releasev1alpha1.Release)
+	if !ok {
+		return false
+	}
+	newRelease, ok := objectNew.(*releasev1alpha1.Release)
+	if !ok {
+		return false
+	}
+
+	return !reflect.DeepEqual(oldRelease.Status.Conditions, newRelease.Status.Conditions)
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package release_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	integrationservicerelease "github.com/redhat-appstudio/integration-service/release"
+	releasev1alpha1 "github.com/redhat-appstudio/release-service/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+var _ = Describe("Release functions for tracking the outcome of Releases", func() {
+
+	var snapshotRelease *releasev1alpha1.Release
+
+	BeforeEach(func() {
+		snapshotRelease = &releasev1alpha1.Release{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "snapshot-sample-release",
+				Namespace: "default",
+			},
+			Spec: releasev1alpha1.ReleaseSpec{
+				Snapshot:    "snapshot-sample",
+				ReleasePlan: "releaseplan-sample",
+			},
+		}
+	})
+
+	It("returns the outcome of a Release", func() {
+		outcome, message := integrationservicerelease.GetReleaseOutcome(snapshotRelease)
+		Expect(outcome).To(Equal(integrationservicerelease.ReleaseOutcomeInProgress))
+		Expect(message).To(BeEmpty())
+
+		meta.SetStatusCondition(&snapshotRelease.Status.Conditions, metav1.Condition{
+			Type:   "Released",
+			Status: metav1.ConditionTrue,
+			Reason: "Succeeded",
+		})
+		outcome, _ = integrationservicerelease.GetReleaseOutcome(snapshotRelease)
+		Expect(outcome).To(Equal(integrationservicerelease.ReleaseOutcomeSucceeded))
+
+		meta.SetStatusCondition(&snapshotRelease.Status.Conditions, metav1.Condition{
+			Type:    "Released",
+			Status:  metav1.ConditionFalse,
+			Reason:  "Failed",
+			Message: "the release pipeline failed",
+		})
+		outcome, message = integrationservicerelease.GetReleaseOutcome(snapshotRelease)
+		Expect(outcome).To(Equal(integrationservicerelease.ReleaseOutcomeFailed))
+		Expect(message).To(Equal("the release pipeline failed"))
+	})
+
+	It("considers Releases which failed validation as failed", func() {
+		meta.SetStatusCondition(&snapshotRelease.Status.Conditions, metav1.Condition{
+			Type:    "Validated",
+			Status:  metav1.ConditionFalse,
+			Reason:  "Failed",
+			Message: "the ReleasePlanAdmission was not found",
+		})
+		outcome, message := integrationservicerelease.GetReleaseOutcome(snapshotRelease)
+		Expect(outcome).To(Equal(integrationservicerelease.ReleaseOutcomeFailed))
+		Expect(message).To(Equal("the ReleasePlanAdmission was not found"))
+	})
+
+	It("filters the Releases whose status changed", func() {
+		instance := integrationservicerelease.ReleaseStatusChangedPredicate()
+		updatedRelease := snapshotRelease.DeepCopy()
+
+		Expect(instance.Create(event.CreateEvent{Object: snapshotRelease})).To(BeFalse())
+		Expect(instance.Delete(event.DeleteEvent{Object: snapshotRelease})).To(BeFalse())
+		Expect(instance.Generic(event.GenericEvent{Object: snapshotRelease})).To(BeFalse())
+		Expect(instance.Update(event.UpdateEvent{ObjectOld: snapshotRelease, ObjectNew: updatedRelease})).To(BeFalse())
+
+		meta.SetStatusCondition(&updatedRelease.Status.Conditions, metav1.Condition{
+			Type:   "Released",
+			Status: metav1.ConditionTrue,
+			Reason: "Succeeded",
+		})
+		Expect(instance.Update(event.UpdateEvent{ObjectOld: snapshotRelease, ObjectNew: updatedRelease})).To(BeTrue())
+	})
+})
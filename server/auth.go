@@ -0,0 +1,236 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Authorizer authenticates the users of the API and checks their access to the requested resources.
+type Authorizer interface {
+	// Authenticate returns the user the bearer token belongs to, or nil if the token isn't valid.
+	Authenticate(ctx context.Context, token string) (*authenticationv1.UserInfo, error)
+	// Authorize returns true if the user is allowed to access the resource described by the attributes.
+	Authorize(ctx context.Context, user *authenticationv1.UserInfo, attributes *authorizationv1.ResourceAttributes) (bool, error)
+}
+
+// KubernetesAuthorizer authenticates the users through TokenReviews and authorizes them through
+// SubjectAccessReviews, so the access to the API follows the RBAC rules of the cluster.
+type KubernetesAuthorizer struct {
+	client client.Client
+}
+
+// NewKubernetesAuthorizer creates a KubernetesAuthorizer creating the reviews with the given client.
+func NewKubernetesAuthorizer(client client.Client) *KubernetesAuthorizer {
+	return &KubernetesAuthorizer{
+		client: client,
+	}
+}
+
+// Authenticate creates a TokenReview for the bearer token and returns the user it belongs to, or nil if the
+// token isn't valid. If the TokenReview can't be created, an error will be returned.
+func (a *KubernetesAuthorizer) Authenticate(ctx context.Context, token string) (*authenticationv1.UserInfo, error) {
+	tokenReview := &authenticationv1.TokenReview{
+		Spec: authenticationv1.TokenReviewSpec{
+			Token: token,
+		},
+	}
+	err := a.client.Create(ctx, tokenReview)
+	if err != nil {
+		return nil, err
+	}
+	if !tokenReview.Status.Authenticated {
+		return nil, nil
+	}
+
+	return &tokenReview.Status.User, nil
+}
+
+// Authorize creates a SubjectAccessReview for the user and the resource described by the attributes and returns
+// true if the access is allowed. If the SubjectAccessReview can't be created, an error will be returned.
+func (a *KubernetesAuthorizer) Authorize(ctx context.Context, user *authenticationv1.UserInfo, attributes *authorizationv1.ResourceAttributes) (bool, error) {
+	extra := map[string]authorizationv1.ExtraValue{}
+	for key, value := range user.Extra {
+		extra[key] = authorizationv1.ExtraValue(value)
+	}
+	subjectAccessReview := &authorizationv1.SubjectAccessReview{
+		Spec: authorizationv1.SubjectAccessReviewSpec{
+			ResourceAttributes: attributes,
+			User:               user.Username,
+			Groups:             user.Groups,
+			UID:                user.UID,
+			Extra:              extra,
+		},
+	}
+	err := a.client.Create(ctx, subjectAccessReview)
+	if err != nil {
+		return false, err
+	}
+
+	return subjectAccessReview.Status.Allowed, nil
+}
+
+const (
+	// DefaultAuthorizationCacheTTL is how long the outcomes of the TokenReviews and SubjectAccessReviews are reused.
+	DefaultAuthorizationCacheTTL = 30 * time.Second
+
+	// maxAuthorizationCacheEntries bounds the number of outcomes each cache of the CachingAuthorizer holds.
+	maxAuthorizationCacheEntries = 10000
+)
+
+// CachingAuthorizer reuses the outcomes of another Authorizer for a short time, so the repeated requests of a
+// user don't each create a TokenReview and a SubjectAccessReview per resource. The tokens are only kept hashed.
+type CachingAuthorizer struct {
+	authorizer     Authorizer
+	ttl            time.Duration
+	mutex          sync.Mutex
+	users          map[string]userCacheEntry
+	authorizations map[string]authorizationCacheEntry
+}
+
+// userCacheEntry is the outcome of the authentication of a token, the user being nil if the token isn't valid.
+type userCacheEntry struct {
+	user      *authenticationv1.UserInfo
+	expiresAt time.Time
+}
+
+// authorizationCacheEntry is the outcome of the authorization of a user to access a resource.
+type authorizationCacheEntry struct {
+	allowed   bool
+	expiresAt time.Time
+}
+
+// NewCachingAuthorizer creates a CachingAuthorizer reusing the outcomes of the given Authorizer for the ttl.
+func NewCachingAuthorizer(authorizer Authorizer, ttl time.Duration) *CachingAuthorizer {
+	return &CachingAuthorizer{
+		authorizer:     authorizer,
+		ttl:            ttl,
+		users:          map[string]userCacheEntry{},
+		authorizations: map[string]authorizationCacheEntry{},
+	}
+}
+
+// Authenticate returns the user the bearer token belongs to, or nil if the token isn't valid. The outcome is
+// reused for the tokens with the same hash until it expires. The errors aren't cached.
+func (a *CachingAuthorizer) Authenticate(ctx context.Context, token string) (*authenticationv1.UserInfo, error) {
+	hash := sha256.Sum256([]byte(token))
+	key := hex.EncodeToString(hash[:])
+
+	a.mutex.Lock()
+	entry, found := a.users[key]
+	a.mutex.Unlock()
+	if found && time.Now().Before(entry.expiresAt) {
+		return entry.user, nil
+	}
+
+	user, err := a.authorizer.Authenticate(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	now := time.Now()
+	for cachedKey, cachedEntry := range a.users {
+		if now.After(cachedEntry.expiresAt) {
+			delete(a.users, cachedKey)
+		}
+	}
+	if len(a.users) < maxAuthorizationCacheEntries {
+		a.users[key] = userCacheEntry{user: user, expiresAt: now.Add(a.ttl)}
+	}
+
+	return user, nil
+}
+
+// Authorize returns true if the user is allowed to access the resource described by the attributes. The outcome
+// is reused for the same user and attributes until it expires. The errors aren't cached.
+func (a *CachingAuthorizer) Authorize(ctx context.Context, user *authenticationv1.UserInfo, attributes *authorizationv1.ResourceAttributes) (bool, error) {
+	key := getAuthorizationCacheKey(user, attributes)
+
+	a.mutex.Lock()
+	entry, found := a.authorizations[key]
+	a.mutex.Unlock()
+	if found && time.Now().Before(entry.expiresAt) {
+		return entry.allowed, nil
+	}
+
+	allowed, err := a.authorizer.Authorize(ctx, user, attributes)
+	if err != nil {
+		return false, err
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	now := time.Now()
+	for cachedKey, cachedEntry := range a.authorizations {
+		if now.After(cachedEntry.expiresAt) {
+			delete(a.authorizations, cachedKey)
+		}
+	}
+	if len(a.authorizations) < maxAuthorizationCacheEntries {
+		a.authorizations[key] = authorizationCacheEntry{allowed: allowed, expiresAt: now.Add(a.ttl)}
+	}
+
+	return allowed, nil
+}
+
+// getAuthorizationCacheKey returns the key identifying the user and the attributes of the resource, built from
+// everything the SubjectAccessReview is created with.
+func getAuthorizationCacheKey(user *authenticationv1.UserInfo, attributes *authorizationv1.ResourceAttributes) string {
+	groups := append([]string{}, user.Groups...)
+	sort.Strings(groups)
+	extraKeys := make([]string, 0, len(user.Extra))
+	for key := range user.Extra {
+		extraKeys = append(extraKeys, key)
+	}
+	sort.Strings(extraKeys)
+	extra := make([]string, 0, len(extraKeys))
+	for _, key := range extraKeys {
+		extra = append(extra, key+"="+strings.Join(user.Extra[key], ","))
+	}
+
+	hash := sha256.Sum256([]byte(strings.Join([]string{
+		user.Username, user.UID, strings.Join(groups, ","), strings.Join(extra, ";"),
+		attributes.Namespace, attributes.Verb, attributes.Group, attributes.Version, attributes.Resource,
+		attributes.Subresource, attributes.Name,
+	}, "\x00")))
+
+	return hex.EncodeToString(hash[:])
+}
+
+// getBearerToken returns the bearer token from the Authorization header of the request, or an empty string
+// if the header is missing or isn't a bearer token.
+func getBearerToken(req *http.Request) string {
+	header := req.Header.Get("Authorization")
+	if len(header) < len("Bearer ") || !strings.EqualFold(header[:len("Bearer ")], "Bearer ") {
+		return ""
+	}
+
+	return strings.TrimSpace(header[len("Bearer "):])
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-appstudio/integration-service/server"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+)
+
+var _ = Describe("CachingAuthorizer", func() {
+	var (
+		authorizer        *MockAuthorizer
+		cachingAuthorizer *server.CachingAuthorizer
+		user              *authenticationv1.UserInfo
+		attributes        *authorizationv1.ResourceAttributes
+	)
+
+	BeforeEach(func() {
+		authorizer = &MockAuthorizer{allowed: true}
+		cachingAuthorizer = server.NewCachingAuthorizer(authorizer, time.Minute)
+		user = &authenticationv1.UserInfo{Username: "developer", Groups: []string{"developers"}}
+		attributes = &authorizationv1.ResourceAttributes{
+			Namespace: "default",
+			Verb:      "list",
+			Group:     "appstudio.redhat.com",
+			Resource:  "snapshots",
+		}
+	})
+
+	It("reuses the outcome of the authentication of a token", func() {
+		for i := 0; i < 3; i++ {
+			authenticatedUser, err := cachingAuthorizer.Authenticate(context.Background(), "valid-token")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(authenticatedUser.Username).To(Equal("developer"))
+		}
+		Expect(authorizer.authentications).To(Equal(1))
+
+		authenticatedUser, err := cachingAuthorizer.Authenticate(context.Background(), "invalid-token")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(authenticatedUser).To(BeNil())
+		Expect(authorizer.authentications).To(Equal(2))
+	})
+
+	It("reuses the outcome of the authorization of a user for the same attributes", func() {
+		for i := 0; i < 3; i++ {
+			allowed, err := cachingAuthorizer.Authorize(context.Background(), user, attributes)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(allowed).To(BeTrue())
+		}
+		Expect(authorizer.reviewedResources).To(HaveLen(1))
+
+		otherNamespaceAttributes := attributes.DeepCopy()
+		otherNamespaceAttributes.Namespace = "other"
+		_, err := cachingAuthorizer.Authorize(context.Background(), user, otherNamespaceAttributes)
+		Expect(err).NotTo(HaveOccurred())
+		otherUser := &authenticationv1.UserInfo{Username: "other-developer"}
+		_, err = cachingAuthorizer.Authorize(context.Background(), otherUser, attributes)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(authorizer.reviewedResources).To(HaveLen(3))
+	})
+
+	It("reviews the access again once the outcome expired", func() {
+		cachingAuthorizer = server.NewCachingAuthorizer(authorizer, 0)
+		_, err := cachingAuthorizer.Authorize(context.Background(), user, attributes)
+		Expect(err).NotTo(HaveOccurred())
+		authorizer.allowed = false
+		allowed, err := cachingAuthorizer.Authorize(context.Background(), user, attributes)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(allowed).To(BeFalse())
+		Expect(authorizer.reviewedResources).To(HaveLen(2))
+	})
+})
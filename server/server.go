@@ -0,0 +1,474 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package server contains the read-only HTTP API serving the status of Snapshots and their testing
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	"github.com/redhat-appstudio/integration-service/api/v1beta1"
+	"github.com/redhat-appstudio/integration-service/loader"
+	releasev1alpha1 "github.com/redhat-appstudio/release-service/api/v1alpha1"
+	tektonv1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// APIPathPrefix is the prefix of the paths of all the namespaced API resources.
+	APIPathPrefix = "/api/v1/namespaces/"
+
+	// DefaultSnapshotLimit is the number of the most recent Snapshots of an Application listed by default.
+	DefaultSnapshotLimit = 20
+
+	// shutdownTimeout is the time the Server waits for the requests being served when the manager stops.
+	shutdownTimeout = 10 * time.Second
+)
+
+//+kubebuilder:rbac:groups=authentication.k8s.io,resources=tokenreviews,verbs=create
+//+kubebuilder:rbac:groups=authorization.k8s.io,resources=subjectaccessreviews,verbs=create
+
+// statusResource is a resource the status of the Snapshots is built from, which the users have to be allowed to
+// list in the namespace to read the status of its Snapshots.
+type statusResource struct {
+	group    string
+	resource string
+	kind     string
+}
+
+// statusResources are the resources the served status of the Snapshots exposes besides the Snapshots themselves.
+var statusResources = []statusResource{
+	{applicationapiv1alpha1.GroupVersion.Group, "applications", "Applications"},
+	{v1beta1.GroupVersion.Group, "integrationtestscenarios", "IntegrationTestScenarios"},
+	{v1beta1.GroupVersion.Group, "testrunrecords", "TestRunRecords"},
+	{tektonv1beta1.SchemeGroupVersion.Group, "pipelineruns", "PipelineRuns"},
+	{releasev1alpha1.GroupVersion.Group, "releases", "Releases"},
+	{releasev1alpha1.GroupVersion.Group, "releaseplans", "ReleasePlans"},
+	{applicationapiv1alpha1.GroupVersion.Group, "environments", "Environments"},
+}
+
+// Server serves the read-only HTTP API exposing the status of Snapshots, the IntegrationTestScenarios testing
+// them, their Releases and their promotion to Environments. Each request must carry the bearer token of a user
+// allowed to read the Snapshots of the requested namespace and every other resource their status exposes.
+//
+// The API is served over HTTPS if a certificate directory is set. Otherwise, it can only bind to a loopback address.
+// If the certificate directory doesn't hold a certificate, e.g. as its Secret wasn't issued, the API isn't served.
+//
+// The API serves the following paths:
+//
+//	/api/v1/namespaces/{namespace}/applications/{application}/snapshots
+//	/api/v1/namespaces/{namespace}/snapshots/{snapshot}
+//	/api/v1/namespaces/{namespace}/pullrequests/{owner}/{repository}/{number}
+//
+// If configured, the Server also receives the GitHub webhook events at /webhooks/github.
+type Server struct {
+	logger            logr.Logger
+	client            client.Client
+	loader            loader.ObjectLoader
+	authorizer        Authorizer
+	bindAddress       string
+	certDir           string
+	webhookSecret     []byte
+	commentHandler    CommentHandler
+	webhookDeliveries *deliveryTracker
+}
+
+// ServerOption is used to extend Server with optional parameters.
+type ServerOption = func(s *Server)
+
+// WithLoader is an option which allows for replacement of the loader used to load the served resources.
+func WithLoader(loader loader.ObjectLoader) ServerOption {
+	return func(s *Server) {
+		s.loader = loader
+	}
+}
+
+// WithAuthorizer is an option which allows for replacement of the Authorizer of the requests.
+func WithAuthorizer(authorizer Authorizer) ServerOption {
+	return func(s *Server) {
+		s.authorizer = authorizer
+	}
+}
+
+// WithCertDir is an option which makes the Server serve HTTPS with the tls.crt and tls.key files of the directory.
+func WithCertDir(certDir string) ServerOption {
+	return func(s *Server) {
+		s.certDir = certDir
+	}
+}
+
+// NewServer constructs a Server listening on the given address with optional params, if specified.
+func NewServer(logger logr.Logger, client client.Client, bindAddress string, opts ...ServerOption) *Server {
+	server := Server{
+		logger:      logger,
+		client:      client,
+		loader:      loader.NewLoader(),
+		authorizer:  NewCachingAuthorizer(NewKubernetesAuthorizer(client), DefaultAuthorizationCacheTTL),
+		bindAddress: bindAddress,
+	}
+
+	for _, opt := range opts {
+		opt(&server)
+	}
+
+	return &server
+}
+
+// Start serves the API until the context is cancelled. It implements the Runnable interface of the manager.
+// If the certificate directory is set but holds no certificate, it waits for the context to be cancelled without
+// serving the API, so the manager keeps running.
+// If no certificate directory is set and the bind address isn't a loopback address, an error will be returned
+// as the bearer tokens of the requests would be sent in plain text.
+func (s *Server) Start(ctx context.Context) error {
+	if s.certDir == "" && !isLoopbackAddress(s.bindAddress) {
+		return fmt.Errorf("the API can only serve plain HTTP on a loopback address, a certificate directory is "+
+			"required to serve it on %s", s.bindAddress)
+	}
+	if s.certDir != "" {
+		if _, err := os.Stat(filepath.Join(s.certDir, "tls.crt")); errors.Is(err, os.ErrNotExist) {
+			s.logger.Info("The certificate directory doesn't hold a certificate, the API won't be served",
+				"certDir", s.certDir)
+			<-ctx.Done()
+			return nil
+		}
+	}
+
+	httpServer := &http.Server{
+		Addr:              s.bindAddress,
+		Handler:           s,
+		ReadHeaderTimeout: 30 * time.Second,
+	}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := httpServer.Shutdown(shutdownCtx); err != nil {
+			s.logger.Error(err, "Failed to shut down the API server")
+		}
+	}()
+
+	s.logger.Info("Starting the API server", "address", s.bindAddress, "tls", s.certDir != "")
+	var err error
+	if s.certDir != "" {
+		err = httpServer.ListenAndServeTLS(filepath.Join(s.certDir, "tls.crt"), filepath.Join(s.certDir, "tls.key"))
+	} else {
+		err = httpServer.ListenAndServe()
+	}
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+
+	return err
+}
+
+// NeedLeaderElection returns false, so all the replicas of the manager serve the API.
+func (s *Server) NeedLeaderElection() bool {
+	return false
+}
+
+// ServeHTTP routes the API requests after authenticating and authorizing them. The webhook events are
+// authenticated by their signature instead.
+func (s *Server) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.URL.Path == GitHubWebhookPath {
+		s.serveGitHubWebhook(w, req)
+		return
+	}
+	if req.Method != http.MethodGet {
+		s.writeError(w, http.StatusMethodNotAllowed, "only GET requests are supported")
+		return
+	}
+	if !strings.HasPrefix(req.URL.Path, APIPathPrefix) {
+		s.writeError(w, http.StatusNotFound, "not found")
+		return
+	}
+
+	segments := strings.Split(strings.Trim(strings.TrimPrefix(req.URL.Path, APIPathPrefix), "/"), "/")
+	namespace := segments[0]
+	switch {
+	case hasEmptySegment(segments):
+		s.writeError(w, http.StatusNotFound, "not found")
+	case len(segments) == 4 && segments[1] == "applications" && segments[3] == "snapshots":
+		if s.authorize(w, req, namespace, "list", "") {
+			s.serveApplicationSnapshots(w, req, namespace, segments[2])
+		}
+	case len(segments) == 3 && segments[1] == "snapshots":
+		if s.authorize(w, req, namespace, "get", segments[2]) {
+			s.serveSnapshot(w, req, namespace, segments[2])
+		}
+	case len(segments) == 5 && segments[1] == "pullrequests":
+		if s.authorize(w, req, namespace, "list", "") {
+			s.servePullRequest(w, req, namespace, segments[2], segments[3], segments[4])
+		}
+	default:
+		s.writeError(w, http.StatusNotFound, "not found")
+	}
+}
+
+// authorize checks that the bearer token of the request belongs to a user allowed to perform the verb on the
+// Snapshots of the namespace and to list the other resources their status exposes. The access to the other
+// resources is reviewed in a single concurrent pass. If not allowed, the error response is written and false
+// is returned.
+func (s *Server) authorize(w http.ResponseWriter, req *http.Request, namespace, verb, name string) bool {
+	token := getBearerToken(req)
+	if token == "" {
+		s.writeError(w, http.StatusUnauthorized, "a bearer token is required")
+		return false
+	}
+
+	user, err := s.authorizer.Authenticate(req.Context(), token)
+	if err != nil {
+		s.logger.Error(err, "Failed to review the bearer token")
+		s.writeError(w, http.StatusInternalServerError, "failed to authenticate the request")
+		return false
+	}
+	if user == nil {
+		s.writeError(w, http.StatusUnauthorized, "the bearer token is not valid")
+		return false
+	}
+
+	if !s.authorizeResources(w, req, user, namespace, verb, []statusResource{
+		{applicationapiv1alpha1.GroupVersion.Group, "snapshots", "Snapshots"},
+	}, name) {
+		return false
+	}
+
+	return s.authorizeResources(w, req, user, namespace, "list", statusResources, "")
+}
+
+// authorizeResources checks concurrently that the user is allowed to perform the verb on each of the resources
+// in the namespace. If not, the error response listing every denied resource is written and false is returned.
+func (s *Server) authorizeResources(w http.ResponseWriter, req *http.Request, user *authenticationv1.UserInfo,
+	namespace, verb string, resources []statusResource, name string) bool {
+	allowed := make([]bool, len(resources))
+	errs := make([]error, len(resources))
+	var wg sync.WaitGroup
+	for i, resource := range resources {
+		wg.Add(1)
+		go func(i int, resource statusResource) {
+			defer wg.Done()
+			allowed[i], errs[i] = s.authorizer.Authorize(req.Context(), user, &authorizationv1.ResourceAttributes{
+				Namespace: namespace,
+				Verb:      verb,
+				Group:     resource.group,
+				Resource:  resource.resource,
+				Name:      name,
+			})
+		}(i, resource)
+	}
+	wg.Wait()
+
+	var deniedKinds []string
+	for i, resource := range resources {
+		if errs[i] != nil {
+			s.logger.Error(errs[i], "Failed to review the access of the user", "user", user.Username, "resource", resource.resource)
+			s.writeError(w, http.StatusInternalServerError, "failed to authorize the request")
+			return false
+		}
+		if !allowed[i] {
+			deniedKinds = append(deniedKinds, resource.kind)
+		}
+	}
+	if len(deniedKinds) > 0 {
+		s.writeError(w, http.StatusForbidden,
+			"user "+user.Username+" can't "+verb+" "+strings.Join(deniedKinds, ", ")+" in namespace "+namespace)
+		return false
+	}
+
+	return true
+}
+
+// serveApplicationSnapshots serves the most recent Snapshots of the Application. The number of Snapshots is
+// set through the limit query parameter.
+func (s *Server) serveApplicationSnapshots(w http.ResponseWriter, req *http.Request, namespace, applicationName string) {
+	limit := DefaultSnapshotLimit
+	if value := req.URL.Query().Get("limit"); value != "" {
+		parsedLimit, err := strconv.Atoi(value)
+		if err != nil || parsedLimit <= 0 {
+			s.writeError(w, http.StatusBadRequest, "the limit must be a positive number")
+			return
+		}
+		limit = parsedLimit
+	}
+
+	application := &applicationapiv1alpha1.Application{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      applicationName,
+			Namespace: namespace,
+		},
+	}
+	snapshots, err := s.loader.GetAllSnapshots(s.client, req.Context(), application)
+	if err != nil {
+		s.writeLoadError(w, err, "Snapshots of the Application")
+		return
+	}
+	sortedSnapshots := sortSnapshotsByCreationTime(*snapshots)
+	if len(sortedSnapshots) > limit {
+		sortedSnapshots = sortedSnapshots[:limit]
+	}
+
+	snapshotStatuses, err := s.getSnapshotStatuses(req.Context(), sortedSnapshots)
+	if err != nil {
+		s.writeLoadError(w, err, "status of the Snapshots")
+		return
+	}
+
+	s.writeJSON(w, &SnapshotStatusList{Items: snapshotStatuses})
+}
+
+// serveSnapshot serves the Snapshot with the given name.
+func (s *Server) serveSnapshot(w http.ResponseWriter, req *http.Request, namespace, name string) {
+	snapshot, err := s.loader.GetSnapshot(s.client, req.Context(), name, namespace)
+	if err != nil {
+		s.writeLoadError(w, err, "Snapshot")
+		return
+	}
+
+	snapshotStatus, err := newStatusBuilder(s).getSnapshotStatus(req.Context(), snapshot)
+	if err != nil {
+		s.writeLoadError(w, err, "status of the Snapshot")
+		return
+	}
+
+	s.writeJSON(w, snapshotStatus)
+}
+
+// servePullRequest serves the Snapshots created for the pull request of the owner's repository.
+func (s *Server) servePullRequest(w http.ResponseWriter, req *http.Request, namespace, owner, repository, number string) {
+	if _, err := strconv.Atoi(number); err != nil {
+		s.writeError(w, http.StatusBadRequest, "the pull request number must be a number")
+		return
+	}
+
+	snapshots, err := s.loader.GetAllSnapshotsForPullRequest(s.client, req.Context(), namespace, owner, repository, number)
+	if err != nil {
+		s.writeLoadError(w, err, "Snapshots of the pull request")
+		return
+	}
+
+	snapshotStatuses, err := s.getSnapshotStatuses(req.Context(), sortSnapshotsByCreationTime(*snapshots))
+	if err != nil {
+		s.writeLoadError(w, err, "status of the Snapshots")
+		return
+	}
+
+	s.writeJSON(w, &PullRequestStatus{
+		Owner:      owner,
+		Repository: repository,
+		Number:     number,
+		Snapshots:  snapshotStatuses,
+	})
+}
+
+// getSnapshotStatuses builds the views of the given Snapshots. If the resources related to any of them can't be
+// loaded, an error will be returned.
+func (s *Server) getSnapshotStatuses(ctx context.Context, snapshots []applicationapiv1alpha1.Snapshot) ([]SnapshotStatus, error) {
+	builder := newStatusBuilder(s)
+	snapshotStatuses := []SnapshotStatus{}
+	for _, snapshot := range snapshots {
+		snapshot := snapshot // G601
+		snapshotStatus, err := builder.getSnapshotStatus(ctx, &snapshot)
+		if err != nil {
+			return nil, err
+		}
+		snapshotStatuses = append(snapshotStatuses, *snapshotStatus)
+	}
+
+	return snapshotStatuses, nil
+}
+
+// writeJSON writes the JSON encoding of the view as a successful response.
+func (s *Server) writeJSON(w http.ResponseWriter, view any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(view); err != nil {
+		s.logger.Error(err, "Failed to write the API response")
+	}
+}
+
+// writeLoadError writes the error response for a resource which couldn't be loaded.
+func (s *Server) writeLoadError(w http.ResponseWriter, err error, resource string) {
+	if k8serrors.IsNotFound(err) {
+		s.writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	s.logger.Error(err, "Failed to load the "+resource)
+	s.writeError(w, http.StatusInternalServerError, "failed to load the "+resource)
+}
+
+// writeError writes an error response with the given status code and message.
+func (s *Server) writeError(w http.ResponseWriter, statusCode int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	if err := json.NewEncoder(w).Encode(map[string]string{"error": message}); err != nil {
+		s.logger.Error(err, "Failed to write the API error response")
+	}
+}
+
+// isLoopbackAddress returns true if the host of the bind address is localhost or a loopback IP address.
+func isLoopbackAddress(address string) bool {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+
+	return ip != nil && ip.IsLoopback()
+}
+
+// hasEmptySegment returns true if any segment of the path is empty.
+func hasEmptySegment(segments []string) bool {
+	for _, segment := range segments {
+		if segment == "" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// sortSnapshotsByCreationTime returns a copy of the Snapshots sorted by their creation time, the most recent first.
+func sortSnapshotsByCreationTime(snapshots []applicationapiv1alpha1.Snapshot) []applicationapiv1alpha1.Snapshot {
+	sortedSnapshots := make([]applicationapiv1alpha1.Snapshot, len(snapshots))
+	copy(sortedSnapshots, snapshots)
+	sort.SliceStable(sortedSnapshots, func(i, j int) bool {
+		return sortedSnapshots[j].CreationTimestamp.Before(&sortedSnapshots[i].CreationTimestamp)
+	})
+
+	return sortedSnapshots
+}
@@ -0,0 +1,384 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/go-logr/logr"
+	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	"github.com/redhat-appstudio/integration-service/api/v1beta1"
+	"github.com/redhat-appstudio/integration-service/gitops"
+	"github.com/redhat-appstudio/integration-service/loader"
+	"github.com/redhat-appstudio/integration-service/server"
+	releasev1alpha1 "github.com/redhat-appstudio/release-service/api/v1alpha1"
+	tektonv1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"knative.dev/pkg/apis"
+)
+
+type MockAuthorizer struct {
+	mutex              sync.Mutex
+	allowed            bool
+	deniedResources    []string
+	reviewedAttributes []*authorizationv1.ResourceAttributes
+	reviewedResources  []string
+	authentications    int
+}
+
+func (a *MockAuthorizer) Authenticate(_ context.Context, token string) (*authenticationv1.UserInfo, error) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.authentications++
+	if token != "valid-token" {
+		return nil, nil
+	}
+	return &authenticationv1.UserInfo{Username: "developer"}, nil
+}
+
+func (a *MockAuthorizer) Authorize(_ context.Context, _ *authenticationv1.UserInfo, attributes *authorizationv1.ResourceAttributes) (bool, error) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.reviewedAttributes = append(a.reviewedAttributes, attributes)
+	a.reviewedResources = append(a.reviewedResources, attributes.Resource)
+	for _, resource := range a.deniedResources {
+		if resource == attributes.Resource {
+			return false, nil
+		}
+	}
+	return a.allowed, nil
+}
+
+var _ = Describe("Server", func() {
+
+	var (
+		apiServer   *server.Server
+		authorizer  *MockAuthorizer
+		application *applicationapiv1alpha1.Application
+		snapshot    *applicationapiv1alpha1.Snapshot
+		oldSnapshot *applicationapiv1alpha1.Snapshot
+		scenarios   []v1beta1.IntegrationTestScenario
+		now         time.Time
+		mockData    []loader.MockData
+	)
+
+	serve := func(method, path, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		req = req.WithContext(loader.GetMockedContext(context.Background(), mockData))
+		recorder := httptest.NewRecorder()
+		apiServer.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	BeforeEach(func() {
+		now = time.Now()
+		authorizer = &MockAuthorizer{allowed: true}
+		apiServer = server.NewServer(logr.Discard(), nil, ":0",
+			server.WithLoader(loader.NewMockLoader()), server.WithAuthorizer(authorizer))
+
+		application = &applicationapiv1alpha1.Application{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "application-sample",
+				Namespace: "default",
+			},
+		}
+		snapshot = &applicationapiv1alpha1.Snapshot{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              "snapshot-sample",
+				Namespace:         "default",
+				CreationTimestamp: metav1.Time{Time: now},
+				Labels: map[string]string{
+					gitops.SnapshotTypeLabel:      "component",
+					gitops.SnapshotComponentLabel: "component-sample",
+					gitops.PipelineAsCodeSHALabel: "12a4a35ccd08194595179815e4646c3a6c08bb77",
+				},
+				Annotations: map[string]string{
+					gitops.SnapshotSkippedScenariosAnnotation: "example-skipped",
+					gitops.SnapshotPromotionAnnotation: `{"Environment/staging":{"promoted":true,"deployed":true},` +
+						`"ReleasePlan/release-plan":{"promoted":false,"reasons":["the Snapshot hasn't passed all required integration tests"]}}`,
+				},
+			},
+			Spec: applicationapiv1alpha1.SnapshotSpec{
+				Application: application.Name,
+				Components: []applicationapiv1alpha1.SnapshotComponent{
+					{Name: "component-sample", ContainerImage: "quay.io/redhat-appstudio/sample-image@sha256:1111"},
+				},
+			},
+		}
+		oldSnapshot = snapshot.DeepCopy()
+		oldSnapshot.Name = "snapshot-old"
+		oldSnapshot.CreationTimestamp = metav1.Time{Time: now.Add(-time.Hour)}
+		meta.SetStatusCondition(&snapshot.Status.Conditions, metav1.Condition{
+			Type:    gitops.AppStudioIntegrationStatusCondition,
+			Status:  metav1.ConditionUnknown,
+			Reason:  gitops.AppStudioIntegrationStatusInProgress,
+			Message: "Snapshot starts being tested by the integration pipeline",
+		})
+
+		scenarios = []v1beta1.IntegrationTestScenario{
+			{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "example-pass",
+					Namespace: "default",
+					Labels: map[string]string{
+						gitops.IntegrationTestScenarioQuarantinedLabel: "true",
+					},
+				},
+				Spec: v1beta1.IntegrationTestScenarioSpec{Application: application.Name},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "example-skipped",
+					Namespace: "default",
+					Labels: map[string]string{
+						"test.appstudio.openshift.io/optional": "true",
+					},
+				},
+				Spec: v1beta1.IntegrationTestScenarioSpec{Application: application.Name},
+			},
+		}
+
+		pipelineRun := tektonv1beta1.PipelineRun{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "example-pass-rerun",
+				Namespace: "default",
+			},
+			Status: tektonv1beta1.PipelineRunStatus{
+				PipelineRunStatusFields: tektonv1beta1.PipelineRunStatusFields{
+					StartTime: &metav1.Time{Time: now.Add(-5 * time.Minute)},
+				},
+			},
+		}
+		pipelineRun.Status.SetCondition(&apis.Condition{
+			Type:   apis.ConditionSucceeded,
+			Status: "Unknown",
+			Reason: "Running",
+		})
+		testRunRecord := v1beta1.TestRunRecord{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "example-pass-first",
+				Namespace: "default",
+			},
+			Spec: v1beta1.TestRunRecordSpec{
+				Application:    application.Name,
+				Scenario:       "example-pass",
+				Snapshot:       snapshot.Name,
+				PipelineRun:    "example-pass-first",
+				Outcome:        gitops.TestRunRecordFailed,
+				Counts:         v1beta1.TestRunCounts{Successes: 3, Failures: 1},
+				StartTime:      &metav1.Time{Time: now.Add(-20 * time.Minute)},
+				CompletionTime: &metav1.Time{Time: now.Add(-10 * time.Minute)},
+				FailureReason:  "Failed tasks: e2e",
+			},
+		}
+
+		mockData = []loader.MockData{
+			{ContextKey: loader.SnapshotContextKey, Resource: snapshot},
+			{ContextKey: loader.AllSnapshotsContextKey, Resource: []applicationapiv1alpha1.Snapshot{*oldSnapshot, *snapshot}},
+			{ContextKey: loader.ApplicationContextKey, Resource: application},
+			{ContextKey: loader.AllIntegrationTestScenariosContextKey, Resource: scenarios},
+			{ContextKey: loader.TestRunRecordsContextKey, Resource: []v1beta1.TestRunRecord{testRunRecord}},
+			{ContextKey: loader.PipelineRunsContextKey, Resource: []tektonv1beta1.PipelineRun{pipelineRun}},
+			{ContextKey: loader.ReleaseContextKey, Resource: &releasev1alpha1.Release{
+				ObjectMeta: metav1.ObjectMeta{Name: "release-sample", Namespace: "default"},
+				Spec:       releasev1alpha1.ReleaseSpec{ReleasePlan: "release-plan", Snapshot: snapshot.Name},
+			}},
+		}
+	})
+
+	It("rejects requests without a valid bearer token", func() {
+		Expect(serve(http.MethodGet, "/api/v1/namespaces/default/snapshots/snapshot-sample", "").Code).To(
+			Equal(http.StatusUnauthorized))
+		Expect(serve(http.MethodGet, "/api/v1/namespaces/default/snapshots/snapshot-sample", "invalid-token").Code).To(
+			Equal(http.StatusUnauthorized))
+	})
+
+	It("rejects requests of users who can't read the Snapshots of the namespace", func() {
+		authorizer.allowed = false
+		Expect(serve(http.MethodGet, "/api/v1/namespaces/default/snapshots/snapshot-sample", "valid-token").Code).To(
+			Equal(http.StatusForbidden))
+		Expect(authorizer.reviewedAttributes).To(ConsistOf(&authorizationv1.ResourceAttributes{
+			Namespace: "default",
+			Verb:      "get",
+			Group:     "appstudio.redhat.com",
+			Resource:  "snapshots",
+			Name:      "snapshot-sample",
+		}))
+	})
+
+	It("rejects requests of users who can't read every resource the status of the Snapshots exposes", func() {
+		response := serve(http.MethodGet, "/api/v1/namespaces/default/snapshots/snapshot-sample", "valid-token")
+		Expect(response.Code).To(Equal(http.StatusOK))
+		Expect(authorizer.reviewedResources).To(ConsistOf("snapshots", "applications", "integrationtestscenarios",
+			"testrunrecords", "pipelineruns", "releases", "releaseplans", "environments"))
+
+		authorizer.deniedResources = []string{"pipelineruns"}
+		response = serve(http.MethodGet, "/api/v1/namespaces/default/snapshots/snapshot-sample", "valid-token")
+		Expect(response.Code).To(Equal(http.StatusForbidden))
+		Expect(response.Body.String()).To(ContainSubstring("can't list PipelineRuns in namespace default"))
+		Expect(authorizer.reviewedAttributes).To(ContainElement(&authorizationv1.ResourceAttributes{
+			Namespace: "default",
+			Verb:      "list",
+			Group:     "tekton.dev",
+			Resource:  "pipelineruns",
+		}))
+
+		authorizer.deniedResources = []string{"pipelineruns", "releases"}
+		response = serve(http.MethodGet, "/api/v1/namespaces/default/snapshots/snapshot-sample", "valid-token")
+		Expect(response.Code).To(Equal(http.StatusForbidden))
+		Expect(response.Body.String()).To(ContainSubstring("can't list PipelineRuns, Releases in namespace default"))
+	})
+
+	It("refuses to serve plain HTTP on addresses other than the loopback ones", func() {
+		plainServer := server.NewServer(logr.Discard(), nil, ":8082")
+		err := plainServer.Start(context.Background())
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("certificate directory is required"))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		Expect(server.NewServer(logr.Discard(), nil, "127.0.0.1:0").Start(ctx)).To(Succeed())
+	})
+
+	It("doesn't serve the API until the context is cancelled when the certificate directory holds no certificate", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		tlsServer := server.NewServer(logr.Discard(), nil, ":0", server.WithCertDir(GinkgoT().TempDir()))
+		Expect(tlsServer.Start(ctx)).To(Succeed())
+	})
+
+	It("rejects unknown paths and methods", func() {
+		Expect(serve(http.MethodGet, "/api/v1/namespaces/default/components", "valid-token").Code).To(
+			Equal(http.StatusNotFound))
+		Expect(serve(http.MethodGet, "/api/v1/namespaces//snapshots/snapshot-sample", "valid-token").Code).To(
+			Equal(http.StatusNotFound))
+		Expect(serve(http.MethodPost, "/api/v1/namespaces/default/snapshots/snapshot-sample", "valid-token").Code).To(
+			Equal(http.StatusMethodNotAllowed))
+	})
+
+	It("serves the status of a Snapshot", func() {
+		response := serve(http.MethodGet, "/api/v1/namespaces/default/snapshots/snapshot-sample", "valid-token")
+		Expect(response.Code).To(Equal(http.StatusOK))
+
+		snapshotStatus := &server.SnapshotStatus{}
+		Expect(json.Unmarshal(response.Body.Bytes(), snapshotStatus)).To(Succeed())
+		Expect(snapshotStatus.Name).To(Equal(snapshot.Name))
+		Expect(snapshotStatus.Component).To(Equal("component-sample"))
+		Expect(snapshotStatus.SHA).To(Equal("12a4a35ccd08194595179815e4646c3a6c08bb77"))
+		Expect(snapshotStatus.Verdict).To(Equal(server.StatusInProgress))
+
+		Expect(snapshotStatus.Scenarios).To(HaveLen(2))
+		passScenario := snapshotStatus.Scenarios[0]
+		Expect(passScenario.Name).To(Equal("example-pass"))
+		Expect(passScenario.Quarantined).To(BeTrue())
+		Expect(passScenario.Status).To(Equal(server.StatusInProgress))
+		Expect(passScenario.PipelineRuns).To(HaveLen(2))
+		Expect(passScenario.PipelineRuns[0].Name).To(Equal("example-pass-rerun"))
+		Expect(passScenario.PipelineRuns[0].Status).To(Equal(server.StatusInProgress))
+		Expect(passScenario.PipelineRuns[1].Name).To(Equal("example-pass-first"))
+		Expect(passScenario.PipelineRuns[1].Status).To(Equal(server.StatusFailed))
+		Expect(passScenario.PipelineRuns[1].Counts).To(Equal(&v1beta1.TestRunCounts{Successes: 3, Failures: 1}))
+		Expect(passScenario.PipelineRuns[1].FailureReason).To(Equal("Failed tasks: e2e"))
+
+		skippedScenario := snapshotStatus.Scenarios[1]
+		Expect(skippedScenario.Name).To(Equal("example-skipped"))
+		Expect(skippedScenario.Optional).To(BeTrue())
+		Expect(skippedScenario.Status).To(Equal(server.StatusSkipped))
+
+		Expect(snapshotStatus.Releases).To(Equal([]server.ReleaseStatus{
+			{Name: "release-sample", ReleasePlan: "release-plan", Outcome: "InProgress"},
+		}))
+		Expect(snapshotStatus.Environments).To(HaveLen(1))
+		Expect(snapshotStatus.Environments[0].Name).To(Equal("staging"))
+		Expect(snapshotStatus.Environments[0].Deployed).To(BeTrue())
+		Expect(snapshotStatus.ReleasePlans).To(HaveLen(1))
+		Expect(snapshotStatus.ReleasePlans[0].Name).To(Equal("release-plan"))
+		Expect(snapshotStatus.ReleasePlans[0].Promoted).To(BeFalse())
+		Expect(snapshotStatus.ReleasePlans[0].Reasons).To(HaveLen(1))
+	})
+
+	It("serves the verdict of Snapshots which finished testing", func() {
+		meta.SetStatusCondition(&snapshot.Status.Conditions, metav1.Condition{
+			Type:    gitops.AppStudioTestSuceededCondition,
+			Status:  metav1.ConditionFalse,
+			Reason:  gitops.AppStudioTestSuceededConditionFailed,
+			Message: "Snapshot failed the integration tests",
+		})
+		response := serve(http.MethodGet, "/api/v1/namespaces/default/snapshots/snapshot-sample", "valid-token")
+		Expect(response.Code).To(Equal(http.StatusOK))
+
+		snapshotStatus := &server.SnapshotStatus{}
+		Expect(json.Unmarshal(response.Body.Bytes(), snapshotStatus)).To(Succeed())
+		Expect(snapshotStatus.Verdict).To(Equal(server.StatusFailed))
+		Expect(snapshotStatus.Message).To(Equal("Snapshot failed the integration tests"))
+	})
+
+	It("serves not found for missing Snapshots", func() {
+		mockData[0].Resource = nil
+		mockData[0].Err = k8serrors.NewNotFound(schema.GroupResource{Group: "appstudio.redhat.com", Resource: "snapshots"}, "snapshot-missing")
+		response := serve(http.MethodGet, "/api/v1/namespaces/default/snapshots/snapshot-missing", "valid-token")
+		Expect(response.Code).To(Equal(http.StatusNotFound))
+	})
+
+	It("serves the most recent Snapshots of an Application", func() {
+		response := serve(http.MethodGet, "/api/v1/namespaces/default/applications/application-sample/snapshots?limit=1", "valid-token")
+		Expect(response.Code).To(Equal(http.StatusOK))
+		Expect(authorizer.reviewedAttributes[0].Verb).To(Equal("list"))
+
+		snapshotStatusList := &server.SnapshotStatusList{}
+		Expect(json.Unmarshal(response.Body.Bytes(), snapshotStatusList)).To(Succeed())
+		Expect(snapshotStatusList.Items).To(HaveLen(1))
+		Expect(snapshotStatusList.Items[0].Name).To(Equal(snapshot.Name))
+
+		response = serve(http.MethodGet, "/api/v1/namespaces/default/applications/application-sample/snapshots?limit=none", "valid-token")
+		Expect(response.Code).To(Equal(http.StatusBadRequest))
+	})
+
+	It("serves the Snapshots of a pull request", func() {
+		response := serve(http.MethodGet, "/api/v1/namespaces/default/pullrequests/redhat-appstudio/integration-service/1", "valid-token")
+		Expect(response.Code).To(Equal(http.StatusOK))
+
+		pullRequestStatus := &server.PullRequestStatus{}
+		Expect(json.Unmarshal(response.Body.Bytes(), pullRequestStatus)).To(Succeed())
+		Expect(pullRequestStatus.Owner).To(Equal("redhat-appstudio"))
+		Expect(pullRequestStatus.Repository).To(Equal("integration-service"))
+		Expect(pullRequestStatus.Number).To(Equal("1"))
+		Expect(pullRequestStatus.Snapshots).To(HaveLen(2))
+		Expect(pullRequestStatus.Snapshots[0].Name).To(Equal(snapshot.Name))
+		Expect(pullRequestStatus.Snapshots[1].Name).To(Equal(oldSnapshot.Name))
+		Expect(pullRequestStatus.Snapshots[1].Verdict).To(Equal(server.StatusPending))
+
+		response = serve(http.MethodGet, "/api/v1/namespaces/default/pullrequests/redhat-appstudio/integration-service/main", "valid-token")
+		Expect(response.Code).To(Equal(http.StatusBadRequest))
+	})
+})
@@ -0,0 +1,436 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	"github.com/redhat-appstudio/integration-service/api/v1beta1"
+	"github.com/redhat-appstudio/integration-service/gitops"
+	"github.com/redhat-appstudio/integration-service/helpers"
+	"github.com/redhat-appstudio/integration-service/release"
+	"github.com/redhat-appstudio/integration-service/tekton"
+	tektonv1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/apis"
+)
+
+const (
+	// StatusPending is the status of Snapshots and IntegrationTestScenarios whose testing hasn't started yet.
+	StatusPending = "Pending"
+
+	// StatusInProgress is the status of Snapshots, IntegrationTestScenarios and PipelineRuns being tested.
+	StatusInProgress = "InProgress"
+
+	// StatusPassed is the status of Snapshots, IntegrationTestScenarios and PipelineRuns which passed testing.
+	StatusPassed = "Passed"
+
+	// StatusFailed is the status of Snapshots, IntegrationTestScenarios and PipelineRuns which failed testing.
+	StatusFailed = "Failed"
+
+	// StatusSkipped is the status of IntegrationTestScenarios which were skipped for the Snapshot.
+	StatusSkipped = "Skipped"
+
+	// StatusInvalid is the status of Snapshots which were found invalid and won't be tested.
+	StatusInvalid = "Invalid"
+
+	// optionalScenarioLabel marks the IntegrationTestScenarios whose outcome doesn't affect the Snapshot verdict.
+	optionalScenarioLabel = "test.appstudio.openshift.io/optional"
+)
+
+// SnapshotStatus is the view of a Snapshot, its testing and its promotion served by the API.
+type SnapshotStatus struct {
+	// Name is the name of the Snapshot
+	Name string `json:"name"`
+	// Namespace is the namespace of the Snapshot
+	Namespace string `json:"namespace"`
+	// Application is the name of the Application of the Snapshot
+	Application string `json:"application"`
+	// Component is the name of the Component whose build created the Snapshot, if any
+	Component string `json:"component,omitempty"`
+	// Type is the type of the Snapshot, e.g. component or override
+	Type string `json:"type,omitempty"`
+	// SHA is the commit the Snapshot was built from, if it was created by Pipelines as Code
+	SHA string `json:"sha,omitempty"`
+	// CreationTime is the time the Snapshot was created
+	CreationTime metav1.Time `json:"creationTime"`
+	// Components are the Components of the Snapshot
+	Components []applicationapiv1alpha1.SnapshotComponent `json:"components,omitempty"`
+	// Verdict is the status of the testing of the Snapshot
+	Verdict string `json:"verdict"`
+	// Message describes the verdict
+	Message string `json:"message,omitempty"`
+	// Scenarios are the statuses of the IntegrationTestScenarios testing the Snapshot
+	Scenarios []ScenarioStatus `json:"scenarios"`
+	// Releases are the Releases created for the Snapshot
+	Releases []ReleaseStatus `json:"releases,omitempty"`
+	// Environments are the promotion decisions for the Environments of the Application
+	Environments []PromotionStatus `json:"environments,omitempty"`
+	// ReleasePlans are the promotion decisions for the ReleasePlans of the Application
+	ReleasePlans []PromotionStatus `json:"releasePlans,omitempty"`
+}
+
+// ScenarioStatus is the view of the testing of a Snapshot by an IntegrationTestScenario.
+type ScenarioStatus struct {
+	// Name is the name of the IntegrationTestScenario
+	Name string `json:"name"`
+	// Status is the status of the IntegrationTestScenario, computed from the latest run of each matrix combination
+	Status string `json:"status"`
+	// Optional is true if the outcome of the IntegrationTestScenario doesn't affect the verdict
+	Optional bool `json:"optional,omitempty"`
+	// Quarantined is true if the IntegrationTestScenario was quarantined as flaky
+	Quarantined bool `json:"quarantined,omitempty"`
+	// PipelineRuns are the integration PipelineRuns which ran the IntegrationTestScenario for the Snapshot
+	PipelineRuns []PipelineRunStatus `json:"pipelineRuns,omitempty"`
+}
+
+// PipelineRunStatus is the view of an integration PipelineRun, or of the TestRunRecord kept once it was pruned.
+type PipelineRunStatus struct {
+	// Name is the name of the PipelineRun
+	Name string `json:"name"`
+	// MatrixParams are the param values of the matrix combination which was tested, if any
+	MatrixParams string `json:"matrixParams,omitempty"`
+	// Status is the status of the PipelineRun
+	Status string `json:"status"`
+	// StartTime is the time the PipelineRun started
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+	// CompletionTime is the time the PipelineRun finished
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+	// Counts are the numbers of tests reported by the tasks of the PipelineRun, once it was recorded
+	Counts *v1beta1.TestRunCounts `json:"counts,omitempty"`
+	// FailureReason describes why the PipelineRun failed
+	FailureReason string `json:"failureReason,omitempty"`
+}
+
+// ReleaseStatus is the view of a Release of a Snapshot.
+type ReleaseStatus struct {
+	// Name is the name of the Release
+	Name string `json:"name"`
+	// ReleasePlan is the name of the ReleasePlan of the Release
+	ReleasePlan string `json:"releasePlan"`
+	// Outcome is the outcome of the Release
+	Outcome string `json:"outcome"`
+	// Message describes why the Release failed
+	Message string `json:"message,omitempty"`
+}
+
+// PromotionStatus is the view of the promotion decision for an Environment or a ReleasePlan.
+type PromotionStatus struct {
+	// Name is the name of the Environment or ReleasePlan
+	Name string `json:"name"`
+
+	gitops.PromotionDecision `json:",inline"`
+}
+
+// SnapshotStatusList is the view of the Snapshots of an Application, the most recent first.
+type SnapshotStatusList struct {
+	Items []SnapshotStatus `json:"items"`
+}
+
+// PullRequestStatus is the view of the Snapshots created for a pull request, the most recent first.
+type PullRequestStatus struct {
+	// Owner is the organization or user owning the repository
+	Owner string `json:"owner"`
+	// Repository is the name of the repository
+	Repository string `json:"repository"`
+	// Number is the number of the pull request
+	Number string `json:"number"`
+	// Snapshots are the Snapshots created for the pull request
+	Snapshots []SnapshotStatus `json:"snapshots"`
+}
+
+// applicationData holds the resources shared by all the Snapshots of an Application.
+type applicationData struct {
+	application    *applicationapiv1alpha1.Application
+	scenarios      *[]v1beta1.IntegrationTestScenario
+	testRunRecords *[]v1beta1.TestRunRecord
+}
+
+// statusBuilder builds the views of Snapshots, loading the resources of each Application once per request.
+type statusBuilder struct {
+	server       *Server
+	applications map[string]*applicationData
+}
+
+// newStatusBuilder creates a statusBuilder for a single request to the given Server.
+func newStatusBuilder(server *Server) *statusBuilder {
+	return &statusBuilder{
+		server:       server,
+		applications: map[string]*applicationData{},
+	}
+}
+
+// getApplicationData returns the Application of the Snapshot together with its IntegrationTestScenarios and
+// TestRunRecords. If any of them can't be loaded, an error will be returned.
+func (b *statusBuilder) getApplicationData(ctx context.Context, snapshot *applicationapiv1alpha1.Snapshot) (*applicationData, error) {
+	if data, ok := b.applications[snapshot.Spec.Application]; ok {
+		return data, nil
+	}
+
+	application, err := b.server.loader.GetApplicationFromSnapshot(b.server.client, ctx, snapshot)
+	if err != nil {
+		return nil, err
+	}
+	scenarios, err := b.server.loader.GetAllIntegrationTestScenariosForApplication(b.server.client, ctx, application)
+	if err != nil {
+		return nil, err
+	}
+	testRunRecords, err := b.server.loader.GetAllTestRunRecordsForApplication(b.server.client, ctx, application)
+	if err != nil {
+		return nil, err
+	}
+
+	data := &applicationData{
+		application:    application,
+		scenarios:      scenarios,
+		testRunRecords: testRunRecords,
+	}
+	b.applications[snapshot.Spec.Application] = data
+
+	return data, nil
+}
+
+// getSnapshotStatus builds the view of the Snapshot. If any of the related resources can't be loaded,
+// an error will be returned.
+func (b *statusBuilder) getSnapshotStatus(ctx context.Context, snapshot *applicationapiv1alpha1.Snapshot) (*SnapshotStatus, error) {
+	data, err := b.getApplicationData(ctx, snapshot)
+	if err != nil {
+		return nil, err
+	}
+
+	verdict, message := getSnapshotVerdict(snapshot)
+	snapshotStatus := &SnapshotStatus{
+		Name:         snapshot.Name,
+		Namespace:    snapshot.Namespace,
+		Application:  snapshot.Spec.Application,
+		Component:    snapshot.GetLabels()[gitops.SnapshotComponentLabel],
+		Type:         snapshot.GetLabels()[gitops.SnapshotTypeLabel],
+		SHA:          snapshot.GetLabels()[gitops.PipelineAsCodeSHALabel],
+		CreationTime: snapshot.CreationTimestamp,
+		Components:   snapshot.Spec.Components,
+		Verdict:      verdict,
+		Message:      message,
+		Scenarios:    []ScenarioStatus{},
+	}
+
+	testRunRecords := gitops.FilterTestRunRecords(*data.testRunRecords, gitops.TestRunRecordFilter{Snapshot: snapshot.Name})
+	for _, scenario := range *gitops.GetIntegrationTestScenariosForSnapshot(snapshot, data.scenarios) {
+		scenario := scenario // G601
+		pipelineRuns, err := b.server.loader.GetAllPipelineRunsForSnapshotAndScenario(b.server.client, ctx, snapshot, &scenario)
+		if err != nil {
+			return nil, err
+		}
+		snapshotStatus.Scenarios = append(snapshotStatus.Scenarios,
+			getScenarioStatus(snapshot, &scenario, *pipelineRuns, testRunRecords))
+	}
+
+	releases, err := b.server.loader.GetReleasesWithSnapshot(b.server.client, ctx, snapshot)
+	if err != nil {
+		return nil, err
+	}
+	for _, snapshotRelease := range *releases {
+		snapshotRelease := snapshotRelease // G601
+		outcome, message := release.GetReleaseOutcome(&snapshotRelease)
+		snapshotStatus.Releases = append(snapshotStatus.Releases, ReleaseStatus{
+			Name:        snapshotRelease.Name,
+			ReleasePlan: snapshotRelease.Spec.ReleasePlan,
+			Outcome:     outcome,
+			Message:     message,
+		})
+	}
+	sort.Slice(snapshotStatus.Releases, func(i, j int) bool {
+		return snapshotStatus.Releases[i].Name < snapshotStatus.Releases[j].Name
+	})
+
+	decisions, err := gitops.GetSnapshotPromotionDecisions(snapshot)
+	if err != nil {
+		return nil, err
+	}
+	for key, decision := range decisions {
+		kind, name, found := strings.Cut(key, "/")
+		if !found {
+			continue
+		}
+		promotionStatus := PromotionStatus{Name: name, PromotionDecision: decision}
+		switch kind {
+		case "Environment":
+			snapshotStatus.Environments = append(snapshotStatus.Environments, promotionStatus)
+		case "ReleasePlan":
+			snapshotStatus.ReleasePlans = append(snapshotStatus.ReleasePlans, promotionStatus)
+		}
+	}
+	sortPromotionStatuses(snapshotStatus.Environments)
+	sortPromotionStatuses(snapshotStatus.ReleasePlans)
+
+	return snapshotStatus, nil
+}
+
+// getSnapshotVerdict returns the status of the testing of the Snapshot together with the message describing it.
+func getSnapshotVerdict(snapshot *applicationapiv1alpha1.Snapshot) (string, string) {
+	integrationCondition := meta.FindStatusCondition(snapshot.Status.Conditions, gitops.AppStudioIntegrationStatusCondition)
+	if integrationCondition == nil {
+		integrationCondition = meta.FindStatusCondition(snapshot.Status.Conditions, gitops.LegacyIntegrationStatusCondition)
+	}
+	testCondition := meta.FindStatusCondition(snapshot.Status.Conditions, gitops.AppStudioTestSuceededCondition)
+	if testCondition == nil {
+		testCondition = meta.FindStatusCondition(snapshot.Status.Conditions, gitops.LegacyTestSuceededCondition)
+	}
+
+	switch {
+	case !gitops.IsSnapshotValid(snapshot):
+		return StatusInvalid, integrationCondition.Message
+	case gitops.HaveAppStudioTestsSucceeded(snapshot):
+		return StatusPassed, testCondition.Message
+	case gitops.HaveAppStudioTestsFinished(snapshot):
+		return StatusFailed, testCondition.Message
+	case gitops.IsSnapshotNotStarted(snapshot):
+		return StatusPending, ""
+	default:
+		return StatusInProgress, integrationCondition.Message
+	}
+}
+
+// getScenarioStatus builds the view of the testing of the Snapshot by the IntegrationTestScenario from its
+// integration PipelineRuns and the TestRunRecords of the Snapshot, which are kept after the PipelineRuns are pruned.
+func getScenarioStatus(snapshot *applicationapiv1alpha1.Snapshot, scenario *v1beta1.IntegrationTestScenario,
+	pipelineRuns []tektonv1beta1.PipelineRun, testRunRecords []v1beta1.TestRunRecord) ScenarioStatus {
+	scenarioStatus := ScenarioStatus{
+		Name:        scenario.Name,
+		Optional:    helpers.HasLabelWithValue(scenario, optionalScenarioLabel, "true"),
+		Quarantined: gitops.IsIntegrationTestScenarioQuarantined(scenario),
+	}
+
+	recordedPipelineRuns := map[string]*v1beta1.TestRunRecord{}
+	for i, testRunRecord := range testRunRecords {
+		if testRunRecord.Spec.Scenario == scenario.Name {
+			recordedPipelineRuns[testRunRecord.Spec.PipelineRun] = &testRunRecords[i]
+		}
+	}
+	for _, pipelineRun := range pipelineRuns {
+		pipelineRun := pipelineRun // G601
+		testRunRecord, recorded := recordedPipelineRuns[pipelineRun.Name]
+		if recorded {
+			scenarioStatus.PipelineRuns = append(scenarioStatus.PipelineRuns, getRecordedPipelineRunStatus(testRunRecord))
+			delete(recordedPipelineRuns, pipelineRun.Name)
+			continue
+		}
+		scenarioStatus.PipelineRuns = append(scenarioStatus.PipelineRuns, getPipelineRunStatus(&pipelineRun))
+	}
+	for _, testRunRecord := range recordedPipelineRuns {
+		scenarioStatus.PipelineRuns = append(scenarioStatus.PipelineRuns, getRecordedPipelineRunStatus(testRunRecord))
+	}
+	sort.SliceStable(scenarioStatus.PipelineRuns, func(i, j int) bool {
+		return hasStartedAfter(&scenarioStatus.PipelineRuns[i], &scenarioStatus.PipelineRuns[j])
+	})
+
+	switch {
+	case gitops.IsIntegrationTestScenarioSkipped(snapshot, scenario.Name):
+		scenarioStatus.Status = StatusSkipped
+	case len(scenarioStatus.PipelineRuns) == 0:
+		scenarioStatus.Status = StatusPending
+	default:
+		scenarioStatus.Status = getLatestRunsStatus(scenarioStatus.PipelineRuns)
+	}
+
+	return scenarioStatus
+}
+
+// getPipelineRunStatus builds the view of an integration PipelineRun which wasn't recorded yet.
+func getPipelineRunStatus(pipelineRun *tektonv1beta1.PipelineRun) PipelineRunStatus {
+	pipelineRunStatus := PipelineRunStatus{
+		Name:           pipelineRun.Name,
+		MatrixParams:   pipelineRun.GetAnnotations()[tekton.MatrixParamsAnnotation],
+		Status:         StatusInProgress,
+		StartTime:      pipelineRun.Status.StartTime,
+		CompletionTime: pipelineRun.Status.CompletionTime,
+	}
+	if helpers.HasPipelineRunFinished(pipelineRun) {
+		condition := pipelineRun.Status.GetCondition(apis.ConditionSucceeded)
+		if condition.IsTrue() {
+			pipelineRunStatus.Status = StatusPassed
+		} else {
+			pipelineRunStatus.Status = StatusFailed
+			pipelineRunStatus.FailureReason = condition.Message
+		}
+	}
+
+	return pipelineRunStatus
+}
+
+// getRecordedPipelineRunStatus builds the view of an integration PipelineRun from its TestRunRecord.
+func getRecordedPipelineRunStatus(testRunRecord *v1beta1.TestRunRecord) PipelineRunStatus {
+	counts := testRunRecord.Spec.Counts
+	pipelineRunStatus := PipelineRunStatus{
+		Name:           testRunRecord.Spec.PipelineRun,
+		MatrixParams:   testRunRecord.Spec.MatrixParams,
+		Status:         StatusPassed,
+		StartTime:      testRunRecord.Spec.StartTime,
+		CompletionTime: testRunRecord.Spec.CompletionTime,
+		Counts:         &counts,
+		FailureReason:  testRunRecord.Spec.FailureReason,
+	}
+	if testRunRecord.Spec.Outcome != gitops.TestRunRecordPassed {
+		pipelineRunStatus.Status = StatusFailed
+	}
+
+	return pipelineRunStatus
+}
+
+// getLatestRunsStatus returns the status of an IntegrationTestScenario from the latest run of each of its matrix
+// combinations, given the runs sorted by their start time, the most recent first.
+func getLatestRunsStatus(pipelineRuns []PipelineRunStatus) string {
+	status := StatusPassed
+	seenMatrixParams := map[string]bool{}
+	for _, pipelineRun := range pipelineRuns {
+		if seenMatrixParams[pipelineRun.MatrixParams] {
+			continue
+		}
+		seenMatrixParams[pipelineRun.MatrixParams] = true
+		switch {
+		case pipelineRun.Status == StatusInProgress:
+			return StatusInProgress
+		case pipelineRun.Status == StatusFailed:
+			status = StatusFailed
+		}
+	}
+
+	return status
+}
+
+// hasStartedAfter returns true if the first PipelineRun started after the second one. PipelineRuns which haven't
+// started yet are the most recent ones.
+func hasStartedAfter(pipelineRun, otherPipelineRun *PipelineRunStatus) bool {
+	switch {
+	case pipelineRun.StartTime == nil:
+		return otherPipelineRun.StartTime != nil
+	case otherPipelineRun.StartTime == nil:
+		return false
+	default:
+		return otherPipelineRun.StartTime.Before(pipelineRun.StartTime)
+	}
+}
+
+// sortPromotionStatuses sorts the promotion decisions by the name of their targets.
+func sortPromotionStatuses(promotionStatuses []PromotionStatus) {
+	sort.Slice(promotionStatuses, func(i, j int) bool {
+		return promotionStatuses[i].Name < promotionStatuses[j].Name
+	})
+}
@@ -0,0 +1,158 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	ghapi "github.com/google/go-github/v45/github"
+	"github.com/redhat-appstudio/integration-service/chatops"
+)
+
+const (
+	// GitHubWebhookPath is the path GitHub delivers the webhook events of the repositories to.
+	GitHubWebhookPath = "/webhooks/github"
+
+	// maxWebhookPayloadSize is the maximum size of the webhook payloads accepted by the Server.
+	maxWebhookPayloadSize = 4 << 20
+
+	// commentHandlingTimeout is the time the CommentHandler is given to handle a pull request comment.
+	commentHandlingTimeout = 5 * time.Minute
+
+	// deliveryRetention is the time the IDs of the handled webhook deliveries are kept to ignore their redeliveries.
+	deliveryRetention = time.Hour
+)
+
+// CommentHandler handles the commands posted as comments on pull requests.
+type CommentHandler interface {
+	HandleComment(ctx context.Context, comment *chatops.PullRequestComment) error
+}
+
+// WithGitHubWebhook is an option which makes the Server receive the GitHub webhook events signed with the given
+// secret and pass the pull request comments to the CommentHandler.
+func WithGitHubWebhook(secret []byte, commentHandler CommentHandler) ServerOption {
+	return func(s *Server) {
+		s.webhookSecret = secret
+		s.commentHandler = commentHandler
+		s.webhookDeliveries = &deliveryTracker{deliveries: map[string]time.Time{}}
+	}
+}
+
+// deliveryTracker keeps the IDs of the recently handled webhook deliveries, so GitHub redeliveries of the same
+// event are only handled once.
+type deliveryTracker struct {
+	mutex      sync.Mutex
+	deliveries map[string]time.Time
+}
+
+// track records the delivery ID and returns true if it wasn't recorded yet. The IDs recorded longer than the
+// delivery retention ago are forgotten.
+func (t *deliveryTracker) track(deliveryID string) bool {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	now := time.Now()
+	for id, trackedAt := range t.deliveries {
+		if now.Sub(trackedAt) > deliveryRetention {
+			delete(t.deliveries, id)
+		}
+	}
+	if _, found := t.deliveries[deliveryID]; found {
+		return false
+	}
+	t.deliveries[deliveryID] = now
+
+	return true
+}
+
+// forget removes the delivery ID, so a redelivery of the event is handled again.
+func (t *deliveryTracker) forget(deliveryID string) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	delete(t.deliveries, deliveryID)
+}
+
+// serveGitHubWebhook validates the signature of the GitHub webhook event and passes the comments created on pull
+// requests to the CommentHandler. The comments are handled asynchronously once the delivery is acknowledged, as
+// GitHub gives up on deliveries which aren't acknowledged within 10 seconds. Redeliveries of events already handled
+// and other events are acknowledged and ignored.
+func (s *Server) serveGitHubWebhook(w http.ResponseWriter, req *http.Request) {
+	if s.commentHandler == nil || len(s.webhookSecret) == 0 {
+		s.writeError(w, http.StatusNotFound, "not found")
+		return
+	}
+	if req.Method != http.MethodPost {
+		s.writeError(w, http.StatusMethodNotAllowed, "only POST requests are supported")
+		return
+	}
+
+	req.Body = http.MaxBytesReader(w, req.Body, maxWebhookPayloadSize)
+	payload, err := ghapi.ValidatePayload(req, s.webhookSecret)
+	if err != nil {
+		s.writeError(w, http.StatusUnauthorized, "the signature of the payload is not valid")
+		return
+	}
+	event, err := ghapi.ParseWebHook(ghapi.WebHookType(req), payload)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, "the payload is not a valid webhook event")
+		return
+	}
+
+	commentEvent, ok := event.(*ghapi.IssueCommentEvent)
+	if !ok || commentEvent.GetAction() != "created" || !commentEvent.GetIssue().IsPullRequest() {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	deliveryID := ghapi.DeliveryID(req)
+	if deliveryID != "" && !s.webhookDeliveries.track(deliveryID) {
+		s.logger.Info("Ignoring a redelivery of a handled webhook event", "deliveryID", deliveryID)
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	go s.handleComment(deliveryID, &chatops.PullRequestComment{
+		Owner:         commentEvent.GetRepo().GetOwner().GetLogin(),
+		Repository:    commentEvent.GetRepo().GetName(),
+		RepositoryURL: commentEvent.GetRepo().GetHTMLURL(),
+		PullRequest:   commentEvent.GetIssue().GetNumber(),
+		Author:        commentEvent.GetComment().GetUser().GetLogin(),
+		Body:          commentEvent.GetComment().GetBody(),
+	})
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// handleComment passes the pull request comment of the webhook delivery to the CommentHandler. If it fails, the
+// delivery is forgotten, so it can be redelivered from GitHub.
+func (s *Server) handleComment(deliveryID string, comment *chatops.PullRequestComment) {
+	ctx, cancel := context.WithTimeout(context.Background(), commentHandlingTimeout)
+	defer cancel()
+
+	err := s.commentHandler.HandleComment(ctx, comment)
+	if err != nil {
+		s.logger.Error(err, "Failed to handle the pull request comment", "deliveryID", deliveryID,
+			"owner", comment.Owner, "repository", comment.Repository, "pullRequest", comment.PullRequest)
+		if deliveryID != "" {
+			s.webhookDeliveries.forget(deliveryID)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/go-logr/logr"
+	"github.com/redhat-appstudio/integration-service/chatops"
+	"github.com/redhat-appstudio/integration-service/server"
+)
+
+type MockCommentHandler struct {
+	mutex    sync.Mutex
+	comments []*chatops.PullRequestComment
+	err      error
+}
+
+func (h *MockCommentHandler) HandleComment(_ context.Context, comment *chatops.PullRequestComment) error {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.comments = append(h.comments, comment)
+	return h.err
+}
+
+func (h *MockCommentHandler) getComments() []*chatops.PullRequestComment {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	return append([]*chatops.PullRequestComment{}, h.comments...)
+}
+
+var _ = Describe("GitHub webhook", func() {
+
+	const (
+		secret         = "example-secret"
+		commentPayload = `{
+  "action": "created",
+  "issue": {"number": 1, "pull_request": {"url": "https://api.github.com/repos/redhat-appstudio/integration-service/pulls/1"}},
+  "comment": {"body": "/retest", "user": {"login": "developer"}},
+  "repository": {"name": "integration-service", "html_url": "https://github.com/redhat-appstudio/integration-service", "owner": {"login": "redhat-appstudio"}}
+}`
+	)
+
+	var (
+		apiServer      *server.Server
+		commentHandler *MockCommentHandler
+	)
+
+	deliverWithID := func(eventType, payload, signingSecret, deliveryID string) *httptest.ResponseRecorder {
+		mac := hmac.New(sha256.New, []byte(signingSecret))
+		mac.Write([]byte(payload))
+		req := httptest.NewRequest(http.MethodPost, server.GitHubWebhookPath, bytes.NewBufferString(payload))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-GitHub-Event", eventType)
+		req.Header.Set("X-Hub-Signature-256", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+		if deliveryID != "" {
+			req.Header.Set("X-GitHub-Delivery", deliveryID)
+		}
+		recorder := httptest.NewRecorder()
+		apiServer.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	deliver := func(eventType, payload, signingSecret string) *httptest.ResponseRecorder {
+		return deliverWithID(eventType, payload, signingSecret, "")
+	}
+
+	BeforeEach(func() {
+		commentHandler = &MockCommentHandler{}
+		apiServer = server.NewServer(logr.Discard(), nil, ":0",
+			server.WithGitHubWebhook([]byte(secret), commentHandler))
+	})
+
+	It("passes the comments created on pull requests to the handler", func() {
+		response := deliver("issue_comment", commentPayload, secret)
+		Expect(response.Code).To(Equal(http.StatusAccepted))
+		Eventually(commentHandler.getComments).Should(Equal([]*chatops.PullRequestComment{
+			{
+				Owner:         "redhat-appstudio",
+				Repository:    "integration-service",
+				RepositoryURL: "https://github.com/redhat-appstudio/integration-service",
+				PullRequest:   1,
+				Author:        "developer",
+				Body:          "/retest",
+			},
+		}))
+	})
+
+	It("handles the redeliveries of an event only once", func() {
+		Expect(deliverWithID("issue_comment", commentPayload, secret, "delivery-1").Code).To(Equal(http.StatusAccepted))
+		Eventually(commentHandler.getComments).Should(HaveLen(1))
+
+		Expect(deliverWithID("issue_comment", commentPayload, secret, "delivery-1").Code).To(Equal(http.StatusNoContent))
+		Consistently(commentHandler.getComments, "100ms").Should(HaveLen(1))
+
+		Expect(deliverWithID("issue_comment", commentPayload, secret, "delivery-2").Code).To(Equal(http.StatusAccepted))
+		Eventually(commentHandler.getComments).Should(HaveLen(2))
+	})
+
+	It("handles the redeliveries of events which failed to be handled", func() {
+		commentHandler.err = errors.New("failed to handle the comment")
+		Expect(deliverWithID("issue_comment", commentPayload, secret, "delivery-1").Code).To(Equal(http.StatusAccepted))
+		Eventually(commentHandler.getComments).Should(HaveLen(1))
+
+		Eventually(func() int {
+			return deliverWithID("issue_comment", commentPayload, secret, "delivery-1").Code
+		}).Should(Equal(http.StatusAccepted))
+	})
+
+	It("ignores other events", func() {
+		response := deliver("ping", `{"zen": "Keep it logically awesome."}`, secret)
+		Expect(response.Code).To(Equal(http.StatusNoContent))
+		Expect(commentHandler.getComments()).To(BeEmpty())
+	})
+
+	It("rejects events with an invalid signature", func() {
+		response := deliver("issue_comment", commentPayload, "wrong-secret")
+		Expect(response.Code).To(Equal(http.StatusUnauthorized))
+		Expect(commentHandler.getComments()).To(BeEmpty())
+	})
+
+	It("doesn't receive events unless configured", func() {
+		apiServer = server.NewServer(logr.Discard(), nil, ":0")
+		response := deliver("issue_comment", commentPayload, secret)
+		Expect(response.Code).To(Equal(http.StatusNotFound))
+	})
+})
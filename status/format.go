@@ -6,12 +6,44 @@ import (
 	"strings"
 	"text/template"
 
+	"github.com/redhat-appstudio/integration-service/gitops"
 	"github.com/redhat-appstudio/integration-service/helpers"
 )
 
-const commentTemplate = `### {{ .Title }}
+const pullRequestCommentTemplate = `{{ .Marker }}
+### {{ .Title }}
 
-{{ .Summary }}`
+Results for commit {{ shortenRevision .Latest.SHA }}:
+
+| Scenario | Status |
+| --- | --- |
+{{- range .Latest.Results }}
+| {{ .Scenario }} | {{ .Status }} |
+{{- end }}
+{{- range .Latest.Results }}{{ if .Summary }}
+
+<details>
+<summary>{{ .Scenario }}</summary>
+
+{{ .Summary }}
+</details>
+{{- end }}{{ end }}
+{{- with .SnapshotDiff }}{{ if .Components }}
+
+{{ formatSnapshotDiff . }}
+{{- end }}{{ end }}
+{{- range .Older }}
+
+<details>
+<summary>Results for older commit {{ shortenRevision .SHA }}</summary>
+
+| Scenario | Status |
+| --- | --- |
+{{- range .Results }}
+| {{ .Scenario }} | {{ .Status }} |
+{{- end }}
+</details>
+{{- end }}`
 
 const summaryTemplate = `| Task | Duration | Test Suite | Status | Details |
 | --- | --- | --- | --- | --- |
@@ -19,30 +51,62 @@ const summaryTemplate = `| Task | Duration | Test Suite | Status | Details |
 | {{ formatTaskName $tr }} | {{ $tr.GetDuration.String }} | {{ formatNamespace $tr }} | {{ formatStatus $tr }} | {{ formatDetails $tr }} |
 {{- end }}
 
-{{ formatFootnotes .TaskRuns }}`
+{{ formatFootnotes .TaskRuns }}
+{{- with .SnapshotDiff }}{{ if .Components }}
+
+{{ formatSnapshotDiff . }}
+{{- end }}{{ end }}`
+
+const snapshotDiffTemplate = `#### Changes {{ if .BaseSnapshot }}since {{ .BaseSnapshot }}{{ else }}in the Snapshot{{ end }}
+
+| Component | Change | Image | Revisions |
+| --- | --- | --- | --- |
+{{- range $c := .Components }}
+| {{ $c.Name }} | {{ $c.Change }} | {{ formatImageChange $c }} | {{ formatRevisionChange $c }} |
+{{- end }}`
 
 // SummaryTemplateData holds the data necessary to construct a PipelineRun summary.
 type SummaryTemplateData struct {
-	TaskRuns []*helpers.TaskRun
+	TaskRuns     []*helpers.TaskRun
+	SnapshotDiff *gitops.SnapshotDiff
 }
 
-// CommentTemplateData holds the data necessary to construct a PipelineRun comment.
-type CommentTemplateData struct {
-	Title   string
-	Summary string
+// ScenarioResult holds the status of an IntegrationTestScenario tested for a commit of a pull request and, once
+// it has finished, the summary of its TaskRuns.
+type ScenarioResult struct {
+	Scenario string
+	Status   string
+	Summary  string
 }
 
-// FormatSummary builds a markdown summary for a list of integration TaskRuns.
-func FormatSummary(taskRuns []*helpers.TaskRun) (string, error) {
+// CommitResults holds the results of the IntegrationTestScenarios tested for a commit of a pull request.
+type CommitResults struct {
+	SHA     string
+	Results []ScenarioResult
+}
+
+// PullRequestCommentData holds the data necessary to construct the comment reporting the results of a pull request.
+type PullRequestCommentData struct {
+	Marker       string
+	Title        string
+	Latest       CommitResults
+	Older        []CommitResults
+	SnapshotDiff *gitops.SnapshotDiff
+}
+
+// FormatSummary builds a markdown summary for a list of integration TaskRuns, followed by the changes of the
+// tested Snapshot, if any.
+func FormatSummary(taskRuns []*helpers.TaskRun, snapshotDiff *gitops.SnapshotDiff) (string, error) {
 	funcMap := template.FuncMap{
-		"formatTaskName":  FormatTaskName,
-		"formatNamespace": FormatNamespace,
-		"formatStatus":    FormatStatus,
-		"formatDetails":   FormatDetails,
-		"formatFootnotes": FormatFootnotes,
+		"formatTaskName":     FormatTaskName,
+		"formatNamespace":    FormatNamespace,
+		"formatStatus":       FormatStatus,
+		"formatDetails":      FormatDetails,
+		"formatFootnotes":    FormatFootnotes,
+		"formatSnapshotDiff": FormatSnapshotDiff,
 	}
 	buf := bytes.Buffer{}
-	data := SummaryTemplateData{TaskRuns: taskRuns}
+	data := SummaryTemplateData{TaskRuns: taskRuns, SnapshotDiff: snapshotDiff}
 	t := template.Must(template.New("").Funcs(funcMap).Parse(summaryTemplate))
 	if err := t.Execute(&buf, data); err != nil {
 		return "", err
@@ -50,16 +114,16 @@ func FormatSummary(taskRuns []*helpers.TaskRun) (string, error) {
 	return buf.String(), nil
 }
 
-// FormatComment builds a markdown comment for a list of integration TaskRuns.
-func FormatComment(title string, results []*helpers.TaskRun) (string, error) {
-	summary, err := FormatSummary(results)
-	if err != nil {
-		return "", err
+// FormatPullRequestComment builds a markdown comment with a table of the results of every IntegrationTestScenario
+// tested for the latest commit of a pull request, followed by their summaries, the changes of the tested Snapshot
+// and the collapsed results of the older commits.
+func FormatPullRequestComment(data PullRequestCommentData) (string, error) {
+	funcMap := template.FuncMap{
+		"formatSnapshotDiff": FormatSnapshotDiff,
+		"shortenRevision":    shortenRevision,
 	}
-
 	buf := bytes.Buffer{}
-	data := CommentTemplateData{Title: title, Summary: summary}
-	t := template.Must(template.New("").Parse(commentTemplate))
+	t := template.Must(template.New("").Funcs(funcMap).Parse(pullRequestCommentTemplate))
 	if err := t.Execute(&buf, data); err != nil {
 		return "", err
 	}
@@ -177,3 +241,67 @@ func FormatFootnotes(taskRuns []*helpers.TaskRun) (string, error) {
 	}
 	return strings.Join(footnotes, "\n"), nil
 }
+
+// FormatSnapshotDiff accepts the diff of a Snapshot and returns a Markdown friendly representation of the changes of its Components.
+func FormatSnapshotDiff(snapshotDiff *gitops.SnapshotDiff) (string, error) {
+	funcMap := template.FuncMap{
+		"formatImageChange":    FormatImageChange,
+		"formatRevisionChange": FormatRevisionChange,
+	}
+	buf := bytes.Buffer{}
+	t := template.Must(template.New("").Funcs(funcMap).Parse(snapshotDiffTemplate))
+	if err := t.Execute(&buf, snapshotDiff); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// FormatImageChange accepts the diff of a Component and returns a Markdown friendly representation of the change of its image digest.
+func FormatImageChange(componentDiff gitops.SnapshotComponentDiff) string {
+	return formatChange(shortenDigest(componentDiff.OldDigest), shortenDigest(componentDiff.NewDigest))
+}
+
+// FormatRevisionChange accepts the diff of a Component and returns a Markdown friendly representation of the change
+// of its git revision, linking to the range of commits if possible.
+func FormatRevisionChange(componentDiff gitops.SnapshotComponentDiff) string {
+	change := formatChange(shortenRevision(componentDiff.OldRevision), shortenRevision(componentDiff.NewRevision))
+	if componentDiff.CompareURL == "" || change == "" {
+		return change
+	}
+
+	return "[" + change + "](" + componentDiff.CompareURL + ")"
+}
+
+// formatChange returns the old and the new value separated by an arrow, leaving out the missing or unchanged ones.
+func formatChange(oldValue, newValue string) string {
+	switch {
+	case oldValue == newValue || oldValue == "":
+		return newValue
+	case newValue == "":
+		return oldValue
+	default:
+		return oldValue + " → " + newValue
+	}
+}
+
+// shortenDigest returns the first 12 characters of the hash of the given image digest.
+func shortenDigest(digest string) string {
+	_, hash, found := strings.Cut(digest, ":")
+	if !found {
+		hash = digest
+	}
+	if len(hash) > 12 {
+		hash = hash[:12]
+	}
+
+	return hash
+}
+
+// shortenRevision returns the first 7 characters of the given git revision if it is a commit SHA.
+func shortenRevision(revision string) string {
+	if len(revision) == 40 && strings.Trim(revision, "0123456789abcdef") == "" {
+		return revision[:7]
+	}
+
+	return revision
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/go-logr/logr"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/redhat-appstudio/integration-service/gitops"
 	"github.com/redhat-appstudio/integration-service/helpers"
 	"github.com/redhat-appstudio/integration-service/status"
 	tektonv1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
@@ -24,6 +25,13 @@ const expectedSummary = `| Task | Duration | Test Suite | Status | Details |
 [^example-task-3]: example note 3
 [^example-task-4]: example note 4`
 
+const expectedSnapshotDiff = `#### Changes since snapshot-sample
+
+| Component | Change | Image | Revisions |
+| --- | --- | --- | --- |
+| component-api | updated | 2b0b4a12a1b5 → 9f1e2bc2c4a8 | [a1b2c3d → f6e5d4c](https://github.com/org/api/compare/a1b2c3d4e5f60718293a4b5c6d7e8f9012345678...f6e5d4c3b2a10987654321fedcba098765432101) |
+| component-ui | added | 7c3f2e3a1d22 | main |`
+
 func newTaskRun(name string, startTime time.Time, completionTime time.Time) *helpers.TaskRun {
 	return helpers.NewTaskRunFromTektonTaskRun(logr.Discard(), name, &tektonv1beta1.TaskRunStatus{
 		TaskRunStatusFields: tektonv1beta1.TaskRunStatusFields{
@@ -131,15 +139,74 @@ var _ = Describe("Formatters", func() {
 		}
 	})
 
-	It("can construct a comment", func() {
-		comment, err := status.FormatComment("example-title", taskRuns)
+	It("can construct a pull request comment", func() {
+		summary, err := status.FormatSummary(taskRuns, nil)
+		Expect(err).To(BeNil())
+
+		comment, err := status.FormatPullRequestComment(status.PullRequestCommentData{
+			Marker: "<!-- example-marker -->",
+			Title:  "example-title",
+			Latest: status.CommitResults{
+				SHA: "12a4a35ccd08194595179815e4646c3a6c08bb77",
+				Results: []status.ScenarioResult{
+					{Scenario: "example-fail", Status: ":x: Failed", Summary: summary},
+					{Scenario: "example-running", Status: ":hourglass_flowing_sand: In progress"},
+				},
+			},
+			Older: []status.CommitResults{
+				{
+					SHA:     "a1b2c3d4e5f60718293a4b5c6d7e8f9012345678",
+					Results: []status.ScenarioResult{{Scenario: "example-fail", Status: ":heavy_check_mark: Passed"}},
+				},
+			},
+		})
 		Expect(err).To(BeNil())
-		Expect(comment).To(ContainSubstring("### example-title"))
-		Expect(comment).To(ContainSubstring(expectedSummary))
+		Expect(comment).To(HavePrefix("<!-- example-marker -->\n### example-title\n"))
+		Expect(comment).To(ContainSubstring("Results for commit 12a4a35:"))
+		Expect(comment).To(ContainSubstring("| example-fail | :x: Failed |\n| example-running | :hourglass_flowing_sand: In progress |"))
+		Expect(comment).To(ContainSubstring("<details>\n<summary>example-fail</summary>\n\n" + expectedSummary + "\n</details>"))
+		Expect(comment).ToNot(ContainSubstring("<summary>example-running</summary>"))
+		Expect(comment).To(HaveSuffix("<details>\n<summary>Results for older commit a1b2c3d</summary>\n\n" +
+			"| Scenario | Status |\n| --- | --- |\n| example-fail | :heavy_check_mark: Passed |\n</details>"))
 	})
 
 	It("can construct a summary", func() {
-		summary, err := status.FormatSummary(taskRuns)
+		summary, err := status.FormatSummary(taskRuns, nil)
+		Expect(err).To(BeNil())
+		Expect(summary).To(Equal(expectedSummary))
+	})
+
+	It("can construct a summary with the changes of the Snapshot", func() {
+		snapshotDiff := &gitops.SnapshotDiff{
+			BaseSnapshot: "snapshot-sample",
+			Components: []gitops.SnapshotComponentDiff{
+				{
+					Name:        "component-api",
+					Change:      gitops.ComponentUpdated,
+					OldDigest:   "sha256:2b0b4a12a1b5e2b8f7a4e1d1c7d0f6b1d9c1f0e4a7a8b2c3d4e5f60718293a4b",
+					NewDigest:   "sha256:9f1e2bc2c4a8d5e6f7a8b9c0d1e2f3a4b5c6d7e8f9a0b1c2d3e4f5a6b7c8d9e0",
+					OldRevision: "a1b2c3d4e5f60718293a4b5c6d7e8f9012345678",
+					NewRevision: "f6e5d4c3b2a10987654321fedcba098765432101",
+					CompareURL:  "https://github.com/org/api/compare/a1b2c3d4e5f60718293a4b5c6d7e8f9012345678...f6e5d4c3b2a10987654321fedcba098765432101",
+				},
+				{
+					Name:        "component-ui",
+					Change:      gitops.ComponentAdded,
+					NewDigest:   "sha256:7c3f2e3a1d22b4c5d6e7f8091a2b3c4d5e6f708192a3b4c5d6e7f8091a2b3c4d",
+					NewRevision: "main",
+				},
+			},
+		}
+		summary, err := status.FormatSummary(taskRuns, snapshotDiff)
+		Expect(err).To(BeNil())
+		Expect(summary).To(Equal(expectedSummary + "\n\n" + expectedSnapshotDiff))
+
+		comment, err := status.FormatPullRequestComment(status.PullRequestCommentData{SnapshotDiff: snapshotDiff})
+		Expect(err).To(BeNil())
+		Expect(comment).To(HaveSuffix(expectedSnapshotDiff))
+
+		// Snapshots without changed Components don't get a section
+		summary, err = status.FormatSummary(taskRuns, &gitops.SnapshotDiff{BaseSnapshot: "snapshot-sample"})
 		Expect(err).To(BeNil())
 		Expect(summary).To(Equal(expectedSummary))
 	})
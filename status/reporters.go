@@ -4,17 +4,26 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/url"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/go-logr/logr"
 	pacv1alpha1 "github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	"github.com/redhat-appstudio/integration-service/api/v1beta1"
 	"github.com/redhat-appstudio/integration-service/git/github"
 	"github.com/redhat-appstudio/integration-service/gitops"
 	"github.com/redhat-appstudio/integration-service/helpers"
+	"github.com/redhat-appstudio/integration-service/tekton"
 	tektonv1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	v1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/types"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
 	"knative.dev/pkg/apis"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -24,6 +33,8 @@ type GitHubReporter struct {
 	logger    logr.Logger
 	k8sClient client.Client
 	client    github.ClientInterface
+	// appID is the ID of the GitHub App the client was last authenticated as, or 0 for a webhook integration
+	appID int64
 }
 
 // GitHubReporterOption is used to extend GitHubReporter with optional parameters.
@@ -56,12 +67,12 @@ type appCredentials struct {
 	PrivateKey     []byte
 }
 
-func (r *GitHubReporter) getAppCredentials(ctx context.Context, pipelineRun *tektonv1beta1.PipelineRun) (*appCredentials, error) {
+func (r *GitHubReporter) getAppCredentials(ctx context.Context, object client.Object) (*appCredentials, error) {
 	var err error
 	var found bool
 	appInfo := appCredentials{}
 
-	appInfo.InstallationID, err = strconv.ParseInt(pipelineRun.GetAnnotations()[gitops.PipelineAsCodeInstallationIDAnnotation], 10, 64)
+	appInfo.InstallationID, err = strconv.ParseInt(object.GetAnnotations()[gitops.PipelineAsCodeInstallationIDAnnotation], 10, 64)
 	if err != nil {
 		return nil, err
 	}
@@ -93,19 +104,19 @@ func (r *GitHubReporter) getAppCredentials(ctx context.Context, pipelineRun *tek
 	return &appInfo, nil
 }
 
-func (r *GitHubReporter) getToken(ctx context.Context, pipelineRun *tektonv1beta1.PipelineRun) (string, error) {
+func (r *GitHubReporter) getToken(ctx context.Context, object client.Object) (string, error) {
 	var err error
 
-	// List all the Repository CRs in the PipelineRun's namespace
+	// List all the Repository CRs in the object's namespace
 	repos := pacv1alpha1.RepositoryList{}
-	if err = r.k8sClient.List(ctx, &repos, &client.ListOptions{Namespace: pipelineRun.Namespace}); err != nil {
+	if err = r.k8sClient.List(ctx, &repos, &client.ListOptions{Namespace: object.GetNamespace()}); err != nil {
 		return "", err
 	}
 
 	// Get the full repo URL
-	url, found := pipelineRun.GetAnnotations()[gitops.PipelineAsCodeRepoURLAnnotation]
+	url, found := object.GetAnnotations()[gitops.PipelineAsCodeRepoURLAnnotation]
 	if !found {
-		return "", fmt.Errorf("PipelineRun annotation not found %q", gitops.PipelineAsCodeRepoURLAnnotation)
+		return "", fmt.Errorf("annotation not found %q", gitops.PipelineAsCodeRepoURLAnnotation)
 	}
 
 	// Find a Repository CR with a matching URL and get its secret details
@@ -121,9 +132,9 @@ func (r *GitHubReporter) getToken(ctx context.Context, pipelineRun *tektonv1beta
 		return "", fmt.Errorf("failed to find a Repository matching URL: %q", url)
 	}
 
-	// Get the pipelines as code secret from the PipelineRun's namespace
+	// Get the pipelines as code secret from the object's namespace
 	pacSecret := v1.Secret{}
-	err = r.k8sClient.Get(ctx, types.NamespacedName{Namespace: pipelineRun.Namespace, Name: repoSecret.Name}, &pacSecret)
+	err = r.k8sClient.Get(ctx, types.NamespacedName{Namespace: object.GetNamespace(), Name: repoSecret.Name}, &pacSecret)
 	if err != nil {
 		return "", err
 	}
@@ -144,6 +155,7 @@ func (r *GitHubReporter) createCheckRunAdapter(k8sClient client.Client, ctx cont
 	if !found {
 		return nil, fmt.Errorf("PipelineRun label not found %q", gitops.SnapshotTestScenarioLabel)
 	}
+	scenario = getScenarioDisplayName(pipelineRun, scenario)
 
 	component, found := labels[gitops.SnapshotComponentLabel]
 	if !found {
@@ -180,6 +192,9 @@ func (r *GitHubReporter) createCheckRunAdapter(k8sClient client.Client, ctx cont
 		if outcome {
 			title = scenario + " has succeeded"
 			conclusion = "success"
+		} else if r.isScenarioQuarantined(ctx, pipelineRun) {
+			title = scenario + " has failed (quarantined)"
+			conclusion = "neutral"
 		} else {
 			title = scenario + " has failed"
 			conclusion = "failure"
@@ -190,7 +205,7 @@ func (r *GitHubReporter) createCheckRunAdapter(k8sClient client.Client, ctx cont
 	if err != nil {
 		return nil, fmt.Errorf("error while getting all child taskRuns from pipelineRun %s: %w", pipelineRun.Name, err)
 	}
-	summary, err := FormatSummary(taskRuns)
+	summary, err := FormatSummary(taskRuns, r.getSnapshotDiff(ctx, pipelineRun))
 	if err != nil {
 		return nil, err
 	}
@@ -237,6 +252,7 @@ func (r *GitHubReporter) createCommitStatus(k8sClient client.Client, ctx context
 	if !found {
 		return fmt.Errorf("PipelineRun label not found %q", gitops.SnapshotTestScenarioLabel)
 	}
+	scenario = getScenarioDisplayName(pipelineRun, scenario)
 
 	component, found := labels[gitops.SnapshotComponentLabel]
 	if !found {
@@ -274,6 +290,10 @@ func (r *GitHubReporter) createCommitStatus(k8sClient client.Client, ctx context
 		if outcome {
 			state = "success"
 			description = scenario + " has succeeded"
+		} else if r.isScenarioQuarantined(ctx, pipelineRun) {
+			// Commit statuses have no neutral state, a quarantined IntegrationTestScenario mustn't block the pull request
+			state = "success"
+			description = scenario + " has failed (quarantined)"
 		} else {
 			state = "failure"
 			description = scenario + " has failed"
@@ -288,7 +308,9 @@ func (r *GitHubReporter) createCommitStatus(k8sClient client.Client, ctx context
 	return nil
 }
 
-func (r *GitHubReporter) createComment(k8sClient client.Client, ctx context.Context, pipelineRun *tektonv1beta1.PipelineRun) error {
+// createOrUpdateComment keeps a single comment per pull request and component up to date with the results of every
+// IntegrationTestScenario tested for the pull request, instead of creating a new comment for each PipelineRun.
+func (r *GitHubReporter) createOrUpdateComment(k8sClient client.Client, ctx context.Context, pipelineRun *tektonv1beta1.PipelineRun) error {
 	labels := pipelineRun.GetLabels()
 
 	succeeded := pipelineRun.Status.GetCondition(apis.ConditionSucceeded)
@@ -296,8 +318,7 @@ func (r *GitHubReporter) createComment(k8sClient client.Client, ctx context.Cont
 		return nil
 	}
 
-	scenario, found := labels[gitops.SnapshotTestScenarioLabel]
-	if !found {
+	if _, found := labels[gitops.SnapshotTestScenarioLabel]; !found {
 		return fmt.Errorf("PipelineRun label not found %q", gitops.SnapshotTestScenarioLabel)
 	}
 
@@ -313,7 +334,7 @@ func (r *GitHubReporter) createComment(k8sClient client.Client, ctx context.Cont
 
 	issueNumberStr, found := pipelineRun.GetAnnotations()[gitops.PipelineAsCodePullRequestAnnotation]
 	if !found {
-		return fmt.Errorf("PipelineRun label not found %q", gitops.PipelineAsCodeURLRepositoryLabel)
+		return fmt.Errorf("PipelineRun annotation not found %q", gitops.PipelineAsCodePullRequestAnnotation)
 	}
 
 	issueNumber, err := strconv.Atoi(issueNumberStr)
@@ -321,28 +342,44 @@ func (r *GitHubReporter) createComment(k8sClient client.Client, ctx context.Cont
 		return err
 	}
 
-	outcome, err := helpers.CalculateIntegrationPipelineRunOutcome(k8sClient, ctx, r.logger, pipelineRun)
+	pipelineRuns, err := r.getPullRequestPipelineRuns(k8sClient, ctx, pipelineRun)
 	if err != nil {
 		return err
 	}
 
-	var title string
-	if outcome {
-		title = scenario + " has succeeded"
-	} else {
-		title = scenario + " has failed"
+	commitResults, err := r.getCommitResults(k8sClient, ctx, pipelineRuns)
+	if err != nil {
+		return err
 	}
 
-	taskRuns, err := helpers.GetAllChildTaskRunsForPipelineRun(r.k8sClient, ctx, r.logger, pipelineRun)
+	component := labels[gitops.SnapshotComponentLabel]
+	title := "Integration test results"
+	if component != "" {
+		title += " for " + component
+	}
+	marker := getCommentMarker(component)
+
+	comment, err := FormatPullRequestComment(PullRequestCommentData{
+		Marker:       marker,
+		Title:        title,
+		Latest:       commitResults[0],
+		Older:        commitResults[1:],
+		SnapshotDiff: r.getSnapshotDiff(ctx, pipelineRuns[0]),
+	})
 	if err != nil {
-		return fmt.Errorf("error while getting all child taskRuns from pipelineRun %s: %w", pipelineRun.Name, err)
+		return err
 	}
-	comment, err := FormatComment(title, taskRuns)
+
+	commentID, err := r.client.GetCommentIDWithMarker(ctx, owner, repo, issueNumber, marker)
 	if err != nil {
 		return err
 	}
 
-	_, err = r.client.CreateComment(ctx, owner, repo, issueNumber, comment)
+	if commentID == nil {
+		_, err = r.client.CreateComment(ctx, owner, repo, issueNumber, comment)
+	} else {
+		_, err = r.client.EditComment(ctx, owner, repo, *commentID, comment)
+	}
 	if err != nil {
 		return err
 	}
@@ -350,17 +387,195 @@ func (r *GitHubReporter) createComment(k8sClient client.Client, ctx context.Cont
 	return nil
 }
 
-// ReportStatus creates/updates CheckRuns when using GitHub App integration.
-// When using GitHub webhook integration a commit status and, in some cases, a comment is created.
-func (r *GitHubReporter) ReportStatus(k8sClient client.Client, ctx context.Context, pipelineRun *tektonv1beta1.PipelineRun) error {
-	if !helpers.HasLabelWithValue(pipelineRun, gitops.PipelineAsCodeEventTypeLabel, gitops.PipelineAsCodePullRequestType) {
+// getPullRequestPipelineRuns returns the integration PipelineRuns of the pull request and component the PipelineRun
+// belongs to, including the PipelineRun itself, sorted from the newest to the oldest.
+func (r *GitHubReporter) getPullRequestPipelineRuns(k8sClient client.Client, ctx context.Context, pipelineRun *tektonv1beta1.PipelineRun) ([]*tektonv1beta1.PipelineRun, error) {
+	labels := pipelineRun.GetLabels()
+	matchingLabels := client.MatchingLabels{
+		tekton.PipelinesTypeLabel:               tekton.PipelineTypeTest,
+		gitops.PipelineAsCodeURLOrgLabel:        labels[gitops.PipelineAsCodeURLOrgLabel],
+		gitops.PipelineAsCodeURLRepositoryLabel: labels[gitops.PipelineAsCodeURLRepositoryLabel],
+		gitops.PipelineAsCodeEventTypeLabel:     gitops.PipelineAsCodePullRequestType,
+	}
+	if component, found := labels[gitops.SnapshotComponentLabel]; found {
+		matchingLabels[gitops.SnapshotComponentLabel] = component
+	}
+
+	pipelineRunList := &tektonv1beta1.PipelineRunList{}
+	err := k8sClient.List(ctx, pipelineRunList, client.InNamespace(pipelineRun.Namespace), matchingLabels)
+	if err != nil {
+		return nil, err
+	}
+
+	// The reported PipelineRun is always included as is, since the listed copy may be outdated
+	pullRequest := pipelineRun.GetAnnotations()[gitops.PipelineAsCodePullRequestAnnotation]
+	pipelineRuns := []*tektonv1beta1.PipelineRun{pipelineRun}
+	for i, item := range pipelineRunList.Items {
+		if item.Name == pipelineRun.Name || item.GetAnnotations()[gitops.PipelineAsCodePullRequestAnnotation] != pullRequest {
+			continue
+		}
+		pipelineRuns = append(pipelineRuns, &pipelineRunList.Items[i])
+	}
+
+	sort.SliceStable(pipelineRuns, func(i, j int) bool {
+		return pipelineRuns[j].CreationTimestamp.Before(&pipelineRuns[i].CreationTimestamp)
+	})
+
+	return pipelineRuns, nil
+}
+
+// getCommitResults groups the PipelineRuns, sorted from the newest to the oldest, by the commit they tested and
+// returns the results of the latest PipelineRun of each IntegrationTestScenario, starting with the latest commit.
+// Only the results of the latest commit include the summaries of the finished PipelineRuns.
+func (r *GitHubReporter) getCommitResults(k8sClient client.Client, ctx context.Context, pipelineRuns []*tektonv1beta1.PipelineRun) ([]CommitResults, error) {
+	commitResults := []CommitResults{}
+	commitIndexes := map[string]int{}
+	reportedScenarios := map[string]bool{}
+	for _, pipelineRun := range pipelineRuns {
+		scenario, found := pipelineRun.GetLabels()[gitops.SnapshotTestScenarioLabel]
+		if !found {
+			continue
+		}
+		scenario = getScenarioDisplayName(pipelineRun, scenario)
+
+		sha := pipelineRun.GetLabels()[gitops.PipelineAsCodeSHALabel]
+		if reportedScenarios[sha+"/"+scenario] {
+			continue
+		}
+		reportedScenarios[sha+"/"+scenario] = true
+
+		index, found := commitIndexes[sha]
+		if !found {
+			index = len(commitResults)
+			commitIndexes[sha] = index
+			commitResults = append(commitResults, CommitResults{SHA: sha})
+		}
+
+		result, err := r.getScenarioResult(k8sClient, ctx, pipelineRun, scenario, index == 0)
+		if err != nil {
+			return nil, err
+		}
+		commitResults[index].Results = append(commitResults[index].Results, result)
+	}
+
+	for _, commit := range commitResults {
+		sort.SliceStable(commit.Results, func(i, j int) bool {
+			return commit.Results[i].Scenario < commit.Results[j].Scenario
+		})
+	}
+
+	return commitResults, nil
+}
+
+// getScenarioResult returns the status of the IntegrationTestScenario tested by the PipelineRun and, if requested,
+// the summary of its TaskRuns once it has finished.
+func (r *GitHubReporter) getScenarioResult(k8sClient client.Client, ctx context.Context, pipelineRun *tektonv1beta1.PipelineRun, scenario string, withSummary bool) (ScenarioResult, error) {
+	result := ScenarioResult{Scenario: scenario}
+	if !helpers.HasPipelineRunFinished(pipelineRun) {
+		result.Status = ":hourglass_flowing_sand: In progress"
+		return result, nil
+	}
+
+	outcome, err := helpers.CalculateIntegrationPipelineRunOutcome(k8sClient, ctx, r.logger, pipelineRun)
+	if err != nil {
+		return result, err
+	}
+
+	if outcome {
+		result.Status = ":heavy_check_mark: Passed"
+	} else if r.isScenarioQuarantined(ctx, pipelineRun) {
+		result.Status = ":warning: Failed (quarantined)"
+	} else {
+		result.Status = ":x: Failed"
+	}
+
+	if withSummary {
+		taskRuns, err := helpers.GetAllChildTaskRunsForPipelineRun(r.k8sClient, ctx, r.logger, pipelineRun)
+		if err != nil {
+			return result, fmt.Errorf("error while getting all child taskRuns from pipelineRun %s: %w", pipelineRun.Name, err)
+		}
+		result.Summary, err = FormatSummary(taskRuns, nil)
+		if err != nil {
+			return result, err
+		}
+	}
+
+	return result, nil
+}
+
+// getCommentMarker returns the hidden marker identifying the comment reporting the results of the given component,
+// or of the whole pull request if the component is empty.
+func getCommentMarker(component string) string {
+	if component == "" {
+		return "<!-- integration-service results -->"
+	}
+
+	return "<!-- integration-service results component=" + component + " -->"
+}
+
+// getSnapshotDiff returns the diff recorded on the Snapshot tested by the PipelineRun. The diff only adds context to
+// the reported status, so nil is returned when it can't be loaded.
+func (r *GitHubReporter) getSnapshotDiff(ctx context.Context, pipelineRun *tektonv1beta1.PipelineRun) *gitops.SnapshotDiff {
+	snapshotName, found := pipelineRun.GetLabels()[tekton.SnapshotNameLabel]
+	if !found {
 		return nil
 	}
 
+	snapshot := &applicationapiv1alpha1.Snapshot{}
+	err := r.k8sClient.Get(ctx, types.NamespacedName{Namespace: pipelineRun.Namespace, Name: snapshotName}, snapshot)
+	if err != nil {
+		r.logger.Error(err, "failed to get the Snapshot of the PipelineRun to report its changes", "snapshot.Name", snapshotName)
+		return nil
+	}
+
+	snapshotDiff, err := gitops.GetSnapshotDiff(snapshot)
+	if err != nil {
+		r.logger.Error(err, "failed to get the changes of the Snapshot", "snapshot.Name", snapshotName)
+		return nil
+	}
+
+	return snapshotDiff
+}
+
+// isScenarioQuarantined returns true if the IntegrationTestScenario tested by the PipelineRun is quarantined, so its
+// failure is reported without blocking. False is returned when the IntegrationTestScenario can't be loaded.
+func (r *GitHubReporter) isScenarioQuarantined(ctx context.Context, pipelineRun *tektonv1beta1.PipelineRun) bool {
+	scenarioName, found := pipelineRun.GetLabels()[tekton.ScenarioNameLabel]
+	if !found {
+		return false
+	}
+
+	integrationTestScenario := &v1beta1.IntegrationTestScenario{}
+	err := r.k8sClient.Get(ctx, types.NamespacedName{Namespace: pipelineRun.Namespace, Name: scenarioName}, integrationTestScenario)
+	if err != nil {
+		if !k8serrors.IsNotFound(err) {
+			r.logger.Error(err, "failed to get the IntegrationTestScenario of the PipelineRun to report its quarantine",
+				"integrationTestScenario.Name", scenarioName)
+		}
+		return false
+	}
+
+	return gitops.IsIntegrationTestScenarioQuarantined(integrationTestScenario)
+}
+
+// getScenarioDisplayName returns the name of the IntegrationTestScenario followed by the matrix param values when the
+// PipelineRun tests a matrix combination, e.g. "scenario (os=rhel9, arch=arm64)", so each combination is reported separately.
+func getScenarioDisplayName(pipelineRun *tektonv1beta1.PipelineRun, scenario string) string {
+	matrixParams, found := pipelineRun.GetAnnotations()[tekton.MatrixParamsAnnotation]
+	if !found || matrixParams == "" {
+		return scenario
+	}
+
+	return scenario + " (" + matrixParams + ")"
+}
+
+// Authenticate sets the token of the GitHub client of the reporter to the one that should be used for the
+// repository the object comes from. The ID of the GitHub App is kept to find the CheckRuns it created.
+func (r *GitHubReporter) Authenticate(ctx context.Context, object client.Object) error {
 	// Existence of the Pipelines as Code installation ID annotation signals configuration using GitHub App integration.
 	// If it doesn't exist, GitHub webhook integration is configured.
-	if helpers.HasAnnotation(pipelineRun, gitops.PipelineAsCodeInstallationIDAnnotation) {
-		creds, err := r.getAppCredentials(ctx, pipelineRun)
+	if helpers.HasAnnotation(object, gitops.PipelineAsCodeInstallationIDAnnotation) {
+		creds, err := r.getAppCredentials(ctx, object)
 		if err != nil {
 			return err
 		}
@@ -371,13 +586,41 @@ func (r *GitHubReporter) ReportStatus(k8sClient client.Client, ctx context.Conte
 		}
 
 		r.client.SetOAuthToken(ctx, token)
+		r.appID = creds.AppID
+	} else {
+		token, err := r.getToken(ctx, object)
+		if err != nil {
+			return err
+		}
+
+		r.client.SetOAuthToken(ctx, token)
+		r.appID = 0
+	}
 
+	return nil
+}
+
+// ReportStatus creates/updates CheckRuns when using GitHub App integration.
+// When using GitHub webhook integration a commit status and, in some cases, a comment is created.
+func (r *GitHubReporter) ReportStatus(k8sClient client.Client, ctx context.Context, pipelineRun *tektonv1beta1.PipelineRun) error {
+	if !helpers.HasLabelWithValue(pipelineRun, gitops.PipelineAsCodeEventTypeLabel, gitops.PipelineAsCodePullRequestType) {
+		return nil
+	}
+
+	err := r.Authenticate(ctx, pipelineRun)
+	if err != nil {
+		return err
+	}
+
+	// Existence of the Pipelines as Code installation ID annotation signals configuration using GitHub App integration.
+	// If it doesn't exist, GitHub webhook integration is configured.
+	if helpers.HasAnnotation(pipelineRun, gitops.PipelineAsCodeInstallationIDAnnotation) {
 		checkRun, err := r.createCheckRunAdapter(k8sClient, ctx, pipelineRun)
 		if err != nil {
 			return err
 		}
 
-		checkRunID, err := r.client.GetCheckRunID(ctx, checkRun.Owner, checkRun.Repository, checkRun.SHA, checkRun.ExternalID, creds.AppID)
+		checkRunID, err := r.client.GetCheckRunID(ctx, checkRun.Owner, checkRun.Repository, checkRun.SHA, checkRun.ExternalID, r.appID)
 		if err != nil {
 			return err
 		}
@@ -392,23 +635,174 @@ func (r *GitHubReporter) ReportStatus(k8sClient client.Client, ctx context.Conte
 			return err
 		}
 	} else {
-		token, err := r.getToken(ctx, pipelineRun)
+		err = r.createCommitStatus(k8sClient, ctx, pipelineRun)
 		if err != nil {
 			return err
 		}
 
-		r.client.SetOAuthToken(ctx, token)
-
-		err = r.createCommitStatus(k8sClient, ctx, pipelineRun)
+		err = r.createOrUpdateComment(k8sClient, ctx, pipelineRun)
 		if err != nil {
 			return err
 		}
+	}
+
+	return nil
+}
+
+// ReportReleaseStatus creates a commit status reporting the progress and outcome of the Releases of the Snapshot
+// to the commit which triggered the build of the Snapshot.
+func (r *GitHubReporter) ReportReleaseStatus(k8sClient client.Client, ctx context.Context, snapshot *applicationapiv1alpha1.Snapshot) error {
+	var (
+		state       string
+		description string
+	)
+
+	condition := meta.FindStatusCondition(snapshot.Status.Conditions, gitops.AppStudioReleasedCondition)
+	if condition == nil {
+		return nil
+	}
+
+	labels := snapshot.GetLabels()
+
+	owner, found := labels[gitops.PipelineAsCodeURLOrgLabel]
+	if !found {
+		return fmt.Errorf("Snapshot label not found %q", gitops.PipelineAsCodeURLOrgLabel)
+	}
+
+	repo, found := labels[gitops.PipelineAsCodeURLRepositoryLabel]
+	if !found {
+		return fmt.Errorf("Snapshot label not found %q", gitops.PipelineAsCodeURLRepositoryLabel)
+	}
+
+	SHA, found := labels[gitops.PipelineAsCodeSHALabel]
+	if !found {
+		return fmt.Errorf("Snapshot label not found %q", gitops.PipelineAsCodeSHALabel)
+	}
+
+	err := r.Authenticate(ctx, snapshot)
+	if err != nil {
+		return err
+	}
+
+	switch condition.Reason {
+	case gitops.AppStudioReleasedSucceeded:
+		state = "success"
+		description = "Release has succeeded"
+	case gitops.AppStudioReleasedFailed:
+		state = "failure"
+		description = "Release has failed"
+	default:
+		state = "pending"
+		description = "Release is in progress"
+	}
+
+	statusContext := NamePrefix + " / " + snapshot.Spec.Application + " / release"
+	if component, found := labels[gitops.SnapshotComponentLabel]; found {
+		statusContext = NamePrefix + " / " + component + " / release"
+	}
+
+	_, err = r.client.CreateCommitStatus(ctx, owner, repo, SHA, state, description, statusContext)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ReportDeploymentStatus creates a GitHub deployment of the git revision of each Component of the Snapshot to the
+// Environment, unless one exists already, and sets its status to the given state, so the Environments of the
+// repositories show which revisions are deployed where.
+func (r *GitHubReporter) ReportDeploymentStatus(k8sClient client.Client, ctx context.Context, snapshot *applicationapiv1alpha1.Snapshot, environmentName string, state gitops.DeploymentState) error {
+	var (
+		deploymentState string
+		description     string
+	)
+
+	switch state {
+	case gitops.DeploymentSucceeded:
+		deploymentState = "success"
+		description = "has been deployed"
+	case gitops.DeploymentFailed:
+		deploymentState = "failure"
+		description = "has failed to deploy"
+	case gitops.DeploymentInactive:
+		deploymentState = "inactive"
+		description = "has been replaced"
+	default:
+		deploymentState = "in_progress"
+		description = "is being deployed"
+	}
+
+	err := r.Authenticate(ctx, snapshot)
+	if err != nil {
+		return err
+	}
+
+	// A failure to report the deployment to the repository of one Component shouldn't stop the reporting
+	// to the repositories of the other Components
+	var errs []error
+	for _, component := range snapshot.Spec.Components {
+		gitSource := component.Source.GitSource
+		if gitSource == nil || gitSource.Revision == "" {
+			continue
+		}
+
+		owner, repo, found := getGitHubRepository(gitSource.URL)
+		if !found {
+			continue
+		}
+
+		err = r.reportComponentDeploymentStatus(ctx, owner, repo, gitSource.Revision, environmentName, state, deploymentState,
+			"Snapshot "+snapshot.Name+" of "+snapshot.Spec.Application,
+			component.Name+" of Snapshot "+snapshot.Name+" "+description)
+		if err != nil {
+			r.logger.Error(err, "Failed to report the deployment status to the repository of the Component",
+				"component.Name", component.Name, "owner", owner, "repo", repo)
+			errs = append(errs, fmt.Errorf("component %s: %w", component.Name, err))
+		}
+	}
+
+	return kerrors.NewAggregate(errs)
+}
+
+// reportComponentDeploymentStatus creates a deployment status for the GitHub deployment of the revision to the
+// environment, creating the deployment first if the revision was never reported as deployed to it.
+func (r *GitHubReporter) reportComponentDeploymentStatus(ctx context.Context, owner, repo, revision, environmentName string, state gitops.DeploymentState, deploymentState, deploymentDescription, statusDescription string) error {
+	deploymentID, err := r.client.GetDeploymentID(ctx, owner, repo, revision, environmentName)
+	if err != nil {
+		return err
+	}
+
+	if deploymentID == nil {
+		// A revision which was never reported as deployed doesn't need to be reported as replaced
+		if state == gitops.DeploymentInactive {
+			return nil
+		}
 
-		err = r.createComment(k8sClient, ctx, pipelineRun)
+		id, err := r.client.CreateDeployment(ctx, owner, repo, revision, environmentName, deploymentDescription)
 		if err != nil {
 			return err
 		}
+		deploymentID = &id
 	}
 
-	return nil
+	_, err = r.client.CreateDeploymentStatus(ctx, owner, repo, *deploymentID, deploymentState, statusDescription)
+
+	return err
+}
+
+// getGitHubRepository returns the owner and the name of the GitHub repository the git URL points to. If the URL
+// doesn't point to a GitHub repository, false is returned.
+func getGitHubRepository(gitURL string) (string, string, bool) {
+	repoURL, err := url.Parse(strings.TrimSuffix(strings.TrimSuffix(gitURL, "/"), ".git"))
+	if err != nil || repoURL.Host != "github.com" {
+		return "", "", false
+	}
+
+	owner, repo, found := strings.Cut(strings.Trim(repoURL.Path, "/"), "/")
+	if !found || owner == "" || repo == "" || strings.Contains(repo, "/") {
+		return "", "", false
+	}
+
+	return owner, repo, true
 }
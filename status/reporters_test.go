@@ -2,6 +2,7 @@ package status_test
 
 import (
 	"context"
+	"errors"
 	"strings"
 	"time"
 
@@ -9,10 +10,14 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 
 	"github.com/go-logr/logr"
+	ghapi "github.com/google/go-github/v45/github"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	pacv1alpha1 "github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	"github.com/redhat-appstudio/integration-service/api/v1beta1"
 	"github.com/redhat-appstudio/integration-service/git/github"
+	"github.com/redhat-appstudio/integration-service/gitops"
 	"github.com/redhat-appstudio/integration-service/status"
 	tektonv1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	v1 "k8s.io/api/core/v1"
@@ -39,6 +44,7 @@ type UpdateCheckRunResult struct {
 }
 
 type GetCheckRunIDResult struct {
+	appID int64
 	ID    *int64
 	Error error
 }
@@ -50,6 +56,19 @@ type CreateCommentResult struct {
 	issueNumber int
 }
 
+type GetCommentIDWithMarkerResult struct {
+	ID     *int64
+	Error  error
+	marker string
+}
+
+type EditCommentResult struct {
+	ID        int64
+	Error     error
+	commentID int64
+	body      string
+}
+
 type CreateCommitStatusResult struct {
 	ID            int64
 	Error         error
@@ -58,13 +77,43 @@ type CreateCommitStatusResult struct {
 	statusContext string
 }
 
+type GetDeploymentIDResult struct {
+	ID    *int64
+	Error error
+}
+
+type CreateDeploymentResult struct {
+	ID          int64
+	Error       error
+	refs        []string
+	environment string
+}
+
+type CreateDeploymentStatusResult struct {
+	ID           int64
+	Error        error
+	state        string
+	descriptions []string
+}
+
+type GetUserPermissionResult struct {
+	Permission string
+	Error      error
+}
+
 type MockGitHubClient struct {
 	CreateAppInstallationTokenResult
 	CreateCheckRunResult
 	UpdateCheckRunResult
 	GetCheckRunIDResult
 	CreateCommentResult
+	GetCommentIDWithMarkerResult
+	EditCommentResult
 	CreateCommitStatusResult
+	GetUserPermissionResult
+	GetDeploymentIDResult
+	CreateDeploymentResult
+	CreateDeploymentStatusResult
 }
 
 func (c *MockGitHubClient) CreateAppInstallationToken(ctx context.Context, appID int64, installationID int64, privateKey []byte) (string, error) {
@@ -83,7 +132,8 @@ func (c *MockGitHubClient) UpdateCheckRun(ctx context.Context, checkRunID int64,
 	return c.UpdateCheckRunResult.Error
 }
 
-func (c *MockGitHubClient) GetCheckRunID(context.Context, string, string, string, string, int64) (*int64, error) {
+func (c *MockGitHubClient) GetCheckRunID(_ context.Context, _ string, _ string, _ string, _ string, appID int64) (*int64, error) {
+	c.GetCheckRunIDResult.appID = appID
 	return c.GetCheckRunIDResult.ID, c.GetCheckRunIDResult.Error
 }
 
@@ -93,6 +143,21 @@ func (c *MockGitHubClient) CreateComment(ctx context.Context, owner string, repo
 	return c.CreateCommentResult.ID, c.CreateCommentResult.Error
 }
 
+func (c *MockGitHubClient) ListComments(ctx context.Context, owner string, repo string, issueNumber int) ([]*ghapi.IssueComment, error) {
+	return nil, nil
+}
+
+func (c *MockGitHubClient) GetCommentIDWithMarker(ctx context.Context, owner string, repo string, issueNumber int, marker string) (*int64, error) {
+	c.GetCommentIDWithMarkerResult.marker = marker
+	return c.GetCommentIDWithMarkerResult.ID, c.GetCommentIDWithMarkerResult.Error
+}
+
+func (c *MockGitHubClient) EditComment(ctx context.Context, owner string, repo string, commentID int64, body string) (int64, error) {
+	c.EditCommentResult.commentID = commentID
+	c.EditCommentResult.body = body
+	return c.EditCommentResult.ID, c.EditCommentResult.Error
+}
+
 func (c *MockGitHubClient) CreateCommitStatus(ctx context.Context, owner string, repo string, SHA string, state string, description string, statusContext string) (int64, error) {
 	c.CreateCommitStatusResult.state = state
 	c.CreateCommitStatusResult.description = description
@@ -100,6 +165,26 @@ func (c *MockGitHubClient) CreateCommitStatus(ctx context.Context, owner string,
 	return c.CreateCommitStatusResult.ID, c.CreateCommitStatusResult.Error
 }
 
+func (c *MockGitHubClient) GetUserPermission(ctx context.Context, owner string, repo string, user string) (string, error) {
+	return c.GetUserPermissionResult.Permission, c.GetUserPermissionResult.Error
+}
+
+func (c *MockGitHubClient) GetDeploymentID(ctx context.Context, owner string, repo string, ref string, environment string) (*int64, error) {
+	return c.GetDeploymentIDResult.ID, c.GetDeploymentIDResult.Error
+}
+
+func (c *MockGitHubClient) CreateDeployment(ctx context.Context, owner string, repo string, ref string, environment string, description string) (int64, error) {
+	c.CreateDeploymentResult.refs = append(c.CreateDeploymentResult.refs, ref)
+	c.CreateDeploymentResult.environment = environment
+	return c.CreateDeploymentResult.ID, c.CreateDeploymentResult.Error
+}
+
+func (c *MockGitHubClient) CreateDeploymentStatus(ctx context.Context, owner string, repo string, deploymentID int64, state string, description string) (int64, error) {
+	c.CreateDeploymentStatusResult.state = state
+	c.CreateDeploymentStatusResult.descriptions = append(c.CreateDeploymentStatusResult.descriptions, description)
+	return c.CreateDeploymentStatusResult.ID, c.CreateDeploymentStatusResult.Error
+}
+
 type MockK8sClient struct {
 	getInterceptor     func(key client.ObjectKey, obj client.Object)
 	listInterceptor    func(list client.ObjectList)
@@ -396,9 +481,38 @@ var _ = Describe("GitHubReporter", func() {
 			Expect(err).ToNot(BeNil())
 		})
 
+		It("reports a separate CheckRun for each matrix combination", func() {
+			pipelineRun.Annotations["test.appstudio.openshift.io/matrix-params"] = "os=rhel9, arch=arm64"
+			Expect(reporter.ReportStatus(mockK8sClient, context.TODO(), pipelineRun)).To(BeNil())
+			Expect(mockGitHubClient.CreateCheckRunResult.cra.Title).To(Equal("example-pass (os=rhel9, arch=arm64) has started"))
+			Expect(mockGitHubClient.CreateCheckRunResult.cra.Name).To(Equal("Red Hat Trusted App Test / devfile-sample-go-basic / example-pass (os=rhel9, arch=arm64)"))
+		})
+
+		It("reports the changes of the tested Snapshot in the CheckRun summary", func() {
+			pipelineRun.Labels["appstudio.openshift.io/snapshot"] = "snapshot-sample"
+			getInterceptor := mockK8sClient.getInterceptor
+			mockK8sClient.getInterceptor = func(key client.ObjectKey, obj client.Object) {
+				getInterceptor(key, obj)
+				if snapshot, ok := obj.(*applicationapiv1alpha1.Snapshot); ok && key.Name == "snapshot-sample" {
+					snapshot.Annotations = map[string]string{
+						gitops.SnapshotDiffAnnotation: `{"baseSnapshot":"snapshot-previous","components":[{"name":"devfile-sample-go-basic",` +
+							`"change":"updated","gitUrl":"https://github.com/devfile-sample/devfile-sample-go-basic",` +
+							`"oldRevision":"v1","newRevision":"v2",` +
+							`"compareUrl":"https://github.com/devfile-sample/devfile-sample-go-basic/compare/v1...v2"}]}`,
+					}
+				}
+			}
+
+			Expect(reporter.ReportStatus(mockK8sClient, context.TODO(), pipelineRun)).To(BeNil())
+			Expect(mockGitHubClient.CreateCheckRunResult.cra.Summary).To(ContainSubstring("#### Changes since snapshot-previous"))
+			Expect(mockGitHubClient.CreateCheckRunResult.cra.Summary).To(ContainSubstring(
+				"| devfile-sample-go-basic | updated |  | [v1 → v2](https://github.com/devfile-sample/devfile-sample-go-basic/compare/v1...v2) |"))
+		})
+
 		It("reports status via CheckRuns", func() {
 			// Create an in progress CheckRun
 			Expect(reporter.ReportStatus(mockK8sClient, context.TODO(), pipelineRun)).To(BeNil())
+			Expect(mockGitHubClient.GetCheckRunIDResult.appID).To(Equal(int64(456)))
 			Expect(mockGitHubClient.CreateCheckRunResult.cra.Title).To(Equal("example-pass has started"))
 			Expect(mockGitHubClient.CreateCheckRunResult.cra.Conclusion).To(Equal(""))
 			Expect(mockGitHubClient.CreateCheckRunResult.cra.ExternalID).To(Equal(pipelineRun.Name))
@@ -426,6 +540,21 @@ var _ = Describe("GitHubReporter", func() {
 			Expect(mockGitHubClient.UpdateCheckRunResult.cra.Title).To(Equal("example-pass has failed"))
 			Expect(mockGitHubClient.UpdateCheckRunResult.cra.Conclusion).To(Equal("failure"))
 		})
+
+		It("reports the failure of a quarantined scenario as neutral", func() {
+			getInterceptor := mockK8sClient.getInterceptor
+			mockK8sClient.getInterceptor = func(key client.ObjectKey, obj client.Object) {
+				getInterceptor(key, obj)
+				if scenario, ok := obj.(*v1beta1.IntegrationTestScenario); ok && key.Name == "example-pass" {
+					scenario.Labels = map[string]string{gitops.IntegrationTestScenarioQuarantinedLabel: "true"}
+				}
+			}
+
+			setPipelineRunOutcome(pipelineRun, failedTaskRun)
+			Expect(reporter.ReportStatus(mockK8sClient, context.TODO(), pipelineRun)).To(BeNil())
+			Expect(mockGitHubClient.CreateCheckRunResult.cra.Title).To(Equal("example-pass has failed (quarantined)"))
+			Expect(mockGitHubClient.CreateCheckRunResult.cra.Conclusion).To(Equal("neutral"))
+		})
 	})
 
 	Context("when provided GitHub webhook integration credentials", func() {
@@ -489,18 +618,89 @@ var _ = Describe("GitHubReporter", func() {
 				Status: "True",
 			})
 			Expect(reporter.ReportStatus(mockK8sClient, context.TODO(), pipelineRun)).To(BeNil())
-			Expect(mockGitHubClient.CreateCommentResult.body).To(ContainSubstring("# example-pass has succeeded"))
+			Expect(mockGitHubClient.GetCommentIDWithMarkerResult.marker).To(Equal("<!-- integration-service results component=devfile-sample-go-basic -->"))
+			Expect(mockGitHubClient.CreateCommentResult.body).To(HavePrefix(mockGitHubClient.GetCommentIDWithMarkerResult.marker))
+			Expect(mockGitHubClient.CreateCommentResult.body).To(ContainSubstring("### Integration test results for devfile-sample-go-basic"))
+			Expect(mockGitHubClient.CreateCommentResult.body).To(ContainSubstring("| example-pass | :heavy_check_mark: Passed |"))
 			Expect(mockGitHubClient.CreateCommentResult.issueNumber).To(Equal(999))
 		})
 
 		It("creates a comment for a failed PipelineRun", func() {
 			setPipelineRunOutcome(pipelineRun, failedTaskRun)
 			Expect(reporter.ReportStatus(mockK8sClient, context.TODO(), pipelineRun)).To(BeNil())
-			called := strings.Contains(mockGitHubClient.CreateCommentResult.body, "# example-pass has failed")
+			called := strings.Contains(mockGitHubClient.CreateCommentResult.body, "| example-pass | :x: Failed |")
 			Expect(called).To(BeTrue())
 			Expect(mockGitHubClient.CreateCommentResult.issueNumber).To(Equal(999))
 		})
 
+		It("updates the existing comment of the pull request", func() {
+			var commentID int64 = 60
+			mockGitHubClient.GetCommentIDWithMarkerResult.ID = &commentID
+
+			setPipelineRunOutcome(pipelineRun, failedTaskRun)
+			Expect(reporter.ReportStatus(mockK8sClient, context.TODO(), pipelineRun)).To(BeNil())
+			Expect(mockGitHubClient.CreateCommentResult.body).To(Equal(""))
+			Expect(mockGitHubClient.EditCommentResult.commentID).To(Equal(commentID))
+			Expect(mockGitHubClient.EditCommentResult.body).To(ContainSubstring("| example-pass | :x: Failed |"))
+		})
+
+		It("reports every scenario of the latest commit and collapses the older commits", func() {
+			now := time.Now()
+			pipelineRun.CreationTimestamp = metav1.NewTime(now)
+			setPipelineRunOutcome(pipelineRun, failedTaskRun)
+
+			runningPipelineRun := pipelineRun.DeepCopy()
+			runningPipelineRun.Name = "test-pipelinerun-running"
+			runningPipelineRun.Labels["test.appstudio.openshift.io/scenario"] = "example-running"
+			runningPipelineRun.Status = tektonv1beta1.PipelineRunStatus{}
+
+			olderPipelineRun := pipelineRun.DeepCopy()
+			olderPipelineRun.Name = "test-pipelinerun-older"
+			olderPipelineRun.CreationTimestamp = metav1.NewTime(now.Add(-time.Hour))
+			olderPipelineRun.Labels["pac.test.appstudio.openshift.io/sha"] = "a1b2c3d4e5f60718293a4b5c6d7e8f9012345678"
+			setPipelineRunOutcome(olderPipelineRun, successfulTaskRun)
+
+			otherPullRequestPipelineRun := runningPipelineRun.DeepCopy()
+			otherPullRequestPipelineRun.Name = "test-pipelinerun-other"
+			otherPullRequestPipelineRun.Labels["test.appstudio.openshift.io/scenario"] = "example-other"
+			otherPullRequestPipelineRun.Annotations["pac.test.appstudio.openshift.io/pull-request"] = "1000"
+
+			listInterceptor := mockK8sClient.listInterceptor
+			mockK8sClient.listInterceptor = func(list client.ObjectList) {
+				listInterceptor(list)
+				if pipelineRunList, ok := list.(*tektonv1beta1.PipelineRunList); ok {
+					pipelineRunList.Items = []tektonv1beta1.PipelineRun{
+						*olderPipelineRun, *pipelineRun, *runningPipelineRun, *otherPullRequestPipelineRun,
+					}
+				}
+			}
+
+			Expect(reporter.ReportStatus(mockK8sClient, context.TODO(), pipelineRun)).To(BeNil())
+			comment := mockGitHubClient.CreateCommentResult.body
+			Expect(comment).To(ContainSubstring("Results for commit 12a4a35:\n\n| Scenario | Status |\n| --- | --- |\n" +
+				"| example-pass | :x: Failed |\n| example-running | :hourglass_flowing_sand: In progress |\n"))
+			Expect(comment).To(ContainSubstring("<summary>example-pass</summary>"))
+			Expect(comment).To(ContainSubstring("<summary>Results for older commit a1b2c3d</summary>\n\n" +
+				"| Scenario | Status |\n| --- | --- |\n| example-pass | :heavy_check_mark: Passed |\n</details>"))
+			Expect(comment).ToNot(ContainSubstring("example-other"))
+		})
+
+		It("reports the failure of a quarantined scenario without blocking", func() {
+			getInterceptor := mockK8sClient.getInterceptor
+			mockK8sClient.getInterceptor = func(key client.ObjectKey, obj client.Object) {
+				getInterceptor(key, obj)
+				if scenario, ok := obj.(*v1beta1.IntegrationTestScenario); ok && key.Name == "example-pass" {
+					scenario.Labels = map[string]string{gitops.IntegrationTestScenarioQuarantinedLabel: "true"}
+				}
+			}
+
+			setPipelineRunOutcome(pipelineRun, failedTaskRun)
+			Expect(reporter.ReportStatus(mockK8sClient, context.TODO(), pipelineRun)).To(BeNil())
+			Expect(mockGitHubClient.CreateCommentResult.body).To(ContainSubstring("| example-pass | :warning: Failed (quarantined) |"))
+			Expect(mockGitHubClient.CreateCommitStatusResult.state).To(Equal("success"))
+			Expect(mockGitHubClient.CreateCommitStatusResult.description).To(Equal("example-pass has failed (quarantined)"))
+		})
+
 		It("doesn't create a comment for non-completed PipelineRuns", func() {
 			Expect(reporter.ReportStatus(mockK8sClient, context.TODO(), pipelineRun)).To(BeNil())
 			Expect(mockGitHubClient.CreateCommentResult.body).To(Equal(""))
@@ -531,6 +731,155 @@ var _ = Describe("GitHubReporter", func() {
 			Expect(mockGitHubClient.CreateCommitStatusResult.description).To(Equal("example-pass has failed"))
 			Expect(mockGitHubClient.CreateCommitStatusResult.statusContext).To(Equal("Red Hat Trusted App Test / devfile-sample-go-basic / example-pass"))
 		})
+
+		It("reports the deployment of a Snapshot to the repositories of its Components", func() {
+			newComponent := func(name, gitURL, revision string) applicationapiv1alpha1.SnapshotComponent {
+				return applicationapiv1alpha1.SnapshotComponent{
+					Name:           name,
+					ContainerImage: "quay.io/redhat-appstudio/sample-image:latest",
+					Source: applicationapiv1alpha1.ComponentSource{
+						ComponentSourceUnion: applicationapiv1alpha1.ComponentSourceUnion{
+							GitSource: &applicationapiv1alpha1.GitSource{URL: gitURL, Revision: revision},
+						},
+					},
+				}
+			}
+			snapshot := &applicationapiv1alpha1.Snapshot{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "snapshot-sample",
+					Namespace:   "default",
+					Labels:      pipelineRun.Labels,
+					Annotations: pipelineRun.Annotations,
+				},
+				Spec: applicationapiv1alpha1.SnapshotSpec{
+					Application: "application-sample",
+					Components: []applicationapiv1alpha1.SnapshotComponent{
+						newComponent("component-go", "https://github.com/devfile-sample/devfile-sample-go-basic.git", "12a4a35ccd08194595179815e4646c3a6c08bb77"),
+						newComponent("component-java", "https://github.com/devfile-sample/devfile-sample-java-basic", "a1b2c3d4e5f60718293a4b5c6d7e8f9012345678"),
+						newComponent("component-gitlab", "https://gitlab.com/devfile-sample/devfile-sample-python-basic", "f6e5d4c3b2a10987654321fedcba098765432101"),
+					},
+				},
+			}
+
+			// New deployments are created for the GitHub repositories
+			Expect(reporter.ReportDeploymentStatus(mockK8sClient, context.TODO(), snapshot, "staging", gitops.DeploymentInProgress)).To(BeNil())
+			Expect(mockGitHubClient.CreateDeploymentResult.refs).To(Equal([]string{
+				"12a4a35ccd08194595179815e4646c3a6c08bb77", "a1b2c3d4e5f60718293a4b5c6d7e8f9012345678",
+			}))
+			Expect(mockGitHubClient.CreateDeploymentResult.environment).To(Equal("staging"))
+			Expect(mockGitHubClient.CreateDeploymentStatusResult.state).To(Equal("in_progress"))
+			Expect(mockGitHubClient.CreateDeploymentStatusResult.descriptions).To(Equal([]string{
+				"component-go of Snapshot snapshot-sample is being deployed", "component-java of Snapshot snapshot-sample is being deployed",
+			}))
+
+			// Existing deployments are updated
+			var deploymentID int64 = 70
+			mockGitHubClient.GetDeploymentIDResult.ID = &deploymentID
+			mockGitHubClient.CreateDeploymentResult.refs = nil
+			Expect(reporter.ReportDeploymentStatus(mockK8sClient, context.TODO(), snapshot, "staging", gitops.DeploymentSucceeded)).To(BeNil())
+			Expect(mockGitHubClient.CreateDeploymentResult.refs).To(BeEmpty())
+			Expect(mockGitHubClient.CreateDeploymentStatusResult.state).To(Equal("success"))
+		})
+
+		It("keeps reporting the deployment to the other repositories when one of them fails", func() {
+			newComponent := func(name, gitURL string) applicationapiv1alpha1.SnapshotComponent {
+				return applicationapiv1alpha1.SnapshotComponent{
+					Name: name,
+					Source: applicationapiv1alpha1.ComponentSource{
+						ComponentSourceUnion: applicationapiv1alpha1.ComponentSourceUnion{
+							GitSource: &applicationapiv1alpha1.GitSource{URL: gitURL, Revision: "12a4a35ccd08194595179815e4646c3a6c08bb77"},
+						},
+					},
+				}
+			}
+			snapshot := &applicationapiv1alpha1.Snapshot{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "snapshot-sample",
+					Namespace:   "default",
+					Labels:      pipelineRun.Labels,
+					Annotations: pipelineRun.Annotations,
+				},
+				Spec: applicationapiv1alpha1.SnapshotSpec{
+					Components: []applicationapiv1alpha1.SnapshotComponent{
+						newComponent("component-go", "https://github.com/devfile-sample/devfile-sample-go-basic"),
+						newComponent("component-java", "https://github.com/devfile-sample/devfile-sample-java-basic"),
+					},
+				},
+			}
+
+			mockGitHubClient.CreateDeploymentResult.Error = errors.New("resource not accessible by integration")
+			err := reporter.ReportDeploymentStatus(mockK8sClient, context.TODO(), snapshot, "staging", gitops.DeploymentInProgress)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("component-go"))
+			Expect(err.Error()).To(ContainSubstring("component-java"))
+			Expect(mockGitHubClient.CreateDeploymentResult.refs).To(HaveLen(2))
+		})
+
+		It("doesn't create deployments only to report them as inactive", func() {
+			snapshot := &applicationapiv1alpha1.Snapshot{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "snapshot-sample",
+					Namespace:   "default",
+					Labels:      pipelineRun.Labels,
+					Annotations: pipelineRun.Annotations,
+				},
+				Spec: applicationapiv1alpha1.SnapshotSpec{
+					Components: []applicationapiv1alpha1.SnapshotComponent{
+						{
+							Name: "component-go",
+							Source: applicationapiv1alpha1.ComponentSource{
+								ComponentSourceUnion: applicationapiv1alpha1.ComponentSourceUnion{
+									GitSource: &applicationapiv1alpha1.GitSource{
+										URL:      "https://github.com/devfile-sample/devfile-sample-go-basic",
+										Revision: "12a4a35ccd08194595179815e4646c3a6c08bb77",
+									},
+								},
+							},
+						},
+					},
+				},
+			}
+
+			Expect(reporter.ReportDeploymentStatus(mockK8sClient, context.TODO(), snapshot, "staging", gitops.DeploymentInactive)).To(BeNil())
+			Expect(mockGitHubClient.CreateDeploymentResult.refs).To(BeEmpty())
+			Expect(mockGitHubClient.CreateDeploymentStatusResult.state).To(Equal(""))
+		})
+
+		It("creates a commit status for the Release status of a Snapshot", func() {
+			snapshot := &applicationapiv1alpha1.Snapshot{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "snapshot-sample",
+					Namespace:   "default",
+					Labels:      pipelineRun.Labels,
+					Annotations: pipelineRun.Annotations,
+				},
+			}
+
+			// No Releases yet
+			Expect(reporter.ReportReleaseStatus(mockK8sClient, context.TODO(), snapshot)).To(BeNil())
+			Expect(mockGitHubClient.CreateCommitStatusResult.state).To(Equal(""))
+
+			// In progress
+			meta.SetStatusCondition(&snapshot.Status.Conditions, metav1.Condition{
+				Type:   gitops.AppStudioReleasedCondition,
+				Status: metav1.ConditionUnknown,
+				Reason: gitops.AppStudioReleasedInProgress,
+			})
+			Expect(reporter.ReportReleaseStatus(mockK8sClient, context.TODO(), snapshot)).To(BeNil())
+			Expect(mockGitHubClient.CreateCommitStatusResult.state).To(Equal("pending"))
+			Expect(mockGitHubClient.CreateCommitStatusResult.description).To(Equal("Release is in progress"))
+			Expect(mockGitHubClient.CreateCommitStatusResult.statusContext).To(Equal("Red Hat Trusted App Test / devfile-sample-go-basic / release"))
+
+			// Failure
+			meta.SetStatusCondition(&snapshot.Status.Conditions, metav1.Condition{
+				Type:   gitops.AppStudioReleasedCondition,
+				Status: metav1.ConditionFalse,
+				Reason: gitops.AppStudioReleasedFailed,
+			})
+			Expect(reporter.ReportReleaseStatus(mockK8sClient, context.TODO(), snapshot)).To(BeNil())
+			Expect(mockGitHubClient.CreateCommitStatusResult.state).To(Equal("failure"))
+			Expect(mockGitHubClient.CreateCommitStatusResult.description).To(Equal("Release has failed"))
+		})
 	})
 
 })
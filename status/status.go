@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/go-logr/logr"
+	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
 	"github.com/redhat-appstudio/integration-service/gitops"
 	"github.com/redhat-appstudio/integration-service/helpers"
 	tektonv1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
@@ -18,16 +19,31 @@ type Reporter interface {
 	ReportStatus(client.Client, context.Context, *tektonv1beta1.PipelineRun) error
 }
 
+// ReleaseReporter is a generic interface all implementations reporting the Release status of a Snapshot must follow.
+type ReleaseReporter interface {
+	ReportReleaseStatus(client.Client, context.Context, *applicationapiv1alpha1.Snapshot) error
+}
+
+// DeploymentReporter is a generic interface all implementations reporting the deployment status of a Snapshot to an
+// Environment must follow.
+type DeploymentReporter interface {
+	ReportDeploymentStatus(client.Client, context.Context, *applicationapiv1alpha1.Snapshot, string, gitops.DeploymentState) error
+}
+
 // Status is the interface of the main status Adapter.
 type Status interface {
 	GetReporters(*tektonv1beta1.PipelineRun) ([]Reporter, error)
+	GetReleaseReporters(*applicationapiv1alpha1.Snapshot) ([]ReleaseReporter, error)
+	GetDeploymentReporters(*applicationapiv1alpha1.Snapshot) ([]DeploymentReporter, error)
 }
 
 // Adapter is responsible for discovering supported Reporter implementations.
 type Adapter struct {
-	logger         logr.Logger
-	k8sClient      client.Reader
-	githubReporter Reporter
+	logger                   logr.Logger
+	k8sClient                client.Reader
+	githubReporter           Reporter
+	githubReleaseReporter    ReleaseReporter
+	githubDeploymentReporter DeploymentReporter
 }
 
 // AdapterOption is used to extend Adapter with optional parameters.
@@ -40,12 +56,29 @@ func WithGitHubReporter(reporter Reporter) AdapterOption {
 	}
 }
 
+// WithGitHubReleaseReporter is an option which allows for replacement of the GitHub Release status reporter.
+func WithGitHubReleaseReporter(reporter ReleaseReporter) AdapterOption {
+	return func(a *Adapter) {
+		a.githubReleaseReporter = reporter
+	}
+}
+
+// WithGitHubDeploymentReporter is an option which allows for replacement of the GitHub deployment status reporter.
+func WithGitHubDeploymentReporter(reporter DeploymentReporter) AdapterOption {
+	return func(a *Adapter) {
+		a.githubDeploymentReporter = reporter
+	}
+}
+
 // NewAdapter constructs an Adapter with optional params, if specified.
 func NewAdapter(logger logr.Logger, k8sClient client.Client, opts ...AdapterOption) *Adapter {
+	githubReporter := NewGitHubReporter(logger, k8sClient)
 	adapter := Adapter{
-		logger:         logger,
-		k8sClient:      k8sClient,
-		githubReporter: NewGitHubReporter(logger, k8sClient),
+		logger:                   logger,
+		k8sClient:                k8sClient,
+		githubReporter:           githubReporter,
+		githubReleaseReporter:    githubReporter,
+		githubDeploymentReporter: githubReporter,
 	}
 
 	for _, opt := range opts {
@@ -66,3 +99,27 @@ func (a *Adapter) GetReporters(pipelineRun *tektonv1beta1.PipelineRun) ([]Report
 
 	return reporters, nil
 }
+
+// GetReleaseReporters returns a list of enabled/supported Release status reporters for a Snapshot.
+// All potential reporters must be added to this function for them to be utilized.
+func (a *Adapter) GetReleaseReporters(snapshot *applicationapiv1alpha1.Snapshot) ([]ReleaseReporter, error) {
+	var reporters []ReleaseReporter
+
+	if helpers.HasLabelWithValue(snapshot, gitops.PipelineAsCodeGitProviderLabel, gitops.PipelineAsCodeGitHubProviderType) {
+		reporters = append(reporters, a.githubReleaseReporter)
+	}
+
+	return reporters, nil
+}
+
+// GetDeploymentReporters returns a list of enabled/supported deployment status reporters for a Snapshot.
+// All potential reporters must be added to this function for them to be utilized.
+func (a *Adapter) GetDeploymentReporters(snapshot *applicationapiv1alpha1.Snapshot) ([]DeploymentReporter, error) {
+	var reporters []DeploymentReporter
+
+	if helpers.HasLabelWithValue(snapshot, gitops.PipelineAsCodeGitProviderLabel, gitops.PipelineAsCodeGitHubProviderType) {
+		reporters = append(reporters, a.githubDeploymentReporter)
+	}
+
+	return reporters, nil
+}
@@ -8,6 +8,8 @@ import (
 	"github.com/redhat-appstudio/integration-service/status"
 
 	"github.com/go-logr/logr"
+	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	"github.com/redhat-appstudio/integration-service/gitops"
 	tektonv1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -19,6 +21,14 @@ func (r *MockReporter) ReportStatus(client.Client, context.Context, *tektonv1bet
 	return nil
 }
 
+func (r *MockReporter) ReportReleaseStatus(client.Client, context.Context, *applicationapiv1alpha1.Snapshot) error {
+	return nil
+}
+
+func (r *MockReporter) ReportDeploymentStatus(client.Client, context.Context, *applicationapiv1alpha1.Snapshot, string, gitops.DeploymentState) error {
+	return nil
+}
+
 var _ = Describe("Status Adapter", func() {
 
 	var pipelineRun *tektonv1beta1.PipelineRun
@@ -39,4 +49,38 @@ var _ = Describe("Status Adapter", func() {
 		Expect(err).To(BeNil())
 		Expect(len(reporters)).To(Equal(1))
 	})
+
+	It("can get Release reporters from a Snapshot", func() {
+		snapshot := &applicationapiv1alpha1.Snapshot{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: pipelineRun.Labels,
+			},
+		}
+		adapter := status.NewAdapter(logr.Discard(), nil, status.WithGitHubReleaseReporter(&MockReporter{}))
+		reporters, err := adapter.GetReleaseReporters(snapshot)
+		Expect(err).To(BeNil())
+		Expect(len(reporters)).To(Equal(1))
+
+		snapshot.Labels = nil
+		reporters, err = adapter.GetReleaseReporters(snapshot)
+		Expect(err).To(BeNil())
+		Expect(reporters).To(BeEmpty())
+	})
+
+	It("can get deployment reporters from a Snapshot", func() {
+		snapshot := &applicationapiv1alpha1.Snapshot{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: pipelineRun.Labels,
+			},
+		}
+		adapter := status.NewAdapter(logr.Discard(), nil, status.WithGitHubDeploymentReporter(&MockReporter{}))
+		reporters, err := adapter.GetDeploymentReporters(snapshot)
+		Expect(err).To(BeNil())
+		Expect(len(reporters)).To(Equal(1))
+
+		snapshot.Labels = nil
+		reporters, err = adapter.GetDeploymentReporters(snapshot)
+		Expect(err).To(BeNil())
+		Expect(reporters).To(BeEmpty())
+	})
 })
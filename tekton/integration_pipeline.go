@@ -20,6 +20,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"reflect"
+	"strings"
 
 	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
 	"github.com/redhat-appstudio/integration-service/api/v1beta1"
@@ -41,6 +42,15 @@ const (
 
 	// PipelineTypeTest is the type for PipelineRuns created to run an integration Pipeline
 	PipelineTypeTest = "test"
+
+	// PipelineTypeVerification is the type for PipelineRuns created to verify a persistent Environment after a deployment
+	PipelineTypeVerification = "verification"
+
+	// EnvironmentNamespaceParamName is the name of the param containing the default namespace of the DeploymentTarget
+	EnvironmentNamespaceParamName = "NAMESPACE"
+
+	// ClusterCredentialsWorkspaceName is the name of the workspace mounting the cluster credentials of the DeploymentTarget
+	ClusterCredentialsWorkspaceName = "cluster-credentials"
 )
 
 var (
@@ -67,6 +77,12 @@ var (
 
 	// OptionalLabel is the label used to specify if an IntegrationTestScenario is allowed to fail
 	OptionalLabel = fmt.Sprintf("%s/%s", TestLabelPrefix, "optional")
+
+	// MatrixCombinationLabel is the label used to identify the IntegrationTestScenario matrix combination tested by the PipelineRun
+	MatrixCombinationLabel = fmt.Sprintf("%s/%s", TestLabelPrefix, "matrix-combination")
+
+	// MatrixParamsAnnotation is the annotation containing the matrix param values tested by the PipelineRun, e.g. "os=rhel9, arch=arm64"
+	MatrixParamsAnnotation = fmt.Sprintf("%s/%s", TestLabelPrefix, "matrix-params")
 )
 
 // IntegrationPipelineRun is a PipelineRun alias, so we can add new methods to it in this file.
@@ -123,9 +139,15 @@ func (r *IntegrationPipelineRun) WithExtraParam(name string, value tektonv1beta1
 	return r
 }
 
-// WithExtraParams adds all provided parameters to the Integration PipelineRun.
+// WithExtraParams adds all provided parameters to the Integration PipelineRun. The parameters referencing a Secret key
+// aren't added as params, so the secret values don't end up in the PipelineRun, but as Secret workspaces instead.
 func (r *IntegrationPipelineRun) WithExtraParams(params []v1beta1.PipelineParameter) *IntegrationPipelineRun {
 	for _, param := range params {
+		if param.ValueFrom != nil && param.ValueFrom.SecretKeyRef != nil {
+			r.WithSecretKeyWorkspace(param.Name, param.ValueFrom.SecretKeyRef)
+			continue
+		}
+
 		var value tektonv1beta1.ParamValue
 		switch {
 		case param.Value != "":
@@ -143,6 +165,27 @@ func (r *IntegrationPipelineRun) WithExtraParams(params []v1beta1.PipelineParame
 	return r
 }
 
+// WithSecretKeyWorkspace adds a workspace with the given name to the Integration PipelineRun which mounts the
+// referenced Secret key as a file named after the key, e.g. $(workspaces.<name>.path)/<key>. If the workspace is not
+// part of the Pipeline definition, it will be silently ignored.
+func (r *IntegrationPipelineRun) WithSecretKeyWorkspace(name string, secretKeyRef *corev1.SecretKeySelector) *IntegrationPipelineRun {
+	r.Spec.Workspaces = append(r.Spec.Workspaces, tektonv1beta1.WorkspaceBinding{
+		Name: name,
+		Secret: &corev1.SecretVolumeSource{
+			SecretName: secretKeyRef.Name,
+			Items: []corev1.KeyToPath{
+				{
+					Key:  secretKeyRef.Key,
+					Path: secretKeyRef.Key,
+				},
+			},
+			Optional: secretKeyRef.Optional,
+		},
+	})
+
+	return r
+}
+
 // WithSnapshot adds a param containing the Snapshot as a json string
 // to the integration PipelineRun.
 func (r *IntegrationPipelineRun) WithSnapshot(snapshot *applicationapiv1alpha1.Snapshot) *IntegrationPipelineRun {
@@ -179,6 +222,57 @@ func (r *IntegrationPipelineRun) WithIntegrationLabels(integrationTestScenario *
 	return r
 }
 
+// WithVerificationLabels adds the verification type and the IntegrationTestScenario name as labels to the Integration
+// PipelineRun, marking it as a post-deployment verification of a persistent Environment.
+func (r *IntegrationPipelineRun) WithVerificationLabels(integrationTestScenario *v1beta1.IntegrationTestScenario) *IntegrationPipelineRun {
+	if r.ObjectMeta.Labels == nil {
+		r.ObjectMeta.Labels = map[string]string{}
+	}
+	r.ObjectMeta.Labels[PipelinesTypeLabel] = PipelineTypeVerification
+	r.ObjectMeta.Labels[ScenarioNameLabel] = integrationTestScenario.Name
+
+	return r
+}
+
+// WithMatrixCombination adds the param values of an IntegrationTestScenario matrix combination to the Integration
+// PipelineRun, replacing the params with the same names, and labels it with the name of the combination.
+// Optional combinations are labeled as allowed to fail.
+func (r *IntegrationPipelineRun) WithMatrixCombination(combinationName string, params []v1beta1.PipelineParameter, optional bool) *IntegrationPipelineRun {
+	formattedParams := make([]string, 0, len(params))
+	for _, param := range params {
+		formattedParams = append(formattedParams, param.Name+"="+param.Value)
+		value := tektonv1beta1.ParamValue{
+			Type:      tektonv1beta1.ParamTypeString,
+			StringVal: param.Value,
+		}
+		replaced := false
+		for i := range r.Spec.Params {
+			if r.Spec.Params[i].Name == param.Name {
+				r.Spec.Params[i].Value = value
+				replaced = true
+			}
+		}
+		if !replaced {
+			r.WithExtraParam(param.Name, value)
+		}
+	}
+
+	if r.ObjectMeta.Labels == nil {
+		r.ObjectMeta.Labels = map[string]string{}
+	}
+	r.ObjectMeta.Labels[MatrixCombinationLabel] = combinationName
+	if optional {
+		r.ObjectMeta.Labels[OptionalLabel] = "true"
+	}
+
+	if r.ObjectMeta.Annotations == nil {
+		r.ObjectMeta.Annotations = map[string]string{}
+	}
+	r.ObjectMeta.Annotations[MatrixParamsAnnotation] = strings.Join(formattedParams, ", ")
+
+	return r
+}
+
 // WithApplicationAndComponent adds the name of both application and component as lables to the Integration PipelineRun.
 func (r *IntegrationPipelineRun) WithApplicationAndComponent(application *applicationapiv1alpha1.Application, component *applicationapiv1alpha1.Component) *IntegrationPipelineRun {
 	if r.ObjectMeta.Labels == nil {
@@ -195,16 +289,31 @@ func (r *IntegrationPipelineRun) WithApplicationAndComponent(application *applic
 // WithEnvironmentAndDeploymentTarget adds a param containing the DeploymentTarget connection details and Environment name
 // to the integration PipelineRun.
 func (r *IntegrationPipelineRun) WithEnvironmentAndDeploymentTarget(dt *applicationapiv1alpha1.DeploymentTarget, environmentName string) *IntegrationPipelineRun {
+	return r.WithEnvironmentAndDeploymentTargetAtIndex(dt, environmentName, 0)
+}
+
+// WithEnvironmentAndDeploymentTargetAtIndex adds a param containing the DeploymentTarget connection details and Environment name
+// to the integration PipelineRun for the Environment at the given index of the environments requested by the IntegrationTestScenario.
+// The first Environment is exposed through the NAMESPACE param and the cluster-credentials workspace while the following ones get
+// the same param and workspace suffixed with their position, e.g. NAMESPACE_2 and cluster-credentials-2.
+func (r *IntegrationPipelineRun) WithEnvironmentAndDeploymentTargetAtIndex(dt *applicationapiv1alpha1.DeploymentTarget, environmentName string, index int) *IntegrationPipelineRun {
 	if !reflect.ValueOf(dt.Spec.KubernetesClusterCredentials).IsZero() {
+		namespaceParamName := EnvironmentNamespaceParamName
+		workspaceName := ClusterCredentialsWorkspaceName
+		if position := index + 1; position > 1 {
+			namespaceParamName = fmt.Sprintf("%s_%d", EnvironmentNamespaceParamName, position)
+			workspaceName = fmt.Sprintf("%s-%d", ClusterCredentialsWorkspaceName, position)
+		}
+
 		// Add the NAMESPACE parameter to the pipeline
-		r.WithExtraParam("NAMESPACE", tektonv1beta1.ParamValue{
+		r.WithExtraParam(namespaceParamName, tektonv1beta1.ParamValue{
 			Type:      tektonv1beta1.ParamTypeString,
 			StringVal: dt.Spec.KubernetesClusterCredentials.DefaultNamespace,
 		})
 
 		// Create a new Workspace binding which will allow mounting the ClusterCredentialsSecret in the Tekton pipelineRun
 		workspace := tektonv1beta1.WorkspaceBinding{
-			Name:   "cluster-credentials",
+			Name:   workspaceName,
 			Secret: &corev1.SecretVolumeSource{SecretName: dt.Spec.KubernetesClusterCredentials.ClusterCredentialsSecret},
 		}
 		// Add the new workspace to the pipelineRun Spec
@@ -214,11 +323,13 @@ func (r *IntegrationPipelineRun) WithEnvironmentAndDeploymentTarget(dt *applicat
 		r.Spec.Workspaces = append(r.Spec.Workspaces, workspace)
 	}
 
-	// Add the environment label to the pipelineRun
+	// Add the environment label to the pipelineRun, only the first Environment is used as label value
 	if r.ObjectMeta.Labels == nil {
 		r.ObjectMeta.Labels = map[string]string{}
 	}
-	r.ObjectMeta.Labels[EnvironmentNameLabel] = environmentName
+	if _, ok := r.ObjectMeta.Labels[EnvironmentNameLabel]; !ok {
+		r.ObjectMeta.Labels[EnvironmentNameLabel] = environmentName
+	}
 
 	return r
 }
@@ -9,6 +9,7 @@ import (
 	"github.com/redhat-appstudio/integration-service/gitops"
 	tekton "github.com/redhat-appstudio/integration-service/tekton"
 	tektonv1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -348,6 +349,15 @@ var _ = Describe("Integration pipeline", func() {
 				To(Equal(integrationTestScenarioGit.Namespace))
 		})
 
+		It("can label an IntegrationPipelineRun as a post-deployment verification of the scenario", func() {
+			newIntegrationPipelineRun.WithVerificationLabels(integrationTestScenarioGit)
+			Expect(newIntegrationPipelineRun.Labels["test.appstudio.openshift.io/scenario"]).
+				To(Equal(integrationTestScenarioGit.Name))
+			Expect(newIntegrationPipelineRun.Labels["pipelines.appstudio.openshift.io/type"]).
+				To(Equal("verification"))
+			Expect(tekton.IsVerificationPipelineRun(newIntegrationPipelineRun.AsPipelineRun())).To(BeTrue())
+		})
+
 		It("can append labels that comes from Snapshot to IntegrationPipelineRun and make sure that label value matches the snapshot name", func() {
 			newIntegrationPipelineRun.WithSnapshot(hasSnapshot)
 			Expect(newIntegrationPipelineRun.Labels["appstudio.openshift.io/snapshot"]).
@@ -379,6 +389,38 @@ var _ = Describe("Integration pipeline", func() {
 				To(Equal(deploymentTarget.Spec.KubernetesClusterCredentials.DefaultNamespace))
 		})
 
+		It("can append workspaces and parameters for each of several Environments to IntegrationPipelineRun", func() {
+			newIntegrationPipelineRun.WithEnvironmentAndDeploymentTargetAtIndex(deploymentTarget, hasEnv.Name, 0)
+			newIntegrationPipelineRun.WithEnvironmentAndDeploymentTargetAtIndex(deploymentTarget, "second-env", 1)
+			Expect(newIntegrationPipelineRun.Labels["appstudio.openshift.io/environment"]).
+				To(Equal(hasEnv.Name))
+
+			Expect(newIntegrationPipelineRun.Spec.Workspaces).To(HaveLen(2))
+			Expect(newIntegrationPipelineRun.Spec.Workspaces[0].Name).To(Equal("cluster-credentials"))
+			Expect(newIntegrationPipelineRun.Spec.Workspaces[1].Name).To(Equal("cluster-credentials-2"))
+			Expect(newIntegrationPipelineRun.Spec.Workspaces[1].Secret.SecretName).
+				To(Equal(deploymentTarget.Spec.KubernetesClusterCredentials.ClusterCredentialsSecret))
+
+			Expect(newIntegrationPipelineRun.Spec.Params).To(HaveLen(2))
+			Expect(newIntegrationPipelineRun.Spec.Params[0].Name).To(Equal("NAMESPACE"))
+			Expect(newIntegrationPipelineRun.Spec.Params[1].Name).To(Equal("NAMESPACE_2"))
+			Expect(newIntegrationPipelineRun.Spec.Params[1].Value.StringVal).
+				To(Equal(deploymentTarget.Spec.KubernetesClusterCredentials.DefaultNamespace))
+		})
+
+		It("numbers the workspaces and parameters of Environments by their index in the IntegrationTestScenario", func() {
+			newIntegrationPipelineRun.WithEnvironmentAndDeploymentTargetAtIndex(deploymentTarget, "third-env", 2)
+			newIntegrationPipelineRun.WithEnvironmentAndDeploymentTargetAtIndex(deploymentTarget, hasEnv.Name, 0)
+
+			Expect(newIntegrationPipelineRun.Spec.Workspaces).To(HaveLen(2))
+			Expect(newIntegrationPipelineRun.Spec.Workspaces[0].Name).To(Equal("cluster-credentials-3"))
+			Expect(newIntegrationPipelineRun.Spec.Workspaces[1].Name).To(Equal("cluster-credentials"))
+
+			Expect(newIntegrationPipelineRun.Spec.Params).To(HaveLen(2))
+			Expect(newIntegrationPipelineRun.Spec.Params[0].Name).To(Equal("NAMESPACE_3"))
+			Expect(newIntegrationPipelineRun.Spec.Params[1].Name).To(Equal("NAMESPACE"))
+		})
+
 		It("provides parameters from IntegrationTestScenario to the PipelineRun", func() {
 			scenarioParams := []v1beta1.PipelineParameter{
 				{
@@ -398,6 +440,56 @@ var _ = Describe("Integration pipeline", func() {
 			Expect(newIntegrationPipelineRun.Spec.Params[1].Value.ArrayVal).To(Equal(scenarioParams[1].Values))
 		})
 
+		It("mounts the params referencing Secret keys as workspaces instead of passing their values", func() {
+			newIntegrationPipelineRun.WithExtraParams([]v1beta1.PipelineParameter{
+				{
+					Name: "token",
+					ValueFrom: &v1beta1.PipelineParameterSource{
+						SecretKeyRef: &corev1.SecretKeySelector{
+							LocalObjectReference: corev1.LocalObjectReference{Name: "secret-params"},
+							Key:                  "api-token",
+						},
+					},
+				},
+			})
+
+			Expect(newIntegrationPipelineRun.Spec.Params).To(BeEmpty())
+			Expect(newIntegrationPipelineRun.Spec.Workspaces).To(HaveLen(1))
+			Expect(newIntegrationPipelineRun.Spec.Workspaces[0].Name).To(Equal("token"))
+			Expect(newIntegrationPipelineRun.Spec.Workspaces[0].Secret.SecretName).To(Equal("secret-params"))
+			Expect(newIntegrationPipelineRun.Spec.Workspaces[0].Secret.Items).To(Equal([]corev1.KeyToPath{
+				{Key: "api-token", Path: "api-token"},
+			}))
+		})
+
+		It("provides the matrix combination param values and labels to the PipelineRun", func() {
+			newIntegrationPipelineRun.WithExtraParams([]v1beta1.PipelineParameter{
+				{
+					Name:  "os",
+					Value: "default",
+				},
+			})
+			newIntegrationPipelineRun.WithMatrixCombination("combination", []v1beta1.PipelineParameter{
+				{
+					Name:  "os",
+					Value: "rhel9",
+				},
+				{
+					Name:  "arch",
+					Value: "arm64",
+				},
+			}, true)
+
+			Expect(newIntegrationPipelineRun.Spec.Params).To(HaveLen(2))
+			Expect(newIntegrationPipelineRun.Spec.Params[0].Name).To(Equal("os"))
+			Expect(newIntegrationPipelineRun.Spec.Params[0].Value.StringVal).To(Equal("rhel9"))
+			Expect(newIntegrationPipelineRun.Spec.Params[1].Name).To(Equal("arch"))
+			Expect(newIntegrationPipelineRun.Spec.Params[1].Value.StringVal).To(Equal("arm64"))
+			Expect(newIntegrationPipelineRun.Labels[tekton.MatrixCombinationLabel]).To(Equal("combination"))
+			Expect(newIntegrationPipelineRun.Labels[tekton.OptionalLabel]).To(Equal("true"))
+			Expect(newIntegrationPipelineRun.Annotations[tekton.MatrixParamsAnnotation]).To(Equal("os=rhel9, arch=arm64"))
+		})
+
 	})
 
 	Context("When managing a new pipelineRun from a bundle-based IntegrationTestScenario", func() {
@@ -26,6 +26,44 @@ func IntegrationPipelineRunPredicate() predicate.Predicate {
 	}
 }
 
+// IntegrationPipelineRunFinishedPredicate returns a predicate which filters out all objects except
+// integration PipelineRuns that have just finished.
+func IntegrationPipelineRunFinishedPredicate() predicate.Predicate {
+	return predicate.Funcs{
+		CreateFunc: func(createEvent event.CreateEvent) bool {
+			return false
+		},
+		DeleteFunc: func(deleteEvent event.DeleteEvent) bool {
+			return false
+		},
+		GenericFunc: func(genericEvent event.GenericEvent) bool {
+			return false
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return IsIntegrationPipelineRun(e.ObjectNew) && hasPipelineRunStateChangedToFinished(e.ObjectOld, e.ObjectNew)
+		},
+	}
+}
+
+// VerificationPipelineRunFinishedPredicate returns a predicate which filters out all objects except
+// post-deployment verification PipelineRuns that have just finished.
+func VerificationPipelineRunFinishedPredicate() predicate.Predicate {
+	return predicate.Funcs{
+		CreateFunc: func(createEvent event.CreateEvent) bool {
+			return false
+		},
+		DeleteFunc: func(deleteEvent event.DeleteEvent) bool {
+			return false
+		},
+		GenericFunc: func(genericEvent event.GenericEvent) bool {
+			return false
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return IsVerificationPipelineRun(e.ObjectNew) && hasPipelineRunStateChangedToFinished(e.ObjectOld, e.ObjectNew)
+		},
+	}
+}
+
 // BuildPipelineRunSignedAndSucceededPredicate returns a predicate which filters out all objects except
 // Build PipelineRuns which have finished, been signed and haven't had a Snapshot created for them.
 func BuildPipelineRunSignedAndSucceededPredicate() predicate.Predicate {
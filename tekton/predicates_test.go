@@ -214,4 +214,45 @@ var _ = Describe("Predicates", func() {
 			Expect(instance.Update(contextEvent)).To(BeFalse())
 		})
 	})
+
+	Context("when testing VerificationPipelineRunFinishedPredicate", func() {
+		instance := tekton.VerificationPipelineRunFinishedPredicate()
+
+		BeforeEach(func() {
+
+			pipelineRun = &tektonv1beta1.PipelineRun{
+				ObjectMeta: v1.ObjectMeta{
+					GenerateName: prefix + "-",
+					Namespace:    namespace,
+					Labels: map[string]string{
+						"pipelines.appstudio.openshift.io/type": "verification",
+					},
+				},
+				Spec: tektonv1beta1.PipelineRunSpec{},
+			}
+			newPipelineRun = pipelineRun.DeepCopy()
+		})
+
+		It("should ignore create, delete and generic events", func() {
+			Expect(instance.Create(event.CreateEvent{Object: pipelineRun})).To(BeFalse())
+			Expect(instance.Delete(event.DeleteEvent{Object: pipelineRun})).To(BeFalse())
+			Expect(instance.Generic(event.GenericEvent{Object: pipelineRun})).To(BeFalse())
+		})
+
+		It("should return true only when a verification PipelineRun finished", func() {
+			contextEvent := event.UpdateEvent{
+				ObjectOld: pipelineRun,
+				ObjectNew: newPipelineRun,
+			}
+
+			Expect(instance.Update(contextEvent)).To(BeFalse())
+			newPipelineRun.Status.SetCondition(&apis.Condition{
+				Type:   apis.ConditionSucceeded,
+				Status: "False",
+			})
+			Expect(instance.Update(contextEvent)).To(BeTrue())
+			newPipelineRun.Labels["pipelines.appstudio.openshift.io/type"] = "test"
+			Expect(instance.Update(contextEvent)).To(BeFalse())
+		})
+	})
 })
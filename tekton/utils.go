@@ -19,6 +19,9 @@ const (
 	// PipelineRunTestType is the type denoting a test PipelineRun.
 	PipelineRunTestType = "test"
 
+	// PipelineRunVerificationType is the type denoting a post-deployment verification PipelineRun.
+	PipelineRunVerificationType = "verification"
+
 	// PipelineRunComponentLabel is the label denoting the application.
 	PipelineRunComponentLabel = "appstudio.openshift.io/component"
 
@@ -53,6 +56,18 @@ func IsIntegrationPipelineRun(object client.Object) bool {
 	return false
 }
 
+// IsVerificationPipelineRun returns a boolean indicating whether the object passed is a post-deployment
+// verification PipelineRun
+func IsVerificationPipelineRun(object client.Object) bool {
+	if pipelineRun, ok := object.(*tektonv1beta1.PipelineRun); ok {
+		return helpers.HasLabelWithValue(pipelineRun,
+			PipelineRunTypeLabel,
+			PipelineRunVerificationType)
+	}
+
+	return false
+}
+
 // hasPipelineRunStateChangedToFinished returns a boolean indicating whether the PipelineRun status changed to finished or not.
 // If the objects passed to this function are not PipelineRuns, the function will return false.
 func hasPipelineRunStateChangedToFinished(objectOld, objectNew client.Object) bool {
@@ -0,0 +1,117 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package verification
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// dockerConfigEntry contains the credentials of a registry in a docker config pull secret.
+type dockerConfigEntry struct {
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	Auth     string `json:"auth,omitempty"`
+}
+
+// dockerConfigJSON is the content of the .dockerconfigjson key of a kubernetes.io/dockerconfigjson Secret.
+type dockerConfigJSON struct {
+	Auths map[string]dockerConfigEntry `json:"auths"`
+}
+
+// pullSecretKeychain resolves the credentials of the registries from the pull secrets of a tenant.
+type pullSecretKeychain struct {
+	// entries maps the registries, optionally followed by a repository path, to their credentials
+	entries map[string]dockerConfigEntry
+}
+
+// NewPullSecretKeychain creates a keychain resolving the registry credentials from the given docker config pull
+// secrets. Secrets of other types are ignored. If a pull secret can't be parsed, an error will be returned.
+func NewPullSecretKeychain(secrets []corev1.Secret) (authn.Keychain, error) {
+	keychain := &pullSecretKeychain{entries: map[string]dockerConfigEntry{}}
+	for _, secret := range secrets {
+		var auths map[string]dockerConfigEntry
+		switch secret.Type {
+		case corev1.SecretTypeDockerConfigJson:
+			config := &dockerConfigJSON{}
+			if err := json.Unmarshal(secret.Data[corev1.DockerConfigJsonKey], config); err != nil {
+				return nil, fmt.Errorf("failed to parse the pull secret %s: %w", secret.Name, err)
+			}
+			auths = config.Auths
+		case corev1.SecretTypeDockercfg:
+			if err := json.Unmarshal(secret.Data[corev1.DockerConfigKey], &auths); err != nil {
+				return nil, fmt.Errorf("failed to parse the pull secret %s: %w", secret.Name, err)
+			}
+		default:
+			continue
+		}
+
+		for registry, entry := range auths {
+			key := normalizeRegistryKey(registry)
+			// The first pull secret providing credentials for a registry wins, like for the kubelet
+			if _, found := keychain.entries[key]; !found {
+				keychain.entries[key] = entry
+			}
+		}
+	}
+
+	return keychain, nil
+}
+
+// Resolve returns the credentials of the most specific entry matching the repository of the resource,
+// or anonymous access if no pull secret has credentials for it.
+func (k *pullSecretKeychain) Resolve(resource authn.Resource) (authn.Authenticator, error) {
+	target := resource.String()
+	var (
+		matchedKey string
+		matched    *dockerConfigEntry
+	)
+	for key, entry := range k.entries {
+		entry := entry // G601
+		if key != resource.RegistryStr() && key != target && !strings.HasPrefix(target, key+"/") {
+			continue
+		}
+		if matched == nil || len(key) > len(matchedKey) {
+			matchedKey, matched = key, &entry
+		}
+	}
+	if matched == nil {
+		return authn.Anonymous, nil
+	}
+
+	return authn.FromConfig(authn.AuthConfig{
+		Username: matched.Username,
+		Password: matched.Password,
+		Auth:     matched.Auth,
+	}), nil
+}
+
+// normalizeRegistryKey strips the scheme and the trailing slashes from the registry keys of docker config pull secrets,
+// mapping the legacy Docker Hub keys to the registry name used for its images.
+func normalizeRegistryKey(registry string) string {
+	registry = strings.TrimPrefix(strings.TrimPrefix(registry, "https://"), "http://")
+	registry = strings.TrimSuffix(registry, "/")
+	if registry == "index.docker.io/v1" || registry == "docker.io" {
+		return "index.docker.io"
+	}
+
+	return registry
+}
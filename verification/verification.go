@@ -0,0 +1,458 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package verification
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+)
+
+const (
+	// BuildVerificationPolicyAnnotation can be set on an Application to the name of a ConfigMap in its namespace
+	// containing the build verification policy the images built for its Components have to meet.
+	BuildVerificationPolicyAnnotation = "test.appstudio.openshift.io/build-verification-policy"
+
+	// BuildVerificationAnnotation is the annotation of build PipelineRuns recording the outcome of the verification of their image.
+	BuildVerificationAnnotation = "test.appstudio.openshift.io/build-verification"
+
+	// BuildVerificationReasonAnnotation is the annotation of build PipelineRuns recording why their image failed the verification.
+	BuildVerificationReasonAnnotation = "test.appstudio.openshift.io/build-verification-reason"
+
+	// BuildVerificationPassed is the outcome of a verification the built image passed.
+	BuildVerificationPassed = "Passed"
+
+	// BuildVerificationFailed is the outcome of a verification the built image failed.
+	BuildVerificationFailed = "Failed"
+
+	// PublicKeysKey is the key of the build verification policy ConfigMap containing the PEM encoded public keys
+	// the signatures and attestations of the built images are verified against.
+	PublicKeysKey = "public-keys"
+
+	// RequireProvenanceKey is the key of the build verification policy ConfigMap which requires the built images
+	// to have a signed SLSA provenance attestation when set to "true".
+	RequireProvenanceKey = "require-provenance"
+
+	// BuilderIDKey is the key of the build verification policy ConfigMap containing the builder identity
+	// the SLSA provenance of the built images has to name.
+	BuilderIDKey = "builder-id"
+
+	// SignatureAnnotation is the annotation of the cosign signature layers containing the base64 encoded signature.
+	SignatureAnnotation = "dev.cosignproject.cosign/signature"
+
+	// SimpleSigningType is the type of the cosign simple signing payloads.
+	SimpleSigningType = "cosign container image signature"
+
+	// InTotoPayloadType is the payload type of the DSSE envelopes containing in-toto statements.
+	InTotoPayloadType = "application/vnd.in-toto+json"
+
+	// SLSAProvenancePredicateType is the type of the SLSA provenance predicates the built images are verified with.
+	SLSAProvenancePredicateType = "https://slsa.dev/provenance/v0.2"
+)
+
+// Policy describes the requirements the built images have to meet before a Snapshot is created for them.
+type Policy struct {
+	// PublicKeys are the keys the signatures and attestations are verified against
+	PublicKeys []crypto.PublicKey
+	// RequireProvenance requires a signed SLSA provenance attestation of the image
+	RequireProvenance bool
+	// BuilderID is the builder identity the SLSA provenance has to name, if set
+	BuilderID string
+}
+
+// VerificationError is returned when a built image doesn't meet the requirements of the policy,
+// as opposed to errors which prevented the verification from being completed.
+type VerificationError struct {
+	Reason string
+}
+
+// Error returns the reason the built image failed the verification.
+func (e *VerificationError) Error() string {
+	return e.Reason
+}
+
+// IsVerificationError returns true if the error was caused by a built image failing the verification.
+func IsVerificationError(err error) bool {
+	var verificationError *VerificationError
+	return errors.As(err, &verificationError)
+}
+
+// newVerificationError creates a new VerificationError with the formatted reason.
+func newVerificationError(format string, args ...any) error {
+	return &VerificationError{Reason: fmt.Sprintf(format, args...)}
+}
+
+// ParsePolicy parses the build verification policy from the data of its ConfigMap.
+// If the policy doesn't contain any valid public keys, an error will be returned.
+func ParsePolicy(data map[string]string) (*Policy, error) {
+	policy := &Policy{
+		RequireProvenance: data[RequireProvenanceKey] == "true",
+		BuilderID:         strings.TrimSpace(data[BuilderIDKey]),
+	}
+
+	rest := []byte(data[PublicKeysKey])
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		publicKey, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse a public key of the build verification policy: %w", err)
+		}
+		policy.PublicKeys = append(policy.PublicKeys, publicKey)
+	}
+	if len(policy.PublicKeys) == 0 {
+		return nil, fmt.Errorf("the build verification policy doesn't contain any public keys")
+	}
+
+	return policy, nil
+}
+
+// Verifier verifies the signatures and provenance of built images against a Policy.
+type Verifier struct {
+	policy  *Policy
+	options []remote.Option
+}
+
+// NewVerifier creates and returns a Verifier for the given Policy. The images are fetched using the given
+// remote options, e.g. the credentials of the pull secrets of the tenant, or anonymously if none are given.
+// The registry credentials of the controller itself are never used.
+func NewVerifier(ctx context.Context, policy *Policy, options ...remote.Option) *Verifier {
+	return &Verifier{
+		policy:  policy,
+		options: append(options, remote.WithContext(ctx)),
+	}
+}
+
+// simpleSigningPayload is the payload signed by cosign for a container image.
+type simpleSigningPayload struct {
+	Critical struct {
+		Identity struct {
+			DockerReference string `json:"docker-reference"`
+		} `json:"identity"`
+		Image struct {
+			DockerManifestDigest string `json:"docker-manifest-digest"`
+		} `json:"image"`
+		Type string `json:"type"`
+	} `json:"critical"`
+}
+
+// dsseEnvelope is the envelope of a signed attestation.
+type dsseEnvelope struct {
+	PayloadType string `json:"payloadType"`
+	Payload     string `json:"payload"`
+	Signatures  []struct {
+		KeyID string `json:"keyid"`
+		Sig   string `json:"sig"`
+	} `json:"signatures"`
+}
+
+// provenanceStatement is an in-toto statement with a SLSA provenance predicate.
+type provenanceStatement struct {
+	PredicateType string `json:"predicateType"`
+	Subject       []struct {
+		Name   string            `json:"name"`
+		Digest map[string]string `json:"digest"`
+	} `json:"subject"`
+	Predicate struct {
+		Builder struct {
+			ID string `json:"id"`
+		} `json:"builder"`
+		Invocation struct {
+			ConfigSource struct {
+				URI string `json:"uri"`
+			} `json:"configSource"`
+		} `json:"invocation"`
+		Materials []struct {
+			URI string `json:"uri"`
+		} `json:"materials"`
+	} `json:"predicate"`
+}
+
+// Verify verifies that the image with the given digest pullspec is signed by one of the public keys of the policy
+// and, if the policy requires it, that it has a signed SLSA provenance naming the expected builder and the given
+// source repository. A VerificationError is returned if the image doesn't meet the policy, including when the
+// provenance is required but the source repository is unknown. Other errors are returned if the verification
+// couldn't be completed.
+func (v *Verifier) Verify(image string, sourceRepository string) error {
+	reference, err := name.NewDigest(image)
+	if err != nil {
+		return newVerificationError("the image %s isn't referenced by its digest: %s", image, err)
+	}
+	if v.policy.RequireProvenance && sourceRepository == "" {
+		return newVerificationError("the source repository of the image %s is unknown, its provenance can't be verified", image)
+	}
+
+	err = v.verifySignature(reference)
+	if err != nil {
+		return err
+	}
+
+	if v.policy.RequireProvenance {
+		return v.verifyProvenance(reference, sourceRepository)
+	}
+
+	return nil
+}
+
+// verifySignature verifies that one of the cosign signatures of the image is signed by a public key of the policy
+// and names the image itself, so the signature of the same content pushed to another repository isn't accepted.
+func (v *Verifier) verifySignature(reference name.Digest) error {
+	signatureImage, err := v.getAttachedImage(reference, "sig")
+	if err != nil {
+		return err
+	}
+	if signatureImage == nil {
+		return newVerificationError("no signature was found for the image %s", reference.String())
+	}
+
+	manifest, err := signatureImage.Manifest()
+	if err != nil {
+		return err
+	}
+	for _, descriptor := range manifest.Layers {
+		signature, err := base64.StdEncoding.DecodeString(descriptor.Annotations[SignatureAnnotation])
+		if err != nil {
+			continue
+		}
+		payload, err := getLayerContent(signatureImage, descriptor.Digest)
+		if err != nil {
+			return err
+		}
+		if !v.verifyWithPublicKeys(payload, signature) {
+			continue
+		}
+
+		signedPayload := &simpleSigningPayload{}
+		if json.Unmarshal(payload, signedPayload) == nil && signedPayload.Critical.Type == SimpleSigningType &&
+			signedPayload.Critical.Image.DockerManifestDigest == reference.DigestStr() &&
+			isSameRepository(signedPayload.Critical.Identity.DockerReference, reference) {
+			return nil
+		}
+	}
+
+	return newVerificationError("the image %s isn't signed by any of the public keys of the build verification policy", reference.String())
+}
+
+// verifyProvenance verifies that the image has a SLSA provenance attestation signed by a public key of the policy,
+// which names the expected builder and source repository.
+func (v *Verifier) verifyProvenance(reference name.Digest, sourceRepository string) error {
+	attestationImage, err := v.getAttachedImage(reference, "att")
+	if err != nil {
+		return err
+	}
+	if attestationImage == nil {
+		return newVerificationError("no provenance attestation was found for the image %s", reference.String())
+	}
+
+	layers, err := attestationImage.Layers()
+	if err != nil {
+		return err
+	}
+	reason := fmt.Sprintf("no SLSA provenance attestation of the image %s is signed by any of the public keys of the build verification policy", reference.String())
+	for _, layer := range layers {
+		content, err := readLayer(layer)
+		if err != nil {
+			return err
+		}
+		statement, ok := v.getSignedStatement(content)
+		if !ok || statement.PredicateType != SLSAProvenancePredicateType {
+			continue
+		}
+
+		if !hasProvenanceSubject(statement, reference.DigestStr()) {
+			reason = fmt.Sprintf("the SLSA provenance attestation doesn't name the image %s as its subject", reference.String())
+			continue
+		}
+		if v.policy.BuilderID != "" && statement.Predicate.Builder.ID != v.policy.BuilderID {
+			reason = fmt.Sprintf("the image was built by the builder %s instead of %s", statement.Predicate.Builder.ID, v.policy.BuilderID)
+			continue
+		}
+		if sourceRepository != "" && !hasProvenanceSourceRepository(statement, sourceRepository) {
+			reason = fmt.Sprintf("the SLSA provenance attestation doesn't name the source repository %s", sourceRepository)
+			continue
+		}
+
+		return nil
+	}
+
+	return newVerificationError(reason)
+}
+
+// isSameRepository returns true if the docker reference of a signature names the repository of the given image.
+func isSameRepository(dockerReference string, reference name.Digest) bool {
+	signedReference, err := name.ParseReference(dockerReference)
+	if err != nil {
+		return false
+	}
+
+	return signedReference.Context().Name() == reference.Context().Name()
+}
+
+// getSignedStatement returns the in-toto statement of the DSSE envelope if it's signed by a public key of the policy.
+func (v *Verifier) getSignedStatement(content []byte) (*provenanceStatement, bool) {
+	envelope := &dsseEnvelope{}
+	if json.Unmarshal(content, envelope) != nil || envelope.PayloadType != InTotoPayloadType {
+		return nil, false
+	}
+	payload, err := base64.StdEncoding.DecodeString(envelope.Payload)
+	if err != nil {
+		return nil, false
+	}
+
+	for _, envelopeSignature := range envelope.Signatures {
+		signature, err := base64.StdEncoding.DecodeString(envelopeSignature.Sig)
+		if err != nil || !v.verifyWithPublicKeys(preAuthenticationEncoding(envelope.PayloadType, payload), signature) {
+			continue
+		}
+		statement := &provenanceStatement{}
+		if json.Unmarshal(payload, statement) == nil {
+			return statement, true
+		}
+	}
+
+	return nil, false
+}
+
+// verifyWithPublicKeys returns true if the signature of the payload was made by one of the public keys of the policy.
+func (v *Verifier) verifyWithPublicKeys(payload []byte, signature []byte) bool {
+	digest := sha256.Sum256(payload)
+	for _, publicKey := range v.policy.PublicKeys {
+		switch key := publicKey.(type) {
+		case *ecdsa.PublicKey:
+			if ecdsa.VerifyASN1(key, digest[:], signature) {
+				return true
+			}
+		case *rsa.PublicKey:
+			if rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature) == nil {
+				return true
+			}
+		case ed25519.PublicKey:
+			if ed25519.Verify(key, payload, signature) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// getAttachedImage returns the image cosign attached to the given image with the given suffix,
+// or nil if the image has none.
+func (v *Verifier) getAttachedImage(reference name.Digest, suffix string) (v1.Image, error) {
+	tag := reference.Context().Tag(fmt.Sprintf("%s.%s", strings.Replace(reference.DigestStr(), ":", "-", 1), suffix))
+	image, err := remote.Image(tag, v.options...)
+	var transportError *transport.Error
+	if errors.As(err, &transportError) && transportError.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+
+	return image, err
+}
+
+// getLayerContent returns the content of the layer of the image with the given digest.
+func getLayerContent(image v1.Image, digest v1.Hash) ([]byte, error) {
+	layer, err := image.LayerByDigest(digest)
+	if err != nil {
+		return nil, err
+	}
+
+	return readLayer(layer)
+}
+
+// readLayer reads the content of the layer as it's stored in the registry.
+func readLayer(layer v1.Layer) ([]byte, error) {
+	reader, err := layer.Compressed()
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	return io.ReadAll(reader)
+}
+
+// preAuthenticationEncoding returns the DSSE pre-authentication encoding of the payload, which is what gets signed.
+func preAuthenticationEncoding(payloadType string, payload []byte) []byte {
+	return []byte(fmt.Sprintf("DSSEv1 %d %s %d %s", len(payloadType), payloadType, len(payload), payload))
+}
+
+// hasProvenanceSubject returns true if the provenance statement names the image with the given digest as its subject.
+func hasProvenanceSubject(statement *provenanceStatement, digest string) bool {
+	for _, subject := range statement.Subject {
+		if "sha256:"+subject.Digest["sha256"] == digest {
+			return true
+		}
+	}
+
+	return false
+}
+
+// hasProvenanceSourceRepository returns true if the provenance statement names the given source repository
+// as the source of its build configuration or as one of its materials.
+func hasProvenanceSourceRepository(statement *provenanceStatement, sourceRepository string) bool {
+	uris := []string{statement.Predicate.Invocation.ConfigSource.URI}
+	for _, material := range statement.Predicate.Materials {
+		uris = append(uris, material.URI)
+	}
+	for _, uri := range uris {
+		if uri != "" && normalizeRepositoryURL(uri) == normalizeRepositoryURL(sourceRepository) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// normalizeRepositoryURL strips the parts of a git repository URL which don't identify the repository,
+// like the git+ scheme prefix, the query, the revision and the .git suffix.
+func normalizeRepositoryURL(url string) string {
+	url = strings.TrimPrefix(url, "git+")
+	if queryStart := strings.IndexAny(url, "?#"); queryStart >= 0 {
+		url = url[:queryStart]
+	}
+	if schemeEnd := strings.Index(url, "://"); schemeEnd >= 0 {
+		pathStart := strings.Index(url[schemeEnd+3:], "/")
+		if pathStart >= 0 {
+			pathStart += schemeEnd + 3
+			if revisionStart := strings.Index(url[pathStart:], "@"); revisionStart >= 0 {
+				url = url[:pathStart+revisionStart]
+			}
+		}
+	}
+	url = strings.TrimSuffix(strings.TrimSuffix(url, "/"), ".git")
+
+	return strings.ToLower(url)
+}
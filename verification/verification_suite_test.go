@@ -0,0 +1,53 @@
+/*
+Copyright 2023.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package verification_test
+
+import (
+	"context"
+	"io"
+	"log"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/registry"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var (
+	registryServer *httptest.Server
+	registryHost   string
+	ctx            context.Context
+	cancel         context.CancelFunc
+)
+
+func TestVerification(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Verification Test Suite")
+}
+
+var _ = BeforeSuite(func() {
+	ctx, cancel = context.WithCancel(context.TODO())
+
+	// An in-memory registry stands in for the registry the built images are pushed to
+	registryServer = httptest.NewServer(registry.New(registry.Logger(log.New(io.Discard, "", 0))))
+	registryHost = strings.TrimPrefix(registryServer.URL, "http://")
+})
+
+var _ = AfterSuite(func() {
+	cancel()
+	registryServer.Close()
+})
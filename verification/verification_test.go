@@ -0,0 +1,273 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package verification_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/redhat-appstudio/integration-service/verification"
+	corev1 "k8s.io/api/core/v1"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Build verification", Ordered, func() {
+
+	const (
+		sourceRepository = "https://github.com/devfile-samples/devfile-sample-go-basic"
+		builderID        = "https://tekton.dev/chains/v2"
+	)
+
+	var (
+		signingKey *ecdsa.PrivateKey
+		otherKey   *ecdsa.PrivateKey
+		policy     *verification.Policy
+	)
+
+	encodePublicKey := func(key *ecdsa.PrivateKey) string {
+		der, err := x509.MarshalPKIXPublicKey(key.Public())
+		Expect(err).NotTo(HaveOccurred())
+		return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+	}
+
+	sign := func(key *ecdsa.PrivateKey, payload []byte) string {
+		digest := sha256.Sum256(payload)
+		signature, err := ecdsa.SignASN1(rand.Reader, key, digest[:])
+		Expect(err).NotTo(HaveOccurred())
+		return base64.StdEncoding.EncodeToString(signature)
+	}
+
+	// pushImage pushes a random image to the repository and returns its digest reference
+	pushImage := func(repository string) name.Digest {
+		image, err := random.Image(64, 1)
+		Expect(err).NotTo(HaveOccurred())
+		tag, err := name.NewTag(fmt.Sprintf("%s/%s:latest", registryHost, repository))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(remote.Write(tag, image)).To(Succeed())
+		digest, err := image.Digest()
+		Expect(err).NotTo(HaveOccurred())
+		return tag.Context().Digest(digest.String())
+	}
+
+	// attach pushes an image with a single layer holding the payload next to the image, the way cosign does
+	attach := func(reference name.Digest, suffix string, payload []byte, mediaType types.MediaType, annotations map[string]string) {
+		attached, err := mutate.Append(empty.Image, mutate.Addendum{
+			Layer:       static.NewLayer(payload, mediaType),
+			Annotations: annotations,
+		})
+		Expect(err).NotTo(HaveOccurred())
+		tag := reference.Context().Tag(strings.Replace(reference.DigestStr(), ":", "-", 1) + "." + suffix)
+		Expect(remote.Write(tag, attached)).To(Succeed())
+	}
+
+	signImageAs := func(reference name.Digest, key *ecdsa.PrivateKey, dockerReference string) {
+		payload := []byte(fmt.Sprintf(`{"critical":{"identity":{"docker-reference":"%s"},"image":{"docker-manifest-digest":"%s"},"type":"%s"},"optional":null}`,
+			dockerReference, reference.DigestStr(), verification.SimpleSigningType))
+		attach(reference, "sig", payload, "application/vnd.dev.cosign.simplesigning.v1+json",
+			map[string]string{verification.SignatureAnnotation: sign(key, payload)})
+	}
+
+	signImage := func(reference name.Digest, key *ecdsa.PrivateKey) {
+		signImageAs(reference, key, reference.Context().String())
+	}
+
+	attestImage := func(reference name.Digest, key *ecdsa.PrivateKey, builder string, source string) {
+		statement, err := json.Marshal(map[string]any{
+			"_type":         "https://in-toto.io/Statement/v0.1",
+			"predicateType": verification.SLSAProvenancePredicateType,
+			"subject": []map[string]any{
+				{"name": reference.Context().String(), "digest": map[string]string{"sha256": strings.TrimPrefix(reference.DigestStr(), "sha256:")}},
+			},
+			"predicate": map[string]any{
+				"builder":   map[string]string{"id": builder},
+				"materials": []map[string]string{{"uri": "git+" + source + ".git@a2ba645d50e471d5f084b"}},
+			},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		pae := fmt.Sprintf("DSSEv1 %d %s %d %s", len(verification.InTotoPayloadType), verification.InTotoPayloadType, len(statement), statement)
+		envelope, err := json.Marshal(map[string]any{
+			"payloadType": verification.InTotoPayloadType,
+			"payload":     base64.StdEncoding.EncodeToString(statement),
+			"signatures":  []map[string]string{{"keyid": "", "sig": sign(key, []byte(pae))}},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		attach(reference, "att", envelope, "application/vnd.dsse.envelope.v1+json", nil)
+	}
+
+	BeforeAll(func() {
+		var err error
+		signingKey, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		Expect(err).NotTo(HaveOccurred())
+		otherKey, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		Expect(err).NotTo(HaveOccurred())
+
+		policy, err = verification.ParsePolicy(map[string]string{
+			verification.PublicKeysKey:        encodePublicKey(otherKey) + encodePublicKey(signingKey),
+			verification.RequireProvenanceKey: "true",
+			verification.BuilderIDKey:         builderID,
+		})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("parses the build verification policy", func() {
+		Expect(policy.PublicKeys).To(HaveLen(2))
+		Expect(policy.PublicKeys[1]).To(Equal(signingKey.Public()))
+		Expect(policy.RequireProvenance).To(BeTrue())
+		Expect(policy.BuilderID).To(Equal(builderID))
+
+		_, err := verification.ParsePolicy(map[string]string{verification.RequireProvenanceKey: "true"})
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("accepts a signed image with a matching provenance", func() {
+		reference := pushImage("verified")
+		signImage(reference, signingKey)
+		attestImage(reference, signingKey, builderID, sourceRepository)
+
+		Expect(verification.NewVerifier(ctx, policy).Verify(reference.String(), sourceRepository+"?rev=main")).To(Succeed())
+		Expect(verification.NewVerifier(ctx, policy).Verify(reference.String(), sourceRepository)).To(Succeed())
+	})
+
+	It("rejects images which aren't signed by the public keys of the policy", func() {
+		unsigned := pushImage("unsigned")
+		err := verification.NewVerifier(ctx, policy).Verify(unsigned.String(), sourceRepository)
+		Expect(verification.IsVerificationError(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("no signature was found"))
+
+		foreignKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		Expect(err).NotTo(HaveOccurred())
+		foreign := pushImage("foreign")
+		signImage(foreign, foreignKey)
+		err = verification.NewVerifier(ctx, policy).Verify(foreign.String(), sourceRepository)
+		Expect(verification.IsVerificationError(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("isn't signed by any of the public keys"))
+
+		err = verification.NewVerifier(ctx, policy).Verify(registryHost+"/unsigned:latest", sourceRepository)
+		Expect(verification.IsVerificationError(err)).To(BeTrue())
+
+		// A valid signature of the same content pushed to another repository isn't accepted
+		copied := pushImage("copied")
+		signImageAs(copied, signingKey, registryHost+"/verified")
+		attestImage(copied, signingKey, builderID, sourceRepository)
+		err = verification.NewVerifier(ctx, policy).Verify(copied.String(), sourceRepository)
+		Expect(verification.IsVerificationError(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("isn't signed by any of the public keys"))
+	})
+
+	It("rejects images whose provenance is missing or doesn't meet the policy", func() {
+		noProvenance := pushImage("no-provenance")
+		signImage(noProvenance, signingKey)
+		err := verification.NewVerifier(ctx, policy).Verify(noProvenance.String(), sourceRepository)
+		Expect(verification.IsVerificationError(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("no provenance attestation was found"))
+
+		otherBuilder := pushImage("other-builder")
+		signImage(otherBuilder, signingKey)
+		attestImage(otherBuilder, signingKey, "https://example.com/builder", sourceRepository)
+		err = verification.NewVerifier(ctx, policy).Verify(otherBuilder.String(), sourceRepository)
+		Expect(verification.IsVerificationError(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("was built by the builder https://example.com/builder"))
+
+		otherSource := pushImage("other-source")
+		signImage(otherSource, signingKey)
+		attestImage(otherSource, signingKey, builderID, "https://github.com/example/other")
+		err = verification.NewVerifier(ctx, policy).Verify(otherSource.String(), sourceRepository)
+		Expect(verification.IsVerificationError(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("doesn't name the source repository"))
+
+		foreignKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		Expect(err).NotTo(HaveOccurred())
+		foreignProvenance := pushImage("foreign-provenance")
+		signImage(foreignProvenance, signingKey)
+		attestImage(foreignProvenance, foreignKey, builderID, sourceRepository)
+		err = verification.NewVerifier(ctx, policy).Verify(foreignProvenance.String(), sourceRepository)
+		Expect(verification.IsVerificationError(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("is signed by any of the public keys"))
+	})
+
+	It("rejects images whose source repository is unknown when the provenance is required", func() {
+		reference := pushImage("unknown-source")
+		signImage(reference, signingKey)
+		attestImage(reference, signingKey, builderID, sourceRepository)
+
+		err := verification.NewVerifier(ctx, policy).Verify(reference.String(), "")
+		Expect(verification.IsVerificationError(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("source repository of the image"))
+	})
+
+	It("resolves the registry credentials from the docker config pull secrets", func() {
+		dockerConfig := fmt.Sprintf(`{"auths":{"https://%s/":{"auth":"%s"},"%s/private":{"username":"robot","password":"token"}}}`,
+			registryHost, base64.StdEncoding.EncodeToString([]byte("user:password")), registryHost)
+		keychain, err := verification.NewPullSecretKeychain([]corev1.Secret{
+			{
+				Type: corev1.SecretTypeOpaque,
+				Data: map[string][]byte{"token": []byte("ignored")},
+			},
+			{
+				Type: corev1.SecretTypeDockerConfigJson,
+				Data: map[string][]byte{corev1.DockerConfigJsonKey: []byte(dockerConfig)},
+			},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		resolveAuth := func(repository string) *authn.AuthConfig {
+			reference, err := name.NewRepository(repository)
+			Expect(err).NotTo(HaveOccurred())
+			authenticator, err := keychain.Resolve(reference)
+			Expect(err).NotTo(HaveOccurred())
+			auth, err := authenticator.Authorization()
+			Expect(err).NotTo(HaveOccurred())
+			return auth
+		}
+		Expect(resolveAuth(registryHost + "/verified").Auth).To(Equal(base64.StdEncoding.EncodeToString([]byte("user:password"))))
+		Expect(resolveAuth(registryHost + "/private/image").Username).To(Equal("robot"))
+		Expect(*resolveAuth("quay.io/redhat-appstudio/image")).To(Equal(authn.AuthConfig{}))
+	})
+
+	It("only requires the signature when the policy doesn't require the provenance", func() {
+		signatureOnlyPolicy := *policy
+		signatureOnlyPolicy.RequireProvenance = false
+
+		reference := pushImage("signature-only")
+		signImage(reference, signingKey)
+		Expect(verification.NewVerifier(ctx, &signatureOnlyPolicy).Verify(reference.String(), sourceRepository)).To(Succeed())
+	})
+
+	It("returns other errors when the registry can't be reached", func() {
+		err := verification.NewVerifier(ctx, policy).Verify("127.0.0.1:1/unreachable@sha256:"+strings.Repeat("0", 64), sourceRepository)
+		Expect(err).To(HaveOccurred())
+		Expect(verification.IsVerificationError(err)).To(BeFalse())
+	})
+})